@@ -0,0 +1,128 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=POST path=/billing/:billingID/adjustment
+func (s *Service) AddAdjustment(ctx context.Context, billingID string, req *AddAdjustmentRequest) (*AddAdjustmentResponse, error) {
+	fn := "billing.Service.AddAdjustment"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("amount", req.Amount).With("referenceLineItemID", req.ReferenceLineItemID)
+
+	// validate amount
+	if req.Amount >= 0 {
+		logger.Warn("amount must be less than 0")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "amount must be less than 0",
+		}
+	}
+
+	// validate reason
+	if req.Reason == "" {
+		logger.Warn("reason is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "reason is required",
+		}
+	}
+
+	lineItemID, err := s.addAdjustmentUsecase.Execute(ctx, billingID, dto.AddAdjustmentInput{
+		Description:         req.Description,
+		Amount:              req.Amount,
+		Reason:              req.Reason,
+		ReferenceLineItemID: req.ReferenceLineItemID,
+		TaxCode:             req.TaxCode,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingNotOpen) {
+			logger.Warn("billing is not open")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "billing is not open",
+			}
+		}
+		if errors.Is(err, dto.ErrAmountHasTooManyDecimals) {
+			logger.Warn("amount has too many decimals")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "amount has many decimals",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingHasNoTaxJurisdiction) {
+			logger.Warn("billing has no tax jurisdiction")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "billing has no tax jurisdiction",
+			}
+		}
+		if errors.Is(err, dto.ErrTaxRateNotFound) {
+			logger.Warn("tax rate not found")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "tax code has no rate in the billing tax jurisdiction",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidAdjustment) {
+			logger.Warn("adjustment is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "adjustment is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrAdjustmentReasonRequired) {
+			logger.Warn("adjustment reason is required")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "reason is required",
+			}
+		}
+		if errors.Is(err, dto.ErrReferencedLineItemNotFound) {
+			logger.Warn("referenced line item not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "referenced line item not found",
+			}
+		}
+		if errors.Is(err, dto.ErrAdjustmentExceedsReferencedItem) {
+			logger.Warn("adjustment exceeds referenced line item")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "adjustment exceeds the remaining amount of the referenced line item",
+			}
+		}
+		if errors.Is(err, dto.ErrNegativeBillingTotal) {
+			logger.Warn("billing total cannot go below zero")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing total cannot go below zero",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to add adjustment", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to add adjustment",
+		}
+	}
+
+	logger.Info("Adjustment added successfully", "lineItemID", lineItemID)
+
+	return &AddAdjustmentResponse{
+		LineItemID: lineItemID,
+	}, nil
+}
@@ -0,0 +1,296 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// defaultBankTransactionsLimit is how many transactions the review queue returns when no limit is given
+const defaultBankTransactionsLimit = 50
+
+// maxBankTransactionsLimit is the largest page of the review queue
+const maxBankTransactionsLimit = 500
+
+// encore:api private method=POST path=/bank-statements
+func (s *Service) ImportBankStatement(ctx context.Context, req *ImportBankStatementRequest) (*BankStatement, error) {
+	fn := "billing.Service.ImportBankStatement"
+	logger := rlog.With("fn", fn).With("format", req.Format).With("size", len(req.Content))
+
+	// validate format
+	if req.Format != entities.BankStatementFormatCamt053 && req.Format != entities.BankStatementFormatCSV {
+		logger.Warn("format is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "format must be camt053 or csv",
+		}
+	}
+
+	// validate content
+	if req.Content == "" || len(req.Content) > entities.BankStatementMaxSize {
+		logger.Warn("content is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "content is required and must be at most 10 MiB",
+		}
+	}
+
+	statement, err := s.importBankStatementUsecase.Execute(ctx, dto.ImportBankStatementInput{
+		Format:  req.Format,
+		Content: []byte(req.Content),
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidBankStatement) {
+			logger.Warn("bank statement is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "bank statement is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrCurrencyNotSupported) {
+			logger.Warn("currency not supported")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "bank statement has a currency that is not supported",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to import bank statement", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to import bank statement",
+		}
+	}
+
+	logger.Info("Bank statement imported successfully", "statementID", statement.ExternalStatementID)
+
+	response := bankStatementResponse(statement)
+	return &response, nil
+}
+
+// encore:api private method=GET path=/bank-statements/:statementID
+func (s *Service) GetBankStatement(ctx context.Context, statementID string) (*BankStatement, error) {
+	fn := "billing.Service.GetBankStatement"
+	logger := rlog.With("fn", fn).With("statementID", statementID)
+
+	statement, err := s.getBankStatementUsecase.Execute(ctx, statementID)
+	if err != nil {
+		if errors.Is(err, dto.ErrBankStatementNotFound) {
+			logger.Warn("bank statement not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "bank statement not found",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to get bank statement", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to get bank statement",
+		}
+	}
+
+	response := bankStatementResponse(statement)
+	return &response, nil
+}
+
+// encore:api private method=GET path=/bank-transactions
+func (s *Service) ListBankTransactions(ctx context.Context, req *ListBankTransactionsRequest) (*ListBankTransactionsResponse, error) {
+	fn := "billing.Service.ListBankTransactions"
+	logger := rlog.With("fn", fn).With("status", req.Status).With("limit", req.Limit)
+
+	// validate status
+	status := req.Status
+	if status == "" {
+		status = entities.BankTransactionStatusNeedsReview
+	}
+	if status != entities.BankTransactionStatusMatched && status != entities.BankTransactionStatusNeedsReview && status != entities.BankTransactionStatusIgnored {
+		logger.Warn("status is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "status must be matched, needs_review or ignored",
+		}
+	}
+
+	// validate limit
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultBankTransactionsLimit
+	}
+	if limit < 0 || limit > maxBankTransactionsLimit {
+		logger.Warn("limit is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "limit must be between 1 and 500",
+		}
+	}
+
+	transactions, err := s.listBankTransactionsUsecase.Execute(ctx, status, limit)
+	if err != nil {
+		logger.Error("failed to list bank transactions", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list bank transactions",
+		}
+	}
+
+	response := make([]BankTransaction, len(transactions))
+	for i := range transactions {
+		response[i] = bankTransactionResponse(&transactions[i])
+	}
+
+	return &ListBankTransactionsResponse{
+		Transactions: response,
+	}, nil
+}
+
+// encore:api private method=POST path=/bank-transactions/:transactionID/match
+func (s *Service) MatchBankTransaction(ctx context.Context, transactionID string, req *MatchBankTransactionRequest) (*BankTransaction, error) {
+	fn := "billing.Service.MatchBankTransaction"
+	logger := rlog.With("fn", fn).With("transactionID", transactionID).With("billingID", req.BillingID)
+
+	// validate billing ID
+	if req.BillingID == "" {
+		logger.Warn("billing ID is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "billing_id is required",
+		}
+	}
+
+	transaction, err := s.reviewBankTransactionUsecase.Match(ctx, transactionID, req.BillingID)
+	if err != nil {
+		return nil, bankTransactionReviewError(logger, err)
+	}
+
+	logger.Info("Bank transaction matched successfully", "paymentID", transaction.ExternalPaymentID)
+
+	response := bankTransactionResponse(transaction)
+	return &response, nil
+}
+
+// encore:api private method=POST path=/bank-transactions/:transactionID/ignore
+func (s *Service) IgnoreBankTransaction(ctx context.Context, transactionID string) (*BankTransaction, error) {
+	fn := "billing.Service.IgnoreBankTransaction"
+	logger := rlog.With("fn", fn).With("transactionID", transactionID)
+
+	transaction, err := s.reviewBankTransactionUsecase.Ignore(ctx, transactionID)
+	if err != nil {
+		return nil, bankTransactionReviewError(logger, err)
+	}
+
+	logger.Info("Bank transaction ignored successfully")
+
+	response := bankTransactionResponse(transaction)
+	return &response, nil
+}
+
+// bankTransactionReviewError maps the errors of a review, recording the payment of a match included
+func bankTransactionReviewError(logger rlog.Ctx, err error) error {
+	if errors.Is(err, dto.ErrBankTransactionNotFound) {
+		logger.Warn("bank transaction not found")
+		return &errs.Error{
+			Code:    errs.NotFound,
+			Message: "bank transaction not found",
+		}
+	}
+	if errors.Is(err, dto.ErrBankTransactionReviewed) {
+		logger.Warn("bank transaction is already reviewed")
+		return &errs.Error{
+			Code:    errs.FailedPrecondition,
+			Message: "bank transaction is already reviewed",
+		}
+	}
+	if errors.Is(err, dto.ErrBillingNotFound) {
+		logger.Warn("billing not found")
+		return &errs.Error{
+			Code:    errs.NotFound,
+			Message: "billing not found",
+		}
+	}
+	if errors.Is(err, dto.ErrBillingNotClosed) {
+		logger.Warn("billing is not closed")
+		return &errs.Error{
+			Code:    errs.FailedPrecondition,
+			Message: "billing is not closed",
+		}
+	}
+	if errors.Is(err, dto.ErrPaymentCurrencyMismatch) {
+		logger.Warn("transaction currency does not match the billing currency")
+		return &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "transaction currency does not match the billing currency",
+		}
+	}
+	if errors.Is(err, dto.ErrPaymentReferenceTaken) {
+		logger.Warn("bank reference is recorded for another billing")
+		return &errs.Error{
+			Code:    errs.AlreadyExists,
+			Message: "bank reference is recorded for another billing",
+		}
+	}
+
+	// unknown error
+	logger.Error("failed to review bank transaction", "error", err)
+	return &errs.Error{
+		Code:    errs.Internal,
+		Message: "failed to review bank transaction",
+	}
+}
+
+func bankStatementResponse(statement *entities.BankStatement) BankStatement {
+	response := BankStatement{
+		StatementID:  statement.ExternalStatementID,
+		Format:       statement.Format,
+		Reference:    statement.Reference,
+		Account:      statement.Account,
+		Transactions: make([]BankTransaction, len(statement.Transactions)),
+		CreatedAt:    statement.CreatedAt,
+	}
+	for i := range statement.Transactions {
+		switch statement.Transactions[i].Status {
+		case entities.BankTransactionStatusMatched:
+			response.Matched++
+		case entities.BankTransactionStatusNeedsReview:
+			response.NeedsReview++
+		default:
+			response.Ignored++
+		}
+		response.Transactions[i] = bankTransactionResponse(&statement.Transactions[i])
+	}
+	return response
+}
+
+func bankTransactionResponse(transaction *entities.BankTransaction) BankTransaction {
+	response := BankTransaction{
+		TransactionID:       transaction.ExternalTransactionID,
+		StatementID:         transaction.ExternalStatementID,
+		BankReference:       transaction.BankReference,
+		BookingDate:         transaction.BookingDate.Format("2006-01-02"),
+		Direction:           transaction.Direction,
+		AmountMinor:         transaction.AmountMinor,
+		Currency:            transaction.Currency,
+		CurrencyPrecision:   transaction.CurrencyPrecision,
+		CounterpartyName:    transaction.CounterpartyName,
+		RemittanceInfo:      transaction.RemittanceInfo,
+		Status:              transaction.Status,
+		MatchRule:           transaction.MatchRule,
+		CandidateBillingIDs: transaction.ExternalCandidateBillingIDs,
+		CreatedAt:           transaction.CreatedAt,
+		UpdatedAt:           transaction.UpdatedAt,
+	}
+	if transaction.ExternalBillingID != nil {
+		response.BillingID = *transaction.ExternalBillingID
+	}
+	if transaction.ExternalPaymentID != nil {
+		response.PaymentID = *transaction.ExternalPaymentID
+	}
+	return response
+}
@@ -12,6 +12,9 @@ import (
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
 
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/infrastructure/documents"
+	"encore.app/billing/infrastructure/events"
 	"encore.app/billing/infrastructure/persistence"
 	"encore.app/billing/infrastructure/services"
 	"encore.app/billing/infrastructure/temporal"
@@ -37,7 +40,71 @@ type Service struct {
 	createBillingUsecase     usecases.CreateBillingUsecase
 	addLineItemUsecase       usecases.AddLineItemUsecase
 	closeBillingUsecase      usecases.CloseBillingUsecase
+	cancelBillingUsecase     usecases.CancelBillingUsecase
 	getBillingSummaryUsecase usecases.GetBillingSummaryUseCase
+	prorateBillingUsecase    usecases.ProrateBillingUsecase
+	addAdjustmentUsecase     usecases.AddAdjustmentUsecase
+
+	setTaxRateUsecase   usecases.SetTaxRateUsecase
+	listTaxRatesUsecase usecases.ListTaxRatesUseCase
+
+	createCouponUsecase usecases.CreateCouponUsecase
+	applyCouponUsecase  usecases.ApplyCouponUsecase
+
+	createCreditNoteUsecase usecases.CreateCreditNoteUsecase
+	getCreditNoteUsecase    usecases.GetCreditNoteUseCase
+	listCreditNotesUsecase  usecases.ListCreditNotesUseCase
+
+	setInvoiceNumberSeriesUsecase  usecases.SetInvoiceNumberSeriesUsecase
+	listInvoiceNumberSeriesUsecase usecases.ListInvoiceNumberSeriesUseCase
+
+	renderInvoiceUsecase      usecases.RenderInvoiceUseCase
+	setInvoiceTemplateUsecase usecases.SetInvoiceTemplateUsecase
+	exportUBLInvoiceUsecase   usecases.ExportUBLInvoiceUseCase
+	setPartyUsecase           usecases.SetPartyUsecase
+
+	exportLineItemsUsecase usecases.ExportLineItemsUseCase
+
+	createWebhookUsecase         usecases.CreateWebhookUsecase
+	listWebhookDeliveriesUsecase usecases.ListWebhookDeliveriesUseCase
+	redeliverWebhookUsecase      usecases.RedeliverWebhookUsecase
+	dispatchWebhookEventUsecase  usecases.DispatchWebhookEventUsecase
+
+	replayOutboxUsecase usecases.ReplayOutboxUseCase
+
+	recordPaymentUsecase usecases.RecordPaymentUsecase
+	listPaymentsUsecase  usecases.ListPaymentsUseCase
+
+	startDunningUsecase usecases.StartDunningUsecase
+	getDunningUsecase   usecases.GetDunningUseCase
+
+	refundPaymentUsecase usecases.RefundPaymentUsecase
+	listRefundsUsecase   usecases.ListRefundsUseCase
+
+	importBankStatementUsecase   usecases.ImportBankStatementUsecase
+	getBankStatementUsecase      usecases.GetBankStatementUseCase
+	listBankTransactionsUsecase  usecases.ListBankTransactionsUseCase
+	reviewBankTransactionUsecase usecases.ReviewBankTransactionUsecase
+
+	getAgingReportUsecase usecases.GetAgingReportUsecase
+
+	getLedgerBalancesUsecase  usecases.GetLedgerBalancesUseCase
+	listJournalEntriesUsecase usecases.ListJournalEntriesUseCase
+	checkLedgerUsecase        usecases.CheckLedgerUseCase
+
+	listWalletsUsecase            usecases.ListWalletsUseCase
+	creditWalletUsecase           usecases.CreditWalletUseCase
+	listWalletTransactionsUsecase usecases.ListWalletTransactionsUseCase
+
+	createMeterUsecase       usecases.CreateMeterUseCase
+	listMetersUsecase        usecases.ListMetersUseCase
+	ingestUsageEventsUsecase usecases.IngestUsageEventsUseCase
+
+	createPlanUsecase             usecases.CreatePlanUsecase
+	createSubscriptionUsecase     usecases.CreateSubscriptionUsecase
+	getSubscriptionUsecase        usecases.GetSubscriptionUseCase
+	changeSubscriptionPlanUsecase usecases.ChangeSubscriptionPlanUsecase
+	cancelSubscriptionUsecase     usecases.CancelSubscriptionUsecase
 
 	client client.Client
 	worker worker.Worker
@@ -48,10 +115,43 @@ func initService() (*Service, error) {
 
 	// initialise database repository
 	dbRepository := persistence.NewPostgresDBRepository(db)
+	subscriptionRepository := persistence.NewPostgresSubscriptionRepository(db)
+	taxRepository := persistence.NewPostgresTaxRepository(db)
+	couponRepository := persistence.NewPostgresCouponRepository(db)
+	creditNoteRepository := persistence.NewPostgresCreditNoteRepository(db)
+	invoiceNumberRepository := persistence.NewPostgresInvoiceNumberRepository(db)
+	invoiceTemplateRepository := persistence.NewPostgresInvoiceTemplateRepository(db)
+	partyRepository := persistence.NewPostgresPartyRepository(db)
+	exportRepository := persistence.NewPostgresExportRepository(db)
+	webhookRepository := persistence.NewPostgresWebhookRepository(db)
+	outboxRepository := persistence.NewPostgresOutboxRepository(db)
+	paymentRepository := persistence.NewPostgresPaymentRepository(db)
+	dunningRepository := persistence.NewPostgresDunningRepository(db)
+	refundRepository := persistence.NewPostgresRefundRepository(db)
+	receivableRepository := persistence.NewPostgresReceivableRepository(db)
+	ledgerRepository := persistence.NewPostgresLedgerRepository(db)
+	walletRepository := persistence.NewPostgresWalletRepository(db)
+	meterRepository := persistence.NewPostgresMeterRepository(db)
+	bankStatementRepository := persistence.NewPostgresBankStatementRepository(db)
 
 	// initialise FX service
 	fxService := services.NewFxService()
 
+	// initialise document renderer
+	documentRenderer := documents.NewDocumentRenderer()
+
+	// initialise bank statement parser
+	bankStatementParser := documents.NewBankStatementParser()
+
+	// initialise event publisher
+	eventPublisher := events.NewPubSubEventPublisher()
+
+	// initialise webhook sender
+	webhookSender := services.NewHTTPWebhookSender()
+
+	// initialise payment gateway, charges are made against the in-process fake until a provider is integrated
+	paymentGateway := services.NewFakePaymentGateway()
+
 	// initialise temporal client
 	temporalClient, err := client.Dial(client.Options{})
 	if err != nil {
@@ -62,20 +162,116 @@ func initService() (*Service, error) {
 	// initialise billing workflow
 	billingWorkflow := temporal.NewTemporalBillingWorkflow(temporalClient, billingWorkflowTaskQueue)
 
+	// initialise webhook delivery workflow
+	webhookDeliveryWorkflow := temporal.NewTemporalWebhookDeliveryWorkflow(temporalClient, billingWorkflowTaskQueue)
+
+	// initialise dunning workflow
+	dunningWorkflow := temporal.NewTemporalDunningWorkflow(temporalClient, billingWorkflowTaskQueue)
+
+	// initialise refund workflow
+	refundWorkflow := temporal.NewTemporalRefundWorkflow(temporalClient, billingWorkflowTaskQueue)
+
 	// initialise create billing usecase
 	createBillingUsecase := usecases.NewCreateBillingUseCase(fxService, billingWorkflow)
 
 	// initialise add line item usecase
-	addLineItemUsecase := usecases.NewAddLineItemUsecase(dbRepository, billingWorkflow)
+	addLineItemUsecase := usecases.NewAddLineItemUsecase(dbRepository, taxRepository, billingWorkflow)
 
 	// initialise close billing usecase
 	closeBillingUsecase := usecases.NewCloseBillingUseCase(dbRepository, billingWorkflow)
 
+	// initialise cancel billing usecase
+	cancelBillingUsecase := usecases.NewCancelBillingUseCase(dbRepository, billingWorkflow)
+
 	// initialise get billing summary usecase
 	getBillingSummaryUsecase := usecases.NewGetBillingSummaryUseCase(dbRepository, billingWorkflow)
 
+	// initialise prorate billing usecase
+	prorateBillingUsecase := usecases.NewProrateBillingUseCase(dbRepository, taxRepository, billingWorkflow)
+
+	// initialise add adjustment usecase
+	addAdjustmentUsecase := usecases.NewAddAdjustmentUseCase(dbRepository, taxRepository, billingWorkflow)
+
+	// initialise tax usecases
+	setTaxRateUsecase := usecases.NewSetTaxRateUseCase(taxRepository)
+	listTaxRatesUsecase := usecases.NewListTaxRatesUseCase(taxRepository)
+
+	// initialise coupon usecases
+	createCouponUsecase := usecases.NewCreateCouponUseCase(fxService, couponRepository)
+	applyCouponUsecase := usecases.NewApplyCouponUseCase(dbRepository, couponRepository, billingWorkflow)
+
+	// initialise credit note usecases
+	createCreditNoteUsecase := usecases.NewCreateCreditNoteUseCase(dbRepository, creditNoteRepository)
+	getCreditNoteUsecase := usecases.NewGetCreditNoteUseCase(creditNoteRepository, documentRenderer)
+	listCreditNotesUsecase := usecases.NewListCreditNotesUseCase(dbRepository, creditNoteRepository)
+
+	// initialise invoice number usecases
+	setInvoiceNumberSeriesUsecase := usecases.NewSetInvoiceNumberSeriesUseCase(invoiceNumberRepository)
+	listInvoiceNumberSeriesUsecase := usecases.NewListInvoiceNumberSeriesUseCase(invoiceNumberRepository)
+
+	// initialise invoice usecases
+	renderInvoiceUsecase := usecases.NewRenderInvoiceUseCase(dbRepository, invoiceTemplateRepository, fxService, documentRenderer)
+	setInvoiceTemplateUsecase := usecases.NewSetInvoiceTemplateUseCase(invoiceTemplateRepository, documentRenderer)
+	exportUBLInvoiceUsecase := usecases.NewExportUBLInvoiceUseCase(dbRepository, partyRepository, documentRenderer)
+	setPartyUsecase := usecases.NewSetPartyUseCase(partyRepository)
+
+	// initialise export usecase
+	exportLineItemsUsecase := usecases.NewExportLineItemsUseCase(exportRepository, documentRenderer)
+
+	// initialise webhook usecases
+	createWebhookUsecase := usecases.NewCreateWebhookUseCase(webhookRepository)
+	listWebhookDeliveriesUsecase := usecases.NewListWebhookDeliveriesUseCase(webhookRepository)
+	redeliverWebhookUsecase := usecases.NewRedeliverWebhookUseCase(webhookRepository, webhookDeliveryWorkflow)
+	dispatchWebhookEventUsecase := usecases.NewDispatchWebhookEventUseCase(webhookRepository, webhookDeliveryWorkflow)
+
+	// initialise outbox usecase
+	replayOutboxUsecase := usecases.NewReplayOutboxUseCase(outboxRepository, eventPublisher)
+
+	// initialise payment usecases
+	recordPaymentUsecase := usecases.NewRecordPaymentUseCase(dbRepository, creditNoteRepository, paymentRepository, dunningWorkflow)
+	listPaymentsUsecase := usecases.NewListPaymentsUseCase(dbRepository, creditNoteRepository, paymentRepository)
+
+	// initialise dunning usecases
+	startDunningUsecase := usecases.NewStartDunningUseCase(dbRepository, creditNoteRepository, paymentRepository, dunningRepository, dunningWorkflow)
+	getDunningUsecase := usecases.NewGetDunningUseCase(dbRepository, dunningRepository)
+
+	// initialise refund usecases
+	refundPaymentUsecase := usecases.NewRefundPaymentUseCase(paymentRepository, creditNoteRepository, refundRepository, refundWorkflow)
+	listRefundsUsecase := usecases.NewListRefundsUseCase(paymentRepository, refundRepository)
+
+	// initialise bank statement usecases
+	importBankStatementUsecase := usecases.NewImportBankStatementUseCase(fxService, bankStatementParser, bankStatementRepository, receivableRepository, recordPaymentUsecase)
+	getBankStatementUsecase := usecases.NewGetBankStatementUseCase(bankStatementRepository)
+	listBankTransactionsUsecase := usecases.NewListBankTransactionsUseCase(bankStatementRepository)
+	reviewBankTransactionUsecase := usecases.NewReviewBankTransactionUseCase(bankStatementRepository, recordPaymentUsecase)
+
+	// initialise report usecases
+	getAgingReportUsecase := usecases.NewGetAgingReportUseCase(fxService, receivableRepository)
+
+	// initialise ledger usecases
+	getLedgerBalancesUsecase := usecases.NewGetLedgerBalancesUseCase(ledgerRepository)
+	listJournalEntriesUsecase := usecases.NewListJournalEntriesUseCase(dbRepository, ledgerRepository)
+	checkLedgerUsecase := usecases.NewCheckLedgerUseCase(ledgerRepository)
+
+	// initialise wallet usecases
+	listWalletsUsecase := usecases.NewListWalletsUseCase(walletRepository)
+	creditWalletUsecase := usecases.NewCreditWalletUseCase(fxService, walletRepository)
+	listWalletTransactionsUsecase := usecases.NewListWalletTransactionsUseCase(walletRepository)
+
+	// initialise metering usecases
+	createMeterUsecase := usecases.NewCreateMeterUseCase(fxService, meterRepository)
+	listMetersUsecase := usecases.NewListMetersUseCase(meterRepository)
+	ingestUsageEventsUsecase := usecases.NewIngestUsageEventsUseCase(meterRepository)
+
+	// initialise subscription usecases
+	createPlanUsecase := usecases.NewCreatePlanUseCase(fxService, subscriptionRepository)
+	createSubscriptionUsecase := usecases.NewCreateSubscriptionUseCase(subscriptionRepository, billingWorkflow)
+	getSubscriptionUsecase := usecases.NewGetSubscriptionUseCase(subscriptionRepository)
+	changeSubscriptionPlanUsecase := usecases.NewChangeSubscriptionPlanUseCase(subscriptionRepository)
+	cancelSubscriptionUsecase := usecases.NewCancelSubscriptionUseCase(subscriptionRepository)
+
 	// initialise temporal activities
-	billingActivities := activities.NewBillingActivities(dbRepository, temporalClient, billingWorkflowTaskQueue)
+	billingActivities := activities.NewBillingActivities(dbRepository, subscriptionRepository, taxRepository, webhookRepository, outboxRepository, paymentRepository, creditNoteRepository, dunningRepository, refundRepository, meterRepository, couponRepository, eventPublisher, webhookSender, paymentGateway, temporalClient, billingWorkflowTaskQueue)
 	activities.SetActivityInstance(billingActivities)
 
 	// initialise temporal worker
@@ -83,12 +279,33 @@ func initService() (*Service, error) {
 
 	// register workflows
 	temporalWorker.RegisterWorkflow(workflows.BillingWorkflow)
+	temporalWorker.RegisterWorkflow(workflows.WebhookDeliveryWorkflow)
+	temporalWorker.RegisterWorkflow(workflows.OutboxRelayWorkflow)
+	temporalWorker.RegisterWorkflow(workflows.DunningWorkflow)
+	temporalWorker.RegisterWorkflow(workflows.RefundWorkflow)
 
 	// register activities
 	temporalWorker.RegisterActivity(activities.StartBillingActivityFunc)
 	temporalWorker.RegisterActivity(activities.AddLineItemActivityFunc)
+	temporalWorker.RegisterActivity(activities.RedeemCouponActivityFunc)
 	temporalWorker.RegisterActivity(activities.CloseBillingActivityFunc)
+	temporalWorker.RegisterActivity(activities.MeterUsageActivityFunc)
+	temporalWorker.RegisterActivity(activities.CancelBillingActivityFunc)
+	temporalWorker.RegisterActivity(activities.LinkNextBillingActivityFunc)
+	temporalWorker.RegisterActivity(activities.RecordSpendThresholdCrossedActivityFunc)
+	temporalWorker.RegisterActivity(activities.PrepareSubscriptionRenewalActivityFunc)
+	temporalWorker.RegisterActivity(activities.SetSubscriptionCurrentBillingActivityFunc)
+	temporalWorker.RegisterActivity(activities.GetTaxRatesActivityFunc)
 	temporalWorker.RegisterActivity(activities.CreateBillingSummaryActivityFunc)
+	temporalWorker.RegisterActivity(activities.ChargeBillingActivityFunc)
+	temporalWorker.RegisterActivity(activities.RetryBillingChargeActivityFunc)
+	temporalWorker.RegisterActivity(activities.StartDunningActivityFunc)
+	temporalWorker.RegisterActivity(activities.DunningStepActivityFunc)
+	temporalWorker.RegisterActivity(activities.ResolveDunningActivityFunc)
+	temporalWorker.RegisterActivity(activities.UpdateSubscriptionPaymentStatusActivityFunc)
+	temporalWorker.RegisterActivity(activities.RefundPaymentActivityFunc)
+	temporalWorker.RegisterActivity(activities.RelayOutboxActivityFunc)
+	temporalWorker.RegisterActivity(activities.DeliverWebhookActivityFunc)
 
 	// start worker in background
 	go func() {
@@ -100,11 +317,82 @@ func initService() (*Service, error) {
 
 	logger.Info("Temporal worker started", "taskQueue", billingWorkflowTaskQueue)
 
+	// start outbox relay
+	err = temporal.StartOutboxRelayWorkflow(context.Background(), temporalClient, billingWorkflowTaskQueue)
+	if err != nil {
+		logger.Error("failed to start outbox relay", "error", err)
+		return nil, err
+	}
+
 	return &Service{
 		createBillingUsecase:     createBillingUsecase,
 		addLineItemUsecase:       addLineItemUsecase,
 		closeBillingUsecase:      closeBillingUsecase,
+		cancelBillingUsecase:     cancelBillingUsecase,
 		getBillingSummaryUsecase: getBillingSummaryUsecase,
+		prorateBillingUsecase:    prorateBillingUsecase,
+		addAdjustmentUsecase:     addAdjustmentUsecase,
+
+		setTaxRateUsecase:   setTaxRateUsecase,
+		listTaxRatesUsecase: listTaxRatesUsecase,
+
+		createCouponUsecase: createCouponUsecase,
+		applyCouponUsecase:  applyCouponUsecase,
+
+		createCreditNoteUsecase: createCreditNoteUsecase,
+		getCreditNoteUsecase:    getCreditNoteUsecase,
+		listCreditNotesUsecase:  listCreditNotesUsecase,
+
+		setInvoiceNumberSeriesUsecase:  setInvoiceNumberSeriesUsecase,
+		listInvoiceNumberSeriesUsecase: listInvoiceNumberSeriesUsecase,
+
+		renderInvoiceUsecase:      renderInvoiceUsecase,
+		setInvoiceTemplateUsecase: setInvoiceTemplateUsecase,
+		exportUBLInvoiceUsecase:   exportUBLInvoiceUsecase,
+		setPartyUsecase:           setPartyUsecase,
+
+		exportLineItemsUsecase: exportLineItemsUsecase,
+
+		createWebhookUsecase:         createWebhookUsecase,
+		listWebhookDeliveriesUsecase: listWebhookDeliveriesUsecase,
+		redeliverWebhookUsecase:      redeliverWebhookUsecase,
+		dispatchWebhookEventUsecase:  dispatchWebhookEventUsecase,
+
+		replayOutboxUsecase: replayOutboxUsecase,
+
+		recordPaymentUsecase: recordPaymentUsecase,
+		listPaymentsUsecase:  listPaymentsUsecase,
+
+		startDunningUsecase: startDunningUsecase,
+		getDunningUsecase:   getDunningUsecase,
+
+		refundPaymentUsecase: refundPaymentUsecase,
+		listRefundsUsecase:   listRefundsUsecase,
+
+		importBankStatementUsecase:   importBankStatementUsecase,
+		getBankStatementUsecase:      getBankStatementUsecase,
+		listBankTransactionsUsecase:  listBankTransactionsUsecase,
+		reviewBankTransactionUsecase: reviewBankTransactionUsecase,
+
+		getAgingReportUsecase: getAgingReportUsecase,
+
+		getLedgerBalancesUsecase:  getLedgerBalancesUsecase,
+		listJournalEntriesUsecase: listJournalEntriesUsecase,
+		checkLedgerUsecase:        checkLedgerUsecase,
+
+		listWalletsUsecase:            listWalletsUsecase,
+		creditWalletUsecase:           creditWalletUsecase,
+		listWalletTransactionsUsecase: listWalletTransactionsUsecase,
+
+		createMeterUsecase:       createMeterUsecase,
+		listMetersUsecase:        listMetersUsecase,
+		ingestUsageEventsUsecase: ingestUsageEventsUsecase,
+
+		createPlanUsecase:             createPlanUsecase,
+		createSubscriptionUsecase:     createSubscriptionUsecase,
+		getSubscriptionUsecase:        getSubscriptionUsecase,
+		changeSubscriptionPlanUsecase: changeSubscriptionPlanUsecase,
+		cancelSubscriptionUsecase:     cancelSubscriptionUsecase,
 
 		client: temporalClient,
 		worker: temporalWorker,
@@ -149,8 +437,45 @@ func (s *Service) CreateBilling(ctx context.Context, req *CreateBillingRequest)
 		}
 	}
 
-	logger.Info("Creating billing", "description", req.Description, "currency", req.Currency, "plannedClosedAt", req.PlannedClosedAt)
-	billingID, err := s.createBillingUsecase.Execute(ctx, req.UserID, req.Description, req.Currency, req.PlannedClosedAt)
+	// map recurrence rule
+	var recurrence *entities.RecurrenceRule
+	if req.Recurrence != nil {
+		recurrence = &entities.RecurrenceRule{
+			Frequency:  req.Recurrence.Frequency,
+			Interval:   req.Recurrence.Interval,
+			DayOfMonth: req.Recurrence.DayOfMonth,
+			EndsAt:     req.Recurrence.EndsAt,
+			Timezone:   req.Recurrence.Timezone,
+		}
+	}
+
+	// map spend limit
+	var spendLimit *dto.SpendLimitInput
+	if req.SpendLimit != nil {
+		spendLimit = &dto.SpendLimitInput{
+			Amount:     req.SpendLimit.Amount,
+			Thresholds: req.SpendLimit.Thresholds,
+			Mode:       req.SpendLimit.Mode,
+		}
+	}
+
+	logger.Info("Creating billing", "description", req.Description, "currency", req.Currency, "plannedClosedAt", req.PlannedClosedAt, "recurrence", recurrence)
+	billingID, err := s.createBillingUsecase.Execute(ctx, dto.CreateBillingInput{
+		UserID:          req.UserID,
+		Description:     req.Description,
+		Currency:        req.Currency,
+		PlannedClosedAt: req.PlannedClosedAt,
+		Period:          req.Period,
+		Timezone:        req.Timezone,
+		Recurrence:      recurrence,
+		TaxJurisdiction: req.TaxJurisdiction,
+		TaxInclusive:    req.TaxInclusive,
+
+		AllowNegativeTotal: req.AllowNegativeTotal,
+		UserGroup:          req.UserGroup,
+		AutoCharge:         req.AutoCharge,
+		SpendLimit:         spendLimit,
+	})
 	if err != nil {
 		if errors.Is(err, dto.ErrCurrencyNotSupported) {
 			logger.Warn("currency not supported")
@@ -159,6 +484,41 @@ func (s *Service) CreateBilling(ctx context.Context, req *CreateBillingRequest)
 				Message: "currency not supported",
 			}
 		}
+		if errors.Is(err, dto.ErrInvalidRecurrenceRule) {
+			logger.Warn("recurrence rule is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "recurrence rule is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidBillingPeriod) {
+			logger.Warn("billing period is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "billing period is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidTimezone) {
+			logger.Warn("timezone is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "timezone is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidSpendLimit) {
+			logger.Warn("spend limit is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "spend limit is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrAmountHasTooManyDecimals) {
+			logger.Warn("spend limit amount has too many decimals")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "spend limit amount has too many decimals",
+			}
+		}
 		if errors.Is(err, dto.ErrFailedToGenerateBillingID) {
 			logger.Warn("failed to generate billing ID")
 			return nil, &errs.Error{
@@ -210,9 +570,13 @@ func (s *Service) AddLineItem(ctx context.Context, billingID string, req *AddLin
 		}
 	}
 
-	logger.Info("Adding line item to billing", "description", req.Description, "amount", req.Amount)
+	logger.Info("Adding line item to billing", "description", req.Description, "amount", req.Amount, "taxCode", req.TaxCode)
 
-	err := s.addLineItemUsecase.Execute(ctx, billingID, req.Description, req.Amount)
+	err := s.addLineItemUsecase.Execute(ctx, billingID, dto.AddLineItemInput{
+		Description: req.Description,
+		Amount:      req.Amount,
+		TaxCode:     req.TaxCode,
+	})
 	if err != nil {
 		if errors.Is(err, dto.ErrBillingNotFound) {
 			logger.Warn("billing not found")
@@ -235,6 +599,34 @@ func (s *Service) AddLineItem(ctx context.Context, billingID string, req *AddLin
 				Message: "billing is not open",
 			}
 		}
+		if errors.Is(err, dto.ErrBillingHasNoTaxJurisdiction) {
+			logger.Warn("billing has no tax jurisdiction")
+			return &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "billing has no tax jurisdiction",
+			}
+		}
+		if errors.Is(err, dto.ErrTaxRateNotFound) {
+			logger.Warn("tax rate not found")
+			return &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "tax code has no rate in the billing tax jurisdiction",
+			}
+		}
+		if errors.Is(err, dto.ErrSpendLimitExceeded) {
+			logger.Warn("line item exceeds spend limit")
+			return &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "line item exceeds the spend limit of the billing",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingClosedAtSpendLimit) {
+			logger.Warn("line item exceeds spend limit, billing closed")
+			return &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "line item exceeds the spend limit of the billing, the billing is closed without it",
+			}
+		}
 
 		logger.Error("failed to add line item", "error", err)
 		// unknown error
@@ -249,6 +641,142 @@ func (s *Service) AddLineItem(ctx context.Context, billingID string, req *AddLin
 	return nil
 }
 
+// encore:api private method=POST path=/billing/:billingID/prorate
+func (s *Service) ProrateBilling(ctx context.Context, billingID string, req *ProrateRequest) (*ProrateResponse, error) {
+	fn := "billing.Service.ProrateBilling"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	// validation amounts
+	if req.OldAmount < 0 || req.NewAmount < 0 {
+		logger.Warn("amounts must not be negative")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "amounts must not be negative",
+		}
+	}
+
+	// apply defaults
+	changeAt := time.Now().UTC()
+	if req.ChangeAt != nil {
+		changeAt = *req.ChangeAt
+	}
+	method := req.Method
+	if method == "" {
+		method = entities.ProrationMethodDay
+	}
+	oldQuantity, newQuantity := int64(1), int64(1)
+	if req.OldQuantity != nil {
+		oldQuantity = *req.OldQuantity
+	}
+	if req.NewQuantity != nil {
+		newQuantity = *req.NewQuantity
+	}
+
+	logger.Info("Prorating billing", "changeAt", changeAt, "method", method)
+
+	lineItems, err := s.prorateBillingUsecase.Execute(ctx, billingID, dto.ProrateInput{
+		ChangeAt:       changeAt,
+		Method:         method,
+		OldDescription: req.OldDescription,
+		OldUnitAmount:  req.OldAmount,
+		OldQuantity:    oldQuantity,
+		NewDescription: req.NewDescription,
+		NewUnitAmount:  req.NewAmount,
+		NewQuantity:    newQuantity,
+		TaxCode:        req.TaxCode,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingNotOpen) {
+			logger.Warn("billing is not open")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "billing is not open",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingHasNoPeriod) {
+			logger.Warn("billing has no period end")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing has no period end",
+			}
+		}
+		if errors.Is(err, dto.ErrAmountHasTooManyDecimals) {
+			logger.Warn("amount has too many decimals")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "amount has many decimals",
+			}
+		}
+		if errors.Is(err, dto.ErrChangeOutsidePeriod) {
+			logger.Warn("change is outside of the billing period")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "change is outside of the billing period",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidProration) {
+			logger.Warn("proration is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "proration is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingHasNoTaxJurisdiction) {
+			logger.Warn("billing has no tax jurisdiction")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "billing has no tax jurisdiction",
+			}
+		}
+		if errors.Is(err, dto.ErrTaxRateNotFound) {
+			logger.Warn("tax rate not found")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "tax code has no rate in the billing tax jurisdiction",
+			}
+		}
+		if errors.Is(err, dto.ErrSpendLimitExceeded) {
+			logger.Warn("proration exceeds spend limit")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "proration exceeds the spend limit of the billing",
+			}
+		}
+		if errors.Is(err, dto.ErrNegativeBillingTotal) {
+			logger.Warn("proration credit takes the billing total below zero")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "proration credit would take the billing total below zero",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to prorate billing", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to prorate billing",
+		}
+	}
+
+	logger.Info("Billing prorated successfully", "billingID", billingID)
+
+	response := make([]LineItem, len(lineItems))
+	for i, lineItem := range lineItems {
+		response[i] = lineItemResponse(lineItem)
+	}
+
+	return &ProrateResponse{
+		LineItems: response,
+	}, nil
+}
+
 // encore:api private method=POST path=/billing/:billingID/close
 func (s *Service) CloseBilling(ctx context.Context, billingID string) error {
 	fn := "billing.Service.CloseBilling"
@@ -296,6 +824,53 @@ func (s *Service) CloseBilling(ctx context.Context, billingID string) error {
 	return nil
 }
 
+// encore:api private method=POST path=/billing/:billingID/cancel
+func (s *Service) CancelBilling(ctx context.Context, billingID string, req *CancelBillingRequest) error {
+	fn := "billing.Service.CancelBilling"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("reason", req.Reason)
+
+	// validation billing ID
+	if billingID == "" {
+		logger.Warn("billing ID is invalid")
+
+		return &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "billing ID is required",
+		}
+	}
+
+	err := s.cancelBillingUsecase.Execute(ctx, billingID, req.Reason)
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+
+			return &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingNotOpen) {
+			logger.Warn("billing is not open")
+
+			return &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "billing is not open",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to cancel billing", "error", err)
+		return &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to cancel billing",
+		}
+	}
+
+	logger.Info("Billing cancelled successfully", "billingID", billingID)
+
+	return nil
+}
+
 // encore:api private method=GET path=/billing/:billingID/summary
 func (s *Service) GetBillingSummary(ctx context.Context, billingID string) (*GetBillingSummaryResponse, error) {
 	fn := "billing.Service.GetBillingSummary"
@@ -331,20 +906,79 @@ func (s *Service) GetBillingSummary(ctx context.Context, billingID string) (*Get
 
 	logger.Info("Billing summary retrieved successfully", "billingID", billingID)
 
-	lineItems := make([]LineItem, len(summary.LineItems))
-	for i, lineItem := range summary.LineItems {
-		lineItems[i] = LineItem{
-			Description: lineItem.Description,
-			AmountMinor: lineItem.AmountMinor,
+	// adjustments are shown under the line items they reference
+	groups := entities.GroupLineItems(summary.LineItems)
+	lineItems := make([]LineItem, len(groups))
+	for i, group := range groups {
+		lineItems[i] = lineItemResponse(group.LineItem)
+		for _, adjustment := range group.Adjustments {
+			lineItems[i].Adjustments = append(lineItems[i].Adjustments, lineItemResponse(adjustment))
+		}
+		lineItems[i].NetAmountMinor = group.NetAmountMinor
+	}
+
+	discounts := make([]Discount, len(summary.Discounts))
+	for i, discount := range summary.Discounts {
+		discounts[i] = Discount{
+			CouponCode:  discount.CouponCode,
+			Type:        discount.Type,
+			PercentOff:  float64(discount.PercentOffBPS) / 100,
+			AmountMinor: discount.AmountMinor,
+		}
+	}
+
+	taxes := make([]TaxBreakdown, len(summary.Taxes))
+	for i, tax := range summary.Taxes {
+		taxes[i] = TaxBreakdown{
+			Rate:               taxRatePercent(tax.RatePPM),
+			TaxableAmountMinor: tax.TaxableAmountMinor,
+			TaxAmountMinor:     tax.TaxAmountMinor,
 		}
 	}
 
 	return &GetBillingSummaryResponse{
 		ExternalBillingID: summary.ExternalBillingID,
+		InvoiceNumber:     summary.InvoiceNumber,
 		Description:       summary.Description,
 		Currency:          summary.Currency,
 		CurrencyPrecision: summary.CurrencyPrecision,
 		LineItems:         lineItems,
 		TotalAmountMinor:  summary.TotalAmountMinor,
+		PeriodStart:       summary.PeriodStart,
+		PeriodEnd:         summary.PeriodEnd,
+		Timezone:          summary.Timezone,
+		SubscriptionID:    summary.SubscriptionID,
+		PreviousBillingID: summary.PreviousBillingID,
+		NextBillingID:     summary.NextBillingID,
+
+		TaxJurisdiction:       summary.TaxJurisdiction,
+		TaxInclusive:          summary.TaxInclusive,
+		Discounts:             discounts,
+		DiscountAmountMinor:   summary.DiscountAmountMinor,
+		SubtotalAmountMinor:   summary.SubtotalAmountMinor,
+		Taxes:                 taxes,
+		TaxAmountMinor:        summary.TaxAmountMinor,
+		GrandTotalAmountMinor: summary.GrandTotalAmountMinor,
+
+		PaidFromCreditAmountMinor: summary.PaidFromCreditAmountMinor,
+		AmountDueMinor:            summary.AmountDueMinor(),
 	}, nil
 }
+
+// lineItemResponse converts a domain line item, its net amount is the amount until adjustments are grouped under it
+func lineItemResponse(lineItem entities.LineItem) LineItem {
+	return LineItem{
+		LineItemID:     lineItem.LineItemID,
+		Kind:           lineItem.Kind,
+		Description:    lineItem.Description,
+		AmountMinor:    lineItem.AmountMinor,
+		TaxCode:        lineItem.TaxCode,
+		TaxAmountMinor: lineItem.TaxAmountMinor,
+
+		DiscountAmountMinor: lineItem.DiscountAmountMinor,
+
+		Reason:              lineItem.Reason,
+		ReferenceLineItemID: lineItem.ReferenceLineItemID,
+		NetAmountMinor:      lineItem.AmountMinor,
+	}
+}
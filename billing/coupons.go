@@ -0,0 +1,160 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=POST path=/coupons
+func (s *Service) CreateCoupon(ctx context.Context, req *CreateCouponRequest) (*CreateCouponResponse, error) {
+	fn := "billing.Service.CreateCoupon"
+	logger := rlog.With("fn", fn).With("code", req.Code).With("type", req.Type)
+
+	// validate code
+	if req.Code == "" {
+		logger.Warn("code is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "code is required",
+		}
+	}
+
+	// validate amounts
+	if req.PercentOff < 0 || req.AmountOff < 0 {
+		logger.Warn("discount must not be negative")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "discount must not be negative",
+		}
+	}
+
+	code, err := s.createCouponUsecase.Execute(ctx, dto.CreateCouponInput{
+		Code:           req.Code,
+		Type:           req.Type,
+		PercentOff:     req.PercentOff,
+		AmountOff:      req.AmountOff,
+		Currency:       req.Currency,
+		ExpiresAt:      req.ExpiresAt,
+		MaxRedemptions: req.MaxRedemptions,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrCurrencyNotSupported) {
+			logger.Warn("currency not supported")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "currency not supported",
+			}
+		}
+		if errors.Is(err, dto.ErrAmountHasTooManyDecimals) {
+			logger.Warn("amount has too many decimals")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "amount has many decimals",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidCoupon) {
+			logger.Warn("coupon is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "coupon is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrCouponCodeTaken) {
+			logger.Warn("coupon code already exists")
+			return nil, &errs.Error{
+				Code:    errs.AlreadyExists,
+				Message: "coupon code already exists",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to create coupon", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to create coupon",
+		}
+	}
+
+	logger.Info("Coupon created successfully")
+
+	return &CreateCouponResponse{
+		Code: code,
+	}, nil
+}
+
+// encore:api private method=POST path=/billing/:billingID/coupon
+func (s *Service) ApplyCoupon(ctx context.Context, billingID string, req *ApplyCouponRequest) error {
+	fn := "billing.Service.ApplyCoupon"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("code", req.Code)
+
+	// validate code
+	if req.Code == "" {
+		logger.Warn("code is invalid")
+		return &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "code is required",
+		}
+	}
+
+	err := s.applyCouponUsecase.Execute(ctx, billingID, req.Code)
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingNotOpen) {
+			logger.Warn("billing is not open")
+			return &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "billing is not open",
+			}
+		}
+		if errors.Is(err, dto.ErrCouponNotFound) {
+			logger.Warn("coupon not found")
+			return &errs.Error{
+				Code:    errs.NotFound,
+				Message: "coupon not found",
+			}
+		}
+		if errors.Is(err, dto.ErrCouponCurrencyMismatch) {
+			logger.Warn("coupon currency does not match billing currency")
+			return &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "coupon currency does not match billing currency",
+			}
+		}
+		if errors.Is(err, dto.ErrCouponExpired) {
+			logger.Warn("coupon expired")
+			return &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "coupon expired",
+			}
+		}
+		if errors.Is(err, dto.ErrCouponRedemptionLimitReached) {
+			logger.Warn("coupon redemption limit reached")
+			return &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "coupon redemption limit reached",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to apply coupon", "error", err)
+		return &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to apply coupon",
+		}
+	}
+
+	logger.Info("Coupon applied successfully")
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"encore.dev"
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=POST path=/billing/:billingID/credit-notes
+func (s *Service) CreateCreditNote(ctx context.Context, billingID string, req *CreateCreditNoteRequest) (*CreditNote, error) {
+	fn := "billing.Service.CreateCreditNote"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	// validate reason
+	if req.Reason == "" {
+		logger.Warn("reason is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "reason is required",
+		}
+	}
+
+	// validate line items
+	if len(req.LineItems) == 0 {
+		logger.Warn("line items are required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "line items are required",
+		}
+	}
+	lineItems := make([]dto.CreditNoteLineItemInput, len(req.LineItems))
+	for i, lineItem := range req.LineItems {
+		if lineItem.Amount <= 0 {
+			logger.Warn("amount must be greater than 0")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "amount must be greater than 0",
+			}
+		}
+		lineItems[i] = dto.CreditNoteLineItemInput{
+			Description:         lineItem.Description,
+			Amount:              lineItem.Amount,
+			ReferenceLineItemID: lineItem.ReferenceLineItemID,
+		}
+	}
+
+	creditNote, err := s.createCreditNoteUsecase.Execute(ctx, billingID, dto.CreateCreditNoteInput{
+		Reason:    req.Reason,
+		LineItems: lineItems,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingNotClosed) {
+			logger.Warn("billing is not closed")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing is not closed",
+			}
+		}
+		if errors.Is(err, dto.ErrAmountHasTooManyDecimals) {
+			logger.Warn("amount has too many decimals")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "amount has many decimals",
+			}
+		}
+		if errors.Is(err, dto.ErrReferencedLineItemNotFound) {
+			logger.Warn("referenced line item not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "referenced line item not found",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidCreditNote) {
+			logger.Warn("credit note is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "credit note is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrCreditNoteExceedsBalance) {
+			logger.Warn("credit note exceeds the remaining balance")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "credit note exceeds the remaining balance of the billing",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to create credit note", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to create credit note",
+		}
+	}
+
+	logger.Info("Credit note created successfully", "creditNoteID", creditNote.ExternalCreditNoteID, "number", creditNote.Number)
+
+	return creditNoteResponse(creditNote), nil
+}
+
+// encore:api private method=GET path=/billing/:billingID/credit-notes
+func (s *Service) ListCreditNotes(ctx context.Context, billingID string) (*ListCreditNotesResponse, error) {
+	fn := "billing.Service.ListCreditNotes"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	creditNotes, balance, err := s.listCreditNotesUsecase.Execute(ctx, billingID)
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingNotClosed) {
+			logger.Warn("billing is not closed")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing is not closed",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to list credit notes", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list credit notes",
+		}
+	}
+
+	response := make([]CreditNote, len(creditNotes))
+	for i := range creditNotes {
+		response[i] = *creditNoteResponse(&creditNotes[i])
+	}
+
+	return &ListCreditNotesResponse{
+		CreditNotes:         response,
+		BilledAmountMinor:   balance.BilledAmountMinor,
+		CreditedAmountMinor: balance.CreditedAmountMinor,
+		NetAmountMinor:      balance.NetAmountMinor,
+	}, nil
+}
+
+// encore:api private method=GET path=/credit-notes/:creditNoteID
+func (s *Service) GetCreditNote(ctx context.Context, creditNoteID string) (*CreditNote, error) {
+	fn := "billing.Service.GetCreditNote"
+	logger := rlog.With("fn", fn).With("creditNoteID", creditNoteID)
+
+	creditNote, err := s.getCreditNoteUsecase.Execute(ctx, creditNoteID)
+	if err != nil {
+		if errors.Is(err, dto.ErrCreditNoteNotFound) {
+			logger.Warn("credit note not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "credit note not found",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to get credit note", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to get credit note",
+		}
+	}
+
+	return creditNoteResponse(creditNote), nil
+}
+
+// encore:api private raw method=GET path=/credit-notes/:creditNoteID/pdf
+func (s *Service) GetCreditNotePDF(w http.ResponseWriter, req *http.Request) {
+	fn := "billing.Service.GetCreditNotePDF"
+	creditNoteID := encore.CurrentRequest().PathParams.Get("creditNoteID")
+	logger := rlog.With("fn", fn).With("creditNoteID", creditNoteID)
+
+	creditNote, pdf, err := s.getCreditNoteUsecase.ExecutePDF(req.Context(), creditNoteID)
+	if err != nil {
+		if errors.Is(err, dto.ErrCreditNoteNotFound) {
+			logger.Warn("credit note not found")
+			errs.HTTPError(w, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "credit note not found",
+			})
+			return
+		}
+
+		// unknown error
+		logger.Error("failed to render credit note", "error", err)
+		errs.HTTPError(w, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to render credit note",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="`+creditNote.Number+`.pdf"`)
+	_, err = w.Write(pdf)
+	if err != nil {
+		logger.Error("failed to write credit note", "error", err)
+	}
+}
+
+func creditNoteResponse(creditNote *entities.CreditNote) *CreditNote {
+	lineItems := make([]CreditNoteLineItem, len(creditNote.LineItems))
+	for i, lineItem := range creditNote.LineItems {
+		lineItems[i] = CreditNoteLineItem{
+			Description:         lineItem.Description,
+			AmountMinor:         lineItem.AmountMinor,
+			ReferenceLineItemID: lineItem.ReferenceLineItemID,
+		}
+	}
+
+	return &CreditNote{
+		CreditNoteID:      creditNote.ExternalCreditNoteID,
+		Number:            creditNote.Number,
+		BillingID:         creditNote.ExternalBillingID,
+		Currency:          creditNote.Currency,
+		CurrencyPrecision: creditNote.CurrencyPrecision,
+		Reason:            creditNote.Reason,
+		LineItems:         lineItems,
+		TotalAmountMinor:  creditNote.TotalAmountMinor,
+		CreatedAt:         creditNote.CreatedAt,
+	}
+}
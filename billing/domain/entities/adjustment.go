@@ -0,0 +1,106 @@
+package entities
+
+type LineItemKind = string
+
+const (
+	LineItemKindCharge     LineItemKind = "charge"
+	LineItemKindAdjustment LineItemKind = "adjustment"
+)
+
+// IsAdjustment reports whether the line item is a credit adjustment rather than a charge
+func (l *LineItem) IsAdjustment() bool {
+	return l.Kind == LineItemKindAdjustment
+}
+
+// ValidateAdjustment checks an adjustment against the line items already on the billing:
+// it must carry a negative amount and a reason, a referenced item must be a charge of the billing
+// that the adjustment does not credit beyond what is left of it, and the billing total may only
+// go below zero when allowNegativeTotal is set.
+func ValidateAdjustment(lineItems []LineItem, adjustment LineItem, allowNegativeTotal bool) error {
+	if adjustment.AmountMinor >= 0 {
+		return ErrInvalidAdjustment
+	}
+	if adjustment.Reason == "" {
+		return ErrAdjustmentReasonRequired
+	}
+
+	if adjustment.ReferenceLineItemID != "" {
+		referenced := findLineItem(lineItems, adjustment.ReferenceLineItemID)
+		if referenced == nil || referenced.IsAdjustment() {
+			return ErrReferencedLineItemNotFound
+		}
+
+		remainingMinor := referenced.AmountMinor
+		for _, lineItem := range lineItems {
+			if lineItem.IsAdjustment() && lineItem.ReferenceLineItemID == referenced.LineItemID {
+				remainingMinor += lineItem.AmountMinor
+			}
+		}
+		if remainingMinor+adjustment.AmountMinor < 0 {
+			return ErrAdjustmentExceedsReferencedItem
+		}
+	}
+
+	return ValidateBillingTotal(lineItems, adjustment, allowNegativeTotal)
+}
+
+// ValidateBillingTotal checks that a line item, whatever its kind, only takes the billing total below zero when
+// allowNegativeTotal is set, so that proration credits and other negative charges follow the policy of adjustments
+func ValidateBillingTotal(lineItems []LineItem, lineItem LineItem, allowNegativeTotal bool) error {
+	if allowNegativeTotal || lineItem.AmountMinor >= 0 {
+		return nil
+	}
+
+	var totalMinor int64
+	for _, item := range lineItems {
+		totalMinor += item.AmountMinor
+	}
+	if totalMinor+lineItem.AmountMinor < 0 {
+		return ErrNegativeBillingTotal
+	}
+
+	return nil
+}
+
+// LineItemGroup is a line item with the adjustments that reference it
+type LineItemGroup struct {
+	LineItem       LineItem   `json:"line_item"`
+	Adjustments    []LineItem `json:"adjustments,omitempty"`
+	NetAmountMinor int64      `json:"net_amount_minor"`
+}
+
+// GroupLineItems nests adjustments under the items they reference, in the order the items were added.
+// Adjustments without a reference, or referencing an unknown item, form a group of their own.
+func GroupLineItems(lineItems []LineItem) []LineItemGroup {
+	groups := []LineItemGroup{}
+	groupIndexByID := map[string]int{}
+
+	for _, lineItem := range lineItems {
+		if lineItem.IsAdjustment() && lineItem.ReferenceLineItemID != "" {
+			if i, ok := groupIndexByID[lineItem.ReferenceLineItemID]; ok {
+				groups[i].Adjustments = append(groups[i].Adjustments, lineItem)
+				groups[i].NetAmountMinor += lineItem.AmountMinor
+				continue
+			}
+		}
+
+		if !lineItem.IsAdjustment() && lineItem.LineItemID != "" {
+			groupIndexByID[lineItem.LineItemID] = len(groups)
+		}
+		groups = append(groups, LineItemGroup{
+			LineItem:       lineItem,
+			NetAmountMinor: lineItem.AmountMinor,
+		})
+	}
+
+	return groups
+}
+
+func findLineItem(lineItems []LineItem, lineItemID string) *LineItem {
+	for i := range lineItems {
+		if lineItems[i].LineItemID == lineItemID {
+			return &lineItems[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package entities
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateAdjustment(t *testing.T) {
+	lineItems := []LineItem{
+		{LineItemID: "a", Kind: LineItemKindCharge, AmountMinor: 1000},
+		{LineItemID: "b", Kind: LineItemKindCharge, AmountMinor: 500},
+		{LineItemID: "c", Kind: LineItemKindAdjustment, AmountMinor: -300, Reason: "goodwill", ReferenceLineItemID: "a"},
+	}
+
+	tests := []struct {
+		name               string
+		adjustment         LineItem
+		allowNegativeTotal bool
+		expected           error
+	}{
+		{
+			name:       "unreferenced credit",
+			adjustment: LineItem{Kind: LineItemKindAdjustment, AmountMinor: -200, Reason: "goodwill"},
+			expected:   nil,
+		},
+		{
+			name:       "credit up to what is left of the referenced item",
+			adjustment: LineItem{Kind: LineItemKindAdjustment, AmountMinor: -700, Reason: "correction", ReferenceLineItemID: "a"},
+			expected:   nil,
+		},
+		{
+			name:       "credit beyond what is left of the referenced item",
+			adjustment: LineItem{Kind: LineItemKindAdjustment, AmountMinor: -701, Reason: "correction", ReferenceLineItemID: "a"},
+			expected:   ErrAdjustmentExceedsReferencedItem,
+		},
+		{
+			name:       "reference to an unknown item",
+			adjustment: LineItem{Kind: LineItemKindAdjustment, AmountMinor: -100, Reason: "correction", ReferenceLineItemID: "z"},
+			expected:   ErrReferencedLineItemNotFound,
+		},
+		{
+			name:       "reference to another adjustment",
+			adjustment: LineItem{Kind: LineItemKindAdjustment, AmountMinor: -100, Reason: "correction", ReferenceLineItemID: "c"},
+			expected:   ErrReferencedLineItemNotFound,
+		},
+		{
+			name:       "missing reason",
+			adjustment: LineItem{Kind: LineItemKindAdjustment, AmountMinor: -100},
+			expected:   ErrAdjustmentReasonRequired,
+		},
+		{
+			name:       "positive amount",
+			adjustment: LineItem{Kind: LineItemKindAdjustment, AmountMinor: 100, Reason: "goodwill"},
+			expected:   ErrInvalidAdjustment,
+		},
+		{
+			name:       "total below zero",
+			adjustment: LineItem{Kind: LineItemKindAdjustment, AmountMinor: -1201, Reason: "goodwill"},
+			expected:   ErrNegativeBillingTotal,
+		},
+		{
+			name:               "total below zero when allowed",
+			adjustment:         LineItem{Kind: LineItemKindAdjustment, AmountMinor: -1201, Reason: "goodwill"},
+			allowNegativeTotal: true,
+			expected:           nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateAdjustment(lineItems, tt.adjustment, tt.allowNegativeTotal)
+			if !errors.Is(result, tt.expected) {
+				t.Errorf("ValidateAdjustment() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateBillingTotal(t *testing.T) {
+	lineItems := []LineItem{
+		{LineItemID: "a", Kind: LineItemKindCharge, AmountMinor: 1000},
+	}
+
+	tests := []struct {
+		name               string
+		lineItem           LineItem
+		allowNegativeTotal bool
+		expected           error
+	}{
+		{
+			name:     "negative charge down to zero",
+			lineItem: LineItem{Kind: LineItemKindCharge, AmountMinor: -1000},
+			expected: nil,
+		},
+		{
+			name:     "negative charge below zero",
+			lineItem: LineItem{Kind: LineItemKindCharge, AmountMinor: -1001},
+			expected: ErrNegativeBillingTotal,
+		},
+		{
+			name:               "negative charge below zero when allowed",
+			lineItem:           LineItem{Kind: LineItemKindCharge, AmountMinor: -1001},
+			allowNegativeTotal: true,
+			expected:           nil,
+		},
+		{
+			name:     "positive charge",
+			lineItem: LineItem{Kind: LineItemKindCharge, AmountMinor: 100},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateBillingTotal(lineItems, tt.lineItem, tt.allowNegativeTotal)
+			if !errors.Is(result, tt.expected) {
+				t.Errorf("ValidateBillingTotal() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGroupLineItems(t *testing.T) {
+	a := LineItem{LineItemID: "a", Kind: LineItemKindCharge, AmountMinor: 1000}
+	b := LineItem{LineItemID: "b", Kind: LineItemKindCharge, AmountMinor: 500}
+	creditA := LineItem{LineItemID: "c", Kind: LineItemKindAdjustment, AmountMinor: -300, Reason: "goodwill", ReferenceLineItemID: "a"}
+	credit := LineItem{LineItemID: "d", Kind: LineItemKindAdjustment, AmountMinor: -100, Reason: "goodwill"}
+	correctionA := LineItem{LineItemID: "e", Kind: LineItemKindAdjustment, AmountMinor: -200, Reason: "correction", ReferenceLineItemID: "a"}
+
+	result := GroupLineItems([]LineItem{a, b, creditA, credit, correctionA})
+	expected := []LineItemGroup{
+		{LineItem: a, Adjustments: []LineItem{creditA, correctionA}, NetAmountMinor: 500},
+		{LineItem: b, NetAmountMinor: 500},
+		{LineItem: credit, NetAmountMinor: -100},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("GroupLineItems() = %+v, expected %+v", result, expected)
+	}
+}
@@ -0,0 +1,190 @@
+package entities
+
+import (
+	"sort"
+	"time"
+)
+
+type AgingBucket string
+
+const (
+	AgingBucketCurrent AgingBucket = "current"
+	AgingBucket1To30   AgingBucket = "1_30"
+	AgingBucket31To60  AgingBucket = "31_60"
+	AgingBucket61To90  AgingBucket = "61_90"
+	AgingBucketOver90  AgingBucket = "90_plus"
+)
+
+// MaxPaymentTermsDays is the longest payment term an aging report accepts
+const MaxPaymentTermsDays = 365
+
+const agingDay = 24 * time.Hour
+
+// AgingBucketFor returns the bucket of a receivable that is daysPastDue days past its due date, one that is not yet due is current
+func AgingBucketFor(daysPastDue int) AgingBucket {
+	switch {
+	case daysPastDue <= 0:
+		return AgingBucketCurrent
+	case daysPastDue <= 30:
+		return AgingBucket1To30
+	case daysPastDue <= 60:
+		return AgingBucket31To60
+	case daysPastDue <= 90:
+		return AgingBucket61To90
+	default:
+		return AgingBucketOver90
+	}
+}
+
+// DaysPastDue returns the calendar days in UTC from the due date of a billing closed at closedAt to asOf. Billings are due
+// on receipt of the invoice, paymentTermsDays moves the due date later.
+func DaysPastDue(closedAt time.Time, paymentTermsDays int, asOf time.Time) int {
+	dueDate := closedAt.UTC().Truncate(agingDay).AddDate(0, 0, paymentTermsDays)
+	return int(asOf.UTC().Truncate(agingDay).Sub(dueDate) / agingDay)
+}
+
+// AgingAmounts are outstanding amounts in minor units split by how long they are past due
+type AgingAmounts struct {
+	CurrentAmountMinor    int64 `json:"current_amount_minor"`
+	Days1To30AmountMinor  int64 `json:"days_1_30_amount_minor"`
+	Days31To60AmountMinor int64 `json:"days_31_60_amount_minor"`
+	Days61To90AmountMinor int64 `json:"days_61_90_amount_minor"`
+	Over90DaysAmountMinor int64 `json:"days_90_plus_amount_minor"`
+	TotalAmountMinor      int64 `json:"total_amount_minor"`
+}
+
+// Add adds an amount to a bucket and to the total
+func (a *AgingAmounts) Add(bucket AgingBucket, amountMinor int64) {
+	switch bucket {
+	case AgingBucketCurrent:
+		a.CurrentAmountMinor += amountMinor
+	case AgingBucket1To30:
+		a.Days1To30AmountMinor += amountMinor
+	case AgingBucket31To60:
+		a.Days31To60AmountMinor += amountMinor
+	case AgingBucket61To90:
+		a.Days61To90AmountMinor += amountMinor
+	default:
+		a.Over90DaysAmountMinor += amountMinor
+	}
+	a.TotalAmountMinor += amountMinor
+}
+
+// Merge adds the amounts of other bucket by bucket
+func (a *AgingAmounts) Merge(other AgingAmounts) {
+	a.CurrentAmountMinor += other.CurrentAmountMinor
+	a.Days1To30AmountMinor += other.Days1To30AmountMinor
+	a.Days31To60AmountMinor += other.Days31To60AmountMinor
+	a.Days61To90AmountMinor += other.Days61To90AmountMinor
+	a.Over90DaysAmountMinor += other.Over90DaysAmountMinor
+	a.TotalAmountMinor += other.TotalAmountMinor
+}
+
+// Convert converts every bucket between currencies, the total is the sum of the converted buckets so that it always adds up
+func (a AgingAmounts) Convert(fromPrecision int64, from CurrencyRate, toPrecision int64, to CurrencyRate) AgingAmounts {
+	convert := func(amountMinor int64) int64 {
+		return ConvertAmountMinor(amountMinor, fromPrecision, from, toPrecision, to)
+	}
+
+	converted := AgingAmounts{
+		CurrentAmountMinor:    convert(a.CurrentAmountMinor),
+		Days1To30AmountMinor:  convert(a.Days1To30AmountMinor),
+		Days31To60AmountMinor: convert(a.Days31To60AmountMinor),
+		Days61To90AmountMinor: convert(a.Days61To90AmountMinor),
+		Over90DaysAmountMinor: convert(a.Over90DaysAmountMinor),
+	}
+	converted.TotalAmountMinor = converted.CurrentAmountMinor + converted.Days1To30AmountMinor + converted.Days31To60AmountMinor +
+		converted.Days61To90AmountMinor + converted.Over90DaysAmountMinor
+	return converted
+}
+
+// AgingRow is what a user owes in a currency
+type AgingRow struct {
+	UserID            string `json:"user_id"`
+	Currency          string `json:"currency"`
+	CurrencyPrecision int64  `json:"currency_precision"`
+	BillingCount      int    `json:"billing_count"`
+
+	Amounts AgingAmounts `json:"amounts"`
+	// ReportingAmounts are the amounts in the reporting currency of the report, if any
+	ReportingAmounts *AgingAmounts `json:"reporting_amounts,omitempty"`
+}
+
+// AgingReport is the accounts receivable aging of the closed billings with something outstanding at AsOf
+type AgingReport struct {
+	AsOf             time.Time  `json:"as_of"`
+	PaymentTermsDays int        `json:"payment_terms_days"`
+	Rows             []AgingRow `json:"rows"`
+
+	ReportingCurrency          string        `json:"reporting_currency,omitempty"`
+	ReportingCurrencyPrecision int64         `json:"reporting_currency_precision,omitempty"`
+	ReportingTotals            *AgingAmounts `json:"reporting_totals,omitempty"`
+}
+
+// NewAgingReport buckets what is outstanding on each receivable by days past due at asOf and sums it per user and currency,
+// rows are sorted by user then currency
+func NewAgingReport(receivables []Receivable, asOf time.Time, paymentTermsDays int) *AgingReport {
+	type rowKey struct {
+		userID   string
+		currency string
+	}
+
+	rows := map[rowKey]*AgingRow{}
+	for _, receivable := range receivables {
+		if receivable.OutstandingAmountMinor <= 0 {
+			continue
+		}
+
+		key := rowKey{userID: receivable.UserID, currency: receivable.Currency}
+		row, ok := rows[key]
+		if !ok {
+			row = &AgingRow{UserID: receivable.UserID, Currency: receivable.Currency, CurrencyPrecision: receivable.CurrencyPrecision}
+			rows[key] = row
+		}
+
+		row.BillingCount++
+		row.Amounts.Add(AgingBucketFor(DaysPastDue(receivable.ClosedAt, paymentTermsDays, asOf)), receivable.OutstandingAmountMinor)
+	}
+
+	report := &AgingReport{
+		AsOf:             asOf,
+		PaymentTermsDays: paymentTermsDays,
+		Rows:             make([]AgingRow, 0, len(rows)),
+	}
+	for _, row := range rows {
+		report.Rows = append(report.Rows, *row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if report.Rows[i].UserID != report.Rows[j].UserID {
+			return report.Rows[i].UserID < report.Rows[j].UserID
+		}
+		return report.Rows[i].Currency < report.Rows[j].Currency
+	})
+
+	return report
+}
+
+// ConvertTo converts every row to the reporting currency with rates quoted per USD and totals the converted rows
+func (r *AgingReport) ConvertTo(currency string, precision int64, rates map[string]CurrencyRate) error {
+	to, ok := rates[currency]
+	if !ok {
+		return ErrFxRateNotFound
+	}
+
+	totals := AgingAmounts{}
+	for i := range r.Rows {
+		from, ok := rates[r.Rows[i].Currency]
+		if !ok {
+			return ErrFxRateNotFound
+		}
+
+		converted := r.Rows[i].Amounts.Convert(r.Rows[i].CurrencyPrecision, from, precision, to)
+		r.Rows[i].ReportingAmounts = &converted
+		totals.Merge(converted)
+	}
+
+	r.ReportingCurrency = currency
+	r.ReportingCurrencyPrecision = precision
+	r.ReportingTotals = &totals
+	return nil
+}
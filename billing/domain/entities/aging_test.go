@@ -0,0 +1,125 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAgingBucketFor(t *testing.T) {
+	tests := []struct {
+		daysPastDue int
+		expected    AgingBucket
+	}{
+		{daysPastDue: -5, expected: AgingBucketCurrent},
+		{daysPastDue: 0, expected: AgingBucketCurrent},
+		{daysPastDue: 1, expected: AgingBucket1To30},
+		{daysPastDue: 30, expected: AgingBucket1To30},
+		{daysPastDue: 31, expected: AgingBucket31To60},
+		{daysPastDue: 60, expected: AgingBucket31To60},
+		{daysPastDue: 61, expected: AgingBucket61To90},
+		{daysPastDue: 90, expected: AgingBucket61To90},
+		{daysPastDue: 91, expected: AgingBucketOver90},
+	}
+
+	for _, tt := range tests {
+		if got := AgingBucketFor(tt.daysPastDue); got != tt.expected {
+			t.Errorf("AgingBucketFor(%v) = %v, expected %v", tt.daysPastDue, got, tt.expected)
+		}
+	}
+}
+
+func TestDaysPastDue(t *testing.T) {
+	asOf := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		closedAt         time.Time
+		paymentTermsDays int
+		expected         int
+	}{
+		{name: "closed on the day", closedAt: time.Date(2024, 3, 31, 23, 0, 0, 0, time.UTC), expected: 0},
+		{name: "closed the day before late at night", closedAt: time.Date(2024, 3, 30, 23, 59, 0, 0, time.UTC), expected: 1},
+		{name: "closed a month before", closedAt: time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC), expected: 31},
+		{name: "within payment terms", closedAt: time.Date(2024, 3, 20, 8, 0, 0, 0, time.UTC), paymentTermsDays: 30, expected: -19},
+		{name: "past payment terms", closedAt: time.Date(2024, 2, 20, 8, 0, 0, 0, time.UTC), paymentTermsDays: 30, expected: 10},
+		{name: "closed in another timezone", closedAt: time.Date(2024, 3, 31, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DaysPastDue(tt.closedAt, tt.paymentTermsDays, asOf); got != tt.expected {
+				t.Errorf("DaysPastDue() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewAgingReport(t *testing.T) {
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return asOf.AddDate(0, 0, -days) }
+
+	receivables := []Receivable{
+		{UserID: "user-2", Currency: "USD", CurrencyPrecision: 2, OutstandingAmountMinor: 500, ClosedAt: daysAgo(0)},
+		{UserID: "user-1", Currency: "USD", CurrencyPrecision: 2, OutstandingAmountMinor: 1000, ClosedAt: daysAgo(10)},
+		{UserID: "user-1", Currency: "USD", CurrencyPrecision: 2, OutstandingAmountMinor: 2000, ClosedAt: daysAgo(45)},
+		{UserID: "user-1", Currency: "USD", CurrencyPrecision: 2, OutstandingAmountMinor: 3000, ClosedAt: daysAgo(75)},
+		{UserID: "user-1", Currency: "USD", CurrencyPrecision: 2, OutstandingAmountMinor: 4000, ClosedAt: daysAgo(120)},
+		{UserID: "user-1", Currency: "GEL", CurrencyPrecision: 2, OutstandingAmountMinor: 2700, ClosedAt: daysAgo(5)},
+		{UserID: "user-1", Currency: "GEL", CurrencyPrecision: 2, OutstandingAmountMinor: 0, ClosedAt: daysAgo(5)},
+	}
+
+	report := NewAgingReport(receivables, asOf, 0)
+
+	expected := []AgingRow{
+		{UserID: "user-1", Currency: "GEL", CurrencyPrecision: 2, BillingCount: 1, Amounts: AgingAmounts{Days1To30AmountMinor: 2700, TotalAmountMinor: 2700}},
+		{UserID: "user-1", Currency: "USD", CurrencyPrecision: 2, BillingCount: 4, Amounts: AgingAmounts{
+			Days1To30AmountMinor: 1000, Days31To60AmountMinor: 2000, Days61To90AmountMinor: 3000, Over90DaysAmountMinor: 4000, TotalAmountMinor: 10000,
+		}},
+		{UserID: "user-2", Currency: "USD", CurrencyPrecision: 2, BillingCount: 1, Amounts: AgingAmounts{CurrentAmountMinor: 500, TotalAmountMinor: 500}},
+	}
+	if len(report.Rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(report.Rows))
+	}
+	for i := range expected {
+		if report.Rows[i] != expected[i] {
+			t.Errorf("row %d = %+v, expected %+v", i, report.Rows[i], expected[i])
+		}
+	}
+
+	// payment terms move billings back into earlier buckets
+	withTerms := NewAgingReport(receivables, asOf, 30)
+	if got := withTerms.Rows[1].Amounts; got.CurrentAmountMinor != 1000 || got.Days1To30AmountMinor != 2000 || got.Days31To60AmountMinor != 3000 || got.Days61To90AmountMinor != 4000 {
+		t.Errorf("unexpected amounts with payment terms: %+v", got)
+	}
+}
+
+func TestAgingReport_ConvertTo(t *testing.T) {
+	rates := map[string]CurrencyRate{
+		"USD": {Rate: 100, Precision: 2},
+		"GEL": {Rate: 270, Precision: 2},
+	}
+	report := &AgingReport{Rows: []AgingRow{
+		{UserID: "user-1", Currency: "GEL", CurrencyPrecision: 2, Amounts: AgingAmounts{CurrentAmountMinor: 1000, Days1To30AmountMinor: 1000, TotalAmountMinor: 2000}},
+		{UserID: "user-1", Currency: "USD", CurrencyPrecision: 2, Amounts: AgingAmounts{Over90DaysAmountMinor: 500, TotalAmountMinor: 500}},
+	}}
+
+	if err := report.ConvertTo("USD", 2, rates); err != nil {
+		t.Fatalf("ConvertTo failed: %v", err)
+	}
+
+	// each bucket is rounded on its own and the total adds the rounded buckets
+	if got := *report.Rows[0].ReportingAmounts; got != (AgingAmounts{CurrentAmountMinor: 370, Days1To30AmountMinor: 370, TotalAmountMinor: 740}) {
+		t.Errorf("unexpected converted amounts: %+v", got)
+	}
+	if got := *report.ReportingTotals; got != (AgingAmounts{CurrentAmountMinor: 370, Days1To30AmountMinor: 370, Over90DaysAmountMinor: 500, TotalAmountMinor: 1240}) {
+		t.Errorf("unexpected reporting totals: %+v", got)
+	}
+	if report.ReportingCurrency != "USD" || report.ReportingCurrencyPrecision != 2 {
+		t.Errorf("unexpected reporting currency %v with precision %v", report.ReportingCurrency, report.ReportingCurrencyPrecision)
+	}
+
+	if err := report.ConvertTo("EUR", 2, rates); !errors.Is(err, ErrFxRateNotFound) {
+		t.Errorf("expected ErrFxRateNotFound, got %v", err)
+	}
+}
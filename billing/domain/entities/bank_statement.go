@@ -0,0 +1,201 @@
+package entities
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+type BankStatementFormat = string
+
+const (
+	BankStatementFormatCamt053 BankStatementFormat = "camt053"
+	BankStatementFormatCSV     BankStatementFormat = "csv"
+)
+
+type BankTransactionDirection = string
+
+const (
+	BankTransactionDirectionCredit BankTransactionDirection = "credit"
+	BankTransactionDirectionDebit  BankTransactionDirection = "debit"
+)
+
+type BankTransactionStatus = string
+
+const (
+	BankTransactionStatusMatched     BankTransactionStatus = "matched"
+	BankTransactionStatusNeedsReview BankTransactionStatus = "needs_review"
+	BankTransactionStatusIgnored     BankTransactionStatus = "ignored"
+)
+
+// BankTransactionMatchRule is how a transaction was matched to a receivable
+type BankTransactionMatchRule = string
+
+const (
+	BankTransactionMatchRuleInvoiceNumber      BankTransactionMatchRule = "invoice_number"
+	BankTransactionMatchRuleAmountAndReference BankTransactionMatchRule = "amount_and_reference"
+	BankTransactionMatchRuleManual             BankTransactionMatchRule = "manual"
+)
+
+// BankStatementMaxSize is the largest statement file that can be imported, in bytes
+const BankStatementMaxSize = 10 << 20
+
+// ParsedBankStatement is a bank statement file read by a parser, before its amounts are converted to minor units
+type ParsedBankStatement struct {
+	// Reference identifies the statement at the bank, e.g. the Stmt/Id of a camt.053 statement
+	Reference string
+	Account   string
+	Entries   []BankStatementEntry
+}
+
+// BankStatementEntry is a booked transaction of a statement file
+type BankStatementEntry struct {
+	// BankReference identifies the transaction at the bank, it becomes the reference of the payment it is recorded as
+	BankReference    string
+	BookingDate      time.Time
+	Direction        BankTransactionDirection
+	Amount           float64
+	Currency         string
+	CounterpartyName string
+
+	// RemittanceInfo is the reference the payer gave, where invoice numbers are looked for
+	RemittanceInfo string
+}
+
+// AmountMinor converts the amount of the entry to minor units of a currency precision
+func (e *BankStatementEntry) AmountMinor(precision int64) (int64, error) {
+	if e.Amount <= 0 || !hasAtMostXDecimals(e.Amount, precision) {
+		return 0, ErrInvalidBankStatement
+	}
+	return int64(math.Round(e.Amount * math.Pow10(int(precision)))), nil
+}
+
+// BankStatement is an imported statement file, a file imported again returns the first import
+type BankStatement struct {
+	ID                  int64               `json:"id"`
+	ExternalStatementID string              `json:"statement_id"`
+	Format              BankStatementFormat `json:"format"`
+	Reference           string              `json:"reference"`
+	Account             string              `json:"account"`
+
+	// ContentHash is the SHA-256 of the file, in hex
+	ContentHash string `json:"content_hash"`
+
+	Transactions []BankTransaction `json:"transactions"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BankTransaction is a transaction of an imported statement with the receivable it was matched to. A confident match is
+// recorded as a payment, an ambiguous or unknown incoming transaction waits for review with its candidates.
+type BankTransaction struct {
+	ID                    int64  `json:"id"`
+	ExternalTransactionID string `json:"transaction_id"`
+
+	StatementID         int64  `json:"-"`
+	ExternalStatementID string `json:"statement_id"`
+
+	BankReference     string                   `json:"bank_reference"`
+	BookingDate       time.Time                `json:"booking_date"`
+	Direction         BankTransactionDirection `json:"direction"`
+	AmountMinor       int64                    `json:"amount_minor"`
+	Currency          string                   `json:"currency"`
+	CurrencyPrecision int64                    `json:"currency_precision"`
+	CounterpartyName  string                   `json:"counterparty_name"`
+	RemittanceInfo    string                   `json:"remittance_info"`
+
+	Status    BankTransactionStatus    `json:"status"`
+	MatchRule BankTransactionMatchRule `json:"match_rule,omitempty"`
+
+	// CandidateBillingIDs are the billings a transaction waiting for review may pay
+	CandidateBillingIDs         []int64  `json:"-"`
+	ExternalCandidateBillingIDs []string `json:"candidate_billing_ids"`
+
+	BillingID         *int64  `json:"-"`
+	ExternalBillingID *string `json:"billing_id,omitempty"`
+	PaymentID         *int64  `json:"-"`
+	ExternalPaymentID *string `json:"payment_id,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CanReview reports whether the transaction waits for review
+func (t *BankTransaction) CanReview() bool {
+	return t.Status == BankTransactionStatusNeedsReview
+}
+
+// BankTransactionMatch is the outcome of matching a transaction against the open receivables
+type BankTransactionMatch struct {
+	Status     BankTransactionStatus
+	Rule       BankTransactionMatchRule
+	Receivable *Receivable
+	Candidates []Receivable
+}
+
+// MatchBankTransaction matches an incoming transaction to the open receivables in its currency. A receivable whose invoice
+// number is in the remittance information, or whose outstanding amount is the transaction amount and whose billing or user
+// ID is in the remittance information, is a confident match when it is the only one. Otherwise the receivables it may pay,
+// confident ones first and else those owing exactly its amount, are candidates for review. Outgoing transactions are ignored.
+func MatchBankTransaction(transaction BankTransaction, receivables []Receivable) BankTransactionMatch {
+	if transaction.Direction != BankTransactionDirectionCredit {
+		return BankTransactionMatch{Status: BankTransactionStatusIgnored}
+	}
+
+	reference := strings.ToUpper(transaction.RemittanceInfo)
+
+	var confident []Receivable
+	var rules []BankTransactionMatchRule
+	var sameAmount []Receivable
+	for _, receivable := range receivables {
+		if !strings.EqualFold(receivable.Currency, transaction.Currency) {
+			continue
+		}
+
+		sameOutstanding := receivable.OutstandingAmountMinor == transaction.AmountMinor
+		if receivable.InvoiceNumber != "" && containsToken(reference, strings.ToUpper(receivable.InvoiceNumber)) {
+			confident = append(confident, receivable)
+			rules = append(rules, BankTransactionMatchRuleInvoiceNumber)
+		} else if sameOutstanding && (containsToken(reference, strings.ToUpper(receivable.ExternalBillingID)) || containsToken(reference, strings.ToUpper(receivable.UserID))) {
+			confident = append(confident, receivable)
+			rules = append(rules, BankTransactionMatchRuleAmountAndReference)
+		} else if sameOutstanding {
+			sameAmount = append(sameAmount, receivable)
+		}
+	}
+
+	if len(confident) == 1 {
+		return BankTransactionMatch{Status: BankTransactionStatusMatched, Rule: rules[0], Receivable: &confident[0]}
+	}
+	if len(confident) > 1 {
+		return BankTransactionMatch{Status: BankTransactionStatusNeedsReview, Candidates: confident}
+	}
+	return BankTransactionMatch{Status: BankTransactionStatusNeedsReview, Candidates: sameAmount}
+}
+
+// containsToken reports whether token is in text and not part of a longer word, so that INV-1 is not found in INV-10
+func containsToken(text string, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	for offset := 0; offset < len(text); {
+		index := strings.Index(text[offset:], token)
+		if index < 0 {
+			return false
+		}
+
+		start := offset + index
+		end := start + len(token)
+		if (start == 0 || !isWordByte(text[start-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+
+	return false
+}
+
+func isWordByte(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z'
+}
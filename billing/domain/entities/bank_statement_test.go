@@ -0,0 +1,120 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBankStatementEntry_AmountMinor(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      float64
+		precision   int64
+		expected    int64
+		expectedErr error
+	}{
+		{name: "cents", amount: 125.5, precision: 2, expected: 12550},
+		{name: "no minor unit", amount: 1500, precision: 0, expected: 1500},
+		{name: "too many decimals", amount: 10.255, precision: 2, expectedErr: ErrInvalidBankStatement},
+		{name: "zero amount", amount: 0, precision: 2, expectedErr: ErrInvalidBankStatement},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := BankStatementEntry{Amount: tt.amount}
+			got, err := entry.AmountMinor(tt.precision)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("AmountMinor() error = %v, expected %v", err, tt.expectedErr)
+			}
+			if got != tt.expected {
+				t.Errorf("AmountMinor() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchBankTransaction(t *testing.T) {
+	receivables := []Receivable{
+		{ExternalBillingID: "0193f1c2-0000-7000-8000-000000000001", UserID: "user-1", InvoiceNumber: "INV-2026-000001", Currency: "EUR", OutstandingAmountMinor: 10000},
+		{ExternalBillingID: "0193f1c2-0000-7000-8000-000000000002", UserID: "user-2", InvoiceNumber: "INV-2026-000002", Currency: "EUR", OutstandingAmountMinor: 5000},
+		{ExternalBillingID: "0193f1c2-0000-7000-8000-000000000003", UserID: "user-3", InvoiceNumber: "INV-2026-000003", Currency: "EUR", OutstandingAmountMinor: 5000},
+		{ExternalBillingID: "0193f1c2-0000-7000-8000-000000000004", UserID: "user-1", InvoiceNumber: "INV-2026-000004", Currency: "USD", OutstandingAmountMinor: 10000},
+	}
+
+	tests := []struct {
+		name               string
+		transaction        BankTransaction
+		expectedStatus     BankTransactionStatus
+		expectedRule       BankTransactionMatchRule
+		expectedBillingID  string
+		expectedCandidates int
+	}{
+		{
+			name:              "invoice number",
+			transaction:       BankTransaction{Direction: BankTransactionDirectionCredit, AmountMinor: 4000, Currency: "EUR", RemittanceInfo: "Payment inv-2026-000001 thanks"},
+			expectedStatus:    BankTransactionStatusMatched,
+			expectedRule:      BankTransactionMatchRuleInvoiceNumber,
+			expectedBillingID: "0193f1c2-0000-7000-8000-000000000001",
+		},
+		{
+			name:              "amount and user reference",
+			transaction:       BankTransaction{Direction: BankTransactionDirectionCredit, AmountMinor: 5000, Currency: "EUR", RemittanceInfo: "customer user-3"},
+			expectedStatus:    BankTransactionStatusMatched,
+			expectedRule:      BankTransactionMatchRuleAmountAndReference,
+			expectedBillingID: "0193f1c2-0000-7000-8000-000000000003",
+		},
+		{
+			name:               "user reference with another amount",
+			transaction:        BankTransaction{Direction: BankTransactionDirectionCredit, AmountMinor: 4000, Currency: "EUR", RemittanceInfo: "customer user-3"},
+			expectedStatus:     BankTransactionStatusNeedsReview,
+			expectedCandidates: 0,
+		},
+		{
+			name:               "amount only",
+			transaction:        BankTransaction{Direction: BankTransactionDirectionCredit, AmountMinor: 5000, Currency: "EUR", RemittanceInfo: "march"},
+			expectedStatus:     BankTransactionStatusNeedsReview,
+			expectedCandidates: 2,
+		},
+		{
+			name:               "two invoice numbers",
+			transaction:        BankTransaction{Direction: BankTransactionDirectionCredit, AmountMinor: 15000, Currency: "EUR", RemittanceInfo: "INV-2026-000001 INV-2026-000002"},
+			expectedStatus:     BankTransactionStatusNeedsReview,
+			expectedCandidates: 2,
+		},
+		{
+			name:               "longer invoice number",
+			transaction:        BankTransaction{Direction: BankTransactionDirectionCredit, AmountMinor: 4000, Currency: "EUR", RemittanceInfo: "INV-2026-0000010"},
+			expectedStatus:     BankTransactionStatusNeedsReview,
+			expectedCandidates: 0,
+		},
+		{
+			name:               "invoice number in another currency",
+			transaction:        BankTransaction{Direction: BankTransactionDirectionCredit, AmountMinor: 10000, Currency: "GBP", RemittanceInfo: "INV-2026-000004"},
+			expectedStatus:     BankTransactionStatusNeedsReview,
+			expectedCandidates: 0,
+		},
+		{
+			name:           "outgoing transaction",
+			transaction:    BankTransaction{Direction: BankTransactionDirectionDebit, AmountMinor: 10000, Currency: "EUR", RemittanceInfo: "INV-2026-000001"},
+			expectedStatus: BankTransactionStatusIgnored,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := MatchBankTransaction(tt.transaction, receivables)
+			if match.Status != tt.expectedStatus {
+				t.Fatalf("MatchBankTransaction() status = %v, expected %v", match.Status, tt.expectedStatus)
+			}
+			if match.Rule != tt.expectedRule {
+				t.Errorf("MatchBankTransaction() rule = %v, expected %v", match.Rule, tt.expectedRule)
+			}
+			if tt.expectedBillingID != "" && (match.Receivable == nil || match.Receivable.ExternalBillingID != tt.expectedBillingID) {
+				t.Errorf("MatchBankTransaction() receivable = %v, expected %v", match.Receivable, tt.expectedBillingID)
+			}
+			if len(match.Candidates) != tt.expectedCandidates {
+				t.Errorf("MatchBankTransaction() candidates = %v, expected %v", len(match.Candidates), tt.expectedCandidates)
+			}
+		})
+	}
+}
@@ -10,19 +10,37 @@ const (
 	BillingStatusOpen           BillingStatus = "open"
 	BillingStatusPendingClosure BillingStatus = "pending_closure"
 	BillingStatusClosed         BillingStatus = "closed"
+	BillingStatusCancelled      BillingStatus = "cancelled"
 )
 
 type Billing struct {
-	ID                int64         `json:"id"`
-	UserID            string        `json:"user_id"`
-	Description       string        `json:"description"`
-	Currency          string        `json:"currency"`
-	CurrencyPrecision int64         `json:"currency_precision"`
-	Status            BillingStatus `json:"status"`
-	PlannedClosedAt   *time.Time    `json:"planned_closed_at"`
-	ActualClosedAt    *time.Time    `json:"actual_closed_at"`
-	CreatedAt         time.Time     `json:"created_at"`
-	UpdatedAt         time.Time     `json:"updated_at"`
+	ID                 int64           `json:"id"`
+	ExternalBillingID  string          `json:"external_billing_id"`
+	UserID             string          `json:"user_id"`
+	Description        string          `json:"description"`
+	Currency           string          `json:"currency"`
+	CurrencyPrecision  int64           `json:"currency_precision"`
+	Status             BillingStatus   `json:"status"`
+	PlannedClosedAt    *time.Time      `json:"planned_closed_at"`
+	ActualClosedAt     *time.Time      `json:"actual_closed_at"`
+	PeriodStart        *time.Time      `json:"period_start"`
+	PeriodEnd          *time.Time      `json:"period_end"`
+	Timezone           string          `json:"timezone"`
+	Recurrence         *RecurrenceRule `json:"recurrence"`
+	SubscriptionID     *string         `json:"subscription_id"`
+	PreviousBillingID  *string         `json:"previous_billing_id"`
+	NextBillingID      *string         `json:"next_billing_id"`
+	TaxJurisdiction    string          `json:"tax_jurisdiction"`
+	TaxInclusive       bool            `json:"tax_inclusive"`
+	AllowNegativeTotal bool            `json:"allow_negative_total"`
+	UserGroup          string          `json:"user_group"`
+	AutoCharge         bool            `json:"auto_charge"`
+	SpendLimit         *SpendLimit     `json:"spend_limit"`
+	InvoiceNumber      *string         `json:"invoice_number"`
+	CancelledAt        *time.Time      `json:"cancelled_at"`
+	CancellationReason string          `json:"cancellation_reason"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
 }
 
 func (b *Billing) CanAddLineItem() bool {
@@ -33,20 +51,83 @@ func (b *Billing) CanCloseBilling() bool {
 	return b.Status == BillingStatusOpen
 }
 
+// CanCancelBilling reports whether the billing can be cancelled, a cancelled billing is never invoiced
+func (b *Billing) CanCancelBilling() bool {
+	return b.Status == BillingStatusOpen
+}
+
 func (b *Billing) CanAddItemWithAmount(amount float64) bool {
 	return b.CanAddLineItem() && hasAtMostXDecimals(amount, b.CurrencyPrecision)
 }
 
+// CanLimitSpendWithAmount reports whether a spend limit amount fits the precision of the billing currency
+func (b *Billing) CanLimitSpendWithAmount(amount float64) bool {
+	return hasAtMostXDecimals(amount, b.CurrencyPrecision)
+}
+
+func (b *Billing) CanCreditBilling() bool {
+	return b.Status == BillingStatusClosed
+}
+
+func (b *Billing) CanCreditItemWithAmount(amount float64) bool {
+	return b.CanCreditBilling() && hasAtMostXDecimals(amount, b.CurrencyPrecision)
+}
+
+// CanPayBilling reports whether payments can be recorded against the billing, only closed billings are owed
+func (b *Billing) CanPayBilling() bool {
+	return b.Status == BillingStatusClosed
+}
+
+func (b *Billing) CanPayWithAmount(amount float64) bool {
+	return b.CanPayBilling() && hasAtMostXDecimals(amount, b.CurrencyPrecision)
+}
+
+func (b *Billing) CanInvoiceBilling() bool {
+	return b.Status == BillingStatusClosed
+}
+
 type LineItem struct {
-	Description string `json:"description"`
-	AmountMinor int64  `json:"amount_minor"`
+	LineItemID     string `json:"line_item_id,omitempty"`
+	Kind           string `json:"kind,omitempty"` // charge (default) or adjustment
+	Description    string `json:"description"`
+	AmountMinor    int64  `json:"amount_minor"`
+	TaxCode        string `json:"tax_code,omitempty"`
+	TaxRatePPM     int64  `json:"tax_rate_ppm,omitempty"` // rate the line was taxed at when the billing closed
+	TaxAmountMinor int64  `json:"tax_amount_minor,omitempty"`
+
+	// DiscountAmountMinor is the share of coupon discounts taken off this line, tax is computed on the discounted amount
+	DiscountAmountMinor int64 `json:"discount_amount_minor,omitempty"`
+
+	// Reason and ReferenceLineItemID describe adjustments, the reference is the charge the adjustment corrects
+	Reason              string `json:"reason,omitempty"`
+	ReferenceLineItemID string `json:"reference_line_item_id,omitempty"`
 }
 
 type BillingSummary struct {
 	ExternalBillingID string     `json:"external_billing_id"`
+	InvoiceNumber     string     `json:"invoice_number,omitempty"`
 	Description       string     `json:"description"`
 	Currency          string     `json:"currency"`
 	CurrencyPrecision int64      `json:"currency_precision"`
 	LineItems         []LineItem `json:"line_items"`
 	TotalAmountMinor  int64      `json:"total_amount_minor"`
+	PeriodStart       *time.Time `json:"period_start,omitempty"`
+	PeriodEnd         *time.Time `json:"period_end,omitempty"`
+	Timezone          string     `json:"timezone,omitempty"`
+	SubscriptionID    *string    `json:"subscription_id,omitempty"`
+	PreviousBillingID *string    `json:"previous_billing_id,omitempty"`
+	NextBillingID     *string    `json:"next_billing_id,omitempty"`
+
+	// discounts and tax are computed when the billing closes, TotalAmountMinor is the sum of the line amounts as entered
+	TaxJurisdiction       string         `json:"tax_jurisdiction,omitempty"`
+	TaxInclusive          bool           `json:"tax_inclusive,omitempty"`
+	Discounts             []Discount     `json:"discounts,omitempty"`
+	DiscountAmountMinor   int64          `json:"discount_amount_minor"`
+	SubtotalAmountMinor   int64          `json:"subtotal_amount_minor"`
+	TaxAmountMinor        int64          `json:"tax_amount_minor"`
+	GrandTotalAmountMinor int64          `json:"grand_total_amount_minor"`
+	Taxes                 []TaxBreakdown `json:"taxes,omitempty"`
+
+	// PaidFromCreditAmountMinor is what the wallet of the user paid when the billing closed
+	PaidFromCreditAmountMinor int64 `json:"paid_from_credit_amount_minor,omitempty"`
 }
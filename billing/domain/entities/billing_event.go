@@ -0,0 +1,230 @@
+package entities
+
+import (
+	"strconv"
+	"time"
+)
+
+type BillingEventType = string
+
+const (
+	BillingEventCreated       BillingEventType = "billing.created"
+	BillingEventLineItemAdded BillingEventType = "billing.line_item_added"
+	BillingEventClosed        BillingEventType = "billing.closed"
+	BillingEventCancelled     BillingEventType = "billing.cancelled"
+
+	BillingEventPaymentReminder BillingEventType = "billing.payment_reminder"
+
+	BillingEventSpendThresholdCrossed BillingEventType = "billing.spend_threshold_crossed"
+
+	BillingEventUsageUnbilled BillingEventType = "billing.usage_unbilled"
+)
+
+// BillingEventVersion is the version of the billing event payloads, it is bumped when a field changes meaning or is removed
+const BillingEventVersion = 1
+
+// BillingEventID identifies a change of a billing, an event published again by a retried activity has the same ID so consumers can deduplicate it
+func BillingEventID(eventType BillingEventType, externalBillingID string, key string) string {
+	eventID := eventType + ":" + externalBillingID
+	if key != "" {
+		eventID = eventID + ":" + key
+	}
+	return eventID
+}
+
+type BillingCreatedEvent struct {
+	EventID           string           `json:"event_id"`
+	Type              BillingEventType `json:"type"`
+	Version           int              `json:"version"`
+	ExternalBillingID string           `json:"billing_id"`
+	OccurredAt        time.Time        `json:"occurred_at"`
+	UserID            string           `json:"user_id"`
+	Description       string           `json:"description"`
+	Currency          string           `json:"currency"`
+	CurrencyPrecision int64            `json:"currency_precision"`
+	PlannedClosedAt   *time.Time       `json:"planned_closed_at,omitempty"`
+	PeriodStart       *time.Time       `json:"period_start,omitempty"`
+	PeriodEnd         *time.Time       `json:"period_end,omitempty"`
+	SubscriptionID    *string          `json:"subscription_id,omitempty"`
+	PreviousBillingID *string          `json:"previous_billing_id,omitempty"`
+}
+
+func NewBillingCreatedEvent(billing Billing, occurredAt time.Time) BillingCreatedEvent {
+	return BillingCreatedEvent{
+		EventID:           BillingEventID(BillingEventCreated, billing.ExternalBillingID, ""),
+		Type:              BillingEventCreated,
+		Version:           BillingEventVersion,
+		ExternalBillingID: billing.ExternalBillingID,
+		OccurredAt:        occurredAt,
+		UserID:            billing.UserID,
+		Description:       billing.Description,
+		Currency:          billing.Currency,
+		CurrencyPrecision: billing.CurrencyPrecision,
+		PlannedClosedAt:   billing.PlannedClosedAt,
+		PeriodStart:       billing.PeriodStart,
+		PeriodEnd:         billing.PeriodEnd,
+		SubscriptionID:    billing.SubscriptionID,
+		PreviousBillingID: billing.PreviousBillingID,
+	}
+}
+
+type BillingLineItemAddedEvent struct {
+	EventID           string           `json:"event_id"`
+	Type              BillingEventType `json:"type"`
+	Version           int              `json:"version"`
+	ExternalBillingID string           `json:"billing_id"`
+	OccurredAt        time.Time        `json:"occurred_at"`
+	LineItem          LineItem         `json:"line_item"`
+}
+
+func NewBillingLineItemAddedEvent(externalBillingID string, lineItem LineItem, occurredAt time.Time) BillingLineItemAddedEvent {
+	return BillingLineItemAddedEvent{
+		EventID:           BillingEventID(BillingEventLineItemAdded, externalBillingID, lineItem.LineItemID),
+		Type:              BillingEventLineItemAdded,
+		Version:           BillingEventVersion,
+		ExternalBillingID: externalBillingID,
+		OccurredAt:        occurredAt,
+		LineItem:          lineItem,
+	}
+}
+
+// BillingClosedEvent carries the summary of the closed billing with its discounts and tax
+type BillingClosedEvent struct {
+	EventID           string           `json:"event_id"`
+	Type              BillingEventType `json:"type"`
+	Version           int              `json:"version"`
+	ExternalBillingID string           `json:"billing_id"`
+	OccurredAt        time.Time        `json:"occurred_at"`
+	InvoiceNumber     string           `json:"invoice_number,omitempty"`
+	Summary           BillingSummary   `json:"summary"`
+}
+
+func NewBillingClosedEvent(summary BillingSummary, occurredAt time.Time) BillingClosedEvent {
+	return BillingClosedEvent{
+		EventID:           BillingEventID(BillingEventClosed, summary.ExternalBillingID, ""),
+		Type:              BillingEventClosed,
+		Version:           BillingEventVersion,
+		ExternalBillingID: summary.ExternalBillingID,
+		OccurredAt:        occurredAt,
+		InvoiceNumber:     summary.InvoiceNumber,
+		Summary:           summary,
+	}
+}
+
+type BillingCancelledEvent struct {
+	EventID           string           `json:"event_id"`
+	Type              BillingEventType `json:"type"`
+	Version           int              `json:"version"`
+	ExternalBillingID string           `json:"billing_id"`
+	OccurredAt        time.Time        `json:"occurred_at"`
+	Reason            string           `json:"reason,omitempty"`
+}
+
+func NewBillingCancelledEvent(externalBillingID string, reason string, occurredAt time.Time) BillingCancelledEvent {
+	return BillingCancelledEvent{
+		EventID:           BillingEventID(BillingEventCancelled, externalBillingID, ""),
+		Type:              BillingEventCancelled,
+		Version:           BillingEventVersion,
+		ExternalBillingID: externalBillingID,
+		OccurredAt:        occurredAt,
+		Reason:            reason,
+	}
+}
+
+// BillingPaymentReminderEvent is sent at each step of the dunning of an unpaid billing with the status the billing escalated to
+type BillingPaymentReminderEvent struct {
+	EventID                string           `json:"event_id"`
+	Type                   BillingEventType `json:"type"`
+	Version                int              `json:"version"`
+	ExternalBillingID      string           `json:"billing_id"`
+	OccurredAt             time.Time        `json:"occurred_at"`
+	InvoiceNumber          string           `json:"invoice_number,omitempty"`
+	Step                   int              `json:"step"`
+	DunningStatus          DunningStatus    `json:"dunning_status"`
+	OutstandingAmountMinor int64            `json:"outstanding_amount_minor"`
+	Currency               string           `json:"currency"`
+}
+
+func NewBillingPaymentReminderEvent(billing Billing, step int, dunningStatus DunningStatus, outstandingAmountMinor int64, occurredAt time.Time) BillingPaymentReminderEvent {
+	invoiceNumber := ""
+	if billing.InvoiceNumber != nil {
+		invoiceNumber = *billing.InvoiceNumber
+	}
+
+	return BillingPaymentReminderEvent{
+		EventID:                BillingEventID(BillingEventPaymentReminder, billing.ExternalBillingID, strconv.Itoa(step)),
+		Type:                   BillingEventPaymentReminder,
+		Version:                BillingEventVersion,
+		ExternalBillingID:      billing.ExternalBillingID,
+		OccurredAt:             occurredAt,
+		InvoiceNumber:          invoiceNumber,
+		Step:                   step,
+		DunningStatus:          dunningStatus,
+		OutstandingAmountMinor: outstandingAmountMinor,
+		Currency:               billing.Currency,
+	}
+}
+
+// BillingSpendThresholdCrossedEvent is sent once for each threshold of the spend limit the billing total reaches
+type BillingSpendThresholdCrossedEvent struct {
+	EventID               string           `json:"event_id"`
+	Type                  BillingEventType `json:"type"`
+	Version               int              `json:"version"`
+	ExternalBillingID     string           `json:"billing_id"`
+	OccurredAt            time.Time        `json:"occurred_at"`
+	UserID                string           `json:"user_id"`
+	Threshold             int64            `json:"threshold"`
+	SpendLimitAmountMinor int64            `json:"spend_limit_amount_minor"`
+	TotalAmountMinor      int64            `json:"total_amount_minor"`
+	Currency              string           `json:"currency"`
+}
+
+func NewBillingSpendThresholdCrossedEvent(billing Billing, threshold int64, totalAmountMinor int64, occurredAt time.Time) BillingSpendThresholdCrossedEvent {
+	var spendLimitAmountMinor int64
+	if billing.SpendLimit != nil {
+		spendLimitAmountMinor = billing.SpendLimit.AmountMinor
+	}
+
+	return BillingSpendThresholdCrossedEvent{
+		EventID:               BillingEventID(BillingEventSpendThresholdCrossed, billing.ExternalBillingID, strconv.FormatInt(threshold, 10)),
+		Type:                  BillingEventSpendThresholdCrossed,
+		Version:               BillingEventVersion,
+		ExternalBillingID:     billing.ExternalBillingID,
+		OccurredAt:            occurredAt,
+		UserID:                billing.UserID,
+		Threshold:             threshold,
+		SpendLimitAmountMinor: spendLimitAmountMinor,
+		TotalAmountMinor:      totalAmountMinor,
+		Currency:              billing.Currency,
+	}
+}
+
+// BillingUsageUnbilledEvent is sent when a billing closes for the usage of its user in its period that no billing claims,
+// because its meter is in another currency than the billing and the user has no open billing in that currency
+type BillingUsageUnbilledEvent struct {
+	EventID           string           `json:"event_id"`
+	Type              BillingEventType `json:"type"`
+	Version           int              `json:"version"`
+	ExternalBillingID string           `json:"billing_id"`
+	OccurredAt        time.Time        `json:"occurred_at"`
+	UserID            string           `json:"user_id"`
+	MeterCode         string           `json:"meter"`
+	Quantity          int64            `json:"quantity"`
+	MeterCurrency     string           `json:"meter_currency"`
+	Currency          string           `json:"currency"`
+}
+
+func NewBillingUsageUnbilledEvent(billing Billing, usage UsageAggregate, occurredAt time.Time) BillingUsageUnbilledEvent {
+	return BillingUsageUnbilledEvent{
+		EventID:           BillingEventID(BillingEventUsageUnbilled, billing.ExternalBillingID, usage.MeterCode),
+		Type:              BillingEventUsageUnbilled,
+		Version:           BillingEventVersion,
+		ExternalBillingID: billing.ExternalBillingID,
+		OccurredAt:        occurredAt,
+		UserID:            billing.UserID,
+		MeterCode:         usage.MeterCode,
+		Quantity:          usage.Quantity,
+		MeterCurrency:     usage.Currency,
+		Currency:          billing.Currency,
+	}
+}
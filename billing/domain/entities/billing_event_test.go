@@ -0,0 +1,97 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBillingEvents(t *testing.T) {
+	occurredAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	created := NewBillingCreatedEvent(Billing{ExternalBillingID: "billing-1"}, occurredAt)
+	lineItemAdded := NewBillingLineItemAddedEvent("billing-1", LineItem{LineItemID: "line-1", Description: "Seat", AmountMinor: 1000}, occurredAt)
+	closed := NewBillingClosedEvent(BillingSummary{ExternalBillingID: "billing-1", InvoiceNumber: "INV-2026-000001"}, occurredAt)
+	cancelled := NewBillingCancelledEvent("billing-1", "duplicate", occurredAt)
+	paymentReminder := NewBillingPaymentReminderEvent(Billing{ExternalBillingID: "billing-1", Currency: "USD"}, 2, DunningStatusPastDue, 1500, occurredAt)
+	spendThresholdCrossed := NewBillingSpendThresholdCrossedEvent(Billing{ExternalBillingID: "billing-1", Currency: "USD", SpendLimit: &SpendLimit{AmountMinor: 10000}}, 80, 8000, occurredAt)
+	usageUnbilled := NewBillingUsageUnbilledEvent(Billing{ExternalBillingID: "billing-1", Currency: "USD"}, UsageAggregate{MeterCode: "eur_calls", Quantity: 1000, Currency: "EUR"}, occurredAt)
+
+	tests := []struct {
+		name            string
+		eventID         string
+		eventType       BillingEventType
+		version         int
+		expectedEventID string
+		expectedType    BillingEventType
+	}{
+		{
+			name:            "created",
+			eventID:         created.EventID,
+			eventType:       created.Type,
+			version:         created.Version,
+			expectedEventID: "billing.created:billing-1",
+			expectedType:    BillingEventCreated,
+		},
+		{
+			name:            "line item added is keyed by the line item",
+			eventID:         lineItemAdded.EventID,
+			eventType:       lineItemAdded.Type,
+			version:         lineItemAdded.Version,
+			expectedEventID: "billing.line_item_added:billing-1:line-1",
+			expectedType:    BillingEventLineItemAdded,
+		},
+		{
+			name:            "closed",
+			eventID:         closed.EventID,
+			eventType:       closed.Type,
+			version:         closed.Version,
+			expectedEventID: "billing.closed:billing-1",
+			expectedType:    BillingEventClosed,
+		},
+		{
+			name:            "cancelled",
+			eventID:         cancelled.EventID,
+			eventType:       cancelled.Type,
+			version:         cancelled.Version,
+			expectedEventID: "billing.cancelled:billing-1",
+			expectedType:    BillingEventCancelled,
+		},
+		{
+			name:            "payment reminder is keyed by the dunning step",
+			eventID:         paymentReminder.EventID,
+			eventType:       paymentReminder.Type,
+			version:         paymentReminder.Version,
+			expectedEventID: "billing.payment_reminder:billing-1:2",
+			expectedType:    BillingEventPaymentReminder,
+		},
+		{
+			name:            "spend threshold crossed is keyed by the threshold",
+			eventID:         spendThresholdCrossed.EventID,
+			eventType:       spendThresholdCrossed.Type,
+			version:         spendThresholdCrossed.Version,
+			expectedEventID: "billing.spend_threshold_crossed:billing-1:80",
+			expectedType:    BillingEventSpendThresholdCrossed,
+		},
+		{
+			name:            "usage unbilled is keyed by the meter",
+			eventID:         usageUnbilled.EventID,
+			eventType:       usageUnbilled.Type,
+			version:         usageUnbilled.Version,
+			expectedEventID: "billing.usage_unbilled:billing-1:eur_calls",
+			expectedType:    BillingEventUsageUnbilled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.eventID != tt.expectedEventID {
+				t.Errorf("EventID = %v, expected %v", tt.eventID, tt.expectedEventID)
+			}
+			if tt.eventType != tt.expectedType {
+				t.Errorf("Type = %v, expected %v", tt.eventType, tt.expectedType)
+			}
+			if tt.version != BillingEventVersion {
+				t.Errorf("Version = %v, expected %v", tt.version, BillingEventVersion)
+			}
+		})
+	}
+}
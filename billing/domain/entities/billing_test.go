@@ -75,6 +75,49 @@ func TestBilling_CanCloseBilling(t *testing.T) {
 	}
 }
 
+func TestBilling_CanCancelBilling(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		billing  *Billing
+		expected bool
+	}{
+		{
+			name: "open status can cancel billing",
+			billing: &Billing{
+				Status: BillingStatusOpen,
+			},
+			expected: true,
+		},
+		{
+			name: "closed status cannot cancel billing",
+			billing: &Billing{
+				Status:         BillingStatusClosed,
+				ActualClosedAt: &now,
+			},
+			expected: false,
+		},
+		{
+			name: "cancelled status cannot cancel billing",
+			billing: &Billing{
+				Status:      BillingStatusCancelled,
+				CancelledAt: &now,
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.billing.CanCancelBilling()
+			if result != tt.expected {
+				t.Errorf("CanCancelBilling() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestBilling_CanAddItemWithAmount(t *testing.T) {
 	now := time.Now()
 
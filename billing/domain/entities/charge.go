@@ -0,0 +1,73 @@
+package entities
+
+import "time"
+
+type ChargeStatus = string
+
+const (
+	ChargeStatusAuthorized        ChargeStatus = "authorized"
+	ChargeStatusCaptured          ChargeStatus = "captured"
+	ChargeStatusDeclined          ChargeStatus = "declined"
+	ChargeStatusPartiallyRefunded ChargeStatus = "partially_refunded"
+	ChargeStatusRefunded          ChargeStatus = "refunded"
+)
+
+// ChargeRequest asks a payment gateway to charge a customer, a request sent again with the same idempotency key returns the same charge
+type ChargeRequest struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	CustomerID     string `json:"customer_id"`
+	AmountMinor    int64  `json:"amount_minor"`
+	Currency       string `json:"currency"`
+	Description    string `json:"description"`
+}
+
+// Charge is a charge at a payment gateway, authorized charges are captured to collect the money
+type Charge struct {
+	ID                  string       `json:"id"`
+	IdempotencyKey      string       `json:"idempotency_key"`
+	CustomerID          string       `json:"customer_id"`
+	AmountMinor         int64        `json:"amount_minor"`
+	Currency            string       `json:"currency"`
+	Description         string       `json:"description"`
+	Status              ChargeStatus `json:"status"`
+	DeclineCode         string       `json:"decline_code,omitempty"`
+	RefundedAmountMinor int64        `json:"refunded_amount_minor"`
+	CreatedAt           time.Time    `json:"created_at"`
+}
+
+// IsCaptured reports whether the money of the charge was collected, refunded charges were captured first
+func (c *Charge) IsCaptured() bool {
+	return c.Status == ChargeStatusCaptured || c.Status == ChargeStatusPartiallyRefunded || c.Status == ChargeStatusRefunded
+}
+
+// RefundableAmountMinor is what is left to refund of a captured charge
+func (c *Charge) RefundableAmountMinor() int64 {
+	if !c.IsCaptured() {
+		return 0
+	}
+	return c.AmountMinor - c.RefundedAmountMinor
+}
+
+type ChargeAttemptStatus = string
+
+const (
+	ChargeAttemptStatusSucceeded ChargeAttemptStatus = "succeeded"
+	ChargeAttemptStatusDeclined  ChargeAttemptStatus = "declined"
+	ChargeAttemptStatusFailed    ChargeAttemptStatus = "failed"
+	ChargeAttemptStatusPending   ChargeAttemptStatus = "pending"
+)
+
+// ChargeAttempt is the outcome of charging a closed billing. Failed attempts made no charge at the gateway, pending attempts
+// made a charge whose outcome the gateway did not tell
+type ChargeAttempt struct {
+	ID                int64               `json:"id"`
+	BillingID         int64               `json:"-"`
+	ExternalBillingID string              `json:"billing_id"`
+	GatewayChargeID   string              `json:"gateway_charge_id,omitempty"`
+	AmountMinor       int64               `json:"amount_minor"`
+	Currency          string              `json:"currency"`
+	Status            ChargeAttemptStatus `json:"status"`
+	DeclineCode       string              `json:"decline_code,omitempty"`
+	Error             string              `json:"error,omitempty"`
+	AttemptedAt       time.Time           `json:"attempted_at"`
+}
@@ -0,0 +1,25 @@
+package entities
+
+import "testing"
+
+func TestCharge_RefundableAmountMinor(t *testing.T) {
+	tests := []struct {
+		name     string
+		charge   Charge
+		expected int64
+	}{
+		{name: "authorized", charge: Charge{AmountMinor: 1000, Status: ChargeStatusAuthorized}, expected: 0},
+		{name: "declined", charge: Charge{AmountMinor: 1000, Status: ChargeStatusDeclined}, expected: 0},
+		{name: "captured", charge: Charge{AmountMinor: 1000, Status: ChargeStatusCaptured}, expected: 1000},
+		{name: "partially refunded", charge: Charge{AmountMinor: 1000, Status: ChargeStatusPartiallyRefunded, RefundedAmountMinor: 300}, expected: 700},
+		{name: "refunded", charge: Charge{AmountMinor: 1000, Status: ChargeStatusRefunded, RefundedAmountMinor: 1000}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.charge.RefundableAmountMinor(); got != tt.expected {
+				t.Errorf("RefundableAmountMinor() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package entities
+
+import (
+	"math"
+	"math/big"
+	"time"
+)
+
+type CouponType = string
+
+const (
+	CouponTypePercentage  CouponType = "percentage"
+	CouponTypeFixedAmount CouponType = "fixed_amount"
+)
+
+// CouponPercentBPSScale is the scale of percentage coupons: 15.5% off is stored as 1550 basis points
+const CouponPercentBPSScale = 10_000
+
+type Coupon struct {
+	ID   int64      `json:"id"`
+	Code string     `json:"code"`
+	Type CouponType `json:"type"`
+
+	// PercentOffBPS is the discount of percentage coupons in basis points
+	PercentOffBPS int64 `json:"percent_off_bps,omitempty"`
+
+	// AmountOffMinor is the discount of fixed amount coupons in minor units of Currency
+	AmountOffMinor int64 `json:"amount_off_minor,omitempty"`
+
+	// Currency is required for fixed amount coupons and restricts percentage coupons to billings in that currency when set
+	Currency          string `json:"currency,omitempty"`
+	CurrencyPrecision int64  `json:"currency_precision,omitempty"`
+
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// MaxRedemptions limits the number of billings the coupon can be applied to, nil means unlimited
+	MaxRedemptions *int64 `json:"max_redemptions,omitempty"`
+	TimesRedeemed  int64  `json:"times_redeemed"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (c *Coupon) Validate() error {
+	if c.Code == "" {
+		return ErrInvalidCoupon
+	}
+
+	switch c.Type {
+	case CouponTypePercentage:
+		if c.PercentOffBPS <= 0 || c.PercentOffBPS > CouponPercentBPSScale || c.AmountOffMinor != 0 {
+			return ErrInvalidCoupon
+		}
+	case CouponTypeFixedAmount:
+		if c.AmountOffMinor <= 0 || c.PercentOffBPS != 0 || c.Currency == "" {
+			return ErrInvalidCoupon
+		}
+	default:
+		return ErrInvalidCoupon
+	}
+
+	if c.MaxRedemptions != nil && *c.MaxRedemptions <= 0 {
+		return ErrInvalidCoupon
+	}
+
+	return nil
+}
+
+// CanRedeem reports whether the coupon can still be applied at now
+func (c *Coupon) CanRedeem(now time.Time) error {
+	if c.ExpiresAt != nil && !now.Before(*c.ExpiresAt) {
+		return ErrCouponExpired
+	}
+	if c.MaxRedemptions != nil && c.TimesRedeemed >= *c.MaxRedemptions {
+		return ErrCouponRedemptionLimitReached
+	}
+	return nil
+}
+
+// AppliesTo reports whether the coupon can be applied to a billing in currency
+func (c *Coupon) AppliesTo(currency string) bool {
+	return c.Currency == "" || c.Currency == currency
+}
+
+// PercentToBasisPoints converts a percentage with at most two decimals to basis points
+func PercentToBasisPoints(percent float64) (int64, error) {
+	if !hasAtMostXDecimals(percent, 2) {
+		return 0, ErrInvalidCoupon
+	}
+	return int64(math.Round(percent * 100)), nil
+}
+
+// Discount is the amount taken off a billing by a coupon
+type Discount struct {
+	CouponCode    string     `json:"coupon_code"`
+	Type          CouponType `json:"type"`
+	PercentOffBPS int64      `json:"percent_off_bps,omitempty"`
+	AmountMinor   int64      `json:"amount_minor"`
+}
+
+// CalculateDiscounts applies coupons in order, each to what is left after the previous ones, and never below zero.
+// Every discount is spread over the remaining positive line items in proportion to their amount,
+// so that tax is computed on discounted lines. The returned line items have DiscountAmountMinor set.
+func CalculateDiscounts(lineItems []LineItem, coupons []Coupon) ([]LineItem, []Discount) {
+	discounted := make([]LineItem, len(lineItems))
+	copy(discounted, lineItems)
+	discounts := []Discount{}
+
+	for _, coupon := range coupons {
+		var remainingMinor int64
+		weights := make([]int64, len(discounted))
+		for i, lineItem := range discounted {
+			remainingMinor += lineItem.AmountMinor - lineItem.DiscountAmountMinor
+			if weight := lineItem.AmountMinor - lineItem.DiscountAmountMinor; weight > 0 {
+				weights[i] = weight
+			}
+		}
+
+		var amountMinor int64
+		if remainingMinor > 0 {
+			switch coupon.Type {
+			case CouponTypePercentage:
+				amountMinor = divRoundHalfAwayFromZero(
+					new(big.Int).Mul(big.NewInt(remainingMinor), big.NewInt(coupon.PercentOffBPS)),
+					big.NewInt(CouponPercentBPSScale),
+				)
+			case CouponTypeFixedAmount:
+				amountMinor = coupon.AmountOffMinor
+			}
+			amountMinor = min(amountMinor, remainingMinor)
+		}
+
+		for i, allocatedMinor := range allocateProportionally(amountMinor, weights) {
+			discounted[i].DiscountAmountMinor += allocatedMinor
+		}
+
+		discounts = append(discounts, Discount{
+			CouponCode:    coupon.Code,
+			Type:          coupon.Type,
+			PercentOffBPS: coupon.PercentOffBPS,
+			AmountMinor:   amountMinor,
+		})
+	}
+
+	return discounted, discounts
+}
+
+// allocateProportionally splits amount over weights with the largest remainder method, so the parts always add up to amount.
+// Ties go to the earliest weight.
+func allocateProportionally(amount int64, weights []int64) []int64 {
+	parts := make([]int64, len(weights))
+
+	totalWeight := big.NewInt(0)
+	for _, weight := range weights {
+		totalWeight.Add(totalWeight, big.NewInt(weight))
+	}
+	if amount == 0 || totalWeight.Sign() == 0 {
+		return parts
+	}
+
+	remainders := make([]*big.Int, len(weights))
+	allocated := int64(0)
+	for i, weight := range weights {
+		quotient, remainder := new(big.Int).QuoRem(new(big.Int).Mul(big.NewInt(amount), big.NewInt(weight)), totalWeight, new(big.Int))
+		parts[i] = quotient.Int64()
+		remainders[i] = remainder
+		allocated += parts[i]
+	}
+
+	// hand out the rounding leftover one minor unit at a time to the largest remainders
+	for ; allocated < amount; allocated++ {
+		largest := -1
+		for i, remainder := range remainders {
+			if weights[i] > 0 && (largest == -1 || remainder.Cmp(remainders[largest]) > 0) {
+				largest = i
+			}
+		}
+		parts[largest]++
+		remainders[largest] = big.NewInt(-1)
+	}
+
+	return parts
+}
@@ -0,0 +1,151 @@
+package entities
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCalculateDiscounts(t *testing.T) {
+	tenPercent := Coupon{Code: "TEN", Type: CouponTypePercentage, PercentOffBPS: 1000}
+	halfOff := Coupon{Code: "HALF", Type: CouponTypePercentage, PercentOffBPS: 5000}
+
+	tests := []struct {
+		name              string
+		lineItems         []LineItem
+		coupons           []Coupon
+		expectedDiscounts []int64 // per line
+		expected          []Discount
+	}{
+		{
+			name:              "percentage spread over lines",
+			lineItems:         []LineItem{{AmountMinor: 1000}, {AmountMinor: 500}},
+			coupons:           []Coupon{tenPercent},
+			expectedDiscounts: []int64{100, 50},
+			expected:          []Discount{{CouponCode: "TEN", Type: CouponTypePercentage, PercentOffBPS: 1000, AmountMinor: 150}},
+		},
+		{
+			name:              "fixed amount is capped at the total",
+			lineItems:         []LineItem{{AmountMinor: 300}, {AmountMinor: 200}},
+			coupons:           []Coupon{{Code: "BIG", Type: CouponTypeFixedAmount, AmountOffMinor: 1000, Currency: "USD"}},
+			expectedDiscounts: []int64{300, 200},
+			expected:          []Discount{{CouponCode: "BIG", Type: CouponTypeFixedAmount, AmountMinor: 500}},
+		},
+		{
+			name:              "coupons apply to what is left after the previous ones",
+			lineItems:         []LineItem{{AmountMinor: 1000}},
+			coupons:           []Coupon{tenPercent, halfOff},
+			expectedDiscounts: []int64{550},
+			expected: []Discount{
+				{CouponCode: "TEN", Type: CouponTypePercentage, PercentOffBPS: 1000, AmountMinor: 100},
+				{CouponCode: "HALF", Type: CouponTypePercentage, PercentOffBPS: 5000, AmountMinor: 450},
+			},
+		},
+		{
+			name:              "rounding leftover goes to the largest remainder",
+			lineItems:         []LineItem{{AmountMinor: 333}, {AmountMinor: 333}, {AmountMinor: 334}},
+			coupons:           []Coupon{{Code: "TEN", Type: CouponTypeFixedAmount, AmountOffMinor: 10, Currency: "USD"}},
+			expectedDiscounts: []int64{3, 3, 4},
+			expected:          []Discount{{CouponCode: "TEN", Type: CouponTypeFixedAmount, AmountMinor: 10}},
+		},
+		{
+			name:              "credits reduce the base but receive no discount",
+			lineItems:         []LineItem{{AmountMinor: 1000}, {AmountMinor: -400}},
+			coupons:           []Coupon{halfOff},
+			expectedDiscounts: []int64{300, 0},
+			expected:          []Discount{{CouponCode: "HALF", Type: CouponTypePercentage, PercentOffBPS: 5000, AmountMinor: 300}},
+		},
+		{
+			name:              "nothing to discount",
+			lineItems:         []LineItem{{AmountMinor: 100}, {AmountMinor: -100}},
+			coupons:           []Coupon{tenPercent},
+			expectedDiscounts: []int64{0, 0},
+			expected:          []Discount{{CouponCode: "TEN", Type: CouponTypePercentage, PercentOffBPS: 1000, AmountMinor: 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lineItems, discounts := CalculateDiscounts(tt.lineItems, tt.coupons)
+			lineDiscounts := make([]int64, len(lineItems))
+			for i, lineItem := range lineItems {
+				lineDiscounts[i] = lineItem.DiscountAmountMinor
+			}
+			if !reflect.DeepEqual(lineDiscounts, tt.expectedDiscounts) {
+				t.Errorf("CalculateDiscounts() line discounts = %v, expected %v", lineDiscounts, tt.expectedDiscounts)
+			}
+			if !reflect.DeepEqual(discounts, tt.expected) {
+				t.Errorf("CalculateDiscounts() = %+v, expected %+v", discounts, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCalculateTax_WithDiscount(t *testing.T) {
+	rates := []TaxRate{{Jurisdiction: "GB", TaxCode: "standard", RatePPM: 200000}}
+	lineItems, _ := CalculateDiscounts([]LineItem{{AmountMinor: 1000, TaxCode: "standard"}}, []Coupon{{Code: "TEN", Type: CouponTypePercentage, PercentOffBPS: 1000}})
+
+	result, err := CalculateTax(lineItems, rates, false)
+	if err != nil {
+		t.Fatalf("CalculateTax() error = %v", err)
+	}
+	if result.SubtotalAmountMinor != 900 || result.TaxAmountMinor != 180 || result.GrandTotalAmountMinor != 1080 {
+		t.Errorf("CalculateTax() = %+v, expected subtotal 900, tax 180 and grand total 1080", result)
+	}
+}
+
+func TestCoupon_CanRedeem(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+	expiredAt := now.Add(-time.Hour)
+	limit := int64(2)
+
+	tests := []struct {
+		name     string
+		coupon   Coupon
+		expected error
+	}{
+		{name: "no limits", coupon: Coupon{}, expected: nil},
+		{name: "not expired", coupon: Coupon{ExpiresAt: &expiresAt}, expected: nil},
+		{name: "expired", coupon: Coupon{ExpiresAt: &expiredAt}, expected: ErrCouponExpired},
+		{name: "below limit", coupon: Coupon{MaxRedemptions: &limit, TimesRedeemed: 1}, expected: nil},
+		{name: "limit reached", coupon: Coupon{MaxRedemptions: &limit, TimesRedeemed: 2}, expected: ErrCouponRedemptionLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.coupon.CanRedeem(now)
+			if !errors.Is(result, tt.expected) {
+				t.Errorf("CanRedeem() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCoupon_Validate(t *testing.T) {
+	zero := int64(0)
+
+	tests := []struct {
+		name     string
+		coupon   Coupon
+		expected error
+	}{
+		{name: "percentage", coupon: Coupon{Code: "TEN", Type: CouponTypePercentage, PercentOffBPS: 1000}, expected: nil},
+		{name: "fixed amount", coupon: Coupon{Code: "FIVE", Type: CouponTypeFixedAmount, AmountOffMinor: 500, Currency: "USD"}, expected: nil},
+		{name: "missing code", coupon: Coupon{Type: CouponTypePercentage, PercentOffBPS: 1000}, expected: ErrInvalidCoupon},
+		{name: "percentage above 100", coupon: Coupon{Code: "X", Type: CouponTypePercentage, PercentOffBPS: 10001}, expected: ErrInvalidCoupon},
+		{name: "fixed amount without currency", coupon: Coupon{Code: "X", Type: CouponTypeFixedAmount, AmountOffMinor: 500}, expected: ErrInvalidCoupon},
+		{name: "zero redemptions", coupon: Coupon{Code: "X", Type: CouponTypePercentage, PercentOffBPS: 1000, MaxRedemptions: &zero}, expected: ErrInvalidCoupon},
+		{name: "unknown type", coupon: Coupon{Code: "X", Type: "free_lunch"}, expected: ErrInvalidCoupon},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.coupon.Validate()
+			if !errors.Is(result, tt.expected) {
+				t.Errorf("Validate() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+// CreditNoteNumberPrefix prefixes the sequence number of credit notes, e.g. CN-000042
+const CreditNoteNumberPrefix = "CN-"
+
+// CreditNote refunds part of a closed billing. The billing summary stays as it was closed,
+// credit notes are separate documents with their own line items and number.
+type CreditNote struct {
+	ID                   int64  `json:"id"`
+	ExternalCreditNoteID string `json:"credit_note_id"`
+	Number               string `json:"number"`
+
+	BillingID         int64  `json:"-"`
+	ExternalBillingID string `json:"billing_id"`
+	Currency          string `json:"currency"`
+	CurrencyPrecision int64  `json:"currency_precision"`
+
+	Reason           string               `json:"reason"`
+	LineItems        []CreditNoteLineItem `json:"line_items"`
+	TotalAmountMinor int64                `json:"total_amount_minor"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreditNoteLineItem is a credited amount, positive and tax inclusive, optionally referencing the billed line item it refunds
+type CreditNoteLineItem struct {
+	Description         string `json:"description"`
+	AmountMinor         int64  `json:"amount_minor"`
+	ReferenceLineItemID string `json:"reference_line_item_id,omitempty"`
+}
+
+// Validate checks the credit note has a reason and positive line items, and sets its total
+func (c *CreditNote) Validate() error {
+	if c.Reason == "" || len(c.LineItems) == 0 {
+		return ErrInvalidCreditNote
+	}
+
+	var totalMinor int64
+	for _, lineItem := range c.LineItems {
+		if lineItem.Description == "" || lineItem.AmountMinor <= 0 {
+			return ErrInvalidCreditNote
+		}
+		totalMinor += lineItem.AmountMinor
+	}
+	c.TotalAmountMinor = totalMinor
+
+	return nil
+}
+
+// CreditNoteNumber formats the sequence number of a credit note
+func CreditNoteNumber(sequence int64) string {
+	return fmt.Sprintf("%s%06d", CreditNoteNumberPrefix, sequence)
+}
+
+// CreditedBalance is what was billed, what was credited by credit notes and what is left of a billing
+type CreditedBalance struct {
+	BilledAmountMinor   int64 `json:"billed_amount_minor"`
+	CreditedAmountMinor int64 `json:"credited_amount_minor"`
+	NetAmountMinor      int64 `json:"net_amount_minor"`
+}
+
+// NewCreditedBalance returns the balance of a billing billed for billedMinor and credited for creditedMinor
+func NewCreditedBalance(billedMinor int64, creditedMinor int64) CreditedBalance {
+	return CreditedBalance{
+		BilledAmountMinor:   billedMinor,
+		CreditedAmountMinor: creditedMinor,
+		NetAmountMinor:      billedMinor - creditedMinor,
+	}
+}
+
+// CanCredit reports whether a credit note of amountMinor fits in what is left of the billing
+func (b CreditedBalance) CanCredit(amountMinor int64) bool {
+	return amountMinor > 0 && amountMinor <= b.NetAmountMinor
+}
+
+// BilledAmountMinor is the amount charged by a closed billing, taxes and discounts included
+func (s *BillingSummary) BilledAmountMinor() int64 {
+	return s.GrandTotalAmountMinor
+}
+
+// AmountDueMinor is what was left to pay on a billing when it closed, once its wallet credit was drawn
+func (s *BillingSummary) AmountDueMinor() int64 {
+	return s.GrandTotalAmountMinor - s.PaidFromCreditAmountMinor
+}
@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCreditNote_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		creditNote    CreditNote
+		expected      error
+		expectedTotal int64
+	}{
+		{
+			name: "valid credit note",
+			creditNote: CreditNote{Reason: "refund", LineItems: []CreditNoteLineItem{
+				{Description: "Seat", AmountMinor: 1000},
+				{Description: "Support", AmountMinor: 250},
+			}},
+			expected:      nil,
+			expectedTotal: 1250,
+		},
+		{
+			name:       "missing reason",
+			creditNote: CreditNote{LineItems: []CreditNoteLineItem{{Description: "Seat", AmountMinor: 1000}}},
+			expected:   ErrInvalidCreditNote,
+		},
+		{
+			name:       "no line items",
+			creditNote: CreditNote{Reason: "refund"},
+			expected:   ErrInvalidCreditNote,
+		},
+		{
+			name:       "negative line item",
+			creditNote: CreditNote{Reason: "refund", LineItems: []CreditNoteLineItem{{Description: "Seat", AmountMinor: -1000}}},
+			expected:   ErrInvalidCreditNote,
+		},
+		{
+			name:       "line item without description",
+			creditNote: CreditNote{Reason: "refund", LineItems: []CreditNoteLineItem{{AmountMinor: 1000}}},
+			expected:   ErrInvalidCreditNote,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.creditNote.Validate()
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+			if err == nil && tt.creditNote.TotalAmountMinor != tt.expectedTotal {
+				t.Errorf("TotalAmountMinor = %v, expected %v", tt.creditNote.TotalAmountMinor, tt.expectedTotal)
+			}
+		})
+	}
+}
+
+func TestCreditedBalance_CanCredit(t *testing.T) {
+	balance := NewCreditedBalance(10000, 7500)
+
+	tests := []struct {
+		name        string
+		amountMinor int64
+		expected    bool
+	}{
+		{name: "within the remaining balance", amountMinor: 2000, expected: true},
+		{name: "exactly the remaining balance", amountMinor: 2500, expected: true},
+		{name: "beyond the remaining balance", amountMinor: 2501, expected: false},
+		{name: "zero", amountMinor: 0, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := balance.CanCredit(tt.amountMinor); got != tt.expected {
+				t.Errorf("CanCredit(%v) = %v, expected %v", tt.amountMinor, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCreditNoteNumber(t *testing.T) {
+	if got := CreditNoteNumber(42); got != "CN-000042" {
+		t.Errorf("CreditNoteNumber(42) = %v, expected %v", got, "CN-000042")
+	}
+}
@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"time"
+)
+
+type DunningStatus = string
+
+const (
+	// DunningStatusActive is a dunning waiting for its first step
+	DunningStatusActive DunningStatus = "active"
+
+	// DunningStatusPastDue is a billing that was reminded and is still unpaid
+	DunningStatusPastDue DunningStatus = "past_due"
+
+	// DunningStatusUncollectible is a billing still unpaid after the last step, it is no longer chased
+	DunningStatusUncollectible DunningStatus = "uncollectible"
+
+	// DunningStatusResolved is a billing paid before the dunning ended
+	DunningStatusResolved DunningStatus = "resolved"
+)
+
+// MaxDunningSteps is the largest number of steps of a dunning schedule
+const MaxDunningSteps = 10
+
+// MaxDunningScheduleDay is the last day a dunning step can be scheduled on
+const MaxDunningScheduleDay = 365
+
+// DefaultDunningScheduleDays are the days after the dunning started on which the charge is retried and a reminder is sent
+var DefaultDunningScheduleDays = []int{1, 3, 7}
+
+// Dunning chases the payment of a closed, unpaid billing. Each step of the schedule sends a reminder: the first one makes
+// the billing past due and the last one makes it uncollectible.
+type Dunning struct {
+	ID                int64         `json:"id"`
+	BillingID         int64         `json:"-"`
+	ExternalBillingID string        `json:"billing_id"`
+	Status            DunningStatus `json:"status"`
+	Step              int           `json:"step"`
+	ScheduleDays      []int         `json:"schedule_days"`
+	StartedAt         time.Time     `json:"started_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+}
+
+// IsFinished reports whether the dunning stopped chasing the billing
+func (d *Dunning) IsFinished() bool {
+	return d.Status == DunningStatusResolved || d.Status == DunningStatusUncollectible
+}
+
+// ValidateDunningSchedule checks a schedule has between 1 and MaxDunningSteps days, strictly increasing from day 1 to MaxDunningScheduleDay
+func ValidateDunningSchedule(scheduleDays []int) error {
+	if len(scheduleDays) == 0 || len(scheduleDays) > MaxDunningSteps {
+		return ErrInvalidDunningSchedule
+	}
+
+	previous := 0
+	for _, day := range scheduleDays {
+		if day <= previous || day > MaxDunningScheduleDay {
+			return ErrInvalidDunningSchedule
+		}
+		previous = day
+	}
+
+	return nil
+}
+
+// DunningStepStatus is the status a billing escalates to at a step of a schedule of steps steps, steps are numbered from 1
+func DunningStepStatus(step int, steps int) DunningStatus {
+	if step >= steps {
+		return DunningStatusUncollectible
+	}
+	return DunningStatusPastDue
+}
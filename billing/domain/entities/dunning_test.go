@@ -0,0 +1,107 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateDunningSchedule(t *testing.T) {
+	tests := []struct {
+		name         string
+		scheduleDays []int
+		expected     error
+	}{
+		{
+			name:         "default schedule",
+			scheduleDays: DefaultDunningScheduleDays,
+			expected:     nil,
+		},
+		{
+			name:         "single step",
+			scheduleDays: []int{14},
+			expected:     nil,
+		},
+		{
+			name:         "empty schedule",
+			scheduleDays: []int{},
+			expected:     ErrInvalidDunningSchedule,
+		},
+		{
+			name:         "day zero",
+			scheduleDays: []int{0, 3},
+			expected:     ErrInvalidDunningSchedule,
+		},
+		{
+			name:         "days not increasing",
+			scheduleDays: []int{1, 7, 3},
+			expected:     ErrInvalidDunningSchedule,
+		},
+		{
+			name:         "repeated day",
+			scheduleDays: []int{1, 1},
+			expected:     ErrInvalidDunningSchedule,
+		},
+		{
+			name:         "day after the last schedulable day",
+			scheduleDays: []int{1, MaxDunningScheduleDay + 1},
+			expected:     ErrInvalidDunningSchedule,
+		},
+		{
+			name:         "too many steps",
+			scheduleDays: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+			expected:     ErrInvalidDunningSchedule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDunningSchedule(tt.scheduleDays)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("ValidateDunningSchedule() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDunningStepStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     int
+		steps    int
+		expected DunningStatus
+	}{
+		{name: "first step", step: 1, steps: 3, expected: DunningStatusPastDue},
+		{name: "middle step", step: 2, steps: 3, expected: DunningStatusPastDue},
+		{name: "last step", step: 3, steps: 3, expected: DunningStatusUncollectible},
+		{name: "single step", step: 1, steps: 1, expected: DunningStatusUncollectible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DunningStepStatus(tt.step, tt.steps); got != tt.expected {
+				t.Errorf("DunningStepStatus() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDunning_IsFinished(t *testing.T) {
+	tests := []struct {
+		status   DunningStatus
+		expected bool
+	}{
+		{status: DunningStatusActive, expected: false},
+		{status: DunningStatusPastDue, expected: false},
+		{status: DunningStatusUncollectible, expected: true},
+		{status: DunningStatusResolved, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			dunning := Dunning{Status: tt.status}
+			if got := dunning.IsFinished(); got != tt.expected {
+				t.Errorf("IsFinished() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -4,6 +4,96 @@ import "errors"
 
 var (
 	ErrFxService       = errors.New("fx service error")
+	ErrFxRateNotFound  = errors.New("fx rate not found")
 	ErrDBService       = errors.New("db service error")
+	ErrEventPublisher  = errors.New("event publisher error")
 	ErrBillingNotFound = errors.New("billing not found")
+	ErrBillingNotOpen  = errors.New("billing is not open")
+
+	ErrBillingSummaryMismatch = errors.New("billing summary differs from the one already created")
+
+	ErrInvalidRecurrenceRule = errors.New("invalid recurrence rule")
+	ErrInvalidBillingPeriod  = errors.New("invalid billing period")
+	ErrInvalidTimezone       = errors.New("invalid timezone")
+
+	ErrInvalidPlan          = errors.New("invalid plan")
+	ErrPlanNotFound         = errors.New("plan not found")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+
+	ErrSubscriptionStatusConflict = errors.New("subscription status changed concurrently")
+
+	ErrInvalidProration    = errors.New("invalid proration")
+	ErrChangeOutsidePeriod = errors.New("change is outside of the billing period")
+
+	ErrInvalidTaxRate  = errors.New("invalid tax rate")
+	ErrTaxRateNotFound = errors.New("tax rate not found")
+
+	ErrInvalidCoupon                = errors.New("invalid coupon")
+	ErrCouponNotFound               = errors.New("coupon not found")
+	ErrCouponCodeTaken              = errors.New("coupon code already exists")
+	ErrCouponExpired                = errors.New("coupon expired")
+	ErrCouponRedemptionLimitReached = errors.New("coupon redemption limit reached")
+
+	ErrInvalidAdjustment               = errors.New("invalid adjustment")
+	ErrAdjustmentReasonRequired        = errors.New("adjustment reason is required")
+	ErrReferencedLineItemNotFound      = errors.New("referenced line item not found")
+	ErrAdjustmentExceedsReferencedItem = errors.New("adjustment exceeds referenced line item")
+	ErrNegativeBillingTotal            = errors.New("billing total cannot go below zero")
+
+	ErrInvalidCreditNote        = errors.New("invalid credit note")
+	ErrCreditNoteNotFound       = errors.New("credit note not found")
+	ErrCreditNoteExceedsBalance = errors.New("credit note exceeds the remaining balance of the billing")
+
+	ErrInvalidInvoiceNumberSeries = errors.New("invalid invoice number series")
+	ErrInvoicePrefixTaken         = errors.New("invoice number prefix is used by another user group")
+
+	ErrInvalidInvoiceTemplate  = errors.New("invalid invoice template")
+	ErrInvoiceTemplateNotFound = errors.New("invoice template not found")
+
+	ErrInvalidParty    = errors.New("invalid party")
+	ErrPartyNotFound   = errors.New("party not found")
+	ErrInvalidEInvoice = errors.New("invalid e-invoice")
+
+	ErrInvalidExportFilter = errors.New("invalid export filter")
+
+	ErrInvalidWebhook          = errors.New("invalid webhook")
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+	ErrInvalidPayment        = errors.New("invalid payment")
+	ErrPaymentReferenceTaken = errors.New("payment reference is recorded for another billing")
+
+	ErrPaymentGateway        = errors.New("payment gateway error")
+	ErrPaymentGatewayTimeout = errors.New("payment gateway timed out")
+	ErrChargeNotFound        = errors.New("charge not found")
+	ErrInvalidChargeState    = errors.New("charge is not in a state allowing the operation")
+
+	ErrInvalidDunningSchedule = errors.New("invalid dunning schedule")
+	ErrDunningNotFound        = errors.New("dunning not found")
+
+	ErrPaymentNotFound         = errors.New("payment not found")
+	ErrInvalidRefund           = errors.New("invalid refund")
+	ErrRefundNotFound          = errors.New("refund not found")
+	ErrRefundExceedsPayment    = errors.New("refunds exceed the payment")
+	ErrRefundExceedsCreditNote = errors.New("refunds exceed the credit note")
+	ErrRefundKeyReused         = errors.New("idempotency key was used for a different refund")
+
+	ErrInvalidBankStatement    = errors.New("invalid bank statement")
+	ErrBankStatementNotFound   = errors.New("bank statement not found")
+	ErrBankTransactionNotFound = errors.New("bank transaction not found")
+	ErrBankTransactionReviewed = errors.New("bank transaction is already reviewed")
+
+	ErrUnbalancedJournalEntry = errors.New("journal entry debits do not equal its credits")
+
+	ErrInvalidWalletTransaction  = errors.New("invalid wallet transaction")
+	ErrInsufficientWalletBalance = errors.New("wallet balance is insufficient")
+
+	ErrInvalidSpendLimit  = errors.New("invalid spend limit")
+	ErrSpendLimitExceeded = errors.New("spend limit exceeded")
+
+	ErrInvalidMeter        = errors.New("invalid meter")
+	ErrMeterNotFound       = errors.New("meter not found")
+	ErrMeterCodeTaken      = errors.New("meter code already exists")
+	ErrInvalidUsageEvent   = errors.New("invalid usage event")
+	ErrUsageAmountTooLarge = errors.New("usage amount is too large")
 )
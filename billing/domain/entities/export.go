@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"time"
+)
+
+type ExportFormat = string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+func IsValidExportFormat(format string) bool {
+	return format == ExportFormatCSV || format == ExportFormatNDJSON
+}
+
+// ExportFilter selects the billings created in [From, To), optionally of a user, currency and status
+type ExportFilter struct {
+	From     time.Time
+	To       time.Time
+	UserID   string
+	Currency string
+	Status   BillingStatus
+}
+
+func (f *ExportFilter) Validate() error {
+	if f.From.IsZero() || f.To.IsZero() || !f.From.Before(f.To) {
+		return ErrInvalidExportFilter
+	}
+	// pending_closure is never stored, billings are open until the close workflow closes them
+	if f.Status != "" && f.Status != BillingStatusOpen && f.Status != BillingStatusClosed && f.Status != BillingStatusCancelled {
+		return ErrInvalidExportFilter
+	}
+	return nil
+}
+
+// ExportRow is a line item with the columns of its billing denormalised, the unit of billing exports
+type ExportRow struct {
+	BillingID           string     `json:"billing_id"`
+	UserID              string     `json:"user_id"`
+	UserGroup           string     `json:"user_group"`
+	BillingDescription  string     `json:"billing_description"`
+	Currency            string     `json:"currency"`
+	CurrencyPrecision   int64      `json:"currency_precision"`
+	Status              string     `json:"status"`
+	InvoiceNumber       string     `json:"invoice_number"`
+	BillingCreatedAt    time.Time  `json:"billing_created_at"`
+	BillingClosedAt     *time.Time `json:"billing_closed_at"`
+	LineItemID          string     `json:"line_item_id"`
+	Kind                string     `json:"kind"`
+	Description         string     `json:"description"`
+	AmountMinor         int64      `json:"amount_minor"`
+	TaxCode             string     `json:"tax_code"`
+	Reason              string     `json:"reason"`
+	ReferenceLineItemID string     `json:"reference_line_item_id"`
+	LineItemCreatedAt   time.Time  `json:"line_item_created_at"`
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExportFilter_Validate(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   ExportFilter
+		expected error
+	}{
+		{name: "date range", filter: ExportFilter{From: from, To: to}, expected: nil},
+		{name: "all filters", filter: ExportFilter{From: from, To: to, UserID: "user-1", Currency: "USD", Status: BillingStatusClosed}, expected: nil},
+		{name: "missing from", filter: ExportFilter{To: to}, expected: ErrInvalidExportFilter},
+		{name: "missing to", filter: ExportFilter{From: from}, expected: ErrInvalidExportFilter},
+		{name: "empty range", filter: ExportFilter{From: from, To: from}, expected: ErrInvalidExportFilter},
+		{name: "reversed range", filter: ExportFilter{From: to, To: from}, expected: ErrInvalidExportFilter},
+		{name: "cancelled status", filter: ExportFilter{From: from, To: to, Status: BillingStatusCancelled}, expected: nil},
+		{name: "pending closure status", filter: ExportFilter{From: from, To: to, Status: BillingStatusPendingClosure}, expected: ErrInvalidExportFilter},
+		{name: "unknown status", filter: ExportFilter{From: from, To: to, Status: "void"}, expected: ErrInvalidExportFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); err != tt.expected {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
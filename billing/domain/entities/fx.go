@@ -1,12 +1,75 @@
 package entities
 
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
 type CurrencyMetadata struct {
 	Code      string `json:"code"`
 	Symbol    string `json:"symbol"`
 	Precision int64  `json:"precision"`
 }
 
+// CanRepresent reports whether amount fits the precision of the currency
+func (c *CurrencyMetadata) CanRepresent(amount float64) bool {
+	return hasAtMostXDecimals(amount, c.Precision)
+}
+
+// ToMinorUnits converts an amount in major units to minor units of the currency
+func (c *CurrencyMetadata) ToMinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * math.Pow10(int(c.Precision))))
+}
+
+// FormatAmountMinor formats an amount in minor units with the symbol and precision of the currency, e.g. -1234 as -$12.34
+func (c *CurrencyMetadata) FormatAmountMinor(amountMinor int64) string {
+	symbol := c.Symbol
+	if symbol == "" {
+		symbol = c.Code + " "
+	}
+
+	formatted := FormatMinorUnits(amountMinor, c.Precision)
+	if amountMinor < 0 {
+		return "-" + symbol + formatted[1:]
+	}
+	return symbol + formatted
+}
+
+// FormatMinorUnits formats an amount in minor units as a decimal number with precision decimals, e.g. 1234 with precision 2 as 12.34
+func FormatMinorUnits(amountMinor int64, precision int64) string {
+	sign := ""
+	if amountMinor < 0 {
+		sign = "-"
+		amountMinor = -amountMinor
+	}
+
+	digits := fmt.Sprintf("%0*d", precision+1, amountMinor)
+	whole, fraction := digits[:len(digits)-int(precision)], digits[len(digits)-int(precision):]
+	if precision == 0 {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
 type CurrencyRate struct {
 	Rate      int64 `json:"rate"`
 	Precision int64 `json:"precision"`
 }
+
+// ConvertAmountMinor converts an amount in minor units between currencies through their rates, which are quoted per USD,
+// the result is rounded half away from zero to the precision of the target currency
+func ConvertAmountMinor(amountMinor int64, fromPrecision int64, from CurrencyRate, toPrecision int64, to CurrencyRate) int64 {
+	// amount / 10^fromPrecision / (from.Rate / 10^from.Precision) * (to.Rate / 10^to.Precision) * 10^toPrecision
+	numerator := big.NewInt(amountMinor)
+	numerator.Mul(numerator, big.NewInt(to.Rate))
+	numerator.Mul(numerator, pow10(from.Precision+toPrecision))
+	denominator := big.NewInt(from.Rate)
+	denominator.Mul(denominator, pow10(to.Precision+fromPrecision))
+
+	return divRoundHalfAwayFromZero(numerator, denominator)
+}
+
+func pow10(exponent int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(exponent), nil)
+}
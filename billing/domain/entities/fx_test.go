@@ -0,0 +1,57 @@
+package entities
+
+import "testing"
+
+func TestCurrencyMetadata_FormatAmountMinor(t *testing.T) {
+	tests := []struct {
+		name        string
+		currency    CurrencyMetadata
+		amountMinor int64
+		expected    string
+	}{
+		{name: "symbol", currency: CurrencyMetadata{Code: "USD", Symbol: "$", Precision: 2}, amountMinor: 1234, expected: "$12.34"},
+		{name: "less than one", currency: CurrencyMetadata{Code: "USD", Symbol: "$", Precision: 2}, amountMinor: 5, expected: "$0.05"},
+		{name: "negative", currency: CurrencyMetadata{Code: "GEL", Symbol: "₾", Precision: 2}, amountMinor: -1050, expected: "-₾10.50"},
+		{name: "no decimals", currency: CurrencyMetadata{Code: "JPY", Symbol: "¥", Precision: 0}, amountMinor: 1234, expected: "¥1234"},
+		{name: "no symbol", currency: CurrencyMetadata{Code: "CHF", Precision: 2}, amountMinor: 1234, expected: "CHF 12.34"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.currency.FormatAmountMinor(tt.amountMinor); got != tt.expected {
+				t.Errorf("FormatAmountMinor(%v) = %v, expected %v", tt.amountMinor, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertAmountMinor(t *testing.T) {
+	usd := CurrencyRate{Rate: 100, Precision: 2}
+	gel := CurrencyRate{Rate: 270, Precision: 2}
+	jpy := CurrencyRate{Rate: 15000, Precision: 2}
+
+	tests := []struct {
+		name          string
+		amountMinor   int64
+		fromPrecision int64
+		from          CurrencyRate
+		toPrecision   int64
+		to            CurrencyRate
+		expected      int64
+	}{
+		{name: "same currency", amountMinor: 1234, fromPrecision: 2, from: usd, toPrecision: 2, to: usd, expected: 1234},
+		{name: "usd to gel", amountMinor: 1000, fromPrecision: 2, from: usd, toPrecision: 2, to: gel, expected: 2700},
+		{name: "gel to usd rounds", amountMinor: 1000, fromPrecision: 2, from: gel, toPrecision: 2, to: usd, expected: 370},
+		{name: "usd to currency without decimals", amountMinor: 1050, fromPrecision: 2, from: usd, toPrecision: 0, to: jpy, expected: 1575},
+		{name: "currency without decimals to usd", amountMinor: 1575, fromPrecision: 0, from: jpy, toPrecision: 2, to: usd, expected: 1050},
+		{name: "negative rounds away from zero", amountMinor: -1000, fromPrecision: 2, from: gel, toPrecision: 2, to: usd, expected: -370},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertAmountMinor(tt.amountMinor, tt.fromPrecision, tt.from, tt.toPrecision, tt.to); got != tt.expected {
+				t.Errorf("ConvertAmountMinor(%v) = %v, expected %v", tt.amountMinor, got, tt.expected)
+			}
+		})
+	}
+}
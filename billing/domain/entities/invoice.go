@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"time"
+)
+
+type InvoiceFormat = string
+
+const (
+	InvoiceFormatHTML InvoiceFormat = "html"
+	InvoiceFormatPDF  InvoiceFormat = "pdf"
+)
+
+func IsValidInvoiceFormat(format string) bool {
+	return format == InvoiceFormatHTML || format == InvoiceFormatPDF
+}
+
+// Invoice is what invoice templates are rendered with: the summary a billing was closed with,
+// its line items with the adjustments grouped under them, and the currency amounts are formatted in
+type Invoice struct {
+	InvoiceNumber string
+	IssuedAt      time.Time
+	Tenant        string
+	UserID        string
+	Currency      CurrencyMetadata
+	Summary       BillingSummary
+	LineItems     []LineItemGroup
+
+	// Seller and Buyer identify the parties of e-invoices, they are not needed to render documents
+	Seller *Party
+	Buyer  *Party
+}
+
+// NewInvoice returns the invoice of a closed billing
+func NewInvoice(billing *Billing, summary *BillingSummary, currency CurrencyMetadata) Invoice {
+	invoice := Invoice{
+		InvoiceNumber: summary.InvoiceNumber,
+		Tenant:        billing.UserGroup,
+		UserID:        billing.UserID,
+		Currency:      currency,
+		Summary:       *summary,
+		LineItems:     GroupLineItems(summary.LineItems),
+	}
+	if billing.ActualClosedAt != nil {
+		invoice.IssuedAt = *billing.ActualClosedAt
+	}
+
+	// billings closed before invoice numbering have no number in their summary
+	if invoice.InvoiceNumber == "" && billing.InvoiceNumber != nil {
+		invoice.InvoiceNumber = *billing.InvoiceNumber
+	}
+
+	return invoice
+}
+
+// InvoiceTemplate overrides the default invoice template of a format for a tenant
+type InvoiceTemplate struct {
+	Tenant    string        `json:"tenant"`
+	Format    InvoiceFormat `json:"format"`
+	Source    string        `json:"source"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
@@ -0,0 +1,53 @@
+package entities
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	DefaultInvoiceNumberPrefix  = "INV"
+	DefaultInvoiceNumberPadding = 6
+)
+
+var invoiceNumberPrefixPattern = regexp.MustCompile(`^[A-Z0-9]+(-[A-Z0-9]+)*$`)
+
+// InvoiceNumberSeries configures the invoice numbers of a user group, e.g. INV-2026-000123.
+// Each prefix is numbered from 1 every year without gaps, the empty user group is the default series.
+type InvoiceNumberSeries struct {
+	UserGroup string    `json:"user_group"`
+	Prefix    string    `json:"prefix"`
+	Padding   int64     `json:"padding"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DefaultInvoiceNumberSeries is used for user groups without a series of their own
+func DefaultInvoiceNumberSeries() InvoiceNumberSeries {
+	return InvoiceNumberSeries{
+		Prefix:  DefaultInvoiceNumberPrefix,
+		Padding: DefaultInvoiceNumberPadding,
+	}
+}
+
+func (s *InvoiceNumberSeries) Validate() error {
+	if !invoiceNumberPrefixPattern.MatchString(s.Prefix) || s.Padding < 1 || s.Padding > 12 {
+		return ErrInvalidInvoiceNumberSeries
+	}
+	return nil
+}
+
+// Format returns the invoice number with the given sequence number in year
+func (s *InvoiceNumberSeries) Format(year int, number int64) string {
+	return fmt.Sprintf("%s-%d-%0*d", s.Prefix, year, s.Padding, number)
+}
+
+// InvoiceYear returns the year a billing closed at closedAt is numbered in, in the timezone of the billing
+func InvoiceYear(closedAt time.Time, timezone string) (int, error) {
+	loc, err := LoadTimezone(timezone)
+	if err != nil {
+		return 0, err
+	}
+	return closedAt.In(loc).Year(), nil
+}
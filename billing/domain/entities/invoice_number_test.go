@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInvoiceNumberSeries_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		series   InvoiceNumberSeries
+		expected error
+	}{
+		{name: "default series", series: DefaultInvoiceNumberSeries(), expected: nil},
+		{name: "prefix with dashes", series: InvoiceNumberSeries{UserGroup: "eu", Prefix: "INV-EU", Padding: 8}, expected: nil},
+		{name: "empty prefix", series: InvoiceNumberSeries{Padding: 6}, expected: ErrInvalidInvoiceNumberSeries},
+		{name: "lowercase prefix", series: InvoiceNumberSeries{Prefix: "inv", Padding: 6}, expected: ErrInvalidInvoiceNumberSeries},
+		{name: "trailing dash", series: InvoiceNumberSeries{Prefix: "INV-", Padding: 6}, expected: ErrInvalidInvoiceNumberSeries},
+		{name: "no padding", series: InvoiceNumberSeries{Prefix: "INV", Padding: 0}, expected: ErrInvalidInvoiceNumberSeries},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.series.Validate(); !errors.Is(err, tt.expected) {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInvoiceNumberSeries_Format(t *testing.T) {
+	series := DefaultInvoiceNumberSeries()
+	if got := series.Format(2026, 123); got != "INV-2026-000123" {
+		t.Errorf("Format() = %v, expected %v", got, "INV-2026-000123")
+	}
+
+	// numbers beyond the padding are not truncated
+	series.Padding = 2
+	if got := series.Format(2026, 123); got != "INV-2026-123" {
+		t.Errorf("Format() = %v, expected %v", got, "INV-2026-123")
+	}
+}
+
+func TestInvoiceYear(t *testing.T) {
+	closedAt := time.Date(2025, 12, 31, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		timezone string
+		expected int
+	}{
+		{timezone: "UTC", expected: 2025},
+		{timezone: "Asia/Tbilisi", expected: 2026},
+		{timezone: "America/New_York", expected: 2025},
+	}
+
+	for _, tt := range tests {
+		got, err := InvoiceYear(closedAt, tt.timezone)
+		if err != nil {
+			t.Fatalf("InvoiceYear() failed: %v", err)
+		}
+		if got != tt.expected {
+			t.Errorf("InvoiceYear(%v) = %v, expected %v", tt.timezone, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package entities
+
+import (
+	"math/big"
+	"time"
+)
+
+type LedgerAccountCode = string
+
+// the chart of accounts, seeded by the ledger and wallet migrations
+const (
+	LedgerAccountCash           LedgerAccountCode = "cash"
+	LedgerAccountReceivable     LedgerAccountCode = "accounts_receivable"
+	LedgerAccountTaxPayable     LedgerAccountCode = "tax_payable"
+	LedgerAccountRevenue        LedgerAccountCode = "revenue"
+	LedgerAccountSalesReturns   LedgerAccountCode = "sales_returns"
+	LedgerAccountCustomerCredit LedgerAccountCode = "customer_credit"
+)
+
+type LedgerAccountType = string
+
+const (
+	LedgerAccountTypeAsset         LedgerAccountType = "asset"
+	LedgerAccountTypeLiability     LedgerAccountType = "liability"
+	LedgerAccountTypeRevenue       LedgerAccountType = "revenue"
+	LedgerAccountTypeContraRevenue LedgerAccountType = "contra_revenue"
+)
+
+type LedgerDirection = string
+
+const (
+	LedgerDirectionDebit  LedgerDirection = "debit"
+	LedgerDirectionCredit LedgerDirection = "credit"
+)
+
+type JournalEntryKind = string
+
+const (
+	JournalEntryKindBillingClosed    JournalEntryKind = "billing_closed"
+	JournalEntryKindPaymentRecorded  JournalEntryKind = "payment_recorded"
+	JournalEntryKindRefundSucceeded  JournalEntryKind = "refund_succeeded"
+	JournalEntryKindCreditNoteIssued JournalEntryKind = "credit_note_issued"
+	JournalEntryKindWalletToppedUp   JournalEntryKind = "wallet_topped_up"
+	JournalEntryKindWalletAdjusted   JournalEntryKind = "wallet_adjusted"
+)
+
+// LedgerPosting moves a positive amount in or out of an account
+type LedgerPosting struct {
+	AccountCode LedgerAccountCode `json:"account"`
+	Direction   LedgerDirection   `json:"direction"`
+	AmountMinor int64             `json:"amount_minor"`
+}
+
+// JournalEntry is an append-only record of a billing operation in the ledger, its debits equal its credits. An operation
+// is posted once, it is identified by its kind and the ID of its source: the billing, payment, refund, credit note or wallet
+// transaction. Wallet transactions are about no billing, their BillingID is zero.
+type JournalEntry struct {
+	ID              int64  `json:"-"`
+	ExternalEntryID string `json:"entry_id"`
+
+	Kind      JournalEntryKind `json:"kind"`
+	SourceID  int64            `json:"-"`
+	BillingID int64            `json:"-"`
+	Currency  string           `json:"currency"`
+
+	Description string          `json:"description"`
+	Postings    []LedgerPosting `json:"postings"`
+
+	PostedAt  time.Time `json:"posted_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// post adds a posting, a negative amount is posted in the other direction and a zero amount is not posted
+func (e *JournalEntry) post(accountCode LedgerAccountCode, direction LedgerDirection, amountMinor int64) {
+	if amountMinor == 0 {
+		return
+	}
+	if amountMinor < 0 {
+		amountMinor = -amountMinor
+		direction = oppositeLedgerDirection(direction)
+	}
+
+	e.Postings = append(e.Postings, LedgerPosting{AccountCode: accountCode, Direction: direction, AmountMinor: amountMinor})
+}
+
+// IsBalanced reports whether the debits of the entry equal its credits
+func (e *JournalEntry) IsBalanced() bool {
+	var debitMinor, creditMinor int64
+	for _, posting := range e.Postings {
+		if posting.Direction == LedgerDirectionDebit {
+			debitMinor += posting.AmountMinor
+		} else {
+			creditMinor += posting.AmountMinor
+		}
+	}
+	return debitMinor == creditMinor
+}
+
+// IsEmpty reports whether the entry moves nothing, e.g. the close of a billing of zero, and is not posted
+func (e *JournalEntry) IsEmpty() bool {
+	return len(e.Postings) == 0
+}
+
+// NewBillingClosedJournalEntry invoices the grand total of a closed billing: the user owes it, net of tax it is revenue and
+// the tax is owed to the tax authority
+func NewBillingClosedJournalEntry(billingID int64, summary BillingSummary, closedAt time.Time) *JournalEntry {
+	entry := &JournalEntry{
+		Kind:        JournalEntryKindBillingClosed,
+		SourceID:    billingID,
+		BillingID:   billingID,
+		Currency:    summary.Currency,
+		Description: "billing " + summary.ExternalBillingID + " closed",
+		PostedAt:    closedAt,
+	}
+	if summary.InvoiceNumber != "" {
+		entry.Description = "invoice " + summary.InvoiceNumber + " issued"
+	}
+
+	entry.post(LedgerAccountReceivable, LedgerDirectionDebit, summary.GrandTotalAmountMinor)
+	entry.post(LedgerAccountRevenue, LedgerDirectionCredit, summary.GrandTotalAmountMinor-summary.TaxAmountMinor)
+	entry.post(LedgerAccountTaxPayable, LedgerDirectionCredit, summary.TaxAmountMinor)
+	return entry
+}
+
+// NewPaymentJournalEntry receives a payment against what the user owes, a payment from the wallet of the user spends
+// their credit instead of bringing in cash
+func NewPaymentJournalEntry(payment *Payment) *JournalEntry {
+	entry := &JournalEntry{
+		Kind:        JournalEntryKindPaymentRecorded,
+		SourceID:    payment.ID,
+		BillingID:   payment.BillingID,
+		Currency:    payment.Currency,
+		Description: "payment " + payment.ExternalPaymentID + " by " + payment.Method,
+		PostedAt:    payment.PaidAt,
+	}
+
+	debitAccountCode := LedgerAccountCash
+	if payment.Method == PaymentMethodWallet {
+		debitAccountCode = LedgerAccountCustomerCredit
+	}
+
+	entry.post(debitAccountCode, LedgerDirectionDebit, payment.AmountMinor)
+	entry.post(LedgerAccountReceivable, LedgerDirectionCredit, payment.AmountMinor)
+	return entry
+}
+
+// NewRefundJournalEntry pays money back, which the user is owed again until a credit note settles it
+func NewRefundJournalEntry(refund *Refund, refundedAt time.Time) *JournalEntry {
+	entry := &JournalEntry{
+		Kind:        JournalEntryKindRefundSucceeded,
+		SourceID:    refund.ID,
+		BillingID:   refund.BillingID,
+		Currency:    refund.Currency,
+		Description: "refund " + refund.ExternalRefundID + " of payment " + refund.ExternalPaymentID,
+		PostedAt:    refundedAt,
+	}
+
+	entry.post(LedgerAccountReceivable, LedgerDirectionDebit, refund.AmountMinor)
+	entry.post(LedgerAccountCash, LedgerDirectionCredit, refund.AmountMinor)
+	return entry
+}
+
+// NewCreditNoteJournalEntry reverses part of a billing: the user owes less, and the tax of the billing is reversed in
+// proportion to the credited share of its grand total
+func NewCreditNoteJournalEntry(creditNote *CreditNote, taxAmountMinor int64, grandTotalAmountMinor int64) *JournalEntry {
+	entry := &JournalEntry{
+		Kind:        JournalEntryKindCreditNoteIssued,
+		SourceID:    creditNote.ID,
+		BillingID:   creditNote.BillingID,
+		Currency:    creditNote.Currency,
+		Description: "credit note " + creditNote.Number + " issued",
+		PostedAt:    creditNote.CreatedAt,
+	}
+
+	creditedTaxMinor := CreditedTaxAmountMinor(creditNote.TotalAmountMinor, taxAmountMinor, grandTotalAmountMinor)
+	entry.post(LedgerAccountSalesReturns, LedgerDirectionDebit, creditNote.TotalAmountMinor-creditedTaxMinor)
+	entry.post(LedgerAccountTaxPayable, LedgerDirectionDebit, creditedTaxMinor)
+	entry.post(LedgerAccountReceivable, LedgerDirectionCredit, creditNote.TotalAmountMinor)
+	return entry
+}
+
+// NewWalletJournalEntry records credit given to a user: a top-up is cash held for them, an adjustment is an allowance, which
+// a negative adjustment takes back. Billing draws are posted as the wallet payments they record.
+func NewWalletJournalEntry(transaction *WalletTransaction) *JournalEntry {
+	entry := &JournalEntry{
+		Kind:        JournalEntryKindWalletAdjusted,
+		SourceID:    transaction.ID,
+		Currency:    transaction.Currency,
+		Description: "wallet of " + transaction.UserID + " adjusted: " + transaction.Reason,
+		PostedAt:    transaction.CreatedAt,
+	}
+	debitAccountCode := LedgerAccountSalesReturns
+
+	if transaction.Kind == WalletTransactionKindTopUp {
+		entry.Kind = JournalEntryKindWalletToppedUp
+		entry.Description = "wallet of " + transaction.UserID + " topped up"
+		debitAccountCode = LedgerAccountCash
+	}
+
+	entry.post(debitAccountCode, LedgerDirectionDebit, transaction.AmountMinor)
+	entry.post(LedgerAccountCustomerCredit, LedgerDirectionCredit, transaction.AmountMinor)
+	return entry
+}
+
+// CreditedTaxAmountMinor is the tax in a credit of creditedAmountMinor on a billing of grandTotalAmountMinor with
+// taxAmountMinor of tax, rounded half away from zero
+func CreditedTaxAmountMinor(creditedAmountMinor int64, taxAmountMinor int64, grandTotalAmountMinor int64) int64 {
+	if taxAmountMinor == 0 || grandTotalAmountMinor <= 0 {
+		return 0
+	}
+
+	numerator := new(big.Int).Mul(big.NewInt(creditedAmountMinor), big.NewInt(taxAmountMinor))
+	return divRoundHalfAwayFromZero(numerator, big.NewInt(grandTotalAmountMinor))
+}
+
+func oppositeLedgerDirection(direction LedgerDirection) LedgerDirection {
+	if direction == LedgerDirectionDebit {
+		return LedgerDirectionCredit
+	}
+	return LedgerDirectionDebit
+}
+
+// LedgerAccount is an account of the chart of accounts, its balance is positive on the side of its normal balance
+type LedgerAccount struct {
+	Code          LedgerAccountCode `json:"code"`
+	Name          string            `json:"name"`
+	Type          LedgerAccountType `json:"type"`
+	NormalBalance LedgerDirection   `json:"normal_balance"`
+}
+
+// LedgerAccountBalance is what was posted to an account in a currency
+type LedgerAccountBalance struct {
+	LedgerAccount
+
+	Currency          string `json:"currency"`
+	DebitAmountMinor  int64  `json:"debit_amount_minor"`
+	CreditAmountMinor int64  `json:"credit_amount_minor"`
+	BalanceMinor      int64  `json:"balance_minor"`
+}
+
+// NewLedgerAccountBalance nets the debits and credits of an account on the side of its normal balance
+func NewLedgerAccountBalance(account LedgerAccount, currency string, debitAmountMinor int64, creditAmountMinor int64) LedgerAccountBalance {
+	balance := LedgerAccountBalance{
+		LedgerAccount:     account,
+		Currency:          currency,
+		DebitAmountMinor:  debitAmountMinor,
+		CreditAmountMinor: creditAmountMinor,
+		BalanceMinor:      debitAmountMinor - creditAmountMinor,
+	}
+	if account.NormalBalance == LedgerDirectionCredit {
+		balance.BalanceMinor = -balance.BalanceMinor
+	}
+	return balance
+}
+
+// LedgerTotals are the debits and credits posted in a currency
+type LedgerTotals struct {
+	Currency          string `json:"currency"`
+	DebitAmountMinor  int64  `json:"debit_amount_minor"`
+	CreditAmountMinor int64  `json:"credit_amount_minor"`
+}
+
+// LedgerCheck is the outcome of checking the ledger invariant: in every currency and in every entry, debits equal credits
+type LedgerCheck struct {
+	Totals                     []LedgerTotals `json:"totals"`
+	UnbalancedExternalEntryIDs []string       `json:"unbalanced_entry_ids"`
+}
+
+// IsBalanced reports whether the ledger holds its invariant
+func (c *LedgerCheck) IsBalanced() bool {
+	if len(c.UnbalancedExternalEntryIDs) > 0 {
+		return false
+	}
+	for _, totals := range c.Totals {
+		if totals.DebitAmountMinor != totals.CreditAmountMinor {
+			return false
+		}
+	}
+	return true
+}
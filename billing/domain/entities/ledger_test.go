@@ -0,0 +1,212 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewBillingClosedJournalEntry(t *testing.T) {
+	closedAt := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		summary  BillingSummary
+		expected []LedgerPosting
+	}{
+		{
+			name:    "taxed",
+			summary: BillingSummary{Currency: "USD", InvoiceNumber: "INV-1", SubtotalAmountMinor: 1000, TaxAmountMinor: 200, GrandTotalAmountMinor: 1200},
+			expected: []LedgerPosting{
+				{AccountCode: LedgerAccountReceivable, Direction: LedgerDirectionDebit, AmountMinor: 1200},
+				{AccountCode: LedgerAccountRevenue, Direction: LedgerDirectionCredit, AmountMinor: 1000},
+				{AccountCode: LedgerAccountTaxPayable, Direction: LedgerDirectionCredit, AmountMinor: 200},
+			},
+		},
+		{
+			name:    "untaxed",
+			summary: BillingSummary{Currency: "USD", SubtotalAmountMinor: 1000, GrandTotalAmountMinor: 1000},
+			expected: []LedgerPosting{
+				{AccountCode: LedgerAccountReceivable, Direction: LedgerDirectionDebit, AmountMinor: 1000},
+				{AccountCode: LedgerAccountRevenue, Direction: LedgerDirectionCredit, AmountMinor: 1000},
+			},
+		},
+		{
+			name:    "negative grand total is posted the other way",
+			summary: BillingSummary{Currency: "USD", SubtotalAmountMinor: -500, GrandTotalAmountMinor: -500},
+			expected: []LedgerPosting{
+				{AccountCode: LedgerAccountReceivable, Direction: LedgerDirectionCredit, AmountMinor: 500},
+				{AccountCode: LedgerAccountRevenue, Direction: LedgerDirectionDebit, AmountMinor: 500},
+			},
+		},
+		{
+			name:     "zero grand total moves nothing",
+			summary:  BillingSummary{Currency: "USD"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := NewBillingClosedJournalEntry(1, tt.summary, closedAt)
+			if !entry.IsBalanced() {
+				t.Errorf("expected entry to be balanced: %+v", entry.Postings)
+			}
+			if entry.IsEmpty() != (len(tt.expected) == 0) {
+				t.Errorf("IsEmpty() = %v, expected %v", entry.IsEmpty(), len(tt.expected) == 0)
+			}
+			if len(entry.Postings) != len(tt.expected) {
+				t.Fatalf("expected postings %+v, got %+v", tt.expected, entry.Postings)
+			}
+			for i := range tt.expected {
+				if entry.Postings[i] != tt.expected[i] {
+					t.Errorf("posting %d = %+v, expected %+v", i, entry.Postings[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewCreditNoteJournalEntry(t *testing.T) {
+	creditNote := &CreditNote{ID: 7, BillingID: 1, Number: "CN-000007", Currency: "USD", TotalAmountMinor: 600}
+
+	// a third of a billing of 1800 with 300 of tax reverses a third of the tax
+	entry := NewCreditNoteJournalEntry(creditNote, 300, 1800)
+
+	expected := []LedgerPosting{
+		{AccountCode: LedgerAccountSalesReturns, Direction: LedgerDirectionDebit, AmountMinor: 500},
+		{AccountCode: LedgerAccountTaxPayable, Direction: LedgerDirectionDebit, AmountMinor: 100},
+		{AccountCode: LedgerAccountReceivable, Direction: LedgerDirectionCredit, AmountMinor: 600},
+	}
+	if len(entry.Postings) != len(expected) {
+		t.Fatalf("expected postings %+v, got %+v", expected, entry.Postings)
+	}
+	for i := range expected {
+		if entry.Postings[i] != expected[i] {
+			t.Errorf("posting %d = %+v, expected %+v", i, entry.Postings[i], expected[i])
+		}
+	}
+	if entry.Kind != JournalEntryKindCreditNoteIssued || entry.SourceID != 7 || !entry.IsBalanced() {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestCreditedTaxAmountMinor(t *testing.T) {
+	tests := []struct {
+		name                  string
+		creditedAmountMinor   int64
+		taxAmountMinor        int64
+		grandTotalAmountMinor int64
+		expected              int64
+	}{
+		{name: "full credit", creditedAmountMinor: 1200, taxAmountMinor: 200, grandTotalAmountMinor: 1200, expected: 200},
+		{name: "rounds half away from zero", creditedAmountMinor: 5, taxAmountMinor: 1, grandTotalAmountMinor: 10, expected: 1},
+		{name: "rounds down", creditedAmountMinor: 100, taxAmountMinor: 200, grandTotalAmountMinor: 1200, expected: 17},
+		{name: "untaxed", creditedAmountMinor: 100, taxAmountMinor: 0, grandTotalAmountMinor: 1200, expected: 0},
+		{name: "no grand total", creditedAmountMinor: 100, taxAmountMinor: 10, grandTotalAmountMinor: 0, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CreditedTaxAmountMinor(tt.creditedAmountMinor, tt.taxAmountMinor, tt.grandTotalAmountMinor); got != tt.expected {
+				t.Errorf("CreditedTaxAmountMinor() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPaymentAndRefundJournalEntries(t *testing.T) {
+	payment := &Payment{ID: 3, BillingID: 1, ExternalPaymentID: "p", AmountMinor: 1000, Currency: "USD", Method: PaymentMethodCard}
+	refund := &Refund{ID: 4, BillingID: 1, ExternalRefundID: "r", ExternalPaymentID: "p", AmountMinor: 400, Currency: "USD"}
+
+	paid := NewPaymentJournalEntry(payment)
+	refunded := NewRefundJournalEntry(refund, time.Now())
+
+	balances := map[LedgerAccountCode]int64{}
+	for _, entry := range []*JournalEntry{paid, refunded} {
+		if !entry.IsBalanced() {
+			t.Errorf("expected %v entry to be balanced: %+v", entry.Kind, entry.Postings)
+		}
+		for _, posting := range entry.Postings {
+			if posting.Direction == LedgerDirectionDebit {
+				balances[posting.AccountCode] += posting.AmountMinor
+			} else {
+				balances[posting.AccountCode] -= posting.AmountMinor
+			}
+		}
+	}
+
+	// the refund reopens what the payment settled
+	if balances[LedgerAccountCash] != 600 || balances[LedgerAccountReceivable] != -600 {
+		t.Errorf("unexpected balances: %v", balances)
+	}
+	if paid.SourceID != 3 || refunded.SourceID != 4 {
+		t.Errorf("unexpected sources: %v, %v", paid.SourceID, refunded.SourceID)
+	}
+}
+
+func TestWalletJournalEntries(t *testing.T) {
+	topUp := &WalletTransaction{ID: 5, UserID: "user-1", Currency: "USD", Kind: WalletTransactionKindTopUp, AmountMinor: 1000}
+	adjustment := &WalletTransaction{ID: 6, UserID: "user-1", Currency: "USD", Kind: WalletTransactionKindAdjustment, AmountMinor: -200, Reason: "goodwill reversed"}
+	payment := &Payment{ID: 7, BillingID: 1, ExternalPaymentID: "p", AmountMinor: 500, Currency: "USD", Method: PaymentMethodWallet}
+
+	toppedUp := NewWalletJournalEntry(topUp)
+	adjusted := NewWalletJournalEntry(adjustment)
+	drawn := NewPaymentJournalEntry(payment)
+
+	balances := map[LedgerAccountCode]int64{}
+	for _, entry := range []*JournalEntry{toppedUp, adjusted, drawn} {
+		if !entry.IsBalanced() {
+			t.Errorf("expected %v entry to be balanced: %+v", entry.Kind, entry.Postings)
+		}
+		for _, posting := range entry.Postings {
+			if posting.Direction == LedgerDirectionDebit {
+				balances[posting.AccountCode] += posting.AmountMinor
+			} else {
+				balances[posting.AccountCode] -= posting.AmountMinor
+			}
+		}
+	}
+
+	// the credit left is owed to the user, the draw settled part of the billing without bringing in cash
+	if balances[LedgerAccountCustomerCredit] != -300 || balances[LedgerAccountCash] != 1000 {
+		t.Errorf("unexpected balances: %v", balances)
+	}
+	if balances[LedgerAccountSalesReturns] != -200 || balances[LedgerAccountReceivable] != -500 {
+		t.Errorf("unexpected balances: %v", balances)
+	}
+	if toppedUp.Kind != JournalEntryKindWalletToppedUp || adjusted.Kind != JournalEntryKindWalletAdjusted {
+		t.Errorf("unexpected kinds: %v, %v", toppedUp.Kind, adjusted.Kind)
+	}
+	if toppedUp.BillingID != 0 || adjusted.SourceID != 6 {
+		t.Errorf("unexpected billing and source: %v, %v", toppedUp.BillingID, adjusted.SourceID)
+	}
+}
+
+func TestNewLedgerAccountBalance(t *testing.T) {
+	asset := LedgerAccount{Code: LedgerAccountCash, NormalBalance: LedgerDirectionDebit}
+	revenue := LedgerAccount{Code: LedgerAccountRevenue, NormalBalance: LedgerDirectionCredit}
+
+	if got := NewLedgerAccountBalance(asset, "USD", 1000, 300).BalanceMinor; got != 700 {
+		t.Errorf("expected debit normal balance 700, got %v", got)
+	}
+	if got := NewLedgerAccountBalance(revenue, "USD", 300, 1000).BalanceMinor; got != 700 {
+		t.Errorf("expected credit normal balance 700, got %v", got)
+	}
+}
+
+func TestLedgerCheck_IsBalanced(t *testing.T) {
+	balanced := &LedgerCheck{Totals: []LedgerTotals{{Currency: "USD", DebitAmountMinor: 100, CreditAmountMinor: 100}}}
+	if !balanced.IsBalanced() {
+		t.Errorf("expected ledger to be balanced")
+	}
+
+	unbalancedTotals := &LedgerCheck{Totals: []LedgerTotals{{Currency: "USD", DebitAmountMinor: 100, CreditAmountMinor: 90}}}
+	if unbalancedTotals.IsBalanced() {
+		t.Errorf("expected ledger with unequal totals not to be balanced")
+	}
+
+	unbalancedEntry := &LedgerCheck{UnbalancedExternalEntryIDs: []string{"entry"}}
+	if unbalancedEntry.IsBalanced() {
+		t.Errorf("expected ledger with an unbalanced entry not to be balanced")
+	}
+}
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxMessage is a billing event written in the transaction of the change it describes, the offset orders the messages
+type OutboxMessage struct {
+	Offset    int64            `json:"offset"`
+	EventID   string           `json:"event_id"`
+	EventType BillingEventType `json:"event_type"`
+	Payload   []byte           `json:"payload"`
+	CreatedAt time.Time        `json:"created_at"`
+	SentAt    *time.Time       `json:"sent_at,omitempty"`
+}
+
+// NewOutboxMessage encodes an event as an outbox message, the offset is assigned when it is written
+func NewOutboxMessage(eventID string, eventType BillingEventType, event any) (*OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxMessage{
+		EventID:   eventID,
+		EventType: eventType,
+		Payload:   payload,
+	}, nil
+}
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNewOutboxMessage(t *testing.T) {
+	event := NewBillingCancelledEvent("billing-1", "duplicate", time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	message, err := NewOutboxMessage(event.EventID, event.Type, event)
+	if err != nil {
+		t.Fatalf("NewOutboxMessage() error = %v", err)
+	}
+	if message.EventID != "billing.cancelled:billing-1" {
+		t.Errorf("NewOutboxMessage() EventID = %v, expected %v", message.EventID, "billing.cancelled:billing-1")
+	}
+	if message.EventType != BillingEventCancelled {
+		t.Errorf("NewOutboxMessage() EventType = %v, expected %v", message.EventType, BillingEventCancelled)
+	}
+	if message.Offset != 0 || message.SentAt != nil {
+		t.Errorf("NewOutboxMessage() Offset = %v, SentAt = %v, expected an unwritten message", message.Offset, message.SentAt)
+	}
+
+	// the payload decodes back to the event
+	var decoded BillingCancelledEvent
+	if err := json.Unmarshal(message.Payload, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded != event {
+		t.Errorf("NewOutboxMessage() Payload = %+v, expected %+v", decoded, event)
+	}
+}
@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"regexp"
+	"time"
+)
+
+type PartyRole = string
+
+const (
+	PartyRoleSeller PartyRole = "seller"
+	PartyRoleBuyer  PartyRole = "buyer"
+)
+
+var (
+	countryCodePattern    = regexp.MustCompile(`^[A-Z]{2}$`)
+	endpointSchemePattern = regexp.MustCompile(`^[0-9]{4}$`)
+)
+
+// Party is the seller or buyer of invoices as e-invoices identify them. Sellers are keyed by tenant (the user group
+// of the billing) and buyers by user ID.
+type Party struct {
+	Role        PartyRole `json:"role"`
+	Key         string    `json:"key"`
+	Name        string    `json:"name"`
+	VATID       string    `json:"vat_id,omitempty"` // e.g. DE123456789, required for sellers
+	Street      string    `json:"street,omitempty"`
+	City        string    `json:"city,omitempty"`
+	PostalCode  string    `json:"postal_code,omitempty"`
+	CountryCode string    `json:"country_code"` // ISO 3166-1 alpha-2
+
+	// EndpointID is the electronic address invoices are delivered to, EndpointScheme its Peppol EAS code, e.g. 0088 for GLN
+	EndpointID     string `json:"endpoint_id"`
+	EndpointScheme string `json:"endpoint_scheme"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (p *Party) Validate() error {
+	if p.Role != PartyRoleSeller && p.Role != PartyRoleBuyer {
+		return ErrInvalidParty
+	}
+	if p.Name == "" || !countryCodePattern.MatchString(p.CountryCode) {
+		return ErrInvalidParty
+	}
+	if p.EndpointID == "" || !endpointSchemePattern.MatchString(p.EndpointScheme) {
+		return ErrInvalidParty
+	}
+
+	// sellers charge VAT, so e-invoices must carry their VAT identifier
+	if p.Role == PartyRoleSeller && p.VATID == "" {
+		return ErrInvalidParty
+	}
+
+	return nil
+}
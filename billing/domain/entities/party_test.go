@@ -0,0 +1,32 @@
+package entities
+
+import "testing"
+
+func TestParty_Validate(t *testing.T) {
+	valid := Party{Role: PartyRoleSeller, Key: "eu", Name: "Pave Bank GmbH", VATID: "DE123456789", CountryCode: "DE", EndpointID: "DE123456789", EndpointScheme: "9930"}
+
+	tests := []struct {
+		name     string
+		modify   func(p *Party)
+		expected error
+	}{
+		{name: "valid seller", modify: func(p *Party) {}, expected: nil},
+		{name: "buyer without VAT ID", modify: func(p *Party) { p.Role = PartyRoleBuyer; p.VATID = "" }, expected: nil},
+		{name: "seller without VAT ID", modify: func(p *Party) { p.VATID = "" }, expected: ErrInvalidParty},
+		{name: "unknown role", modify: func(p *Party) { p.Role = "payee" }, expected: ErrInvalidParty},
+		{name: "missing name", modify: func(p *Party) { p.Name = "" }, expected: ErrInvalidParty},
+		{name: "lowercase country", modify: func(p *Party) { p.CountryCode = "de" }, expected: ErrInvalidParty},
+		{name: "missing endpoint", modify: func(p *Party) { p.EndpointID = "" }, expected: ErrInvalidParty},
+		{name: "invalid endpoint scheme", modify: func(p *Party) { p.EndpointScheme = "GLN" }, expected: ErrInvalidParty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			party := valid
+			tt.modify(&party)
+			if err := party.Validate(); err != tt.expected {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package entities
+
+import (
+	"slices"
+	"time"
+)
+
+type PaymentStatus = string
+
+const (
+	PaymentStatusUnpaid        PaymentStatus = "unpaid"
+	PaymentStatusPartiallyPaid PaymentStatus = "partially_paid"
+	PaymentStatusPaid          PaymentStatus = "paid"
+	PaymentStatusOverpaid      PaymentStatus = "overpaid"
+)
+
+type PaymentMethod = string
+
+const (
+	PaymentMethodCard         PaymentMethod = "card"
+	PaymentMethodBankTransfer PaymentMethod = "bank_transfer"
+	PaymentMethodDirectDebit  PaymentMethod = "direct_debit"
+	PaymentMethodCash         PaymentMethod = "cash"
+	PaymentMethodOther        PaymentMethod = "other"
+
+	// PaymentMethodWallet is credit drawn from the wallet of the user when a billing closes, it is not recorded by hand
+	PaymentMethodWallet PaymentMethod = "wallet"
+)
+
+var paymentMethods = []PaymentMethod{PaymentMethodCard, PaymentMethodBankTransfer, PaymentMethodDirectDebit, PaymentMethodCash, PaymentMethodOther}
+
+// Payment is money received for a closed billing, in the currency of the billing.
+// The external reference identifies the payment at its provider, e.g. a card charge or a bank transfer.
+type Payment struct {
+	ID                int64  `json:"id"`
+	ExternalPaymentID string `json:"payment_id"`
+
+	BillingID         int64  `json:"-"`
+	ExternalBillingID string `json:"billing_id"`
+
+	AmountMinor       int64         `json:"amount_minor"`
+	Currency          string        `json:"currency"`
+	CurrencyPrecision int64         `json:"currency_precision"`
+	Method            PaymentMethod `json:"method"`
+	ExternalReference string        `json:"external_reference,omitempty"`
+	PaidAt            time.Time     `json:"paid_at"`
+
+	// RefundedAmountMinor is the sum of the succeeded refunds of the payment
+	RefundedAmountMinor int64 `json:"refunded_amount_minor"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NetAmountMinor is what the payment paid once its refunds are returned
+func (p *Payment) NetAmountMinor() int64 {
+	return p.AmountMinor - p.RefundedAmountMinor
+}
+
+// IsRefundable reports whether the payment can be paid back, credit drawn from a wallet is given back by adjusting the wallet
+func (p *Payment) IsRefundable() bool {
+	return p.Method != PaymentMethodWallet
+}
+
+// CanRefundWithAmount reports whether an amount fits the precision of the payment currency
+func (p *Payment) CanRefundWithAmount(amount float64) bool {
+	return hasAtMostXDecimals(amount, p.CurrencyPrecision)
+}
+
+// Validate checks the payment has a positive amount, a known method and the time it was paid
+func (p *Payment) Validate() error {
+	if p.AmountMinor <= 0 || p.PaidAt.IsZero() {
+		return ErrInvalidPayment
+	}
+	if !slices.Contains(paymentMethods, p.Method) {
+		return ErrInvalidPayment
+	}
+	return nil
+}
+
+// PaymentBalance is what a closed billing is owed net of its credit notes, what was paid for it and what is left
+type PaymentBalance struct {
+	DueAmountMinor         int64         `json:"due_amount_minor"`
+	PaidAmountMinor        int64         `json:"paid_amount_minor"`
+	OutstandingAmountMinor int64         `json:"outstanding_amount_minor"`
+	Status                 PaymentStatus `json:"status"`
+}
+
+// NewPaymentBalance returns the balance of a billing owing dueMinor and paid paidMinor, the outstanding amount is negative when overpaid
+func NewPaymentBalance(dueMinor int64, paidMinor int64) PaymentBalance {
+	balance := PaymentBalance{
+		DueAmountMinor:         dueMinor,
+		PaidAmountMinor:        paidMinor,
+		OutstandingAmountMinor: dueMinor - paidMinor,
+	}
+
+	switch {
+	case paidMinor > dueMinor:
+		balance.Status = PaymentStatusOverpaid
+	case paidMinor == dueMinor:
+		balance.Status = PaymentStatusPaid
+	case paidMinor > 0:
+		balance.Status = PaymentStatusPartiallyPaid
+	default:
+		balance.Status = PaymentStatusUnpaid
+	}
+
+	return balance
+}
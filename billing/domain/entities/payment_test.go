@@ -0,0 +1,114 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPayment_Validate(t *testing.T) {
+	paidAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		payment  Payment
+		expected error
+	}{
+		{
+			name:     "valid payment",
+			payment:  Payment{AmountMinor: 1000, Method: PaymentMethodCard, PaidAt: paidAt},
+			expected: nil,
+		},
+		{
+			name:     "zero amount",
+			payment:  Payment{AmountMinor: 0, Method: PaymentMethodCard, PaidAt: paidAt},
+			expected: ErrInvalidPayment,
+		},
+		{
+			name:     "negative amount",
+			payment:  Payment{AmountMinor: -100, Method: PaymentMethodCash, PaidAt: paidAt},
+			expected: ErrInvalidPayment,
+		},
+		{
+			name:     "unknown method",
+			payment:  Payment{AmountMinor: 1000, Method: "cheque", PaidAt: paidAt},
+			expected: ErrInvalidPayment,
+		},
+		{
+			name:     "missing paid at",
+			payment:  Payment{AmountMinor: 1000, Method: PaymentMethodBankTransfer},
+			expected: ErrInvalidPayment,
+		},
+		{
+			name:     "wallet payments are not recorded by hand",
+			payment:  Payment{AmountMinor: 1000, Method: PaymentMethodWallet, PaidAt: paidAt},
+			expected: ErrInvalidPayment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.payment.Validate()
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPayment_NetAmountMinor(t *testing.T) {
+	payment := Payment{AmountMinor: 1000, RefundedAmountMinor: 250}
+	if got := payment.NetAmountMinor(); got != 750 {
+		t.Errorf("NetAmountMinor() = %v, expected %v", got, 750)
+	}
+}
+
+func TestPayment_CanRefundWithAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   float64
+		expected bool
+	}{
+		{name: "whole amount", amount: 10, expected: true},
+		{name: "cents", amount: 10.25, expected: true},
+		{name: "too many decimals", amount: 10.255, expected: false},
+	}
+
+	payment := Payment{AmountMinor: 5000, CurrencyPrecision: 2}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := payment.CanRefundWithAmount(tt.amount); got != tt.expected {
+				t.Errorf("CanRefundWithAmount(%v) = %v, expected %v", tt.amount, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewPaymentBalance(t *testing.T) {
+	tests := []struct {
+		name                string
+		dueMinor            int64
+		paidMinor           int64
+		expectedStatus      PaymentStatus
+		expectedOutstanding int64
+	}{
+		{name: "nothing paid", dueMinor: 1000, paidMinor: 0, expectedStatus: PaymentStatusUnpaid, expectedOutstanding: 1000},
+		{name: "partially paid", dueMinor: 1000, paidMinor: 400, expectedStatus: PaymentStatusPartiallyPaid, expectedOutstanding: 600},
+		{name: "paid", dueMinor: 1000, paidMinor: 1000, expectedStatus: PaymentStatusPaid, expectedOutstanding: 0},
+		{name: "overpaid", dueMinor: 1000, paidMinor: 1200, expectedStatus: PaymentStatusOverpaid, expectedOutstanding: -200},
+		{name: "nothing due", dueMinor: 0, paidMinor: 0, expectedStatus: PaymentStatusPaid, expectedOutstanding: 0},
+		{name: "credited after payment", dueMinor: 500, paidMinor: 1000, expectedStatus: PaymentStatusOverpaid, expectedOutstanding: -500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balance := NewPaymentBalance(tt.dueMinor, tt.paidMinor)
+			if balance.Status != tt.expectedStatus {
+				t.Errorf("NewPaymentBalance() Status = %v, expected %v", balance.Status, tt.expectedStatus)
+			}
+			if balance.OutstandingAmountMinor != tt.expectedOutstanding {
+				t.Errorf("NewPaymentBalance() OutstandingAmountMinor = %v, expected %v", balance.OutstandingAmountMinor, tt.expectedOutstanding)
+			}
+		})
+	}
+}
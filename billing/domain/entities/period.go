@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"time"
+	_ "time/tzdata" // embed IANA timezone database so timezones resolve in minimal containers
+)
+
+type BillingPeriod = string
+
+const (
+	BillingPeriodCalendarMonth BillingPeriod = "calendar_month"
+	BillingPeriodCalendarWeek  BillingPeriod = "calendar_week"
+	BillingPeriodDay           BillingPeriod = "day"
+)
+
+// PeriodBounds returns the start and end of the calendar period containing at, computed in timezone.
+// Calendar weeks start on Monday. Bounds are returned in UTC.
+func PeriodBounds(period BillingPeriod, timezone string, at time.Time) (time.Time, time.Time, error) {
+	loc, err := LoadTimezone(timezone)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	local := at.In(loc)
+
+	var start, end time.Time
+	switch period {
+	case BillingPeriodCalendarMonth:
+		start = time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, 0)
+	case BillingPeriodCalendarWeek:
+		daysSinceMonday := (int(local.Weekday()) + 6) % 7
+		start = time.Date(local.Year(), local.Month(), local.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 0, 7)
+	case BillingPeriodDay:
+		start = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 0, 1)
+	default:
+		return time.Time{}, time.Time{}, ErrInvalidBillingPeriod
+	}
+
+	return start.UTC(), end.UTC(), nil
+}
+
+// LoadTimezone loads an IANA timezone, an empty name is UTC
+func LoadTimezone(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	tbilisi, _ := time.LoadLocation("Asia/Tbilisi")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name          string
+		period        BillingPeriod
+		timezone      string
+		at            time.Time
+		expectedStart time.Time
+		expectedEnd   time.Time
+		expectedErr   error
+	}{
+		{
+			name:          "calendar month in UTC",
+			period:        BillingPeriodCalendarMonth,
+			at:            time.Date(2026, 2, 14, 10, 0, 0, 0, time.UTC),
+			expectedStart: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "calendar month in Tbilisi when UTC is still in the previous month",
+			period:   BillingPeriodCalendarMonth,
+			timezone: "Asia/Tbilisi",
+			// 2026-03-31 22:00 UTC is already April 1st in Tbilisi (UTC+4)
+			at:            time.Date(2026, 3, 31, 22, 0, 0, 0, time.UTC),
+			expectedStart: time.Date(2026, 4, 1, 0, 0, 0, 0, tbilisi),
+			expectedEnd:   time.Date(2026, 5, 1, 0, 0, 0, 0, tbilisi),
+		},
+		{
+			name:          "calendar week starts on monday",
+			period:        BillingPeriodCalendarWeek,
+			at:            time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC), // sunday
+			expectedStart: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day spanning a DST change is 23 hours long",
+			period:   BillingPeriodDay,
+			timezone: "America/New_York",
+			at:       time.Date(2026, 3, 8, 12, 0, 0, 0, newYork),
+			// 2026-03-08 is the spring forward day in New York
+			expectedStart: time.Date(2026, 3, 8, 0, 0, 0, 0, newYork),
+			expectedEnd:   time.Date(2026, 3, 9, 0, 0, 0, 0, newYork),
+		},
+		{
+			name:        "unknown period",
+			period:      "fortnight",
+			at:          time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC),
+			expectedErr: ErrInvalidBillingPeriod,
+		},
+		{
+			name:        "unknown timezone",
+			period:      BillingPeriodDay,
+			timezone:    "Europe/Atlantis",
+			at:          time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC),
+			expectedErr: ErrInvalidTimezone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := PeriodBounds(tt.period, tt.timezone, tt.at)
+			if err != tt.expectedErr {
+				t.Fatalf("PeriodBounds() error = %v, expected %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+			if !start.Equal(tt.expectedStart) {
+				t.Errorf("PeriodBounds() start = %v, expected %v", start, tt.expectedStart)
+			}
+			if !end.Equal(tt.expectedEnd) {
+				t.Errorf("PeriodBounds() end = %v, expected %v", end, tt.expectedEnd)
+			}
+		})
+	}
+
+	// the DST day is one hour short
+	start, end, _ := PeriodBounds(BillingPeriodDay, "America/New_York", time.Date(2026, 3, 8, 12, 0, 0, 0, newYork))
+	if end.Sub(start) != 23*time.Hour {
+		t.Errorf("Expected 23h DST day, got %v", end.Sub(start))
+	}
+}
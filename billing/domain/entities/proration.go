@@ -0,0 +1,128 @@
+package entities
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+type ProrationMethod = string
+
+const (
+	// ProrationMethodDay prorates by calendar days in the billing timezone, the day of the change is billed at the new price
+	ProrationMethodDay ProrationMethod = "day"
+
+	// ProrationMethodSecond prorates by the exact time left in the period
+	ProrationMethodSecond ProrationMethod = "second"
+)
+
+// ProrationChange describes a plan or quantity change in the middle of a billing period.
+// Unit amounts are in minor units of the billing currency.
+type ProrationChange struct {
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	ChangeAt    time.Time
+	Timezone    string
+	Method      ProrationMethod
+
+	OldDescription     string
+	OldUnitAmountMinor int64
+	OldQuantity        int64
+
+	NewDescription     string
+	NewUnitAmountMinor int64
+	NewQuantity        int64
+}
+
+// Prorate returns the line items settling a change for the rest of the period:
+// a credit for the unused part of the old price and a charge for the remaining part of the new price.
+// Amounts are rounded half away from zero to the minor unit of the currency.
+func Prorate(change ProrationChange) ([]LineItem, error) {
+	if !change.PeriodEnd.After(change.PeriodStart) {
+		return nil, ErrInvalidProration
+	}
+	if change.ChangeAt.Before(change.PeriodStart) || !change.ChangeAt.Before(change.PeriodEnd) {
+		return nil, ErrChangeOutsidePeriod
+	}
+	if change.OldUnitAmountMinor < 0 || change.NewUnitAmountMinor < 0 || change.OldQuantity < 0 || change.NewQuantity < 0 {
+		return nil, ErrInvalidProration
+	}
+
+	loc, err := LoadTimezone(change.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, total, err := prorationFraction(change, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	changeDate := change.ChangeAt.In(loc).Format("2006-01-02")
+	lineItems := []LineItem{}
+
+	oldAmountMinor := prorateAmount(change.OldUnitAmountMinor, change.OldQuantity, remaining, total)
+	if oldAmountMinor != 0 {
+		lineItems = append(lineItems, LineItem{
+			Description: fmt.Sprintf("Unused time on %s × %d after %s", change.OldDescription, change.OldQuantity, changeDate),
+			AmountMinor: -oldAmountMinor,
+		})
+	}
+
+	newAmountMinor := prorateAmount(change.NewUnitAmountMinor, change.NewQuantity, remaining, total)
+	if newAmountMinor != 0 {
+		lineItems = append(lineItems, LineItem{
+			Description: fmt.Sprintf("Remaining time on %s × %d after %s", change.NewDescription, change.NewQuantity, changeDate),
+			AmountMinor: newAmountMinor,
+		})
+	}
+
+	return lineItems, nil
+}
+
+// prorationFraction returns the remaining and total length of the period in units of the proration method
+func prorationFraction(change ProrationChange, loc *time.Location) (int64, int64, error) {
+	switch change.Method {
+	case ProrationMethodSecond:
+		total := int64(change.PeriodEnd.Sub(change.PeriodStart) / time.Second)
+		remaining := int64(change.PeriodEnd.Sub(change.ChangeAt) / time.Second)
+		return remaining, total, nil
+	case ProrationMethodDay:
+		total := calendarDaysBetween(change.PeriodStart, change.PeriodEnd, loc)
+		remaining := calendarDaysBetween(change.ChangeAt, change.PeriodEnd, loc)
+		if total == 0 {
+			return 0, 0, ErrInvalidProration
+		}
+		return remaining, total, nil
+	}
+
+	return 0, 0, ErrInvalidProration
+}
+
+// calendarDaysBetween counts the calendar days from the day of from up to the day of to in loc, independent of DST
+func calendarDaysBetween(from time.Time, to time.Time, loc *time.Location) int64 {
+	from, to = from.In(loc), to.In(loc)
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	// a period ending during a day includes that day
+	days := int64(toDate.Sub(fromDate) / (24 * time.Hour))
+	if !to.Equal(time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)) {
+		days++
+	}
+	return days
+}
+
+// prorateAmount returns unitAmount * quantity * remaining / total rounded half away from zero
+func prorateAmount(unitAmountMinor int64, quantity int64, remaining int64, total int64) int64 {
+	numerator := new(big.Int).Mul(big.NewInt(unitAmountMinor), big.NewInt(quantity))
+	numerator.Mul(numerator, big.NewInt(remaining))
+	denominator := big.NewInt(total)
+
+	// round half up on non-negative values: (2n + d) / 2d
+	numerator.Mul(numerator, big.NewInt(2))
+	numerator.Add(numerator, denominator)
+	denominator.Mul(denominator, big.NewInt(2))
+
+	return numerator.Quo(numerator, denominator).Int64()
+}
@@ -0,0 +1,112 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProrate(t *testing.T) {
+	periodStart := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		change      ProrationChange
+		expected    []LineItem
+		expectedErr error
+	}{
+		{
+			name: "day based upgrade in the middle of the month",
+			change: ProrationChange{
+				PeriodStart: periodStart, PeriodEnd: periodEnd,
+				ChangeAt: time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC),
+				Method:   ProrationMethodDay,
+				// 17 of 31 days remain (15th to 31st)
+				OldDescription: "Basic", OldUnitAmountMinor: 999, OldQuantity: 1,
+				NewDescription: "Pro", NewUnitAmountMinor: 2999, NewQuantity: 1,
+			},
+			expected: []LineItem{
+				{Description: "Unused time on Basic × 1 after 2026-03-15", AmountMinor: -548},  // 999 * 17 / 31 = 547.84
+				{Description: "Remaining time on Pro × 1 after 2026-03-15", AmountMinor: 1645}, // 2999 * 17 / 31 = 1644.61
+			},
+		},
+		{
+			name: "second based seat increase",
+			change: ProrationChange{
+				PeriodStart: periodStart, PeriodEnd: periodEnd,
+				// 3/4 of the period remain
+				ChangeAt:       periodStart.Add(periodEnd.Sub(periodStart) / 4),
+				Method:         ProrationMethodSecond,
+				OldDescription: "Seat", OldUnitAmountMinor: 1000, OldQuantity: 3,
+				NewDescription: "Seat", NewUnitAmountMinor: 1000, NewQuantity: 5,
+			},
+			expected: []LineItem{
+				{Description: "Unused time on Seat × 3 after 2026-03-08", AmountMinor: -2250},
+				{Description: "Remaining time on Seat × 5 after 2026-03-08", AmountMinor: 3750},
+			},
+		},
+		{
+			name: "day based change in the billing timezone",
+			change: ProrationChange{
+				PeriodStart: periodStart, PeriodEnd: periodEnd,
+				// 2026-03-30 22:00 UTC is already the 31st in Tbilisi, so only one day remains
+				ChangeAt:       time.Date(2026, 3, 30, 22, 0, 0, 0, time.UTC),
+				Timezone:       "Asia/Tbilisi",
+				Method:         ProrationMethodDay,
+				NewDescription: "Add-on", NewUnitAmountMinor: 3100, NewQuantity: 1,
+			},
+			expected: []LineItem{
+				// the UTC period spans 32 calendar days in Tbilisi (March 1st 04:00 to April 1st 04:00)
+				{Description: "Remaining time on Add-on × 1 after 2026-03-31", AmountMinor: 194}, // 3100 * 2 / 32 = 193.75
+			},
+		},
+		{
+			name: "removing all seats only credits",
+			change: ProrationChange{
+				PeriodStart: periodStart, PeriodEnd: periodEnd,
+				ChangeAt:       periodStart,
+				Method:         ProrationMethodSecond,
+				OldDescription: "Seat", OldUnitAmountMinor: 1000, OldQuantity: 2,
+				NewDescription: "Seat", NewUnitAmountMinor: 1000, NewQuantity: 0,
+			},
+			expected: []LineItem{
+				{Description: "Unused time on Seat × 2 after 2026-03-01", AmountMinor: -2000},
+			},
+		},
+		{
+			name: "change after the end of the period",
+			change: ProrationChange{
+				PeriodStart: periodStart, PeriodEnd: periodEnd,
+				ChangeAt: periodEnd,
+				Method:   ProrationMethodDay,
+			},
+			expectedErr: ErrChangeOutsidePeriod,
+		},
+		{
+			name: "unknown method",
+			change: ProrationChange{
+				PeriodStart: periodStart, PeriodEnd: periodEnd,
+				ChangeAt: periodStart,
+				Method:   "hour",
+			},
+			expectedErr: ErrInvalidProration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lineItems, err := Prorate(tt.change)
+			if err != tt.expectedErr {
+				t.Fatalf("Prorate() error = %v, expected %v", err, tt.expectedErr)
+			}
+			if len(lineItems) != len(tt.expected) {
+				t.Fatalf("Prorate() = %+v, expected %+v", lineItems, tt.expected)
+			}
+			for i := range lineItems {
+				if lineItems[i] != tt.expected[i] {
+					t.Errorf("Prorate()[%d] = %+v, expected %+v", i, lineItems[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
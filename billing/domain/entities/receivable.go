@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+)
+
+// Receivable is a closed billing with something left to pay, what is due is its grand total net of credit notes and what
+// was paid is net of refunds
+type Receivable struct {
+	BillingID         int64  `json:"-"`
+	ExternalBillingID string `json:"billing_id"`
+	UserID            string `json:"user_id"`
+	InvoiceNumber     string `json:"invoice_number,omitempty"`
+
+	Currency          string `json:"currency"`
+	CurrencyPrecision int64  `json:"currency_precision"`
+
+	DueAmountMinor         int64 `json:"due_amount_minor"`
+	PaidAmountMinor        int64 `json:"paid_amount_minor"`
+	OutstandingAmountMinor int64 `json:"outstanding_amount_minor"`
+
+	ClosedAt time.Time `json:"closed_at"`
+}
@@ -0,0 +1,129 @@
+package entities
+
+import (
+	"time"
+)
+
+type RecurrenceFrequency = string
+
+const (
+	RecurrenceFrequencyMonthly RecurrenceFrequency = "monthly"
+	RecurrenceFrequencyWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceFrequencyDaily   RecurrenceFrequency = "daily"
+)
+
+// RecurrenceRule describes how a billing rolls over into the next period.
+// Period boundaries are computed at midnight (monthly) or by calendar days (weekly, daily) in Timezone,
+// so they stay stable across DST changes and month lengths.
+type RecurrenceRule struct {
+	Frequency RecurrenceFrequency `json:"frequency"`
+
+	// Interval is the number of frequency units between two period boundaries, defaults to 1
+	Interval int64 `json:"interval"`
+
+	// DayOfMonth is the boundary day for monthly rules (1-31), clamped to the length of shorter months
+	DayOfMonth int64 `json:"day_of_month,omitempty"`
+
+	// EndsAt stops the chain: no period starting at or after EndsAt is created
+	EndsAt *time.Time `json:"ends_at,omitempty"`
+
+	// Timezone is an IANA timezone name, defaults to UTC
+	Timezone string `json:"timezone,omitempty"`
+}
+
+func (r *RecurrenceRule) Validate() error {
+	switch r.Frequency {
+	case RecurrenceFrequencyMonthly:
+		if r.DayOfMonth < 1 || r.DayOfMonth > 31 {
+			return ErrInvalidRecurrenceRule
+		}
+	case RecurrenceFrequencyWeekly, RecurrenceFrequencyDaily:
+		if r.DayOfMonth != 0 {
+			return ErrInvalidRecurrenceRule
+		}
+	default:
+		return ErrInvalidRecurrenceRule
+	}
+
+	if r.Interval < 0 {
+		return ErrInvalidRecurrenceRule
+	}
+
+	if _, err := r.location(); err != nil {
+		return ErrInvalidRecurrenceRule
+	}
+
+	return nil
+}
+
+// FirstClose returns the end of the first period for a billing created at start
+func (r *RecurrenceRule) FirstClose(start time.Time) (time.Time, error) {
+	loc, err := r.location()
+	if err != nil {
+		return time.Time{}, ErrInvalidRecurrenceRule
+	}
+	local := start.In(loc)
+
+	switch r.Frequency {
+	case RecurrenceFrequencyMonthly:
+		// next occurrence of day N at midnight strictly after start
+		candidate := monthlyBoundary(local.Year(), local.Month(), r.DayOfMonth, loc)
+		if !candidate.After(local) {
+			candidate = monthlyBoundary(local.Year(), local.Month()+1, r.DayOfMonth, loc)
+		}
+		return candidate.UTC(), nil
+	case RecurrenceFrequencyWeekly:
+		return local.AddDate(0, 0, 7*int(r.interval())).UTC(), nil
+	case RecurrenceFrequencyDaily:
+		return local.AddDate(0, 0, int(r.interval())).UTC(), nil
+	}
+
+	return time.Time{}, ErrInvalidRecurrenceRule
+}
+
+// NextClose returns the end of the period following the one that closed at previousClose
+func (r *RecurrenceRule) NextClose(previousClose time.Time) (time.Time, error) {
+	loc, err := r.location()
+	if err != nil {
+		return time.Time{}, ErrInvalidRecurrenceRule
+	}
+	local := previousClose.In(loc)
+
+	switch r.Frequency {
+	case RecurrenceFrequencyMonthly:
+		return monthlyBoundary(local.Year(), local.Month()+time.Month(r.interval()), r.DayOfMonth, loc).UTC(), nil
+	case RecurrenceFrequencyWeekly:
+		return local.AddDate(0, 0, 7*int(r.interval())).UTC(), nil
+	case RecurrenceFrequencyDaily:
+		return local.AddDate(0, 0, int(r.interval())).UTC(), nil
+	}
+
+	return time.Time{}, ErrInvalidRecurrenceRule
+}
+
+// HasNextPeriod reports whether a period starting at periodStart is still within the rule
+func (r *RecurrenceRule) HasNextPeriod(periodStart time.Time) bool {
+	return r.EndsAt == nil || periodStart.Before(*r.EndsAt)
+}
+
+func (r *RecurrenceRule) interval() int64 {
+	if r.Interval <= 0 {
+		return 1
+	}
+	return r.Interval
+}
+
+func (r *RecurrenceRule) location() (*time.Location, error) {
+	return LoadTimezone(r.Timezone)
+}
+
+// monthlyBoundary returns midnight of the given day in the given month, clamping the day to the month length.
+// month may overflow (e.g. 13), in which case it is normalised into the following year.
+func monthlyBoundary(year int, month time.Month, day int64, loc *time.Location) time.Time {
+	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	if int(day) < lastDay {
+		lastDay = int(day)
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), lastDay, 0, 0, 0, 0, loc)
+}
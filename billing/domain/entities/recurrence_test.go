@@ -0,0 +1,168 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecurrenceRule_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     RecurrenceRule
+		expected error
+	}{
+		{
+			name:     "monthly with day of month",
+			rule:     RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, DayOfMonth: 31},
+			expected: nil,
+		},
+		{
+			name:     "monthly without day of month",
+			rule:     RecurrenceRule{Frequency: RecurrenceFrequencyMonthly},
+			expected: ErrInvalidRecurrenceRule,
+		},
+		{
+			name:     "weekly with day of month",
+			rule:     RecurrenceRule{Frequency: RecurrenceFrequencyWeekly, DayOfMonth: 3},
+			expected: ErrInvalidRecurrenceRule,
+		},
+		{
+			name:     "every 10 days",
+			rule:     RecurrenceRule{Frequency: RecurrenceFrequencyDaily, Interval: 10},
+			expected: nil,
+		},
+		{
+			name:     "unknown frequency",
+			rule:     RecurrenceRule{Frequency: "yearly"},
+			expected: ErrInvalidRecurrenceRule,
+		},
+		{
+			name:     "unknown timezone",
+			rule:     RecurrenceRule{Frequency: RecurrenceFrequencyDaily, Timezone: "Mars/Olympus"},
+			expected: ErrInvalidRecurrenceRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if err != tt.expected {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRule_FirstClose(t *testing.T) {
+	tbilisi, _ := time.LoadLocation("Asia/Tbilisi")
+
+	tests := []struct {
+		name     string
+		rule     RecurrenceRule
+		start    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "monthly on day 15 before the boundary",
+			rule:     RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, DayOfMonth: 15},
+			start:    time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "monthly on day 15 after the boundary",
+			rule:     RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, DayOfMonth: 15},
+			start:    time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "monthly on day 31 is clamped in february",
+			rule:     RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, DayOfMonth: 31},
+			start:    time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "monthly in timezone",
+			rule:     RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, DayOfMonth: 1, Timezone: "Asia/Tbilisi"},
+			start:    time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 4, 1, 0, 0, 0, 0, tbilisi).UTC(),
+		},
+		{
+			name:     "every 2 weeks",
+			rule:     RecurrenceRule{Frequency: RecurrenceFrequencyWeekly, Interval: 2},
+			start:    time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 3, 24, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.rule.FirstClose(tt.start)
+			if err != nil {
+				t.Fatalf("FirstClose() failed: %v", err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("FirstClose() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRule_NextClose(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	tests := []struct {
+		name          string
+		rule          RecurrenceRule
+		previousClose time.Time
+		expected      time.Time
+	}{
+		{
+			name:          "monthly on day 31 after january",
+			rule:          RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, DayOfMonth: 31},
+			previousClose: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+			expected:      time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "monthly on day 31 recovers after a short month",
+			rule:          RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, DayOfMonth: 31},
+			previousClose: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+			expected:      time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "quarterly across the year end",
+			rule:          RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, DayOfMonth: 1, Interval: 3},
+			previousClose: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+			expected:      time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "daily keeps wall clock across DST",
+			rule:          RecurrenceRule{Frequency: RecurrenceFrequencyDaily, Timezone: "Europe/Berlin"},
+			previousClose: time.Date(2026, 3, 28, 0, 0, 0, 0, berlin).UTC(),
+			expected:      time.Date(2026, 3, 29, 0, 0, 0, 0, berlin).UTC(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.rule.NextClose(tt.previousClose)
+			if err != nil {
+				t.Fatalf("NextClose() failed: %v", err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("NextClose() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRule_HasNextPeriod(t *testing.T) {
+	endsAt := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	rule := RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, DayOfMonth: 1, EndsAt: &endsAt}
+
+	if !rule.HasNextPeriod(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expected a period starting before EndsAt")
+	}
+	if rule.HasNextPeriod(endsAt) {
+		t.Error("Expected no period starting at EndsAt")
+	}
+}
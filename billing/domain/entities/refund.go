@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"time"
+)
+
+type RefundStatus = string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusSucceeded RefundStatus = "succeeded"
+	RefundStatusFailed    RefundStatus = "failed"
+)
+
+// RefundIdempotencyKeyMaxLength is the longest idempotency key a refund can be requested with
+const RefundIdempotencyKeyMaxLength = 255
+
+// Refund returns part or all of a payment. Pending and succeeded refunds reserve their amount on the payment, so the refunds
+// of a payment never exceed what was paid. A refund may settle a credit note of the billing.
+type Refund struct {
+	ID               int64  `json:"id"`
+	ExternalRefundID string `json:"refund_id"`
+
+	PaymentID         int64  `json:"-"`
+	ExternalPaymentID string `json:"payment_id"`
+
+	BillingID         int64  `json:"-"`
+	ExternalBillingID string `json:"billing_id"`
+
+	CreditNoteID         *int64  `json:"-"`
+	ExternalCreditNoteID *string `json:"credit_note_id,omitempty"`
+
+	AmountMinor       int64        `json:"amount_minor"`
+	Currency          string       `json:"currency"`
+	CurrencyPrecision int64        `json:"currency_precision"`
+	Reason            string       `json:"reason"`
+	IdempotencyKey    string       `json:"idempotency_key"`
+	Status            RefundStatus `json:"status"`
+	Error             string       `json:"error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the refund has a positive amount, a reason and an idempotency key
+func (r *Refund) Validate() error {
+	if r.AmountMinor <= 0 || r.Reason == "" {
+		return ErrInvalidRefund
+	}
+	if r.IdempotencyKey == "" || len(r.IdempotencyKey) > RefundIdempotencyKeyMaxLength {
+		return ErrInvalidRefund
+	}
+	return nil
+}
+
+// IsCompleted reports whether the gateway answered the refund
+func (r *Refund) IsCompleted() bool {
+	return r.Status == RefundStatusSucceeded || r.Status == RefundStatusFailed
+}
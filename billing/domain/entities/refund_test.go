@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRefund_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		refund   Refund
+		expected error
+	}{
+		{
+			name:     "valid refund",
+			refund:   Refund{AmountMinor: 500, Reason: "damaged", IdempotencyKey: "refund-1"},
+			expected: nil,
+		},
+		{
+			name:     "zero amount",
+			refund:   Refund{AmountMinor: 0, Reason: "damaged", IdempotencyKey: "refund-1"},
+			expected: ErrInvalidRefund,
+		},
+		{
+			name:     "missing reason",
+			refund:   Refund{AmountMinor: 500, IdempotencyKey: "refund-1"},
+			expected: ErrInvalidRefund,
+		},
+		{
+			name:     "missing idempotency key",
+			refund:   Refund{AmountMinor: 500, Reason: "damaged"},
+			expected: ErrInvalidRefund,
+		},
+		{
+			name:     "idempotency key too long",
+			refund:   Refund{AmountMinor: 500, Reason: "damaged", IdempotencyKey: strings.Repeat("k", RefundIdempotencyKeyMaxLength+1)},
+			expected: ErrInvalidRefund,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.refund.Validate()
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRefund_IsCompleted(t *testing.T) {
+	tests := []struct {
+		status   RefundStatus
+		expected bool
+	}{
+		{status: RefundStatusPending, expected: false},
+		{status: RefundStatusSucceeded, expected: true},
+		{status: RefundStatusFailed, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			refund := Refund{Status: tt.status}
+			if got := refund.IsCompleted(); got != tt.expected {
+				t.Errorf("IsCompleted() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
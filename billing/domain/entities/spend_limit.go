@@ -0,0 +1,69 @@
+package entities
+
+type SpendLimitMode = string
+
+const (
+	// SpendLimitModeAlert only alerts when the total crosses a threshold
+	SpendLimitModeAlert SpendLimitMode = "alert"
+	// SpendLimitModeReject rejects line items that would take the total over the limit
+	SpendLimitModeReject SpendLimitMode = "reject"
+	// SpendLimitModeClose closes the billing when a line item would take the total over the limit, the line item is not added
+	SpendLimitModeClose SpendLimitMode = "close"
+)
+
+// DefaultSpendLimitThresholds are the percentages of the limit alerted when a spend limit is set without thresholds
+var DefaultSpendLimitThresholds = []int64{50, 80, 100}
+
+// SpendLimit caps what a billing can total before discounts and tax. Each threshold, a percentage of the limit,
+// is alerted once when the total reaches it.
+type SpendLimit struct {
+	AmountMinor int64          `json:"amount_minor"`
+	Thresholds  []int64        `json:"thresholds"`
+	Mode        SpendLimitMode `json:"mode"`
+}
+
+// Validate checks the limit is positive and its thresholds are increasing percentages between 1 and 100
+func (l *SpendLimit) Validate() error {
+	if l.AmountMinor <= 0 {
+		return ErrInvalidSpendLimit
+	}
+
+	switch l.Mode {
+	case SpendLimitModeAlert, SpendLimitModeReject, SpendLimitModeClose:
+	default:
+		return ErrInvalidSpendLimit
+	}
+
+	var previous int64
+	for _, threshold := range l.Thresholds {
+		if threshold <= previous || threshold > 100 {
+			return ErrInvalidSpendLimit
+		}
+		previous = threshold
+	}
+
+	return nil
+}
+
+// IsHard reports whether the limit stops the total from going over it
+func (l *SpendLimit) IsHard() bool {
+	return l.Mode == SpendLimitModeReject || l.Mode == SpendLimitModeClose
+}
+
+// Exceeds reports whether a billing totalling totalAmountMinor is over the limit, reaching it exactly is not
+func (l *SpendLimit) Exceeds(totalAmountMinor int64) bool {
+	return totalAmountMinor > l.AmountMinor
+}
+
+// CrossedThresholds returns the thresholds the total reached when it went from previousTotalAmountMinor to totalAmountMinor
+func (l *SpendLimit) CrossedThresholds(previousTotalAmountMinor int64, totalAmountMinor int64) []int64 {
+	var crossed []int64
+	for _, threshold := range l.Thresholds {
+		// compared in hundredths so that thresholds of limits not divisible by 100 are not rounded
+		thresholdAmount := l.AmountMinor * threshold
+		if previousTotalAmountMinor*100 < thresholdAmount && totalAmountMinor*100 >= thresholdAmount {
+			crossed = append(crossed, threshold)
+		}
+	}
+	return crossed
+}
@@ -0,0 +1,134 @@
+package entities
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSpendLimit_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    SpendLimit
+		expected error
+	}{
+		{
+			name:     "alert at the default thresholds",
+			limit:    SpendLimit{AmountMinor: 10000, Thresholds: DefaultSpendLimitThresholds, Mode: SpendLimitModeAlert},
+			expected: nil,
+		},
+		{
+			name:     "hard limit without thresholds",
+			limit:    SpendLimit{AmountMinor: 10000, Mode: SpendLimitModeReject},
+			expected: nil,
+		},
+		{
+			name:     "zero amount",
+			limit:    SpendLimit{AmountMinor: 0, Mode: SpendLimitModeAlert},
+			expected: ErrInvalidSpendLimit,
+		},
+		{
+			name:     "unknown mode",
+			limit:    SpendLimit{AmountMinor: 10000, Mode: "block"},
+			expected: ErrInvalidSpendLimit,
+		},
+		{
+			name:     "thresholds not increasing",
+			limit:    SpendLimit{AmountMinor: 10000, Thresholds: []int64{80, 50}, Mode: SpendLimitModeAlert},
+			expected: ErrInvalidSpendLimit,
+		},
+		{
+			name:     "threshold above 100",
+			limit:    SpendLimit{AmountMinor: 10000, Thresholds: []int64{50, 120}, Mode: SpendLimitModeClose},
+			expected: ErrInvalidSpendLimit,
+		},
+		{
+			name:     "zero threshold",
+			limit:    SpendLimit{AmountMinor: 10000, Thresholds: []int64{0, 50}, Mode: SpendLimitModeAlert},
+			expected: ErrInvalidSpendLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limit.Validate()
+			if err != tt.expected {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSpendLimit_CrossedThresholds(t *testing.T) {
+	limit := SpendLimit{AmountMinor: 10000, Thresholds: DefaultSpendLimitThresholds, Mode: SpendLimitModeAlert}
+
+	tests := []struct {
+		name          string
+		limit         SpendLimit
+		previousTotal int64
+		total         int64
+		expected      []int64
+	}{
+		{
+			name:          "below the first threshold",
+			limit:         limit,
+			previousTotal: 0,
+			total:         4999,
+			expected:      nil,
+		},
+		{
+			name:          "reaching a threshold exactly crosses it",
+			limit:         limit,
+			previousTotal: 4999,
+			total:         5000,
+			expected:      []int64{50},
+		},
+		{
+			name:          "a threshold already reached is not crossed again",
+			limit:         limit,
+			previousTotal: 5000,
+			total:         7000,
+			expected:      nil,
+		},
+		{
+			name:          "one line item crosses several thresholds",
+			limit:         limit,
+			previousTotal: 4000,
+			total:         12000,
+			expected:      []int64{50, 80, 100},
+		},
+		{
+			name:          "a total going down crosses nothing",
+			limit:         limit,
+			previousTotal: 9000,
+			total:         4000,
+			expected:      nil,
+		},
+		{
+			name:          "thresholds of a limit not divisible by 100 are not rounded",
+			limit:         SpendLimit{AmountMinor: 999, Thresholds: []int64{50}, Mode: SpendLimitModeAlert},
+			previousTotal: 0,
+			total:         499,
+			expected:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crossed := tt.limit.CrossedThresholds(tt.previousTotal, tt.total)
+			if !slices.Equal(crossed, tt.expected) {
+				t.Errorf("CrossedThresholds() = %v, expected %v", crossed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSpendLimit_Exceeds(t *testing.T) {
+	limit := SpendLimit{AmountMinor: 10000, Mode: SpendLimitModeReject}
+
+	if limit.Exceeds(10000) {
+		t.Errorf("Exceeds(10000) = true, expected reaching the limit to be allowed")
+	}
+	if !limit.Exceeds(10001) {
+		t.Errorf("Exceeds(10001) = false, expected going over the limit to exceed it")
+	}
+}
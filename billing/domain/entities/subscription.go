@@ -0,0 +1,171 @@
+package entities
+
+import (
+	"time"
+)
+
+type PlanInterval = string
+
+const (
+	PlanIntervalMonth PlanInterval = "month"
+	PlanIntervalWeek  PlanInterval = "week"
+	PlanIntervalDay   PlanInterval = "day"
+)
+
+type SubscriptionStatus = string
+
+const (
+	SubscriptionStatusTrial     SubscriptionStatus = "trial"
+	SubscriptionStatusActive    SubscriptionStatus = "active"
+	SubscriptionStatusPastDue   SubscriptionStatus = "past_due"
+	SubscriptionStatusCancelled SubscriptionStatus = "cancelled"
+)
+
+type Plan struct {
+	ID                int64        `json:"id"`
+	ExternalPlanID    string       `json:"external_plan_id"`
+	Name              string       `json:"name"`
+	Currency          string       `json:"currency"`
+	CurrencyPrecision int64        `json:"currency_precision"`
+	AmountMinor       int64        `json:"amount_minor"`
+	Interval          PlanInterval `json:"interval"`
+	IntervalCount     int64        `json:"interval_count"`
+	TrialDays         int64        `json:"trial_days"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
+
+func (p *Plan) Validate() error {
+	switch p.Interval {
+	case PlanIntervalMonth, PlanIntervalWeek, PlanIntervalDay:
+	default:
+		return ErrInvalidPlan
+	}
+
+	if p.Name == "" || p.AmountMinor < 0 || p.IntervalCount < 0 || p.TrialDays < 0 {
+		return ErrInvalidPlan
+	}
+
+	return nil
+}
+
+// RecurrenceRule returns the recurrence of billings generated by the plan, with period boundaries aligned on anchor
+func (p *Plan) RecurrenceRule(anchor time.Time, timezone string) (*RecurrenceRule, error) {
+	loc, err := LoadTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &RecurrenceRule{
+		Interval: p.IntervalCount,
+		Timezone: timezone,
+	}
+	switch p.Interval {
+	case PlanIntervalMonth:
+		rule.Frequency = RecurrenceFrequencyMonthly
+		rule.DayOfMonth = int64(anchor.In(loc).Day())
+	case PlanIntervalWeek:
+		rule.Frequency = RecurrenceFrequencyWeekly
+	case PlanIntervalDay:
+		rule.Frequency = RecurrenceFrequencyDaily
+	default:
+		return nil, ErrInvalidPlan
+	}
+
+	return rule, nil
+}
+
+// Charge returns the line item charged for one period of the plan
+func (p *Plan) Charge() LineItem {
+	return LineItem{
+		Description: p.Name,
+		AmountMinor: p.AmountMinor,
+	}
+}
+
+// TrialCharge returns the zero amount line item of a trial period
+func (p *Plan) TrialCharge() LineItem {
+	return LineItem{
+		Description: p.Name + " (trial)",
+		AmountMinor: 0,
+	}
+}
+
+type Subscription struct {
+	ID                     int64              `json:"id"`
+	ExternalSubscriptionID string             `json:"external_subscription_id"`
+	UserID                 string             `json:"user_id"`
+	PlanID                 int64              `json:"plan_id"`
+	ExternalPlanID         string             `json:"external_plan_id"`
+	Status                 SubscriptionStatus `json:"status"`
+	Timezone               string             `json:"timezone"`
+	AnchorAt               time.Time          `json:"anchor_at"`
+	TrialEndsAt            *time.Time         `json:"trial_ends_at"`
+	CurrentBillingID       *string            `json:"current_billing_id"`
+	CancelledAt            *time.Time         `json:"cancelled_at"`
+	CreatedAt              time.Time          `json:"created_at"`
+	UpdatedAt              time.Time          `json:"updated_at"`
+}
+
+func (s *Subscription) CanChangePlan() bool {
+	return s.Status != SubscriptionStatusCancelled
+}
+
+func (s *Subscription) CanCancel() bool {
+	return s.Status != SubscriptionStatusCancelled
+}
+
+// CanTransitionTo reports whether the subscription may move from its current status to status
+func (s *Subscription) CanTransitionTo(status SubscriptionStatus) bool {
+	switch s.Status {
+	case SubscriptionStatusTrial:
+		return status == SubscriptionStatusActive || status == SubscriptionStatusCancelled
+	case SubscriptionStatusActive:
+		return status == SubscriptionStatusPastDue || status == SubscriptionStatusCancelled
+	case SubscriptionStatusPastDue:
+		return status == SubscriptionStatusActive || status == SubscriptionStatusCancelled
+	}
+	return false
+}
+
+// StatusAfterPayment returns the status of the subscription once one of its billings went unpaid or was paid.
+// Active subscriptions with an unpaid billing are past due until it is paid, trials and cancelled subscriptions keep their status.
+func (s *Subscription) StatusAfterPayment(paid bool) SubscriptionStatus {
+	if !paid && s.CanTransitionTo(SubscriptionStatusPastDue) {
+		return SubscriptionStatusPastDue
+	}
+	if paid && s.Status == SubscriptionStatusPastDue {
+		return SubscriptionStatusActive
+	}
+	return s.Status
+}
+
+// StatusAfterDunning returns the status of the subscription once the dunning of one of its billings reached status.
+// The subscription is past due while the billing is chased, and cancelled once the billing is uncollectible, so that it
+// is not renewed anymore.
+func (s *Subscription) StatusAfterDunning(status DunningStatus) SubscriptionStatus {
+	switch status {
+	case DunningStatusResolved:
+		return s.StatusAfterPayment(true)
+	case DunningStatusUncollectible:
+		if s.CanTransitionTo(SubscriptionStatusCancelled) {
+			return SubscriptionStatusCancelled
+		}
+		return s.Status
+	}
+	return s.StatusAfterPayment(false)
+}
+
+// Renew prepares the subscription for a period starting at periodStart.
+// It returns false when the subscription is cancelled and no billing must be generated.
+func (s *Subscription) Renew(periodStart time.Time) bool {
+	if s.Status == SubscriptionStatusCancelled {
+		return false
+	}
+
+	if s.Status == SubscriptionStatusTrial && (s.TrialEndsAt == nil || !periodStart.Before(*s.TrialEndsAt)) {
+		s.Status = SubscriptionStatusActive
+	}
+
+	return true
+}
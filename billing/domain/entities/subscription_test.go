@@ -0,0 +1,187 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlan_RecurrenceRule(t *testing.T) {
+	anchor := time.Date(2026, 1, 31, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		plan     Plan
+		timezone string
+		expected RecurrenceRule
+	}{
+		{
+			name:     "monthly plan is anchored on the day of the anchor",
+			plan:     Plan{Interval: PlanIntervalMonth, IntervalCount: 1},
+			expected: RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, Interval: 1, DayOfMonth: 31},
+		},
+		{
+			name:     "monthly plan anchor day is computed in the subscription timezone",
+			plan:     Plan{Interval: PlanIntervalMonth, IntervalCount: 1},
+			timezone: "Pacific/Auckland",
+			// 2026-01-31 15:00 UTC is already February 1st in Auckland
+			expected: RecurrenceRule{Frequency: RecurrenceFrequencyMonthly, Interval: 1, DayOfMonth: 1, Timezone: "Pacific/Auckland"},
+		},
+		{
+			name:     "biweekly plan",
+			plan:     Plan{Interval: PlanIntervalWeek, IntervalCount: 2},
+			expected: RecurrenceRule{Frequency: RecurrenceFrequencyWeekly, Interval: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := tt.plan.RecurrenceRule(anchor, tt.timezone)
+			if err != nil {
+				t.Fatalf("RecurrenceRule() failed: %v", err)
+			}
+			if *rule != tt.expected {
+				t.Errorf("RecurrenceRule() = %+v, expected %+v", *rule, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlan_Validate(t *testing.T) {
+	valid := Plan{Name: "Pro", AmountMinor: 2999, Interval: PlanIntervalMonth, IntervalCount: 1}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v, expected nil", err)
+	}
+
+	invalid := Plan{Name: "Pro", AmountMinor: 2999, Interval: "year"}
+	if err := invalid.Validate(); err != ErrInvalidPlan {
+		t.Errorf("Validate() = %v, expected %v", err, ErrInvalidPlan)
+	}
+}
+
+func TestSubscription_Renew(t *testing.T) {
+	trialEndsAt := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		subscription   Subscription
+		periodStart    time.Time
+		expectedRenew  bool
+		expectedStatus SubscriptionStatus
+	}{
+		{
+			name:           "trial becomes active when the trial is over",
+			subscription:   Subscription{Status: SubscriptionStatusTrial, TrialEndsAt: &trialEndsAt},
+			periodStart:    trialEndsAt,
+			expectedRenew:  true,
+			expectedStatus: SubscriptionStatusActive,
+		},
+		{
+			name:           "trial stays in trial before the trial is over",
+			subscription:   Subscription{Status: SubscriptionStatusTrial, TrialEndsAt: &trialEndsAt},
+			periodStart:    trialEndsAt.Add(-24 * time.Hour),
+			expectedRenew:  true,
+			expectedStatus: SubscriptionStatusTrial,
+		},
+		{
+			name:           "past due subscription keeps being billed",
+			subscription:   Subscription{Status: SubscriptionStatusPastDue},
+			periodStart:    trialEndsAt,
+			expectedRenew:  true,
+			expectedStatus: SubscriptionStatusPastDue,
+		},
+		{
+			name:           "cancelled subscription is not renewed",
+			subscription:   Subscription{Status: SubscriptionStatusCancelled},
+			periodStart:    trialEndsAt,
+			expectedRenew:  false,
+			expectedStatus: SubscriptionStatusCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renew := tt.subscription.Renew(tt.periodStart)
+			if renew != tt.expectedRenew {
+				t.Errorf("Renew() = %v, expected %v", renew, tt.expectedRenew)
+			}
+			if tt.subscription.Status != tt.expectedStatus {
+				t.Errorf("Status = %s, expected %s", tt.subscription.Status, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestSubscription_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from     SubscriptionStatus
+		to       SubscriptionStatus
+		expected bool
+	}{
+		{from: SubscriptionStatusTrial, to: SubscriptionStatusActive, expected: true},
+		{from: SubscriptionStatusTrial, to: SubscriptionStatusPastDue, expected: false},
+		{from: SubscriptionStatusActive, to: SubscriptionStatusPastDue, expected: true},
+		{from: SubscriptionStatusPastDue, to: SubscriptionStatusActive, expected: true},
+		{from: SubscriptionStatusActive, to: SubscriptionStatusCancelled, expected: true},
+		{from: SubscriptionStatusCancelled, to: SubscriptionStatusActive, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			subscription := Subscription{Status: tt.from}
+			if result := subscription.CanTransitionTo(tt.to); result != tt.expected {
+				t.Errorf("CanTransitionTo(%s) = %v, expected %v", tt.to, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSubscription_StatusAfterPayment(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   SubscriptionStatus
+		paid     bool
+		expected SubscriptionStatus
+	}{
+		{name: "active subscription with an unpaid billing is past due", status: SubscriptionStatusActive, paid: false, expected: SubscriptionStatusPastDue},
+		{name: "past due subscription is active once paid", status: SubscriptionStatusPastDue, paid: true, expected: SubscriptionStatusActive},
+		{name: "past due subscription stays past due while unpaid", status: SubscriptionStatusPastDue, paid: false, expected: SubscriptionStatusPastDue},
+		{name: "active subscription stays active when paid", status: SubscriptionStatusActive, paid: true, expected: SubscriptionStatusActive},
+		{name: "trial is never past due", status: SubscriptionStatusTrial, paid: false, expected: SubscriptionStatusTrial},
+		{name: "cancelled subscription stays cancelled", status: SubscriptionStatusCancelled, paid: true, expected: SubscriptionStatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := Subscription{Status: tt.status}
+			if result := subscription.StatusAfterPayment(tt.paid); result != tt.expected {
+				t.Errorf("StatusAfterPayment(%v) = %s, expected %s", tt.paid, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSubscription_StatusAfterDunning(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   SubscriptionStatus
+		dunning  DunningStatus
+		expected SubscriptionStatus
+	}{
+		{name: "active subscription with a reminded billing is past due", status: SubscriptionStatusActive, dunning: DunningStatusPastDue, expected: SubscriptionStatusPastDue},
+		{name: "past due subscription stays past due while chased", status: SubscriptionStatusPastDue, dunning: DunningStatusPastDue, expected: SubscriptionStatusPastDue},
+		{name: "past due subscription is cancelled when uncollectible", status: SubscriptionStatusPastDue, dunning: DunningStatusUncollectible, expected: SubscriptionStatusCancelled},
+		{name: "trial is cancelled when uncollectible", status: SubscriptionStatusTrial, dunning: DunningStatusUncollectible, expected: SubscriptionStatusCancelled},
+		{name: "past due subscription is active once resolved", status: SubscriptionStatusPastDue, dunning: DunningStatusResolved, expected: SubscriptionStatusActive},
+		{name: "trial is never past due", status: SubscriptionStatusTrial, dunning: DunningStatusPastDue, expected: SubscriptionStatusTrial},
+		{name: "cancelled subscription stays cancelled", status: SubscriptionStatusCancelled, dunning: DunningStatusUncollectible, expected: SubscriptionStatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := Subscription{Status: tt.status}
+			if result := subscription.StatusAfterDunning(tt.dunning); result != tt.expected {
+				t.Errorf("StatusAfterDunning(%s) = %s, expected %s", tt.dunning, result, tt.expected)
+			}
+		})
+	}
+}
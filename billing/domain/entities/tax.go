@@ -0,0 +1,130 @@
+package entities
+
+import (
+	"math/big"
+	"sort"
+	"time"
+)
+
+// TaxRatePPMScale is the scale of tax rates: a rate of 8.875% is stored as 88750 parts per million
+const TaxRatePPMScale = 1_000_000
+
+// TaxRate is the rate applied to line items with TaxCode in Jurisdiction
+type TaxRate struct {
+	ID           int64     `json:"id"`
+	Jurisdiction string    `json:"jurisdiction"`
+	TaxCode      string    `json:"tax_code"`
+	Name         string    `json:"name"`
+	RatePPM      int64     `json:"rate_ppm"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (r *TaxRate) Validate() error {
+	if r.Jurisdiction == "" || r.TaxCode == "" || r.RatePPM < 0 || r.RatePPM > TaxRatePPMScale {
+		return ErrInvalidTaxRate
+	}
+	return nil
+}
+
+// TaxBreakdown is the tax of all line items charged at the same rate
+type TaxBreakdown struct {
+	RatePPM            int64 `json:"rate_ppm"`
+	TaxableAmountMinor int64 `json:"taxable_amount_minor"`
+	TaxAmountMinor     int64 `json:"tax_amount_minor"`
+}
+
+// TaxCalculation is the result of taxing the line items of a billing
+type TaxCalculation struct {
+	// LineItems are the taxed line items, with TaxAmountMinor set per line
+	LineItems             []LineItem     `json:"line_items"`
+	SubtotalAmountMinor   int64          `json:"subtotal_amount_minor"`
+	TaxAmountMinor        int64          `json:"tax_amount_minor"`
+	GrandTotalAmountMinor int64          `json:"grand_total_amount_minor"`
+	Taxes                 []TaxBreakdown `json:"taxes"`
+}
+
+// CalculateTax computes the tax of each line item with the rate of its tax code, line items without a tax code are not taxed.
+// The tax base of a line is its amount minus its discount.
+// With inclusive pricing the line amounts already contain the tax, otherwise the tax is added on top.
+// Tax is computed and rounded half away from zero per line, so the breakdown always adds up to the sum of the lines.
+func CalculateTax(lineItems []LineItem, rates []TaxRate, inclusive bool) (TaxCalculation, error) {
+	ratesByCode := make(map[string]TaxRate, len(rates))
+	for _, rate := range rates {
+		ratesByCode[rate.TaxCode] = rate
+	}
+
+	calculation := TaxCalculation{
+		LineItems: make([]LineItem, len(lineItems)),
+		Taxes:     []TaxBreakdown{},
+	}
+	breakdownByRate := map[int64]*TaxBreakdown{}
+
+	for i, lineItem := range lineItems {
+		amountMinor := lineItem.AmountMinor - lineItem.DiscountAmountMinor
+		netAmountMinor := amountMinor
+		lineItem.TaxRatePPM = 0
+		lineItem.TaxAmountMinor = 0
+
+		if lineItem.TaxCode != "" {
+			rate, ok := ratesByCode[lineItem.TaxCode]
+			if !ok {
+				return TaxCalculation{}, ErrTaxRateNotFound
+			}
+			lineItem.TaxRatePPM = rate.RatePPM
+
+			if inclusive {
+				// net = gross / (1 + rate), the tax is whatever is left so that net + tax = gross
+				netAmountMinor = divRoundHalfAwayFromZero(
+					new(big.Int).Mul(big.NewInt(amountMinor), big.NewInt(TaxRatePPMScale)),
+					big.NewInt(TaxRatePPMScale+rate.RatePPM),
+				)
+				lineItem.TaxAmountMinor = amountMinor - netAmountMinor
+			} else {
+				lineItem.TaxAmountMinor = divRoundHalfAwayFromZero(
+					new(big.Int).Mul(big.NewInt(amountMinor), big.NewInt(rate.RatePPM)),
+					big.NewInt(TaxRatePPMScale),
+				)
+			}
+
+			breakdown, ok := breakdownByRate[rate.RatePPM]
+			if !ok {
+				breakdown = &TaxBreakdown{RatePPM: rate.RatePPM}
+				breakdownByRate[rate.RatePPM] = breakdown
+			}
+			breakdown.TaxableAmountMinor += netAmountMinor
+			breakdown.TaxAmountMinor += lineItem.TaxAmountMinor
+		}
+
+		calculation.LineItems[i] = lineItem
+		calculation.SubtotalAmountMinor += netAmountMinor
+		calculation.TaxAmountMinor += lineItem.TaxAmountMinor
+	}
+
+	for _, breakdown := range breakdownByRate {
+		calculation.Taxes = append(calculation.Taxes, *breakdown)
+	}
+	sort.Slice(calculation.Taxes, func(i, j int) bool {
+		return calculation.Taxes[i].RatePPM < calculation.Taxes[j].RatePPM
+	})
+
+	calculation.GrandTotalAmountMinor = calculation.SubtotalAmountMinor + calculation.TaxAmountMinor
+
+	return calculation, nil
+}
+
+// divRoundHalfAwayFromZero returns numerator / denominator rounded half away from zero, denominator must be positive
+func divRoundHalfAwayFromZero(numerator *big.Int, denominator *big.Int) int64 {
+	// (2n + d) / 2d for positive values, mirrored for negative ones
+	negative := numerator.Sign() < 0
+	n := new(big.Int).Abs(numerator)
+	n.Mul(n, big.NewInt(2))
+	n.Add(n, denominator)
+	d := new(big.Int).Mul(denominator, big.NewInt(2))
+
+	result := n.Quo(n, d).Int64()
+	if negative {
+		return -result
+	}
+	return result
+}
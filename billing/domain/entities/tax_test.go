@@ -0,0 +1,145 @@
+package entities
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCalculateTax(t *testing.T) {
+	rates := []TaxRate{
+		{Jurisdiction: "GB", TaxCode: "standard", RatePPM: 200000},
+		{Jurisdiction: "GB", TaxCode: "reduced", RatePPM: 50000},
+	}
+
+	tests := []struct {
+		name        string
+		lineItems   []LineItem
+		inclusive   bool
+		expected    TaxCalculation
+		expectedErr error
+	}{
+		{
+			name: "exclusive pricing rounds per line",
+			lineItems: []LineItem{
+				{Description: "A", AmountMinor: 1000, TaxCode: "standard"},
+				{Description: "B", AmountMinor: 333, TaxCode: "standard"}, // 66.6
+				{Description: "C", AmountMinor: 999, TaxCode: "reduced"},  // 49.95
+				{Description: "D", AmountMinor: 500},
+			},
+			expected: TaxCalculation{
+				LineItems: []LineItem{
+					{Description: "A", AmountMinor: 1000, TaxCode: "standard", TaxRatePPM: 200000, TaxAmountMinor: 200},
+					{Description: "B", AmountMinor: 333, TaxCode: "standard", TaxRatePPM: 200000, TaxAmountMinor: 67},
+					{Description: "C", AmountMinor: 999, TaxCode: "reduced", TaxRatePPM: 50000, TaxAmountMinor: 50},
+					{Description: "D", AmountMinor: 500},
+				},
+				SubtotalAmountMinor:   2832,
+				TaxAmountMinor:        317,
+				GrandTotalAmountMinor: 3149,
+				Taxes: []TaxBreakdown{
+					{RatePPM: 50000, TaxableAmountMinor: 999, TaxAmountMinor: 50},
+					{RatePPM: 200000, TaxableAmountMinor: 1333, TaxAmountMinor: 267},
+				},
+			},
+		},
+		{
+			name:      "inclusive pricing extracts the tax from the gross amount",
+			inclusive: true,
+			lineItems: []LineItem{
+				{Description: "A", AmountMinor: 1200, TaxCode: "standard"},
+				{Description: "B", AmountMinor: 999, TaxCode: "standard"}, // net 832.5
+				{Description: "C", AmountMinor: 105, TaxCode: "reduced"},
+			},
+			expected: TaxCalculation{
+				LineItems: []LineItem{
+					{Description: "A", AmountMinor: 1200, TaxCode: "standard", TaxRatePPM: 200000, TaxAmountMinor: 200},
+					{Description: "B", AmountMinor: 999, TaxCode: "standard", TaxRatePPM: 200000, TaxAmountMinor: 166},
+					{Description: "C", AmountMinor: 105, TaxCode: "reduced", TaxRatePPM: 50000, TaxAmountMinor: 5},
+				},
+				SubtotalAmountMinor:   1933,
+				TaxAmountMinor:        371,
+				GrandTotalAmountMinor: 2304,
+				Taxes: []TaxBreakdown{
+					{RatePPM: 50000, TaxableAmountMinor: 100, TaxAmountMinor: 5},
+					{RatePPM: 200000, TaxableAmountMinor: 1833, TaxAmountMinor: 366},
+				},
+			},
+		},
+		{
+			name: "credits are rounded away from zero",
+			lineItems: []LineItem{
+				{Description: "Credit", AmountMinor: -333, TaxCode: "standard"},
+			},
+			expected: TaxCalculation{
+				LineItems: []LineItem{
+					{Description: "Credit", AmountMinor: -333, TaxCode: "standard", TaxRatePPM: 200000, TaxAmountMinor: -67},
+				},
+				SubtotalAmountMinor:   -333,
+				TaxAmountMinor:        -67,
+				GrandTotalAmountMinor: -400,
+				Taxes: []TaxBreakdown{
+					{RatePPM: 200000, TaxableAmountMinor: -333, TaxAmountMinor: -67},
+				},
+			},
+		},
+		{
+			name: "untaxed billing",
+			lineItems: []LineItem{
+				{Description: "A", AmountMinor: 1000},
+			},
+			expected: TaxCalculation{
+				LineItems:             []LineItem{{Description: "A", AmountMinor: 1000}},
+				SubtotalAmountMinor:   1000,
+				GrandTotalAmountMinor: 1000,
+				Taxes:                 []TaxBreakdown{},
+			},
+		},
+		{
+			name: "unknown tax code",
+			lineItems: []LineItem{
+				{Description: "A", AmountMinor: 1000, TaxCode: "luxury"},
+			},
+			expectedErr: ErrTaxRateNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CalculateTax(tt.lineItems, rates, tt.inclusive)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("CalculateTax() error = %v, expected %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("CalculateTax() = %+v, expected %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTaxRate_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     TaxRate
+		expected error
+	}{
+		{name: "valid", rate: TaxRate{Jurisdiction: "US-NY", TaxCode: "standard", RatePPM: 88750}, expected: nil},
+		{name: "zero rated", rate: TaxRate{Jurisdiction: "GB", TaxCode: "zero", RatePPM: 0}, expected: nil},
+		{name: "missing jurisdiction", rate: TaxRate{TaxCode: "standard", RatePPM: 200000}, expected: ErrInvalidTaxRate},
+		{name: "missing tax code", rate: TaxRate{Jurisdiction: "GB", RatePPM: 200000}, expected: ErrInvalidTaxRate},
+		{name: "negative rate", rate: TaxRate{Jurisdiction: "GB", TaxCode: "standard", RatePPM: -1}, expected: ErrInvalidTaxRate},
+		{name: "rate above 100%", rate: TaxRate{Jurisdiction: "GB", TaxCode: "standard", RatePPM: 1000001}, expected: ErrInvalidTaxRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.rate.Validate()
+			if !errors.Is(result, tt.expected) {
+				t.Errorf("Validate() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package entities
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+type MeterAggregation = string
+
+const (
+	// MeterAggregationSum bills the total quantity of the period, e.g. API calls
+	MeterAggregationSum MeterAggregation = "sum"
+	// MeterAggregationMax bills the peak quantity of the period, e.g. concurrent seats
+	MeterAggregationMax MeterAggregation = "max"
+	// MeterAggregationLast bills the quantity last reported in the period, e.g. stored gigabytes
+	MeterAggregationLast MeterAggregation = "last"
+)
+
+// UsageEventsMaxBatchSize is the largest number of usage events ingested at once
+const UsageEventsMaxBatchSize = 1000
+
+// UsageDedupIDMaxLength is the longest dedup ID a usage event can be reported with
+const UsageDedupIDMaxLength = 255
+
+// Meter measures a kind of usage. The usage of a billing period is aggregated per meter when the billing closes
+// and billed at the unit amount, in the currency of the meter.
+type Meter struct {
+	ID                int64            `json:"id"`
+	Code              string           `json:"code"`
+	Name              string           `json:"name"`
+	Aggregation       MeterAggregation `json:"aggregation"`
+	Currency          string           `json:"currency"`
+	CurrencyPrecision int64            `json:"currency_precision"`
+	UnitAmountMinor   int64            `json:"unit_amount_minor"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+func (m *Meter) Validate() error {
+	if m.Code == "" || m.Name == "" || m.Currency == "" || m.UnitAmountMinor < 0 {
+		return ErrInvalidMeter
+	}
+
+	switch m.Aggregation {
+	case MeterAggregationSum, MeterAggregationMax, MeterAggregationLast:
+	default:
+		return ErrInvalidMeter
+	}
+
+	return nil
+}
+
+// UsageEvent reports a quantity of a meter used by a user at a time. An event reported again with the dedup ID of
+// one already ingested for the user is ingested once.
+type UsageEvent struct {
+	ID        int64     `json:"-"`
+	UserID    string    `json:"user_id"`
+	MeterCode string    `json:"meter"`
+	MeterID   int64     `json:"-"`
+	Quantity  int64     `json:"quantity"`
+	Timestamp time.Time `json:"timestamp"`
+	DedupID   string    `json:"dedup_id"`
+}
+
+func (e *UsageEvent) Validate() error {
+	if e.UserID == "" || e.MeterCode == "" || e.Quantity < 0 || e.Timestamp.IsZero() {
+		return ErrInvalidUsageEvent
+	}
+	if e.DedupID == "" || len(e.DedupID) > UsageDedupIDMaxLength {
+		return ErrInvalidUsageEvent
+	}
+	return nil
+}
+
+// UsageAggregate is the usage of a meter over a billing period, priced in the currency of the meter
+type UsageAggregate struct {
+	MeterCode       string           `json:"meter"`
+	MeterName       string           `json:"meter_name"`
+	Aggregation     MeterAggregation `json:"aggregation"`
+	Quantity        int64            `json:"quantity"`
+	UnitAmountMinor int64            `json:"unit_amount_minor"`
+	Currency        string           `json:"currency"`
+}
+
+// LineItem returns the charge of the aggregated usage at the unit amount of its meter, ErrUsageAmountTooLarge is
+// returned when the amount does not fit in minor units
+func (a UsageAggregate) LineItem() (LineItem, error) {
+	amountMinor := new(big.Int).Mul(big.NewInt(a.Quantity), big.NewInt(a.UnitAmountMinor))
+	if !amountMinor.IsInt64() {
+		return LineItem{}, ErrUsageAmountTooLarge
+	}
+
+	return LineItem{
+		Kind:        LineItemKindCharge,
+		Description: fmt.Sprintf("%s × %d", a.MeterName, a.Quantity),
+		AmountMinor: amountMinor.Int64(),
+	}, nil
+}
@@ -0,0 +1,126 @@
+package entities
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMeter_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		meter    Meter
+		expected error
+	}{
+		{
+			name:     "summed meter",
+			meter:    Meter{Code: "api_calls", Name: "API calls", Aggregation: MeterAggregationSum, Currency: "USD", UnitAmountMinor: 2},
+			expected: nil,
+		},
+		{
+			name:     "free meter",
+			meter:    Meter{Code: "seats", Name: "Seats", Aggregation: MeterAggregationMax, Currency: "USD"},
+			expected: nil,
+		},
+		{
+			name:     "unknown aggregation",
+			meter:    Meter{Code: "storage", Name: "Storage", Aggregation: "avg", Currency: "USD", UnitAmountMinor: 10},
+			expected: ErrInvalidMeter,
+		},
+		{
+			name:     "negative unit amount",
+			meter:    Meter{Code: "storage", Name: "Storage", Aggregation: MeterAggregationLast, Currency: "USD", UnitAmountMinor: -10},
+			expected: ErrInvalidMeter,
+		},
+		{
+			name:     "missing code",
+			meter:    Meter{Name: "Storage", Aggregation: MeterAggregationLast, Currency: "USD", UnitAmountMinor: 10},
+			expected: ErrInvalidMeter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.meter.Validate()
+			if err != tt.expected {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUsageEvent_Validate(t *testing.T) {
+	timestamp := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		event    UsageEvent
+		expected error
+	}{
+		{
+			name:     "valid event",
+			event:    UsageEvent{UserID: "user123", MeterCode: "api_calls", Quantity: 3, Timestamp: timestamp, DedupID: "req-1"},
+			expected: nil,
+		},
+		{
+			name:     "zero quantity",
+			event:    UsageEvent{UserID: "user123", MeterCode: "seats", Quantity: 0, Timestamp: timestamp, DedupID: "req-1"},
+			expected: nil,
+		},
+		{
+			name:     "negative quantity",
+			event:    UsageEvent{UserID: "user123", MeterCode: "api_calls", Quantity: -1, Timestamp: timestamp, DedupID: "req-1"},
+			expected: ErrInvalidUsageEvent,
+		},
+		{
+			name:     "missing dedup ID",
+			event:    UsageEvent{UserID: "user123", MeterCode: "api_calls", Quantity: 3, Timestamp: timestamp},
+			expected: ErrInvalidUsageEvent,
+		},
+		{
+			name:     "missing timestamp",
+			event:    UsageEvent{UserID: "user123", MeterCode: "api_calls", Quantity: 3, DedupID: "req-1"},
+			expected: ErrInvalidUsageEvent,
+		},
+		{
+			name:     "missing user",
+			event:    UsageEvent{MeterCode: "api_calls", Quantity: 3, Timestamp: timestamp, DedupID: "req-1"},
+			expected: ErrInvalidUsageEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.event.Validate()
+			if err != tt.expected {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUsageAggregate_LineItem(t *testing.T) {
+	aggregate := UsageAggregate{MeterCode: "api_calls", MeterName: "API calls", Aggregation: MeterAggregationSum, Quantity: 1500, UnitAmountMinor: 2, Currency: "USD"}
+
+	lineItem, err := aggregate.LineItem()
+	if err != nil {
+		t.Fatalf("LineItem() error = %v", err)
+	}
+	if lineItem.Kind != LineItemKindCharge {
+		t.Errorf("Kind = %v, expected %v", lineItem.Kind, LineItemKindCharge)
+	}
+	if lineItem.Description != "API calls × 1500" {
+		t.Errorf("Description = %q, expected %q", lineItem.Description, "API calls × 1500")
+	}
+	if lineItem.AmountMinor != 3000 {
+		t.Errorf("AmountMinor = %v, expected 3000", lineItem.AmountMinor)
+	}
+
+	// a quantity priced over what minor units hold is refused instead of wrapping around
+	aggregate.Quantity = math.MaxInt64 / 2
+	aggregate.UnitAmountMinor = 3
+	_, err = aggregate.LineItem()
+	if err != ErrUsageAmountTooLarge {
+		t.Errorf("LineItem() error = %v, expected %v", err, ErrUsageAmountTooLarge)
+	}
+}
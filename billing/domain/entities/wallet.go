@@ -0,0 +1,92 @@
+package entities
+
+import (
+	"time"
+)
+
+type WalletTransactionKind = string
+
+const (
+	WalletTransactionKindTopUp       WalletTransactionKind = "top_up"
+	WalletTransactionKindAdjustment  WalletTransactionKind = "adjustment"
+	WalletTransactionKindBillingDraw WalletTransactionKind = "billing_draw"
+)
+
+// WalletReasonMaxLength is the longest reason a wallet adjustment can be made with
+const WalletReasonMaxLength = 255
+
+// Wallet is credit a user holds in a currency, billings in the currency draw from it when they close.
+// Its balance never goes below zero.
+type Wallet struct {
+	ID                int64     `json:"-"`
+	UserID            string    `json:"user_id"`
+	Currency          string    `json:"currency"`
+	CurrencyPrecision int64     `json:"currency_precision"`
+	BalanceMinor      int64     `json:"balance_minor"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// WalletTransaction changes the balance of a wallet by a signed amount: top-ups add credit, adjustments add or remove it and
+// billing draws remove it. The external reference identifies a top-up at its provider.
+type WalletTransaction struct {
+	ID                    int64  `json:"-"`
+	ExternalTransactionID string `json:"transaction_id"`
+
+	WalletID          int64  `json:"-"`
+	UserID            string `json:"user_id"`
+	Currency          string `json:"currency"`
+	CurrencyPrecision int64  `json:"currency_precision"`
+
+	Kind              WalletTransactionKind `json:"kind"`
+	AmountMinor       int64                 `json:"amount_minor"`
+	BalanceAfterMinor int64                 `json:"balance_after_minor"`
+	Reason            string                `json:"reason,omitempty"`
+	ExternalReference string                `json:"external_reference,omitempty"`
+
+	BillingID         *int64  `json:"-"`
+	ExternalBillingID *string `json:"billing_id,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CanCreditWithAmount reports whether an amount fits the precision of the wallet currency
+func (t *WalletTransaction) CanCreditWithAmount(amount float64) bool {
+	return hasAtMostXDecimals(amount, t.CurrencyPrecision)
+}
+
+// Validate checks a top-up adds credit and an adjustment changes the balance for a reason, billing draws are made when
+// billings close and are never validated here
+func (t *WalletTransaction) Validate() error {
+	if t.UserID == "" || t.Currency == "" || len(t.Reason) > WalletReasonMaxLength {
+		return ErrInvalidWalletTransaction
+	}
+
+	switch t.Kind {
+	case WalletTransactionKindTopUp:
+		if t.AmountMinor <= 0 {
+			return ErrInvalidWalletTransaction
+		}
+	case WalletTransactionKindAdjustment:
+		if t.AmountMinor == 0 || t.Reason == "" {
+			return ErrInvalidWalletTransaction
+		}
+	default:
+		return ErrInvalidWalletTransaction
+	}
+	return nil
+}
+
+// WalletDraw identifies the wallet transaction and the payment of a billing paid from the wallet of its user
+type WalletDraw struct {
+	ExternalTransactionID string
+	ExternalPaymentID     string
+}
+
+// WalletDrawAmountMinor is what a billing with dueAmountMinor left to pay draws from a wallet holding balanceMinor
+func WalletDrawAmountMinor(balanceMinor int64, dueAmountMinor int64) int64 {
+	if balanceMinor <= 0 || dueAmountMinor <= 0 {
+		return 0
+	}
+	return min(balanceMinor, dueAmountMinor)
+}
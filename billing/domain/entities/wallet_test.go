@@ -0,0 +1,88 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWalletTransaction_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		transaction WalletTransaction
+		expected    error
+	}{
+		{
+			name:        "valid top-up",
+			transaction: WalletTransaction{UserID: "user-1", Currency: "USD", Kind: WalletTransactionKindTopUp, AmountMinor: 1000},
+			expected:    nil,
+		},
+		{
+			name:        "negative top-up",
+			transaction: WalletTransaction{UserID: "user-1", Currency: "USD", Kind: WalletTransactionKindTopUp, AmountMinor: -1000},
+			expected:    ErrInvalidWalletTransaction,
+		},
+		{
+			name:        "negative adjustment",
+			transaction: WalletTransaction{UserID: "user-1", Currency: "USD", Kind: WalletTransactionKindAdjustment, AmountMinor: -500, Reason: "expired credit"},
+			expected:    nil,
+		},
+		{
+			name:        "adjustment without reason",
+			transaction: WalletTransaction{UserID: "user-1", Currency: "USD", Kind: WalletTransactionKindAdjustment, AmountMinor: 500},
+			expected:    ErrInvalidWalletTransaction,
+		},
+		{
+			name:        "zero adjustment",
+			transaction: WalletTransaction{UserID: "user-1", Currency: "USD", Kind: WalletTransactionKindAdjustment, Reason: "nothing"},
+			expected:    ErrInvalidWalletTransaction,
+		},
+		{
+			name:        "reason too long",
+			transaction: WalletTransaction{UserID: "user-1", Currency: "USD", Kind: WalletTransactionKindAdjustment, AmountMinor: 500, Reason: strings.Repeat("a", WalletReasonMaxLength+1)},
+			expected:    ErrInvalidWalletTransaction,
+		},
+		{
+			name:        "billing draws are not made by hand",
+			transaction: WalletTransaction{UserID: "user-1", Currency: "USD", Kind: WalletTransactionKindBillingDraw, AmountMinor: -500},
+			expected:    ErrInvalidWalletTransaction,
+		},
+		{
+			name:        "missing user",
+			transaction: WalletTransaction{Currency: "USD", Kind: WalletTransactionKindTopUp, AmountMinor: 1000},
+			expected:    ErrInvalidWalletTransaction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.transaction.Validate()
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWalletDrawAmountMinor(t *testing.T) {
+	tests := []struct {
+		name     string
+		balance  int64
+		due      int64
+		expected int64
+	}{
+		{name: "balance covers the billing", balance: 5000, due: 1200, expected: 1200},
+		{name: "billing takes the whole balance", balance: 800, due: 1200, expected: 800},
+		{name: "empty wallet", balance: 0, due: 1200, expected: 0},
+		{name: "nothing due", balance: 800, due: 0, expected: 0},
+		{name: "billing of a negative total", balance: 800, due: -300, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WalletDrawAmountMinor(tt.balance, tt.due); got != tt.expected {
+				t.Errorf("WalletDrawAmountMinor(%d, %d) = %d, expected %d", tt.balance, tt.due, got, tt.expected)
+			}
+		})
+	}
+}
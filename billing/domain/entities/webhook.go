@@ -0,0 +1,103 @@
+package entities
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type WebhookDeliveryStatus = string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+// headers of webhook requests, the signature lets receivers check the request comes from us and is recent
+const (
+	WebhookSignatureHeader  = "X-Billing-Signature"
+	WebhookEventIDHeader    = "X-Billing-Event-ID"
+	WebhookEventTypeHeader  = "X-Billing-Event-Type"
+	WebhookDeliveryIDHeader = "X-Billing-Delivery-ID"
+)
+
+// WebhookSecretMinLength is the shortest secret a webhook can be signed with
+const WebhookSecretMinLength = 16
+
+var webhookEventTypes = []BillingEventType{BillingEventCreated, BillingEventLineItemAdded, BillingEventClosed, BillingEventCancelled, BillingEventPaymentReminder, BillingEventSpendThresholdCrossed, BillingEventUsageUnbilled}
+
+// Webhook is a subscription of a URL to billing events, requests are signed with its secret
+type Webhook struct {
+	ID                int64              `json:"id"`
+	ExternalWebhookID string             `json:"external_webhook_id"`
+	URL               string             `json:"url"`
+	EventTypes        []BillingEventType `json:"event_types"`
+	Secret            string             `json:"-"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+func (w *Webhook) Validate() error {
+	endpoint, err := url.Parse(w.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return ErrInvalidWebhook
+	}
+	if len(w.EventTypes) == 0 {
+		return ErrInvalidWebhook
+	}
+	for _, eventType := range w.EventTypes {
+		if !slices.Contains(webhookEventTypes, eventType) {
+			return ErrInvalidWebhook
+		}
+	}
+	if len(w.Secret) < WebhookSecretMinLength {
+		return ErrInvalidWebhook
+	}
+	return nil
+}
+
+// WebhookDelivery is an event sent to a webhook, it is retried until it succeeds or runs out of attempts
+type WebhookDelivery struct {
+	ID                 int64                    `json:"id"`
+	ExternalDeliveryID string                   `json:"external_delivery_id"`
+	WebhookID          int64                    `json:"webhook_id"`
+	ExternalWebhookID  string                   `json:"external_webhook_id"`
+	EventID            string                   `json:"event_id"`
+	EventType          BillingEventType         `json:"event_type"`
+	Payload            []byte                   `json:"payload"`
+	Status             WebhookDeliveryStatus    `json:"status"`
+	Attempts           []WebhookDeliveryAttempt `json:"attempts"`
+	CreatedAt          time.Time                `json:"created_at"`
+	UpdatedAt          time.Time                `json:"updated_at"`
+}
+
+// WebhookDeliveryAttempt is one request of a delivery, StatusCode is 0 when no response was received
+type WebhookDeliveryAttempt struct {
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// Succeeded reports whether the receiver accepted the request
+func (a *WebhookDeliveryAttempt) Succeeded() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// SignWebhookPayload signs a payload sent at timestamp, the signature is "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<payload>">"
+func SignWebhookPayload(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return fmt.Sprintf("t=%s,v1=%s", t, hex.EncodeToString(mac.Sum(nil)))
+}
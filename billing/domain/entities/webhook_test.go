@@ -0,0 +1,72 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebhook_Validate(t *testing.T) {
+	valid := Webhook{URL: "https://partner.example.com/billing", EventTypes: []BillingEventType{BillingEventClosed}, Secret: "whsec_0123456789abcdef"}
+
+	tests := []struct {
+		name     string
+		modify   func(w *Webhook)
+		expected error
+	}{
+		{name: "valid webhook", modify: func(w *Webhook) {}, expected: nil},
+		{name: "all event types", modify: func(w *Webhook) { w.EventTypes = webhookEventTypes }, expected: nil},
+		{name: "plain http", modify: func(w *Webhook) { w.URL = "http://localhost:8080/hook" }, expected: nil},
+		{name: "relative URL", modify: func(w *Webhook) { w.URL = "/billing" }, expected: ErrInvalidWebhook},
+		{name: "unsupported scheme", modify: func(w *Webhook) { w.URL = "ftp://partner.example.com" }, expected: ErrInvalidWebhook},
+		{name: "no event types", modify: func(w *Webhook) { w.EventTypes = nil }, expected: ErrInvalidWebhook},
+		{name: "unknown event type", modify: func(w *Webhook) { w.EventTypes = []BillingEventType{"billing.paid"} }, expected: ErrInvalidWebhook},
+		{name: "short secret", modify: func(w *Webhook) { w.Secret = "secret" }, expected: ErrInvalidWebhook},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook := valid
+			tt.modify(&webhook)
+			if err := webhook.Validate(); err != tt.expected {
+				t.Errorf("Validate() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliveryAttempt_Succeeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  WebhookDeliveryAttempt
+		expected bool
+	}{
+		{name: "ok", attempt: WebhookDeliveryAttempt{StatusCode: 200}, expected: true},
+		{name: "no content", attempt: WebhookDeliveryAttempt{StatusCode: 204}, expected: true},
+		{name: "redirect", attempt: WebhookDeliveryAttempt{StatusCode: 301}, expected: false},
+		{name: "server error", attempt: WebhookDeliveryAttempt{StatusCode: 503}, expected: false},
+		{name: "no response", attempt: WebhookDeliveryAttempt{Error: "connection refused"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.attempt.Succeeded(); result != tt.expected {
+				t.Errorf("Succeeded() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	timestamp := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	payload := []byte(`{"event_id":"billing.closed:billing-1"}`)
+
+	expected := "t=1772366400,v1=f2bab75125f4804641ef9eb252539a1a7b976bef8d2a88531614b739adb8b9a2"
+	if result := SignWebhookPayload("whsec_0123456789abcdef", timestamp, payload); result != expected {
+		t.Errorf("SignWebhookPayload() = %v, expected %v", result, expected)
+	}
+
+	// another secret gives another signature
+	if result := SignWebhookPayload("whsec_fedcba9876543210", timestamp, payload); result == expected {
+		t.Errorf("SignWebhookPayload() = %v, expected a different signature", result)
+	}
+}
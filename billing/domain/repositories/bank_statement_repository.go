@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type BankStatementRepository interface {
+	// CreateBankStatement creates a statement with its transactions and returns it. A statement with the content hash of an
+	// imported statement returns that statement.
+	CreateBankStatement(ctx context.Context, statement *entities.BankStatement) (*entities.BankStatement, error)
+
+	// GetBankStatementByExternalID gets a statement with its transactions
+	GetBankStatementByExternalID(ctx context.Context, externalStatementID string) (*entities.BankStatement, error)
+
+	// GetBankStatementByContentHash gets the statement imported from a file with its transactions
+	GetBankStatementByContentHash(ctx context.Context, contentHash string) (*entities.BankStatement, error)
+
+	// GetBankTransactionByExternalID gets a transaction of a statement
+	GetBankTransactionByExternalID(ctx context.Context, externalTransactionID string) (*entities.BankTransaction, error)
+
+	// ListBankTransactionsByStatus lists the transactions in a status, oldest first
+	ListBankTransactionsByStatus(ctx context.Context, status entities.BankTransactionStatus, limit int) ([]entities.BankTransaction, error)
+
+	// SettleBankTransaction sets the payment of a matched transaction, or sends it to review when its payment is rejected.
+	// A transaction already settled is left as is.
+	SettleBankTransaction(ctx context.Context, transaction *entities.BankTransaction) error
+
+	// ReviewBankTransaction sets the outcome of a transaction waiting for review, a reviewed transaction fails with ErrBankTransactionReviewed
+	ReviewBankTransaction(ctx context.Context, transaction *entities.BankTransaction) error
+}
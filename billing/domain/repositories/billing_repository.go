@@ -11,17 +11,31 @@ type DBRepository interface {
 	// GetBillingByExternalID gets a billing by ID
 	GetBillingByExternalID(ctx context.Context, externalBillingID string) (*entities.Billing, error)
 
-	// CreateBilling creates a new billing and returns the external billing ID
-	CreateBilling(ctx context.Context, userID string, externalBillingID string, description string, currency string, currencyPrecision int64, plannedClosedAt *time.Time) (int64, error)
+	// CreateBilling creates a new billing and returns the internal billing ID, billing.created is written to the outbox in the same transaction
+	CreateBilling(ctx context.Context, billing *entities.Billing) (int64, error)
 
-	// AddLineItem adds a line item to a billing
-	AddLineItem(ctx context.Context, billingID int64, description string, amountMinor int64) error
+	// AddLineItem adds a line item to a billing, billing.line_item_added is written to the outbox in the same transaction
+	AddLineItem(ctx context.Context, billingID int64, lineItem *entities.LineItem) error
 
-	// CloseBilling closes a billing and sets the actual closed at time
-	CloseBilling(ctx context.Context, billingID int64, actualClosedAt time.Time) error
+	// CloseBilling closes a billing, sets the actual closed at time and assigns the next invoice number of its user group.
+	// Closing an already closed billing returns the invoice number it was assigned.
+	CloseBilling(ctx context.Context, billingID int64, actualClosedAt time.Time) (string, error)
 
-	// CreateBillingSummary creates a billing summary
-	CreateBillingSummary(ctx context.Context, externalBillingID string, billingSummary []byte) error
+	// CancelBilling cancels an open billing and writes billing.cancelled to the outbox, cancelling an already cancelled billing does nothing
+	CancelBilling(ctx context.Context, billingID int64, reason string, cancelledAt time.Time) error
+
+	// RecordSpendThresholdCrossed writes billing.spend_threshold_crossed to the outbox, recording a threshold again does nothing
+	RecordSpendThresholdCrossed(ctx context.Context, billingID int64, threshold int64, totalAmountMinor int64, occurredAt time.Time) error
+
+	// LinkNextBilling links a billing to the billing of the following period
+	LinkNextBilling(ctx context.Context, billingID int64, nextExternalBillingID string) error
+
+	// CreateBillingSummary creates a billing summary, writes billing.closed to the outbox and posts it to the ledger. With a
+	// walletDraw, what the wallet of the user holds in the currency of the billing pays its grand total in the same
+	// transaction, and the amount drawn is returned and set as paid from credit in the summary. The wallet is locked while
+	// it is drawn, so concurrent closures never overdraw it. Creating the same summary again is a no-op that returns the
+	// amount drawn the first time, a different one returns entities.ErrBillingSummaryMismatch.
+	CreateBillingSummary(ctx context.Context, externalBillingID string, billingSummary []byte, walletDraw *entities.WalletDraw) (int64, error)
 
 	// GetBillingSummary gets a billing summary
 	GetBillingSummary(ctx context.Context, externalBillingID string) (*entities.BillingSummary, error)
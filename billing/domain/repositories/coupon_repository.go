@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type CouponRepository interface {
+	// CreateCoupon creates a new coupon and returns the internal coupon ID
+	CreateCoupon(ctx context.Context, coupon *entities.Coupon) (int64, error)
+
+	// GetCouponByCode gets a coupon by code
+	GetCouponByCode(ctx context.Context, code string) (*entities.Coupon, error)
+
+	// RedeemCoupon records the redemption of a coupon by a billing, redeeming it again for the same billing is a no-op
+	RedeemCoupon(ctx context.Context, couponID int64, billingID int64) error
+}
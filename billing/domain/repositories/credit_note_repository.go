@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type CreditNoteRepository interface {
+	// CreateCreditNote numbers and creates a credit note, it fails with ErrCreditNoteExceedsBalance
+	// when the credit notes of the billing would exceed billedAmountMinor
+	CreateCreditNote(ctx context.Context, creditNote *entities.CreditNote, billedAmountMinor int64) (int64, error)
+
+	// GetCreditNoteByExternalID gets a credit note with its line items
+	GetCreditNoteByExternalID(ctx context.Context, externalCreditNoteID string) (*entities.CreditNote, error)
+
+	// ListCreditNotesByBillingID lists the credit notes of a billing with their line items, oldest first
+	ListCreditNotesByBillingID(ctx context.Context, billingID int64) ([]entities.CreditNote, error)
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+type DunningRepository interface {
+	// CreateDunning starts the dunning of a billing and returns it. Creating the dunning of a billing again returns the existing dunning.
+	CreateDunning(ctx context.Context, dunning *entities.Dunning) (*entities.Dunning, error)
+
+	// GetDunningByBillingID gets the dunning of a billing
+	GetDunningByBillingID(ctx context.Context, billingID int64) (*entities.Dunning, error)
+
+	// AdvanceDunning moves a dunning to a step and status and writes the payment reminder of the step to the outbox.
+	// A step that was already reached is not written again.
+	AdvanceDunning(ctx context.Context, billingID int64, step int, status entities.DunningStatus, outstandingAmountMinor int64, occurredAt time.Time) error
+
+	// ResolveDunning stops the dunning of a paid billing, a dunning that already ended is left as it is
+	ResolveDunning(ctx context.Context, billingID int64) error
+}
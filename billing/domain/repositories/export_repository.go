@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type ExportRepository interface {
+	// StreamExportRows calls handleRow for each line item of the billings matching filter, ordered by billing and line item,
+	// as the rows are read from the database. An error returned by handleRow stops the stream and is returned.
+	StreamExportRows(ctx context.Context, filter entities.ExportFilter, handleRow func(row *entities.ExportRow) error) error
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type InvoiceNumberRepository interface {
+	// UpsertInvoiceNumberSeries creates or replaces the invoice number series of a user group
+	UpsertInvoiceNumberSeries(ctx context.Context, series *entities.InvoiceNumberSeries) error
+
+	// ListInvoiceNumberSeries lists the invoice number series ordered by user group
+	ListInvoiceNumberSeries(ctx context.Context) ([]entities.InvoiceNumberSeries, error)
+}
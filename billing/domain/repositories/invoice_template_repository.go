@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type InvoiceTemplateRepository interface {
+	// UpsertInvoiceTemplate creates or replaces the invoice template of a tenant and format
+	UpsertInvoiceTemplate(ctx context.Context, invoiceTemplate *entities.InvoiceTemplate) error
+
+	// GetInvoiceTemplate returns ErrInvoiceTemplateNotFound when the tenant uses the default template of the format
+	GetInvoiceTemplate(ctx context.Context, tenant string, format entities.InvoiceFormat) (*entities.InvoiceTemplate, error)
+}
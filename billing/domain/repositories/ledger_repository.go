@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+// LedgerRepository reads the ledger, journal entries are posted by the repositories of the operations they record in the
+// same transaction
+type LedgerRepository interface {
+	// ListAccountBalances lists the balance of every account in every currency it was posted in, an empty currency lists
+	// all currencies
+	ListAccountBalances(ctx context.Context, currency string) ([]entities.LedgerAccountBalance, error)
+
+	// ListJournalEntriesByBillingID lists the journal entries of a billing with their postings, oldest posted first
+	ListJournalEntriesByBillingID(ctx context.Context, billingID int64) ([]entities.JournalEntry, error)
+
+	// CheckLedger totals the debits and credits of every currency and lists the entries whose debits do not equal their credits
+	CheckLedger(ctx context.Context) (*entities.LedgerCheck, error)
+}
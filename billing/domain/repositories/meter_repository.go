@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+type MeterRepository interface {
+	// CreateMeter creates a new meter and returns the internal meter ID
+	CreateMeter(ctx context.Context, meter *entities.Meter) (int64, error)
+
+	// ListMeters lists the meters by code
+	ListMeters(ctx context.Context) ([]entities.Meter, error)
+
+	// IngestUsageEvents stores usage events of known meters and returns how many were new,
+	// events whose dedup ID is already ingested for their user are skipped
+	IngestUsageEvents(ctx context.Context, events []entities.UsageEvent) (int, error)
+
+	// ClaimUsage claims the unbilled usage events of the user of a closing billing until the end of its period, for the meters
+	// of its currency, and returns their aggregates by meter. Billings without a period claim the events until closedAt. Late
+	// events of earlier periods roll into the billing, events in the period of another open billing of the user in the same
+	// currency are left for it. Usage of the period in another currency that no open billing of the user can claim is written
+	// to the outbox as billing.usage_unbilled.
+	// Claiming the usage of a billing again returns the aggregates of the first claim and of the events it claims since.
+	ClaimUsage(ctx context.Context, billingID int64, closedAt time.Time) ([]entities.UsageAggregate, error)
+}
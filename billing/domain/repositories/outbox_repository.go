@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+// OutboxRepository relays the messages written to the outbox at least once. Offsets follow the order messages were inserted,
+// not the order their transactions committed, so messages are in order within a transaction but not across transactions:
+// a message whose transaction commits late is published after messages with higher offsets.
+type OutboxRepository interface {
+	// RelayOutbox calls publish for up to limit pending messages in offset order and marks each published message as sent.
+	// Relays run one at a time so that they do not publish the same messages, a relay that finds another one running relays
+	// nothing. A failed publish stops the relay and is returned, the messages published before it stay sent.
+	RelayOutbox(ctx context.Context, limit int, publish func(message *entities.OutboxMessage) error) (int, error)
+
+	// ReplayOutbox calls publish for up to limit sent messages from fromOffset in offset order, and returns how many were
+	// replayed and the offset to continue from. Replays wait for a running relay so that the two do not interleave. A message
+	// sent after a replay went past its offset is not replayed from the returned offset, a replay from an earlier offset covers it.
+	ReplayOutbox(ctx context.Context, fromOffset int64, limit int, publish func(message *entities.OutboxMessage) error) (int, int64, error)
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type PartyRepository interface {
+	// UpsertParty creates or replaces the party of a role and key
+	UpsertParty(ctx context.Context, party *entities.Party) error
+
+	// GetParty returns ErrPartyNotFound when no party is set for the role and key
+	GetParty(ctx context.Context, role entities.PartyRole, key string) (*entities.Party, error)
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type PaymentRepository interface {
+	// CreatePayment records a payment and returns its internal ID. A payment with the method and external reference of a
+	// recorded payment is recorded once: payment is set to the recorded one, or ErrPaymentReferenceTaken is returned when
+	// it was recorded for another billing.
+	CreatePayment(ctx context.Context, payment *entities.Payment) (int64, error)
+
+	// GetPaymentByExternalID gets a payment with the sum of its succeeded refunds
+	GetPaymentByExternalID(ctx context.Context, externalPaymentID string) (*entities.Payment, error)
+
+	// ListPaymentsByBillingID lists the payments of a billing with the sum of their succeeded refunds, oldest first
+	ListPaymentsByBillingID(ctx context.Context, billingID int64) ([]entities.Payment, error)
+
+	// CreateChargeAttempt records the outcome of charging a billing and returns its internal ID
+	CreateChargeAttempt(ctx context.Context, attempt *entities.ChargeAttempt) (int64, error)
+
+	// ListChargeAttemptsByBillingID lists the charge attempts of a billing, oldest first
+	ListChargeAttemptsByBillingID(ctx context.Context, billingID int64) ([]entities.ChargeAttempt, error)
+}
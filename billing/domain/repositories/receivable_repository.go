@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+type ReceivableRepository interface {
+	// ListOpenReceivables lists the closed billings with something outstanding, oldest closed first. An empty currency lists all currencies.
+	ListOpenReceivables(ctx context.Context, currency string) ([]entities.Receivable, error)
+
+	// ListOpenReceivablesAsOf lists the receivables as they stood just before asOf: only the billings closed, credit notes
+	// issued, payments paid and refunds settled before asOf count.
+	ListOpenReceivablesAsOf(ctx context.Context, asOf time.Time) ([]entities.Receivable, error)
+}
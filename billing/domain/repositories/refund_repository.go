@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type RefundRepository interface {
+	// CreateRefund creates a pending refund of a payment and returns it. A refund requested again with the idempotency key of
+	// a refund of the payment returns that refund, or fails with ErrRefundKeyReused if the amount, reason or credit note
+	// differ. It fails with ErrRefundExceedsPayment when the pending and succeeded
+	// refunds of the payment would exceed its amount, and with ErrRefundExceedsCreditNote when the refunds settling the
+	// credit note would exceed its total.
+	CreateRefund(ctx context.Context, refund *entities.Refund) (*entities.Refund, error)
+
+	// GetRefundByExternalID gets a refund by ID
+	GetRefundByExternalID(ctx context.Context, externalRefundID string) (*entities.Refund, error)
+
+	// ListRefundsByPaymentID lists the refunds of a payment, oldest first
+	ListRefundsByPaymentID(ctx context.Context, paymentID int64) ([]entities.Refund, error)
+
+	// CompleteRefund sets the outcome of a pending refund, a completed refund is left as it is
+	CompleteRefund(ctx context.Context, refundID int64, status entities.RefundStatus, errorMessage string) error
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+type SubscriptionRepository interface {
+	// CreatePlan creates a new plan and returns the internal plan ID
+	CreatePlan(ctx context.Context, plan *entities.Plan) (int64, error)
+
+	// GetPlanByExternalID gets a plan by external ID
+	GetPlanByExternalID(ctx context.Context, externalPlanID string) (*entities.Plan, error)
+
+	// GetPlanByID gets a plan by internal ID
+	GetPlanByID(ctx context.Context, planID int64) (*entities.Plan, error)
+
+	// CreateSubscription creates a new subscription and returns the internal subscription ID
+	CreateSubscription(ctx context.Context, subscription *entities.Subscription) (int64, error)
+
+	// GetSubscriptionByExternalID gets a subscription by external ID
+	GetSubscriptionByExternalID(ctx context.Context, externalSubscriptionID string) (*entities.Subscription, error)
+
+	// UpdateSubscriptionPlan switches a subscription to another plan, effective from the next period
+	UpdateSubscriptionPlan(ctx context.Context, subscriptionID int64, planID int64) error
+
+	// UpdateSubscriptionStatus moves a subscription from expectedStatus to status, cancelledAt is only set for cancellations.
+	// A subscription no longer in expectedStatus is left as is and ErrSubscriptionStatusConflict is returned.
+	UpdateSubscriptionStatus(ctx context.Context, subscriptionID int64, expectedStatus entities.SubscriptionStatus, status entities.SubscriptionStatus, cancelledAt *time.Time) error
+
+	// SetSubscriptionCurrentBilling sets the billing of the current subscription period
+	SetSubscriptionCurrentBilling(ctx context.Context, subscriptionID int64, externalBillingID string) error
+}
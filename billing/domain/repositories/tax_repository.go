@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type TaxRepository interface {
+	// UpsertTaxRate creates or replaces the rate of a tax code in a jurisdiction and returns the internal tax rate ID
+	UpsertTaxRate(ctx context.Context, taxRate *entities.TaxRate) (int64, error)
+
+	// GetTaxRate gets the rate of a tax code in a jurisdiction
+	GetTaxRate(ctx context.Context, jurisdiction string, taxCode string) (*entities.TaxRate, error)
+
+	// ListTaxRates lists the rates of a jurisdiction ordered by tax code
+	ListTaxRates(ctx context.Context, jurisdiction string) ([]entities.TaxRate, error)
+}
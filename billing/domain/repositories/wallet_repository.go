@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type WalletRepository interface {
+	// ListWalletsByUserID lists the wallets of a user, ordered by currency
+	ListWalletsByUserID(ctx context.Context, userID string) ([]entities.Wallet, error)
+
+	// CreditWallet changes the balance of the wallet of the user in the currency of the transaction, creating the wallet on
+	// its first credit, and posts it to the ledger. ErrInsufficientWalletBalance is returned when the balance would go below
+	// zero. A transaction with the external reference of a recorded one is recorded once: transaction is set to the recorded one.
+	CreditWallet(ctx context.Context, transaction *entities.WalletTransaction) error
+
+	// ListWalletTransactions lists the transactions of the wallet of a user in a currency, oldest first
+	ListWalletTransactions(ctx context.Context, userID string, currency string) ([]entities.WalletTransaction, error)
+}
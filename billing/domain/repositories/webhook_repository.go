@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type WebhookRepository interface {
+	// CreateWebhook creates a new webhook and returns the internal webhook ID
+	CreateWebhook(ctx context.Context, webhook *entities.Webhook) (int64, error)
+
+	// GetWebhookByExternalID gets a webhook by ID
+	GetWebhookByExternalID(ctx context.Context, externalWebhookID string) (*entities.Webhook, error)
+
+	// ListWebhooksByEventType lists the webhooks subscribed to an event type
+	ListWebhooksByEventType(ctx context.Context, eventType entities.BillingEventType) ([]entities.Webhook, error)
+
+	// CreateWebhookDelivery creates a pending delivery of an event to a webhook and returns it.
+	// Creating the delivery of an event again returns the existing delivery.
+	CreateWebhookDelivery(ctx context.Context, delivery *entities.WebhookDelivery) (*entities.WebhookDelivery, error)
+
+	// GetWebhookDeliveryByExternalID gets a delivery with its attempts by ID
+	GetWebhookDeliveryByExternalID(ctx context.Context, externalDeliveryID string) (*entities.WebhookDelivery, error)
+
+	// ListWebhookDeliveries lists the deliveries of a webhook with their attempts, latest first
+	ListWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]entities.WebhookDelivery, error)
+
+	// RecordWebhookDeliveryAttempt adds an attempt to the delivery log and sets the delivery status
+	RecordWebhookDeliveryAttempt(ctx context.Context, deliveryID int64, attempt entities.WebhookDeliveryAttempt, status entities.WebhookDeliveryStatus) error
+
+	// SetWebhookDeliveryStatus sets the status of a delivery, e.g. back to pending when it is redelivered
+	SetWebhookDeliveryStatus(ctx context.Context, deliveryID int64, status entities.WebhookDeliveryStatus) error
+}
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"io"
+
+	"encore.app/billing/domain/entities"
+)
+
+type BankStatementParser = interface {
+	// Parse reads the booked transactions of a statement file in the format, a file that cannot be read fails with ErrInvalidBankStatement
+	Parse(r io.Reader, format entities.BankStatementFormat) (*entities.ParsedBankStatement, error)
+}
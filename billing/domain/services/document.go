@@ -0,0 +1,28 @@
+package services
+
+import (
+	"io"
+
+	"encore.app/billing/domain/entities"
+)
+
+type DocumentRenderer = interface {
+	RenderCreditNotePDF(creditNote *entities.CreditNote) ([]byte, error)
+
+	// RenderInvoice renders an invoice with a template of the format, or the default template when source is empty
+	RenderInvoice(invoice entities.Invoice, format entities.InvoiceFormat, source string) ([]byte, error)
+	ValidateInvoiceTemplate(format entities.InvoiceFormat, source string) error
+
+	// RenderUBL exports an invoice with its seller and buyer as a UBL 2.1 e-invoice following EN 16931 (Peppol BIS Billing 3.0)
+	RenderUBL(invoice entities.Invoice) ([]byte, error)
+
+	// NewExportWriter returns a writer encoding export rows to w in the format as they are written
+	NewExportWriter(w io.Writer, format entities.ExportFormat) (ExportWriter, error)
+}
+
+type ExportWriter = interface {
+	Write(row *entities.ExportRow) error
+
+	// Flush writes buffered rows to the underlying writer
+	Flush() error
+}
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+// EventPublisher publishes billing events from the outbox, delivery is at least once so consumers deduplicate on the event ID
+type EventPublisher = interface {
+	Publish(ctx context.Context, message *entities.OutboxMessage) error
+}
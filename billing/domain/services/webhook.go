@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type WebhookSender = interface {
+	// Send posts the signed payload of a delivery to its webhook, a request that got no response is an attempt with an error
+	Send(ctx context.Context, webhook *entities.Webhook, delivery *entities.WebhookDelivery) entities.WebhookDeliveryAttempt
+}
@@ -0,0 +1,116 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=POST path=/billing/:billingID/dunning
+func (s *Service) StartDunning(ctx context.Context, billingID string, req *StartDunningRequest) (*Dunning, error) {
+	fn := "billing.Service.StartDunning"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("scheduleDays", req.ScheduleDays)
+
+	dunning, err := s.startDunningUsecase.Execute(ctx, billingID, dto.StartDunningInput{
+		ScheduleDays: req.ScheduleDays,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidDunningSchedule) {
+			logger.Warn("dunning schedule is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "schedule_days must be increasing days between 1 and 365",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingNotClosed) {
+			logger.Warn("billing is not closed")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing is not closed",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingAlreadyPaid) {
+			logger.Warn("billing is already paid")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing is already paid",
+			}
+		}
+		if errors.Is(err, dto.ErrDunningAlreadyStarted) {
+			logger.Warn("dunning is already started")
+			return nil, &errs.Error{
+				Code:    errs.AlreadyExists,
+				Message: "dunning is already started",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to start dunning", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to start dunning",
+		}
+	}
+
+	logger.Info("Dunning started successfully")
+
+	response := dunningResponse(dunning)
+	return &response, nil
+}
+
+// encore:api private method=GET path=/billing/:billingID/dunning
+func (s *Service) GetDunning(ctx context.Context, billingID string) (*Dunning, error) {
+	fn := "billing.Service.GetDunning"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	dunning, err := s.getDunningUsecase.Execute(ctx, billingID)
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+		if errors.Is(err, dto.ErrDunningNotFound) {
+			logger.Warn("dunning not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "dunning not found",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to get dunning", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to get dunning",
+		}
+	}
+
+	response := dunningResponse(dunning)
+	return &response, nil
+}
+
+func dunningResponse(dunning *entities.Dunning) Dunning {
+	return Dunning{
+		BillingID:    dunning.ExternalBillingID,
+		Status:       dunning.Status,
+		Step:         dunning.Step,
+		ScheduleDays: dunning.ScheduleDays,
+		StartedAt:    dunning.StartedAt,
+		UpdatedAt:    dunning.UpdatedAt,
+	}
+}
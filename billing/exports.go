@@ -0,0 +1,102 @@
+package billing
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// exportContentTypes are the content types of export formats
+var exportContentTypes = map[entities.ExportFormat]string{
+	entities.ExportFormatCSV:    "text/csv; charset=utf-8",
+	entities.ExportFormatNDJSON: "application/x-ndjson",
+}
+
+// encore:api private raw method=GET path=/exports/line-items
+func (s *Service) ExportLineItems(w http.ResponseWriter, req *http.Request) {
+	fn := "billing.Service.ExportLineItems"
+	query := req.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = entities.ExportFormatCSV
+	}
+	logger := rlog.With("fn", fn).With("format", format).With("from", query.Get("from")).With("to", query.Get("to"))
+
+	// validate date range
+	from, errFrom := parseExportTime(query.Get("from"))
+	to, errTo := parseExportTime(query.Get("to"))
+	if errFrom != nil || errTo != nil {
+		logger.Warn("date range is invalid")
+		errs.HTTPError(w, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "from and to must be dates (2006-01-02) or RFC 3339 timestamps",
+		})
+		return
+	}
+
+	filter := entities.ExportFilter{
+		From:     from,
+		To:       to,
+		UserID:   query.Get("user_id"),
+		Currency: query.Get("currency"),
+		Status:   query.Get("status"),
+	}
+
+	// nothing can be reported as an error once rows are streamed, so the export is validated first
+	err := s.exportLineItemsUsecase.Validate(format, filter)
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidExportFormat) {
+			logger.Warn("export format is invalid")
+			errs.HTTPError(w, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "format must be csv or ndjson",
+			})
+			return
+		}
+
+		logger.Warn("export filter is invalid")
+		errs.HTTPError(w, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "from must be before to, and status open, closed or cancelled",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", `attachment; filename="line-items.`+format+`"`)
+	err = s.exportLineItemsUsecase.Execute(req.Context(), format, filter, &flushWriter{w: w})
+	if err != nil {
+		// the response is cut short, clients see an incomplete export
+		logger.Error("failed to export line items", "error", err)
+		return
+	}
+
+	logger.Info("Line items exported successfully")
+}
+
+// parseExportTime parses a date as midnight UTC, or an RFC 3339 timestamp
+func parseExportTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// flushWriter sends every write to the client, so that exports stream instead of building up in the response buffer
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if flusher, ok := f.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
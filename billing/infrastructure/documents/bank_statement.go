@@ -0,0 +1,304 @@
+package documents
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/services"
+)
+
+// statementCSVHeader are the columns of csv statements, amounts are signed with incoming transactions positive
+var statementCSVHeader = []string{"booking_date", "reference", "amount", "currency", "counterparty", "remittance_info"}
+
+type bankStatementParser struct{}
+
+func NewBankStatementParser() services.BankStatementParser {
+	return &bankStatementParser{}
+}
+
+func (p *bankStatementParser) Parse(r io.Reader, format entities.BankStatementFormat) (*entities.ParsedBankStatement, error) {
+	var statement *entities.ParsedBankStatement
+	var err error
+	switch format {
+	case entities.BankStatementFormatCamt053:
+		statement, err = parseCamt053(r)
+	case entities.BankStatementFormatCSV:
+		statement, err = parseStatementCSV(r)
+	default:
+		err = fmt.Errorf("unsupported bank statement format %q", format)
+	}
+	if err != nil {
+		return nil, errors.Join(entities.ErrInvalidBankStatement, err)
+	}
+
+	return statement, nil
+}
+
+// camt053Document is the part of an ISO 20022 camt.053 BankToCustomerStatement the import reads. Elements are matched by
+// their local name, so that every version of the message is read.
+type camt053Document struct {
+	Statements []camt053Statement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camt053Statement struct {
+	ID      string         `xml:"Id"`
+	IBAN    string         `xml:"Acct>Id>IBAN"`
+	OtherID string         `xml:"Acct>Id>Othr>Id"`
+	Entries []camt053Entry `xml:"Ntry"`
+}
+
+type camt053Entry struct {
+	Reference   string               `xml:"NtryRef"`
+	Amount      camt053Amount        `xml:"Amt"`
+	Direction   string               `xml:"CdtDbtInd"`
+	Status      camt053Status        `xml:"Sts"`
+	BookingDate camt053Date          `xml:"BookgDt"`
+	BankRef     string               `xml:"AcctSvcrRef"`
+	Details     []camt053Transaction `xml:"NtryDtls>TxDtls"`
+}
+
+type camt053Amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// camt053Status is the entry status, a code before version 8 and a Cd element from version 8
+type camt053Status struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+func (s camt053Status) code() string {
+	if s.Code != "" {
+		return strings.TrimSpace(s.Code)
+	}
+	return strings.TrimSpace(s.Value)
+}
+
+type camt053Date struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camt053Transaction struct {
+	BankRef         string         `xml:"Refs>AcctSvcrRef"`
+	EndToEndID      string         `xml:"Refs>EndToEndId"`
+	Amount          *camt053Amount `xml:"Amt"`
+	DetailAmount    *camt053Amount `xml:"AmtDtls>TxAmt>Amt"`
+	DebtorName      string         `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPartyName string         `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Unstructured    []string       `xml:"RmtInf>Ustrd"`
+	CreditorRefs    []string       `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+}
+
+func (t camt053Transaction) amount() *camt053Amount {
+	if t.Amount != nil {
+		return t.Amount
+	}
+	return t.DetailAmount
+}
+
+func (t camt053Transaction) remittanceInfo() string {
+	parts := append(append([]string{}, t.Unstructured...), t.CreditorRefs...)
+	if t.EndToEndID != "" && t.EndToEndID != "NOTPROVIDED" {
+		parts = append(parts, t.EndToEndID)
+	}
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+// parseCamt053 reads the booked entries of the first statement of a camt.053 file. An entry batching several transactions
+// with their own amounts is read as one transaction each.
+func parseCamt053(r io.Reader) (*entities.ParsedBankStatement, error) {
+	var document camt053Document
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to decode camt.053 document: %w", err)
+	}
+	if len(document.Statements) == 0 {
+		return nil, errors.New("camt.053 document has no statement")
+	}
+
+	statement := document.Statements[0]
+	parsed := &entities.ParsedBankStatement{
+		Reference: strings.TrimSpace(statement.ID),
+		Account:   strings.TrimSpace(statement.IBAN),
+	}
+	if parsed.Account == "" {
+		parsed.Account = strings.TrimSpace(statement.OtherID)
+	}
+
+	for i, entry := range statement.Entries {
+		if entry.Status.code() != "BOOK" {
+			continue
+		}
+
+		direction, err := camt053Direction(entry.Direction)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		bookingDate, err := parseStatementDate(entry.BookingDate.Date, entry.BookingDate.DateTime)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+
+		reference := firstNonEmpty(entry.BankRef, entry.Reference)
+		details := entry.Details
+		split := len(details) > 1
+		for _, detail := range details {
+			if detail.amount() == nil {
+				split = false
+			}
+		}
+
+		if !split {
+			transaction := camt053Transaction{}
+			if len(details) > 0 {
+				transaction = details[0]
+			}
+			amount, err := parseStatementAmount(entry.Amount.Value)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+
+			parsed.Entries = append(parsed.Entries, entities.BankStatementEntry{
+				BankReference:    firstNonEmpty(reference, transaction.BankRef, transaction.EndToEndID, fmt.Sprintf("%s-%d", parsed.Reference, i+1)),
+				BookingDate:      bookingDate,
+				Direction:        direction,
+				Amount:           amount,
+				Currency:         strings.TrimSpace(entry.Amount.Currency),
+				CounterpartyName: strings.TrimSpace(firstNonEmpty(transaction.DebtorName, transaction.DebtorPartyName)),
+				RemittanceInfo:   transaction.remittanceInfo(),
+			})
+			continue
+		}
+
+		for j, detail := range details {
+			amount, err := parseStatementAmount(detail.amount().Value)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d transaction %d: %w", i+1, j+1, err)
+			}
+
+			parsed.Entries = append(parsed.Entries, entities.BankStatementEntry{
+				BankReference:    firstNonEmpty(detail.BankRef, fmt.Sprintf("%s-%d", firstNonEmpty(reference, fmt.Sprintf("%s-%d", parsed.Reference, i+1)), j+1)),
+				BookingDate:      bookingDate,
+				Direction:        direction,
+				Amount:           amount,
+				Currency:         strings.TrimSpace(detail.amount().Currency),
+				CounterpartyName: strings.TrimSpace(firstNonEmpty(detail.DebtorName, detail.DebtorPartyName)),
+				RemittanceInfo:   detail.remittanceInfo(),
+			})
+		}
+	}
+
+	return parsed, nil
+}
+
+func camt053Direction(indicator string) (entities.BankTransactionDirection, error) {
+	switch strings.TrimSpace(indicator) {
+	case "CRDT":
+		return entities.BankTransactionDirectionCredit, nil
+	case "DBIT":
+		return entities.BankTransactionDirectionDebit, nil
+	}
+	return "", fmt.Errorf("unknown credit debit indicator %q", indicator)
+}
+
+// parseStatementCSV reads a csv statement with the statementCSVHeader columns, the statement has no reference or account
+func parseStatementCSV(r io.Reader) (*entities.ParsedBankStatement, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(statementCSVHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	for i, column := range statementCSVHeader {
+		if strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")) != column {
+			return nil, fmt.Errorf("csv column %d is %q, expected %q", i+1, header[i], column)
+		}
+	}
+
+	parsed := &entities.ParsedBankStatement{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv line %d: %w", line, err)
+		}
+
+		bookingDate, err := parseStatementDate(record[0], "")
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		reference := strings.TrimSpace(record[1])
+		if reference == "" {
+			return nil, fmt.Errorf("line %d: reference is required", line)
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil || amount == 0 {
+			return nil, fmt.Errorf("line %d: invalid amount %q", line, record[2])
+		}
+
+		direction := entities.BankTransactionDirectionCredit
+		if amount < 0 {
+			direction = entities.BankTransactionDirectionDebit
+			amount = -amount
+		}
+
+		parsed.Entries = append(parsed.Entries, entities.BankStatementEntry{
+			BankReference:    reference,
+			BookingDate:      bookingDate,
+			Direction:        direction,
+			Amount:           amount,
+			Currency:         strings.ToUpper(strings.TrimSpace(record[3])),
+			CounterpartyName: strings.TrimSpace(record[4]),
+			RemittanceInfo:   strings.Join(strings.Fields(record[5]), " "),
+		})
+	}
+
+	return parsed, nil
+}
+
+func parseStatementAmount(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+// parseStatementDate reads a booking date as an ISO date, or as an ISO date time when there is no date
+func parseStatementDate(date string, dateTime string) (time.Time, error) {
+	if date = strings.TrimSpace(date); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid booking date %q", date)
+		}
+		return parsed, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(dateTime))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid booking date time %q", dateTime)
+	}
+	y, m, d := parsed.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
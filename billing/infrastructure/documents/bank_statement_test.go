@@ -0,0 +1,91 @@
+package documents
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+func TestBankStatementParser_ParseCamt053(t *testing.T) {
+	file, err := os.Open("testdata/camt053.xml")
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	statement, err := NewBankStatementParser().Parse(file, entities.BankStatementFormatCamt053)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if statement.Reference != "STMT-2026-03-01" {
+		t.Errorf("Parse() reference = %v, expected %v", statement.Reference, "STMT-2026-03-01")
+	}
+	if statement.Account != "DE89370400440532013000" {
+		t.Errorf("Parse() account = %v, expected %v", statement.Account, "DE89370400440532013000")
+	}
+
+	bookedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	expected := []entities.BankStatementEntry{
+		{BankReference: "BANKREF-0001", BookingDate: bookedAt, Direction: entities.BankTransactionDirectionCredit, Amount: 125.5, Currency: "EUR", CounterpartyName: "Acme GmbH", RemittanceInfo: "Invoice INV-2026-000001 March seats"},
+		{BankReference: "BANKREF-0002", BookingDate: bookedAt, Direction: entities.BankTransactionDirectionCredit, Amount: 50, Currency: "EUR", CounterpartyName: "Jane Doe", RemittanceInfo: "RF18INV2026000002 E2E-USER-42"},
+		{BankReference: "BANKREF-0003", BookingDate: bookedAt, Direction: entities.BankTransactionDirectionDebit, Amount: 2.5, Currency: "EUR"},
+		{BankReference: "BANKREF-0005-A", BookingDate: bookedAt, Direction: entities.BankTransactionDirectionCredit, Amount: 100, Currency: "EUR", CounterpartyName: "Beta Ltd", RemittanceInfo: "INV-2026-000003"},
+		{BankReference: "BANKREF-0005-2", BookingDate: bookedAt, Direction: entities.BankTransactionDirectionCredit, Amount: 200, Currency: "EUR", CounterpartyName: "Gamma SA", RemittanceInfo: "INV-2026-000004"},
+	}
+	if !reflect.DeepEqual(statement.Entries, expected) {
+		t.Errorf("Parse() entries = %+v, expected %+v", statement.Entries, expected)
+	}
+}
+
+func TestBankStatementParser_ParseCSV(t *testing.T) {
+	file, err := os.Open("testdata/statement.csv")
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	statement, err := NewBankStatementParser().Parse(file, entities.BankStatementFormatCSV)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	expected := []entities.BankStatementEntry{
+		{BankReference: "TRX-1001", BookingDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Direction: entities.BankTransactionDirectionCredit, Amount: 125.5, Currency: "EUR", CounterpartyName: "Acme GmbH", RemittanceInfo: "Invoice INV-2026-000001"},
+		{BankReference: "TRX-1002", BookingDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Direction: entities.BankTransactionDirectionCredit, Amount: 50, Currency: "EUR", CounterpartyName: "Doe, Jane", RemittanceInfo: "user-42 march"},
+		{BankReference: "TRX-1003", BookingDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Direction: entities.BankTransactionDirectionDebit, Amount: 2.5, Currency: "EUR", CounterpartyName: "Bank", RemittanceInfo: "Account fee"},
+	}
+	if !reflect.DeepEqual(statement.Entries, expected) {
+		t.Errorf("Parse() entries = %+v, expected %+v", statement.Entries, expected)
+	}
+}
+
+func TestBankStatementParser_ParseInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  entities.BankStatementFormat
+		content string
+	}{
+		{name: "not xml", format: entities.BankStatementFormatCamt053, content: "booking_date,reference"},
+		{name: "no statement", format: entities.BankStatementFormatCamt053, content: `<Document><BkToCstmrStmt></BkToCstmrStmt></Document>`},
+		{name: "unknown indicator", format: entities.BankStatementFormatCamt053, content: `<Document><BkToCstmrStmt><Stmt><Ntry><Amt Ccy="EUR">1.00</Amt><CdtDbtInd>X</CdtDbtInd><Sts>BOOK</Sts><BookgDt><Dt>2026-03-01</Dt></BookgDt></Ntry></Stmt></BkToCstmrStmt></Document>`},
+		{name: "wrong csv header", format: entities.BankStatementFormatCSV, content: "date,reference,amount,currency,counterparty,remittance_info\n"},
+		{name: "invalid csv amount", format: entities.BankStatementFormatCSV, content: "booking_date,reference,amount,currency,counterparty,remittance_info\n2026-03-01,TRX-1,abc,EUR,Acme,\n"},
+		{name: "missing csv reference", format: entities.BankStatementFormatCSV, content: "booking_date,reference,amount,currency,counterparty,remittance_info\n2026-03-01,,10,EUR,Acme,\n"},
+		{name: "unknown format", format: "mt940", content: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBankStatementParser().Parse(strings.NewReader(tt.content), tt.format)
+			if !errors.Is(err, entities.ErrInvalidBankStatement) {
+				t.Errorf("Parse() error = %v, expected %v", err, entities.ErrInvalidBankStatement)
+			}
+		})
+	}
+}
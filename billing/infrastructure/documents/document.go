@@ -0,0 +1,43 @@
+package documents
+
+import (
+	"fmt"
+	"strings"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/services"
+)
+
+type documentRenderer struct{}
+
+func NewDocumentRenderer() services.DocumentRenderer {
+	return &documentRenderer{}
+}
+
+func (r *documentRenderer) RenderCreditNotePDF(creditNote *entities.CreditNote) ([]byte, error) {
+	lines := []pdfLine{
+		{text: "Credit Note " + creditNote.Number, bold: true},
+		{},
+		{text: "Date: " + creditNote.CreatedAt.UTC().Format("2006-01-02")},
+		{text: "Billing: " + creditNote.ExternalBillingID},
+		{text: "Reason: " + creditNote.Reason},
+		{},
+		{text: "Items", bold: true},
+	}
+	for _, lineItem := range creditNote.LineItems {
+		lines = append(lines, pdfLine{
+			text: fmt.Sprintf("%s\t\t%s", lineItem.Description, formatAmountMinor(lineItem.AmountMinor, creditNote.CurrencyPrecision, creditNote.Currency)),
+		})
+	}
+	lines = append(lines,
+		pdfLine{},
+		pdfLine{text: "Total credited: " + formatAmountMinor(creditNote.TotalAmountMinor, creditNote.CurrencyPrecision, creditNote.Currency), bold: true},
+	)
+
+	return writePDF(lines), nil
+}
+
+// formatAmountMinor formats an amount in minor units with the precision of its currency, e.g. 1234 with precision 2 as 12.34 USD
+func formatAmountMinor(amountMinor int64, precision int64, currency string) string {
+	return strings.TrimSpace(entities.FormatMinorUnits(amountMinor, precision) + " " + currency)
+}
@@ -0,0 +1,201 @@
+package documents
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+func TestDocumentRenderer_RenderCreditNotePDF(t *testing.T) {
+	renderer := NewDocumentRenderer()
+
+	pdf, err := renderer.RenderCreditNotePDF(&entities.CreditNote{
+		Number:            "CN-000042",
+		ExternalBillingID: "0193f1c2-0000-7000-8000-000000000000",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		Reason:            "Refund (partial)",
+		LineItems:         []entities.CreditNoteLineItem{{Description: "Seat × 2", AmountMinor: 1250}},
+		TotalAmountMinor:  1250,
+		CreatedAt:         time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("RenderCreditNotePDF failed: %v", err)
+	}
+
+	for _, expected := range []string{"%PDF-1.4", "(Credit Note CN-000042)", "(Reason: Refund \\(partial\\))", "Seat \\327 2", "12.50 USD", "%%EOF"} {
+		if !bytes.Contains(pdf, []byte(expected)) {
+			t.Errorf("RenderCreditNotePDF() does not contain %q", expected)
+		}
+	}
+}
+
+func TestFormatAmountMinor(t *testing.T) {
+	tests := []struct {
+		amountMinor int64
+		precision   int64
+		currency    string
+		expected    string
+	}{
+		{amountMinor: 1234, precision: 2, currency: "USD", expected: "12.34 USD"},
+		{amountMinor: 5, precision: 2, currency: "USD", expected: "0.05 USD"},
+		{amountMinor: -1050, precision: 2, currency: "GEL", expected: "-10.50 GEL"},
+		{amountMinor: 1234, precision: 0, currency: "JPY", expected: "1234 JPY"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAmountMinor(tt.amountMinor, tt.precision, tt.currency); got != tt.expected {
+			t.Errorf("formatAmountMinor(%v, %v, %v) = %v, expected %v", tt.amountMinor, tt.precision, tt.currency, got, tt.expected)
+		}
+	}
+}
+
+func testInvoice(currency entities.CurrencyMetadata) entities.Invoice {
+	periodStart := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	lineItems := []entities.LineItem{
+		{LineItemID: "a", Description: "Seats <team>", AmountMinor: 10000, TaxCode: "standard", TaxAmountMinor: 2000},
+		{LineItemID: "b", Kind: entities.LineItemKindAdjustment, Description: "Seat refund", AmountMinor: -1050, Reason: "downgrade", ReferenceLineItemID: "a"},
+	}
+
+	return entities.Invoice{
+		InvoiceNumber: "INV-2026-000001",
+		IssuedAt:      time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC),
+		UserID:        "user-1",
+		Currency:      currency,
+		Summary: entities.BillingSummary{
+			ExternalBillingID:     "0193f1c2-0000-7000-8000-000000000000",
+			Currency:              currency.Code,
+			CurrencyPrecision:     currency.Precision,
+			LineItems:             lineItems,
+			PeriodStart:           &periodStart,
+			PeriodEnd:             &periodEnd,
+			SubtotalAmountMinor:   8950,
+			TaxAmountMinor:        1790,
+			GrandTotalAmountMinor: 10740,
+			Taxes:                 []entities.TaxBreakdown{{RatePPM: 200000, TaxableAmountMinor: 8950, TaxAmountMinor: 1790}},
+		},
+		LineItems: entities.GroupLineItems(lineItems),
+	}
+}
+
+func TestDocumentRenderer_RenderInvoice(t *testing.T) {
+	renderer := NewDocumentRenderer()
+
+	tests := []struct {
+		name           string
+		currency       entities.CurrencyMetadata
+		format         entities.InvoiceFormat
+		source         string
+		paidFromCredit int64
+		expected       []string
+	}{
+		{
+			name:     "default html template",
+			currency: entities.CurrencyMetadata{Code: "USD", Symbol: "$", Precision: 2},
+			format:   entities.InvoiceFormatHTML,
+			expected: []string{"Invoice INV-2026-000001", "Seats &lt;team&gt;", "Seat refund (downgrade)", "-$10.50", "Tax 20%", "$107.40", "2026-01-01"},
+		},
+		{
+			name:     "default pdf template",
+			currency: entities.CurrencyMetadata{Code: "EUR", Symbol: "€", Precision: 2},
+			format:   entities.InvoiceFormatPDF,
+			expected: []string{"%PDF-1.4", "(Invoice INV-2026-000001)", "(Seats <team>)", "(\\200107.40)", "%%EOF"},
+		},
+		{
+			name:     "pdf falls back to the currency code",
+			currency: entities.CurrencyMetadata{Code: "GEL", Symbol: "₾", Precision: 2},
+			format:   entities.InvoiceFormatPDF,
+			expected: []string{"(GEL 107.40)", "(-GEL 10.50)"},
+		},
+		{
+			name:     "tenant template",
+			currency: entities.CurrencyMetadata{Code: "JPY", Symbol: "¥", Precision: 0},
+			format:   entities.InvoiceFormatHTML,
+			source:   `<p>{{.InvoiceNumber}} for {{.UserID}}: {{money .Summary.GrandTotalAmountMinor}}</p>`,
+			expected: []string{"<p>INV-2026-000001 for user-1: ¥10740</p>"},
+		},
+		{
+			name:           "paid from credit",
+			currency:       entities.CurrencyMetadata{Code: "USD", Symbol: "$", Precision: 2},
+			format:         entities.InvoiceFormatHTML,
+			paidFromCredit: 4000,
+			expected:       []string{"Paid from credit", "-$40.00", "Amount due", "$67.40"},
+		},
+		{
+			name:           "pdf paid from credit",
+			currency:       entities.CurrencyMetadata{Code: "USD", Symbol: "$", Precision: 2},
+			format:         entities.InvoiceFormatPDF,
+			paidFromCredit: 4000,
+			expected:       []string{"(Paid from credit)", "(-$40.00)", "(Amount due)", "($67.40)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := testInvoice(tt.currency)
+			invoice.Summary.PaidFromCreditAmountMinor = tt.paidFromCredit
+
+			document, err := renderer.RenderInvoice(invoice, tt.format, tt.source)
+			if err != nil {
+				t.Fatalf("RenderInvoice failed: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !bytes.Contains(document, []byte(expected)) {
+					t.Errorf("RenderInvoice() does not contain %q", expected)
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentRenderer_ValidateInvoiceTemplate(t *testing.T) {
+	renderer := NewDocumentRenderer()
+
+	tests := []struct {
+		name     string
+		format   entities.InvoiceFormat
+		source   string
+		expected bool
+	}{
+		{name: "valid html", format: entities.InvoiceFormatHTML, source: "<h1>{{.InvoiceNumber}}</h1>", expected: true},
+		{name: "valid pdf", format: entities.InvoiceFormatPDF, source: "# {{.InvoiceNumber}}\n{{money .Summary.GrandTotalAmountMinor}}", expected: true},
+		{name: "empty", format: entities.InvoiceFormatHTML, source: " ", expected: false},
+		{name: "syntax error", format: entities.InvoiceFormatHTML, source: "{{.InvoiceNumber", expected: false},
+		{name: "unknown function", format: entities.InvoiceFormatPDF, source: "{{shout .InvoiceNumber}}", expected: false},
+		{name: "unknown format", format: "docx", source: "{{.InvoiceNumber}}", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := renderer.ValidateInvoiceTemplate(tt.format, tt.source)
+			if (err == nil) != tt.expected {
+				t.Errorf("ValidateInvoiceTemplate() = %v, expected valid %v", err, tt.expected)
+			}
+			if err != nil && !errors.Is(err, entities.ErrInvalidInvoiceTemplate) {
+				t.Errorf("ValidateInvoiceTemplate() = %v, expected %v", err, entities.ErrInvalidInvoiceTemplate)
+			}
+		})
+	}
+}
+
+func TestFormatTaxRate(t *testing.T) {
+	tests := []struct {
+		ratePPM  int64
+		expected string
+	}{
+		{ratePPM: 200000, expected: "20%"},
+		{ratePPM: 88750, expected: "8.875%"},
+		{ratePPM: 0, expected: "0%"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTaxRate(tt.ratePPM); got != tt.expected {
+			t.Errorf("formatTaxRate(%v) = %v, expected %v", tt.ratePPM, got, tt.expected)
+		}
+	}
+}
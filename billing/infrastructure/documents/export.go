@@ -0,0 +1,110 @@
+package documents
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/services"
+)
+
+// exportCSVHeader are the columns of csv exports, in the order of csvExportRecord
+var exportCSVHeader = []string{
+	"billing_id", "user_id", "user_group", "billing_description", "currency", "currency_precision", "status", "invoice_number",
+	"billing_created_at", "billing_closed_at", "line_item_id", "kind", "description", "amount_minor", "amount", "tax_code", "reason",
+	"reference_line_item_id", "line_item_created_at",
+}
+
+func (r *documentRenderer) NewExportWriter(w io.Writer, format entities.ExportFormat) (services.ExportWriter, error) {
+	switch format {
+	case entities.ExportFormatCSV:
+		return &csvExportWriter{writer: csv.NewWriter(w)}, nil
+	case entities.ExportFormatNDJSON:
+		buffered := bufio.NewWriter(w)
+		return &ndjsonExportWriter{buffer: buffered, encoder: json.NewEncoder(buffered)}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+type csvExportWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvExportWriter) Write(row *entities.ExportRow) error {
+	if !w.headerWritten {
+		if err := w.writer.Write(exportCSVHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	return w.writer.Write(csvExportRecord(row))
+}
+
+// Flush writes the header of exports without rows too, so that every csv export has its columns
+func (w *csvExportWriter) Flush() error {
+	if !w.headerWritten {
+		if err := w.writer.Write(exportCSVHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func csvExportRecord(row *entities.ExportRow) []string {
+	closedAt := ""
+	if row.BillingClosedAt != nil {
+		closedAt = row.BillingClosedAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		row.BillingID,
+		row.UserID,
+		row.UserGroup,
+		row.BillingDescription,
+		row.Currency,
+		strconv.FormatInt(row.CurrencyPrecision, 10),
+		row.Status,
+		row.InvoiceNumber,
+		row.BillingCreatedAt.UTC().Format(time.RFC3339),
+		closedAt,
+		row.LineItemID,
+		row.Kind,
+		row.Description,
+		strconv.FormatInt(row.AmountMinor, 10),
+		entities.FormatMinorUnits(row.AmountMinor, row.CurrencyPrecision),
+		row.TaxCode,
+		row.Reason,
+		row.ReferenceLineItemID,
+		row.LineItemCreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+type ndjsonExportWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+// ndjsonExportRow adds the decimal amount to the row, as in csv exports
+type ndjsonExportRow struct {
+	*entities.ExportRow
+	Amount string `json:"amount"`
+}
+
+func (w *ndjsonExportWriter) Write(row *entities.ExportRow) error {
+	return w.encoder.Encode(ndjsonExportRow{ExportRow: row, Amount: entities.FormatMinorUnits(row.AmountMinor, row.CurrencyPrecision)})
+}
+
+func (w *ndjsonExportWriter) Flush() error {
+	return w.buffer.Flush()
+}
@@ -0,0 +1,131 @@
+package documents
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+func testExportRows() []entities.ExportRow {
+	closedAt := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	billing := entities.ExportRow{
+		BillingID:          "0193f1c2-0000-7000-8000-000000000000",
+		UserID:             "user-1",
+		BillingDescription: "Team plan, January",
+		Currency:           "USD",
+		CurrencyPrecision:  2,
+		Status:             entities.BillingStatusClosed,
+		InvoiceNumber:      "INV-2026-000001",
+		BillingCreatedAt:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		BillingClosedAt:    &closedAt,
+	}
+
+	charge := billing
+	charge.LineItemID = "0193f1c4-0000-7000-8000-000000000000"
+	charge.Kind = entities.LineItemKindCharge
+	charge.Description = `Seats "team"`
+	charge.AmountMinor = 10000
+	charge.LineItemCreatedAt = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	adjustment := billing
+	adjustment.LineItemID = "0193f1c5-0000-7000-8000-000000000000"
+	adjustment.Kind = entities.LineItemKindAdjustment
+	adjustment.Description = "Seat refund"
+	adjustment.AmountMinor = -1050
+	adjustment.Reason = "downgrade"
+	adjustment.ReferenceLineItemID = charge.LineItemID
+	adjustment.LineItemCreatedAt = time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	return []entities.ExportRow{charge, adjustment}
+}
+
+func TestDocumentRenderer_NewExportWriter_CSV(t *testing.T) {
+	renderer := NewDocumentRenderer()
+
+	var buf bytes.Buffer
+	writer, err := renderer.NewExportWriter(&buf, entities.ExportFormatCSV)
+	if err != nil {
+		t.Fatalf("NewExportWriter failed: %v", err)
+	}
+	for _, row := range testExportRows() {
+		if err := writer.Write(&row); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	expected := `billing_id,user_id,user_group,billing_description,currency,currency_precision,status,invoice_number,billing_created_at,billing_closed_at,line_item_id,kind,description,amount_minor,amount,tax_code,reason,reference_line_item_id,line_item_created_at
+0193f1c2-0000-7000-8000-000000000000,user-1,,"Team plan, January",USD,2,closed,INV-2026-000001,2026-01-01T00:00:00Z,2026-02-01T00:00:00Z,0193f1c4-0000-7000-8000-000000000000,charge,"Seats ""team""",10000,100.00,,,,2026-01-02T00:00:00Z
+0193f1c2-0000-7000-8000-000000000000,user-1,,"Team plan, January",USD,2,closed,INV-2026-000001,2026-01-01T00:00:00Z,2026-02-01T00:00:00Z,0193f1c5-0000-7000-8000-000000000000,adjustment,Seat refund,-1050,-10.50,,downgrade,0193f1c4-0000-7000-8000-000000000000,2026-01-03T00:00:00Z
+`
+	if buf.String() != expected {
+		t.Errorf("CSV export = \n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
+
+func TestDocumentRenderer_NewExportWriter_CSVWithoutRows(t *testing.T) {
+	renderer := NewDocumentRenderer()
+
+	var buf bytes.Buffer
+	writer, err := renderer.NewExportWriter(&buf, entities.ExportFormatCSV)
+	if err != nil {
+		t.Fatalf("NewExportWriter failed: %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if buf.String() != strings.Join(exportCSVHeader, ",")+"\n" {
+		t.Errorf("CSV export = %q, expected the header only", buf.String())
+	}
+}
+
+func TestDocumentRenderer_NewExportWriter_NDJSON(t *testing.T) {
+	renderer := NewDocumentRenderer()
+
+	var buf bytes.Buffer
+	writer, err := renderer.NewExportWriter(&buf, entities.ExportFormatNDJSON)
+	if err != nil {
+		t.Fatalf("NewExportWriter failed: %v", err)
+	}
+	rows := testExportRows()
+	for _, row := range rows {
+		if err := writer.Write(&row); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(rows) {
+		t.Fatalf("NDJSON export has %d lines, expected %d", len(lines), len(rows))
+	}
+	for i, line := range lines {
+		var decoded map[string]any
+		if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i, err)
+		}
+		if decoded["line_item_id"] != rows[i].LineItemID || decoded["billing_id"] != rows[i].BillingID {
+			t.Errorf("line %d = %s, expected line item %s", i, line, rows[i].LineItemID)
+		}
+	}
+	if !strings.Contains(lines[1], `"amount":"-10.50"`) {
+		t.Errorf("line 1 = %s, expected amount -10.50", lines[1])
+	}
+}
+
+func TestDocumentRenderer_NewExportWriter_UnknownFormat(t *testing.T) {
+	renderer := NewDocumentRenderer()
+
+	if _, err := renderer.NewExportWriter(&bytes.Buffer{}, "xlsx"); err == nil {
+		t.Errorf("NewExportWriter() = nil, expected an error")
+	}
+}
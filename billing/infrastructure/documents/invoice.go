@@ -0,0 +1,131 @@
+package documents
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// invoiceTemplateSources are the default invoice templates per format
+var invoiceTemplateSources = map[entities.InvoiceFormat]string{
+	entities.InvoiceFormatHTML: mustReadTemplate("templates/invoice.html.tmpl"),
+	entities.InvoiceFormatPDF:  mustReadTemplate("templates/invoice.pdf.tmpl"),
+}
+
+func mustReadTemplate(name string) string {
+	source, err := templates.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return string(source)
+}
+
+// invoiceTemplate is a parsed html or text template
+type invoiceTemplate interface {
+	Execute(w io.Writer, data any) error
+}
+
+func (r *documentRenderer) RenderInvoice(invoice entities.Invoice, format entities.InvoiceFormat, source string) ([]byte, error) {
+	if source == "" {
+		source = invoiceTemplateSources[format]
+	}
+
+	tmpl, err := parseInvoiceTemplate(format, source, invoiceTemplateFuncs(invoice.Currency, format))
+	if err != nil {
+		return nil, err
+	}
+
+	// templates get a pointer, so that they can call the methods of the summary
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, &invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == entities.InvoiceFormatPDF {
+		return writePDF(pdfLines(buf.String())), nil
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *documentRenderer) ValidateInvoiceTemplate(format entities.InvoiceFormat, source string) error {
+	if strings.TrimSpace(source) == "" {
+		return entities.ErrInvalidInvoiceTemplate
+	}
+
+	_, err := parseInvoiceTemplate(format, source, invoiceTemplateFuncs(entities.CurrencyMetadata{}, format))
+	return err
+}
+
+func parseInvoiceTemplate(format entities.InvoiceFormat, source string, funcs map[string]any) (invoiceTemplate, error) {
+	switch format {
+	case entities.InvoiceFormatHTML:
+		tmpl, err := htmltemplate.New("invoice").Funcs(funcs).Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", entities.ErrInvalidInvoiceTemplate, err)
+		}
+		return tmpl, nil
+	case entities.InvoiceFormatPDF:
+		tmpl, err := texttemplate.New("invoice").Funcs(funcs).Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", entities.ErrInvalidInvoiceTemplate, err)
+		}
+		return tmpl, nil
+	}
+
+	return nil, entities.ErrInvalidInvoiceTemplate
+}
+
+// invoiceTemplateFuncs are the functions available to invoice templates
+func invoiceTemplateFuncs(currency entities.CurrencyMetadata, format entities.InvoiceFormat) map[string]any {
+	// the standard pdf fonts cannot draw every currency symbol, those amounts are written with the currency code
+	if format == entities.InvoiceFormatPDF && !pdfCanEncode(currency.Symbol) {
+		currency.Symbol = ""
+	}
+
+	return map[string]any{
+		"money": currency.FormatAmountMinor,
+		"date": func(t time.Time) string {
+			return t.UTC().Format("2006-01-02")
+		},
+		"taxRate": formatTaxRate,
+		"negate": func(amountMinor int64) int64 {
+			return -amountMinor
+		},
+	}
+}
+
+// formatTaxRate formats a rate in parts per million as a percentage, e.g. 88750 as 8.875%
+func formatTaxRate(ratePPM int64) string {
+	return formatPercent(ratePPM) + "%"
+}
+
+// formatPercent formats a rate in parts per million as a percent number without trailing zeros, e.g. 88750 as 8.875
+func formatPercent(ratePPM int64) string {
+	percent := entities.FormatMinorUnits(ratePPM, 4)
+	percent = strings.TrimRight(percent, "0")
+	return strings.TrimSuffix(percent, ".")
+}
+
+// pdfLines turns the output of a pdf template into lines, lines starting with "# " are bold
+func pdfLines(output string) []pdfLine {
+	lines := []pdfLine{}
+	for _, text := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if strings.HasPrefix(text, "# ") {
+			lines = append(lines, pdfLine{text: strings.TrimPrefix(text, "# "), bold: true})
+			continue
+		}
+		lines = append(lines, pdfLine{text: text})
+	}
+	return lines
+}
@@ -0,0 +1,133 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pdfPageWidth    = 595 // A4 in points
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 10
+	pdfLineHeight   = 14
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+)
+
+// pdfColumns are the x positions of the tab separated columns of a line
+var pdfColumns = []int{pdfMargin, 330, 440}
+
+// pdfLine is a line of text, bold lines use Helvetica-Bold and tabs move to the next column
+type pdfLine struct {
+	text string
+	bold bool
+}
+
+// writePDF lays out lines of text on A4 pages with the standard Helvetica fonts, which every PDF reader
+// provides, so the document needs no embedded font
+func writePDF(lines []pdfLine) []byte {
+	pages := [][]pdfLine{}
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	// objects: 1 catalog, 2 page tree, 3 and 4 fonts, then a page and its content stream per page
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	kids := []string{}
+	for _, page := range pages {
+		pageObject := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObject))
+
+		content := pdfPageContent(page)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, pageObject+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+func pdfPageContent(lines []pdfLine) string {
+	var content strings.Builder
+	content.WriteString("BT\n")
+	for i, line := range lines {
+		font := "F1"
+		if line.bold {
+			font = "F2"
+		}
+		fmt.Fprintf(&content, "/%s %d Tf\n", font, pdfFontSize)
+
+		y := pdfPageHeight - pdfMargin - (i+1)*pdfLineHeight
+		for column, text := range strings.Split(line.text, "\t") {
+			if column >= len(pdfColumns) || text == "" {
+				continue
+			}
+			fmt.Fprintf(&content, "1 0 0 1 %d %d Tm\n(%s) Tj\n", pdfColumns[column], y, pdfEscape(text))
+		}
+	}
+	content.WriteString("ET")
+	return content.String()
+}
+
+// pdfWinAnsi are the characters of WinAnsiEncoding outside Latin-1
+var pdfWinAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// pdfCanEncode reports whether the standard fonts can show every character of text
+func pdfCanEncode(text string) bool {
+	for _, r := range text {
+		if _, ok := pdfWinAnsi[r]; !ok && (r < 0x20 || r > 0xff || (r >= 0x7f && r < 0xa0)) {
+			return false
+		}
+	}
+	return true
+}
+
+// pdfEscape escapes a string literal, characters that cannot be shown by the standard fonts are replaced
+func pdfEscape(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		if b, ok := pdfWinAnsi[r]; ok {
+			fmt.Fprintf(&escaped, "\\%03o", b)
+			continue
+		}
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			escaped.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		default:
+			escaped.WriteByte('?')
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,341 @@
+package documents
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+const (
+	ublInvoiceNamespace = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	ublCacNamespace     = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	ublCbcNamespace     = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+
+	ublCustomizationID = "urn:cen.eu:en16931:2017#compliant#urn:fdc:peppol.eu:2017:poacc:billing:3.0"
+	ublProfileID       = "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0"
+	ublInvoiceTypeCode = "380" // commercial invoice
+	ublUnitCode        = "C62" // one
+	ublDiscountCode    = "95"  // allowance reason code of discounts
+	ublVATScheme       = "VAT"
+	ublPaymentTerms    = "Payment due on receipt"
+
+	// EN 16931 amounts have at most 2 decimals
+	ublMaxPrecision = 2
+)
+
+// VAT categories of EN 16931: taxed lines are standard rated, lines without tax are zero rated
+const (
+	ublTaxCategoryStandard = "S"
+	ublTaxCategoryZero     = "Z"
+)
+
+// The ubl types below declare the elements in the order of the UBL 2.1 schema, which validators enforce
+
+type ublInvoice struct {
+	XMLName                 xml.Name         `xml:"Invoice"`
+	Xmlns                   string           `xml:"xmlns,attr"`
+	XmlnsCac                string           `xml:"xmlns:cac,attr"`
+	XmlnsCbc                string           `xml:"xmlns:cbc,attr"`
+	CustomizationID         string           `xml:"cbc:CustomizationID"`
+	ProfileID               string           `xml:"cbc:ProfileID"`
+	ID                      string           `xml:"cbc:ID"`
+	IssueDate               string           `xml:"cbc:IssueDate"`
+	InvoiceTypeCode         string           `xml:"cbc:InvoiceTypeCode"`
+	Note                    string           `xml:"cbc:Note,omitempty"`
+	DocumentCurrencyCode    string           `xml:"cbc:DocumentCurrencyCode"`
+	BuyerReference          string           `xml:"cbc:BuyerReference"`
+	InvoicePeriod           *ublPeriod       `xml:"cac:InvoicePeriod"`
+	AccountingSupplierParty ublPartyRole     `xml:"cac:AccountingSupplierParty"`
+	AccountingCustomerParty ublPartyRole     `xml:"cac:AccountingCustomerParty"`
+	PaymentTerms            ublPaymentTerm   `xml:"cac:PaymentTerms"`
+	TaxTotal                ublTaxTotal      `xml:"cac:TaxTotal"`
+	LegalMonetaryTotal      ublMonetaryTotal `xml:"cac:LegalMonetaryTotal"`
+	InvoiceLines            []ublInvoiceLine `xml:"cac:InvoiceLine"`
+}
+
+type ublAmount struct {
+	CurrencyID string `xml:"currencyID,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type ublQuantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ublPeriod struct {
+	StartDate string `xml:"cbc:StartDate"`
+	EndDate   string `xml:"cbc:EndDate"`
+}
+
+type ublPartyRole struct {
+	Party ublParty `xml:"cac:Party"`
+}
+
+type ublParty struct {
+	EndpointID       ublEndpointID      `xml:"cbc:EndpointID"`
+	PartyName        ublPartyName       `xml:"cac:PartyName"`
+	PostalAddress    ublAddress         `xml:"cac:PostalAddress"`
+	PartyTaxScheme   *ublPartyTaxScheme `xml:"cac:PartyTaxScheme"`
+	PartyLegalEntity ublLegalEntity     `xml:"cac:PartyLegalEntity"`
+}
+
+type ublEndpointID struct {
+	SchemeID string `xml:"schemeID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ublPartyName struct {
+	Name string `xml:"cbc:Name"`
+}
+
+type ublAddress struct {
+	StreetName string     `xml:"cbc:StreetName,omitempty"`
+	CityName   string     `xml:"cbc:CityName,omitempty"`
+	PostalZone string     `xml:"cbc:PostalZone,omitempty"`
+	Country    ublCountry `xml:"cac:Country"`
+}
+
+type ublCountry struct {
+	IdentificationCode string `xml:"cbc:IdentificationCode"`
+}
+
+type ublPartyTaxScheme struct {
+	CompanyID string       `xml:"cbc:CompanyID"`
+	TaxScheme ublTaxScheme `xml:"cac:TaxScheme"`
+}
+
+type ublTaxScheme struct {
+	ID string `xml:"cbc:ID"`
+}
+
+type ublLegalEntity struct {
+	RegistrationName string `xml:"cbc:RegistrationName"`
+}
+
+type ublPaymentTerm struct {
+	Note string `xml:"cbc:Note"`
+}
+
+type ublTaxTotal struct {
+	TaxAmount    ublAmount        `xml:"cbc:TaxAmount"`
+	TaxSubtotals []ublTaxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type ublTaxSubtotal struct {
+	TaxableAmount ublAmount      `xml:"cbc:TaxableAmount"`
+	TaxAmount     ublAmount      `xml:"cbc:TaxAmount"`
+	TaxCategory   ublTaxCategory `xml:"cac:TaxCategory"`
+}
+
+type ublTaxCategory struct {
+	ID        string       `xml:"cbc:ID"`
+	Percent   string       `xml:"cbc:Percent"`
+	TaxScheme ublTaxScheme `xml:"cac:TaxScheme"`
+}
+
+type ublMonetaryTotal struct {
+	LineExtensionAmount ublAmount  `xml:"cbc:LineExtensionAmount"`
+	TaxExclusiveAmount  ublAmount  `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusiveAmount  ublAmount  `xml:"cbc:TaxInclusiveAmount"`
+	PrepaidAmount       *ublAmount `xml:"cbc:PrepaidAmount,omitempty"`
+	PayableAmount       ublAmount  `xml:"cbc:PayableAmount"`
+}
+
+type ublInvoiceLine struct {
+	ID                  string               `xml:"cbc:ID"`
+	Note                string               `xml:"cbc:Note,omitempty"`
+	InvoicedQuantity    ublQuantity          `xml:"cbc:InvoicedQuantity"`
+	LineExtensionAmount ublAmount            `xml:"cbc:LineExtensionAmount"`
+	AllowanceCharges    []ublAllowanceCharge `xml:"cac:AllowanceCharge"`
+	Item                ublItem              `xml:"cac:Item"`
+	Price               ublPrice             `xml:"cac:Price"`
+}
+
+type ublAllowanceCharge struct {
+	ChargeIndicator           bool      `xml:"cbc:ChargeIndicator"`
+	AllowanceChargeReasonCode string    `xml:"cbc:AllowanceChargeReasonCode"`
+	AllowanceChargeReason     string    `xml:"cbc:AllowanceChargeReason"`
+	Amount                    ublAmount `xml:"cbc:Amount"`
+}
+
+type ublItem struct {
+	Name                  string         `xml:"cbc:Name"`
+	ClassifiedTaxCategory ublTaxCategory `xml:"cac:ClassifiedTaxCategory"`
+}
+
+type ublPrice struct {
+	PriceAmount ublAmount `xml:"cbc:PriceAmount"`
+}
+
+func (r *documentRenderer) RenderUBL(invoice entities.Invoice) ([]byte, error) {
+	if invoice.Seller == nil || invoice.Buyer == nil {
+		return nil, fmt.Errorf("%w: seller and buyer are required", entities.ErrInvalidEInvoice)
+	}
+	if invoice.InvoiceNumber == "" {
+		return nil, fmt.Errorf("%w: invoice number is required", entities.ErrInvalidEInvoice)
+	}
+	if invoice.Currency.Precision > ublMaxPrecision {
+		return nil, fmt.Errorf("%w: %s amounts have more than %d decimals", entities.ErrInvalidEInvoice, invoice.Currency.Code, ublMaxPrecision)
+	}
+
+	// dates are the calendar dates of the billing timezone
+	loc, err := entities.LoadTimezone(invoice.Summary.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := func(amountMinor int64) ublAmount {
+		return ublAmount{CurrencyID: invoice.Currency.Code, Value: entities.FormatMinorUnits(amountMinor, invoice.Currency.Precision)}
+	}
+
+	document := ublInvoice{
+		Xmlns:                   ublInvoiceNamespace,
+		XmlnsCac:                ublCacNamespace,
+		XmlnsCbc:                ublCbcNamespace,
+		CustomizationID:         ublCustomizationID,
+		ProfileID:               ublProfileID,
+		ID:                      invoice.InvoiceNumber,
+		IssueDate:               invoice.IssuedAt.In(loc).Format(time.DateOnly),
+		InvoiceTypeCode:         ublInvoiceTypeCode,
+		Note:                    invoice.Summary.Description,
+		DocumentCurrencyCode:    invoice.Currency.Code,
+		BuyerReference:          invoice.Summary.ExternalBillingID,
+		AccountingSupplierParty: ublPartyRole{Party: newUBLParty(invoice.Seller)},
+		AccountingCustomerParty: ublPartyRole{Party: newUBLParty(invoice.Buyer)},
+		PaymentTerms:            ublPaymentTerm{Note: ublPaymentTerms},
+	}
+
+	// billing periods end exclusively, e-invoices on the last day of the period
+	if invoice.Summary.PeriodStart != nil && invoice.Summary.PeriodEnd != nil {
+		document.InvoicePeriod = &ublPeriod{
+			StartDate: invoice.Summary.PeriodStart.In(loc).Format(time.DateOnly),
+			EndDate:   invoice.Summary.PeriodEnd.Add(-time.Nanosecond).In(loc).Format(time.DateOnly),
+		}
+	}
+
+	// lines are net of tax and discount, prices are never negative so corrections are negative quantities
+	subtotals := map[ublTaxCategory]*ublSubtotal{}
+	var lineExtensionAmountMinor int64
+	for i, lineItem := range invoice.Summary.LineItems {
+		netAmountMinor := lineItem.AmountMinor - lineItem.DiscountAmountMinor
+		if invoice.Summary.TaxInclusive {
+			netAmountMinor -= lineItem.TaxAmountMinor
+		}
+		priceMinor := netAmountMinor + lineItem.DiscountAmountMinor
+		quantity := "1"
+		if priceMinor < 0 {
+			priceMinor = -priceMinor
+			quantity = "-1"
+		}
+
+		category := newUBLTaxCategory(lineItem)
+		line := ublInvoiceLine{
+			ID:                  strconv.Itoa(i + 1),
+			Note:                lineItem.Reason,
+			InvoicedQuantity:    ublQuantity{UnitCode: ublUnitCode, Value: quantity},
+			LineExtensionAmount: amount(netAmountMinor),
+			Item:                ublItem{Name: lineItem.Description, ClassifiedTaxCategory: category},
+			Price:               ublPrice{PriceAmount: amount(priceMinor)},
+		}
+		if lineItem.DiscountAmountMinor != 0 {
+			line.AllowanceCharges = []ublAllowanceCharge{{
+				ChargeIndicator:           false,
+				AllowanceChargeReasonCode: ublDiscountCode,
+				AllowanceChargeReason:     "Discount",
+				Amount:                    amount(lineItem.DiscountAmountMinor),
+			}}
+		}
+		document.InvoiceLines = append(document.InvoiceLines, line)
+		lineExtensionAmountMinor += netAmountMinor
+
+		// break tax down per category and rate
+		subtotal, ok := subtotals[category]
+		if !ok {
+			subtotal = &ublSubtotal{category: category, ratePPM: lineItem.TaxRatePPM}
+			subtotals[category] = subtotal
+		}
+		subtotal.taxableAmountMinor += netAmountMinor
+		subtotal.taxAmountMinor += lineItem.TaxAmountMinor
+	}
+
+	sortedSubtotals := make([]*ublSubtotal, 0, len(subtotals))
+	for _, subtotal := range subtotals {
+		sortedSubtotals = append(sortedSubtotals, subtotal)
+	}
+	sort.Slice(sortedSubtotals, func(i, j int) bool {
+		if sortedSubtotals[i].category.ID != sortedSubtotals[j].category.ID {
+			return sortedSubtotals[i].category.ID < sortedSubtotals[j].category.ID
+		}
+		return sortedSubtotals[i].ratePPM < sortedSubtotals[j].ratePPM
+	})
+
+	var taxAmountMinor int64
+	for _, subtotal := range sortedSubtotals {
+		document.TaxTotal.TaxSubtotals = append(document.TaxTotal.TaxSubtotals, ublTaxSubtotal{
+			TaxableAmount: amount(subtotal.taxableAmountMinor),
+			TaxAmount:     amount(subtotal.taxAmountMinor),
+			TaxCategory:   subtotal.category,
+		})
+		taxAmountMinor += subtotal.taxAmountMinor
+	}
+	document.TaxTotal.TaxAmount = amount(taxAmountMinor)
+
+	document.LegalMonetaryTotal = ublMonetaryTotal{
+		LineExtensionAmount: amount(lineExtensionAmountMinor),
+		TaxExclusiveAmount:  amount(lineExtensionAmountMinor),
+		TaxInclusiveAmount:  amount(lineExtensionAmountMinor + taxAmountMinor),
+		PayableAmount:       amount(lineExtensionAmountMinor + taxAmountMinor - invoice.Summary.PaidFromCreditAmountMinor),
+	}
+
+	// BR-CO-16: credit drawn from the wallet of the buyer when the billing closed is prepaid
+	if invoice.Summary.PaidFromCreditAmountMinor != 0 {
+		prepaid := amount(invoice.Summary.PaidFromCreditAmountMinor)
+		document.LegalMonetaryTotal.PrepaidAmount = &prepaid
+	}
+
+	output, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(output, '\n')...), nil
+}
+
+// ublSubtotal accumulates the lines of a VAT category and rate
+type ublSubtotal struct {
+	category           ublTaxCategory
+	ratePPM            int64
+	taxableAmountMinor int64
+	taxAmountMinor     int64
+}
+
+func newUBLParty(party *entities.Party) ublParty {
+	result := ublParty{
+		EndpointID: ublEndpointID{SchemeID: party.EndpointScheme, Value: party.EndpointID},
+		PartyName:  ublPartyName{Name: party.Name},
+		PostalAddress: ublAddress{
+			StreetName: party.Street,
+			CityName:   party.City,
+			PostalZone: party.PostalCode,
+			Country:    ublCountry{IdentificationCode: party.CountryCode},
+		},
+		PartyLegalEntity: ublLegalEntity{RegistrationName: party.Name},
+	}
+	if party.VATID != "" {
+		result.PartyTaxScheme = &ublPartyTaxScheme{CompanyID: party.VATID, TaxScheme: ublTaxScheme{ID: ublVATScheme}}
+	}
+
+	return result
+}
+
+func newUBLTaxCategory(lineItem entities.LineItem) ublTaxCategory {
+	if lineItem.TaxCode == "" || lineItem.TaxRatePPM == 0 {
+		return ublTaxCategory{ID: ublTaxCategoryZero, Percent: "0", TaxScheme: ublTaxScheme{ID: ublVATScheme}}
+	}
+	return ublTaxCategory{ID: ublTaxCategoryStandard, Percent: formatPercent(lineItem.TaxRatePPM), TaxScheme: ublTaxScheme{ID: ublVATScheme}}
+}
@@ -0,0 +1,430 @@
+package documents
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// testEInvoice is a billing taxed at close with a discounted line, a correction and an untaxed line
+func testEInvoice(t *testing.T, inclusive bool) entities.Invoice {
+	periodStart := time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC) // 2026-01-01 in Berlin
+	periodEnd := time.Date(2026, 1, 31, 23, 0, 0, 0, time.UTC)
+	rates := []entities.TaxRate{{Jurisdiction: "DE", TaxCode: "standard", RatePPM: 200000}}
+
+	calculation, err := entities.CalculateTax([]entities.LineItem{
+		{LineItemID: "a", Description: "Seats", AmountMinor: 10000, TaxCode: "standard", DiscountAmountMinor: 1000},
+		{LineItemID: "b", Kind: entities.LineItemKindAdjustment, Description: "Seat refund", AmountMinor: -1050, TaxCode: "standard", Reason: "downgrade", ReferenceLineItemID: "a"},
+		{LineItemID: "c", Description: "Setup fee", AmountMinor: 2500},
+	}, rates, inclusive)
+	if err != nil {
+		t.Fatalf("CalculateTax failed: %v", err)
+	}
+
+	return entities.Invoice{
+		InvoiceNumber: "INV-2026-000001",
+		IssuedAt:      time.Date(2026, 1, 31, 23, 30, 0, 0, time.UTC),
+		UserID:        "user-1",
+		Currency:      entities.CurrencyMetadata{Code: "EUR", Symbol: "€", Precision: 2},
+		Summary: entities.BillingSummary{
+			ExternalBillingID:     "0193f1c2-0000-7000-8000-000000000000",
+			Description:           "Team plan",
+			Currency:              "EUR",
+			CurrencyPrecision:     2,
+			LineItems:             calculation.LineItems,
+			PeriodStart:           &periodStart,
+			PeriodEnd:             &periodEnd,
+			Timezone:              "Europe/Berlin",
+			TaxJurisdiction:       "DE",
+			TaxInclusive:          inclusive,
+			Discounts:             []entities.Discount{{CouponCode: "WELCOME", Type: entities.CouponTypeFixedAmount, AmountMinor: 1000}},
+			DiscountAmountMinor:   1000,
+			SubtotalAmountMinor:   calculation.SubtotalAmountMinor,
+			TaxAmountMinor:        calculation.TaxAmountMinor,
+			GrandTotalAmountMinor: calculation.GrandTotalAmountMinor,
+			Taxes:                 calculation.Taxes,
+		},
+		LineItems: entities.GroupLineItems(calculation.LineItems),
+		Seller: &entities.Party{
+			Role: entities.PartyRoleSeller, Key: "eu", Name: "Pave Bank GmbH", VATID: "DE123456789",
+			Street: "Friedrichstraße 1", City: "Berlin", PostalCode: "10117", CountryCode: "DE",
+			EndpointID: "DE123456789", EndpointScheme: "9930",
+		},
+		Buyer: &entities.Party{
+			Role: entities.PartyRoleBuyer, Key: "user-1", Name: "Acme B.V.",
+			City: "Amsterdam", CountryCode: "NL",
+			EndpointID: "0012345678", EndpointScheme: "0106",
+		},
+	}
+}
+
+func TestDocumentRenderer_RenderUBL(t *testing.T) {
+	renderer := NewDocumentRenderer()
+
+	document, err := renderer.RenderUBL(testEInvoice(t, false))
+	if err != nil {
+		t.Fatalf("RenderUBL failed: %v", err)
+	}
+
+	golden := "testdata/invoice.ubl.xml"
+	if *update {
+		if err := os.WriteFile(golden, document, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(document, expected) {
+		t.Errorf("RenderUBL() = \n%s\nexpected\n%s", document, expected)
+	}
+}
+
+// testEInvoices are the invoices the structure and the business rules are checked on
+func testEInvoices(t *testing.T) map[string]entities.Invoice {
+	withBuyerVATID := testEInvoice(t, false)
+	withBuyerVATID.Buyer.VATID = "NL123456789B01"
+
+	paidFromCredit := testEInvoice(t, false)
+	paidFromCredit.Summary.PaidFromCreditAmountMinor = 4000
+
+	return map[string]entities.Invoice{"exclusive": testEInvoice(t, false), "inclusive": testEInvoice(t, true), "buyer VAT ID": withBuyerVATID, "paid from credit": paidFromCredit}
+}
+
+func TestDocumentRenderer_RenderUBL_Structure(t *testing.T) {
+	renderer := NewDocumentRenderer()
+	structure := readUBLStructure(t, "testdata/ubl-invoice-2.1.structure")
+
+	for name, invoice := range testEInvoices(t) {
+		t.Run(name, func(t *testing.T) {
+			document, err := renderer.RenderUBL(invoice)
+			if err != nil {
+				t.Fatalf("RenderUBL failed: %v", err)
+			}
+
+			root := parseUBL(t, document)
+			if root.name != "Invoice" || root.namespace != ublInvoiceNamespace {
+				t.Fatalf("RenderUBL() root = %s in %s, expected Invoice in %s", root.name, root.namespace, ublInvoiceNamespace)
+			}
+			validateUBLElement(t, structure, root, root.name)
+		})
+	}
+}
+
+// TestDocumentRenderer_RenderUBL_Totals checks the calculation rules of EN 16931 and Peppol BIS Billing 3.0
+func TestDocumentRenderer_RenderUBL_Totals(t *testing.T) {
+	renderer := NewDocumentRenderer()
+
+	for name, invoice := range testEInvoices(t) {
+		t.Run(name, func(t *testing.T) {
+			document, err := renderer.RenderUBL(invoice)
+			if err != nil {
+				t.Fatalf("RenderUBL failed: %v", err)
+			}
+
+			root := parseUBL(t, document)
+			totals := root.child("cac:LegalMonetaryTotal")
+			taxTotal := root.child("cac:TaxTotal")
+
+			// PEPPOL-EN16931-R120: line net amount = quantity × price - line allowances + line charges
+			categoryNet := map[string]int64{}
+			var lineExtension int64
+			for _, line := range root.children("cac:InvoiceLine") {
+				net := ublAmountMinor(t, line.child("cbc:LineExtensionAmount"))
+				allowances, charges := ublAllowancesAndCharges(t, line)
+				quantity := ublDecimal(t, line.child("cbc:InvoicedQuantity"))
+				if expected := int64(math.Round(quantity*float64(ublAmountMinor(t, line.child("cac:Price").child("cbc:PriceAmount"))))) - allowances + charges; net != expected {
+					t.Errorf("line %s: LineExtensionAmount = %v, expected quantity × price - allowances + charges %v", line.child("cbc:ID").text, net, expected)
+				}
+				lineExtension += net
+				categoryNet[ublTaxCategoryKey(line.child("cac:Item").child("cac:ClassifiedTaxCategory"))] += net
+			}
+			documentAllowances, documentCharges := ublAllowancesAndCharges(t, root)
+
+			// BR-CO-10: sum of line net amounts
+			if got := ublAmountMinor(t, totals.child("cbc:LineExtensionAmount")); got != lineExtension {
+				t.Errorf("LineExtensionAmount = %v, expected sum of lines %v", got, lineExtension)
+			}
+			// BR-CO-11 and BR-CO-12: sums of document level allowances and charges
+			if got := ublOptionalAmountMinor(t, totals, "cbc:AllowanceTotalAmount"); got != documentAllowances {
+				t.Errorf("AllowanceTotalAmount = %v, expected %v", got, documentAllowances)
+			}
+			if got := ublOptionalAmountMinor(t, totals, "cbc:ChargeTotalAmount"); got != documentCharges {
+				t.Errorf("ChargeTotalAmount = %v, expected %v", got, documentCharges)
+			}
+			// BR-CO-13: total without VAT = sum of lines - allowances + charges
+			taxExclusive := ublAmountMinor(t, totals.child("cbc:TaxExclusiveAmount"))
+			if expected := lineExtension - documentAllowances + documentCharges; taxExclusive != expected {
+				t.Errorf("TaxExclusiveAmount = %v, expected %v", taxExclusive, expected)
+			}
+
+			var subtotalTax int64
+			for _, subtotal := range taxTotal.children("cac:TaxSubtotal") {
+				category := subtotal.child("cac:TaxCategory")
+				taxable := ublAmountMinor(t, subtotal.child("cbc:TaxableAmount"))
+				tax := ublAmountMinor(t, subtotal.child("cbc:TaxAmount"))
+				subtotalTax += tax
+
+				// BR-S-08 and BR-Z-08: taxable amount of a category = sum of its line net amounts, there are no document
+				// level allowances or charges to add
+				if key := ublTaxCategoryKey(category); taxable != categoryNet[key] {
+					t.Errorf("category %s: TaxableAmount = %v, expected sum of its lines %v", key, taxable, categoryNet[key])
+				}
+				// BR-CO-17: tax amount of a category = taxable amount × rate, rounded to 2 decimals
+				if expected := int64(math.Round(float64(taxable) * ublDecimal(t, category.child("cbc:Percent")) / 100)); tax != expected {
+					t.Errorf("category %s: TaxAmount = %v, expected TaxableAmount × Percent %v", ublTaxCategoryKey(category), tax, expected)
+				}
+			}
+
+			// BR-CO-14: total VAT = sum of the category tax amounts
+			taxAmount := ublAmountMinor(t, taxTotal.child("cbc:TaxAmount"))
+			if taxAmount != subtotalTax {
+				t.Errorf("TaxAmount = %v, expected sum of subtotals %v", taxAmount, subtotalTax)
+			}
+			// BR-CO-15: total with VAT = total without VAT + total VAT
+			taxInclusive := ublAmountMinor(t, totals.child("cbc:TaxInclusiveAmount"))
+			if taxInclusive != taxExclusive+taxAmount {
+				t.Errorf("TaxInclusiveAmount = %v, expected TaxExclusiveAmount plus TaxAmount %v", taxInclusive, taxExclusive+taxAmount)
+			}
+			// BR-CO-16: amount due = total with VAT - prepaid amount + rounding amount
+			prepaid := ublOptionalAmountMinor(t, totals, "cbc:PrepaidAmount")
+			payable := ublAmountMinor(t, totals.child("cbc:PayableAmount"))
+			if expected := taxInclusive - prepaid + ublOptionalAmountMinor(t, totals, "cbc:PayableRoundingAmount"); payable != expected {
+				t.Errorf("PayableAmount = %v, expected %v", payable, expected)
+			}
+
+			if payable != invoice.Summary.AmountDueMinor() {
+				t.Errorf("PayableAmount = %v, expected amount due %v", payable, invoice.Summary.AmountDueMinor())
+			}
+			if prepaid != invoice.Summary.PaidFromCreditAmountMinor {
+				t.Errorf("PrepaidAmount = %v, expected %v", prepaid, invoice.Summary.PaidFromCreditAmountMinor)
+			}
+		})
+	}
+}
+
+func TestDocumentRenderer_RenderUBL_Invalid(t *testing.T) {
+	renderer := NewDocumentRenderer()
+
+	withoutSeller := testEInvoice(t, false)
+	withoutSeller.Seller = nil
+
+	threeDecimals := testEInvoice(t, false)
+	threeDecimals.Currency = entities.CurrencyMetadata{Code: "KWD", Precision: 3}
+
+	for name, invoice := range map[string]entities.Invoice{"without seller": withoutSeller, "three decimals": threeDecimals} {
+		t.Run(name, func(t *testing.T) {
+			_, err := renderer.RenderUBL(invoice)
+			if !errors.Is(err, entities.ErrInvalidEInvoice) {
+				t.Errorf("RenderUBL() = %v, expected %v", err, entities.ErrInvalidEInvoice)
+			}
+		})
+	}
+}
+
+// ublStructureElement is the content model of an element: its children in order and which of them are mandatory
+type ublStructureElement struct {
+	sequence  []string
+	mandatory map[string]bool
+}
+
+func readUBLStructure(t *testing.T, path string) map[string]ublStructureElement {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open structure: %v", err)
+	}
+	defer file.Close()
+
+	structure := map[string]ublStructureElement{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, children, _ := strings.Cut(line, ": ")
+		element := ublStructureElement{mandatory: map[string]bool{}}
+		for _, child := range strings.Fields(children) {
+			child, mandatory := strings.CutSuffix(child, "!")
+			element.sequence = append(element.sequence, child)
+			element.mandatory[child] = mandatory
+		}
+		structure[name] = element
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read structure: %v", err)
+	}
+
+	return structure
+}
+
+// validateUBLElement checks the children of node against the structure, recursively
+func validateUBLElement(t *testing.T, structure map[string]ublStructureElement, node *ublNode, path string) {
+	t.Helper()
+
+	element, ok := structure[node.name]
+	if !ok {
+		if len(node.nodes) > 0 {
+			t.Errorf("%s: element is not in the structure", path)
+		}
+		if strings.TrimSpace(node.text) == "" {
+			t.Errorf("%s: element is empty", path)
+		}
+		return
+	}
+
+	position := 0
+	present := map[string]bool{}
+	for _, child := range node.nodes {
+		index := -1
+		for i := position; i < len(element.sequence); i++ {
+			if element.sequence[i] == child.name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			t.Errorf("%s: %s is not allowed here", path, child.name)
+			continue
+		}
+		position = index
+		present[child.name] = true
+		validateUBLElement(t, structure, child, path+"/"+child.name)
+	}
+	for _, name := range element.sequence {
+		if element.mandatory[name] && !present[name] {
+			t.Errorf("%s: %s is missing", path, name)
+		}
+	}
+}
+
+type ublNode struct {
+	name      string
+	namespace string
+	text      string
+	nodes     []*ublNode
+}
+
+func (n *ublNode) children(name string) []*ublNode {
+	nodes := []*ublNode{}
+	for _, node := range n.nodes {
+		if node.name == name {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func (n *ublNode) child(name string) *ublNode {
+	for _, node := range n.nodes {
+		if node.name == name {
+			return node
+		}
+	}
+	return &ublNode{name: name}
+}
+
+// parseUBL parses a document into a tree of elements named with their cac or cbc prefix
+func parseUBL(t *testing.T, document []byte) *ublNode {
+	prefixes := map[string]string{ublInvoiceNamespace: "", ublCacNamespace: "cac:", ublCbcNamespace: "cbc:"}
+
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	stack := []*ublNode{{}}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			prefix, ok := prefixes[token.Name.Space]
+			if !ok {
+				t.Errorf("unexpected namespace %s of %s", token.Name.Space, token.Name.Local)
+			}
+			node := &ublNode{name: prefix + token.Name.Local, namespace: token.Name.Space}
+			parent := stack[len(stack)-1]
+			parent.nodes = append(parent.nodes, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			stack[len(stack)-1].text += string(token)
+		}
+	}
+	if len(stack) != 1 || len(stack[0].nodes) != 1 {
+		t.Fatalf("document is not well formed")
+	}
+
+	return stack[0].nodes[0]
+}
+
+func ublAmountMinor(t *testing.T, node *ublNode) int64 {
+	t.Helper()
+
+	amount, err := strconv.ParseFloat(node.text, 64)
+	if err != nil {
+		t.Fatalf("%s is not an amount: %q", node.name, node.text)
+	}
+	return int64(amount*100 + 0.5*sign(amount))
+}
+
+// ublOptionalAmountMinor returns the amount of the child of node, zero when it is absent
+func ublOptionalAmountMinor(t *testing.T, node *ublNode, name string) int64 {
+	t.Helper()
+
+	if len(node.children(name)) == 0 {
+		return 0
+	}
+	return ublAmountMinor(t, node.child(name))
+}
+
+// ublAllowancesAndCharges sums the allowances and the charges of node
+func ublAllowancesAndCharges(t *testing.T, node *ublNode) (int64, int64) {
+	t.Helper()
+
+	var allowances, charges int64
+	for _, allowanceCharge := range node.children("cac:AllowanceCharge") {
+		amount := ublAmountMinor(t, allowanceCharge.child("cbc:Amount"))
+		if strings.TrimSpace(allowanceCharge.child("cbc:ChargeIndicator").text) == "true" {
+			charges += amount
+		} else {
+			allowances += amount
+		}
+	}
+	return allowances, charges
+}
+
+// ublTaxCategoryKey identifies a VAT category by its code and rate
+func ublTaxCategoryKey(category *ublNode) string {
+	return category.child("cbc:ID").text + "/" + category.child("cbc:Percent").text
+}
+
+func ublDecimal(t *testing.T, node *ublNode) float64 {
+	t.Helper()
+
+	value, err := strconv.ParseFloat(node.text, 64)
+	if err != nil {
+		t.Fatalf("%s is not a decimal: %q", node.name, node.text)
+	}
+	return value
+}
+
+func sign(amount float64) float64 {
+	if amount < 0 {
+		return -1
+	}
+	return 1
+}
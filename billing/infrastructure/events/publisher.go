@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"encore.dev/pubsub"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/services"
+)
+
+// topics of the billing events, Encore topic names are kebab case so billing.created is published on billing-created
+var (
+	BillingCreatedTopic = pubsub.NewTopic[*entities.BillingCreatedEvent]("billing-created", pubsub.TopicConfig{
+		DeliveryGuarantee: pubsub.AtLeastOnce,
+	})
+
+	BillingLineItemAddedTopic = pubsub.NewTopic[*entities.BillingLineItemAddedEvent]("billing-line-item-added", pubsub.TopicConfig{
+		DeliveryGuarantee: pubsub.AtLeastOnce,
+	})
+
+	BillingClosedTopic = pubsub.NewTopic[*entities.BillingClosedEvent]("billing-closed", pubsub.TopicConfig{
+		DeliveryGuarantee: pubsub.AtLeastOnce,
+	})
+
+	BillingCancelledTopic = pubsub.NewTopic[*entities.BillingCancelledEvent]("billing-cancelled", pubsub.TopicConfig{
+		DeliveryGuarantee: pubsub.AtLeastOnce,
+	})
+
+	BillingPaymentReminderTopic = pubsub.NewTopic[*entities.BillingPaymentReminderEvent]("billing-payment-reminder", pubsub.TopicConfig{
+		DeliveryGuarantee: pubsub.AtLeastOnce,
+	})
+
+	BillingSpendThresholdCrossedTopic = pubsub.NewTopic[*entities.BillingSpendThresholdCrossedEvent]("billing-spend-threshold-crossed", pubsub.TopicConfig{
+		DeliveryGuarantee: pubsub.AtLeastOnce,
+	})
+
+	BillingUsageUnbilledTopic = pubsub.NewTopic[*entities.BillingUsageUnbilledEvent]("billing-usage-unbilled", pubsub.TopicConfig{
+		DeliveryGuarantee: pubsub.AtLeastOnce,
+	})
+)
+
+type pubsubEventPublisher struct{}
+
+func NewPubSubEventPublisher() services.EventPublisher {
+	return &pubsubEventPublisher{}
+}
+
+// Publish decodes an outbox message to its event and publishes it on the topic of its type
+func (p *pubsubEventPublisher) Publish(ctx context.Context, message *entities.OutboxMessage) error {
+	fn := "infrastructure.events.pubsubEventPublisher.Publish"
+	logger := rlog.With("fn", fn).With("eventID", message.EventID).With("eventType", message.EventType).With("offset", message.Offset)
+
+	var messageID string
+	var err error
+	switch message.EventType {
+	case entities.BillingEventCreated:
+		var event entities.BillingCreatedEvent
+		if err = json.Unmarshal(message.Payload, &event); err == nil {
+			messageID, err = BillingCreatedTopic.Publish(ctx, &event)
+		}
+	case entities.BillingEventLineItemAdded:
+		var event entities.BillingLineItemAddedEvent
+		if err = json.Unmarshal(message.Payload, &event); err == nil {
+			messageID, err = BillingLineItemAddedTopic.Publish(ctx, &event)
+		}
+	case entities.BillingEventClosed:
+		var event entities.BillingClosedEvent
+		if err = json.Unmarshal(message.Payload, &event); err == nil {
+			messageID, err = BillingClosedTopic.Publish(ctx, &event)
+		}
+	case entities.BillingEventCancelled:
+		var event entities.BillingCancelledEvent
+		if err = json.Unmarshal(message.Payload, &event); err == nil {
+			messageID, err = BillingCancelledTopic.Publish(ctx, &event)
+		}
+	case entities.BillingEventPaymentReminder:
+		var event entities.BillingPaymentReminderEvent
+		if err = json.Unmarshal(message.Payload, &event); err == nil {
+			messageID, err = BillingPaymentReminderTopic.Publish(ctx, &event)
+		}
+	case entities.BillingEventSpendThresholdCrossed:
+		var event entities.BillingSpendThresholdCrossedEvent
+		if err = json.Unmarshal(message.Payload, &event); err == nil {
+			messageID, err = BillingSpendThresholdCrossedTopic.Publish(ctx, &event)
+		}
+	case entities.BillingEventUsageUnbilled:
+		var event entities.BillingUsageUnbilledEvent
+		if err = json.Unmarshal(message.Payload, &event); err == nil {
+			messageID, err = BillingUsageUnbilledTopic.Publish(ctx, &event)
+		}
+	default:
+		logger.Error("unknown event type")
+		return entities.ErrEventPublisher
+	}
+	if err != nil {
+		logger.Error("failed to publish billing event", "error", err)
+		return entities.ErrEventPublisher
+	}
+
+	logger.Info("billing event published", "messageID", messageID)
+	return nil
+}
@@ -0,0 +1,12 @@
+package events
+
+import (
+	"encore.dev/pubsub"
+
+	"encore.app/billing/domain/entities"
+)
+
+// UsageEventsTopic carries usage events reported by other services, they are ingested like the events of the usage events endpoint
+var UsageEventsTopic = pubsub.NewTopic[*entities.UsageEvent]("usage-events", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
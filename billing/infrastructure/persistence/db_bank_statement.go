@@ -0,0 +1,235 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresBankStatementRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresBankStatementRepository(db *sqldb.Database) repositories.BankStatementRepository {
+	return &postgresBankStatementRepository{db: db}
+}
+
+func (r *postgresBankStatementRepository) CreateBankStatement(ctx context.Context, statement *entities.BankStatement) (*entities.BankStatement, error) {
+	fn := "infrastructure.persistence.postgresBankStatementRepository.CreateBankStatement"
+	logger := rlog.With("fn", fn).With("externalStatementID", statement.ExternalStatementID).With("format", statement.Format).With("reference", statement.Reference).With("contentHash", statement.ContentHash)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// insert statement into database, a file imported again is skipped
+	var statementID int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO bank_statements (external_statement_id, format, reference, account, content_hash)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (content_hash) DO NOTHING
+		RETURNING id
+	`, statement.ExternalStatementID, statement.Format, statement.Reference, statement.Account, statement.ContentHash).Scan(&statementID)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		logger.Info("bank statement already imported")
+		return r.GetBankStatementByContentHash(ctx, statement.ContentHash)
+	}
+	if err != nil {
+		logger.Error("failed to create bank statement in database", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	// insert transactions
+	for _, transaction := range statement.Transactions {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO bank_transactions (external_transaction_id, statement_id, bank_reference, booking_date, direction, amount_minor, currency, currency_precision,
+				counterparty_name, remittance_info, status, match_rule, candidate_billing_ids, billing_id, payment_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		`, transaction.ExternalTransactionID, statementID, transaction.BankReference, transaction.BookingDate, transaction.Direction, transaction.AmountMinor, transaction.Currency, transaction.CurrencyPrecision,
+			transaction.CounterpartyName, transaction.RemittanceInfo, transaction.Status, transaction.MatchRule, candidateBillingIDs(transaction.CandidateBillingIDs), transaction.BillingID, transaction.PaymentID)
+		if err != nil {
+			logger.Error("failed to create bank transaction in database", "error", err, "bankReference", transaction.BankReference)
+			return nil, entities.ErrDBService
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit bank statement", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	logger.Info("bank statement created successfully", "transactions", len(statement.Transactions))
+
+	return r.GetBankStatementByExternalID(ctx, statement.ExternalStatementID)
+}
+
+func (r *postgresBankStatementRepository) GetBankStatementByExternalID(ctx context.Context, externalStatementID string) (*entities.BankStatement, error) {
+	return r.getBankStatement(ctx, `external_statement_id = $1`, externalStatementID)
+}
+
+func (r *postgresBankStatementRepository) GetBankStatementByContentHash(ctx context.Context, contentHash string) (*entities.BankStatement, error) {
+	return r.getBankStatement(ctx, `content_hash = $1`, contentHash)
+}
+
+// getBankStatement gets the statement matching a filter on the bank statements table with its transactions
+func (r *postgresBankStatementRepository) getBankStatement(ctx context.Context, filter string, arg any) (*entities.BankStatement, error) {
+	fn := "infrastructure.persistence.postgresBankStatementRepository.getBankStatement"
+	logger := rlog.With("fn", fn).With("filter", filter).With("arg", arg)
+
+	var statement entities.BankStatement
+	err := r.db.QueryRow(ctx, `
+		SELECT id, external_statement_id, format, reference, account, content_hash, created_at FROM bank_statements WHERE `+filter,
+		arg).Scan(&statement.ID, &statement.ExternalStatementID, &statement.Format, &statement.Reference, &statement.Account, &statement.ContentHash, &statement.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("bank statement not found")
+			return nil, entities.ErrBankStatementNotFound
+		}
+
+		logger.Error("failed to get bank statement", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	statement.Transactions, err = r.listBankTransactions(ctx, 0, `WHERE t.statement_id = $1`, statement.ID)
+	if err != nil {
+		logger.Error("failed to list bank transactions", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return &statement, nil
+}
+
+func (r *postgresBankStatementRepository) GetBankTransactionByExternalID(ctx context.Context, externalTransactionID string) (*entities.BankTransaction, error) {
+	fn := "infrastructure.persistence.postgresBankStatementRepository.GetBankTransactionByExternalID"
+	logger := rlog.With("fn", fn).With("externalTransactionID", externalTransactionID)
+
+	transactions, err := r.listBankTransactions(ctx, 0, `WHERE t.external_transaction_id = $1`, externalTransactionID)
+	if err != nil {
+		logger.Error("failed to get bank transaction", "error", err)
+		return nil, entities.ErrDBService
+	}
+	if len(transactions) == 0 {
+		logger.Warn("bank transaction not found")
+		return nil, entities.ErrBankTransactionNotFound
+	}
+
+	return &transactions[0], nil
+}
+
+func (r *postgresBankStatementRepository) ListBankTransactionsByStatus(ctx context.Context, status entities.BankTransactionStatus, limit int) ([]entities.BankTransaction, error) {
+	fn := "infrastructure.persistence.postgresBankStatementRepository.ListBankTransactionsByStatus"
+	logger := rlog.With("fn", fn).With("status", status).With("limit", limit)
+
+	transactions, err := r.listBankTransactions(ctx, limit, `WHERE t.status = $1`, status)
+	if err != nil {
+		logger.Error("failed to list bank transactions", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return transactions, nil
+}
+
+func (r *postgresBankStatementRepository) ReviewBankTransaction(ctx context.Context, transaction *entities.BankTransaction) error {
+	fn := "infrastructure.persistence.postgresBankStatementRepository.ReviewBankTransaction"
+	logger := rlog.With("fn", fn).With("transactionID", transaction.ID).With("status", transaction.Status).With("billingID", transaction.BillingID).With("paymentID", transaction.PaymentID)
+
+	// only a transaction waiting for review is reviewed, so that two reviewers cannot both record it
+	result, err := r.db.Exec(ctx, `
+		UPDATE bank_transactions SET status = $1, match_rule = $2, billing_id = $3, payment_id = $4, updated_at = timezone('utc', now())
+		WHERE id = $5 AND status = 'needs_review'
+	`, transaction.Status, transaction.MatchRule, transaction.BillingID, transaction.PaymentID, transaction.ID)
+	if err != nil {
+		logger.Error("failed to review bank transaction in database", "error", err)
+		return entities.ErrDBService
+	}
+	if result.RowsAffected() == 0 {
+		logger.Warn("bank transaction is already reviewed")
+		return entities.ErrBankTransactionReviewed
+	}
+
+	logger.Info("bank transaction reviewed successfully")
+
+	return nil
+}
+
+func (r *postgresBankStatementRepository) SettleBankTransaction(ctx context.Context, transaction *entities.BankTransaction) error {
+	fn := "infrastructure.persistence.postgresBankStatementRepository.SettleBankTransaction"
+	logger := rlog.With("fn", fn).With("transactionID", transaction.ID).With("status", transaction.Status).With("billingID", transaction.BillingID).With("paymentID", transaction.PaymentID)
+
+	// only a matched transaction without its payment is settled, so that an import resumed twice settles it once
+	result, err := r.db.Exec(ctx, `
+		UPDATE bank_transactions SET status = $1, match_rule = $2, candidate_billing_ids = $3, billing_id = $4, payment_id = $5, updated_at = timezone('utc', now())
+		WHERE id = $6 AND status = 'matched' AND payment_id IS NULL
+	`, transaction.Status, transaction.MatchRule, candidateBillingIDs(transaction.CandidateBillingIDs), transaction.BillingID, transaction.PaymentID, transaction.ID)
+	if err != nil {
+		logger.Error("failed to settle bank transaction in database", "error", err)
+		return entities.ErrDBService
+	}
+	if result.RowsAffected() == 0 {
+		logger.Info("bank transaction is already settled")
+		return nil
+	}
+
+	logger.Info("bank transaction settled successfully")
+
+	return nil
+}
+
+// listBankTransactions lists the transactions matching a filter on the bank transactions table t, oldest first, at most
+// limit of them when limit is positive
+func (r *postgresBankStatementRepository) listBankTransactions(ctx context.Context, limit int, filter string, args ...any) ([]entities.BankTransaction, error) {
+	limitClause := ""
+	if limit > 0 {
+		limitClause = fmt.Sprintf("LIMIT %d", limit)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT t.id, t.external_transaction_id, t.statement_id, s.external_statement_id, t.bank_reference, t.booking_date, t.direction, t.amount_minor, t.currency, t.currency_precision,
+			t.counterparty_name, t.remittance_info, t.status, t.match_rule, t.candidate_billing_ids,
+			ARRAY(SELECT c.external_billing_id::TEXT FROM billings c WHERE c.id = ANY(t.candidate_billing_ids) ORDER BY c.id),
+			t.billing_id, b.external_billing_id::TEXT, t.payment_id, p.external_payment_id::TEXT, t.created_at, t.updated_at
+		FROM bank_transactions t
+		JOIN bank_statements s ON s.id = t.statement_id
+		LEFT JOIN billings b ON b.id = t.billing_id
+		LEFT JOIN payments p ON p.id = t.payment_id
+		`+filter+`
+		ORDER BY t.id
+		`+limitClause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []entities.BankTransaction{}
+	for rows.Next() {
+		var transaction entities.BankTransaction
+		err = rows.Scan(&transaction.ID, &transaction.ExternalTransactionID, &transaction.StatementID, &transaction.ExternalStatementID, &transaction.BankReference, &transaction.BookingDate, &transaction.Direction, &transaction.AmountMinor, &transaction.Currency, &transaction.CurrencyPrecision,
+			&transaction.CounterpartyName, &transaction.RemittanceInfo, &transaction.Status, &transaction.MatchRule, &transaction.CandidateBillingIDs,
+			&transaction.ExternalCandidateBillingIDs,
+			&transaction.BillingID, &transaction.ExternalBillingID, &transaction.PaymentID, &transaction.ExternalPaymentID, &transaction.CreatedAt, &transaction.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+// candidateBillingIDs stores no candidates as an empty array rather than NULL
+func candidateBillingIDs(ids []int64) []int64 {
+	if ids == nil {
+		return []int64{}
+	}
+	return ids
+}
@@ -0,0 +1,208 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresBankStatementRepository_CreateBankStatement(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresBankStatementRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	billing := createClosedTestBilling(t, ctx, billingRepo, 1000)
+	bookedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	newStatement := func() *entities.BankStatement {
+		return &entities.BankStatement{
+			ExternalStatementID: uuid.NewString(),
+			Format:              entities.BankStatementFormatCSV,
+			ContentHash:         "hash-1",
+			Transactions: []entities.BankTransaction{
+				{
+					ExternalTransactionID: uuid.NewString(),
+					BankReference:         "TRX-1",
+					BookingDate:           bookedAt,
+					Direction:             entities.BankTransactionDirectionCredit,
+					AmountMinor:           1000,
+					Currency:              "USD",
+					CurrencyPrecision:     2,
+					RemittanceInfo:        "march",
+					Status:                entities.BankTransactionStatusNeedsReview,
+					CandidateBillingIDs:   []int64{billing.ID},
+				},
+				{
+					ExternalTransactionID: uuid.NewString(),
+					BankReference:         "TRX-2",
+					BookingDate:           bookedAt,
+					Direction:             entities.BankTransactionDirectionDebit,
+					AmountMinor:           250,
+					Currency:              "USD",
+					CurrencyPrecision:     2,
+					Status:                entities.BankTransactionStatusIgnored,
+				},
+			},
+		}
+	}
+
+	statement, err := repo.CreateBankStatement(ctx, newStatement())
+	if err != nil {
+		t.Fatalf("CreateBankStatement failed: %v", err)
+	}
+	if len(statement.Transactions) != 2 {
+		t.Fatalf("Expected 2 transactions, got %d", len(statement.Transactions))
+	}
+	review := statement.Transactions[0]
+	if len(review.ExternalCandidateBillingIDs) != 1 || review.ExternalCandidateBillingIDs[0] != billing.ExternalBillingID {
+		t.Errorf("Expected candidate %v, got %v", billing.ExternalBillingID, review.ExternalCandidateBillingIDs)
+	}
+	if len(statement.Transactions[1].ExternalCandidateBillingIDs) != 0 {
+		t.Errorf("Expected no candidates, got %v", statement.Transactions[1].ExternalCandidateBillingIDs)
+	}
+
+	// a file imported again returns the first import
+	again, err := repo.CreateBankStatement(ctx, newStatement())
+	if err != nil {
+		t.Fatalf("CreateBankStatement failed: %v", err)
+	}
+	if again.ExternalStatementID != statement.ExternalStatementID {
+		t.Errorf("Expected statement %v, got %v", statement.ExternalStatementID, again.ExternalStatementID)
+	}
+
+	queue, err := repo.ListBankTransactionsByStatus(ctx, entities.BankTransactionStatusNeedsReview, 10)
+	if err != nil {
+		t.Fatalf("ListBankTransactionsByStatus failed: %v", err)
+	}
+	found := false
+	for _, transaction := range queue {
+		found = found || transaction.ExternalTransactionID == review.ExternalTransactionID
+	}
+	if !found {
+		t.Errorf("Expected transaction %v in the review queue", review.ExternalTransactionID)
+	}
+}
+
+func TestPostgresBankStatementRepository_ReviewBankTransaction(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresBankStatementRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+	paymentRepo := NewPostgresPaymentRepository(db)
+
+	billing := createClosedTestBilling(t, ctx, billingRepo, 1000)
+	statement, err := repo.CreateBankStatement(ctx, &entities.BankStatement{
+		ExternalStatementID: uuid.NewString(),
+		Format:              entities.BankStatementFormatCSV,
+		ContentHash:         "hash-2",
+		Transactions: []entities.BankTransaction{{
+			ExternalTransactionID: uuid.NewString(),
+			BankReference:         "TRX-3",
+			BookingDate:           time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			Direction:             entities.BankTransactionDirectionCredit,
+			AmountMinor:           1000,
+			Currency:              "USD",
+			CurrencyPrecision:     2,
+			Status:                entities.BankTransactionStatusNeedsReview,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateBankStatement failed: %v", err)
+	}
+
+	payment := &entities.Payment{ExternalPaymentID: uuid.NewString(), BillingID: billing.ID, AmountMinor: 1000, Currency: "USD", Method: entities.PaymentMethodBankTransfer, ExternalReference: "TRX-3", PaidAt: time.Now().UTC()}
+	if _, err = paymentRepo.CreatePayment(ctx, payment); err != nil {
+		t.Fatalf("CreatePayment failed: %v", err)
+	}
+
+	transaction := statement.Transactions[0]
+	transaction.Status = entities.BankTransactionStatusMatched
+	transaction.MatchRule = entities.BankTransactionMatchRuleManual
+	transaction.BillingID = &billing.ID
+	transaction.PaymentID = &payment.ID
+	if err = repo.ReviewBankTransaction(ctx, &transaction); err != nil {
+		t.Fatalf("ReviewBankTransaction failed: %v", err)
+	}
+
+	reviewed, err := repo.GetBankTransactionByExternalID(ctx, transaction.ExternalTransactionID)
+	if err != nil {
+		t.Fatalf("GetBankTransactionByExternalID failed: %v", err)
+	}
+	if reviewed.Status != entities.BankTransactionStatusMatched || reviewed.ExternalPaymentID == nil || *reviewed.ExternalPaymentID != payment.ExternalPaymentID {
+		t.Errorf("Unexpected reviewed transaction: %+v", reviewed)
+	}
+
+	// a reviewed transaction cannot be reviewed again
+	transaction.Status = entities.BankTransactionStatusIgnored
+	err = repo.ReviewBankTransaction(ctx, &transaction)
+	if !errors.Is(err, entities.ErrBankTransactionReviewed) {
+		t.Errorf("Expected %v, got %v", entities.ErrBankTransactionReviewed, err)
+	}
+}
+
+func TestPostgresBankStatementRepository_SettleBankTransaction(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresBankStatementRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+	paymentRepo := NewPostgresPaymentRepository(db)
+
+	// a matched transaction is stored with its billing before its payment is recorded
+	billing := createClosedTestBilling(t, ctx, billingRepo, 1000)
+	statement, err := repo.CreateBankStatement(ctx, &entities.BankStatement{
+		ExternalStatementID: uuid.NewString(),
+		Format:              entities.BankStatementFormatCSV,
+		ContentHash:         "hash-3",
+		Transactions: []entities.BankTransaction{{
+			ExternalTransactionID: uuid.NewString(),
+			BankReference:         "TRX-4",
+			BookingDate:           time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			Direction:             entities.BankTransactionDirectionCredit,
+			AmountMinor:           1000,
+			Currency:              "USD",
+			CurrencyPrecision:     2,
+			Status:                entities.BankTransactionStatusMatched,
+			MatchRule:             entities.BankTransactionMatchRuleManual,
+			BillingID:             &billing.ID,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateBankStatement failed: %v", err)
+	}
+	transaction := statement.Transactions[0]
+	if transaction.ExternalBillingID == nil || *transaction.ExternalBillingID != billing.ExternalBillingID || transaction.PaymentID != nil {
+		t.Fatalf("Expected a matched transaction without payment, got %+v", transaction)
+	}
+
+	payment := &entities.Payment{ExternalPaymentID: uuid.NewString(), BillingID: billing.ID, AmountMinor: 1000, Currency: "USD", Method: entities.PaymentMethodBankTransfer, ExternalReference: "TRX-4", PaidAt: time.Now().UTC()}
+	if _, err = paymentRepo.CreatePayment(ctx, payment); err != nil {
+		t.Fatalf("CreatePayment failed: %v", err)
+	}
+	transaction.PaymentID = &payment.ID
+	if err = repo.SettleBankTransaction(ctx, &transaction); err != nil {
+		t.Fatalf("SettleBankTransaction failed: %v", err)
+	}
+
+	// a transaction settled again is left as is
+	again := transaction
+	again.Status = entities.BankTransactionStatusNeedsReview
+	again.PaymentID = nil
+	if err = repo.SettleBankTransaction(ctx, &again); err != nil {
+		t.Fatalf("SettleBankTransaction failed: %v", err)
+	}
+
+	settled, err := repo.GetBankTransactionByExternalID(ctx, transaction.ExternalTransactionID)
+	if err != nil {
+		t.Fatalf("GetBankTransactionByExternalID failed: %v", err)
+	}
+	if settled.Status != entities.BankTransactionStatusMatched || settled.ExternalPaymentID == nil || *settled.ExternalPaymentID != payment.ExternalPaymentID {
+		t.Errorf("Unexpected settled transaction: %+v", settled)
+	}
+}
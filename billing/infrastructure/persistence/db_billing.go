@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -28,8 +29,8 @@ func (r *postgresDBRepository) GetBillingByExternalID(ctx context.Context, exter
 
 	// get billing from database
 	err := r.db.QueryRow(ctx, `
-		SELECT id, user_id, description, currency, currency_precision, status, planned_closed_at, actual_closed_at, created_at, updated_at FROM billings WHERE external_billing_id = $1
-	`, externalBillingID).Scan(&billing.ID, &billing.UserID, &billing.Description, &billing.Currency, &billing.CurrencyPrecision, &billing.Status, &billing.PlannedClosedAt, &billing.ActualClosedAt, &billing.CreatedAt, &billing.UpdatedAt)
+		SELECT id, external_billing_id, user_id, description, currency, currency_precision, status, planned_closed_at, actual_closed_at, period_start, period_end, timezone, recurrence, subscription_id, previous_billing_id, next_billing_id, tax_jurisdiction, tax_inclusive, allow_negative_total, user_group, auto_charge, spend_limit, invoice_number, cancelled_at, cancellation_reason, created_at, updated_at FROM billings WHERE external_billing_id = $1
+	`, externalBillingID).Scan(&billing.ID, &billing.ExternalBillingID, &billing.UserID, &billing.Description, &billing.Currency, &billing.CurrencyPrecision, &billing.Status, &billing.PlannedClosedAt, &billing.ActualClosedAt, &billing.PeriodStart, &billing.PeriodEnd, &billing.Timezone, &billing.Recurrence, &billing.SubscriptionID, &billing.PreviousBillingID, &billing.NextBillingID, &billing.TaxJurisdiction, &billing.TaxInclusive, &billing.AllowNegativeTotal, &billing.UserGroup, &billing.AutoCharge, &billing.SpendLimit, &billing.InvoiceNumber, &billing.CancelledAt, &billing.CancellationReason, &billing.CreatedAt, &billing.UpdatedAt)
 	if err != nil {
 		// no rows found
 		if errors.Is(err, sqldb.ErrNoRows) {
@@ -45,83 +46,414 @@ func (r *postgresDBRepository) GetBillingByExternalID(ctx context.Context, exter
 	return &billing, nil
 }
 
-func (r *postgresDBRepository) CreateBilling(ctx context.Context, userID string, externalBillingID string, description string, currency string, currencyPrecision int64, plannedClosedAt *time.Time) (int64, error) {
+func (r *postgresDBRepository) CreateBilling(ctx context.Context, billing *entities.Billing) (int64, error) {
 	fn := "infrastructure.persistence.postgresDBRepository.CreateBilling"
-	logger := rlog.With("fn", fn).With("userID", userID).With("externalBillingID", externalBillingID).With("description", description).With("currency", currency).With("currencyPrecision", currencyPrecision).With("plannedClosedAt", plannedClosedAt)
+	logger := rlog.With("fn", fn).With("userID", billing.UserID).With("externalBillingID", billing.ExternalBillingID).With("description", billing.Description).With("currency", billing.Currency).With("currencyPrecision", billing.CurrencyPrecision).With("plannedClosedAt", billing.PlannedClosedAt).With("previousBillingID", billing.PreviousBillingID)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return 0, entities.ErrDBService
+	}
+	defer tx.Rollback()
 
 	var billingID int64
+	var createdAt time.Time
 
-	// insert billing into database
-	err := r.db.QueryRow(ctx, `
-		INSERT INTO billings (user_id, external_billing_id, description, currency, currency_precision, status, planned_closed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id
-	`, userID, externalBillingID, description, currency, currencyPrecision, entities.BillingStatusOpen, plannedClosedAt).Scan(&billingID)
+	// insert billing into database, the insert is idempotent on the external billing ID so that retried activities return the same row
+	err = tx.QueryRow(ctx, `
+		INSERT INTO billings (user_id, external_billing_id, description, currency, currency_precision, status, planned_closed_at, period_start, period_end, timezone, recurrence, subscription_id, previous_billing_id, tax_jurisdiction, tax_inclusive, allow_negative_total, user_group, auto_charge, spend_limit)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE(NULLIF($10, ''), 'UTC'), $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		ON CONFLICT (external_billing_id) DO UPDATE SET updated_at = billings.updated_at
+		RETURNING id, created_at
+	`, billing.UserID, billing.ExternalBillingID, billing.Description, billing.Currency, billing.CurrencyPrecision, entities.BillingStatusOpen, billing.PlannedClosedAt, billing.PeriodStart, billing.PeriodEnd, billing.Timezone, billing.Recurrence, billing.SubscriptionID, billing.PreviousBillingID, billing.TaxJurisdiction, billing.TaxInclusive, billing.AllowNegativeTotal, billing.UserGroup, billing.AutoCharge, billing.SpendLimit).Scan(&billingID, &createdAt)
 	if err != nil {
 		logger.Error("failed to create billing in database", "error", err)
 		return 0, entities.ErrDBService
 	}
 
+	// write billing created to the outbox
+	event := entities.NewBillingCreatedEvent(*billing, createdAt)
+	err = insertOutboxMessage(ctx, tx, event.EventID, event.Type, event)
+	if err != nil {
+		logger.Error("failed to write billing created event to outbox", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit billing creation", "error", err)
+		return 0, entities.ErrDBService
+	}
+
 	logger.Info("billing created successfully")
 
 	// return billing ID
 	return billingID, nil
 }
 
-func (r *postgresDBRepository) AddLineItem(ctx context.Context, billingID int64, description string, amountMinor int64) error {
+func (r *postgresDBRepository) AddLineItem(ctx context.Context, billingID int64, lineItem *entities.LineItem) error {
 	fn := "infrastructure.persistence.postgresDBRepository.AddLineItem"
-	logger := rlog.With("fn", fn).With("billingID", billingID).With("description", description).With("amountMinor", amountMinor)
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("lineItemID", lineItem.LineItemID).With("kind", lineItem.Kind).With("description", lineItem.Description).With("amountMinor", lineItem.AmountMinor).With("taxCode", lineItem.TaxCode)
 
-	// insert line item into database
-	_, err := r.db.Exec(ctx, `
-		INSERT INTO line_items (billing_id, description, amount_minor)
-		VALUES ($1, $2, $3)
-	`, billingID, description, amountMinor)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// get external billing ID of the event
+	var externalBillingID string
+	err = tx.QueryRow(ctx, `
+		SELECT external_billing_id FROM billings WHERE id = $1
+	`, billingID).Scan(&externalBillingID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Billing not found")
+			return entities.ErrBillingNotFound
+		}
+
+		logger.Error("failed to get billing", "error", err)
+		return entities.ErrDBService
+	}
+
+	// insert line item into database, the insert is idempotent on the external line item ID so that retried activities add it once
+	_, err = tx.Exec(ctx, `
+		INSERT INTO line_items (billing_id, external_line_item_id, kind, description, amount_minor, tax_code, reason, reference_line_item_id)
+		VALUES ($1, NULLIF($2, '')::UUID, COALESCE(NULLIF($3, ''), 'charge')::LINE_ITEM_KIND, $4, $5, $6, $7, NULLIF($8, '')::UUID)
+		ON CONFLICT (external_line_item_id) DO NOTHING
+	`, billingID, lineItem.LineItemID, lineItem.Kind, lineItem.Description, lineItem.AmountMinor, lineItem.TaxCode, lineItem.Reason, lineItem.ReferenceLineItemID)
 	if err != nil {
 		logger.Error("failed to add line item to database", "error", err)
 		return entities.ErrDBService
 	}
 
+	// write line item added to the outbox
+	event := entities.NewBillingLineItemAddedEvent(externalBillingID, *lineItem, time.Now().UTC())
+	err = insertOutboxMessage(ctx, tx, event.EventID, event.Type, event)
+	if err != nil {
+		logger.Error("failed to write line item added event to outbox", "error", err)
+		return entities.ErrDBService
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit line item", "error", err)
+		return entities.ErrDBService
+	}
+
 	logger.Info("line item added successfully")
 
 	return nil
 }
 
-func (r *postgresDBRepository) CloseBilling(ctx context.Context, billingID int64, actualClosedAt time.Time) error {
+func (r *postgresDBRepository) CloseBilling(ctx context.Context, billingID int64, actualClosedAt time.Time) (string, error) {
 	fn := "infrastructure.persistence.postgresDBRepository.CloseBilling"
 	logger := rlog.With("fn", fn).With("billingID", billingID).With("actualClosedAt", actualClosedAt)
 
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return "", entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// lock billing, a billing closed by a previous attempt keeps its invoice number
+	var userGroup, timezone string
+	var invoiceNumber *string
+	err = tx.QueryRow(ctx, `
+		SELECT user_group, timezone, invoice_number FROM billings WHERE id = $1 FOR UPDATE
+	`, billingID).Scan(&userGroup, &timezone, &invoiceNumber)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Billing not found")
+			return "", entities.ErrBillingNotFound
+		}
+
+		logger.Error("failed to lock billing", "error", err)
+		return "", entities.ErrDBService
+	}
+	if invoiceNumber != nil {
+		logger.Info("billing already closed", "invoiceNumber", *invoiceNumber)
+		return *invoiceNumber, nil
+	}
+
+	// get invoice number series of the user group, falling back to the default series
+	series := entities.DefaultInvoiceNumberSeries()
+	err = tx.QueryRow(ctx, `
+		SELECT user_group, prefix, padding FROM invoice_number_series WHERE user_group IN ($1, '') ORDER BY user_group DESC LIMIT 1
+	`, userGroup).Scan(&series.UserGroup, &series.Prefix, &series.Padding)
+	if err != nil && !errors.Is(err, sqldb.ErrNoRows) {
+		logger.Error("failed to get invoice number series", "error", err)
+		return "", entities.ErrDBService
+	}
+
+	year, err := entities.InvoiceYear(actualClosedAt, timezone)
+	if err != nil {
+		logger.Error("failed to get invoice year", "error", err)
+		return "", err
+	}
+
+	// take the next number, the counter row stays locked until commit so concurrent closes are numbered one after the other
+	var number int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO invoice_number_counters (prefix, year, last_number)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (prefix, year) DO UPDATE SET last_number = invoice_number_counters.last_number + 1, updated_at = timezone('utc', now())
+		RETURNING last_number
+	`, series.Prefix, year).Scan(&number)
+	if err != nil {
+		logger.Error("failed to get next invoice number", "error", err)
+		return "", entities.ErrDBService
+	}
+	assigned := series.Format(year, number)
+
 	// update billing in database
-	_, err := r.db.Exec(ctx, `
-		UPDATE billings SET status = $1, actual_closed_at = $2 WHERE id = $3
-	`, entities.BillingStatusClosed, actualClosedAt, billingID)
+	_, err = tx.Exec(ctx, `
+		UPDATE billings SET status = $1, actual_closed_at = $2, invoice_number = $3, updated_at = timezone('utc', now()) WHERE id = $4
+	`, entities.BillingStatusClosed, actualClosedAt, assigned, billingID)
 	if err != nil {
 		logger.Error("failed to close billing in database", "error", err)
+		return "", entities.ErrDBService
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit billing close", "error", err)
+		return "", entities.ErrDBService
+	}
+
+	logger.Info("billing closed successfully", "invoiceNumber", assigned)
+
+	return assigned, nil
+}
+
+func (r *postgresDBRepository) CancelBilling(ctx context.Context, billingID int64, reason string, cancelledAt time.Time) error {
+	fn := "infrastructure.persistence.postgresDBRepository.CancelBilling"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("reason", reason).With("cancelledAt", cancelledAt)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// lock billing, so that it is not closed while it is cancelled
+	var status entities.BillingStatus
+	var externalBillingID string
+	err = tx.QueryRow(ctx, `
+		SELECT status, external_billing_id FROM billings WHERE id = $1 FOR UPDATE
+	`, billingID).Scan(&status, &externalBillingID)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Billing not found")
+			return entities.ErrBillingNotFound
+		}
+
+		logger.Error("failed to lock billing", "error", err)
+		return entities.ErrDBService
+	}
+	if status == entities.BillingStatusCancelled {
+		logger.Info("billing already cancelled")
+		return nil
+	}
+	if status != entities.BillingStatusOpen {
+		logger.Warn("billing is not open", "status", status)
+		return entities.ErrBillingNotOpen
+	}
+
+	// update billing in database
+	_, err = tx.Exec(ctx, `
+		UPDATE billings SET status = $1, cancelled_at = $2, cancellation_reason = $3, updated_at = timezone('utc', now()) WHERE id = $4
+	`, entities.BillingStatusCancelled, cancelledAt, reason, billingID)
+	if err != nil {
+		logger.Error("failed to cancel billing in database", "error", err)
 		return entities.ErrDBService
 	}
 
-	logger.Info("billing closed successfully")
+	// write billing cancelled to the outbox
+	event := entities.NewBillingCancelledEvent(externalBillingID, reason, cancelledAt)
+	err = insertOutboxMessage(ctx, tx, event.EventID, event.Type, event)
+	if err != nil {
+		logger.Error("failed to write billing cancelled event to outbox", "error", err)
+		return entities.ErrDBService
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit billing cancellation", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("billing cancelled successfully")
 
 	return nil
 }
 
-func (r *postgresDBRepository) CreateBillingSummary(ctx context.Context, externalBillingID string, billingSummary []byte) error {
+func (r *postgresDBRepository) RecordSpendThresholdCrossed(ctx context.Context, billingID int64, threshold int64, totalAmountMinor int64, occurredAt time.Time) error {
+	fn := "infrastructure.persistence.postgresDBRepository.RecordSpendThresholdCrossed"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("threshold", threshold).With("totalAmountMinor", totalAmountMinor)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// get billing of the event
+	var billing entities.Billing
+	err = tx.QueryRow(ctx, `
+		SELECT external_billing_id, user_id, currency, spend_limit FROM billings WHERE id = $1
+	`, billingID).Scan(&billing.ExternalBillingID, &billing.UserID, &billing.Currency, &billing.SpendLimit)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Billing not found")
+			return entities.ErrBillingNotFound
+		}
+
+		logger.Error("failed to get billing", "error", err)
+		return entities.ErrDBService
+	}
+
+	// write spend threshold crossed to the outbox, the event of a threshold is written once
+	event := entities.NewBillingSpendThresholdCrossedEvent(billing, threshold, totalAmountMinor, occurredAt)
+	err = insertOutboxMessage(ctx, tx, event.EventID, event.Type, event)
+	if err != nil {
+		logger.Error("failed to write spend threshold crossed event to outbox", "error", err)
+		return entities.ErrDBService
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit spend threshold crossed", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("spend threshold crossed recorded successfully")
+
+	return nil
+}
+
+func (r *postgresDBRepository) LinkNextBilling(ctx context.Context, billingID int64, nextExternalBillingID string) error {
+	fn := "infrastructure.persistence.postgresDBRepository.LinkNextBilling"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("nextExternalBillingID", nextExternalBillingID)
+
+	// update billing in database
+	_, err := r.db.Exec(ctx, `
+		UPDATE billings SET next_billing_id = $1, updated_at = timezone('utc', now()) WHERE id = $2
+	`, nextExternalBillingID, billingID)
+	if err != nil {
+		logger.Error("failed to link next billing in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("next billing linked successfully")
+
+	return nil
+}
+
+func (r *postgresDBRepository) CreateBillingSummary(ctx context.Context, externalBillingID string, billingSummary []byte, walletDraw *entities.WalletDraw) (int64, error) {
 	fn := "infrastructure.persistence.postgresDBRepository.CreateBillingSummary"
 	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID)
 
-	// insert billing summary into database
-	_, err := r.db.Exec(ctx, `
+	var summary entities.BillingSummary
+	err := json.Unmarshal(billingSummary, &summary)
+	if err != nil {
+		logger.Error("failed to decode billing summary", "error", err)
+		return 0, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return 0, entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// lock the closed billing, attempts to write its summary go one after the other
+	var billing entities.Billing
+	var closedAt time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT id, external_billing_id, user_id, currency, currency_precision, COALESCE(actual_closed_at, timezone('utc', now()))
+		FROM billings
+		WHERE external_billing_id = $1
+		FOR UPDATE
+	`, externalBillingID).Scan(&billing.ID, &billing.ExternalBillingID, &billing.UserID, &billing.Currency, &billing.CurrencyPrecision, &closedAt)
+	if err != nil {
+		logger.Error("failed to get closed billing", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	// a retried activity finds the summary of its first attempt, which must be the same apart from what the wallet paid
+	var same bool
+	var paidFromCreditAmountMinor int64
+	err = tx.QueryRow(ctx, `
+		SELECT summary - 'paid_from_credit_amount_minor' = $2::JSONB - 'paid_from_credit_amount_minor',
+			COALESCE((summary->>'paid_from_credit_amount_minor')::BIGINT, 0)
+		FROM billing_summaries
+		WHERE external_billing_id = $1
+	`, externalBillingID, billingSummary).Scan(&same, &paidFromCreditAmountMinor)
+	if err == nil {
+		if !same {
+			logger.Error("billing summary differs from the one already created")
+			return 0, entities.ErrBillingSummaryMismatch
+		}
+
+		logger.Info("billing summary already created")
+		return paidFromCreditAmountMinor, nil
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		logger.Error("failed to get billing summary from database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	// post the invoiced grand total to the ledger
+	err = insertJournalEntry(ctx, tx, entities.NewBillingClosedJournalEntry(billing.ID, summary, closedAt))
+	if err != nil {
+		logger.Error("failed to post billing closed to ledger", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	// pay what the wallet of the user holds, so that the summary shows what is left to pay
+	if walletDraw != nil {
+		paidFromCreditAmountMinor, err = drawWalletForBilling(ctx, tx, &billing, summary.GrandTotalAmountMinor, walletDraw)
+		if err != nil {
+			logger.Error("failed to draw wallet for billing", "error", err)
+			return 0, err
+		}
+		summary.PaidFromCreditAmountMinor = paidFromCreditAmountMinor
+	}
+
+	// insert billing summary into database, the summary is written once with its outbox message and journal entries
+	_, err = tx.Exec(ctx, `
 		INSERT INTO billing_summaries (external_billing_id, summary)
-		VALUES ($1, $2)
-	`, externalBillingID, billingSummary)
+		VALUES ($1, $2::JSONB || jsonb_build_object('paid_from_credit_amount_minor', $3::BIGINT))
+	`, externalBillingID, billingSummary, paidFromCreditAmountMinor)
 	if err != nil {
 		logger.Error("failed to create billing summary in database", "error", err)
-		return entities.ErrDBService
+		return 0, entities.ErrDBService
 	}
 
-	logger.Info("billing summary created successfully")
+	// write billing closed to the outbox, the billing is closed once its summary is written
+	event := entities.NewBillingClosedEvent(summary, time.Now().UTC())
+	err = insertOutboxMessage(ctx, tx, event.EventID, event.Type, event)
+	if err != nil {
+		logger.Error("failed to write billing closed event to outbox", "error", err)
+		return 0, entities.ErrDBService
+	}
 
-	return nil
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit billing summary", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("billing summary created successfully", "paidFromCreditAmountMinor", paidFromCreditAmountMinor)
+
+	return paidFromCreditAmountMinor, nil
 }
 
 func (r *postgresDBRepository) GetBillingSummary(ctx context.Context, externalBillingID string) (*entities.BillingSummary, error) {
@@ -33,7 +33,14 @@ func TestPostgresDBRepository_CreateBilling(t *testing.T) {
 	externalBillingID, _ := uuid.NewV7()
 
 	plannedClosedAt := time.Now().Add(24 * time.Hour)
-	billingID, err := repo.CreateBilling(ctx, "user123", externalBillingID.String(), "Test billing", "USD", 2, &plannedClosedAt)
+	billingID, err := repo.CreateBilling(ctx, &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: externalBillingID.String(),
+		Description:       "Test billing",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		PlannedClosedAt:   &plannedClosedAt,
+	})
 	if err != nil {
 		t.Fatalf("CreateBilling failed: %v", err)
 	}
@@ -59,7 +66,14 @@ func TestPostgresDBRepository_GetBillingByExternalID(t *testing.T) {
 
 	// Test found
 	plannedClosedAt := time.Now().Add(24 * time.Hour)
-	billingID, err := repo.CreateBilling(ctx, "user123", externalBillingID.String(), "Test billing", "USD", 2, &plannedClosedAt)
+	billingID, err := repo.CreateBilling(ctx, &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: externalBillingID.String(),
+		Description:       "Test billing",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		PlannedClosedAt:   &plannedClosedAt,
+	})
 	if err != nil {
 		t.Fatalf("CreateBilling failed: %v", err)
 	}
@@ -83,12 +97,19 @@ func TestPostgresDBRepository_AddLineItem(t *testing.T) {
 	externalBillingID, _ := uuid.NewV7()
 
 	plannedClosedAt := time.Now().Add(24 * time.Hour)
-	billingID, err := repo.CreateBilling(ctx, "user123", externalBillingID.String(), "Test billing", "USD", 2, &plannedClosedAt)
+	billingID, err := repo.CreateBilling(ctx, &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: externalBillingID.String(),
+		Description:       "Test billing",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		PlannedClosedAt:   &plannedClosedAt,
+	})
 	if err != nil {
 		t.Fatalf("CreateBilling failed: %v", err)
 	}
 
-	err = repo.AddLineItem(ctx, billingID, "Test item", 1000)
+	err = repo.AddLineItem(ctx, billingID, &entities.LineItem{Description: "Test item", AmountMinor: 1000})
 	if err != nil {
 		t.Fatalf("AddLineItem failed: %v", err)
 	}
@@ -101,13 +122,20 @@ func TestPostgresDBRepository_CloseBilling(t *testing.T) {
 	externalBillingID, _ := uuid.NewV7()
 
 	plannedClosedAt := time.Now().Add(24 * time.Hour)
-	billingID, err := repo.CreateBilling(ctx, "user123", externalBillingID.String(), "Test billing", "USD", 2, &plannedClosedAt)
+	billingID, err := repo.CreateBilling(ctx, &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: externalBillingID.String(),
+		Description:       "Test billing",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		PlannedClosedAt:   &plannedClosedAt,
+	})
 	if err != nil {
 		t.Fatalf("CreateBilling failed: %v", err)
 	}
 
 	actualClosedAt := time.Now().UTC()
-	err = repo.CloseBilling(ctx, billingID, actualClosedAt)
+	invoiceNumber, err := repo.CloseBilling(ctx, billingID, actualClosedAt)
 	if err != nil {
 		t.Fatalf("CloseBilling failed: %v", err)
 	}
@@ -120,4 +148,281 @@ func TestPostgresDBRepository_CloseBilling(t *testing.T) {
 	if billing.Status != entities.BillingStatusClosed {
 		t.Errorf("Expected status %s, got %s", entities.BillingStatusClosed, billing.Status)
 	}
+	if billing.InvoiceNumber == nil || *billing.InvoiceNumber != invoiceNumber {
+		t.Errorf("Expected invoice number %s, got %v", invoiceNumber, billing.InvoiceNumber)
+	}
+}
+
+func TestPostgresDBRepository_CloseBilling_InvoiceNumbers(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresDBRepository(db)
+
+	closedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first := createTestBilling(t, ctx, repo)
+	second := createTestBilling(t, ctx, repo)
+
+	firstNumber, err := repo.CloseBilling(ctx, first.ID, closedAt)
+	if err != nil {
+		t.Fatalf("CloseBilling failed: %v", err)
+	}
+
+	// a retried close keeps the number of the first attempt
+	retriedNumber, err := repo.CloseBilling(ctx, first.ID, closedAt.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("CloseBilling failed: %v", err)
+	}
+	if retriedNumber != firstNumber {
+		t.Errorf("Expected retried close to return %s, got %s", firstNumber, retriedNumber)
+	}
+
+	// the next billing gets the next number
+	secondNumber, err := repo.CloseBilling(ctx, second.ID, closedAt)
+	if err != nil {
+		t.Fatalf("CloseBilling failed: %v", err)
+	}
+	if firstNumber != "INV-2026-000001" || secondNumber != "INV-2026-000002" {
+		t.Errorf("Expected INV-2026-000001 and INV-2026-000002, got %s and %s", firstNumber, secondNumber)
+	}
+}
+
+func TestPostgresDBRepository_CancelBilling(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresDBRepository(db)
+
+	cancelledAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	billing := createTestBilling(t, ctx, repo)
+
+	err := repo.CancelBilling(ctx, billing.ID, "created by mistake", cancelledAt)
+	if err != nil {
+		t.Fatalf("CancelBilling failed: %v", err)
+	}
+
+	// a retried cancel does nothing
+	err = repo.CancelBilling(ctx, billing.ID, "created by mistake", cancelledAt.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("CancelBilling failed: %v", err)
+	}
+
+	// Verify billing is cancelled
+	cancelled, err := repo.GetBillingByExternalID(ctx, billing.ExternalBillingID)
+	if err != nil {
+		t.Fatalf("GetBillingByExternalID failed: %v", err)
+	}
+	if cancelled.Status != entities.BillingStatusCancelled {
+		t.Errorf("Expected status %s, got %s", entities.BillingStatusCancelled, cancelled.Status)
+	}
+	if cancelled.CancelledAt == nil || !cancelled.CancelledAt.Equal(cancelledAt) {
+		t.Errorf("Expected cancelled at %v, got %v", cancelledAt, cancelled.CancelledAt)
+	}
+	if cancelled.CancellationReason != "created by mistake" {
+		t.Errorf("Expected cancellation reason %q, got %q", "created by mistake", cancelled.CancellationReason)
+	}
+
+	// a closed billing cannot be cancelled
+	closed := createTestBilling(t, ctx, repo)
+	_, err = repo.CloseBilling(ctx, closed.ID, cancelledAt)
+	if err != nil {
+		t.Fatalf("CloseBilling failed: %v", err)
+	}
+	err = repo.CancelBilling(ctx, closed.ID, "", cancelledAt)
+	if !errors.Is(err, entities.ErrBillingNotOpen) {
+		t.Errorf("CancelBilling() = %v, expected %v", err, entities.ErrBillingNotOpen)
+	}
+
+	err = repo.CancelBilling(ctx, 0, "", cancelledAt)
+	if !errors.Is(err, entities.ErrBillingNotFound) {
+		t.Errorf("CancelBilling() = %v, expected %v", err, entities.ErrBillingNotFound)
+	}
+}
+
+func TestPostgresDBRepository_LinkNextBilling(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresDBRepository(db)
+	externalBillingID, _ := uuid.NewV7()
+	nextExternalBillingID, _ := uuid.NewV7()
+
+	plannedClosedAt := time.Now().Add(24 * time.Hour)
+	billingID, err := repo.CreateBilling(ctx, &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: externalBillingID.String(),
+		Description:       "Test billing",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		PlannedClosedAt:   &plannedClosedAt,
+		Recurrence: &entities.RecurrenceRule{
+			Frequency:  entities.RecurrenceFrequencyMonthly,
+			DayOfMonth: 1,
+			Timezone:   "Asia/Tbilisi",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateBilling failed: %v", err)
+	}
+
+	err = repo.LinkNextBilling(ctx, billingID, nextExternalBillingID.String())
+	if err != nil {
+		t.Fatalf("LinkNextBilling failed: %v", err)
+	}
+
+	// Verify billing is linked and keeps its recurrence rule
+	billing, err := repo.GetBillingByExternalID(ctx, externalBillingID.String())
+	if err != nil {
+		t.Fatalf("GetBillingByExternalID failed: %v", err)
+	}
+	if billing.NextBillingID == nil || *billing.NextBillingID != nextExternalBillingID.String() {
+		t.Errorf("Expected next billing ID %s, got %v", nextExternalBillingID.String(), billing.NextBillingID)
+	}
+	if billing.Recurrence == nil || billing.Recurrence.Timezone != "Asia/Tbilisi" {
+		t.Errorf("Expected recurrence to be persisted, got %+v", billing.Recurrence)
+	}
+}
+
+func TestPostgresDBRepository_CreateBilling_WithPeriod(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresDBRepository(db)
+	externalBillingID, _ := uuid.NewV7()
+
+	periodStart, periodEnd, err := entities.PeriodBounds(entities.BillingPeriodCalendarMonth, "Asia/Tbilisi", time.Now())
+	if err != nil {
+		t.Fatalf("PeriodBounds failed: %v", err)
+	}
+	_, err = repo.CreateBilling(ctx, &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: externalBillingID.String(),
+		Description:       "Test billing",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		PlannedClosedAt:   &periodEnd,
+		PeriodStart:       &periodStart,
+		PeriodEnd:         &periodEnd,
+		Timezone:          "Asia/Tbilisi",
+	})
+	if err != nil {
+		t.Fatalf("CreateBilling failed: %v", err)
+	}
+
+	billing, err := repo.GetBillingByExternalID(ctx, externalBillingID.String())
+	if err != nil {
+		t.Fatalf("GetBillingByExternalID failed: %v", err)
+	}
+	if billing.PeriodStart == nil || !billing.PeriodStart.Equal(periodStart) {
+		t.Errorf("Expected period start %v, got %v", periodStart, billing.PeriodStart)
+	}
+	if billing.PeriodEnd == nil || !billing.PeriodEnd.Equal(periodEnd) {
+		t.Errorf("Expected period end %v, got %v", periodEnd, billing.PeriodEnd)
+	}
+	if billing.Timezone != "Asia/Tbilisi" {
+		t.Errorf("Expected timezone Asia/Tbilisi, got %s", billing.Timezone)
+	}
+}
+
+func createTestBilling(t *testing.T, ctx context.Context, repo repositories.DBRepository) *entities.Billing {
+	t.Helper()
+
+	externalBillingID, _ := uuid.NewV7()
+	billing := &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: externalBillingID.String(),
+		Description:       "Test billing",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+	}
+	billingID, err := repo.CreateBilling(ctx, billing)
+	if err != nil {
+		t.Fatalf("CreateBilling failed: %v", err)
+	}
+	billing.ID = billingID
+
+	return billing
+}
+
+func TestPostgresDBRepository_AddLineItem_Adjustment(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresDBRepository(db)
+
+	billing := createTestBilling(t, ctx, repo)
+	chargeID, _ := uuid.NewV7()
+	adjustmentID, _ := uuid.NewV7()
+
+	charge := &entities.LineItem{LineItemID: chargeID.String(), Kind: entities.LineItemKindCharge, Description: "Seat", AmountMinor: 1000}
+	adjustment := &entities.LineItem{LineItemID: adjustmentID.String(), Kind: entities.LineItemKindAdjustment, Description: "Goodwill", AmountMinor: -300, Reason: "outage", ReferenceLineItemID: chargeID.String()}
+	for _, lineItem := range []*entities.LineItem{charge, adjustment, adjustment} {
+		err := repo.AddLineItem(ctx, billing.ID, lineItem)
+		if err != nil {
+			t.Fatalf("AddLineItem failed: %v", err)
+		}
+	}
+
+	// the adjustment was added twice with the same ID, it must be stored once
+	var count int
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM line_items WHERE billing_id = $1`, billing.ID).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to count line items: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 line items, got %d", count)
+	}
+}
+
+func TestPostgresDBRepository_RecordSpendThresholdCrossed(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresDBRepository(db)
+	outboxRepo := NewPostgresOutboxRepository(db)
+
+	externalBillingID, _ := uuid.NewV7()
+	billing := &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: externalBillingID.String(),
+		Description:       "Test billing",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		SpendLimit:        &entities.SpendLimit{AmountMinor: 10000, Thresholds: entities.DefaultSpendLimitThresholds, Mode: entities.SpendLimitModeReject},
+	}
+	billingID, err := repo.CreateBilling(ctx, billing)
+	if err != nil {
+		t.Fatalf("CreateBilling failed: %v", err)
+	}
+
+	// the spend limit is stored with the billing
+	stored, err := repo.GetBillingByExternalID(ctx, billing.ExternalBillingID)
+	if err != nil {
+		t.Fatalf("GetBillingByExternalID failed: %v", err)
+	}
+	if stored.SpendLimit == nil || stored.SpendLimit.AmountMinor != 10000 || stored.SpendLimit.Mode != entities.SpendLimitModeReject || len(stored.SpendLimit.Thresholds) != 3 {
+		t.Fatalf("Expected the spend limit, got %+v", stored.SpendLimit)
+	}
+
+	// a threshold recorded again by a retried activity is written once
+	occurredAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		err = repo.RecordSpendThresholdCrossed(ctx, billingID, 50, 5000, occurredAt)
+		if err != nil {
+			t.Fatalf("RecordSpendThresholdCrossed failed: %v", err)
+		}
+	}
+
+	var published []*entities.OutboxMessage
+	_, err = outboxRepo.RelayOutbox(ctx, 100, func(message *entities.OutboxMessage) error {
+		if message.EventType == entities.BillingEventSpendThresholdCrossed {
+			published = append(published, message)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RelayOutbox failed: %v", err)
+	}
+	if len(published) != 1 || published[0].EventID != "billing.spend_threshold_crossed:"+billing.ExternalBillingID+":50" {
+		t.Errorf("Expected one spend threshold crossed event, got %+v", published)
+	}
+
+	err = repo.RecordSpendThresholdCrossed(ctx, 0, 50, 5000, occurredAt)
+	if !errors.Is(err, entities.ErrBillingNotFound) {
+		t.Errorf("RecordSpendThresholdCrossed() = %v, expected %v", err, entities.ErrBillingNotFound)
+	}
 }
@@ -0,0 +1,127 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresCouponRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresCouponRepository(db *sqldb.Database) repositories.CouponRepository {
+	return &postgresCouponRepository{db: db}
+}
+
+func (r *postgresCouponRepository) CreateCoupon(ctx context.Context, coupon *entities.Coupon) (int64, error) {
+	fn := "infrastructure.persistence.postgresCouponRepository.CreateCoupon"
+	logger := rlog.With("fn", fn).With("code", coupon.Code).With("type", coupon.Type)
+
+	var couponID int64
+
+	// insert coupon into database
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO coupons (code, type, percent_off_bps, amount_off_minor, currency, currency_precision, expires_at, max_redemptions)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::CURRENCY_CODE, $6, $7, $8)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING id
+	`, coupon.Code, coupon.Type, coupon.PercentOffBPS, coupon.AmountOffMinor, coupon.Currency, coupon.CurrencyPrecision, coupon.ExpiresAt, coupon.MaxRedemptions).Scan(&couponID)
+	if err != nil {
+		// code already taken
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Coupon code already exists")
+			return 0, entities.ErrCouponCodeTaken
+		}
+
+		logger.Error("failed to create coupon in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("coupon created successfully")
+
+	return couponID, nil
+}
+
+func (r *postgresCouponRepository) GetCouponByCode(ctx context.Context, code string) (*entities.Coupon, error) {
+	fn := "infrastructure.persistence.postgresCouponRepository.GetCouponByCode"
+	logger := rlog.With("fn", fn).With("code", code)
+
+	var coupon entities.Coupon
+
+	// get coupon from database
+	err := r.db.QueryRow(ctx, `
+		SELECT id, code, type, percent_off_bps, amount_off_minor, COALESCE(currency::TEXT, ''), currency_precision, expires_at, max_redemptions, times_redeemed, created_at, updated_at FROM coupons WHERE code = $1
+	`, code).Scan(&coupon.ID, &coupon.Code, &coupon.Type, &coupon.PercentOffBPS, &coupon.AmountOffMinor, &coupon.Currency, &coupon.CurrencyPrecision, &coupon.ExpiresAt, &coupon.MaxRedemptions, &coupon.TimesRedeemed, &coupon.CreatedAt, &coupon.UpdatedAt)
+	if err != nil {
+		// no rows found
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Coupon not found")
+			return nil, entities.ErrCouponNotFound
+		}
+
+		// unknown error
+		logger.Error("Failed to get coupon by code", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return &coupon, nil
+}
+
+func (r *postgresCouponRepository) RedeemCoupon(ctx context.Context, couponID int64, billingID int64) error {
+	fn := "infrastructure.persistence.postgresCouponRepository.RedeemCoupon"
+	logger := rlog.With("fn", fn).With("couponID", couponID).With("billingID", billingID)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// record redemption, a second redemption by the same billing does not count against the limit
+	result, err := tx.Exec(ctx, `
+		INSERT INTO coupon_redemptions (coupon_id, billing_id)
+		VALUES ($1, $2)
+		ON CONFLICT (coupon_id, billing_id) DO NOTHING
+	`, couponID, billingID)
+	if err != nil {
+		logger.Error("failed to record coupon redemption in database", "error", err)
+		return entities.ErrDBService
+	}
+	if result.RowsAffected() == 0 {
+		logger.Info("coupon already redeemed by billing")
+		return nil
+	}
+
+	// count redemption, the conditions make concurrent redemptions respect the expiry and the limit
+	result, err = tx.Exec(ctx, `
+		UPDATE coupons SET times_redeemed = times_redeemed + 1, updated_at = timezone('utc', now())
+		WHERE id = $1
+			AND (expires_at IS NULL OR expires_at > timezone('utc', now()))
+			AND (max_redemptions IS NULL OR times_redeemed < max_redemptions)
+	`, couponID)
+	if err != nil {
+		logger.Error("failed to count coupon redemption in database", "error", err)
+		return entities.ErrDBService
+	}
+	if result.RowsAffected() == 0 {
+		logger.Warn("coupon can no longer be redeemed")
+		return entities.ErrCouponRedemptionLimitReached
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit coupon redemption", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("coupon redeemed successfully")
+
+	return nil
+}
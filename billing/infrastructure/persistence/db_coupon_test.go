@@ -0,0 +1,85 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresCouponRepository_CreateCoupon(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresCouponRepository(db)
+
+	code := "TEST-" + uuid.NewString()
+	_, err := repo.CreateCoupon(ctx, &entities.Coupon{Code: code, Type: entities.CouponTypeFixedAmount, AmountOffMinor: 500, Currency: "USD", CurrencyPrecision: 2})
+	if err != nil {
+		t.Fatalf("CreateCoupon failed: %v", err)
+	}
+
+	coupon, err := repo.GetCouponByCode(ctx, code)
+	if err != nil {
+		t.Fatalf("GetCouponByCode failed: %v", err)
+	}
+	if coupon.Type != entities.CouponTypeFixedAmount || coupon.AmountOffMinor != 500 || coupon.Currency != "USD" {
+		t.Errorf("Unexpected coupon: %+v", coupon)
+	}
+
+	// Test duplicate code
+	_, err = repo.CreateCoupon(ctx, &entities.Coupon{Code: code, Type: entities.CouponTypePercentage, PercentOffBPS: 1000})
+	if !errors.Is(err, entities.ErrCouponCodeTaken) {
+		t.Errorf("Expected ErrCouponCodeTaken, got: %v", err)
+	}
+
+	// Test not found
+	_, err = repo.GetCouponByCode(ctx, "TEST-"+uuid.NewString())
+	if !errors.Is(err, entities.ErrCouponNotFound) {
+		t.Errorf("Expected ErrCouponNotFound, got: %v", err)
+	}
+}
+
+func TestPostgresCouponRepository_RedeemCoupon(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresCouponRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	code := "TEST-" + uuid.NewString()
+	maxRedemptions := int64(1)
+	couponID, err := repo.CreateCoupon(ctx, &entities.Coupon{Code: code, Type: entities.CouponTypePercentage, PercentOffBPS: 1000, MaxRedemptions: &maxRedemptions})
+	if err != nil {
+		t.Fatalf("CreateCoupon failed: %v", err)
+	}
+
+	billing := createTestBilling(t, ctx, billingRepo)
+	err = repo.RedeemCoupon(ctx, couponID, billing.ID)
+	if err != nil {
+		t.Fatalf("RedeemCoupon failed: %v", err)
+	}
+
+	// redeeming again for the same billing is a no-op
+	err = repo.RedeemCoupon(ctx, couponID, billing.ID)
+	if err != nil {
+		t.Fatalf("RedeemCoupon failed: %v", err)
+	}
+
+	// another billing hits the limit
+	otherBilling := createTestBilling(t, ctx, billingRepo)
+	err = repo.RedeemCoupon(ctx, couponID, otherBilling.ID)
+	if !errors.Is(err, entities.ErrCouponRedemptionLimitReached) {
+		t.Errorf("Expected ErrCouponRedemptionLimitReached, got: %v", err)
+	}
+
+	coupon, err := repo.GetCouponByCode(ctx, code)
+	if err != nil {
+		t.Fatalf("GetCouponByCode failed: %v", err)
+	}
+	if coupon.TimesRedeemed != 1 {
+		t.Errorf("Expected 1 redemption, got %d", coupon.TimesRedeemed)
+	}
+}
@@ -0,0 +1,221 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresCreditNoteRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresCreditNoteRepository(db *sqldb.Database) repositories.CreditNoteRepository {
+	return &postgresCreditNoteRepository{db: db}
+}
+
+func (r *postgresCreditNoteRepository) CreateCreditNote(ctx context.Context, creditNote *entities.CreditNote, billedAmountMinor int64) (int64, error) {
+	fn := "infrastructure.persistence.postgresCreditNoteRepository.CreateCreditNote"
+	logger := rlog.With("fn", fn).With("externalCreditNoteID", creditNote.ExternalCreditNoteID).With("billingID", creditNote.BillingID).With("totalAmountMinor", creditNote.TotalAmountMinor)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return 0, entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// lock billing, so that concurrent credit notes of the same billing see each other
+	var billingID, taxAmountMinor, grandTotalAmountMinor int64
+	err = tx.QueryRow(ctx, `
+		SELECT b.id, b.currency, COALESCE((s.summary->>'tax_amount_minor')::BIGINT, 0), COALESCE((s.summary->>'grand_total_amount_minor')::BIGINT, 0)
+		FROM billings b
+		LEFT JOIN billing_summaries s ON s.external_billing_id = b.external_billing_id
+		WHERE b.id = $1
+		FOR UPDATE OF b
+	`, creditNote.BillingID).Scan(&billingID, &creditNote.Currency, &taxAmountMinor, &grandTotalAmountMinor)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Billing not found")
+			return 0, entities.ErrBillingNotFound
+		}
+
+		logger.Error("failed to lock billing", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	// check the credit note fits in what is left of the billing
+	var creditedAmountMinor int64
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(total_amount_minor), 0) FROM credit_notes WHERE billing_id = $1
+	`, creditNote.BillingID).Scan(&creditedAmountMinor)
+	if err != nil {
+		logger.Error("failed to get credited amount", "error", err)
+		return 0, entities.ErrDBService
+	}
+	if !entities.NewCreditedBalance(billedAmountMinor, creditedAmountMinor).CanCredit(creditNote.TotalAmountMinor) {
+		logger.Warn("credit note exceeds the remaining balance", "billedAmountMinor", billedAmountMinor, "creditedAmountMinor", creditedAmountMinor)
+		return 0, entities.ErrCreditNoteExceedsBalance
+	}
+
+	// number credit note
+	var sequence int64
+	err = tx.QueryRow(ctx, `SELECT nextval('credit_note_number_seq')`).Scan(&sequence)
+	if err != nil {
+		logger.Error("failed to get next credit note number", "error", err)
+		return 0, entities.ErrDBService
+	}
+	creditNote.Number = entities.CreditNoteNumber(sequence)
+
+	// insert credit note into database
+	var creditNoteID int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO credit_notes (external_credit_note_id, number, billing_id, reason, total_amount_minor)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, creditNote.ExternalCreditNoteID, creditNote.Number, creditNote.BillingID, creditNote.Reason, creditNote.TotalAmountMinor).Scan(&creditNoteID, &creditNote.CreatedAt)
+	if err != nil {
+		logger.Error("failed to create credit note in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	// insert line items into database
+	for _, lineItem := range creditNote.LineItems {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO credit_note_line_items (credit_note_id, description, amount_minor, reference_line_item_id)
+			VALUES ($1, $2, $3, NULLIF($4, '')::UUID)
+		`, creditNoteID, lineItem.Description, lineItem.AmountMinor, lineItem.ReferenceLineItemID)
+		if err != nil {
+			logger.Error("failed to create credit note line item in database", "error", err)
+			return 0, entities.ErrDBService
+		}
+	}
+
+	// post the credited amount to the ledger
+	creditNote.ID = creditNoteID
+	err = insertJournalEntry(ctx, tx, entities.NewCreditNoteJournalEntry(creditNote, taxAmountMinor, grandTotalAmountMinor))
+	if err != nil {
+		logger.Error("failed to post credit note to ledger", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit credit note", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("credit note created successfully", "number", creditNote.Number)
+
+	return creditNoteID, nil
+}
+
+func (r *postgresCreditNoteRepository) GetCreditNoteByExternalID(ctx context.Context, externalCreditNoteID string) (*entities.CreditNote, error) {
+	fn := "infrastructure.persistence.postgresCreditNoteRepository.GetCreditNoteByExternalID"
+	logger := rlog.With("fn", fn).With("externalCreditNoteID", externalCreditNoteID)
+
+	var creditNote entities.CreditNote
+
+	// get credit note with its billing from database
+	err := r.db.QueryRow(ctx, `
+		SELECT c.id, c.external_credit_note_id, c.number, c.billing_id, b.external_billing_id, b.currency, b.currency_precision, c.reason, c.total_amount_minor, c.created_at
+		FROM credit_notes c JOIN billings b ON b.id = c.billing_id
+		WHERE c.external_credit_note_id = $1
+	`, externalCreditNoteID).Scan(&creditNote.ID, &creditNote.ExternalCreditNoteID, &creditNote.Number, &creditNote.BillingID, &creditNote.ExternalBillingID, &creditNote.Currency, &creditNote.CurrencyPrecision, &creditNote.Reason, &creditNote.TotalAmountMinor, &creditNote.CreatedAt)
+	if err != nil {
+		// no rows found
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Credit note not found")
+			return nil, entities.ErrCreditNoteNotFound
+		}
+
+		// unknown error
+		logger.Error("Failed to get credit note by external ID", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	lineItems, err := r.getLineItems(ctx, logger, creditNote.ID)
+	if err != nil {
+		return nil, err
+	}
+	creditNote.LineItems = lineItems
+
+	return &creditNote, nil
+}
+
+func (r *postgresCreditNoteRepository) ListCreditNotesByBillingID(ctx context.Context, billingID int64) ([]entities.CreditNote, error) {
+	fn := "infrastructure.persistence.postgresCreditNoteRepository.ListCreditNotesByBillingID"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	// get credit notes of billing from database
+	rows, err := r.db.Query(ctx, `
+		SELECT c.id, c.external_credit_note_id, c.number, c.billing_id, b.external_billing_id, b.currency, b.currency_precision, c.reason, c.total_amount_minor, c.created_at
+		FROM credit_notes c JOIN billings b ON b.id = c.billing_id
+		WHERE c.billing_id = $1
+		ORDER BY c.id
+	`, billingID)
+	if err != nil {
+		logger.Error("Failed to list credit notes", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	creditNotes := []entities.CreditNote{}
+	for rows.Next() {
+		var creditNote entities.CreditNote
+		err = rows.Scan(&creditNote.ID, &creditNote.ExternalCreditNoteID, &creditNote.Number, &creditNote.BillingID, &creditNote.ExternalBillingID, &creditNote.Currency, &creditNote.CurrencyPrecision, &creditNote.Reason, &creditNote.TotalAmountMinor, &creditNote.CreatedAt)
+		if err != nil {
+			logger.Error("Failed to scan credit note", "error", err)
+			return nil, entities.ErrDBService
+		}
+		creditNotes = append(creditNotes, creditNote)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Failed to list credit notes", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	for i := range creditNotes {
+		lineItems, err := r.getLineItems(ctx, logger, creditNotes[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		creditNotes[i].LineItems = lineItems
+	}
+
+	return creditNotes, nil
+}
+
+func (r *postgresCreditNoteRepository) getLineItems(ctx context.Context, logger rlog.Ctx, creditNoteID int64) ([]entities.CreditNoteLineItem, error) {
+	// get line items of credit note from database
+	rows, err := r.db.Query(ctx, `
+		SELECT description, amount_minor, COALESCE(reference_line_item_id::TEXT, '') FROM credit_note_line_items WHERE credit_note_id = $1 ORDER BY id
+	`, creditNoteID)
+	if err != nil {
+		logger.Error("Failed to get credit note line items", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	lineItems := []entities.CreditNoteLineItem{}
+	for rows.Next() {
+		var lineItem entities.CreditNoteLineItem
+		err = rows.Scan(&lineItem.Description, &lineItem.AmountMinor, &lineItem.ReferenceLineItemID)
+		if err != nil {
+			logger.Error("Failed to scan credit note line item", "error", err)
+			return nil, entities.ErrDBService
+		}
+		lineItems = append(lineItems, lineItem)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Failed to get credit note line items", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return lineItems, nil
+}
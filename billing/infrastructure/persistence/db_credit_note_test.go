@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresCreditNoteRepository_CreateCreditNote(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresCreditNoteRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	billing := createTestBilling(t, ctx, billingRepo)
+
+	newCreditNote := func(amountMinor int64) *entities.CreditNote {
+		creditNote := &entities.CreditNote{
+			ExternalCreditNoteID: uuid.NewString(),
+			BillingID:            billing.ID,
+			Reason:               "refund",
+			LineItems:            []entities.CreditNoteLineItem{{Description: "Seat", AmountMinor: amountMinor}},
+		}
+		if err := creditNote.Validate(); err != nil {
+			t.Fatalf("Validate failed: %v", err)
+		}
+		return creditNote
+	}
+
+	first := newCreditNote(600)
+	_, err := repo.CreateCreditNote(ctx, first, 1000)
+	if err != nil {
+		t.Fatalf("CreateCreditNote failed: %v", err)
+	}
+	if first.Number == "" {
+		t.Errorf("Expected credit note to be numbered")
+	}
+
+	// Test credit note beyond the remaining balance
+	_, err = repo.CreateCreditNote(ctx, newCreditNote(401), 1000)
+	if !errors.Is(err, entities.ErrCreditNoteExceedsBalance) {
+		t.Errorf("Expected ErrCreditNoteExceedsBalance, got: %v", err)
+	}
+
+	second := newCreditNote(400)
+	_, err = repo.CreateCreditNote(ctx, second, 1000)
+	if err != nil {
+		t.Fatalf("CreateCreditNote failed: %v", err)
+	}
+	if second.Number == first.Number {
+		t.Errorf("Expected distinct numbers, got %v twice", second.Number)
+	}
+
+	creditNote, err := repo.GetCreditNoteByExternalID(ctx, first.ExternalCreditNoteID)
+	if err != nil {
+		t.Fatalf("GetCreditNoteByExternalID failed: %v", err)
+	}
+	if creditNote.ExternalBillingID != billing.ExternalBillingID || creditNote.TotalAmountMinor != 600 || len(creditNote.LineItems) != 1 {
+		t.Errorf("Unexpected credit note: %+v", creditNote)
+	}
+
+	creditNotes, err := repo.ListCreditNotesByBillingID(ctx, billing.ID)
+	if err != nil {
+		t.Fatalf("ListCreditNotesByBillingID failed: %v", err)
+	}
+	if len(creditNotes) != 2 {
+		t.Errorf("Expected 2 credit notes, got %d", len(creditNotes))
+	}
+
+	// Test not found
+	_, err = repo.GetCreditNoteByExternalID(ctx, uuid.NewString())
+	if !errors.Is(err, entities.ErrCreditNoteNotFound) {
+		t.Errorf("Expected ErrCreditNoteNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresDunningRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresDunningRepository(db *sqldb.Database) repositories.DunningRepository {
+	return &postgresDunningRepository{db: db}
+}
+
+func (r *postgresDunningRepository) CreateDunning(ctx context.Context, dunning *entities.Dunning) (*entities.Dunning, error) {
+	fn := "infrastructure.persistence.postgresDunningRepository.CreateDunning"
+	logger := rlog.With("fn", fn).With("billingID", dunning.BillingID).With("scheduleDays", dunning.ScheduleDays)
+
+	// insert dunning into database, the dunning of a billing is started once
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO dunnings (billing_id, schedule_days)
+		VALUES ($1, $2)
+		ON CONFLICT (billing_id) DO NOTHING
+	`, dunning.BillingID, dunning.ScheduleDays)
+	if err != nil {
+		logger.Error("failed to create dunning in database", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	created, err := r.GetDunningByBillingID(ctx, dunning.BillingID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("dunning created successfully", "dunningID", created.ID)
+
+	return created, nil
+}
+
+func (r *postgresDunningRepository) GetDunningByBillingID(ctx context.Context, billingID int64) (*entities.Dunning, error) {
+	fn := "infrastructure.persistence.postgresDunningRepository.GetDunningByBillingID"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	var dunning entities.Dunning
+	err := r.db.QueryRow(ctx, `
+		SELECT d.id, d.billing_id, b.external_billing_id, d.status, d.step, d.schedule_days, d.started_at, d.updated_at
+		FROM dunnings d JOIN billings b ON b.id = d.billing_id
+		WHERE d.billing_id = $1
+	`, billingID).Scan(&dunning.ID, &dunning.BillingID, &dunning.ExternalBillingID, &dunning.Status, &dunning.Step, &dunning.ScheduleDays, &dunning.StartedAt, &dunning.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("dunning not found")
+			return nil, entities.ErrDunningNotFound
+		}
+
+		logger.Error("failed to get dunning", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return &dunning, nil
+}
+
+func (r *postgresDunningRepository) AdvanceDunning(ctx context.Context, billingID int64, step int, status entities.DunningStatus, outstandingAmountMinor int64, occurredAt time.Time) error {
+	fn := "infrastructure.persistence.postgresDunningRepository.AdvanceDunning"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("step", step).With("status", status)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// move dunning to the step, a retried activity finds the step already reached
+	result, err := tx.Exec(ctx, `
+		UPDATE dunnings SET step = $1, status = $2, updated_at = timezone('utc', now())
+		WHERE billing_id = $3 AND step < $1 AND status NOT IN ('resolved', 'uncollectible')
+	`, step, status, billingID)
+	if err != nil {
+		logger.Error("failed to advance dunning in database", "error", err)
+		return entities.ErrDBService
+	}
+	if result.RowsAffected() == 0 {
+		logger.Info("dunning already advanced or ended")
+		return nil
+	}
+
+	// write payment reminder to the outbox
+	var billing entities.Billing
+	err = tx.QueryRow(ctx, `
+		SELECT external_billing_id, currency, invoice_number FROM billings WHERE id = $1
+	`, billingID).Scan(&billing.ExternalBillingID, &billing.Currency, &billing.InvoiceNumber)
+	if err != nil {
+		logger.Error("failed to get billing", "error", err)
+		return entities.ErrDBService
+	}
+
+	event := entities.NewBillingPaymentReminderEvent(billing, step, status, outstandingAmountMinor, occurredAt)
+	err = insertOutboxMessage(ctx, tx, event.EventID, event.Type, event)
+	if err != nil {
+		logger.Error("failed to write payment reminder event to outbox", "error", err)
+		return entities.ErrDBService
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit dunning step", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("dunning advanced successfully")
+
+	return nil
+}
+
+func (r *postgresDunningRepository) ResolveDunning(ctx context.Context, billingID int64) error {
+	fn := "infrastructure.persistence.postgresDunningRepository.ResolveDunning"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE dunnings SET status = 'resolved', updated_at = timezone('utc', now())
+		WHERE billing_id = $1 AND status NOT IN ('resolved', 'uncollectible')
+	`, billingID)
+	if err != nil {
+		logger.Error("failed to resolve dunning in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("dunning resolved successfully")
+
+	return nil
+}
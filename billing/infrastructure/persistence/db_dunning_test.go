@@ -0,0 +1,81 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresDunningRepository_AdvanceDunning(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresDunningRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	billing := createTestBilling(t, ctx, billingRepo)
+	otherBilling := createTestBilling(t, ctx, billingRepo)
+
+	// the dunning of a billing is created once
+	dunning, err := repo.CreateDunning(ctx, &entities.Dunning{BillingID: billing.ID, ScheduleDays: entities.DefaultDunningScheduleDays})
+	if err != nil {
+		t.Fatalf("CreateDunning failed: %v", err)
+	}
+	again, err := repo.CreateDunning(ctx, &entities.Dunning{BillingID: billing.ID, ScheduleDays: []int{5}})
+	if err != nil {
+		t.Fatalf("CreateDunning failed: %v", err)
+	}
+	if again.ID != dunning.ID || !slices.Equal(again.ScheduleDays, entities.DefaultDunningScheduleDays) {
+		t.Errorf("CreateDunning() = %+v, expected %+v", again, dunning)
+	}
+	if dunning.Status != entities.DunningStatusActive || dunning.Step != 0 || dunning.ExternalBillingID != billing.ExternalBillingID {
+		t.Errorf("Unexpected dunning: %+v", dunning)
+	}
+
+	// a step reached again writes its reminder once
+	for i := 0; i < 2; i++ {
+		if err = repo.AdvanceDunning(ctx, billing.ID, 1, entities.DunningStatusPastDue, 1000, time.Now().UTC()); err != nil {
+			t.Fatalf("AdvanceDunning failed: %v", err)
+		}
+	}
+	dunning, err = repo.GetDunningByBillingID(ctx, billing.ID)
+	if err != nil {
+		t.Fatalf("GetDunningByBillingID failed: %v", err)
+	}
+	if dunning.Step != 1 || dunning.Status != entities.DunningStatusPastDue {
+		t.Errorf("Unexpected dunning: %+v", dunning)
+	}
+
+	var reminders int
+	err = db.QueryRow(ctx, `SELECT COUNT(*) FROM outbox WHERE event_type = $1`, entities.BillingEventPaymentReminder).Scan(&reminders)
+	if err != nil {
+		t.Fatalf("failed to count payment reminders: %v", err)
+	}
+	if reminders != 1 {
+		t.Errorf("Expected 1 payment reminder, got %d", reminders)
+	}
+
+	// a resolved dunning is not advanced
+	if err = repo.ResolveDunning(ctx, billing.ID); err != nil {
+		t.Fatalf("ResolveDunning failed: %v", err)
+	}
+	if err = repo.AdvanceDunning(ctx, billing.ID, 2, entities.DunningStatusPastDue, 1000, time.Now().UTC()); err != nil {
+		t.Fatalf("AdvanceDunning failed: %v", err)
+	}
+	dunning, err = repo.GetDunningByBillingID(ctx, billing.ID)
+	if err != nil {
+		t.Fatalf("GetDunningByBillingID failed: %v", err)
+	}
+	if dunning.Step != 1 || dunning.Status != entities.DunningStatusResolved {
+		t.Errorf("Unexpected dunning: %+v", dunning)
+	}
+
+	_, err = repo.GetDunningByBillingID(ctx, otherBilling.ID)
+	if !errors.Is(err, entities.ErrDunningNotFound) {
+		t.Errorf("Expected ErrDunningNotFound, got: %v", err)
+	}
+}
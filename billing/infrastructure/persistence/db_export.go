@@ -0,0 +1,69 @@
+package persistence
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresExportRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresExportRepository(db *sqldb.Database) repositories.ExportRepository {
+	return &postgresExportRepository{db: db}
+}
+
+func (r *postgresExportRepository) StreamExportRows(ctx context.Context, filter entities.ExportFilter, handleRow func(row *entities.ExportRow) error) error {
+	fn := "infrastructure.persistence.postgresExportRepository.StreamExportRows"
+	logger := rlog.With("fn", fn).With("from", filter.From).With("to", filter.To).With("userID", filter.UserID).With("currency", filter.Currency).With("status", filter.Status)
+
+	// rows are scanned one at a time as the database sends them, so exports never hold more than a row in memory
+	rows, err := r.db.Query(ctx, `
+		SELECT b.external_billing_id::TEXT, b.user_id, b.user_group, b.description, b.currency::TEXT, b.currency_precision, b.status::TEXT,
+			COALESCE(b.invoice_number, ''), b.created_at, b.actual_closed_at,
+			COALESCE(l.external_line_item_id::TEXT, ''), l.kind::TEXT, l.description, l.amount_minor, l.tax_code, l.reason,
+			COALESCE(l.reference_line_item_id::TEXT, ''), l.created_at
+		FROM billings b JOIN line_items l ON l.billing_id = b.id
+		WHERE b.created_at >= $1 AND b.created_at < $2
+			AND ($3 = '' OR b.user_id = $3)
+			AND ($4 = '' OR b.currency::TEXT = $4)
+			AND ($5 = '' OR b.status::TEXT = $5)
+		ORDER BY b.id, l.id
+	`, filter.From, filter.To, filter.UserID, filter.Currency, filter.Status)
+	if err != nil {
+		logger.Error("Failed to query export rows", "error", err)
+		return entities.ErrDBService
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		var row entities.ExportRow
+		err = rows.Scan(&row.BillingID, &row.UserID, &row.UserGroup, &row.BillingDescription, &row.Currency, &row.CurrencyPrecision, &row.Status,
+			&row.InvoiceNumber, &row.BillingCreatedAt, &row.BillingClosedAt,
+			&row.LineItemID, &row.Kind, &row.Description, &row.AmountMinor, &row.TaxCode, &row.Reason,
+			&row.ReferenceLineItemID, &row.LineItemCreatedAt)
+		if err != nil {
+			logger.Error("Failed to scan export row", "error", err)
+			return entities.ErrDBService
+		}
+
+		if err = handleRow(&row); err != nil {
+			return err
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Failed to stream export rows", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("export rows streamed successfully", "count", count)
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresExportRepository_StreamExportRows(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresExportRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	billing := createTestBilling(t, ctx, billingRepo)
+	for _, amountMinor := range []int64{1000, 250} {
+		lineItemID, _ := uuid.NewV7()
+		err := billingRepo.AddLineItem(ctx, billing.ID, &entities.LineItem{LineItemID: lineItemID.String(), Kind: entities.LineItemKindCharge, Description: "Seat", AmountMinor: amountMinor})
+		if err != nil {
+			t.Fatalf("AddLineItem failed: %v", err)
+		}
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name     string
+		filter   entities.ExportFilter
+		expected int
+	}{
+		{name: "date range", filter: entities.ExportFilter{From: now.Add(-time.Hour), To: now.Add(time.Hour)}, expected: 2},
+		{name: "user and status", filter: entities.ExportFilter{From: now.Add(-time.Hour), To: now.Add(time.Hour), UserID: billing.UserID, Status: entities.BillingStatusOpen}, expected: 2},
+		{name: "other currency", filter: entities.ExportFilter{From: now.Add(-time.Hour), To: now.Add(time.Hour), Currency: "EUR"}, expected: 0},
+		{name: "closed billings", filter: entities.ExportFilter{From: now.Add(-time.Hour), To: now.Add(time.Hour), Status: entities.BillingStatusClosed}, expected: 0},
+		{name: "earlier range", filter: entities.ExportFilter{From: now.Add(-2 * time.Hour), To: now.Add(-time.Hour)}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := []entities.ExportRow{}
+			err := repo.StreamExportRows(ctx, tt.filter, func(row *entities.ExportRow) error {
+				rows = append(rows, *row)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("StreamExportRows failed: %v", err)
+			}
+			if len(rows) != tt.expected {
+				t.Fatalf("StreamExportRows() streamed %d rows, expected %d", len(rows), tt.expected)
+			}
+			if tt.expected > 0 && (rows[0].BillingID != billing.ExternalBillingID || rows[0].AmountMinor != 1000 || rows[1].AmountMinor != 250) {
+				t.Errorf("Unexpected rows: %+v", rows)
+			}
+		})
+	}
+
+	// Test stopping the stream
+	errStop := errors.New("stop")
+	err := repo.StreamExportRows(ctx, tests[0].filter, func(row *entities.ExportRow) error {
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("Expected errStop, got: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresInvoiceNumberRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresInvoiceNumberRepository(db *sqldb.Database) repositories.InvoiceNumberRepository {
+	return &postgresInvoiceNumberRepository{db: db}
+}
+
+func (r *postgresInvoiceNumberRepository) UpsertInvoiceNumberSeries(ctx context.Context, series *entities.InvoiceNumberSeries) error {
+	fn := "infrastructure.persistence.postgresInvoiceNumberRepository.UpsertInvoiceNumberSeries"
+	logger := rlog.With("fn", fn).With("userGroup", series.UserGroup).With("prefix", series.Prefix).With("padding", series.Padding)
+
+	// a prefix belongs to a single user group, otherwise two groups would share a sequence
+	var userGroup string
+	err := r.db.QueryRow(ctx, `
+		SELECT user_group FROM invoice_number_series WHERE prefix = $1 AND user_group <> $2
+	`, series.Prefix, series.UserGroup).Scan(&userGroup)
+	if err == nil {
+		logger.Warn("Invoice number prefix is used by another user group", "otherUserGroup", userGroup)
+		return entities.ErrInvoicePrefixTaken
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		logger.Error("failed to check invoice number prefix", "error", err)
+		return entities.ErrDBService
+	}
+
+	// insert or update invoice number series in database
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO invoice_number_series (user_group, prefix, padding)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_group) DO UPDATE SET prefix = EXCLUDED.prefix, padding = EXCLUDED.padding, updated_at = timezone('utc', now())
+	`, series.UserGroup, series.Prefix, series.Padding)
+	if err != nil {
+		logger.Error("failed to upsert invoice number series in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("invoice number series upserted successfully")
+
+	return nil
+}
+
+func (r *postgresInvoiceNumberRepository) ListInvoiceNumberSeries(ctx context.Context) ([]entities.InvoiceNumberSeries, error) {
+	fn := "infrastructure.persistence.postgresInvoiceNumberRepository.ListInvoiceNumberSeries"
+	logger := rlog.With("fn", fn)
+
+	// get invoice number series from database
+	rows, err := r.db.Query(ctx, `
+		SELECT user_group, prefix, padding, created_at, updated_at FROM invoice_number_series ORDER BY user_group
+	`)
+	if err != nil {
+		logger.Error("Failed to list invoice number series", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	seriesList := []entities.InvoiceNumberSeries{}
+	for rows.Next() {
+		var series entities.InvoiceNumberSeries
+		err = rows.Scan(&series.UserGroup, &series.Prefix, &series.Padding, &series.CreatedAt, &series.UpdatedAt)
+		if err != nil {
+			logger.Error("Failed to scan invoice number series", "error", err)
+			return nil, entities.ErrDBService
+		}
+		seriesList = append(seriesList, series)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Failed to list invoice number series", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return seriesList, nil
+}
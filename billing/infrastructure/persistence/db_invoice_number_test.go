@@ -0,0 +1,51 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresInvoiceNumberRepository_UpsertInvoiceNumberSeries(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresInvoiceNumberRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	err := repo.UpsertInvoiceNumberSeries(ctx, &entities.InvoiceNumberSeries{UserGroup: "eu", Prefix: "EU", Padding: 4})
+	if err != nil {
+		t.Fatalf("UpsertInvoiceNumberSeries failed: %v", err)
+	}
+
+	// Test prefix of another user group
+	err = repo.UpsertInvoiceNumberSeries(ctx, &entities.InvoiceNumberSeries{UserGroup: "us", Prefix: "EU", Padding: 4})
+	if !errors.Is(err, entities.ErrInvoicePrefixTaken) {
+		t.Errorf("Expected ErrInvoicePrefixTaken, got: %v", err)
+	}
+
+	seriesList, err := repo.ListInvoiceNumberSeries(ctx)
+	if err != nil {
+		t.Fatalf("ListInvoiceNumberSeries failed: %v", err)
+	}
+	if len(seriesList) != 2 || seriesList[0].Prefix != entities.DefaultInvoiceNumberPrefix || seriesList[1].Prefix != "EU" {
+		t.Errorf("Unexpected invoice number series: %+v", seriesList)
+	}
+
+	// billings of the user group are numbered in its series
+	billing := createTestBilling(t, ctx, billingRepo)
+	_, err = db.Exec(ctx, `UPDATE billings SET user_group = 'eu' WHERE id = $1`, billing.ID)
+	if err != nil {
+		t.Fatalf("Failed to set user group: %v", err)
+	}
+	invoiceNumber, err := billingRepo.CloseBilling(ctx, billing.ID, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("CloseBilling failed: %v", err)
+	}
+	if invoiceNumber != "EU-2026-0001" {
+		t.Errorf("Expected invoice number EU-2026-0001, got %s", invoiceNumber)
+	}
+}
@@ -0,0 +1,65 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresInvoiceTemplateRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresInvoiceTemplateRepository(db *sqldb.Database) repositories.InvoiceTemplateRepository {
+	return &postgresInvoiceTemplateRepository{db: db}
+}
+
+func (r *postgresInvoiceTemplateRepository) UpsertInvoiceTemplate(ctx context.Context, invoiceTemplate *entities.InvoiceTemplate) error {
+	fn := "infrastructure.persistence.postgresInvoiceTemplateRepository.UpsertInvoiceTemplate"
+	logger := rlog.With("fn", fn).With("tenant", invoiceTemplate.Tenant).With("format", invoiceTemplate.Format)
+
+	// insert or update invoice template in database
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO invoice_templates (tenant, format, source)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant, format) DO UPDATE SET source = EXCLUDED.source, updated_at = timezone('utc', now())
+		RETURNING created_at, updated_at
+	`, invoiceTemplate.Tenant, invoiceTemplate.Format, invoiceTemplate.Source).Scan(&invoiceTemplate.CreatedAt, &invoiceTemplate.UpdatedAt)
+	if err != nil {
+		logger.Error("failed to upsert invoice template in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("invoice template upserted successfully")
+
+	return nil
+}
+
+func (r *postgresInvoiceTemplateRepository) GetInvoiceTemplate(ctx context.Context, tenant string, format entities.InvoiceFormat) (*entities.InvoiceTemplate, error) {
+	fn := "infrastructure.persistence.postgresInvoiceTemplateRepository.GetInvoiceTemplate"
+	logger := rlog.With("fn", fn).With("tenant", tenant).With("format", format)
+
+	var invoiceTemplate entities.InvoiceTemplate
+
+	// get invoice template from database
+	err := r.db.QueryRow(ctx, `
+		SELECT tenant, format, source, created_at, updated_at FROM invoice_templates WHERE tenant = $1 AND format = $2
+	`, tenant, format).Scan(&invoiceTemplate.Tenant, &invoiceTemplate.Format, &invoiceTemplate.Source, &invoiceTemplate.CreatedAt, &invoiceTemplate.UpdatedAt)
+	if err != nil {
+		// no rows found
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil, entities.ErrInvoiceTemplateNotFound
+		}
+
+		// unknown error
+		logger.Error("Failed to get invoice template", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return &invoiceTemplate, nil
+}
@@ -0,0 +1,47 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresInvoiceTemplateRepository_UpsertInvoiceTemplate(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresInvoiceTemplateRepository(db)
+
+	// Test tenant without a template
+	_, err := repo.GetInvoiceTemplate(ctx, "acme", entities.InvoiceFormatHTML)
+	if !errors.Is(err, entities.ErrInvoiceTemplateNotFound) {
+		t.Errorf("Expected ErrInvoiceTemplateNotFound, got: %v", err)
+	}
+
+	err = repo.UpsertInvoiceTemplate(ctx, &entities.InvoiceTemplate{Tenant: "acme", Format: entities.InvoiceFormatHTML, Source: "<h1>{{.InvoiceNumber}}</h1>"})
+	if err != nil {
+		t.Fatalf("UpsertInvoiceTemplate failed: %v", err)
+	}
+
+	// Test replacing the template
+	err = repo.UpsertInvoiceTemplate(ctx, &entities.InvoiceTemplate{Tenant: "acme", Format: entities.InvoiceFormatHTML, Source: "<h2>{{.InvoiceNumber}}</h2>"})
+	if err != nil {
+		t.Fatalf("UpsertInvoiceTemplate failed: %v", err)
+	}
+
+	invoiceTemplate, err := repo.GetInvoiceTemplate(ctx, "acme", entities.InvoiceFormatHTML)
+	if err != nil {
+		t.Fatalf("GetInvoiceTemplate failed: %v", err)
+	}
+	if invoiceTemplate.Source != "<h2>{{.InvoiceNumber}}</h2>" {
+		t.Errorf("Expected replaced template, got %s", invoiceTemplate.Source)
+	}
+
+	// templates are per format
+	_, err = repo.GetInvoiceTemplate(ctx, "acme", entities.InvoiceFormatPDF)
+	if !errors.Is(err, entities.ErrInvoiceTemplateNotFound) {
+		t.Errorf("Expected ErrInvoiceTemplateNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresLedgerRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresLedgerRepository(db *sqldb.Database) repositories.LedgerRepository {
+	return &postgresLedgerRepository{db: db}
+}
+
+func (r *postgresLedgerRepository) ListAccountBalances(ctx context.Context, currency string) ([]entities.LedgerAccountBalance, error) {
+	fn := "infrastructure.persistence.postgresLedgerRepository.ListAccountBalances"
+	logger := rlog.With("fn", fn).With("currency", currency)
+
+	rows, err := r.db.Query(ctx, `
+		SELECT a.code, a.name, a.type, a.normal_balance, e.currency,
+			COALESCE(SUM(p.amount_minor) FILTER (WHERE p.direction = 'debit'), 0),
+			COALESCE(SUM(p.amount_minor) FILTER (WHERE p.direction = 'credit'), 0)
+		FROM ledger_postings p
+		JOIN journal_entries e ON e.id = p.journal_entry_id
+		JOIN ledger_accounts a ON a.code = p.account_code
+		WHERE $1 = '' OR e.currency::TEXT = $1
+		GROUP BY a.code, e.currency
+		ORDER BY a.code, e.currency
+	`, currency)
+	if err != nil {
+		logger.Error("failed to list account balances", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	balances := []entities.LedgerAccountBalance{}
+	for rows.Next() {
+		var account entities.LedgerAccount
+		var accountCurrency string
+		var debitAmountMinor, creditAmountMinor int64
+		err = rows.Scan(&account.Code, &account.Name, &account.Type, &account.NormalBalance, &accountCurrency, &debitAmountMinor, &creditAmountMinor)
+		if err != nil {
+			logger.Error("failed to scan account balance", "error", err)
+			return nil, entities.ErrDBService
+		}
+		balances = append(balances, entities.NewLedgerAccountBalance(account, accountCurrency, debitAmountMinor, creditAmountMinor))
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("failed to list account balances", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return balances, nil
+}
+
+func (r *postgresLedgerRepository) ListJournalEntriesByBillingID(ctx context.Context, billingID int64) ([]entities.JournalEntry, error) {
+	fn := "infrastructure.persistence.postgresLedgerRepository.ListJournalEntriesByBillingID"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	rows, err := r.db.Query(ctx, `
+		SELECT e.id, e.external_entry_id, e.kind, e.source_id, e.billing_id, e.currency, e.description, e.posted_at, e.created_at,
+			p.account_code, p.direction, p.amount_minor
+		FROM journal_entries e
+		JOIN ledger_postings p ON p.journal_entry_id = e.id
+		WHERE e.billing_id = $1
+		ORDER BY e.posted_at, e.id, p.id
+	`, billingID)
+	if err != nil {
+		logger.Error("failed to list journal entries", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	entries := []entities.JournalEntry{}
+	for rows.Next() {
+		var entry entities.JournalEntry
+		var posting entities.LedgerPosting
+		err = rows.Scan(&entry.ID, &entry.ExternalEntryID, &entry.Kind, &entry.SourceID, &entry.BillingID, &entry.Currency, &entry.Description, &entry.PostedAt, &entry.CreatedAt,
+			&posting.AccountCode, &posting.Direction, &posting.AmountMinor)
+		if err != nil {
+			logger.Error("failed to scan journal entry", "error", err)
+			return nil, entities.ErrDBService
+		}
+
+		// postings of an entry are consecutive rows
+		if len(entries) == 0 || entries[len(entries)-1].ID != entry.ID {
+			entries = append(entries, entry)
+		}
+		last := &entries[len(entries)-1]
+		last.Postings = append(last.Postings, posting)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("failed to list journal entries", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return entries, nil
+}
+
+func (r *postgresLedgerRepository) CheckLedger(ctx context.Context) (*entities.LedgerCheck, error) {
+	fn := "infrastructure.persistence.postgresLedgerRepository.CheckLedger"
+	logger := rlog.With("fn", fn)
+
+	check := &entities.LedgerCheck{
+		Totals:                     []entities.LedgerTotals{},
+		UnbalancedExternalEntryIDs: []string{},
+	}
+
+	// both queries read the same snapshot, so that an entry posted in between is seen by both or neither
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY`)
+	if err != nil {
+		logger.Error("failed to set transaction isolation level", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT e.currency,
+			COALESCE(SUM(p.amount_minor) FILTER (WHERE p.direction = 'debit'), 0),
+			COALESCE(SUM(p.amount_minor) FILTER (WHERE p.direction = 'credit'), 0)
+		FROM ledger_postings p
+		JOIN journal_entries e ON e.id = p.journal_entry_id
+		GROUP BY e.currency
+		ORDER BY e.currency
+	`)
+	if err != nil {
+		logger.Error("failed to total ledger", "error", err)
+		return nil, entities.ErrDBService
+	}
+	for rows.Next() {
+		var totals entities.LedgerTotals
+		if err = rows.Scan(&totals.Currency, &totals.DebitAmountMinor, &totals.CreditAmountMinor); err != nil {
+			rows.Close()
+			logger.Error("failed to scan ledger totals", "error", err)
+			return nil, entities.ErrDBService
+		}
+		check.Totals = append(check.Totals, totals)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		logger.Error("failed to total ledger", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	// an entry without postings moves nothing and is never posted, so it is unbalanced too
+	rows, err = tx.Query(ctx, `
+		SELECT e.external_entry_id
+		FROM journal_entries e
+		LEFT JOIN ledger_postings p ON p.journal_entry_id = e.id
+		GROUP BY e.id
+		HAVING COUNT(p.id) = 0
+			OR COALESCE(SUM(p.amount_minor) FILTER (WHERE p.direction = 'debit'), 0) <> COALESCE(SUM(p.amount_minor) FILTER (WHERE p.direction = 'credit'), 0)
+		ORDER BY e.id
+	`)
+	if err != nil {
+		logger.Error("failed to list unbalanced journal entries", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var externalEntryID string
+		if err = rows.Scan(&externalEntryID); err != nil {
+			logger.Error("failed to scan unbalanced journal entry", "error", err)
+			return nil, entities.ErrDBService
+		}
+		check.UnbalancedExternalEntryIDs = append(check.UnbalancedExternalEntryIDs, externalEntryID)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("failed to list unbalanced journal entries", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return check, nil
+}
+
+// insertJournalEntry posts an entry in the transaction of the operation it records, an operation posted again by a
+// retried activity keeps its first entry. An entry that moves nothing is not posted, one without a billing has none.
+func insertJournalEntry(ctx context.Context, tx *sqldb.Tx, entry *entities.JournalEntry) error {
+	if entry.IsEmpty() {
+		return nil
+	}
+	if !entry.IsBalanced() {
+		return entities.ErrUnbalancedJournalEntry
+	}
+
+	err := tx.QueryRow(ctx, `
+		INSERT INTO journal_entries (kind, source_id, billing_id, currency, description, posted_at)
+		VALUES ($1, $2, NULLIF($3::BIGINT, 0), $4, $5, $6)
+		ON CONFLICT (kind, source_id) DO NOTHING
+		RETURNING id, external_entry_id, created_at
+	`, entry.Kind, entry.SourceID, entry.BillingID, entry.Currency, entry.Description, entry.PostedAt).Scan(&entry.ID, &entry.ExternalEntryID, &entry.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	for _, posting := range entry.Postings {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO ledger_postings (journal_entry_id, account_code, direction, amount_minor)
+			VALUES ($1, $2, $3, $4)
+		`, entry.ID, posting.AccountCode, posting.Direction, posting.AmountMinor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
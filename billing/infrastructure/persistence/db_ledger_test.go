@@ -0,0 +1,146 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresLedgerRepository_PostsBillingOperations(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresLedgerRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+	paymentRepo := NewPostgresPaymentRepository(db)
+	refundRepo := NewPostgresRefundRepository(db)
+	creditNoteRepo := NewPostgresCreditNoteRepository(db)
+
+	billing := createClosedTestBilling(t, ctx, billingRepo, 1000)
+
+	// a summary written again by a retried activity is posted once, a different summary is refused and not posted
+	summary := testBillingSummary(t, billing, 1000)
+	if _, err := billingRepo.CreateBillingSummary(ctx, billing.ExternalBillingID, summary, nil); err != nil {
+		t.Fatalf("CreateBillingSummary failed: %v", err)
+	}
+	changedSummary := testBillingSummary(t, billing, 2000)
+	if _, err := billingRepo.CreateBillingSummary(ctx, billing.ExternalBillingID, changedSummary, nil); !errors.Is(err, entities.ErrBillingSummaryMismatch) {
+		t.Errorf("Expected %v, got %v", entities.ErrBillingSummaryMismatch, err)
+	}
+	stored, err := billingRepo.GetBillingSummary(ctx, billing.ExternalBillingID)
+	if err != nil {
+		t.Fatalf("GetBillingSummary failed: %v", err)
+	}
+	if stored.GrandTotalAmountMinor != 1000 {
+		t.Errorf("Expected the first summary to be kept, got grand total %v", stored.GrandTotalAmountMinor)
+	}
+
+	payment := &entities.Payment{
+		ExternalPaymentID: uuid.NewString(),
+		BillingID:         billing.ID,
+		AmountMinor:       1000,
+		Currency:          "USD",
+		Method:            entities.PaymentMethodCard,
+		ExternalReference: "ch_ledger",
+		PaidAt:            time.Now().UTC(),
+	}
+	if _, err := paymentRepo.CreatePayment(ctx, payment); err != nil {
+		t.Fatalf("CreatePayment failed: %v", err)
+	}
+
+	creditNote := &entities.CreditNote{
+		ExternalCreditNoteID: uuid.NewString(),
+		BillingID:            billing.ID,
+		Reason:               "discount",
+		LineItems:            []entities.CreditNoteLineItem{{Description: "Seat", AmountMinor: 300}},
+		TotalAmountMinor:     300,
+	}
+	if _, err := creditNoteRepo.CreateCreditNote(ctx, creditNote, 1000); err != nil {
+		t.Fatalf("CreateCreditNote failed: %v", err)
+	}
+
+	refund, err := refundRepo.CreateRefund(ctx, &entities.Refund{
+		ExternalRefundID: uuid.NewString(),
+		PaymentID:        payment.ID,
+		AmountMinor:      300,
+		Reason:           "discount",
+		IdempotencyKey:   "refund-ledger",
+	})
+	if err != nil {
+		t.Fatalf("CreateRefund failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err = refundRepo.CompleteRefund(ctx, refund.ID, entities.RefundStatusSucceeded, ""); err != nil {
+			t.Fatalf("CompleteRefund failed: %v", err)
+		}
+	}
+
+	entries, err := repo.ListJournalEntriesByBillingID(ctx, billing.ID)
+	if err != nil {
+		t.Fatalf("ListJournalEntriesByBillingID failed: %v", err)
+	}
+	kinds := []entities.JournalEntryKind{}
+	for _, entry := range entries {
+		if !entry.IsBalanced() {
+			t.Errorf("Expected %v entry to be balanced, got %+v", entry.Kind, entry.Postings)
+		}
+		kinds = append(kinds, entry.Kind)
+	}
+	expectedKinds := []entities.JournalEntryKind{
+		entities.JournalEntryKindBillingClosed,
+		entities.JournalEntryKindPaymentRecorded,
+		entities.JournalEntryKindCreditNoteIssued,
+		entities.JournalEntryKindRefundSucceeded,
+	}
+	if len(kinds) != len(expectedKinds) {
+		t.Fatalf("Expected entries %v, got %v", expectedKinds, kinds)
+	}
+	for i := range expectedKinds {
+		if kinds[i] != expectedKinds[i] {
+			t.Errorf("Expected entries %v, got %v", expectedKinds, kinds)
+			break
+		}
+	}
+
+	// billed 1000, credited 300, paid 1000 and refunded 300: nothing is owed and 700 was kept
+	balances, err := repo.ListAccountBalances(ctx, "USD")
+	if err != nil {
+		t.Fatalf("ListAccountBalances failed: %v", err)
+	}
+	byAccount := map[entities.LedgerAccountCode]int64{}
+	for _, balance := range balances {
+		byAccount[balance.Code] = balance.BalanceMinor
+	}
+	expectedBalances := map[entities.LedgerAccountCode]int64{
+		entities.LedgerAccountReceivable:   0,
+		entities.LedgerAccountCash:         700,
+		entities.LedgerAccountRevenue:      1000,
+		entities.LedgerAccountSalesReturns: 300,
+	}
+	for code, expected := range expectedBalances {
+		if byAccount[code] != expected {
+			t.Errorf("Expected %v balance %v, got %v", code, expected, byAccount[code])
+		}
+	}
+
+	check, err := repo.CheckLedger(ctx)
+	if err != nil {
+		t.Fatalf("CheckLedger failed: %v", err)
+	}
+	if !check.IsBalanced() {
+		t.Errorf("Expected ledger to be balanced, got %+v", check)
+	}
+
+	// the ledger is append-only
+	if _, err = db.Exec(ctx, `DELETE FROM ledger_postings`); err == nil {
+		t.Errorf("Expected postings not to be deleted")
+	}
+	if _, err = db.Exec(ctx, `UPDATE journal_entries SET description = 'edited'`); err == nil {
+		t.Errorf("Expected journal entries not to be updated")
+	}
+}
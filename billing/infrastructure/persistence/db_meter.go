@@ -0,0 +1,246 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresMeterRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresMeterRepository(db *sqldb.Database) repositories.MeterRepository {
+	return &postgresMeterRepository{db: db}
+}
+
+func (r *postgresMeterRepository) CreateMeter(ctx context.Context, meter *entities.Meter) (int64, error) {
+	fn := "infrastructure.persistence.postgresMeterRepository.CreateMeter"
+	logger := rlog.With("fn", fn).With("code", meter.Code).With("aggregation", meter.Aggregation).With("currency", meter.Currency)
+
+	// insert meter into database
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO meters (code, name, aggregation, currency, currency_precision, unit_amount_minor)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`, meter.Code, meter.Name, meter.Aggregation, meter.Currency, meter.CurrencyPrecision, meter.UnitAmountMinor).Scan(&meter.ID, &meter.CreatedAt, &meter.UpdatedAt)
+	if err != nil {
+		// code already taken
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Meter code already exists")
+			return 0, entities.ErrMeterCodeTaken
+		}
+
+		logger.Error("failed to create meter in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("meter created successfully")
+
+	return meter.ID, nil
+}
+
+func (r *postgresMeterRepository) ListMeters(ctx context.Context) ([]entities.Meter, error) {
+	fn := "infrastructure.persistence.postgresMeterRepository.ListMeters"
+	logger := rlog.With("fn", fn)
+
+	// get meters from database
+	rows, err := r.db.Query(ctx, `
+		SELECT id, code, name, aggregation, currency, currency_precision, unit_amount_minor, created_at, updated_at FROM meters ORDER BY code
+	`)
+	if err != nil {
+		logger.Error("Failed to list meters", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	meters := []entities.Meter{}
+	for rows.Next() {
+		var meter entities.Meter
+		err = rows.Scan(&meter.ID, &meter.Code, &meter.Name, &meter.Aggregation, &meter.Currency, &meter.CurrencyPrecision, &meter.UnitAmountMinor, &meter.CreatedAt, &meter.UpdatedAt)
+		if err != nil {
+			logger.Error("Failed to scan meter", "error", err)
+			return nil, entities.ErrDBService
+		}
+		meters = append(meters, meter)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Failed to list meters", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return meters, nil
+}
+
+func (r *postgresMeterRepository) IngestUsageEvents(ctx context.Context, events []entities.UsageEvent) (int, error) {
+	fn := "infrastructure.persistence.postgresMeterRepository.IngestUsageEvents"
+	logger := rlog.With("fn", fn).With("events", len(events))
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return 0, entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// insert usage events into database, the insert is idempotent on the dedup ID of the user so that events reported again are stored once
+	ingested := 0
+	for _, event := range events {
+		result, err := tx.Exec(ctx, `
+			INSERT INTO usage_events (user_id, meter_id, quantity, occurred_at, dedup_id)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, dedup_id) DO NOTHING
+		`, event.UserID, event.MeterID, event.Quantity, event.Timestamp, event.DedupID)
+		if err != nil {
+			logger.Error("failed to ingest usage event in database", "error", err, "dedupID", event.DedupID)
+			return 0, entities.ErrDBService
+		}
+		ingested += int(result.RowsAffected())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit usage events", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("usage events ingested successfully", "ingested", ingested)
+
+	return ingested, nil
+}
+
+func (r *postgresMeterRepository) ClaimUsage(ctx context.Context, billingID int64, closedAt time.Time) ([]entities.UsageAggregate, error) {
+	fn := "infrastructure.persistence.postgresMeterRepository.ClaimUsage"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("closedAt", closedAt)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// lock billing, so that a retried claim waits for the first one
+	var billing entities.Billing
+	err = tx.QueryRow(ctx, `
+		SELECT id, external_billing_id, user_id, currency FROM billings WHERE id = $1 FOR UPDATE
+	`, billingID).Scan(&billing.ID, &billing.ExternalBillingID, &billing.UserID, &billing.Currency)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Billing not found")
+			return nil, entities.ErrBillingNotFound
+		}
+
+		logger.Error("failed to lock billing", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	// claim the unbilled usage until the end of the billing period, billings without a period claim it until their close.
+	// Late events of a period whose billing is already closed roll into this billing, events in the period of another open
+	// billing of the user in the same currency are left for it.
+	_, err = tx.Exec(ctx, `
+		UPDATE usage_events e SET billing_id = b.id
+		FROM billings b, meters m
+		WHERE b.id = $1 AND m.id = e.meter_id AND m.currency = b.currency AND e.user_id = b.user_id AND e.billing_id IS NULL
+			AND e.occurred_at < COALESCE(b.period_end, $2)
+			AND NOT EXISTS (
+				SELECT 1 FROM billings o
+				WHERE o.id <> b.id AND o.user_id = b.user_id AND o.currency = b.currency AND o.status = 'open'
+					AND e.occurred_at >= COALESCE(o.period_start, o.created_at) AND (o.period_end IS NULL OR e.occurred_at < o.period_end)
+			)
+	`, billingID, closedAt)
+	if err != nil {
+		logger.Error("failed to claim usage events", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	// aggregate the claimed usage by meter
+	aggregates, err := queryUsageAggregates(ctx, tx, `
+		SELECT m.code, m.name, m.aggregation, m.unit_amount_minor, m.currency,
+			(CASE m.aggregation
+				WHEN 'sum' THEN SUM(e.quantity)
+				WHEN 'max' THEN MAX(e.quantity)
+				ELSE (ARRAY_AGG(e.quantity ORDER BY e.occurred_at DESC, e.id DESC))[1]
+			END)::BIGINT
+		FROM usage_events e
+		JOIN meters m ON m.id = e.meter_id
+		WHERE e.billing_id = $1
+		GROUP BY m.id
+		ORDER BY m.code
+	`, billingID)
+	if err != nil {
+		logger.Error("failed to aggregate usage events", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	// report the usage of the period in another currency that no open billing of the user will claim, it is never billed
+	unbilled, err := queryUsageAggregates(ctx, tx, `
+		SELECT m.code, m.name, m.aggregation, m.unit_amount_minor, m.currency,
+			(CASE m.aggregation
+				WHEN 'sum' THEN SUM(e.quantity)
+				WHEN 'max' THEN MAX(e.quantity)
+				ELSE (ARRAY_AGG(e.quantity ORDER BY e.occurred_at DESC, e.id DESC))[1]
+			END)::BIGINT
+		FROM usage_events e
+		JOIN meters m ON m.id = e.meter_id
+		JOIN billings b ON b.id = $1
+		WHERE m.currency <> b.currency AND e.user_id = b.user_id AND e.billing_id IS NULL
+			AND e.occurred_at >= COALESCE(b.period_start, b.created_at) AND e.occurred_at < COALESCE(b.period_end, $2)
+			AND NOT EXISTS (
+				SELECT 1 FROM billings o WHERE o.user_id = b.user_id AND o.currency = m.currency AND o.status = 'open'
+			)
+		GROUP BY m.id
+		ORDER BY m.code
+	`, billingID, closedAt)
+	if err != nil {
+		logger.Error("failed to aggregate unbilled usage events", "error", err)
+		return nil, entities.ErrDBService
+	}
+	for _, usage := range unbilled {
+		logger.Warn("usage left unbilled in another currency", "meter", usage.MeterCode, "meterCurrency", usage.Currency, "quantity", usage.Quantity)
+
+		event := entities.NewBillingUsageUnbilledEvent(billing, usage, time.Now().UTC())
+		err = insertOutboxMessage(ctx, tx, event.EventID, event.Type, event)
+		if err != nil {
+			logger.Error("failed to write usage unbilled event to outbox", "error", err)
+			return nil, entities.ErrDBService
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit usage claim", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	logger.Info("usage claimed successfully", "meters", len(aggregates), "unbilledMeters", len(unbilled))
+
+	return aggregates, nil
+}
+
+// queryUsageAggregates runs a query of usage aggregates within tx
+func queryUsageAggregates(ctx context.Context, tx *sqldb.Tx, query string, args ...any) ([]entities.UsageAggregate, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aggregates := []entities.UsageAggregate{}
+	for rows.Next() {
+		var aggregate entities.UsageAggregate
+		err = rows.Scan(&aggregate.MeterCode, &aggregate.MeterName, &aggregate.Aggregation, &aggregate.UnitAmountMinor, &aggregate.Currency, &aggregate.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+	return aggregates, rows.Err()
+}
@@ -0,0 +1,225 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresMeterRepository_CreateMeter(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresMeterRepository(db)
+
+	meter := &entities.Meter{Code: "api_calls", Name: "API calls", Aggregation: entities.MeterAggregationSum, Currency: "USD", CurrencyPrecision: 2, UnitAmountMinor: 2}
+	meterID, err := repo.CreateMeter(ctx, meter)
+	if err != nil {
+		t.Fatalf("CreateMeter failed: %v", err)
+	}
+	if meterID == 0 {
+		t.Error("Expected non-zero meter ID")
+	}
+
+	// the code of a meter is taken once
+	_, err = repo.CreateMeter(ctx, &entities.Meter{Code: "api_calls", Name: "Calls", Aggregation: entities.MeterAggregationMax, Currency: "USD", CurrencyPrecision: 2})
+	if !errors.Is(err, entities.ErrMeterCodeTaken) {
+		t.Errorf("CreateMeter() = %v, expected %v", err, entities.ErrMeterCodeTaken)
+	}
+
+	meters, err := repo.ListMeters(ctx)
+	if err != nil {
+		t.Fatalf("ListMeters failed: %v", err)
+	}
+	if len(meters) != 1 || meters[0].Code != "api_calls" || meters[0].Aggregation != entities.MeterAggregationSum {
+		t.Errorf("Expected the api_calls meter, got %+v", meters)
+	}
+}
+
+func TestPostgresMeterRepository_ClaimUsage(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresMeterRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	meterIDs := map[string]int64{}
+	for _, meter := range []*entities.Meter{
+		{Code: "api_calls", Name: "API calls", Aggregation: entities.MeterAggregationSum, Currency: "USD", CurrencyPrecision: 2, UnitAmountMinor: 2},
+		{Code: "seats", Name: "Seats", Aggregation: entities.MeterAggregationMax, Currency: "USD", CurrencyPrecision: 2, UnitAmountMinor: 1000},
+		{Code: "storage_gb", Name: "Storage (GB)", Aggregation: entities.MeterAggregationLast, Currency: "USD", CurrencyPrecision: 2, UnitAmountMinor: 10},
+		{Code: "eur_calls", Name: "API calls", Aggregation: entities.MeterAggregationSum, Currency: "EUR", CurrencyPrecision: 2, UnitAmountMinor: 2},
+	} {
+		meterID, err := repo.CreateMeter(ctx, meter)
+		if err != nil {
+			t.Fatalf("CreateMeter failed: %v", err)
+		}
+		meterIDs[meter.Code] = meterID
+	}
+
+	periodStart := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	externalBillingID, _ := uuid.NewV7()
+	billing := &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: externalBillingID.String(),
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		PeriodStart:       &periodStart,
+		PeriodEnd:         &periodEnd,
+	}
+	billingID, err := billingRepo.CreateBilling(ctx, billing)
+	if err != nil {
+		t.Fatalf("CreateBilling failed: %v", err)
+	}
+
+	usage := func(userID string, meterCode string, quantity int64, timestamp time.Time, dedupID string) entities.UsageEvent {
+		return entities.UsageEvent{UserID: userID, MeterCode: meterCode, MeterID: meterIDs[meterCode], Quantity: quantity, Timestamp: timestamp, DedupID: dedupID}
+	}
+	events := []entities.UsageEvent{
+		usage("user123", "api_calls", 100, periodStart, "call-1"),
+		usage("user123", "api_calls", 50, periodStart.Add(time.Hour), "call-2"),
+		usage("user123", "seats", 3, periodStart.Add(time.Hour), "seats-1"),
+		usage("user123", "seats", 5, periodStart.Add(2*time.Hour), "seats-2"),
+		usage("user123", "seats", 4, periodStart.Add(3*time.Hour), "seats-3"),
+		usage("user123", "storage_gb", 20, periodStart.Add(time.Hour), "storage-1"),
+		usage("user123", "storage_gb", 12, periodStart.Add(48*time.Hour), "storage-2"),
+		// not in the billing: after its period, of another user and in another currency
+		usage("user123", "api_calls", 1000, periodEnd, "call-3"),
+		usage("user456", "api_calls", 1000, periodStart, "call-1"),
+		usage("user123", "eur_calls", 1000, periodStart, "eur-call-1"),
+	}
+	ingested, err := repo.IngestUsageEvents(ctx, events)
+	if err != nil {
+		t.Fatalf("IngestUsageEvents failed: %v", err)
+	}
+	if ingested != len(events) {
+		t.Errorf("Expected %d events ingested, got %d", len(events), ingested)
+	}
+
+	// events reported again are ingested once
+	ingested, err = repo.IngestUsageEvents(ctx, events[:2])
+	if err != nil {
+		t.Fatalf("IngestUsageEvents failed: %v", err)
+	}
+	if ingested != 0 {
+		t.Errorf("Expected duplicates to be skipped, got %d ingested", ingested)
+	}
+
+	expected := []entities.UsageAggregate{
+		{MeterCode: "api_calls", MeterName: "API calls", Aggregation: entities.MeterAggregationSum, Quantity: 150, UnitAmountMinor: 2, Currency: "USD"},
+		{MeterCode: "seats", MeterName: "Seats", Aggregation: entities.MeterAggregationMax, Quantity: 5, UnitAmountMinor: 1000, Currency: "USD"},
+		{MeterCode: "storage_gb", MeterName: "Storage (GB)", Aggregation: entities.MeterAggregationLast, Quantity: 12, UnitAmountMinor: 10, Currency: "USD"},
+	}
+
+	// usage is claimed while the billing closes, a retried claim returns the same aggregates
+	for i := 0; i < 2; i++ {
+		aggregates, err := repo.ClaimUsage(ctx, billingID, periodEnd)
+		if err != nil {
+			t.Fatalf("ClaimUsage failed: %v", err)
+		}
+		if len(aggregates) != len(expected) {
+			t.Fatalf("Expected %d aggregates, got %+v", len(expected), aggregates)
+		}
+		for j := range expected {
+			if aggregates[j] != expected[j] {
+				t.Errorf("ClaimUsage() aggregate %d = %+v, expected %+v", j, aggregates[j], expected[j])
+			}
+		}
+	}
+
+	// the usage in euros has no billing to claim it, it is reported once
+	var unbilled int
+	err = db.QueryRow(ctx, `SELECT COUNT(*) FROM outbox WHERE event_type = $1 AND event_id = $2`,
+		entities.BillingEventUsageUnbilled, entities.BillingEventID(entities.BillingEventUsageUnbilled, billing.ExternalBillingID, "eur_calls")).Scan(&unbilled)
+	if err != nil {
+		t.Fatalf("failed to count usage unbilled events: %v", err)
+	}
+	if unbilled != 1 {
+		t.Errorf("Expected the euro usage to be reported once, got %d events", unbilled)
+	}
+
+	_, err = repo.ClaimUsage(ctx, 0, periodEnd)
+	if !errors.Is(err, entities.ErrBillingNotFound) {
+		t.Errorf("ClaimUsage() = %v, expected %v", err, entities.ErrBillingNotFound)
+	}
+}
+
+func TestPostgresMeterRepository_ClaimUsageLateAndOverlapping(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresMeterRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	meterID, err := repo.CreateMeter(ctx, &entities.Meter{Code: "late_calls", Name: "API calls", Aggregation: entities.MeterAggregationSum, Currency: "USD", CurrencyPrecision: 2, UnitAmountMinor: 2})
+	if err != nil {
+		t.Fatalf("CreateMeter failed: %v", err)
+	}
+
+	// two open billings of the user whose periods overlap in March
+	createBilling := func(periodStart time.Time, periodEnd time.Time) int64 {
+		externalBillingID, _ := uuid.NewV7()
+		billingID, err := billingRepo.CreateBilling(ctx, &entities.Billing{
+			UserID:            "user789",
+			ExternalBillingID: externalBillingID.String(),
+			Currency:          "USD",
+			CurrencyPrecision: 2,
+			PeriodStart:       &periodStart,
+			PeriodEnd:         &periodEnd,
+		})
+		if err != nil {
+			t.Fatalf("CreateBilling failed: %v", err)
+		}
+		return billingID
+	}
+	firstBillingID := createBilling(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	secondBillingID := createBilling(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC))
+
+	usage := func(quantity int64, timestamp time.Time, dedupID string) entities.UsageEvent {
+		return entities.UsageEvent{UserID: "user789", MeterCode: "late_calls", MeterID: meterID, Quantity: quantity, Timestamp: timestamp, DedupID: dedupID}
+	}
+	_, err = repo.IngestUsageEvents(ctx, []entities.UsageEvent{
+		// before both periods, no open billing covers it
+		usage(1, time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC), "late-call-1"),
+		usage(10, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), "late-call-2"),
+		// in the period of the second billing as well
+		usage(100, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), "late-call-3"),
+		usage(1000, time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC), "late-call-4"),
+	})
+	if err != nil {
+		t.Fatalf("IngestUsageEvents failed: %v", err)
+	}
+
+	claimed := func(billingID int64, closedAt time.Time) int64 {
+		aggregates, err := repo.ClaimUsage(ctx, billingID, closedAt)
+		if err != nil {
+			t.Fatalf("ClaimUsage failed: %v", err)
+		}
+		if len(aggregates) != 1 {
+			t.Fatalf("Expected 1 aggregate, got %+v", aggregates)
+		}
+		return aggregates[0].Quantity
+	}
+
+	// the first billing takes the late event before its period, and leaves the usage in the period of the second billing
+	if quantity := claimed(firstBillingID, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)); quantity != 11 {
+		t.Errorf("Expected the first billing to claim 11 calls, got %d", quantity)
+	}
+	_, err = billingRepo.CloseBilling(ctx, firstBillingID, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("CloseBilling failed: %v", err)
+	}
+
+	// an event of March reported once the first billing is closed rolls into the second billing
+	_, err = repo.IngestUsageEvents(ctx, []entities.UsageEvent{usage(10000, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), "late-call-5")})
+	if err != nil {
+		t.Fatalf("IngestUsageEvents failed: %v", err)
+	}
+	if quantity := claimed(secondBillingID, time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)); quantity != 11100 {
+		t.Errorf("Expected the second billing to claim 11100 calls, got %d", quantity)
+	}
+}
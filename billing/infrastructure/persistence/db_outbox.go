@@ -0,0 +1,164 @@
+package persistence
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+// outboxLockKey is the advisory lock held by relays and replays, so that only one of them publishes at a time
+const outboxLockKey = 7_240_040
+
+type postgresOutboxRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresOutboxRepository(db *sqldb.Database) repositories.OutboxRepository {
+	return &postgresOutboxRepository{db: db}
+}
+
+func (r *postgresOutboxRepository) RelayOutbox(ctx context.Context, limit int, publish func(message *entities.OutboxMessage) error) (int, error) {
+	fn := "infrastructure.persistence.postgresOutboxRepository.RelayOutbox"
+	logger := rlog.With("fn", fn).With("limit", limit)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return 0, entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// take the outbox lock, another relay is already publishing when it is taken
+	var locked bool
+	err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockKey).Scan(&locked)
+	if err != nil {
+		logger.Error("failed to lock outbox", "error", err)
+		return 0, entities.ErrDBService
+	}
+	if !locked {
+		logger.Info("outbox is relayed by another relay")
+		return 0, nil
+	}
+
+	// offsets follow the insert order, a message whose transaction commits late is relayed with the next batch
+	messages, err := listOutboxMessages(ctx, tx, `
+		SELECT id, event_id, event_type, payload, created_at, sent_at FROM outbox WHERE sent_at IS NULL ORDER BY id LIMIT $1
+	`, limit)
+	if err != nil {
+		logger.Error("failed to get pending outbox messages", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	// publish in offset order, stopping at the first failure so that later messages of the batch are not published before it
+	relayed := 0
+	var publishErr error
+	for i := range messages {
+		if publishErr = publish(&messages[i]); publishErr != nil {
+			logger.Error("failed to publish outbox message", "offset", messages[i].Offset, "error", publishErr)
+			break
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE outbox SET sent_at = timezone('utc', now()) WHERE id = $1
+		`, messages[i].Offset)
+		if err != nil {
+			logger.Error("failed to mark outbox message as sent", "offset", messages[i].Offset, "error", err)
+			return 0, entities.ErrDBService
+		}
+		relayed++
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit outbox relay", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	if relayed > 0 {
+		logger.Info("outbox messages relayed", "count", relayed)
+	}
+
+	return relayed, publishErr
+}
+
+func (r *postgresOutboxRepository) ReplayOutbox(ctx context.Context, fromOffset int64, limit int, publish func(message *entities.OutboxMessage) error) (int, int64, error) {
+	fn := "infrastructure.persistence.postgresOutboxRepository.ReplayOutbox"
+	logger := rlog.With("fn", fn).With("fromOffset", fromOffset).With("limit", limit)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return 0, fromOffset, entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// wait for a running relay
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, outboxLockKey)
+	if err != nil {
+		logger.Error("failed to lock outbox", "error", err)
+		return 0, fromOffset, entities.ErrDBService
+	}
+
+	// pending messages are left to the relay, so a replay never publishes ahead of it
+	messages, err := listOutboxMessages(ctx, tx, `
+		SELECT id, event_id, event_type, payload, created_at, sent_at FROM outbox WHERE id >= $2 AND sent_at IS NOT NULL ORDER BY id LIMIT $1
+	`, limit, fromOffset)
+	if err != nil {
+		logger.Error("failed to get sent outbox messages", "error", err)
+		return 0, fromOffset, entities.ErrDBService
+	}
+
+	nextOffset := fromOffset
+	for i := range messages {
+		if err = publish(&messages[i]); err != nil {
+			logger.Error("failed to publish outbox message", "offset", messages[i].Offset, "error", err)
+			return i, nextOffset, err
+		}
+		nextOffset = messages[i].Offset + 1
+	}
+
+	logger.Info("outbox messages replayed", "count", len(messages), "nextOffset", nextOffset)
+
+	return len(messages), nextOffset, nil
+}
+
+// listOutboxMessages reads all messages of a query before any is published, a transaction cannot run statements while rows are open
+func listOutboxMessages(ctx context.Context, tx *sqldb.Tx, query string, args ...any) ([]entities.OutboxMessage, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []entities.OutboxMessage
+	for rows.Next() {
+		var message entities.OutboxMessage
+		err = rows.Scan(&message.Offset, &message.EventID, &message.EventType, &message.Payload, &message.CreatedAt, &message.SentAt)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// insertOutboxMessage writes an event to the outbox in the transaction of the change it describes,
+// an event written again by a retried activity keeps its first offset
+func insertOutboxMessage(ctx context.Context, tx *sqldb.Tx, eventID string, eventType entities.BillingEventType, event any) error {
+	message, err := entities.NewOutboxMessage(eventID, eventType, event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO outbox (event_id, event_type, payload)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id) DO NOTHING
+	`, message.EventID, message.EventType, message.Payload)
+	return err
+}
@@ -0,0 +1,162 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresOutboxRepository_RelayOutbox(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	billingRepo := NewPostgresDBRepository(db)
+	repo := NewPostgresOutboxRepository(db)
+	externalBillingID, _ := uuid.NewV7()
+	lineItemID, _ := uuid.NewV7()
+
+	// billing changes write their events to the outbox, a retried change writes it once
+	billing := &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: externalBillingID.String(),
+		Description:       "Test billing",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+	}
+	billingID, err := billingRepo.CreateBilling(ctx, billing)
+	if err != nil {
+		t.Fatalf("CreateBilling failed: %v", err)
+	}
+	if _, err = billingRepo.CreateBilling(ctx, billing); err != nil {
+		t.Fatalf("CreateBilling retry failed: %v", err)
+	}
+	lineItem := &entities.LineItem{LineItemID: lineItemID.String(), Description: "Seat", AmountMinor: 1000}
+	if err = billingRepo.AddLineItem(ctx, billingID, lineItem); err != nil {
+		t.Fatalf("AddLineItem failed: %v", err)
+	}
+	if err = billingRepo.CancelBilling(ctx, billingID, "duplicate", time.Now().UTC()); err != nil {
+		t.Fatalf("CancelBilling failed: %v", err)
+	}
+
+	// the pending messages are published in order
+	var published []*entities.OutboxMessage
+	relayed, err := repo.RelayOutbox(ctx, 100, func(message *entities.OutboxMessage) error {
+		published = append(published, message)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RelayOutbox failed: %v", err)
+	}
+	expected := []entities.BillingEventType{entities.BillingEventCreated, entities.BillingEventLineItemAdded, entities.BillingEventCancelled}
+	if relayed != len(expected) || len(published) != len(expected) {
+		t.Fatalf("RelayOutbox() = %v, expected %v", relayed, len(expected))
+	}
+	for i, eventType := range expected {
+		if published[i].EventType != eventType {
+			t.Errorf("RelayOutbox() message %d type = %v, expected %v", i, published[i].EventType, eventType)
+		}
+		if i > 0 && published[i].Offset <= published[i-1].Offset {
+			t.Errorf("RelayOutbox() message %d offset = %v, expected after %v", i, published[i].Offset, published[i-1].Offset)
+		}
+	}
+
+	// sent messages are not relayed again
+	relayed, err = repo.RelayOutbox(ctx, 100, func(message *entities.OutboxMessage) error {
+		return nil
+	})
+	if err != nil || relayed != 0 {
+		t.Errorf("RelayOutbox() = %v, %v, expected 0, nil", relayed, err)
+	}
+}
+
+func TestPostgresOutboxRepository_RelayOutboxFailure(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	billingRepo := NewPostgresDBRepository(db)
+	repo := NewPostgresOutboxRepository(db)
+
+	for i := 0; i < 3; i++ {
+		externalBillingID, _ := uuid.NewV7()
+		_, err := billingRepo.CreateBilling(ctx, &entities.Billing{UserID: "user123", ExternalBillingID: externalBillingID.String(), Currency: "USD", CurrencyPrecision: 2})
+		if err != nil {
+			t.Fatalf("CreateBilling failed: %v", err)
+		}
+	}
+
+	// a failed publish stops the relay, the messages before it stay sent
+	errPublish := errors.New("publish failed")
+	calls := 0
+	relayed, err := repo.RelayOutbox(ctx, 100, func(message *entities.OutboxMessage) error {
+		calls++
+		if calls == 2 {
+			return errPublish
+		}
+		return nil
+	})
+	if !errors.Is(err, errPublish) || relayed != 1 {
+		t.Fatalf("RelayOutbox() = %v, %v, expected 1, %v", relayed, err, errPublish)
+	}
+
+	// the next relay starts from the failed message
+	relayed, err = repo.RelayOutbox(ctx, 100, func(message *entities.OutboxMessage) error {
+		return nil
+	})
+	if err != nil || relayed != 2 {
+		t.Errorf("RelayOutbox() = %v, %v, expected 2, nil", relayed, err)
+	}
+}
+
+func TestPostgresOutboxRepository_ReplayOutbox(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	billingRepo := NewPostgresDBRepository(db)
+	repo := NewPostgresOutboxRepository(db)
+
+	for i := 0; i < 3; i++ {
+		externalBillingID, _ := uuid.NewV7()
+		_, err := billingRepo.CreateBilling(ctx, &entities.Billing{UserID: "user123", ExternalBillingID: externalBillingID.String(), Currency: "USD", CurrencyPrecision: 2})
+		if err != nil {
+			t.Fatalf("CreateBilling failed: %v", err)
+		}
+	}
+
+	// pending messages are not replayed
+	replayed, _, err := repo.ReplayOutbox(ctx, 0, 100, func(message *entities.OutboxMessage) error {
+		return nil
+	})
+	if err != nil || replayed != 0 {
+		t.Fatalf("ReplayOutbox() = %v, %v, expected 0, nil", replayed, err)
+	}
+
+	var offsets []int64
+	_, err = repo.RelayOutbox(ctx, 100, func(message *entities.OutboxMessage) error {
+		offsets = append(offsets, message.Offset)
+		return nil
+	})
+	if err != nil || len(offsets) != 3 {
+		t.Fatalf("RelayOutbox() = %v, %v, expected 3 messages", offsets, err)
+	}
+
+	// replay pages from the second message
+	var replayedOffsets []int64
+	replayed, nextOffset, err := repo.ReplayOutbox(ctx, offsets[1], 1, func(message *entities.OutboxMessage) error {
+		replayedOffsets = append(replayedOffsets, message.Offset)
+		return nil
+	})
+	if err != nil || replayed != 1 || replayedOffsets[0] != offsets[1] || nextOffset != offsets[1]+1 {
+		t.Errorf("ReplayOutbox() = %v, %v, %v, expected 1, %v, nil", replayed, nextOffset, err, offsets[1]+1)
+	}
+
+	replayed, _, err = repo.ReplayOutbox(ctx, nextOffset, 100, func(message *entities.OutboxMessage) error {
+		replayedOffsets = append(replayedOffsets, message.Offset)
+		return nil
+	})
+	if err != nil || replayed != 1 || replayedOffsets[1] != offsets[2] {
+		t.Errorf("ReplayOutbox() = %v, %v, expected the last message", replayedOffsets, err)
+	}
+}
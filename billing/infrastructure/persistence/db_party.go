@@ -0,0 +1,71 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresPartyRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresPartyRepository(db *sqldb.Database) repositories.PartyRepository {
+	return &postgresPartyRepository{db: db}
+}
+
+func (r *postgresPartyRepository) UpsertParty(ctx context.Context, party *entities.Party) error {
+	fn := "infrastructure.persistence.postgresPartyRepository.UpsertParty"
+	logger := rlog.With("fn", fn).With("role", party.Role).With("key", party.Key)
+
+	// insert or update party in database
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO parties (role, key, name, vat_id, street, city, postal_code, country_code, endpoint_id, endpoint_scheme)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (role, key) DO UPDATE SET
+			name = EXCLUDED.name, vat_id = EXCLUDED.vat_id, street = EXCLUDED.street, city = EXCLUDED.city,
+			postal_code = EXCLUDED.postal_code, country_code = EXCLUDED.country_code,
+			endpoint_id = EXCLUDED.endpoint_id, endpoint_scheme = EXCLUDED.endpoint_scheme,
+			updated_at = timezone('utc', now())
+		RETURNING created_at, updated_at
+	`, party.Role, party.Key, party.Name, party.VATID, party.Street, party.City, party.PostalCode, party.CountryCode, party.EndpointID, party.EndpointScheme).Scan(&party.CreatedAt, &party.UpdatedAt)
+	if err != nil {
+		logger.Error("failed to upsert party in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("party upserted successfully")
+
+	return nil
+}
+
+func (r *postgresPartyRepository) GetParty(ctx context.Context, role entities.PartyRole, key string) (*entities.Party, error) {
+	fn := "infrastructure.persistence.postgresPartyRepository.GetParty"
+	logger := rlog.With("fn", fn).With("role", role).With("key", key)
+
+	var party entities.Party
+
+	// get party from database
+	err := r.db.QueryRow(ctx, `
+		SELECT role, key, name, vat_id, street, city, postal_code, country_code, endpoint_id, endpoint_scheme, created_at, updated_at
+		FROM parties WHERE role = $1 AND key = $2
+	`, role, key).Scan(&party.Role, &party.Key, &party.Name, &party.VATID, &party.Street, &party.City, &party.PostalCode, &party.CountryCode, &party.EndpointID, &party.EndpointScheme, &party.CreatedAt, &party.UpdatedAt)
+	if err != nil {
+		// no rows found
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Party not found")
+			return nil, entities.ErrPartyNotFound
+		}
+
+		// unknown error
+		logger.Error("Failed to get party", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return &party, nil
+}
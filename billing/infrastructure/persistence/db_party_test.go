@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresPartyRepository_UpsertParty(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresPartyRepository(db)
+
+	seller := &entities.Party{
+		Role:           entities.PartyRoleSeller,
+		Key:            "eu",
+		Name:           "Pave Bank GmbH",
+		VATID:          "DE123456789",
+		City:           "Berlin",
+		CountryCode:    "DE",
+		EndpointID:     "DE123456789",
+		EndpointScheme: "9930",
+	}
+	err := repo.UpsertParty(ctx, seller)
+	if err != nil {
+		t.Fatalf("UpsertParty failed: %v", err)
+	}
+
+	// Test replacing the party
+	seller.City = "Munich"
+	err = repo.UpsertParty(ctx, seller)
+	if err != nil {
+		t.Fatalf("UpsertParty failed: %v", err)
+	}
+
+	party, err := repo.GetParty(ctx, entities.PartyRoleSeller, "eu")
+	if err != nil {
+		t.Fatalf("GetParty failed: %v", err)
+	}
+	if party.City != "Munich" || party.VATID != "DE123456789" {
+		t.Errorf("Unexpected party: %+v", party)
+	}
+
+	// sellers and buyers are keyed separately
+	_, err = repo.GetParty(ctx, entities.PartyRoleBuyer, "eu")
+	if !errors.Is(err, entities.ErrPartyNotFound) {
+		t.Errorf("Expected ErrPartyNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresPaymentRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresPaymentRepository(db *sqldb.Database) repositories.PaymentRepository {
+	return &postgresPaymentRepository{db: db}
+}
+
+func (r *postgresPaymentRepository) CreatePayment(ctx context.Context, payment *entities.Payment) (int64, error) {
+	fn := "infrastructure.persistence.postgresPaymentRepository.CreatePayment"
+	logger := rlog.With("fn", fn).With("externalPaymentID", payment.ExternalPaymentID).With("billingID", payment.BillingID).With("amountMinor", payment.AmountMinor).With("method", payment.Method).With("externalReference", payment.ExternalReference)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return 0, entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// insert payment into database, a payment whose reference is already recorded is skipped
+	var paymentID int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO payments (external_payment_id, billing_id, amount_minor, currency, method, external_reference, paid_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (method, external_reference) WHERE external_reference <> '' DO NOTHING
+		RETURNING id, created_at
+	`, payment.ExternalPaymentID, payment.BillingID, payment.AmountMinor, payment.Currency, payment.Method, payment.ExternalReference, payment.PaidAt).Scan(&paymentID, &payment.CreatedAt)
+	if err == nil {
+		payment.ID = paymentID
+
+		// post the payment to the ledger
+		err = insertJournalEntry(ctx, tx, entities.NewPaymentJournalEntry(payment))
+		if err != nil {
+			logger.Error("failed to post payment to ledger", "error", err)
+			return 0, entities.ErrDBService
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			logger.Error("failed to commit payment", "error", err)
+			return 0, entities.ErrDBService
+		}
+
+		logger.Info("payment created successfully")
+		return paymentID, nil
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		logger.Error("failed to create payment in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	// get the recorded payment
+	var recorded entities.Payment
+	err = tx.QueryRow(ctx, `
+		SELECT p.id, p.external_payment_id, p.billing_id, b.external_billing_id, p.amount_minor, p.currency, b.currency_precision, p.method, p.external_reference, p.paid_at, p.created_at,
+			(SELECT COALESCE(SUM(r.amount_minor), 0) FROM refunds r WHERE r.payment_id = p.id AND r.status = 'succeeded')
+		FROM payments p JOIN billings b ON b.id = p.billing_id
+		WHERE p.method = $1 AND p.external_reference = $2
+	`, payment.Method, payment.ExternalReference).Scan(&recorded.ID, &recorded.ExternalPaymentID, &recorded.BillingID, &recorded.ExternalBillingID, &recorded.AmountMinor, &recorded.Currency, &recorded.CurrencyPrecision, &recorded.Method, &recorded.ExternalReference, &recorded.PaidAt, &recorded.CreatedAt, &recorded.RefundedAmountMinor)
+	if err != nil {
+		logger.Error("failed to get recorded payment", "error", err)
+		return 0, entities.ErrDBService
+	}
+	if recorded.BillingID != payment.BillingID {
+		logger.Warn("payment reference is recorded for another billing", "recordedBillingID", recorded.BillingID)
+		return 0, entities.ErrPaymentReferenceTaken
+	}
+
+	logger.Info("payment already recorded", "recordedPaymentID", recorded.ExternalPaymentID)
+	*payment = recorded
+	return recorded.ID, nil
+}
+
+func (r *postgresPaymentRepository) GetPaymentByExternalID(ctx context.Context, externalPaymentID string) (*entities.Payment, error) {
+	fn := "infrastructure.persistence.postgresPaymentRepository.GetPaymentByExternalID"
+	logger := rlog.With("fn", fn).With("externalPaymentID", externalPaymentID)
+
+	var payment entities.Payment
+	err := r.db.QueryRow(ctx, `
+		SELECT p.id, p.external_payment_id, p.billing_id, b.external_billing_id, p.amount_minor, p.currency, b.currency_precision, p.method, p.external_reference, p.paid_at, p.created_at,
+			(SELECT COALESCE(SUM(r.amount_minor), 0) FROM refunds r WHERE r.payment_id = p.id AND r.status = 'succeeded')
+		FROM payments p JOIN billings b ON b.id = p.billing_id
+		WHERE p.external_payment_id = $1
+	`, externalPaymentID).Scan(&payment.ID, &payment.ExternalPaymentID, &payment.BillingID, &payment.ExternalBillingID, &payment.AmountMinor, &payment.Currency, &payment.CurrencyPrecision, &payment.Method, &payment.ExternalReference, &payment.PaidAt, &payment.CreatedAt, &payment.RefundedAmountMinor)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("payment not found")
+			return nil, entities.ErrPaymentNotFound
+		}
+
+		logger.Error("failed to get payment", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return &payment, nil
+}
+
+func (r *postgresPaymentRepository) ListPaymentsByBillingID(ctx context.Context, billingID int64) ([]entities.Payment, error) {
+	fn := "infrastructure.persistence.postgresPaymentRepository.ListPaymentsByBillingID"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.external_payment_id, p.billing_id, b.external_billing_id, p.amount_minor, p.currency, b.currency_precision, p.method, p.external_reference, p.paid_at, p.created_at,
+			(SELECT COALESCE(SUM(r.amount_minor), 0) FROM refunds r WHERE r.payment_id = p.id AND r.status = 'succeeded')
+		FROM payments p JOIN billings b ON b.id = p.billing_id
+		WHERE p.billing_id = $1
+		ORDER BY p.paid_at, p.id
+	`, billingID)
+	if err != nil {
+		logger.Error("failed to list payments", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	payments := []entities.Payment{}
+	for rows.Next() {
+		var payment entities.Payment
+		err = rows.Scan(&payment.ID, &payment.ExternalPaymentID, &payment.BillingID, &payment.ExternalBillingID, &payment.AmountMinor, &payment.Currency, &payment.CurrencyPrecision, &payment.Method, &payment.ExternalReference, &payment.PaidAt, &payment.CreatedAt, &payment.RefundedAmountMinor)
+		if err != nil {
+			logger.Error("failed to scan payment", "error", err)
+			return nil, entities.ErrDBService
+		}
+		payments = append(payments, payment)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("failed to list payments", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return payments, nil
+}
+
+func (r *postgresPaymentRepository) CreateChargeAttempt(ctx context.Context, attempt *entities.ChargeAttempt) (int64, error) {
+	fn := "infrastructure.persistence.postgresPaymentRepository.CreateChargeAttempt"
+	logger := rlog.With("fn", fn).With("billingID", attempt.BillingID).With("gatewayChargeID", attempt.GatewayChargeID).With("status", attempt.Status)
+
+	// insert charge attempt into database
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO charge_attempts (billing_id, gateway_charge_id, amount_minor, currency, status, decline_code, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, attempted_at
+	`, attempt.BillingID, attempt.GatewayChargeID, attempt.AmountMinor, attempt.Currency, attempt.Status, attempt.DeclineCode, attempt.Error).Scan(&attempt.ID, &attempt.AttemptedAt)
+	if err != nil {
+		logger.Error("failed to create charge attempt in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("charge attempt created successfully")
+
+	return attempt.ID, nil
+}
+
+func (r *postgresPaymentRepository) ListChargeAttemptsByBillingID(ctx context.Context, billingID int64) ([]entities.ChargeAttempt, error) {
+	fn := "infrastructure.persistence.postgresPaymentRepository.ListChargeAttemptsByBillingID"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	rows, err := r.db.Query(ctx, `
+		SELECT a.id, a.billing_id, b.external_billing_id, a.gateway_charge_id, a.amount_minor, a.currency, a.status, a.decline_code, a.error, a.attempted_at
+		FROM charge_attempts a JOIN billings b ON b.id = a.billing_id
+		WHERE a.billing_id = $1
+		ORDER BY a.id
+	`, billingID)
+	if err != nil {
+		logger.Error("failed to list charge attempts", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	attempts := []entities.ChargeAttempt{}
+	for rows.Next() {
+		var attempt entities.ChargeAttempt
+		err = rows.Scan(&attempt.ID, &attempt.BillingID, &attempt.ExternalBillingID, &attempt.GatewayChargeID, &attempt.AmountMinor, &attempt.Currency, &attempt.Status, &attempt.DeclineCode, &attempt.Error, &attempt.AttemptedAt)
+		if err != nil {
+			logger.Error("failed to scan charge attempt", "error", err)
+			return nil, entities.ErrDBService
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("failed to list charge attempts", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return attempts, nil
+}
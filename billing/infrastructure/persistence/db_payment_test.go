@@ -0,0 +1,114 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresPaymentRepository_CreatePayment(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresPaymentRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	billing := createTestBilling(t, ctx, billingRepo)
+	otherBilling := createTestBilling(t, ctx, billingRepo)
+	paidAt := time.Now().UTC().Truncate(time.Second)
+
+	newPayment := func(billingID int64, amountMinor int64, reference string) *entities.Payment {
+		return &entities.Payment{
+			ExternalPaymentID: uuid.NewString(),
+			BillingID:         billingID,
+			AmountMinor:       amountMinor,
+			Currency:          "USD",
+			Method:            entities.PaymentMethodBankTransfer,
+			ExternalReference: reference,
+			PaidAt:            paidAt,
+		}
+	}
+
+	first := newPayment(billing.ID, 600, "TRX-1")
+	firstID, err := repo.CreatePayment(ctx, first)
+	if err != nil {
+		t.Fatalf("CreatePayment failed: %v", err)
+	}
+
+	// a payment recorded again with the same reference returns the recorded payment
+	again := newPayment(billing.ID, 600, "TRX-1")
+	againID, err := repo.CreatePayment(ctx, again)
+	if err != nil {
+		t.Fatalf("CreatePayment failed: %v", err)
+	}
+	if againID != firstID || again.ExternalPaymentID != first.ExternalPaymentID {
+		t.Errorf("Expected payment %v, got %v", first.ExternalPaymentID, again.ExternalPaymentID)
+	}
+
+	// the same reference cannot pay another billing
+	_, err = repo.CreatePayment(ctx, newPayment(otherBilling.ID, 600, "TRX-1"))
+	if !errors.Is(err, entities.ErrPaymentReferenceTaken) {
+		t.Errorf("Expected ErrPaymentReferenceTaken, got: %v", err)
+	}
+
+	// payments without reference are all recorded
+	for i := 0; i < 2; i++ {
+		if _, err = repo.CreatePayment(ctx, newPayment(billing.ID, 200, "")); err != nil {
+			t.Fatalf("CreatePayment failed: %v", err)
+		}
+	}
+
+	payments, err := repo.ListPaymentsByBillingID(ctx, billing.ID)
+	if err != nil {
+		t.Fatalf("ListPaymentsByBillingID failed: %v", err)
+	}
+	if len(payments) != 3 {
+		t.Fatalf("Expected 3 payments, got %d", len(payments))
+	}
+	if payments[0].ExternalBillingID != billing.ExternalBillingID || payments[0].AmountMinor != 600 || payments[0].CurrencyPrecision != 2 {
+		t.Errorf("Unexpected payment: %+v", payments[0])
+	}
+
+	payments, err = repo.ListPaymentsByBillingID(ctx, otherBilling.ID)
+	if err != nil || len(payments) != 0 {
+		t.Errorf("ListPaymentsByBillingID() = %v, %v, expected no payments", payments, err)
+	}
+}
+
+func TestPostgresPaymentRepository_CreateChargeAttempt(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresPaymentRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	billing := createTestBilling(t, ctx, billingRepo)
+
+	attempts := []*entities.ChargeAttempt{
+		{BillingID: billing.ID, GatewayChargeID: "ch_1", AmountMinor: 1000, Currency: "USD", Status: entities.ChargeAttemptStatusDeclined, DeclineCode: "card_declined"},
+		{BillingID: billing.ID, GatewayChargeID: "ch_2", AmountMinor: 1000, Currency: "USD", Status: entities.ChargeAttemptStatusSucceeded},
+	}
+	for _, attempt := range attempts {
+		if _, err := repo.CreateChargeAttempt(ctx, attempt); err != nil {
+			t.Fatalf("CreateChargeAttempt failed: %v", err)
+		}
+	}
+
+	listed, err := repo.ListChargeAttemptsByBillingID(ctx, billing.ID)
+	if err != nil {
+		t.Fatalf("ListChargeAttemptsByBillingID failed: %v", err)
+	}
+	if len(listed) != 2 {
+		t.Fatalf("Expected 2 charge attempts, got %d", len(listed))
+	}
+	if listed[0].Status != entities.ChargeAttemptStatusDeclined || listed[0].DeclineCode != "card_declined" || listed[0].ExternalBillingID != billing.ExternalBillingID {
+		t.Errorf("Unexpected charge attempt: %+v", listed[0])
+	}
+	if listed[1].GatewayChargeID != "ch_2" || listed[1].Status != entities.ChargeAttemptStatusSucceeded {
+		t.Errorf("Unexpected charge attempt: %+v", listed[1])
+	}
+}
@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresReceivableRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresReceivableRepository(db *sqldb.Database) repositories.ReceivableRepository {
+	return &postgresReceivableRepository{db: db}
+}
+
+func (r *postgresReceivableRepository) ListOpenReceivables(ctx context.Context, currency string) ([]entities.Receivable, error) {
+	fn := "infrastructure.persistence.postgresReceivableRepository.ListOpenReceivables"
+	logger := rlog.With("fn", fn).With("currency", currency)
+
+	receivables, err := r.listOpenReceivables(ctx, currency, nil)
+	if err != nil {
+		logger.Error("failed to list open receivables", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return receivables, nil
+}
+
+func (r *postgresReceivableRepository) ListOpenReceivablesAsOf(ctx context.Context, asOf time.Time) ([]entities.Receivable, error) {
+	fn := "infrastructure.persistence.postgresReceivableRepository.ListOpenReceivablesAsOf"
+	logger := rlog.With("fn", fn).With("asOf", asOf)
+
+	receivables, err := r.listOpenReceivables(ctx, "", &asOf)
+	if err != nil {
+		logger.Error("failed to list open receivables", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return receivables, nil
+}
+
+// listOpenReceivables lists the open receivables in a currency, an empty currency lists all currencies and a nil asOf
+// counts everything recorded so far
+func (r *postgresReceivableRepository) listOpenReceivables(ctx context.Context, currency string, asOf *time.Time) ([]entities.Receivable, error) {
+	// what is due is the grand total net of credit notes, what was paid is net of succeeded refunds, a refund is settled
+	// when it last changed status
+	rows, err := r.db.Query(ctx, `
+		SELECT id, external_billing_id, user_id, invoice_number, currency, currency_precision, due_amount_minor, paid_amount_minor, closed_at
+		FROM (
+			SELECT b.id, b.external_billing_id, b.user_id, COALESCE(b.invoice_number, '') AS invoice_number, b.currency, b.currency_precision,
+				COALESCE(b.actual_closed_at, b.updated_at) AS closed_at,
+				(s.summary->>'grand_total_amount_minor')::BIGINT
+					- (SELECT COALESCE(SUM(c.total_amount_minor), 0) FROM credit_notes c
+						WHERE c.billing_id = b.id AND ($2::TIMESTAMPTZ IS NULL OR c.created_at < $2)) AS due_amount_minor,
+				(SELECT COALESCE(SUM(p.amount_minor), 0) FROM payments p
+					WHERE p.billing_id = b.id AND ($2::TIMESTAMPTZ IS NULL OR p.paid_at < $2))
+					- (SELECT COALESCE(SUM(rf.amount_minor), 0) FROM refunds rf JOIN payments p ON p.id = rf.payment_id
+						WHERE p.billing_id = b.id AND rf.status = 'succeeded' AND ($2::TIMESTAMPTZ IS NULL OR rf.updated_at < $2)) AS paid_amount_minor
+			FROM billings b
+			JOIN billing_summaries s ON s.external_billing_id = b.external_billing_id
+			WHERE b.status = 'closed' AND ($1 = '' OR b.currency::TEXT = $1)
+		) receivables
+		WHERE due_amount_minor > paid_amount_minor AND ($2::TIMESTAMPTZ IS NULL OR closed_at < $2)
+		ORDER BY closed_at, id
+	`, currency, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receivables := []entities.Receivable{}
+	for rows.Next() {
+		var receivable entities.Receivable
+		err = rows.Scan(&receivable.BillingID, &receivable.ExternalBillingID, &receivable.UserID, &receivable.InvoiceNumber, &receivable.Currency, &receivable.CurrencyPrecision, &receivable.DueAmountMinor, &receivable.PaidAmountMinor, &receivable.ClosedAt)
+		if err != nil {
+			return nil, err
+		}
+		receivable.OutstandingAmountMinor = receivable.DueAmountMinor - receivable.PaidAmountMinor
+		receivables = append(receivables, receivable)
+	}
+
+	return receivables, rows.Err()
+}
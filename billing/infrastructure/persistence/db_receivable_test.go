@@ -0,0 +1,170 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.dev/et"
+)
+
+// createClosedTestBilling creates a closed billing with a summary of grandTotalMinor
+func createClosedTestBilling(t *testing.T, ctx context.Context, repo repositories.DBRepository, grandTotalMinor int64) *entities.Billing {
+	t.Helper()
+
+	billing := closeTestBilling(t, ctx, repo)
+	if _, err := repo.CreateBillingSummary(ctx, billing.ExternalBillingID, testBillingSummary(t, billing, grandTotalMinor), nil); err != nil {
+		t.Fatalf("CreateBillingSummary failed: %v", err)
+	}
+
+	return billing
+}
+
+// closeTestBilling creates a closed billing without its summary
+func closeTestBilling(t *testing.T, ctx context.Context, repo repositories.DBRepository) *entities.Billing {
+	t.Helper()
+
+	billing := createTestBilling(t, ctx, repo)
+	invoiceNumber, err := repo.CloseBilling(ctx, billing.ID, time.Now().UTC())
+	if err != nil {
+		t.Fatalf("CloseBilling failed: %v", err)
+	}
+	billing.InvoiceNumber = &invoiceNumber
+
+	return billing
+}
+
+// testBillingSummary encodes a summary of billing with grandTotalMinor
+func testBillingSummary(t *testing.T, billing *entities.Billing, grandTotalMinor int64) []byte {
+	t.Helper()
+
+	summary, err := json.Marshal(entities.BillingSummary{
+		ExternalBillingID:     billing.ExternalBillingID,
+		Currency:              billing.Currency,
+		CurrencyPrecision:     billing.CurrencyPrecision,
+		GrandTotalAmountMinor: grandTotalMinor,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal billing summary: %v", err)
+	}
+
+	return summary
+}
+
+func TestPostgresReceivableRepository_ListOpenReceivables(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresReceivableRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+	paymentRepo := NewPostgresPaymentRepository(db)
+	creditNoteRepo := NewPostgresCreditNoteRepository(db)
+
+	unpaid := createClosedTestBilling(t, ctx, billingRepo, 1000)
+	partiallyPaid := createClosedTestBilling(t, ctx, billingRepo, 1000)
+	paid := createClosedTestBilling(t, ctx, billingRepo, 1000)
+	open := createTestBilling(t, ctx, billingRepo)
+
+	for _, payment := range []*entities.Payment{
+		{ExternalPaymentID: uuid.NewString(), BillingID: partiallyPaid.ID, AmountMinor: 400, Currency: "USD", Method: entities.PaymentMethodBankTransfer, PaidAt: time.Now().UTC()},
+		{ExternalPaymentID: uuid.NewString(), BillingID: paid.ID, AmountMinor: 700, Currency: "USD", Method: entities.PaymentMethodBankTransfer, PaidAt: time.Now().UTC()},
+	} {
+		if _, err := paymentRepo.CreatePayment(ctx, payment); err != nil {
+			t.Fatalf("CreatePayment failed: %v", err)
+		}
+	}
+	_, err := creditNoteRepo.CreateCreditNote(ctx, &entities.CreditNote{
+		ExternalCreditNoteID: uuid.NewString(),
+		BillingID:            paid.ID,
+		Reason:               "discount",
+		LineItems:            []entities.CreditNoteLineItem{{Description: "Seat", AmountMinor: 300}},
+		TotalAmountMinor:     300,
+	}, 1000)
+	if err != nil {
+		t.Fatalf("CreateCreditNote failed: %v", err)
+	}
+
+	receivables, err := repo.ListOpenReceivables(ctx, "USD")
+	if err != nil {
+		t.Fatalf("ListOpenReceivables failed: %v", err)
+	}
+
+	outstanding := map[string]int64{}
+	for _, receivable := range receivables {
+		outstanding[receivable.ExternalBillingID] = receivable.OutstandingAmountMinor
+	}
+	if outstanding[unpaid.ExternalBillingID] != 1000 {
+		t.Errorf("Expected unpaid billing to owe 1000, got %v", outstanding[unpaid.ExternalBillingID])
+	}
+	if outstanding[partiallyPaid.ExternalBillingID] != 600 {
+		t.Errorf("Expected partially paid billing to owe 600, got %v", outstanding[partiallyPaid.ExternalBillingID])
+	}
+	if _, ok := outstanding[paid.ExternalBillingID]; ok {
+		t.Errorf("Expected billing paid net of its credit note not to be listed")
+	}
+	if _, ok := outstanding[open.ExternalBillingID]; ok {
+		t.Errorf("Expected open billing not to be listed")
+	}
+
+	others, err := repo.ListOpenReceivables(ctx, "EUR")
+	if err != nil {
+		t.Fatalf("ListOpenReceivables failed: %v", err)
+	}
+	for _, receivable := range others {
+		if receivable.Currency != "EUR" {
+			t.Errorf("Expected EUR receivables only, got %v", receivable.Currency)
+		}
+	}
+}
+
+func TestPostgresReceivableRepository_ListOpenReceivablesAsOf(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresReceivableRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+	paymentRepo := NewPostgresPaymentRepository(db)
+
+	billing := createClosedTestBilling(t, ctx, billingRepo, 1000)
+	now := time.Now().UTC()
+
+	for _, payment := range []*entities.Payment{
+		{ExternalPaymentID: uuid.NewString(), BillingID: billing.ID, AmountMinor: 300, Currency: "USD", Method: entities.PaymentMethodBankTransfer, PaidAt: now},
+		{ExternalPaymentID: uuid.NewString(), BillingID: billing.ID, AmountMinor: 700, Currency: "USD", Method: entities.PaymentMethodBankTransfer, PaidAt: now.Add(2 * time.Hour)},
+	} {
+		if _, err := paymentRepo.CreatePayment(ctx, payment); err != nil {
+			t.Fatalf("CreatePayment failed: %v", err)
+		}
+	}
+
+	outstandingAsOf := func(asOf time.Time) (int64, bool) {
+		receivables, err := repo.ListOpenReceivablesAsOf(ctx, asOf)
+		if err != nil {
+			t.Fatalf("ListOpenReceivablesAsOf failed: %v", err)
+		}
+		for _, receivable := range receivables {
+			if receivable.ExternalBillingID == billing.ExternalBillingID {
+				return receivable.OutstandingAmountMinor, true
+			}
+		}
+		return 0, false
+	}
+
+	// not closed yet
+	if _, ok := outstandingAsOf(now.Add(-time.Hour)); ok {
+		t.Errorf("Expected billing closed after as of not to be listed")
+	}
+
+	// the later payment is not paid yet
+	if outstanding, _ := outstandingAsOf(now.Add(time.Hour)); outstanding != 700 {
+		t.Errorf("Expected billing to owe 700 before the second payment, got %v", outstanding)
+	}
+
+	// fully paid
+	if _, ok := outstandingAsOf(now.Add(3 * time.Hour)); ok {
+		t.Errorf("Expected paid billing not to be listed")
+	}
+}
@@ -0,0 +1,252 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresRefundRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresRefundRepository(db *sqldb.Database) repositories.RefundRepository {
+	return &postgresRefundRepository{db: db}
+}
+
+func (r *postgresRefundRepository) CreateRefund(ctx context.Context, refund *entities.Refund) (*entities.Refund, error) {
+	fn := "infrastructure.persistence.postgresRefundRepository.CreateRefund"
+	logger := rlog.With("fn", fn).With("externalRefundID", refund.ExternalRefundID).With("paymentID", refund.PaymentID).With("amountMinor", refund.AmountMinor).With("idempotencyKey", refund.IdempotencyKey)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// lock payment, so that concurrent refunds are checked one after the other
+	var paymentAmountMinor int64
+	err = tx.QueryRow(ctx, `
+		SELECT amount_minor FROM payments WHERE id = $1 FOR UPDATE
+	`, refund.PaymentID).Scan(&paymentAmountMinor)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("payment not found")
+			return nil, entities.ErrPaymentNotFound
+		}
+
+		logger.Error("failed to lock payment", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	// a refund requested again returns the first refund, a key reused for a different refund is refused
+	var externalRefundID string
+	var requestedAmountMinor int64
+	var requestedReason string
+	var requestedCreditNoteID *int64
+	err = tx.QueryRow(ctx, `
+		SELECT external_refund_id, amount_minor, reason, credit_note_id FROM refunds WHERE payment_id = $1 AND idempotency_key = $2
+	`, refund.PaymentID, refund.IdempotencyKey).Scan(&externalRefundID, &requestedAmountMinor, &requestedReason, &requestedCreditNoteID)
+	if err == nil {
+		if requestedAmountMinor != refund.AmountMinor || requestedReason != refund.Reason || !sameID(requestedCreditNoteID, refund.CreditNoteID) {
+			logger.Warn("idempotency key was used for a different refund", "requestedRefundID", externalRefundID, "requestedAmountMinor", requestedAmountMinor)
+			return nil, entities.ErrRefundKeyReused
+		}
+
+		logger.Info("refund already requested", "requestedRefundID", externalRefundID)
+		return r.GetRefundByExternalID(ctx, externalRefundID)
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		logger.Error("failed to get requested refund", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	// validate refunds do not exceed the payment, failed refunds returned nothing
+	var refundedAmountMinor int64
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount_minor), 0) FROM refunds WHERE payment_id = $1 AND status <> 'failed'
+	`, refund.PaymentID).Scan(&refundedAmountMinor)
+	if err != nil {
+		logger.Error("failed to sum payment refunds", "error", err)
+		return nil, entities.ErrDBService
+	}
+	if refundedAmountMinor+refund.AmountMinor > paymentAmountMinor {
+		logger.Warn("refund exceeds payment", "paymentAmountMinor", paymentAmountMinor, "refundedAmountMinor", refundedAmountMinor)
+		return nil, entities.ErrRefundExceedsPayment
+	}
+
+	// validate refunds do not exceed the credit note they settle
+	if refund.CreditNoteID != nil {
+		var creditNoteAmountMinor int64
+		err = tx.QueryRow(ctx, `
+			SELECT total_amount_minor FROM credit_notes WHERE id = $1 FOR UPDATE
+		`, *refund.CreditNoteID).Scan(&creditNoteAmountMinor)
+		if err != nil {
+			if errors.Is(err, sqldb.ErrNoRows) {
+				logger.Warn("credit note not found")
+				return nil, entities.ErrCreditNoteNotFound
+			}
+
+			logger.Error("failed to lock credit note", "error", err)
+			return nil, entities.ErrDBService
+		}
+
+		var settledAmountMinor int64
+		err = tx.QueryRow(ctx, `
+			SELECT COALESCE(SUM(amount_minor), 0) FROM refunds WHERE credit_note_id = $1 AND status <> 'failed'
+		`, *refund.CreditNoteID).Scan(&settledAmountMinor)
+		if err != nil {
+			logger.Error("failed to sum credit note refunds", "error", err)
+			return nil, entities.ErrDBService
+		}
+		if settledAmountMinor+refund.AmountMinor > creditNoteAmountMinor {
+			logger.Warn("refund exceeds credit note", "creditNoteAmountMinor", creditNoteAmountMinor, "settledAmountMinor", settledAmountMinor)
+			return nil, entities.ErrRefundExceedsCreditNote
+		}
+	}
+
+	// insert refund into database
+	_, err = tx.Exec(ctx, `
+		INSERT INTO refunds (external_refund_id, payment_id, credit_note_id, amount_minor, reason, idempotency_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, refund.ExternalRefundID, refund.PaymentID, refund.CreditNoteID, refund.AmountMinor, refund.Reason, refund.IdempotencyKey)
+	if err != nil {
+		logger.Error("failed to create refund in database", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit refund", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	logger.Info("refund created successfully")
+
+	return r.GetRefundByExternalID(ctx, refund.ExternalRefundID)
+}
+
+func (r *postgresRefundRepository) GetRefundByExternalID(ctx context.Context, externalRefundID string) (*entities.Refund, error) {
+	fn := "infrastructure.persistence.postgresRefundRepository.GetRefundByExternalID"
+	logger := rlog.With("fn", fn).With("externalRefundID", externalRefundID)
+
+	refunds, err := r.listRefunds(ctx, `WHERE r.external_refund_id = $1`, externalRefundID)
+	if err != nil {
+		logger.Error("failed to get refund", "error", err)
+		return nil, entities.ErrDBService
+	}
+	if len(refunds) == 0 {
+		logger.Warn("refund not found")
+		return nil, entities.ErrRefundNotFound
+	}
+
+	return &refunds[0], nil
+}
+
+func (r *postgresRefundRepository) ListRefundsByPaymentID(ctx context.Context, paymentID int64) ([]entities.Refund, error) {
+	fn := "infrastructure.persistence.postgresRefundRepository.ListRefundsByPaymentID"
+	logger := rlog.With("fn", fn).With("paymentID", paymentID)
+
+	refunds, err := r.listRefunds(ctx, `WHERE r.payment_id = $1`, paymentID)
+	if err != nil {
+		logger.Error("failed to list refunds", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return refunds, nil
+}
+
+func (r *postgresRefundRepository) CompleteRefund(ctx context.Context, refundID int64, status entities.RefundStatus, errorMessage string) error {
+	fn := "infrastructure.persistence.postgresRefundRepository.CompleteRefund"
+	logger := rlog.With("fn", fn).With("refundID", refundID).With("status", status)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	refund := entities.Refund{ID: refundID}
+	var completedAt time.Time
+	err = tx.QueryRow(ctx, `
+		UPDATE refunds r SET status = $1, error = $2, updated_at = timezone('utc', now())
+		FROM payments p
+		WHERE r.id = $3 AND r.status = 'pending' AND p.id = r.payment_id
+		RETURNING r.external_refund_id, p.external_payment_id, p.billing_id, r.amount_minor, p.currency, r.updated_at
+	`, status, errorMessage, refundID).Scan(&refund.ExternalRefundID, &refund.ExternalPaymentID, &refund.BillingID, &refund.AmountMinor, &refund.Currency, &completedAt)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Info("refund already completed")
+			return nil
+		}
+
+		logger.Error("failed to complete refund in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	// post the money paid back to the ledger
+	if status == entities.RefundStatusSucceeded {
+		err = insertJournalEntry(ctx, tx, entities.NewRefundJournalEntry(&refund, completedAt))
+		if err != nil {
+			logger.Error("failed to post refund to ledger", "error", err)
+			return entities.ErrDBService
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit refund", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("refund completed successfully")
+
+	return nil
+}
+
+// listRefunds lists the refunds matching a filter on the refunds table r, oldest first
+func (r *postgresRefundRepository) listRefunds(ctx context.Context, filter string, args ...any) ([]entities.Refund, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT r.id, r.external_refund_id, r.payment_id, p.external_payment_id, p.billing_id, b.external_billing_id, r.credit_note_id, c.external_credit_note_id,
+			r.amount_minor, p.currency, b.currency_precision, r.reason, r.idempotency_key, r.status, r.error, r.created_at, r.updated_at
+		FROM refunds r
+		JOIN payments p ON p.id = r.payment_id
+		JOIN billings b ON b.id = p.billing_id
+		LEFT JOIN credit_notes c ON c.id = r.credit_note_id
+		`+filter+`
+		ORDER BY r.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := []entities.Refund{}
+	for rows.Next() {
+		var refund entities.Refund
+		err = rows.Scan(&refund.ID, &refund.ExternalRefundID, &refund.PaymentID, &refund.ExternalPaymentID, &refund.BillingID, &refund.ExternalBillingID, &refund.CreditNoteID, &refund.ExternalCreditNoteID,
+			&refund.AmountMinor, &refund.Currency, &refund.CurrencyPrecision, &refund.Reason, &refund.IdempotencyKey, &refund.Status, &refund.Error, &refund.CreatedAt, &refund.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, rows.Err()
+}
+
+// sameID reports whether two optional IDs are both unset or equal
+func sameID(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,170 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresRefundRepository_CreateRefund(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresRefundRepository(db)
+	paymentRepo := NewPostgresPaymentRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	billing := createTestBilling(t, ctx, billingRepo)
+	payment := &entities.Payment{
+		ExternalPaymentID: uuid.NewString(),
+		BillingID:         billing.ID,
+		AmountMinor:       1000,
+		Currency:          "USD",
+		Method:            entities.PaymentMethodCard,
+		ExternalReference: "ch_1",
+		PaidAt:            time.Now().UTC(),
+	}
+	if _, err := paymentRepo.CreatePayment(ctx, payment); err != nil {
+		t.Fatalf("CreatePayment failed: %v", err)
+	}
+
+	newRefund := func(amountMinor int64, idempotencyKey string) *entities.Refund {
+		return &entities.Refund{
+			ExternalRefundID: uuid.NewString(),
+			PaymentID:        payment.ID,
+			AmountMinor:      amountMinor,
+			Reason:           "damaged",
+			IdempotencyKey:   idempotencyKey,
+		}
+	}
+
+	first, err := repo.CreateRefund(ctx, newRefund(600, "refund-1"))
+	if err != nil {
+		t.Fatalf("CreateRefund failed: %v", err)
+	}
+	if first.Status != entities.RefundStatusPending || first.ExternalPaymentID != payment.ExternalPaymentID || first.Currency != "USD" {
+		t.Errorf("Unexpected refund: %+v", first)
+	}
+
+	// a refund requested again with the same key returns the first refund
+	again, err := repo.CreateRefund(ctx, newRefund(600, "refund-1"))
+	if err != nil {
+		t.Fatalf("CreateRefund failed: %v", err)
+	}
+	if again.ExternalRefundID != first.ExternalRefundID {
+		t.Errorf("Expected refund %v, got %v", first.ExternalRefundID, again.ExternalRefundID)
+	}
+
+	// a key reused for a different refund is refused
+	_, err = repo.CreateRefund(ctx, newRefund(300, "refund-1"))
+	if !errors.Is(err, entities.ErrRefundKeyReused) {
+		t.Errorf("Expected ErrRefundKeyReused, got: %v", err)
+	}
+
+	// pending refunds reserve their amount
+	_, err = repo.CreateRefund(ctx, newRefund(500, "refund-2"))
+	if !errors.Is(err, entities.ErrRefundExceedsPayment) {
+		t.Errorf("Expected ErrRefundExceedsPayment, got: %v", err)
+	}
+
+	// failed refunds release it
+	if err = repo.CompleteRefund(ctx, first.ID, entities.RefundStatusFailed, "gateway error"); err != nil {
+		t.Fatalf("CompleteRefund failed: %v", err)
+	}
+	second, err := repo.CreateRefund(ctx, newRefund(1000, "refund-2"))
+	if err != nil {
+		t.Fatalf("CreateRefund failed: %v", err)
+	}
+	if err = repo.CompleteRefund(ctx, second.ID, entities.RefundStatusSucceeded, ""); err != nil {
+		t.Fatalf("CompleteRefund failed: %v", err)
+	}
+
+	// a completed refund is not completed again
+	if err = repo.CompleteRefund(ctx, second.ID, entities.RefundStatusFailed, "late answer"); err != nil {
+		t.Fatalf("CompleteRefund failed: %v", err)
+	}
+
+	refunds, err := repo.ListRefundsByPaymentID(ctx, payment.ID)
+	if err != nil {
+		t.Fatalf("ListRefundsByPaymentID failed: %v", err)
+	}
+	if len(refunds) != 2 || refunds[0].Status != entities.RefundStatusFailed || refunds[1].Status != entities.RefundStatusSucceeded {
+		t.Errorf("Unexpected refunds: %+v", refunds)
+	}
+
+	refunded, err := paymentRepo.GetPaymentByExternalID(ctx, payment.ExternalPaymentID)
+	if err != nil {
+		t.Fatalf("GetPaymentByExternalID failed: %v", err)
+	}
+	if refunded.RefundedAmountMinor != 1000 || refunded.NetAmountMinor() != 0 {
+		t.Errorf("Expected payment to be refunded, got %+v", refunded)
+	}
+}
+
+func TestPostgresRefundRepository_CreateRefundForCreditNote(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresRefundRepository(db)
+	paymentRepo := NewPostgresPaymentRepository(db)
+	creditNoteRepo := NewPostgresCreditNoteRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+
+	billing := createTestBilling(t, ctx, billingRepo)
+	payment := &entities.Payment{
+		ExternalPaymentID: uuid.NewString(),
+		BillingID:         billing.ID,
+		AmountMinor:       1000,
+		Currency:          "USD",
+		Method:            entities.PaymentMethodBankTransfer,
+		PaidAt:            time.Now().UTC(),
+	}
+	if _, err := paymentRepo.CreatePayment(ctx, payment); err != nil {
+		t.Fatalf("CreatePayment failed: %v", err)
+	}
+
+	creditNote := &entities.CreditNote{
+		ExternalCreditNoteID: uuid.NewString(),
+		BillingID:            billing.ID,
+		Reason:               "damaged",
+		LineItems:            []entities.CreditNoteLineItem{{Description: "Seat", AmountMinor: 300}},
+	}
+	if err := creditNote.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if _, err := creditNoteRepo.CreateCreditNote(ctx, creditNote, 1000); err != nil {
+		t.Fatalf("CreateCreditNote failed: %v", err)
+	}
+
+	newRefund := func(amountMinor int64, idempotencyKey string) *entities.Refund {
+		return &entities.Refund{
+			ExternalRefundID: uuid.NewString(),
+			PaymentID:        payment.ID,
+			CreditNoteID:     &creditNote.ID,
+			AmountMinor:      amountMinor,
+			Reason:           "credit note",
+			IdempotencyKey:   idempotencyKey,
+		}
+	}
+
+	refund, err := repo.CreateRefund(ctx, newRefund(200, "refund-1"))
+	if err != nil {
+		t.Fatalf("CreateRefund failed: %v", err)
+	}
+	if refund.ExternalCreditNoteID == nil || *refund.ExternalCreditNoteID != creditNote.ExternalCreditNoteID {
+		t.Errorf("Expected refund to settle credit note %v, got %v", creditNote.ExternalCreditNoteID, refund.ExternalCreditNoteID)
+	}
+
+	// the refunds settling a credit note cannot exceed it
+	_, err = repo.CreateRefund(ctx, newRefund(101, "refund-2"))
+	if !errors.Is(err, entities.ErrRefundExceedsCreditNote) {
+		t.Errorf("Expected ErrRefundExceedsCreditNote, got: %v", err)
+	}
+	if _, err = repo.CreateRefund(ctx, newRefund(100, "refund-2")); err != nil {
+		t.Errorf("CreateRefund failed: %v", err)
+	}
+}
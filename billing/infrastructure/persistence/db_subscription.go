@@ -0,0 +1,187 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresSubscriptionRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresSubscriptionRepository(db *sqldb.Database) repositories.SubscriptionRepository {
+	return &postgresSubscriptionRepository{db: db}
+}
+
+func (r *postgresSubscriptionRepository) CreatePlan(ctx context.Context, plan *entities.Plan) (int64, error) {
+	fn := "infrastructure.persistence.postgresSubscriptionRepository.CreatePlan"
+	logger := rlog.With("fn", fn).With("externalPlanID", plan.ExternalPlanID).With("name", plan.Name).With("currency", plan.Currency).With("amountMinor", plan.AmountMinor)
+
+	var planID int64
+
+	// insert plan into database
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO plans (external_plan_id, name, currency, currency_precision, amount_minor, interval, interval_count, trial_days)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, plan.ExternalPlanID, plan.Name, plan.Currency, plan.CurrencyPrecision, plan.AmountMinor, plan.Interval, plan.IntervalCount, plan.TrialDays).Scan(&planID)
+	if err != nil {
+		logger.Error("failed to create plan in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("plan created successfully")
+
+	return planID, nil
+}
+
+func (r *postgresSubscriptionRepository) GetPlanByExternalID(ctx context.Context, externalPlanID string) (*entities.Plan, error) {
+	fn := "infrastructure.persistence.postgresSubscriptionRepository.GetPlanByExternalID"
+	logger := rlog.With("fn", fn).With("externalPlanID", externalPlanID)
+
+	return r.getPlan(ctx, logger, `external_plan_id = $1`, externalPlanID)
+}
+
+func (r *postgresSubscriptionRepository) GetPlanByID(ctx context.Context, planID int64) (*entities.Plan, error) {
+	fn := "infrastructure.persistence.postgresSubscriptionRepository.GetPlanByID"
+	logger := rlog.With("fn", fn).With("planID", planID)
+
+	return r.getPlan(ctx, logger, `id = $1`, planID)
+}
+
+func (r *postgresSubscriptionRepository) getPlan(ctx context.Context, logger rlog.Ctx, condition string, arg any) (*entities.Plan, error) {
+	var plan entities.Plan
+
+	// get plan from database
+	err := r.db.QueryRow(ctx, `
+		SELECT id, external_plan_id, name, currency, currency_precision, amount_minor, interval, interval_count, trial_days, created_at, updated_at FROM plans WHERE `+condition,
+		arg).Scan(&plan.ID, &plan.ExternalPlanID, &plan.Name, &plan.Currency, &plan.CurrencyPrecision, &plan.AmountMinor, &plan.Interval, &plan.IntervalCount, &plan.TrialDays, &plan.CreatedAt, &plan.UpdatedAt)
+	if err != nil {
+		// no rows found
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Plan not found")
+			return nil, entities.ErrPlanNotFound
+		}
+
+		// unknown error
+		logger.Error("Failed to get plan", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return &plan, nil
+}
+
+func (r *postgresSubscriptionRepository) CreateSubscription(ctx context.Context, subscription *entities.Subscription) (int64, error) {
+	fn := "infrastructure.persistence.postgresSubscriptionRepository.CreateSubscription"
+	logger := rlog.With("fn", fn).With("externalSubscriptionID", subscription.ExternalSubscriptionID).With("userID", subscription.UserID).With("planID", subscription.PlanID).With("status", subscription.Status)
+
+	var subscriptionID int64
+
+	// insert subscription into database
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO subscriptions (external_subscription_id, user_id, plan_id, status, timezone, anchor_at, trial_ends_at)
+		VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'UTC'), $6, $7)
+		RETURNING id
+	`, subscription.ExternalSubscriptionID, subscription.UserID, subscription.PlanID, subscription.Status, subscription.Timezone, subscription.AnchorAt, subscription.TrialEndsAt).Scan(&subscriptionID)
+	if err != nil {
+		logger.Error("failed to create subscription in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("subscription created successfully")
+
+	return subscriptionID, nil
+}
+
+func (r *postgresSubscriptionRepository) GetSubscriptionByExternalID(ctx context.Context, externalSubscriptionID string) (*entities.Subscription, error) {
+	fn := "infrastructure.persistence.postgresSubscriptionRepository.GetSubscriptionByExternalID"
+	logger := rlog.With("fn", fn).With("externalSubscriptionID", externalSubscriptionID)
+
+	var subscription entities.Subscription
+
+	// get subscription with its plan's external ID from database
+	err := r.db.QueryRow(ctx, `
+		SELECT s.id, s.external_subscription_id, s.user_id, s.plan_id, p.external_plan_id, s.status, s.timezone, s.anchor_at, s.trial_ends_at, s.current_billing_id, s.cancelled_at, s.created_at, s.updated_at
+		FROM subscriptions s JOIN plans p ON p.id = s.plan_id
+		WHERE s.external_subscription_id = $1
+	`, externalSubscriptionID).Scan(&subscription.ID, &subscription.ExternalSubscriptionID, &subscription.UserID, &subscription.PlanID, &subscription.ExternalPlanID, &subscription.Status, &subscription.Timezone, &subscription.AnchorAt, &subscription.TrialEndsAt, &subscription.CurrentBillingID, &subscription.CancelledAt, &subscription.CreatedAt, &subscription.UpdatedAt)
+	if err != nil {
+		// no rows found
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Subscription not found")
+			return nil, entities.ErrSubscriptionNotFound
+		}
+
+		// unknown error
+		logger.Error("Failed to get subscription by external ID", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return &subscription, nil
+}
+
+func (r *postgresSubscriptionRepository) UpdateSubscriptionPlan(ctx context.Context, subscriptionID int64, planID int64) error {
+	fn := "infrastructure.persistence.postgresSubscriptionRepository.UpdateSubscriptionPlan"
+	logger := rlog.With("fn", fn).With("subscriptionID", subscriptionID).With("planID", planID)
+
+	// update subscription in database
+	_, err := r.db.Exec(ctx, `
+		UPDATE subscriptions SET plan_id = $1, updated_at = timezone('utc', now()) WHERE id = $2
+	`, planID, subscriptionID)
+	if err != nil {
+		logger.Error("failed to update subscription plan in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("subscription plan updated successfully")
+
+	return nil
+}
+
+func (r *postgresSubscriptionRepository) UpdateSubscriptionStatus(ctx context.Context, subscriptionID int64, expectedStatus entities.SubscriptionStatus, status entities.SubscriptionStatus, cancelledAt *time.Time) error {
+	fn := "infrastructure.persistence.postgresSubscriptionRepository.UpdateSubscriptionStatus"
+	logger := rlog.With("fn", fn).With("subscriptionID", subscriptionID).With("expectedStatus", expectedStatus).With("status", status)
+
+	// update subscription in database, only from the status the transition was checked from
+	result, err := r.db.Exec(ctx, `
+		UPDATE subscriptions SET status = $1, cancelled_at = COALESCE($2, cancelled_at), updated_at = timezone('utc', now())
+		WHERE id = $3 AND status = $4
+	`, status, cancelledAt, subscriptionID, expectedStatus)
+	if err != nil {
+		logger.Error("failed to update subscription status in database", "error", err)
+		return entities.ErrDBService
+	}
+	if result.RowsAffected() == 0 {
+		logger.Warn("subscription status changed concurrently")
+		return entities.ErrSubscriptionStatusConflict
+	}
+
+	logger.Info("subscription status updated successfully")
+
+	return nil
+}
+
+func (r *postgresSubscriptionRepository) SetSubscriptionCurrentBilling(ctx context.Context, subscriptionID int64, externalBillingID string) error {
+	fn := "infrastructure.persistence.postgresSubscriptionRepository.SetSubscriptionCurrentBilling"
+	logger := rlog.With("fn", fn).With("subscriptionID", subscriptionID).With("externalBillingID", externalBillingID)
+
+	// update subscription in database
+	_, err := r.db.Exec(ctx, `
+		UPDATE subscriptions SET current_billing_id = $1, updated_at = timezone('utc', now()) WHERE id = $2
+	`, externalBillingID, subscriptionID)
+	if err != nil {
+		logger.Error("failed to set subscription current billing in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("subscription current billing set successfully")
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.dev/et"
+)
+
+func createTestPlan(t *testing.T, ctx context.Context, repo repositories.SubscriptionRepository) *entities.Plan {
+	t.Helper()
+
+	externalPlanID, _ := uuid.NewV7()
+	plan := &entities.Plan{
+		ExternalPlanID:    externalPlanID.String(),
+		Name:              "Pro",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		AmountMinor:       2999,
+		Interval:          entities.PlanIntervalMonth,
+		IntervalCount:     1,
+	}
+	planID, err := repo.CreatePlan(ctx, plan)
+	if err != nil {
+		t.Fatalf("CreatePlan failed: %v", err)
+	}
+	plan.ID = planID
+
+	return plan
+}
+
+func TestPostgresSubscriptionRepository_CreatePlan(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresSubscriptionRepository(db)
+
+	plan := createTestPlan(t, ctx, repo)
+
+	found, err := repo.GetPlanByExternalID(ctx, plan.ExternalPlanID)
+	if err != nil {
+		t.Fatalf("GetPlanByExternalID failed: %v", err)
+	}
+	if found.ID != plan.ID || found.AmountMinor != 2999 || found.Interval != entities.PlanIntervalMonth {
+		t.Errorf("Unexpected plan: %+v", found)
+	}
+
+	// Test not found
+	missingPlanID, _ := uuid.NewV7()
+	_, err = repo.GetPlanByExternalID(ctx, missingPlanID.String())
+	if !errors.Is(err, entities.ErrPlanNotFound) {
+		t.Errorf("Expected ErrPlanNotFound, got: %v", err)
+	}
+}
+
+func TestPostgresSubscriptionRepository_Subscription(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresSubscriptionRepository(db)
+
+	plan := createTestPlan(t, ctx, repo)
+	upgradePlan := createTestPlan(t, ctx, repo)
+
+	externalSubscriptionID, _ := uuid.NewV7()
+	subscriptionID, err := repo.CreateSubscription(ctx, &entities.Subscription{
+		ExternalSubscriptionID: externalSubscriptionID.String(),
+		UserID:                 "user123",
+		PlanID:                 plan.ID,
+		Status:                 entities.SubscriptionStatusActive,
+		AnchorAt:               time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscription failed: %v", err)
+	}
+
+	currentBillingID, _ := uuid.NewV7()
+	if err := repo.SetSubscriptionCurrentBilling(ctx, subscriptionID, currentBillingID.String()); err != nil {
+		t.Fatalf("SetSubscriptionCurrentBilling failed: %v", err)
+	}
+	if err := repo.UpdateSubscriptionPlan(ctx, subscriptionID, upgradePlan.ID); err != nil {
+		t.Fatalf("UpdateSubscriptionPlan failed: %v", err)
+	}
+	cancelledAt := time.Now().UTC()
+	if err := repo.UpdateSubscriptionStatus(ctx, subscriptionID, entities.SubscriptionStatusActive, entities.SubscriptionStatusCancelled, &cancelledAt); err != nil {
+		t.Fatalf("UpdateSubscriptionStatus failed: %v", err)
+	}
+
+	// a transition checked against a status the subscription no longer has is refused
+	err = repo.UpdateSubscriptionStatus(ctx, subscriptionID, entities.SubscriptionStatusActive, entities.SubscriptionStatusPastDue, nil)
+	if !errors.Is(err, entities.ErrSubscriptionStatusConflict) {
+		t.Errorf("UpdateSubscriptionStatus() = %v, expected %v", err, entities.ErrSubscriptionStatusConflict)
+	}
+
+	subscription, err := repo.GetSubscriptionByExternalID(ctx, externalSubscriptionID.String())
+	if err != nil {
+		t.Fatalf("GetSubscriptionByExternalID failed: %v", err)
+	}
+	if subscription.ExternalPlanID != upgradePlan.ExternalPlanID {
+		t.Errorf("Expected plan %s, got %s", upgradePlan.ExternalPlanID, subscription.ExternalPlanID)
+	}
+	if subscription.Status != entities.SubscriptionStatusCancelled || subscription.CancelledAt == nil {
+		t.Errorf("Expected cancelled subscription, got status %s", subscription.Status)
+	}
+	if subscription.CurrentBillingID == nil || *subscription.CurrentBillingID != currentBillingID.String() {
+		t.Errorf("Expected current billing %s, got %v", currentBillingID.String(), subscription.CurrentBillingID)
+	}
+	if subscription.Timezone != "UTC" {
+		t.Errorf("Expected default timezone UTC, got %s", subscription.Timezone)
+	}
+}
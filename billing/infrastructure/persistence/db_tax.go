@@ -0,0 +1,100 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresTaxRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresTaxRepository(db *sqldb.Database) repositories.TaxRepository {
+	return &postgresTaxRepository{db: db}
+}
+
+func (r *postgresTaxRepository) UpsertTaxRate(ctx context.Context, taxRate *entities.TaxRate) (int64, error) {
+	fn := "infrastructure.persistence.postgresTaxRepository.UpsertTaxRate"
+	logger := rlog.With("fn", fn).With("jurisdiction", taxRate.Jurisdiction).With("taxCode", taxRate.TaxCode).With("ratePPM", taxRate.RatePPM)
+
+	var taxRateID int64
+
+	// insert or update tax rate in database
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO tax_rates (jurisdiction, tax_code, name, rate_ppm)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (jurisdiction, tax_code) DO UPDATE SET name = EXCLUDED.name, rate_ppm = EXCLUDED.rate_ppm, updated_at = timezone('utc', now())
+		RETURNING id
+	`, taxRate.Jurisdiction, taxRate.TaxCode, taxRate.Name, taxRate.RatePPM).Scan(&taxRateID)
+	if err != nil {
+		logger.Error("failed to upsert tax rate in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("tax rate upserted successfully")
+
+	return taxRateID, nil
+}
+
+func (r *postgresTaxRepository) GetTaxRate(ctx context.Context, jurisdiction string, taxCode string) (*entities.TaxRate, error) {
+	fn := "infrastructure.persistence.postgresTaxRepository.GetTaxRate"
+	logger := rlog.With("fn", fn).With("jurisdiction", jurisdiction).With("taxCode", taxCode)
+
+	var taxRate entities.TaxRate
+
+	// get tax rate from database
+	err := r.db.QueryRow(ctx, `
+		SELECT id, jurisdiction, tax_code, name, rate_ppm, created_at, updated_at FROM tax_rates WHERE jurisdiction = $1 AND tax_code = $2
+	`, jurisdiction, taxCode).Scan(&taxRate.ID, &taxRate.Jurisdiction, &taxRate.TaxCode, &taxRate.Name, &taxRate.RatePPM, &taxRate.CreatedAt, &taxRate.UpdatedAt)
+	if err != nil {
+		// no rows found
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Tax rate not found")
+			return nil, entities.ErrTaxRateNotFound
+		}
+
+		// unknown error
+		logger.Error("Failed to get tax rate", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return &taxRate, nil
+}
+
+func (r *postgresTaxRepository) ListTaxRates(ctx context.Context, jurisdiction string) ([]entities.TaxRate, error) {
+	fn := "infrastructure.persistence.postgresTaxRepository.ListTaxRates"
+	logger := rlog.With("fn", fn).With("jurisdiction", jurisdiction)
+
+	// get tax rates from database
+	rows, err := r.db.Query(ctx, `
+		SELECT id, jurisdiction, tax_code, name, rate_ppm, created_at, updated_at FROM tax_rates WHERE jurisdiction = $1 ORDER BY tax_code
+	`, jurisdiction)
+	if err != nil {
+		logger.Error("Failed to list tax rates", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	taxRates := []entities.TaxRate{}
+	for rows.Next() {
+		var taxRate entities.TaxRate
+		err = rows.Scan(&taxRate.ID, &taxRate.Jurisdiction, &taxRate.TaxCode, &taxRate.Name, &taxRate.RatePPM, &taxRate.CreatedAt, &taxRate.UpdatedAt)
+		if err != nil {
+			logger.Error("Failed to scan tax rate", "error", err)
+			return nil, entities.ErrDBService
+		}
+		taxRates = append(taxRates, taxRate)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Failed to list tax rates", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return taxRates, nil
+}
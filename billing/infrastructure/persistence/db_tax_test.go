@@ -0,0 +1,73 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresTaxRepository_UpsertTaxRate(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresTaxRepository(db)
+
+	jurisdiction := "TEST-" + uuid.NewString()
+
+	taxRateID, err := repo.UpsertTaxRate(ctx, &entities.TaxRate{Jurisdiction: jurisdiction, TaxCode: "standard", Name: "VAT", RatePPM: 200000})
+	if err != nil {
+		t.Fatalf("UpsertTaxRate failed: %v", err)
+	}
+
+	// upserting the same tax code replaces the rate
+	updatedTaxRateID, err := repo.UpsertTaxRate(ctx, &entities.TaxRate{Jurisdiction: jurisdiction, TaxCode: "standard", Name: "VAT", RatePPM: 210000})
+	if err != nil {
+		t.Fatalf("UpsertTaxRate failed: %v", err)
+	}
+	if updatedTaxRateID != taxRateID {
+		t.Errorf("UpsertTaxRate() = %v, expected %v", updatedTaxRateID, taxRateID)
+	}
+
+	taxRate, err := repo.GetTaxRate(ctx, jurisdiction, "standard")
+	if err != nil {
+		t.Fatalf("GetTaxRate failed: %v", err)
+	}
+	if taxRate.RatePPM != 210000 {
+		t.Errorf("Expected rate 210000, got %d", taxRate.RatePPM)
+	}
+
+	// Test not found
+	_, err = repo.GetTaxRate(ctx, jurisdiction, "reduced")
+	if !errors.Is(err, entities.ErrTaxRateNotFound) {
+		t.Errorf("Expected ErrTaxRateNotFound, got: %v", err)
+	}
+}
+
+func TestPostgresTaxRepository_ListTaxRates(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresTaxRepository(db)
+
+	jurisdiction := "TEST-" + uuid.NewString()
+
+	for _, taxRate := range []entities.TaxRate{
+		{Jurisdiction: jurisdiction, TaxCode: "standard", Name: "VAT", RatePPM: 200000},
+		{Jurisdiction: jurisdiction, TaxCode: "reduced", Name: "Reduced VAT", RatePPM: 50000},
+	} {
+		if _, err := repo.UpsertTaxRate(ctx, &taxRate); err != nil {
+			t.Fatalf("UpsertTaxRate failed: %v", err)
+		}
+	}
+
+	taxRates, err := repo.ListTaxRates(ctx, jurisdiction)
+	if err != nil {
+		t.Fatalf("ListTaxRates failed: %v", err)
+	}
+	if len(taxRates) != 2 || taxRates[0].TaxCode != "reduced" || taxRates[1].TaxCode != "standard" {
+		t.Errorf("Unexpected tax rates: %+v", taxRates)
+	}
+}
@@ -0,0 +1,286 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresWalletRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresWalletRepository(db *sqldb.Database) repositories.WalletRepository {
+	return &postgresWalletRepository{db: db}
+}
+
+func (r *postgresWalletRepository) ListWalletsByUserID(ctx context.Context, userID string) ([]entities.Wallet, error) {
+	fn := "infrastructure.persistence.postgresWalletRepository.ListWalletsByUserID"
+	logger := rlog.With("fn", fn).With("userID", userID)
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, user_id, currency, currency_precision, balance_minor, created_at, updated_at
+		FROM wallets
+		WHERE user_id = $1
+		ORDER BY currency
+	`, userID)
+	if err != nil {
+		logger.Error("failed to list wallets", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	wallets := []entities.Wallet{}
+	for rows.Next() {
+		var wallet entities.Wallet
+		err = rows.Scan(&wallet.ID, &wallet.UserID, &wallet.Currency, &wallet.CurrencyPrecision, &wallet.BalanceMinor, &wallet.CreatedAt, &wallet.UpdatedAt)
+		if err != nil {
+			logger.Error("failed to scan wallet", "error", err)
+			return nil, entities.ErrDBService
+		}
+		wallets = append(wallets, wallet)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("failed to list wallets", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return wallets, nil
+}
+
+func (r *postgresWalletRepository) CreditWallet(ctx context.Context, transaction *entities.WalletTransaction) error {
+	fn := "infrastructure.persistence.postgresWalletRepository.CreditWallet"
+	logger := rlog.With("fn", fn).With("userID", transaction.UserID).With("currency", transaction.Currency).With("kind", transaction.Kind).With("amountMinor", transaction.AmountMinor).With("externalReference", transaction.ExternalReference)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// create the wallet on its first credit
+	_, err = tx.Exec(ctx, `
+		INSERT INTO wallets (user_id, currency, currency_precision)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, currency) DO NOTHING
+	`, transaction.UserID, transaction.Currency, transaction.CurrencyPrecision)
+	if err != nil {
+		logger.Error("failed to create wallet", "error", err)
+		return entities.ErrDBService
+	}
+
+	// lock the wallet, so that its balance is changed by one transaction at a time
+	var balanceMinor int64
+	err = tx.QueryRow(ctx, `
+		SELECT id, currency_precision, balance_minor
+		FROM wallets
+		WHERE user_id = $1 AND currency = $2
+		FOR UPDATE
+	`, transaction.UserID, transaction.Currency).Scan(&transaction.WalletID, &transaction.CurrencyPrecision, &balanceMinor)
+	if err != nil {
+		logger.Error("failed to lock wallet", "error", err)
+		return entities.ErrDBService
+	}
+
+	// a transaction whose reference is already recorded is skipped
+	if transaction.ExternalReference != "" {
+		var recorded entities.WalletTransaction
+		err = tx.QueryRow(ctx, `
+			SELECT t.id, t.external_transaction_id, t.wallet_id, w.user_id, w.currency, w.currency_precision, t.kind, t.amount_minor, t.balance_after_minor, t.reason, t.external_reference, t.created_at
+			FROM wallet_transactions t JOIN wallets w ON w.id = t.wallet_id
+			WHERE t.wallet_id = $1 AND t.external_reference = $2
+		`, transaction.WalletID, transaction.ExternalReference).Scan(&recorded.ID, &recorded.ExternalTransactionID, &recorded.WalletID, &recorded.UserID, &recorded.Currency, &recorded.CurrencyPrecision, &recorded.Kind, &recorded.AmountMinor, &recorded.BalanceAfterMinor, &recorded.Reason, &recorded.ExternalReference, &recorded.CreatedAt)
+		if err == nil {
+			logger.Info("wallet transaction already recorded", "recordedTransactionID", recorded.ExternalTransactionID)
+			*transaction = recorded
+			return nil
+		}
+		if !errors.Is(err, sqldb.ErrNoRows) {
+			logger.Error("failed to get recorded wallet transaction", "error", err)
+			return entities.ErrDBService
+		}
+	}
+
+	transaction.BalanceAfterMinor = balanceMinor + transaction.AmountMinor
+	if transaction.BalanceAfterMinor < 0 {
+		logger.Warn("wallet balance is insufficient", "balanceMinor", balanceMinor)
+		return entities.ErrInsufficientWalletBalance
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE wallets SET balance_minor = $2, updated_at = timezone('utc', now())
+		WHERE id = $1
+	`, transaction.WalletID, transaction.BalanceAfterMinor)
+	if err != nil {
+		logger.Error("failed to update wallet balance", "error", err)
+		return entities.ErrDBService
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO wallet_transactions (external_transaction_id, wallet_id, kind, amount_minor, balance_after_minor, reason, external_reference)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, transaction.ExternalTransactionID, transaction.WalletID, transaction.Kind, transaction.AmountMinor, transaction.BalanceAfterMinor, transaction.Reason, transaction.ExternalReference).Scan(&transaction.ID, &transaction.CreatedAt)
+	if err != nil {
+		logger.Error("failed to create wallet transaction in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	// post the credit to the ledger
+	err = insertJournalEntry(ctx, tx, entities.NewWalletJournalEntry(transaction))
+	if err != nil {
+		logger.Error("failed to post wallet transaction to ledger", "error", err)
+		return entities.ErrDBService
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit wallet transaction", "error", err)
+		return entities.ErrDBService
+	}
+
+	logger.Info("wallet credited successfully", "balanceAfterMinor", transaction.BalanceAfterMinor)
+	return nil
+}
+
+func (r *postgresWalletRepository) ListWalletTransactions(ctx context.Context, userID string, currency string) ([]entities.WalletTransaction, error) {
+	fn := "infrastructure.persistence.postgresWalletRepository.ListWalletTransactions"
+	logger := rlog.With("fn", fn).With("userID", userID).With("currency", currency)
+
+	rows, err := r.db.Query(ctx, `
+		SELECT t.id, t.external_transaction_id, t.wallet_id, w.user_id, w.currency, w.currency_precision, t.kind, t.amount_minor, t.balance_after_minor, t.reason, t.external_reference,
+			t.billing_id, b.external_billing_id::TEXT, t.created_at
+		FROM wallet_transactions t
+		JOIN wallets w ON w.id = t.wallet_id
+		LEFT JOIN billings b ON b.id = t.billing_id
+		WHERE w.user_id = $1 AND w.currency::TEXT = $2
+		ORDER BY t.id
+	`, userID, currency)
+	if err != nil {
+		logger.Error("failed to list wallet transactions", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	transactions := []entities.WalletTransaction{}
+	for rows.Next() {
+		var transaction entities.WalletTransaction
+		err = rows.Scan(&transaction.ID, &transaction.ExternalTransactionID, &transaction.WalletID, &transaction.UserID, &transaction.Currency, &transaction.CurrencyPrecision, &transaction.Kind, &transaction.AmountMinor, &transaction.BalanceAfterMinor, &transaction.Reason, &transaction.ExternalReference,
+			&transaction.BillingID, &transaction.ExternalBillingID, &transaction.CreatedAt)
+		if err != nil {
+			logger.Error("failed to scan wallet transaction", "error", err)
+			return nil, entities.ErrDBService
+		}
+		transactions = append(transactions, transaction)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("failed to list wallet transactions", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return transactions, nil
+}
+
+// drawWalletForBilling pays what it can of dueAmountMinor on a closing billing from the wallet of its user in its currency,
+// as a wallet payment, within tx and returns the amount drawn. A billing draws once: drawing again returns the first amount.
+func drawWalletForBilling(ctx context.Context, tx *sqldb.Tx, billing *entities.Billing, dueAmountMinor int64, walletDraw *entities.WalletDraw) (int64, error) {
+	fn := "infrastructure.persistence.drawWalletForBilling"
+	logger := rlog.With("fn", fn).With("externalBillingID", billing.ExternalBillingID).With("userID", billing.UserID).With("currency", billing.Currency).With("dueAmountMinor", dueAmountMinor)
+
+	// lock the wallet, billings of the user closing at the same time draw from it one after the other
+	var walletID, balanceMinor int64
+	err := tx.QueryRow(ctx, `
+		SELECT id, balance_minor
+		FROM wallets
+		WHERE user_id = $1 AND currency = $2
+		FOR UPDATE
+	`, billing.UserID, billing.Currency).Scan(&walletID, &balanceMinor)
+	if err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Info("user has no wallet in the currency of the billing")
+			return 0, nil
+		}
+
+		logger.Error("failed to lock wallet", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	// a retried draw gets the amount of the first one
+	var drawnMinor int64
+	err = tx.QueryRow(ctx, `
+		SELECT -amount_minor
+		FROM wallet_transactions
+		WHERE billing_id = $1 AND kind = 'billing_draw'
+	`, billing.ID).Scan(&drawnMinor)
+	if err == nil {
+		logger.Info("wallet already drawn for billing", "drawnMinor", drawnMinor)
+		return drawnMinor, nil
+	}
+	if !errors.Is(err, sqldb.ErrNoRows) {
+		logger.Error("failed to get wallet draw", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	drawMinor := entities.WalletDrawAmountMinor(balanceMinor, dueAmountMinor)
+	if drawMinor == 0 {
+		logger.Info("nothing to draw from wallet", "balanceMinor", balanceMinor)
+		return 0, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE wallets SET balance_minor = balance_minor - $2, updated_at = timezone('utc', now())
+		WHERE id = $1
+	`, walletID, drawMinor)
+	if err != nil {
+		logger.Error("failed to update wallet balance", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	// the draw is a payment of the billing, referenced by the billing so that it is recorded once
+	payment := &entities.Payment{
+		ExternalPaymentID: walletDraw.ExternalPaymentID,
+		BillingID:         billing.ID,
+		ExternalBillingID: billing.ExternalBillingID,
+		AmountMinor:       drawMinor,
+		Currency:          billing.Currency,
+		CurrencyPrecision: billing.CurrencyPrecision,
+		Method:            entities.PaymentMethodWallet,
+		ExternalReference: billing.ExternalBillingID,
+		PaidAt:            time.Now().UTC(),
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO payments (external_payment_id, billing_id, amount_minor, currency, method, external_reference, paid_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, payment.ExternalPaymentID, payment.BillingID, payment.AmountMinor, payment.Currency, payment.Method, payment.ExternalReference, payment.PaidAt).Scan(&payment.ID, &payment.CreatedAt)
+	if err != nil {
+		logger.Error("failed to create wallet payment in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO wallet_transactions (external_transaction_id, wallet_id, kind, amount_minor, balance_after_minor, billing_id)
+		VALUES ($1, $2, 'billing_draw', $3, $4, $5)
+	`, walletDraw.ExternalTransactionID, walletID, -drawMinor, balanceMinor-drawMinor, billing.ID)
+	if err != nil {
+		logger.Error("failed to create wallet transaction in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	// post the payment to the ledger
+	err = insertJournalEntry(ctx, tx, entities.NewPaymentJournalEntry(payment))
+	if err != nil {
+		logger.Error("failed to post wallet payment to ledger", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("wallet drawn for billing successfully", "drawMinor", drawMinor, "balanceAfterMinor", balanceMinor-drawMinor)
+	return drawMinor, nil
+}
@@ -0,0 +1,237 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.dev/et"
+)
+
+func TestPostgresWalletRepository_CreditWallet(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresWalletRepository(db)
+
+	topUp := &entities.WalletTransaction{
+		ExternalTransactionID: uuid.NewString(),
+		UserID:                "user123",
+		Currency:              "USD",
+		CurrencyPrecision:     2,
+		Kind:                  entities.WalletTransactionKindTopUp,
+		AmountMinor:           1000,
+		ExternalReference:     "topup-1",
+	}
+	if err := repo.CreditWallet(ctx, topUp); err != nil {
+		t.Fatalf("CreditWallet failed: %v", err)
+	}
+	if topUp.BalanceAfterMinor != 1000 {
+		t.Errorf("Expected balance 1000, got %d", topUp.BalanceAfterMinor)
+	}
+
+	// a top-up recorded again with its reference is recorded once
+	again := &entities.WalletTransaction{
+		ExternalTransactionID: uuid.NewString(),
+		UserID:                "user123",
+		Currency:              "USD",
+		CurrencyPrecision:     2,
+		Kind:                  entities.WalletTransactionKindTopUp,
+		AmountMinor:           1000,
+		ExternalReference:     "topup-1",
+	}
+	if err := repo.CreditWallet(ctx, again); err != nil {
+		t.Fatalf("CreditWallet failed: %v", err)
+	}
+	if again.ExternalTransactionID != topUp.ExternalTransactionID {
+		t.Errorf("Expected recorded top-up %s, got %s", topUp.ExternalTransactionID, again.ExternalTransactionID)
+	}
+
+	// an adjustment cannot take more than the balance
+	err := repo.CreditWallet(ctx, &entities.WalletTransaction{
+		ExternalTransactionID: uuid.NewString(),
+		UserID:                "user123",
+		Currency:              "USD",
+		CurrencyPrecision:     2,
+		Kind:                  entities.WalletTransactionKindAdjustment,
+		AmountMinor:           -1500,
+		Reason:                "expired credit",
+	})
+	if !errors.Is(err, entities.ErrInsufficientWalletBalance) {
+		t.Errorf("Expected ErrInsufficientWalletBalance, got %v", err)
+	}
+
+	err = repo.CreditWallet(ctx, &entities.WalletTransaction{
+		ExternalTransactionID: uuid.NewString(),
+		UserID:                "user123",
+		Currency:              "USD",
+		CurrencyPrecision:     2,
+		Kind:                  entities.WalletTransactionKindAdjustment,
+		AmountMinor:           -400,
+		Reason:                "expired credit",
+	})
+	if err != nil {
+		t.Fatalf("CreditWallet failed: %v", err)
+	}
+
+	wallets, err := repo.ListWalletsByUserID(ctx, "user123")
+	if err != nil {
+		t.Fatalf("ListWalletsByUserID failed: %v", err)
+	}
+	if len(wallets) != 1 || wallets[0].BalanceMinor != 600 {
+		t.Fatalf("Expected one wallet holding 600, got %+v", wallets)
+	}
+
+	transactions, err := repo.ListWalletTransactions(ctx, "user123", "USD")
+	if err != nil {
+		t.Fatalf("ListWalletTransactions failed: %v", err)
+	}
+	if len(transactions) != 2 || transactions[1].AmountMinor != -400 || transactions[1].BalanceAfterMinor != 600 {
+		t.Errorf("Expected the top-up and the adjustment, got %+v", transactions)
+	}
+}
+
+func TestPostgresDBRepository_CreateBillingSummary_DrawsWallet(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresWalletRepository(db)
+	billingRepo := NewPostgresDBRepository(db)
+	paymentRepo := NewPostgresPaymentRepository(db)
+	ledgerRepo := NewPostgresLedgerRepository(db)
+
+	err := repo.CreditWallet(ctx, &entities.WalletTransaction{
+		ExternalTransactionID: uuid.NewString(),
+		UserID:                "user123",
+		Currency:              "USD",
+		CurrencyPrecision:     2,
+		Kind:                  entities.WalletTransactionKindTopUp,
+		AmountMinor:           1500,
+	})
+	if err != nil {
+		t.Fatalf("CreditWallet failed: %v", err)
+	}
+
+	newWalletDraw := func() *entities.WalletDraw {
+		return &entities.WalletDraw{ExternalTransactionID: uuid.NewString(), ExternalPaymentID: uuid.NewString()}
+	}
+
+	// two billings closing at the same time draw the wallet once between them
+	billings := []*entities.Billing{
+		closeTestBilling(t, ctx, billingRepo),
+		closeTestBilling(t, ctx, billingRepo),
+	}
+	drawn := make([]int64, len(billings))
+	errs := make([]error, len(billings))
+	var wg sync.WaitGroup
+	for i, billing := range billings {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			drawn[i], errs[i] = billingRepo.CreateBillingSummary(ctx, billing.ExternalBillingID, testBillingSummary(t, billing, 1000), newWalletDraw())
+		}()
+	}
+	wg.Wait()
+	for i := range billings {
+		if errs[i] != nil {
+			t.Fatalf("CreateBillingSummary failed: %v", errs[i])
+		}
+	}
+	if drawn[0]+drawn[1] != 1500 {
+		t.Errorf("Expected 1500 drawn, got %v", drawn)
+	}
+
+	// a retried summary gets the amount of the first draw and draws nothing more
+	again, err := billingRepo.CreateBillingSummary(ctx, billings[0].ExternalBillingID, testBillingSummary(t, billings[0], 1000), newWalletDraw())
+	if err != nil {
+		t.Fatalf("CreateBillingSummary failed: %v", err)
+	}
+	if again != drawn[0] {
+		t.Errorf("Expected retried draw of %d, got %d", drawn[0], again)
+	}
+
+	// the summary shows what the wallet paid
+	stored, err := billingRepo.GetBillingSummary(ctx, billings[0].ExternalBillingID)
+	if err != nil {
+		t.Fatalf("GetBillingSummary failed: %v", err)
+	}
+	if stored.PaidFromCreditAmountMinor != drawn[0] {
+		t.Errorf("Expected %d paid from credit, got %d", drawn[0], stored.PaidFromCreditAmountMinor)
+	}
+
+	wallets, err := repo.ListWalletsByUserID(ctx, "user123")
+	if err != nil {
+		t.Fatalf("ListWalletsByUserID failed: %v", err)
+	}
+	if len(wallets) != 1 || wallets[0].BalanceMinor != 0 {
+		t.Fatalf("Expected an empty wallet, got %+v", wallets)
+	}
+
+	payments, err := paymentRepo.ListPaymentsByBillingID(ctx, billings[0].ID)
+	if err != nil {
+		t.Fatalf("ListPaymentsByBillingID failed: %v", err)
+	}
+	if len(payments) != 1 || payments[0].Method != entities.PaymentMethodWallet || payments[0].AmountMinor != drawn[0] {
+		t.Errorf("Expected one wallet payment of %d, got %+v", drawn[0], payments)
+	}
+
+	// the credit was given and spent
+	balances, err := ledgerRepo.ListAccountBalances(ctx, "USD")
+	if err != nil {
+		t.Fatalf("ListAccountBalances failed: %v", err)
+	}
+	for _, balance := range balances {
+		if balance.Code == entities.LedgerAccountCustomerCredit && balance.BalanceMinor != 0 {
+			t.Errorf("Expected no customer credit left, got %d", balance.BalanceMinor)
+		}
+		if balance.Code == entities.LedgerAccountReceivable && balance.BalanceMinor != 500 {
+			t.Errorf("Expected 500 receivable, got %d", balance.BalanceMinor)
+		}
+	}
+
+	// a refused summary draws nothing
+	err = repo.CreditWallet(ctx, &entities.WalletTransaction{
+		ExternalTransactionID: uuid.NewString(),
+		UserID:                "user123",
+		Currency:              "USD",
+		CurrencyPrecision:     2,
+		Kind:                  entities.WalletTransactionKindTopUp,
+		AmountMinor:           300,
+	})
+	if err != nil {
+		t.Fatalf("CreditWallet failed: %v", err)
+	}
+	_, err = billingRepo.CreateBillingSummary(ctx, billings[0].ExternalBillingID, testBillingSummary(t, billings[0], 2000), newWalletDraw())
+	if !errors.Is(err, entities.ErrBillingSummaryMismatch) {
+		t.Errorf("Expected %v, got %v", entities.ErrBillingSummaryMismatch, err)
+	}
+	wallets, err = repo.ListWalletsByUserID(ctx, "user123")
+	if err != nil {
+		t.Fatalf("ListWalletsByUserID failed: %v", err)
+	}
+	if len(wallets) != 1 || wallets[0].BalanceMinor != 300 {
+		t.Errorf("Expected the wallet to keep 300, got %+v", wallets)
+	}
+
+	// a billing in a currency the user holds no wallet in draws nothing
+	other := &entities.Billing{
+		UserID:            "user123",
+		ExternalBillingID: uuid.NewString(),
+		Description:       "Test billing",
+		Currency:          "EUR",
+		CurrencyPrecision: 2,
+	}
+	other.ID, err = billingRepo.CreateBilling(ctx, other)
+	if err != nil {
+		t.Fatalf("CreateBilling failed: %v", err)
+	}
+	nothing, err := billingRepo.CreateBillingSummary(ctx, other.ExternalBillingID, testBillingSummary(t, other, 1000), newWalletDraw())
+	if err != nil {
+		t.Fatalf("CreateBillingSummary failed: %v", err)
+	}
+	if nothing != 0 {
+		t.Errorf("Expected nothing drawn, got %d", nothing)
+	}
+}
@@ -0,0 +1,281 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+	"encore.dev/storage/sqldb"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+)
+
+type postgresWebhookRepository struct {
+	db *sqldb.Database
+}
+
+func NewPostgresWebhookRepository(db *sqldb.Database) repositories.WebhookRepository {
+	return &postgresWebhookRepository{db: db}
+}
+
+func (r *postgresWebhookRepository) CreateWebhook(ctx context.Context, webhook *entities.Webhook) (int64, error) {
+	fn := "infrastructure.persistence.postgresWebhookRepository.CreateWebhook"
+	logger := rlog.With("fn", fn).With("externalWebhookID", webhook.ExternalWebhookID).With("url", webhook.URL).With("eventTypes", webhook.EventTypes)
+
+	// insert webhook into database
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO webhooks (external_webhook_id, url, event_types, secret)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`, webhook.ExternalWebhookID, webhook.URL, webhook.EventTypes, webhook.Secret).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		logger.Error("failed to create webhook in database", "error", err)
+		return 0, entities.ErrDBService
+	}
+
+	logger.Info("webhook created successfully")
+
+	return webhook.ID, nil
+}
+
+func (r *postgresWebhookRepository) GetWebhookByExternalID(ctx context.Context, externalWebhookID string) (*entities.Webhook, error) {
+	fn := "infrastructure.persistence.postgresWebhookRepository.GetWebhookByExternalID"
+	logger := rlog.With("fn", fn).With("externalWebhookID", externalWebhookID)
+
+	var webhook entities.Webhook
+
+	// get webhook from database
+	err := r.db.QueryRow(ctx, `
+		SELECT id, external_webhook_id, url, event_types, secret, created_at, updated_at FROM webhooks WHERE external_webhook_id = $1
+	`, externalWebhookID).Scan(&webhook.ID, &webhook.ExternalWebhookID, &webhook.URL, &webhook.EventTypes, &webhook.Secret, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		// no rows found
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Webhook not found")
+			return nil, entities.ErrWebhookNotFound
+		}
+
+		// unknown error
+		logger.Error("Failed to get webhook by external ID", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return &webhook, nil
+}
+
+func (r *postgresWebhookRepository) ListWebhooksByEventType(ctx context.Context, eventType entities.BillingEventType) ([]entities.Webhook, error) {
+	fn := "infrastructure.persistence.postgresWebhookRepository.ListWebhooksByEventType"
+	logger := rlog.With("fn", fn).With("eventType", eventType)
+
+	// get webhooks subscribed to the event type from database
+	rows, err := r.db.Query(ctx, `
+		SELECT id, external_webhook_id, url, event_types, secret, created_at, updated_at FROM webhooks WHERE event_types @> ARRAY[$1]::TEXT[] ORDER BY id
+	`, eventType)
+	if err != nil {
+		logger.Error("Failed to list webhooks", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	webhooks := []entities.Webhook{}
+	for rows.Next() {
+		var webhook entities.Webhook
+		err = rows.Scan(&webhook.ID, &webhook.ExternalWebhookID, &webhook.URL, &webhook.EventTypes, &webhook.Secret, &webhook.CreatedAt, &webhook.UpdatedAt)
+		if err != nil {
+			logger.Error("Failed to scan webhook", "error", err)
+			return nil, entities.ErrDBService
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Failed to list webhooks", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return webhooks, nil
+}
+
+func (r *postgresWebhookRepository) CreateWebhookDelivery(ctx context.Context, delivery *entities.WebhookDelivery) (*entities.WebhookDelivery, error) {
+	fn := "infrastructure.persistence.postgresWebhookRepository.CreateWebhookDelivery"
+	logger := rlog.With("fn", fn).With("webhookID", delivery.WebhookID).With("eventID", delivery.EventID)
+
+	created := *delivery
+
+	// insert delivery into database, the insert is idempotent on the webhook and event so that an event published again is delivered once
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO webhook_deliveries (external_delivery_id, webhook_id, event_id, event_type, payload, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (webhook_id, event_id) DO UPDATE SET updated_at = webhook_deliveries.updated_at
+		RETURNING id, external_delivery_id, status, created_at, updated_at
+	`, delivery.ExternalDeliveryID, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Payload, entities.WebhookDeliveryStatusPending).Scan(&created.ID, &created.ExternalDeliveryID, &created.Status, &created.CreatedAt, &created.UpdatedAt)
+	if err != nil {
+		logger.Error("failed to create webhook delivery in database", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	logger.Info("webhook delivery created successfully", "externalDeliveryID", created.ExternalDeliveryID)
+
+	return &created, nil
+}
+
+func (r *postgresWebhookRepository) GetWebhookDeliveryByExternalID(ctx context.Context, externalDeliveryID string) (*entities.WebhookDelivery, error) {
+	fn := "infrastructure.persistence.postgresWebhookRepository.GetWebhookDeliveryByExternalID"
+	logger := rlog.With("fn", fn).With("externalDeliveryID", externalDeliveryID)
+
+	var delivery entities.WebhookDelivery
+
+	// get delivery from database
+	err := r.db.QueryRow(ctx, `
+		SELECT d.id, d.external_delivery_id, d.webhook_id, w.external_webhook_id, d.event_id, d.event_type, d.payload, d.status, d.created_at, d.updated_at
+		FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.external_delivery_id = $1
+	`, externalDeliveryID).Scan(&delivery.ID, &delivery.ExternalDeliveryID, &delivery.WebhookID, &delivery.ExternalWebhookID, &delivery.EventID, &delivery.EventType, &delivery.Payload, &delivery.Status, &delivery.CreatedAt, &delivery.UpdatedAt)
+	if err != nil {
+		// no rows found
+		if errors.Is(err, sqldb.ErrNoRows) {
+			logger.Warn("Webhook delivery not found")
+			return nil, entities.ErrWebhookDeliveryNotFound
+		}
+
+		// unknown error
+		logger.Error("Failed to get webhook delivery by external ID", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	attempts, err := r.getAttempts(ctx, logger, delivery.ID)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Attempts = attempts
+
+	return &delivery, nil
+}
+
+func (r *postgresWebhookRepository) ListWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]entities.WebhookDelivery, error) {
+	fn := "infrastructure.persistence.postgresWebhookRepository.ListWebhookDeliveries"
+	logger := rlog.With("fn", fn).With("webhookID", webhookID).With("limit", limit)
+
+	// get deliveries of webhook from database
+	rows, err := r.db.Query(ctx, `
+		SELECT d.id, d.external_delivery_id, d.webhook_id, w.external_webhook_id, d.event_id, d.event_type, d.payload, d.status, d.created_at, d.updated_at
+		FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.webhook_id = $1
+		ORDER BY d.id DESC
+		LIMIT $2
+	`, webhookID, limit)
+	if err != nil {
+		logger.Error("Failed to list webhook deliveries", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	deliveries := []entities.WebhookDelivery{}
+	for rows.Next() {
+		var delivery entities.WebhookDelivery
+		err = rows.Scan(&delivery.ID, &delivery.ExternalDeliveryID, &delivery.WebhookID, &delivery.ExternalWebhookID, &delivery.EventID, &delivery.EventType, &delivery.Payload, &delivery.Status, &delivery.CreatedAt, &delivery.UpdatedAt)
+		if err != nil {
+			logger.Error("Failed to scan webhook delivery", "error", err)
+			return nil, entities.ErrDBService
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Failed to list webhook deliveries", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	for i := range deliveries {
+		attempts, err := r.getAttempts(ctx, logger, deliveries[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		deliveries[i].Attempts = attempts
+	}
+
+	return deliveries, nil
+}
+
+func (r *postgresWebhookRepository) RecordWebhookDeliveryAttempt(ctx context.Context, deliveryID int64, attempt entities.WebhookDeliveryAttempt, status entities.WebhookDeliveryStatus) error {
+	fn := "infrastructure.persistence.postgresWebhookRepository.RecordWebhookDeliveryAttempt"
+	logger := rlog.With("fn", fn).With("deliveryID", deliveryID).With("statusCode", attempt.StatusCode).With("status", status)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		return entities.ErrDBService
+	}
+	defer tx.Rollback()
+
+	// insert attempt into database
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, status_code, error, duration_ms, attempted_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, deliveryID, attempt.StatusCode, attempt.Error, attempt.DurationMS, attempt.AttemptedAt)
+	if err != nil {
+		logger.Error("failed to record webhook delivery attempt in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	// update delivery status
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries SET status = $1, updated_at = timezone('utc', now()) WHERE id = $2
+	`, status, deliveryID)
+	if err != nil {
+		logger.Error("failed to update webhook delivery status in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("failed to commit webhook delivery attempt", "error", err)
+		return entities.ErrDBService
+	}
+
+	return nil
+}
+
+func (r *postgresWebhookRepository) SetWebhookDeliveryStatus(ctx context.Context, deliveryID int64, status entities.WebhookDeliveryStatus) error {
+	fn := "infrastructure.persistence.postgresWebhookRepository.SetWebhookDeliveryStatus"
+	logger := rlog.With("fn", fn).With("deliveryID", deliveryID).With("status", status)
+
+	// update delivery status
+	_, err := r.db.Exec(ctx, `
+		UPDATE webhook_deliveries SET status = $1, updated_at = timezone('utc', now()) WHERE id = $2
+	`, status, deliveryID)
+	if err != nil {
+		logger.Error("failed to update webhook delivery status in database", "error", err)
+		return entities.ErrDBService
+	}
+
+	return nil
+}
+
+func (r *postgresWebhookRepository) getAttempts(ctx context.Context, logger rlog.Ctx, deliveryID int64) ([]entities.WebhookDeliveryAttempt, error) {
+	// get attempts of delivery from database
+	rows, err := r.db.Query(ctx, `
+		SELECT status_code, error, duration_ms, attempted_at FROM webhook_delivery_attempts WHERE delivery_id = $1 ORDER BY id
+	`, deliveryID)
+	if err != nil {
+		logger.Error("Failed to get webhook delivery attempts", "error", err)
+		return nil, entities.ErrDBService
+	}
+	defer rows.Close()
+
+	attempts := []entities.WebhookDeliveryAttempt{}
+	for rows.Next() {
+		var attempt entities.WebhookDeliveryAttempt
+		err = rows.Scan(&attempt.StatusCode, &attempt.Error, &attempt.DurationMS, &attempt.AttemptedAt)
+		if err != nil {
+			logger.Error("Failed to scan webhook delivery attempt", "error", err)
+			return nil, entities.ErrDBService
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("Failed to get webhook delivery attempts", "error", err)
+		return nil, entities.ErrDBService
+	}
+
+	return attempts, nil
+}
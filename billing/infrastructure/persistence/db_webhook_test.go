@@ -0,0 +1,146 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.dev/et"
+)
+
+func TestPostgresWebhookRepository_CreateWebhook(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresWebhookRepository(db)
+
+	closed := createTestWebhook(t, ctx, repo, entities.BillingEventClosed)
+	createTestWebhook(t, ctx, repo, entities.BillingEventCreated, entities.BillingEventCancelled)
+
+	webhook, err := repo.GetWebhookByExternalID(ctx, closed.ExternalWebhookID)
+	if err != nil {
+		t.Fatalf("GetWebhookByExternalID failed: %v", err)
+	}
+	if webhook.URL != closed.URL || webhook.Secret != closed.Secret || len(webhook.EventTypes) != 1 {
+		t.Errorf("Unexpected webhook: %+v", webhook)
+	}
+
+	// only the webhooks subscribed to the event type are listed
+	webhooks, err := repo.ListWebhooksByEventType(ctx, entities.BillingEventClosed)
+	if err != nil {
+		t.Fatalf("ListWebhooksByEventType failed: %v", err)
+	}
+	if len(webhooks) != 1 || webhooks[0].ID != closed.ID {
+		t.Errorf("Expected webhook %d, got %+v", closed.ID, webhooks)
+	}
+
+	missingID, _ := uuid.NewV7()
+	_, err = repo.GetWebhookByExternalID(ctx, missingID.String())
+	if !errors.Is(err, entities.ErrWebhookNotFound) {
+		t.Errorf("Expected ErrWebhookNotFound, got: %v", err)
+	}
+}
+
+func TestPostgresWebhookRepository_CreateWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+	db, _ := et.NewTestDatabase(ctx, "billing")
+	repo := NewPostgresWebhookRepository(db)
+
+	webhook := createTestWebhook(t, ctx, repo, entities.BillingEventClosed)
+
+	externalDeliveryID, _ := uuid.NewV7()
+	delivery, err := repo.CreateWebhookDelivery(ctx, &entities.WebhookDelivery{
+		ExternalDeliveryID: externalDeliveryID.String(),
+		WebhookID:          webhook.ID,
+		EventID:            "billing.closed:billing-1",
+		EventType:          entities.BillingEventClosed,
+		Payload:            []byte(`{"event_id":"billing.closed:billing-1"}`),
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhookDelivery failed: %v", err)
+	}
+	if delivery.Status != entities.WebhookDeliveryStatusPending {
+		t.Errorf("Expected status %s, got %s", entities.WebhookDeliveryStatusPending, delivery.Status)
+	}
+
+	// the same event is delivered once
+	otherDeliveryID, _ := uuid.NewV7()
+	again, err := repo.CreateWebhookDelivery(ctx, &entities.WebhookDelivery{
+		ExternalDeliveryID: otherDeliveryID.String(),
+		WebhookID:          webhook.ID,
+		EventID:            "billing.closed:billing-1",
+		EventType:          entities.BillingEventClosed,
+		Payload:            []byte(`{"event_id":"billing.closed:billing-1"}`),
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhookDelivery failed: %v", err)
+	}
+	if again.ID != delivery.ID || again.ExternalDeliveryID != delivery.ExternalDeliveryID {
+		t.Errorf("Expected delivery %s, got %s", delivery.ExternalDeliveryID, again.ExternalDeliveryID)
+	}
+
+	// record a failed then a successful attempt
+	attemptedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	err = repo.RecordWebhookDeliveryAttempt(ctx, delivery.ID, entities.WebhookDeliveryAttempt{StatusCode: 503, DurationMS: 40, AttemptedAt: attemptedAt}, entities.WebhookDeliveryStatusPending)
+	if err != nil {
+		t.Fatalf("RecordWebhookDeliveryAttempt failed: %v", err)
+	}
+	err = repo.RecordWebhookDeliveryAttempt(ctx, delivery.ID, entities.WebhookDeliveryAttempt{StatusCode: 200, DurationMS: 25, AttemptedAt: attemptedAt.Add(time.Minute)}, entities.WebhookDeliveryStatusSucceeded)
+	if err != nil {
+		t.Fatalf("RecordWebhookDeliveryAttempt failed: %v", err)
+	}
+
+	logged, err := repo.GetWebhookDeliveryByExternalID(ctx, delivery.ExternalDeliveryID)
+	if err != nil {
+		t.Fatalf("GetWebhookDeliveryByExternalID failed: %v", err)
+	}
+	if logged.Status != entities.WebhookDeliveryStatusSucceeded {
+		t.Errorf("Expected status %s, got %s", entities.WebhookDeliveryStatusSucceeded, logged.Status)
+	}
+	if len(logged.Attempts) != 2 || logged.Attempts[0].StatusCode != 503 || logged.Attempts[1].StatusCode != 200 {
+		t.Errorf("Unexpected attempts: %+v", logged.Attempts)
+	}
+
+	deliveries, err := repo.ListWebhookDeliveries(ctx, webhook.ID, 10)
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries failed: %v", err)
+	}
+	if len(deliveries) != 1 || len(deliveries[0].Attempts) != 2 {
+		t.Errorf("Unexpected deliveries: %+v", deliveries)
+	}
+
+	// a redelivered delivery is pending again
+	err = repo.SetWebhookDeliveryStatus(ctx, delivery.ID, entities.WebhookDeliveryStatusPending)
+	if err != nil {
+		t.Fatalf("SetWebhookDeliveryStatus failed: %v", err)
+	}
+	logged, err = repo.GetWebhookDeliveryByExternalID(ctx, delivery.ExternalDeliveryID)
+	if err != nil {
+		t.Fatalf("GetWebhookDeliveryByExternalID failed: %v", err)
+	}
+	if logged.Status != entities.WebhookDeliveryStatusPending {
+		t.Errorf("Expected status %s, got %s", entities.WebhookDeliveryStatusPending, logged.Status)
+	}
+}
+
+func createTestWebhook(t *testing.T, ctx context.Context, repo repositories.WebhookRepository, eventTypes ...entities.BillingEventType) *entities.Webhook {
+	t.Helper()
+
+	externalWebhookID, _ := uuid.NewV7()
+	webhook := &entities.Webhook{
+		ExternalWebhookID: externalWebhookID.String(),
+		URL:               "https://partner.example.com/billing",
+		EventTypes:        eventTypes,
+		Secret:            "whsec_0123456789abcdef",
+	}
+	_, err := repo.CreateWebhook(ctx, webhook)
+	if err != nil {
+		t.Fatalf("CreateWebhook failed: %v", err)
+	}
+
+	return webhook
+}
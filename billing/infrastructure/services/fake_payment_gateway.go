@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+type FakeGatewayOutcome = string
+
+const (
+	// FakeGatewaySucceed authorizes the charge
+	FakeGatewaySucceed FakeGatewayOutcome = "succeed"
+
+	// FakeGatewayDecline declines the charge with FakeGatewayDeclineCode
+	FakeGatewayDecline FakeGatewayOutcome = "decline"
+
+	// FakeGatewayTimeout authorizes the charge but answers with ErrPaymentGatewayTimeout, as a gateway whose response was lost.
+	// Sending the request again with the same idempotency key returns the charge.
+	FakeGatewayTimeout FakeGatewayOutcome = "timeout"
+)
+
+// FakeGatewayDeclineCode is the decline code of declined fake charges
+const FakeGatewayDeclineCode = "card_declined"
+
+// FakePaymentGateway is an in-process payment gateway for running and testing the charge flow offline.
+// Charges are numbered in order and succeed unless scripted otherwise, so a scripted run always gives the same result.
+type FakePaymentGateway struct {
+	mu sync.Mutex
+
+	// outcomes are consumed by new charges in order, then customer outcomes apply, then charges succeed
+	outcomes         []FakeGatewayOutcome
+	customerOutcomes map[string]FakeGatewayOutcome
+
+	charges        map[string]*entities.Charge
+	chargesByKey   map[string]string
+	refundKeys     map[string]bool
+	chargeSequence int
+	now            func() time.Time
+}
+
+func NewFakePaymentGateway() *FakePaymentGateway {
+	return &FakePaymentGateway{
+		customerOutcomes: map[string]FakeGatewayOutcome{},
+		charges:          map[string]*entities.Charge{},
+		chargesByKey:     map[string]string{},
+		refundKeys:       map[string]bool{},
+		now:              time.Now,
+	}
+}
+
+// Script queues the outcomes of the next charges, one per new charge
+func (g *FakePaymentGateway) Script(outcomes ...FakeGatewayOutcome) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.outcomes = append(g.outcomes, outcomes...)
+}
+
+// ScriptCustomer sets the outcome of every charge of a customer that no queued outcome applies to
+func (g *FakePaymentGateway) ScriptCustomer(customerID string, outcome FakeGatewayOutcome) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.customerOutcomes[customerID] = outcome
+}
+
+func (g *FakePaymentGateway) CreateCharge(ctx context.Context, request entities.ChargeRequest) (*entities.Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, entities.ErrPaymentGatewayTimeout
+	}
+
+	// a request sent again returns the charge of the first request
+	if chargeID, ok := g.chargesByKey[request.IdempotencyKey]; ok && request.IdempotencyKey != "" {
+		charge := *g.charges[chargeID]
+		return &charge, nil
+	}
+
+	if request.AmountMinor <= 0 || request.Currency == "" {
+		return nil, entities.ErrPaymentGateway
+	}
+
+	outcome := FakeGatewaySucceed
+	if customerOutcome, ok := g.customerOutcomes[request.CustomerID]; ok {
+		outcome = customerOutcome
+	}
+	if len(g.outcomes) > 0 {
+		outcome = g.outcomes[0]
+		g.outcomes = g.outcomes[1:]
+	}
+
+	g.chargeSequence++
+	charge := &entities.Charge{
+		ID:             fmt.Sprintf("ch_fake_%06d", g.chargeSequence),
+		IdempotencyKey: request.IdempotencyKey,
+		CustomerID:     request.CustomerID,
+		AmountMinor:    request.AmountMinor,
+		Currency:       request.Currency,
+		Description:    request.Description,
+		Status:         entities.ChargeStatusAuthorized,
+		CreatedAt:      g.now().UTC(),
+	}
+	if outcome == FakeGatewayDecline {
+		charge.Status = entities.ChargeStatusDeclined
+		charge.DeclineCode = FakeGatewayDeclineCode
+	}
+
+	g.charges[charge.ID] = charge
+	if request.IdempotencyKey != "" {
+		g.chargesByKey[request.IdempotencyKey] = charge.ID
+	}
+
+	if outcome == FakeGatewayTimeout {
+		return nil, entities.ErrPaymentGatewayTimeout
+	}
+
+	result := *charge
+	return &result, nil
+}
+
+func (g *FakePaymentGateway) CaptureCharge(ctx context.Context, chargeID string) (*entities.Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[chargeID]
+	if !ok {
+		return nil, entities.ErrChargeNotFound
+	}
+
+	switch {
+	case charge.Status == entities.ChargeStatusAuthorized:
+		charge.Status = entities.ChargeStatusCaptured
+	case !charge.IsCaptured():
+		return nil, entities.ErrInvalidChargeState
+	}
+
+	result := *charge
+	return &result, nil
+}
+
+func (g *FakePaymentGateway) RefundCharge(ctx context.Context, chargeID string, amountMinor int64, idempotencyKey string) (*entities.Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[chargeID]
+	if !ok {
+		return nil, entities.ErrChargeNotFound
+	}
+
+	// a refund sent again is refunded once
+	if idempotencyKey != "" && g.refundKeys[idempotencyKey] {
+		result := *charge
+		return &result, nil
+	}
+
+	if amountMinor <= 0 || amountMinor > charge.RefundableAmountMinor() {
+		return nil, entities.ErrInvalidChargeState
+	}
+
+	charge.RefundedAmountMinor += amountMinor
+	charge.Status = entities.ChargeStatusPartiallyRefunded
+	if charge.RefundedAmountMinor == charge.AmountMinor {
+		charge.Status = entities.ChargeStatusRefunded
+	}
+	if idempotencyKey != "" {
+		g.refundKeys[idempotencyKey] = true
+	}
+
+	result := *charge
+	return &result, nil
+}
+
+func (g *FakePaymentGateway) GetCharge(ctx context.Context, chargeID string) (*entities.Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[chargeID]
+	if !ok {
+		return nil, entities.ErrChargeNotFound
+	}
+
+	result := *charge
+	return &result, nil
+}
+
+func (g *FakePaymentGateway) GetChargeByIdempotencyKey(ctx context.Context, idempotencyKey string) (*entities.Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	chargeID, ok := g.chargesByKey[idempotencyKey]
+	if !ok || idempotencyKey == "" {
+		return nil, entities.ErrChargeNotFound
+	}
+
+	result := *g.charges[chargeID]
+	return &result, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"encore.app/billing/domain/entities"
+)
+
+func TestFakePaymentGateway_CreateCharge(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                string
+		outcome             FakeGatewayOutcome
+		expectedErr         error
+		expectedStatus      entities.ChargeStatus
+		expectedDeclineCode string
+	}{
+		{name: "succeed", outcome: FakeGatewaySucceed, expectedStatus: entities.ChargeStatusAuthorized},
+		{name: "decline", outcome: FakeGatewayDecline, expectedStatus: entities.ChargeStatusDeclined, expectedDeclineCode: FakeGatewayDeclineCode},
+		{name: "timeout", outcome: FakeGatewayTimeout, expectedErr: entities.ErrPaymentGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := NewFakePaymentGateway()
+			gateway.Script(tt.outcome)
+
+			charge, err := gateway.CreateCharge(ctx, entities.ChargeRequest{IdempotencyKey: "charge:billing-1", CustomerID: "user-1", AmountMinor: 1000, Currency: "USD"})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("CreateCharge() error = %v, expected %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+			if charge.ID != "ch_fake_000001" {
+				t.Errorf("CreateCharge() ID = %v, expected %v", charge.ID, "ch_fake_000001")
+			}
+			if charge.Status != tt.expectedStatus {
+				t.Errorf("CreateCharge() Status = %v, expected %v", charge.Status, tt.expectedStatus)
+			}
+			if charge.DeclineCode != tt.expectedDeclineCode {
+				t.Errorf("CreateCharge() DeclineCode = %v, expected %v", charge.DeclineCode, tt.expectedDeclineCode)
+			}
+		})
+	}
+}
+
+func TestFakePaymentGateway_TimeoutRetry(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakePaymentGateway()
+	gateway.Script(FakeGatewayTimeout)
+	request := entities.ChargeRequest{IdempotencyKey: "charge:billing-1", CustomerID: "user-1", AmountMinor: 1000, Currency: "USD"}
+
+	_, err := gateway.CreateCharge(ctx, request)
+	if !errors.Is(err, entities.ErrPaymentGatewayTimeout) {
+		t.Fatalf("CreateCharge() error = %v, expected %v", err, entities.ErrPaymentGatewayTimeout)
+	}
+
+	// the charge was made, a retry with the same idempotency key returns it instead of charging again
+	charge, err := gateway.CreateCharge(ctx, request)
+	if err != nil {
+		t.Fatalf("CreateCharge() error = %v", err)
+	}
+	if charge.ID != "ch_fake_000001" || charge.Status != entities.ChargeStatusAuthorized {
+		t.Errorf("CreateCharge() = %+v, expected the authorized charge of the first request", charge)
+	}
+
+	// the charge of an unanswered request can be looked up under its idempotency key
+	found, err := gateway.GetChargeByIdempotencyKey(ctx, request.IdempotencyKey)
+	if err != nil || found.ID != charge.ID {
+		t.Errorf("GetChargeByIdempotencyKey() = %+v, %v, expected the charge of the first request", found, err)
+	}
+	_, err = gateway.GetChargeByIdempotencyKey(ctx, "charge:billing-missing")
+	if !errors.Is(err, entities.ErrChargeNotFound) {
+		t.Errorf("GetChargeByIdempotencyKey() error = %v, expected %v", err, entities.ErrChargeNotFound)
+	}
+
+	other, err := gateway.CreateCharge(ctx, entities.ChargeRequest{IdempotencyKey: "charge:billing-2", CustomerID: "user-1", AmountMinor: 500, Currency: "USD"})
+	if err != nil || other.ID != "ch_fake_000002" {
+		t.Errorf("CreateCharge() = %+v, %v, expected a new charge", other, err)
+	}
+}
+
+func TestFakePaymentGateway_ScriptCustomer(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakePaymentGateway()
+	gateway.ScriptCustomer("user-declined", FakeGatewayDecline)
+
+	declined, err := gateway.CreateCharge(ctx, entities.ChargeRequest{IdempotencyKey: "a", CustomerID: "user-declined", AmountMinor: 1000, Currency: "USD"})
+	if err != nil || declined.Status != entities.ChargeStatusDeclined {
+		t.Errorf("CreateCharge() = %+v, %v, expected a declined charge", declined, err)
+	}
+
+	authorized, err := gateway.CreateCharge(ctx, entities.ChargeRequest{IdempotencyKey: "b", CustomerID: "user-1", AmountMinor: 1000, Currency: "USD"})
+	if err != nil || authorized.Status != entities.ChargeStatusAuthorized {
+		t.Errorf("CreateCharge() = %+v, %v, expected an authorized charge", authorized, err)
+	}
+}
+
+func TestFakePaymentGateway_CaptureAndRefund(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakePaymentGateway()
+	gateway.Script(FakeGatewaySucceed, FakeGatewayDecline)
+
+	charge, _ := gateway.CreateCharge(ctx, entities.ChargeRequest{IdempotencyKey: "a", CustomerID: "user-1", AmountMinor: 1000, Currency: "USD"})
+	declined, _ := gateway.CreateCharge(ctx, entities.ChargeRequest{IdempotencyKey: "b", CustomerID: "user-1", AmountMinor: 1000, Currency: "USD"})
+
+	// authorized charges cannot be refunded
+	_, err := gateway.RefundCharge(ctx, charge.ID, 100, "refund-0")
+	if !errors.Is(err, entities.ErrInvalidChargeState) {
+		t.Errorf("RefundCharge() error = %v, expected %v", err, entities.ErrInvalidChargeState)
+	}
+
+	captured, err := gateway.CaptureCharge(ctx, charge.ID)
+	if err != nil || captured.Status != entities.ChargeStatusCaptured {
+		t.Fatalf("CaptureCharge() = %+v, %v, expected a captured charge", captured, err)
+	}
+	_, err = gateway.CaptureCharge(ctx, declined.ID)
+	if !errors.Is(err, entities.ErrInvalidChargeState) {
+		t.Errorf("CaptureCharge() error = %v, expected %v", err, entities.ErrInvalidChargeState)
+	}
+
+	// a refund sent again is refunded once
+	for i := 0; i < 2; i++ {
+		refunded, err := gateway.RefundCharge(ctx, charge.ID, 300, "refund-1")
+		if err != nil || refunded.Status != entities.ChargeStatusPartiallyRefunded || refunded.RefundedAmountMinor != 300 {
+			t.Errorf("RefundCharge() = %+v, %v, expected 300 refunded", refunded, err)
+		}
+	}
+
+	_, err = gateway.RefundCharge(ctx, charge.ID, 701, "refund-2")
+	if !errors.Is(err, entities.ErrInvalidChargeState) {
+		t.Errorf("RefundCharge() error = %v, expected %v", err, entities.ErrInvalidChargeState)
+	}
+	refunded, err := gateway.RefundCharge(ctx, charge.ID, 700, "refund-3")
+	if err != nil || refunded.Status != entities.ChargeStatusRefunded {
+		t.Errorf("RefundCharge() = %+v, %v, expected a refunded charge", refunded, err)
+	}
+
+	fetched, err := gateway.GetCharge(ctx, charge.ID)
+	if err != nil || fetched.RefundedAmountMinor != 1000 {
+		t.Errorf("GetCharge() = %+v, %v, expected the refunded charge", fetched, err)
+	}
+	_, err = gateway.GetCharge(ctx, "ch_fake_missing")
+	if !errors.Is(err, entities.ErrChargeNotFound) {
+		t.Errorf("GetCharge() error = %v, expected %v", err, entities.ErrChargeNotFound)
+	}
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/services"
+)
+
+// webhookTimeout bounds a webhook request, a receiver slower than this is retried
+const webhookTimeout = 10 * time.Second
+
+// webhookMaxResponseSize is how much of a response is read, receivers are expected to answer with an empty body
+const webhookMaxResponseSize = 64 << 10
+
+type httpWebhookSender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewHTTPWebhookSender() services.WebhookSender {
+	return &httpWebhookSender{
+		client: &http.Client{
+			Timeout: webhookTimeout,
+			// redirects are not followed, so that the signed payload only goes to the registered URL
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+func (s *httpWebhookSender) Send(ctx context.Context, webhook *entities.Webhook, delivery *entities.WebhookDelivery) entities.WebhookDeliveryAttempt {
+	attemptedAt := s.now().UTC()
+	attempt := entities.WebhookDeliveryAttempt{AttemptedAt: attemptedAt}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(entities.WebhookSignatureHeader, entities.SignWebhookPayload(webhook.Secret, attemptedAt, delivery.Payload))
+	req.Header.Set(entities.WebhookEventIDHeader, delivery.EventID)
+	req.Header.Set(entities.WebhookEventTypeHeader, delivery.EventType)
+	req.Header.Set(entities.WebhookDeliveryIDHeader, delivery.ExternalDeliveryID)
+
+	resp, err := s.client.Do(req)
+	attempt.DurationMS = time.Since(attemptedAt).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	// drain the response so that the connection is reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookMaxResponseSize))
+
+	attempt.StatusCode = resp.StatusCode
+	return attempt
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+func TestHTTPWebhookSender_Send(t *testing.T) {
+	ctx := context.Background()
+	attemptedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	delivery := &entities.WebhookDelivery{
+		ExternalDeliveryID: "delivery-1",
+		EventID:            "billing.closed:billing-1",
+		EventType:          entities.BillingEventClosed,
+		Payload:            []byte(`{"event_id":"billing.closed:billing-1"}`),
+	}
+
+	tests := []struct {
+		name               string
+		statusCode         int
+		expectedStatusCode int
+		expectedSucceeded  bool
+	}{
+		{name: "accepted", statusCode: http.StatusOK, expectedStatusCode: http.StatusOK, expectedSucceeded: true},
+		{name: "no content", statusCode: http.StatusNoContent, expectedStatusCode: http.StatusNoContent, expectedSucceeded: true},
+		{name: "server error", statusCode: http.StatusServiceUnavailable, expectedStatusCode: http.StatusServiceUnavailable, expectedSucceeded: false},
+		{name: "redirect is not followed", statusCode: http.StatusFound, expectedStatusCode: http.StatusFound, expectedSucceeded: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received *http.Request
+			var body []byte
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				body, _ = io.ReadAll(r.Body)
+				if tt.statusCode == http.StatusFound {
+					w.Header().Set("Location", "/elsewhere")
+				}
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer receiver.Close()
+
+			webhook := &entities.Webhook{URL: receiver.URL, Secret: "whsec_0123456789abcdef"}
+			sender := NewHTTPWebhookSender().(*httpWebhookSender)
+			sender.now = func() time.Time { return attemptedAt }
+
+			attempt := sender.Send(ctx, webhook, delivery)
+			if attempt.StatusCode != tt.expectedStatusCode {
+				t.Errorf("StatusCode = %v, expected %v", attempt.StatusCode, tt.expectedStatusCode)
+			}
+			if attempt.Succeeded() != tt.expectedSucceeded {
+				t.Errorf("Succeeded() = %v, expected %v", attempt.Succeeded(), tt.expectedSucceeded)
+			}
+			if !attempt.AttemptedAt.Equal(attemptedAt) {
+				t.Errorf("AttemptedAt = %v, expected %v", attempt.AttemptedAt, attemptedAt)
+			}
+
+			// the receiver gets the payload signed with the webhook secret
+			if received == nil {
+				t.Fatal("receiver got no request")
+			}
+			if received.Method != http.MethodPost || received.URL.Path != "/" {
+				t.Errorf("Request = %s %s, expected POST /", received.Method, received.URL.Path)
+			}
+			if string(body) != string(delivery.Payload) {
+				t.Errorf("Body = %s, expected %s", body, delivery.Payload)
+			}
+			expectedSignature := entities.SignWebhookPayload(webhook.Secret, attemptedAt, delivery.Payload)
+			if signature := received.Header.Get(entities.WebhookSignatureHeader); signature != expectedSignature {
+				t.Errorf("%s = %v, expected %v", entities.WebhookSignatureHeader, signature, expectedSignature)
+			}
+			if eventID := received.Header.Get(entities.WebhookEventIDHeader); eventID != delivery.EventID {
+				t.Errorf("%s = %v, expected %v", entities.WebhookEventIDHeader, eventID, delivery.EventID)
+			}
+			if deliveryID := received.Header.Get(entities.WebhookDeliveryIDHeader); deliveryID != delivery.ExternalDeliveryID {
+				t.Errorf("%s = %v, expected %v", entities.WebhookDeliveryIDHeader, deliveryID, delivery.ExternalDeliveryID)
+			}
+		})
+	}
+}
+
+func TestHTTPWebhookSender_Send_Unreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	receiver.Close()
+
+	webhook := &entities.Webhook{URL: receiver.URL, Secret: "whsec_0123456789abcdef"}
+	attempt := NewHTTPWebhookSender().Send(context.Background(), webhook, &entities.WebhookDelivery{Payload: []byte(`{}`)})
+	if attempt.StatusCode != 0 || attempt.Error == "" {
+		t.Errorf("Send() = %+v, expected an attempt with an error and no status code", attempt)
+	}
+	if attempt.Succeeded() {
+		t.Errorf("Succeeded() = true, expected false")
+	}
+}
@@ -2,42 +2,118 @@ package activities
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"encore.dev/rlog"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
 
+	"encore.app/billing/domain/entities"
 	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
 	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
 )
 
 type BillingActivities struct {
-	dbRepository   repositories.DBRepository
-	temporalClient client.Client
-	taskQueue      string
+	dbRepository           repositories.DBRepository
+	subscriptionRepository repositories.SubscriptionRepository
+	taxRepository          repositories.TaxRepository
+	webhookRepository      repositories.WebhookRepository
+	outboxRepository       repositories.OutboxRepository
+	paymentRepository      repositories.PaymentRepository
+	creditNoteRepository   repositories.CreditNoteRepository
+	dunningRepository      repositories.DunningRepository
+	refundRepository       repositories.RefundRepository
+	meterRepository        repositories.MeterRepository
+	couponRepository       repositories.CouponRepository
+	eventPublisher         services.EventPublisher
+	webhookSender          services.WebhookSender
+	paymentGateway         ports.PaymentGateway
+	temporalClient         client.Client
+	taskQueue              string
 }
 
 func NewBillingActivities(
 	dbRepository repositories.DBRepository,
+	subscriptionRepository repositories.SubscriptionRepository,
+	taxRepository repositories.TaxRepository,
+	webhookRepository repositories.WebhookRepository,
+	outboxRepository repositories.OutboxRepository,
+	paymentRepository repositories.PaymentRepository,
+	creditNoteRepository repositories.CreditNoteRepository,
+	dunningRepository repositories.DunningRepository,
+	refundRepository repositories.RefundRepository,
+	meterRepository repositories.MeterRepository,
+	couponRepository repositories.CouponRepository,
+	eventPublisher services.EventPublisher,
+	webhookSender services.WebhookSender,
+	paymentGateway ports.PaymentGateway,
 	temporalClient client.Client,
 	taskQueue string,
 ) *BillingActivities {
 	return &BillingActivities{
-		dbRepository:   dbRepository,
-		temporalClient: temporalClient,
-		taskQueue:      taskQueue,
+		dbRepository:           dbRepository,
+		subscriptionRepository: subscriptionRepository,
+		taxRepository:          taxRepository,
+		webhookRepository:      webhookRepository,
+		outboxRepository:       outboxRepository,
+		paymentRepository:      paymentRepository,
+		creditNoteRepository:   creditNoteRepository,
+		dunningRepository:      dunningRepository,
+		refundRepository:       refundRepository,
+		meterRepository:        meterRepository,
+		couponRepository:       couponRepository,
+		eventPublisher:         eventPublisher,
+		webhookSender:          webhookSender,
+		paymentGateway:         paymentGateway,
+		temporalClient:         temporalClient,
+		taskQueue:              taskQueue,
 	}
 }
 
+// OutboxRelayBatchSize is how many outbox messages a relay publishes at most
+const OutboxRelayBatchSize = 100
+
+// WebhookDeliveryMaxAttempts is how many times a webhook delivery is attempted before it is marked as failed
+const WebhookDeliveryMaxAttempts = 10
+
+// ChargeMaxAttempts is how many times a billing charge is attempted before an unanswered charge is recorded as failed
+const ChargeMaxAttempts = 3
+
+// RefundMaxAttempts is how many times a refund is sent to the payment gateway before an unanswered refund is marked as failed
+const RefundMaxAttempts = 5
+
+// CouponRedemptionLimitReachedErrorType is the type of the error of a coupon that expired or reached its redemption limit
+const CouponRedemptionLimitReachedErrorType = "CouponRedemptionLimitReached"
+
+// BillingSummaryMismatchErrorType is the type of the error of a billing summary that differs from the one already created
+const BillingSummaryMismatchErrorType = "BillingSummaryMismatch"
+
+// UsageAmountTooLargeErrorType is the type of the error of usage whose amount does not fit in minor units
+const UsageAmountTooLargeErrorType = "UsageAmountTooLarge"
+
+// SubscriptionRenewal describes the billing of the next subscription period
+type SubscriptionRenewal struct {
+	Renew       bool                     `json:"renew"`
+	Description string                   `json:"description"`
+	LineItem    entities.LineItem        `json:"line_item"`
+	Recurrence  *entities.RecurrenceRule `json:"recurrence"`
+}
+
 // StartBillingActivity starts a new billing workflow
-func (a *BillingActivities) StartBillingActivity(ctx context.Context, userID string, externalBillingID string, description string, currency string, currencyPrecision int64, plannedClosedAt *time.Time) (int64, error) {
+func (a *BillingActivities) StartBillingActivity(ctx context.Context, billing entities.Billing) (int64, error) {
 	fn := "billingActivities.StartBillingActivity"
-	logger := rlog.With("fn", fn).With("userID", userID).With("externalBillingID", externalBillingID).With("description", description).With("currency", currency).With("currencyPrecision", currencyPrecision).With("plannedClosedAt", plannedClosedAt)
+	logger := rlog.With("fn", fn).With("userID", billing.UserID).With("externalBillingID", billing.ExternalBillingID).With("description", billing.Description).With("currency", billing.Currency).With("currencyPrecision", billing.CurrencyPrecision).With("plannedClosedAt", billing.PlannedClosedAt)
 
 	logger.Info("StartBillingActivity starting")
 
-	// Create billing in database
-	billingID, err := a.dbRepository.CreateBilling(ctx, userID, externalBillingID, description, currency, currencyPrecision, plannedClosedAt)
+	// Create billing in database, billing created is written to the outbox with it
+	billingID, err := a.dbRepository.CreateBilling(ctx, &billing)
 	if err != nil {
 		logger.Error("Failed to create billing in database", "error", err)
 		return 0, dto.ErrFailedToCreateBillingInDatabase
@@ -48,13 +124,13 @@ func (a *BillingActivities) StartBillingActivity(ctx context.Context, userID str
 }
 
 // AddLineItemActivity adds a line item to a billing
-func (a *BillingActivities) AddLineItemActivity(ctx context.Context, billingID int64, description string, amount int64) error {
+func (a *BillingActivities) AddLineItemActivity(ctx context.Context, billingID int64, lineItem entities.LineItem, externalBillingID string) error {
 	fn := "billingActivities.AddLineItemActivity"
-	logger := rlog.With("fn", fn).With("billingID", billingID).With("description", description).With("amount", amount)
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("externalBillingID", externalBillingID).With("description", lineItem.Description).With("amount", lineItem.AmountMinor).With("taxCode", lineItem.TaxCode)
 	logger.Info("AddLineItemActivity starting")
 
-	// Add line item using repository
-	err := a.dbRepository.AddLineItem(ctx, billingID, description, amount)
+	// Add line item using repository, line item added is written to the outbox with it
+	err := a.dbRepository.AddLineItem(ctx, billingID, &lineItem)
 	if err != nil {
 		logger.Error("Failed to add line item to database", "error", err)
 		return dto.ErrFailedToAddLineItemToDatabase
@@ -64,39 +140,788 @@ func (a *BillingActivities) AddLineItemActivity(ctx context.Context, billingID i
 	return nil
 }
 
+// MeterUsageActivity claims the usage of a closing billing period until closedAt and returns a line item per meter used,
+// priced at its unit amount. Usage whose amount does not fit in minor units fails the activity without retries.
+func (a *BillingActivities) MeterUsageActivity(ctx context.Context, billingID int64, closedAt time.Time) ([]entities.LineItem, error) {
+	fn := "billingActivities.MeterUsageActivity"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("closedAt", closedAt)
+
+	logger.Info("MeterUsageActivity starting")
+
+	// claim and aggregate usage in database, a retried activity gets the aggregates of the first attempt
+	aggregates, err := a.meterRepository.ClaimUsage(ctx, billingID, closedAt)
+	if err != nil {
+		logger.Error("Failed to claim usage in database", "error", err)
+		return nil, err
+	}
+
+	// meters without usage in the period are not billed
+	lineItems := []entities.LineItem{}
+	for _, aggregate := range aggregates {
+		if aggregate.Quantity == 0 {
+			continue
+		}
+		// the line item ID is derived from the billing and the meter, so that a close run again adds the usage once
+		lineItem, err := aggregate.LineItem()
+		if err != nil {
+			logger.Error("Usage amount is too large", "meter", aggregate.MeterCode, "quantity", aggregate.Quantity, "unitAmountMinor", aggregate.UnitAmountMinor)
+			return nil, temporal.NewNonRetryableApplicationError("usage amount too large", UsageAmountTooLargeErrorType, err)
+		}
+		lineItem.LineItemID = uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmt.Sprintf("usage:%d:%s", billingID, aggregate.MeterCode))).String()
+		lineItems = append(lineItems, lineItem)
+	}
+
+	logger.Info("Usage metered successfully", "meters", len(aggregates), "lineItems", len(lineItems))
+	return lineItems, nil
+}
+
+// RedeemCouponActivity records the redemption of a coupon by a billing, redeeming it again for the same billing is a no-op.
+// A coupon that expired or reached its limit fails the activity without retries.
+func (a *BillingActivities) RedeemCouponActivity(ctx context.Context, couponID int64, billingID int64) error {
+	fn := "billingActivities.RedeemCouponActivity"
+	logger := rlog.With("fn", fn).With("couponID", couponID).With("billingID", billingID)
+
+	logger.Info("RedeemCouponActivity starting")
+
+	// redeem coupon, the database enforces the expiry and the limit under concurrent redemptions
+	err := a.couponRepository.RedeemCoupon(ctx, couponID, billingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrCouponRedemptionLimitReached) {
+			logger.Warn("Coupon can no longer be redeemed")
+			return temporal.NewNonRetryableApplicationError("coupon redemption limit reached", CouponRedemptionLimitReachedErrorType, err)
+		}
+
+		logger.Error("Failed to redeem coupon in database", "error", err)
+		return err
+	}
+
+	logger.Info("Coupon redeemed successfully")
+	return nil
+}
+
 // CloseBillingActivity closes a billing
-func (a *BillingActivities) CloseBillingActivity(ctx context.Context, billingID int64) error {
+func (a *BillingActivities) CloseBillingActivity(ctx context.Context, billingID int64, closedAt time.Time) (string, error) {
 	fn := "billingActivities.CloseBillingActivity"
-	logger := rlog.With("fn", fn).With("billingID", billingID)
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("closedAt", closedAt)
 
 	logger.Info("CloseBillingActivity starting")
 
-	// close billing in database
-	actualClosedAt := time.Now().UTC()
-	err := a.dbRepository.CloseBilling(ctx, billingID, actualClosedAt)
+	// close billing in database at the time its usage was claimed until, a retried activity gets the invoice number
+	// assigned by the first attempt
+	invoiceNumber, err := a.dbRepository.CloseBilling(ctx, billingID, closedAt.UTC())
 	if err != nil {
 		logger.Error("Failed to close billing in database", "error", err)
+		return "", err
+	}
+
+	logger.Info("Billing closed successfully", "invoiceNumber", invoiceNumber)
+	return invoiceNumber, nil
+}
+
+// CancelBillingActivity cancels an open billing
+func (a *BillingActivities) CancelBillingActivity(ctx context.Context, billingID int64, externalBillingID string, reason string) error {
+	fn := "billingActivities.CancelBillingActivity"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("externalBillingID", externalBillingID).With("reason", reason)
+
+	logger.Info("CancelBillingActivity starting")
+
+	// cancel billing in database, a retried activity finds the billing already cancelled
+	err := a.dbRepository.CancelBilling(ctx, billingID, reason, time.Now().UTC())
+	if err != nil {
+		logger.Error("Failed to cancel billing in database", "error", err)
+		return err
+	}
+
+	logger.Info("Billing cancelled successfully")
+	return nil
+}
+
+// LinkNextBillingActivity links a closed billing to the billing of its next period
+func (a *BillingActivities) LinkNextBillingActivity(ctx context.Context, billingID int64, nextExternalBillingID string) error {
+	fn := "billingActivities.LinkNextBillingActivity"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("nextExternalBillingID", nextExternalBillingID)
+
+	logger.Info("LinkNextBillingActivity starting")
+
+	// link billings in database
+	err := a.dbRepository.LinkNextBilling(ctx, billingID, nextExternalBillingID)
+	if err != nil {
+		logger.Error("Failed to link next billing in database", "error", err)
 		return err
 	}
 
-	logger.Info("Billing closed successfully")
+	logger.Info("Next billing linked successfully")
 	return nil
 }
 
-// CreateBillingSummaryActivity creates a billing summary
-func (a *BillingActivities) CreateBillingSummaryActivity(ctx context.Context, externalBillingID string, billingSummary []byte) error {
+// RecordSpendThresholdCrossedActivity writes the alert of a spend limit threshold the billing total reached
+func (a *BillingActivities) RecordSpendThresholdCrossedActivity(ctx context.Context, billingID int64, threshold int64, totalAmountMinor int64) error {
+	fn := "billingActivities.RecordSpendThresholdCrossedActivity"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("threshold", threshold).With("totalAmountMinor", totalAmountMinor)
+
+	logger.Info("RecordSpendThresholdCrossedActivity starting")
+
+	// record threshold in database, spend threshold crossed is written to the outbox with it
+	err := a.dbRepository.RecordSpendThresholdCrossed(ctx, billingID, threshold, totalAmountMinor, time.Now().UTC())
+	if err != nil {
+		logger.Error("Failed to record spend threshold crossed in database", "error", err)
+		return err
+	}
+
+	logger.Info("Spend threshold crossed recorded successfully")
+	return nil
+}
+
+// PrepareSubscriptionRenewalActivity renews a subscription for the period starting at periodStart
+func (a *BillingActivities) PrepareSubscriptionRenewalActivity(ctx context.Context, externalSubscriptionID string, periodStart time.Time) (SubscriptionRenewal, error) {
+	fn := "billingActivities.PrepareSubscriptionRenewalActivity"
+	logger := rlog.With("fn", fn).With("externalSubscriptionID", externalSubscriptionID).With("periodStart", periodStart)
+
+	logger.Info("PrepareSubscriptionRenewalActivity starting")
+
+	// get subscription
+	subscription, err := a.subscriptionRepository.GetSubscriptionByExternalID(ctx, externalSubscriptionID)
+	if err != nil {
+		logger.Error("Failed to get subscription", "error", err)
+		return SubscriptionRenewal{}, err
+	}
+
+	// renew subscription, trials become active once the trial is over
+	previousStatus := subscription.Status
+	if !subscription.Renew(periodStart) {
+		logger.Info("Subscription is cancelled")
+		return SubscriptionRenewal{Renew: false}, nil
+	}
+	if subscription.Status != previousStatus {
+		// a status changed since it was read fails the activity, its retry renews the subscription from the new status
+		err = a.subscriptionRepository.UpdateSubscriptionStatus(ctx, subscription.ID, previousStatus, subscription.Status, nil)
+		if err != nil {
+			logger.Error("Failed to update subscription status", "error", err)
+			return SubscriptionRenewal{}, err
+		}
+	}
+
+	// get current plan
+	plan, err := a.subscriptionRepository.GetPlanByID(ctx, subscription.PlanID)
+	if err != nil {
+		logger.Error("Failed to get plan", "error", err)
+		return SubscriptionRenewal{}, err
+	}
+
+	recurrence, err := plan.RecurrenceRule(subscription.AnchorAt, subscription.Timezone)
+	if err != nil {
+		logger.Error("Failed to get plan recurrence rule", "error", err)
+		return SubscriptionRenewal{}, err
+	}
+
+	lineItem := plan.Charge()
+	if subscription.Status == entities.SubscriptionStatusTrial {
+		lineItem = plan.TrialCharge()
+	}
+
+	logger.Info("Subscription renewed", "status", subscription.Status, "planID", plan.ExternalPlanID)
+	return SubscriptionRenewal{
+		Renew:       true,
+		Description: plan.Name,
+		LineItem:    lineItem,
+		Recurrence:  recurrence,
+	}, nil
+}
+
+// SetSubscriptionCurrentBillingActivity points a subscription to the billing of its current period
+func (a *BillingActivities) SetSubscriptionCurrentBillingActivity(ctx context.Context, externalSubscriptionID string, externalBillingID string) error {
+	fn := "billingActivities.SetSubscriptionCurrentBillingActivity"
+	logger := rlog.With("fn", fn).With("externalSubscriptionID", externalSubscriptionID).With("externalBillingID", externalBillingID)
+
+	logger.Info("SetSubscriptionCurrentBillingActivity starting")
+
+	// get subscription
+	subscription, err := a.subscriptionRepository.GetSubscriptionByExternalID(ctx, externalSubscriptionID)
+	if err != nil {
+		logger.Error("Failed to get subscription", "error", err)
+		return err
+	}
+
+	err = a.subscriptionRepository.SetSubscriptionCurrentBilling(ctx, subscription.ID, externalBillingID)
+	if err != nil {
+		logger.Error("Failed to set subscription current billing", "error", err)
+		return err
+	}
+
+	logger.Info("Subscription current billing set successfully")
+	return nil
+}
+
+// GetTaxRatesActivity gets the tax rates of a jurisdiction, used to compute the tax of a billing when it closes
+func (a *BillingActivities) GetTaxRatesActivity(ctx context.Context, jurisdiction string) ([]entities.TaxRate, error) {
+	fn := "billingActivities.GetTaxRatesActivity"
+	logger := rlog.With("fn", fn).With("jurisdiction", jurisdiction)
+
+	logger.Info("GetTaxRatesActivity starting")
+
+	// get tax rates from database
+	taxRates, err := a.taxRepository.ListTaxRates(ctx, jurisdiction)
+	if err != nil {
+		logger.Error("Failed to get tax rates from database", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Tax rates loaded successfully", "count", len(taxRates))
+	return taxRates, nil
+}
+
+// CreateBillingSummaryActivity creates the summary of a closed billing and pays what it can of the billing from the wallet
+// of its user, returning the amount drawn. The billing is closed once its summary is written, a retried activity gets the
+// amount drawn by the first attempt.
+func (a *BillingActivities) CreateBillingSummaryActivity(ctx context.Context, externalBillingID string, billingSummary []byte) (int64, error) {
 	fn := "billingActivities.CreateBillingSummaryActivity"
 	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID)
 
 	logger.Info("CreateBillingSummaryActivity starting")
 
-	// generate billing summary in database
-	err := a.dbRepository.CreateBillingSummary(ctx, externalBillingID, billingSummary)
+	// generate the IDs of the wallet draw and of its payment
+	transactionUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("Failed to generate external wallet transaction ID", "error", err)
+		return 0, dto.ErrFailedToGenerateWalletTransactionID
+	}
+	paymentUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("Failed to generate external payment ID", "error", err)
+		return 0, dto.ErrFailedToGeneratePaymentID
+	}
+
+	// generate billing summary in database, billing closed is written to the outbox and the wallet is drawn with it
+	walletDraw := &entities.WalletDraw{
+		ExternalTransactionID: transactionUUID.String(),
+		ExternalPaymentID:     paymentUUID.String(),
+	}
+	drawnMinor, err := a.dbRepository.CreateBillingSummary(ctx, externalBillingID, billingSummary, walletDraw)
 	if err != nil {
+		if errors.Is(err, entities.ErrBillingSummaryMismatch) {
+			logger.Error("Billing summary differs from the one already created")
+			return 0, temporal.NewNonRetryableApplicationError("billing summary mismatch", BillingSummaryMismatchErrorType, err)
+		}
+
 		logger.Error("Failed to generate billing summary in database", "error", err)
+		return 0, err
+	}
+
+	logger.Info("Billing summary created", "drawnMinor", drawnMinor)
+	return drawnMinor, nil
+}
+
+// ChargeBillingActivity charges what is due on a closed billing through the payment gateway and records the outcome.
+// A captured charge is recorded as a card payment. A gateway that does not answer fails the activity so that the charge is
+// sent again with the same idempotency key, the last attempt looks the charge up before recording it as failed or pending.
+func (a *BillingActivities) ChargeBillingActivity(ctx context.Context, externalBillingID string) (entities.ChargeAttempt, error) {
+	return a.chargeBilling(ctx, externalBillingID, "charge:"+externalBillingID)
+}
+
+// RetryBillingChargeActivity charges a billing again at a step of its dunning, each step is a new charge at the gateway
+func (a *BillingActivities) RetryBillingChargeActivity(ctx context.Context, externalBillingID string, step int) (entities.ChargeAttempt, error) {
+	return a.chargeBilling(ctx, externalBillingID, fmt.Sprintf("charge:%s:dunning-%d", externalBillingID, step))
+}
+
+// chargeBilling charges what is left to pay on a billing with a charge identified by idempotencyKey
+func (a *BillingActivities) chargeBilling(ctx context.Context, externalBillingID string, idempotencyKey string) (entities.ChargeAttempt, error) {
+	fn := "billingActivities.chargeBilling"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("idempotencyKey", idempotencyKey)
+
+	logger.Info("Charging billing")
+
+	// get billing
+	billing, err := a.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		logger.Error("Failed to get billing", "error", err)
+		return entities.ChargeAttempt{}, err
+	}
+
+	// charge what is not paid yet
+	dueAmountMinor, err := a.outstandingAmountMinor(ctx, billing)
+	if err != nil {
+		return entities.ChargeAttempt{}, err
+	}
+	if dueAmountMinor <= 0 {
+		logger.Info("Billing has nothing due, charge skipped")
+		return entities.ChargeAttempt{}, nil
+	}
+
+	// a charge of unknown outcome may have collected the money, the billing is not charged again until it is settled
+	attempts, err := a.paymentRepository.ListChargeAttemptsByBillingID(ctx, billing.ID)
+	if err != nil {
+		logger.Error("Failed to list charge attempts", "error", err)
+		return entities.ChargeAttempt{}, err
+	}
+	for _, previous := range attempts {
+		if previous.Status == entities.ChargeAttemptStatusPending {
+			logger.Warn("Billing has a pending charge, charge skipped", "gatewayChargeID", previous.GatewayChargeID)
+			return previous, nil
+		}
+	}
+
+	attempt := entities.ChargeAttempt{
+		BillingID:         billing.ID,
+		ExternalBillingID: billing.ExternalBillingID,
+		AmountMinor:       dueAmountMinor,
+		Currency:          billing.Currency,
+	}
+
+	// authorize and capture charge, a retried activity gets the charge of the first attempt
+	charge, err := a.paymentGateway.CreateCharge(ctx, entities.ChargeRequest{
+		IdempotencyKey: idempotencyKey,
+		CustomerID:     billing.UserID,
+		AmountMinor:    dueAmountMinor,
+		Currency:       billing.Currency,
+		Description:    billing.Description,
+	})
+	if err == nil && charge.Status != entities.ChargeStatusDeclined {
+		charge, err = a.paymentGateway.CaptureCharge(ctx, charge.ID)
+	}
+	if err != nil {
+		if activity.GetInfo(ctx).Attempt < ChargeMaxAttempts {
+			logger.Warn("Failed to charge billing, charge will be retried", "error", err)
+			return entities.ChargeAttempt{}, temporal.NewApplicationError("charge attempt failed", "ChargeFailed", err.Error())
+		}
+
+		// the gateway may have charged without answering, so the charge is looked up under its idempotency key. A charge the
+		// gateway never made failed, a charge it did not decline or capture, or that cannot be looked up, is pending.
+		chargeErr := err
+		charge, err = a.paymentGateway.GetChargeByIdempotencyKey(ctx, idempotencyKey)
+		switch {
+		case errors.Is(err, entities.ErrChargeNotFound):
+			attempt.Status = entities.ChargeAttemptStatusFailed
+		case err != nil:
+			logger.Warn("Failed to look up unanswered charge", "error", err)
+			attempt.Status = entities.ChargeAttemptStatusPending
+		case !charge.IsCaptured() && charge.Status != entities.ChargeStatusDeclined:
+			attempt.Status = entities.ChargeAttemptStatusPending
+			attempt.GatewayChargeID = charge.ID
+		}
+		if attempt.Status != "" {
+			attempt.Error = chargeErr.Error()
+			if _, err = a.paymentRepository.CreateChargeAttempt(ctx, &attempt); err != nil {
+				logger.Error("Failed to record charge attempt", "error", err)
+				return entities.ChargeAttempt{}, err
+			}
+
+			logger.Warn("Billing charge not settled", "status", attempt.Status, "gatewayChargeID", attempt.GatewayChargeID, "error", attempt.Error)
+			return attempt, nil
+		}
+
+		logger.Info("Unanswered charge found at the gateway", "gatewayChargeID", charge.ID, "status", charge.Status)
+	}
+
+	attempt.GatewayChargeID = charge.ID
+	if charge.Status == entities.ChargeStatusDeclined {
+		attempt.Status = entities.ChargeAttemptStatusDeclined
+		attempt.DeclineCode = charge.DeclineCode
+		if _, err = a.paymentRepository.CreateChargeAttempt(ctx, &attempt); err != nil {
+			logger.Error("Failed to record charge attempt", "error", err)
+			return entities.ChargeAttempt{}, err
+		}
+
+		logger.Warn("Billing charge declined", "gatewayChargeID", charge.ID, "declineCode", charge.DeclineCode)
+		return attempt, nil
+	}
+
+	// record captured charge as a payment, the charge ID keeps a retried activity from recording it twice
+	randomUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("Failed to generate external payment ID", "error", err)
+		return entities.ChargeAttempt{}, dto.ErrFailedToGeneratePaymentID
+	}
+	_, err = a.paymentRepository.CreatePayment(ctx, &entities.Payment{
+		ExternalPaymentID: randomUUID.String(),
+		BillingID:         billing.ID,
+		AmountMinor:       charge.AmountMinor,
+		Currency:          charge.Currency,
+		Method:            entities.PaymentMethodCard,
+		ExternalReference: charge.ID,
+		PaidAt:            time.Now().UTC(),
+	})
+	if err != nil {
+		logger.Error("Failed to record charge payment", "error", err)
+		return entities.ChargeAttempt{}, err
+	}
+
+	attempt.Status = entities.ChargeAttemptStatusSucceeded
+	if _, err = a.paymentRepository.CreateChargeAttempt(ctx, &attempt); err != nil {
+		logger.Error("Failed to record charge attempt", "error", err)
+		return entities.ChargeAttempt{}, err
+	}
+
+	logger.Info("Billing charged successfully", "gatewayChargeID", charge.ID, "amountMinor", charge.AmountMinor)
+	return attempt, nil
+}
+
+// StartDunningActivity starts the dunning of a billing, a retried activity gets the dunning started by the first attempt
+func (a *BillingActivities) StartDunningActivity(ctx context.Context, externalBillingID string, scheduleDays []int) (entities.Dunning, error) {
+	fn := "billingActivities.StartDunningActivity"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("scheduleDays", scheduleDays)
+
+	logger.Info("StartDunningActivity starting")
+
+	billing, err := a.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		logger.Error("Failed to get billing", "error", err)
+		return entities.Dunning{}, err
+	}
+
+	dunning, err := a.dunningRepository.CreateDunning(ctx, &entities.Dunning{
+		BillingID:    billing.ID,
+		ScheduleDays: scheduleDays,
+	})
+	if err != nil {
+		logger.Error("Failed to create dunning in database", "error", err)
+		return entities.Dunning{}, err
+	}
+
+	logger.Info("Dunning started", "status", dunning.Status, "step", dunning.Step)
+	return *dunning, nil
+}
+
+// DunningStepActivity runs a step of the dunning of a billing: a paid billing resolves the dunning, an unpaid one escalates
+// to past due, or to uncollectible at the last step, and is sent a payment reminder
+func (a *BillingActivities) DunningStepActivity(ctx context.Context, externalBillingID string, step int, steps int) (entities.DunningStatus, error) {
+	fn := "billingActivities.DunningStepActivity"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("step", step).With("steps", steps)
+
+	logger.Info("DunningStepActivity starting")
+
+	billing, err := a.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		logger.Error("Failed to get billing", "error", err)
+		return "", err
+	}
+
+	outstandingAmountMinor, err := a.outstandingAmountMinor(ctx, billing)
+	if err != nil {
+		return "", err
+	}
+
+	// a billing paid without a payment signal is resolved at its next step
+	if outstandingAmountMinor <= 0 {
+		err = a.dunningRepository.ResolveDunning(ctx, billing.ID)
+		if err != nil {
+			logger.Error("Failed to resolve dunning", "error", err)
+			return "", err
+		}
+
+		err = a.updateSubscriptionDunningStatus(ctx, billing, entities.DunningStatusResolved)
+		if err != nil {
+			return "", err
+		}
+
+		logger.Info("Billing is paid, dunning resolved")
+		return entities.DunningStatusResolved, nil
+	}
+
+	// escalate dunning, payment reminder is written to the outbox with it
+	status := entities.DunningStepStatus(step, steps)
+	err = a.dunningRepository.AdvanceDunning(ctx, billing.ID, step, status, outstandingAmountMinor, time.Now().UTC())
+	if err != nil {
+		logger.Error("Failed to advance dunning", "error", err)
+		return "", err
+	}
+
+	// the subscription of the billing is past due while it is chased, and cancelled once the billing is uncollectible
+	err = a.updateSubscriptionDunningStatus(ctx, billing, status)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Info("Payment reminder sent", "status", status, "outstandingAmountMinor", outstandingAmountMinor)
+	return status, nil
+}
+
+// ResolveDunningActivity stops the dunning of a billing that was paid
+func (a *BillingActivities) ResolveDunningActivity(ctx context.Context, externalBillingID string) error {
+	fn := "billingActivities.ResolveDunningActivity"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID)
+
+	logger.Info("ResolveDunningActivity starting")
+
+	billing, err := a.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		logger.Error("Failed to get billing", "error", err)
+		return err
+	}
+
+	err = a.dunningRepository.ResolveDunning(ctx, billing.ID)
+	if err != nil {
+		logger.Error("Failed to resolve dunning", "error", err)
 		return err
 	}
 
+	// the subscription of the billing is active again once it is paid
+	err = a.updateSubscriptionDunningStatus(ctx, billing, entities.DunningStatusResolved)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Dunning resolved")
+	return nil
+}
+
+// UpdateSubscriptionPaymentStatusActivity moves the subscription of a closed billing to past due while the billing is unpaid,
+// and back to active once it is paid. Billings without a subscription are skipped.
+func (a *BillingActivities) UpdateSubscriptionPaymentStatusActivity(ctx context.Context, externalBillingID string) error {
+	fn := "billingActivities.UpdateSubscriptionPaymentStatusActivity"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID)
+
+	logger.Info("UpdateSubscriptionPaymentStatusActivity starting")
+
+	billing, err := a.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		logger.Error("Failed to get billing", "error", err)
+		return err
+	}
+	if billing.SubscriptionID == nil {
+		logger.Info("Billing has no subscription")
+		return nil
+	}
+
+	outstandingAmountMinor, err := a.outstandingAmountMinor(ctx, billing)
+	if err != nil {
+		return err
+	}
+
+	paid := outstandingAmountMinor <= 0
+	return a.updateSubscriptionStatus(ctx, billing, func(subscription *entities.Subscription) entities.SubscriptionStatus {
+		return subscription.StatusAfterPayment(paid)
+	})
+}
+
+// updateSubscriptionDunningStatus moves the subscription of a billing to the status it has once the dunning of the billing
+// reached dunningStatus
+func (a *BillingActivities) updateSubscriptionDunningStatus(ctx context.Context, billing *entities.Billing, dunningStatus entities.DunningStatus) error {
+	return a.updateSubscriptionStatus(ctx, billing, func(subscription *entities.Subscription) entities.SubscriptionStatus {
+		return subscription.StatusAfterDunning(dunningStatus)
+	})
+}
+
+// updateSubscriptionStatus moves the subscription of a billing to the status statusOf returns for it, billings without a
+// subscription are skipped
+func (a *BillingActivities) updateSubscriptionStatus(ctx context.Context, billing *entities.Billing, statusOf func(subscription *entities.Subscription) entities.SubscriptionStatus) error {
+	if billing.SubscriptionID == nil {
+		return nil
+	}
+	logger := rlog.With("fn", "billingActivities.updateSubscriptionStatus").With("externalBillingID", billing.ExternalBillingID).With("externalSubscriptionID", *billing.SubscriptionID)
+
+	subscription, err := a.subscriptionRepository.GetSubscriptionByExternalID(ctx, *billing.SubscriptionID)
+	if err != nil {
+		logger.Error("Failed to get subscription", "error", err)
+		return err
+	}
+
+	status := statusOf(subscription)
+	if status == subscription.Status {
+		return nil
+	}
+
+	var cancelledAt *time.Time
+	if status == entities.SubscriptionStatusCancelled {
+		now := time.Now().UTC()
+		cancelledAt = &now
+	}
+
+	// a status changed since it was read fails the activity, its retry checks the transition from the new status
+	err = a.subscriptionRepository.UpdateSubscriptionStatus(ctx, subscription.ID, subscription.Status, status, cancelledAt)
+	if err != nil {
+		logger.Error("Failed to update subscription status", "error", err)
+		return err
+	}
+
+	logger.Info("Subscription status updated", "previousStatus", subscription.Status, "status", status)
+	return nil
+}
+
+// outstandingAmountMinor is what is left to pay on a closed billing, its grand total net of credit notes less its payments net of refunds
+func (a *BillingActivities) outstandingAmountMinor(ctx context.Context, billing *entities.Billing) (int64, error) {
+	logger := rlog.With("fn", "billingActivities.outstandingAmountMinor").With("externalBillingID", billing.ExternalBillingID)
+
+	summary, err := a.dbRepository.GetBillingSummary(ctx, billing.ExternalBillingID)
+	if err != nil {
+		logger.Error("Failed to get billing summary", "error", err)
+		return 0, err
+	}
+
+	creditNotes, err := a.creditNoteRepository.ListCreditNotesByBillingID(ctx, billing.ID)
+	if err != nil {
+		logger.Error("Failed to list credit notes", "error", err)
+		return 0, err
+	}
+	var creditedAmountMinor int64
+	for _, creditNote := range creditNotes {
+		creditedAmountMinor += creditNote.TotalAmountMinor
+	}
+
+	payments, err := a.paymentRepository.ListPaymentsByBillingID(ctx, billing.ID)
+	if err != nil {
+		logger.Error("Failed to list payments", "error", err)
+		return 0, err
+	}
+	var paidAmountMinor int64
+	for _, payment := range payments {
+		paidAmountMinor += payment.NetAmountMinor()
+	}
+
+	due := entities.NewCreditedBalance(summary.BilledAmountMinor(), creditedAmountMinor).NetAmountMinor
+	return entities.NewPaymentBalance(due, paidAmountMinor).OutstandingAmountMinor, nil
+}
+
+// RefundPaymentActivity returns a pending refund to the payer. Payments charged by the payment gateway are refunded
+// through it, other payments were collected outside the service and their refund is recorded as it is.
+func (a *BillingActivities) RefundPaymentActivity(ctx context.Context, externalRefundID string) error {
+	fn := "billingActivities.RefundPaymentActivity"
+	logger := rlog.With("fn", fn).With("externalRefundID", externalRefundID)
+
+	logger.Info("RefundPaymentActivity starting")
+
+	// get refund and its payment
+	refund, err := a.refundRepository.GetRefundByExternalID(ctx, externalRefundID)
+	if err != nil {
+		logger.Error("Failed to get refund", "error", err)
+		return err
+	}
+	if refund.IsCompleted() {
+		logger.Info("Refund already completed", "status", refund.Status)
+		return nil
+	}
+
+	payment, err := a.paymentRepository.GetPaymentByExternalID(ctx, refund.ExternalPaymentID)
+	if err != nil {
+		logger.Error("Failed to get payment", "error", err)
+		return err
+	}
+
+	charged, err := a.isGatewayCharge(ctx, payment)
+	if err != nil {
+		return err
+	}
+	if !charged {
+		if err = a.refundRepository.CompleteRefund(ctx, refund.ID, entities.RefundStatusSucceeded, ""); err != nil {
+			logger.Error("Failed to complete refund", "error", err)
+			return err
+		}
+
+		logger.Info("Refund of payment collected outside the gateway recorded")
+		return nil
+	}
+
+	// refund charge, a retried activity is refunded once by the gateway
+	_, err = a.paymentGateway.RefundCharge(ctx, payment.ExternalReference, refund.AmountMinor, fmt.Sprintf("refund:%s", refund.ExternalRefundID))
+	if err != nil {
+		rejected := errors.Is(err, entities.ErrChargeNotFound) || errors.Is(err, entities.ErrInvalidChargeState)
+		if !rejected && activity.GetInfo(ctx).Attempt < RefundMaxAttempts {
+			logger.Warn("Failed to refund charge, refund will be retried", "error", err)
+			return temporal.NewApplicationError("refund attempt failed", "RefundFailed", err.Error())
+		}
+
+		refundError := err.Error()
+		if err = a.refundRepository.CompleteRefund(ctx, refund.ID, entities.RefundStatusFailed, refundError); err != nil {
+			logger.Error("Failed to complete refund", "error", err)
+			return err
+		}
+
+		logger.Warn("Refund failed", "error", refundError)
+		return nil
+	}
+
+	if err = a.refundRepository.CompleteRefund(ctx, refund.ID, entities.RefundStatusSucceeded, ""); err != nil {
+		logger.Error("Failed to complete refund", "error", err)
+		return err
+	}
+
+	logger.Info("Payment refunded successfully", "gatewayChargeID", payment.ExternalReference, "amountMinor", refund.AmountMinor)
+	return nil
+}
+
+// isGatewayCharge tells whether a payment was collected by a charge of the payment gateway
+func (a *BillingActivities) isGatewayCharge(ctx context.Context, payment *entities.Payment) (bool, error) {
+	fn := "billingActivities.isGatewayCharge"
+	logger := rlog.With("fn", fn).With("externalPaymentID", payment.ExternalPaymentID)
+
+	if payment.Method != entities.PaymentMethodCard || payment.ExternalReference == "" {
+		return false, nil
+	}
+
+	attempts, err := a.paymentRepository.ListChargeAttemptsByBillingID(ctx, payment.BillingID)
+	if err != nil {
+		logger.Error("Failed to list charge attempts", "error", err)
+		return false, err
+	}
+	for _, attempt := range attempts {
+		if attempt.Status == entities.ChargeAttemptStatusSucceeded && attempt.GatewayChargeID == payment.ExternalReference {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RelayOutboxActivity publishes the pending outbox messages in order and returns how many were published
+func (a *BillingActivities) RelayOutboxActivity(ctx context.Context) (int, error) {
+	fn := "billingActivities.RelayOutboxActivity"
+	logger := rlog.With("fn", fn)
+
+	relayed, err := a.outboxRepository.RelayOutbox(ctx, OutboxRelayBatchSize, func(message *entities.OutboxMessage) error {
+		return a.eventPublisher.Publish(ctx, message)
+	})
+	if err != nil {
+		logger.Error("Failed to relay outbox", "relayed", relayed, "error", err)
+		return relayed, err
+	}
+
+	return relayed, nil
+}
+
+// DeliverWebhookActivity sends a delivery to its webhook and logs the attempt, a rejected attempt fails the activity so that it is retried
+func (a *BillingActivities) DeliverWebhookActivity(ctx context.Context, externalDeliveryID string) error {
+	fn := "billingActivities.DeliverWebhookActivity"
+	logger := rlog.With("fn", fn).With("externalDeliveryID", externalDeliveryID)
+
+	logger.Info("DeliverWebhookActivity starting")
+
+	// get delivery and its webhook
+	delivery, err := a.webhookRepository.GetWebhookDeliveryByExternalID(ctx, externalDeliveryID)
+	if err != nil {
+		logger.Error("Failed to get webhook delivery", "error", err)
+		return err
+	}
+	if delivery.Status == entities.WebhookDeliveryStatusSucceeded {
+		logger.Info("Webhook delivery already succeeded")
+		return nil
+	}
+
+	webhook, err := a.webhookRepository.GetWebhookByExternalID(ctx, delivery.ExternalWebhookID)
+	if err != nil {
+		logger.Error("Failed to get webhook", "error", err)
+		return err
+	}
+
+	// send delivery, the last attempt marks the delivery as failed
+	attempt := a.webhookSender.Send(ctx, webhook, delivery)
+	status := entities.WebhookDeliveryStatusPending
+	if attempt.Succeeded() {
+		status = entities.WebhookDeliveryStatusSucceeded
+	} else if activity.GetInfo(ctx).Attempt >= WebhookDeliveryMaxAttempts {
+		status = entities.WebhookDeliveryStatusFailed
+	}
+
+	err = a.webhookRepository.RecordWebhookDeliveryAttempt(ctx, delivery.ID, attempt, status)
+	if err != nil {
+		logger.Error("Failed to record webhook delivery attempt", "error", err)
+		return err
+	}
+
+	if status != entities.WebhookDeliveryStatusSucceeded {
+		logger.Warn("Webhook delivery attempt failed", "statusCode", attempt.StatusCode, "error", attempt.Error, "status", status)
+		return temporal.NewApplicationError("webhook delivery attempt failed", "WebhookDeliveryFailed", attempt.StatusCode, attempt.Error)
+	}
+
+	logger.Info("Webhook delivered successfully", "statusCode", attempt.StatusCode)
 	return nil
 }
 
@@ -112,33 +937,169 @@ func SetActivityInstance(activities *BillingActivities) {
 }
 
 // StartBillingActivityFunc is a package-level function wrapper for StartBillingActivity
-func StartBillingActivityFunc(ctx context.Context, userID string, externalBillingID string, description string, currency string, currencyPrecision int64, plannedClosedAt *time.Time) (int64, error) {
+func StartBillingActivityFunc(ctx context.Context, billing entities.Billing) (int64, error) {
 	if activityInstance == nil {
 		panic("activity instance not initialized - call SetActivityInstance first")
 	}
-	return activityInstance.StartBillingActivity(ctx, userID, externalBillingID, description, currency, currencyPrecision, plannedClosedAt)
+	return activityInstance.StartBillingActivity(ctx, billing)
 }
 
 // AddLineItemActivityFunc is a package-level function wrapper for AddLineItemActivity
-func AddLineItemActivityFunc(ctx context.Context, billingID int64, description string, amount int64) error {
+func AddLineItemActivityFunc(ctx context.Context, billingID int64, lineItem entities.LineItem, externalBillingID string) error {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.AddLineItemActivity(ctx, billingID, lineItem, externalBillingID)
+}
+
+// RedeemCouponActivityFunc is a package-level function wrapper for RedeemCouponActivity
+func RedeemCouponActivityFunc(ctx context.Context, couponID int64, billingID int64) error {
 	if activityInstance == nil {
 		panic("activity instance not initialized - call SetActivityInstance first")
 	}
-	return activityInstance.AddLineItemActivity(ctx, billingID, description, amount)
+	return activityInstance.RedeemCouponActivity(ctx, couponID, billingID)
 }
 
 // CloseBillingActivityFunc is a package-level function wrapper for CloseBillingActivity
-func CloseBillingActivityFunc(ctx context.Context, billingID int64) error {
+func CloseBillingActivityFunc(ctx context.Context, billingID int64, closedAt time.Time) (string, error) {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.CloseBillingActivity(ctx, billingID, closedAt)
+}
+
+// MeterUsageActivityFunc is a package-level function wrapper for MeterUsageActivity
+func MeterUsageActivityFunc(ctx context.Context, billingID int64, closedAt time.Time) ([]entities.LineItem, error) {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.MeterUsageActivity(ctx, billingID, closedAt)
+}
+
+// CancelBillingActivityFunc is a package-level function wrapper for CancelBillingActivity
+func CancelBillingActivityFunc(ctx context.Context, billingID int64, externalBillingID string, reason string) error {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.CancelBillingActivity(ctx, billingID, externalBillingID, reason)
+}
+
+// LinkNextBillingActivityFunc is a package-level function wrapper for LinkNextBillingActivity
+func LinkNextBillingActivityFunc(ctx context.Context, billingID int64, nextExternalBillingID string) error {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.LinkNextBillingActivity(ctx, billingID, nextExternalBillingID)
+}
+
+// RecordSpendThresholdCrossedActivityFunc is a package-level function wrapper for RecordSpendThresholdCrossedActivity
+func RecordSpendThresholdCrossedActivityFunc(ctx context.Context, billingID int64, threshold int64, totalAmountMinor int64) error {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.RecordSpendThresholdCrossedActivity(ctx, billingID, threshold, totalAmountMinor)
+}
+
+// PrepareSubscriptionRenewalActivityFunc is a package-level function wrapper for PrepareSubscriptionRenewalActivity
+func PrepareSubscriptionRenewalActivityFunc(ctx context.Context, externalSubscriptionID string, periodStart time.Time) (SubscriptionRenewal, error) {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.PrepareSubscriptionRenewalActivity(ctx, externalSubscriptionID, periodStart)
+}
+
+// SetSubscriptionCurrentBillingActivityFunc is a package-level function wrapper for SetSubscriptionCurrentBillingActivity
+func SetSubscriptionCurrentBillingActivityFunc(ctx context.Context, externalSubscriptionID string, externalBillingID string) error {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.SetSubscriptionCurrentBillingActivity(ctx, externalSubscriptionID, externalBillingID)
+}
+
+// GetTaxRatesActivityFunc is a package-level function wrapper for GetTaxRatesActivity
+func GetTaxRatesActivityFunc(ctx context.Context, jurisdiction string) ([]entities.TaxRate, error) {
 	if activityInstance == nil {
 		panic("activity instance not initialized - call SetActivityInstance first")
 	}
-	return activityInstance.CloseBillingActivity(ctx, billingID)
+	return activityInstance.GetTaxRatesActivity(ctx, jurisdiction)
 }
 
 // CreateBillingSummaryActivityFunc is a package-level function wrapper for CreateBillingSummaryActivity
-func CreateBillingSummaryActivityFunc(ctx context.Context, externalBillingID string, billingSummary []byte) error {
+func CreateBillingSummaryActivityFunc(ctx context.Context, externalBillingID string, billingSummary []byte) (int64, error) {
 	if activityInstance == nil {
 		panic("activity instance not initialized - call SetActivityInstance first")
 	}
 	return activityInstance.CreateBillingSummaryActivity(ctx, externalBillingID, billingSummary)
 }
+
+// ChargeBillingActivityFunc is a package-level function wrapper for ChargeBillingActivity
+func ChargeBillingActivityFunc(ctx context.Context, externalBillingID string) (entities.ChargeAttempt, error) {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.ChargeBillingActivity(ctx, externalBillingID)
+}
+
+// RetryBillingChargeActivityFunc is a package-level function wrapper for RetryBillingChargeActivity
+func RetryBillingChargeActivityFunc(ctx context.Context, externalBillingID string, step int) (entities.ChargeAttempt, error) {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.RetryBillingChargeActivity(ctx, externalBillingID, step)
+}
+
+// StartDunningActivityFunc is a package-level function wrapper for StartDunningActivity
+func StartDunningActivityFunc(ctx context.Context, externalBillingID string, scheduleDays []int) (entities.Dunning, error) {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.StartDunningActivity(ctx, externalBillingID, scheduleDays)
+}
+
+// DunningStepActivityFunc is a package-level function wrapper for DunningStepActivity
+func DunningStepActivityFunc(ctx context.Context, externalBillingID string, step int, steps int) (entities.DunningStatus, error) {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.DunningStepActivity(ctx, externalBillingID, step, steps)
+}
+
+// ResolveDunningActivityFunc is a package-level function wrapper for ResolveDunningActivity
+func ResolveDunningActivityFunc(ctx context.Context, externalBillingID string) error {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.ResolveDunningActivity(ctx, externalBillingID)
+}
+
+// UpdateSubscriptionPaymentStatusActivityFunc is a package-level function wrapper for UpdateSubscriptionPaymentStatusActivity
+func UpdateSubscriptionPaymentStatusActivityFunc(ctx context.Context, externalBillingID string) error {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.UpdateSubscriptionPaymentStatusActivity(ctx, externalBillingID)
+}
+
+// RelayOutboxActivityFunc is a package-level function wrapper for RelayOutboxActivity
+func RelayOutboxActivityFunc(ctx context.Context) (int, error) {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.RelayOutboxActivity(ctx)
+}
+
+// DeliverWebhookActivityFunc is a package-level function wrapper for DeliverWebhookActivity
+func DeliverWebhookActivityFunc(ctx context.Context, externalDeliveryID string) error {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.DeliverWebhookActivity(ctx, externalDeliveryID)
+}
+
+// RefundPaymentActivityFunc is a package-level function wrapper for RefundPaymentActivity
+func RefundPaymentActivityFunc(ctx context.Context, externalRefundID string) error {
+	if activityInstance == nil {
+		panic("activity instance not initialized - call SetActivityInstance first")
+	}
+	return activityInstance.RefundPaymentActivity(ctx, externalRefundID)
+}
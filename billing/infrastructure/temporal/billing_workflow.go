@@ -2,13 +2,17 @@ package temporal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"encore.dev/rlog"
+	"go.temporal.io/api/serviceerror"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
 
 	"encore.app/billing/domain/entities"
+	"encore.app/billing/infrastructure/temporal/activities"
 	"encore.app/billing/infrastructure/temporal/workflows"
 	"encore.app/billing/usecases/dto"
 	"encore.app/billing/usecases/ports"
@@ -17,7 +21,7 @@ import (
 const (
 	BillingWorkflowName = "billing-workflow"
 	WorkflowTaskQueue   = "billing-workflow-task-queue"
-	WorkflowIDPrefix    = "billing-workflow-"
+	WorkflowIDPrefix    = workflows.BillingWorkflowIDPrefix
 )
 
 type TemporalBillingWorkflow struct {
@@ -33,22 +37,41 @@ func NewTemporalBillingWorkflow(client client.Client, taskQueue string) ports.Bi
 }
 
 // StartBilling starts a billing workflow
-func (s *TemporalBillingWorkflow) StartBilling(ctx context.Context, userID string, externalBillingID string, description string, currency string, currencyPrecision int64, plannedClosedAt *time.Time) error {
-	logger := rlog.With("fn", "TemporalBillingWorkflow.StartBill").With("userID", userID).With("externalBillingID", externalBillingID).With("description", description).With("currency", currency).With("currencyPrecision", currencyPrecision).With("plannedClosedAt", plannedClosedAt)
+func (s *TemporalBillingWorkflow) StartBilling(ctx context.Context, billing *entities.Billing, initialLineItems []entities.LineItem) error {
+	logger := rlog.With("fn", "TemporalBillingWorkflow.StartBill").With("userID", billing.UserID).With("externalBillingID", billing.ExternalBillingID).With("description", billing.Description).With("currency", billing.Currency).With("currencyPrecision", billing.CurrencyPrecision).With("plannedClosedAt", billing.PlannedClosedAt)
 
-	workflowID := fmt.Sprintf("%s%s", WorkflowIDPrefix, externalBillingID)
+	workflowID := fmt.Sprintf("%s%s", WorkflowIDPrefix, billing.ExternalBillingID)
 	workflowOptions := client.StartWorkflowOptions{
 		ID:        workflowID,
 		TaskQueue: s.taskQueue,
 	}
 
+	now := time.Now().UTC()
+	lineItems := make([]workflows.LineItemState, len(initialLineItems))
+	for i, lineItem := range initialLineItems {
+		lineItems[i] = workflows.NewLineItemState(lineItem, now)
+	}
+
 	input := workflows.BillingWorkflowInput{
-		UserID:            userID,
-		ExternalBillingID: externalBillingID,
-		Description:       description,
-		Currency:          currency,
-		CurrencyPrecision: currencyPrecision,
-		PlannedClosedAt:   plannedClosedAt,
+		UserID:             billing.UserID,
+		ExternalBillingID:  billing.ExternalBillingID,
+		Description:        billing.Description,
+		Currency:           billing.Currency,
+		CurrencyPrecision:  billing.CurrencyPrecision,
+		PlannedClosedAt:    billing.PlannedClosedAt,
+		PeriodStart:        billing.PeriodStart,
+		PeriodEnd:          billing.PeriodEnd,
+		Timezone:           billing.Timezone,
+		Recurrence:         billing.Recurrence,
+		SubscriptionID:     billing.SubscriptionID,
+		PreviousBillingID:  billing.PreviousBillingID,
+		TaxJurisdiction:    billing.TaxJurisdiction,
+		TaxInclusive:       billing.TaxInclusive,
+		AllowNegativeTotal: billing.AllowNegativeTotal,
+		UserGroup:          billing.UserGroup,
+		AutoCharge:         billing.AutoCharge,
+		SpendLimit:         billing.SpendLimit,
+		InitialLineItems:   lineItems,
 	}
 
 	logger.Info("Starting billing workflow", "workflowID", workflowID)
@@ -63,25 +86,51 @@ func (s *TemporalBillingWorkflow) StartBilling(ctx context.Context, userID strin
 	return nil
 }
 
-// AddLineItem sends a signal to add a line item to the billing workflow
-func (s *TemporalBillingWorkflow) AddLineItem(ctx context.Context, externalBillingID string, description string, amountMinor int64) error {
-	logger := rlog.With("fn", "TemporalBillingWorkflow.AddLineItem").With("externalBillingID", externalBillingID).With("description", description).With("amountMinor", amountMinor)
+// AddLineItem sends an update to add a line item to the billing workflow and waits for it to be added
+func (s *TemporalBillingWorkflow) AddLineItem(ctx context.Context, externalBillingID string, lineItem entities.LineItem) error {
+	logger := rlog.With("fn", "TemporalBillingWorkflow.AddLineItem").With("externalBillingID", externalBillingID).With("description", lineItem.Description).With("amountMinor", lineItem.AmountMinor).With("taxCode", lineItem.TaxCode).With("lineItemID", lineItem.LineItemID).With("kind", lineItem.Kind)
 
-	workflowID := fmt.Sprintf("%s%s", WorkflowIDPrefix, externalBillingID)
+	lineItemState := workflows.NewLineItemState(lineItem, time.Now().UTC())
+
+	err := s.updateWorkflow(ctx, externalBillingID, workflows.AddLineItemUpdate, nil, lineItemState)
+	if err != nil {
+		if updateErrorType(err) == workflows.BillingNotOpenErrorType {
+			logger.Warn("Billing is not open")
+			return dto.ErrBillingNotOpen
+		}
+		if rejection := workflows.LineItemRejection(err); rejection != nil {
+			logger.Warn("Line item rejected", "error", rejection)
+			return rejection
+		}
 
-	lineItem := workflows.LineItemState{
-		Description: description,
-		AmountMinor: amountMinor,
-		AddedAt:     time.Now().UTC(),
+		logger.Error("Failed to update add-line-item", "error", err)
+		return fmt.Errorf("failed to update add-line-item: %w", err)
 	}
 
-	err := s.client.SignalWorkflow(ctx, workflowID, "", workflows.AddLineItemSignal, lineItem)
+	logger.Info("Add-line-item update completed")
+	return nil
+}
+
+// ApplyCoupon sends an update to redeem and apply a coupon to the billing workflow and waits for it to be applied
+func (s *TemporalBillingWorkflow) ApplyCoupon(ctx context.Context, externalBillingID string, coupon entities.Coupon) error {
+	logger := rlog.With("fn", "TemporalBillingWorkflow.ApplyCoupon").With("externalBillingID", externalBillingID).With("code", coupon.Code)
+
+	err := s.updateWorkflow(ctx, externalBillingID, workflows.ApplyCouponUpdate, nil, coupon)
 	if err != nil {
-		logger.Error("Failed to signal add-line-item", "error", err)
-		return fmt.Errorf("failed to signal add-line-item: %w", err)
+		switch updateErrorType(err) {
+		case workflows.BillingNotOpenErrorType:
+			logger.Warn("Billing is not open")
+			return dto.ErrBillingNotOpen
+		case activities.CouponRedemptionLimitReachedErrorType:
+			logger.Warn("Coupon can no longer be redeemed")
+			return dto.ErrCouponRedemptionLimitReached
+		}
+
+		logger.Error("Failed to update apply-coupon", "error", err)
+		return fmt.Errorf("failed to update apply-coupon: %w", err)
 	}
 
-	logger.Info("Add-line-item signal sent", "workflowID", workflowID)
+	logger.Info("Apply-coupon update completed")
 	return nil
 }
 
@@ -101,6 +150,22 @@ func (s *TemporalBillingWorkflow) CloseBilling(ctx context.Context, externalBill
 	return nil
 }
 
+// CancelBilling sends a signal to cancel the billing workflow
+func (s *TemporalBillingWorkflow) CancelBilling(ctx context.Context, externalBillingID string, reason string) error {
+	logger := rlog.With("fn", "TemporalBillingWorkflow.CancelBilling").With("externalBillingID", externalBillingID).With("reason", reason)
+
+	workflowID := fmt.Sprintf("%s%s", WorkflowIDPrefix, externalBillingID)
+
+	err := s.client.SignalWorkflow(ctx, workflowID, "", workflows.CancelBillingSignal, workflows.CancelBillingSignalInput{Reason: reason})
+	if err != nil {
+		logger.Error("Failed to signal cancel-billing", "error", err)
+		return fmt.Errorf("failed to signal cancel-billing: %w", err)
+	}
+
+	logger.Info("Cancel-billing signal sent", "workflowID", workflowID)
+	return nil
+}
+
 // GetBillingSummary gets a billing summary
 func (s *TemporalBillingWorkflow) GetBillingSummary(ctx context.Context, externalBillingID string) (*entities.BillingSummary, error) {
 	fn := "TemporalBillingWorkflow.GetBillingSummary"
@@ -125,20 +190,63 @@ func (s *TemporalBillingWorkflow) GetBillingSummary(ctx context.Context, externa
 
 	lineItems := make([]entities.LineItem, len(state.LineItems))
 	for i, lineItem := range state.LineItems {
-		lineItems[i] = entities.LineItem{
-			Description: lineItem.Description,
-			AmountMinor: lineItem.AmountMinor,
-		}
+		lineItems[i] = lineItem.LineItem()
 	}
 
 	summary := entities.BillingSummary{
 		ExternalBillingID: state.ExternalBillingID,
+		InvoiceNumber:     state.InvoiceNumber,
 		Description:       state.Description,
 		Currency:          state.Currency,
 		CurrencyPrecision: state.CurrencyPrecision,
 		LineItems:         lineItems,
 		TotalAmountMinor:  state.TotalAmountMinor,
+		PeriodStart:       state.PeriodStart,
+		PeriodEnd:         state.PeriodEnd,
+		Timezone:          state.Timezone,
+		SubscriptionID:    state.SubscriptionID,
+		PreviousBillingID: state.PreviousBillingID,
+		NextBillingID:     state.NextBillingID,
+
+		TaxJurisdiction:       state.TaxJurisdiction,
+		TaxInclusive:          state.TaxInclusive,
+		Discounts:             state.Discounts,
+		DiscountAmountMinor:   state.DiscountAmountMinor,
+		SubtotalAmountMinor:   state.SubtotalAmountMinor,
+		TaxAmountMinor:        state.TaxAmountMinor,
+		GrandTotalAmountMinor: state.GrandTotalAmountMinor,
+		Taxes:                 state.Taxes,
+
+		PaidFromCreditAmountMinor: state.PaidFromCreditAmountMinor,
 	}
 
 	return &summary, nil
 }
+
+// updateWorkflow sends an update to the workflow of a billing and waits for its result
+func (s *TemporalBillingWorkflow) updateWorkflow(ctx context.Context, externalBillingID string, updateName string, result any, args ...any) error {
+	handle, err := s.client.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+		WorkflowID:   fmt.Sprintf("%s%s", WorkflowIDPrefix, externalBillingID),
+		UpdateName:   updateName,
+		Args:         args,
+		WaitForStage: client.WorkflowUpdateStageCompleted,
+	})
+	if err != nil {
+		return err
+	}
+	return handle.Get(ctx, result)
+}
+
+// updateErrorType returns the type of the error an update was rejected with, the update of a workflow that already
+// completed is rejected as the billing is no longer open
+func updateErrorType(err error) string {
+	var applicationErr *temporal.ApplicationError
+	if errors.As(err, &applicationErr) {
+		return applicationErr.Type()
+	}
+	var notFoundErr *serviceerror.NotFound
+	if errors.As(err, &notFoundErr) {
+		return workflows.BillingNotOpenErrorType
+	}
+	return ""
+}
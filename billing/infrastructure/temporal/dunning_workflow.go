@@ -0,0 +1,79 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"encore.dev/rlog"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+
+	"encore.app/billing/infrastructure/temporal/workflows"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+)
+
+type TemporalDunningWorkflow struct {
+	client    client.Client
+	taskQueue string
+}
+
+func NewTemporalDunningWorkflow(client client.Client, taskQueue string) ports.DunningWorkflow {
+	return &TemporalDunningWorkflow{
+		client:    client,
+		taskQueue: taskQueue,
+	}
+}
+
+// StartDunning starts a dunning workflow, a billing has one dunning at a time
+func (s *TemporalDunningWorkflow) StartDunning(ctx context.Context, externalBillingID string, scheduleDays []int, autoCharge bool) error {
+	logger := rlog.With("fn", "TemporalDunningWorkflow.StartDunning").With("externalBillingID", externalBillingID).With("scheduleDays", scheduleDays).With("autoCharge", autoCharge)
+
+	workflowID := fmt.Sprintf("%s%s", workflows.DunningWorkflowIDPrefix, externalBillingID)
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: s.taskQueue,
+	}
+
+	_, err := s.client.ExecuteWorkflow(ctx, workflowOptions, workflows.DunningWorkflow, workflows.DunningWorkflowInput{
+		ExternalBillingID: externalBillingID,
+		ScheduleDays:      scheduleDays,
+		AutoCharge:        autoCharge,
+	})
+	if err != nil {
+		var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+		if errors.As(err, &alreadyStarted) {
+			logger.Warn("Dunning workflow already running")
+			return dto.ErrDunningAlreadyStarted
+		}
+
+		logger.Error("Failed to start dunning workflow", "error", err)
+		return fmt.Errorf("failed to start dunning workflow: %w", err)
+	}
+
+	logger.Info("Dunning workflow started", "workflowID", workflowID)
+	return nil
+}
+
+// NotifyPayment sends a signal to stop the dunning workflow
+func (s *TemporalDunningWorkflow) NotifyPayment(ctx context.Context, externalBillingID string) error {
+	logger := rlog.With("fn", "TemporalDunningWorkflow.NotifyPayment").With("externalBillingID", externalBillingID)
+
+	workflowID := fmt.Sprintf("%s%s", workflows.DunningWorkflowIDPrefix, externalBillingID)
+
+	err := s.client.SignalWorkflow(ctx, workflowID, "", workflows.PaymentReceivedSignal, struct{}{})
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			logger.Info("No dunning workflow running")
+			return nil
+		}
+
+		logger.Error("Failed to signal payment-received", "error", err)
+		return fmt.Errorf("failed to signal payment-received: %w", err)
+	}
+
+	logger.Info("Payment-received signal sent", "workflowID", workflowID)
+	return nil
+}
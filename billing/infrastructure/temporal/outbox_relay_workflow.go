@@ -0,0 +1,32 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+
+	"encore.dev/rlog"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+
+	"encore.app/billing/infrastructure/temporal/workflows"
+)
+
+// StartOutboxRelayWorkflow starts the outbox relay, a relay that is already running is kept
+func StartOutboxRelayWorkflow(ctx context.Context, temporalClient client.Client, taskQueue string) error {
+	logger := rlog.With("fn", "StartOutboxRelayWorkflow")
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:                       workflows.OutboxRelayWorkflowID,
+		TaskQueue:                taskQueue,
+		WorkflowIDConflictPolicy: enumspb.WORKFLOW_ID_CONFLICT_POLICY_USE_EXISTING,
+	}
+
+	run, err := temporalClient.ExecuteWorkflow(ctx, workflowOptions, workflows.OutboxRelayWorkflow)
+	if err != nil {
+		logger.Error("Failed to start outbox relay workflow", "error", err)
+		return fmt.Errorf("failed to start outbox relay workflow: %w", err)
+	}
+
+	logger.Info("Outbox relay workflow started", "workflowID", run.GetID(), "runID", run.GetRunID())
+	return nil
+}
@@ -0,0 +1,54 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"encore.dev/rlog"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+
+	"encore.app/billing/infrastructure/temporal/workflows"
+	"encore.app/billing/usecases/ports"
+)
+
+type TemporalRefundWorkflow struct {
+	client    client.Client
+	taskQueue string
+}
+
+func NewTemporalRefundWorkflow(client client.Client, taskQueue string) ports.RefundWorkflow {
+	return &TemporalRefundWorkflow{
+		client:    client,
+		taskQueue: taskQueue,
+	}
+}
+
+// StartRefund starts a refund workflow, a refund requested again while it is running is not started twice
+func (s *TemporalRefundWorkflow) StartRefund(ctx context.Context, externalRefundID string) error {
+	logger := rlog.With("fn", "TemporalRefundWorkflow.StartRefund").With("externalRefundID", externalRefundID)
+
+	workflowID := fmt.Sprintf("%s%s", workflows.RefundWorkflowIDPrefix, externalRefundID)
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: s.taskQueue,
+	}
+
+	_, err := s.client.ExecuteWorkflow(ctx, workflowOptions, workflows.RefundWorkflow, workflows.RefundWorkflowInput{
+		ExternalRefundID: externalRefundID,
+	})
+	if err != nil {
+		var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+		if errors.As(err, &alreadyStarted) {
+			logger.Info("Refund workflow already running")
+			return nil
+		}
+
+		logger.Error("Failed to start refund workflow", "error", err)
+		return fmt.Errorf("failed to start refund workflow: %w", err)
+	}
+
+	logger.Info("Refund workflow started", "workflowID", workflowID)
+	return nil
+}
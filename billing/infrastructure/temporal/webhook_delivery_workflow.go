@@ -0,0 +1,46 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+
+	"encore.dev/rlog"
+	"go.temporal.io/sdk/client"
+
+	"encore.app/billing/infrastructure/temporal/workflows"
+	"encore.app/billing/usecases/ports"
+)
+
+type TemporalWebhookDeliveryWorkflow struct {
+	client    client.Client
+	taskQueue string
+}
+
+func NewTemporalWebhookDeliveryWorkflow(client client.Client, taskQueue string) ports.WebhookDeliveryWorkflow {
+	return &TemporalWebhookDeliveryWorkflow{
+		client:    client,
+		taskQueue: taskQueue,
+	}
+}
+
+// DeliverWebhook starts a webhook delivery workflow, a delivery that is still running is not started again
+func (s *TemporalWebhookDeliveryWorkflow) DeliverWebhook(ctx context.Context, externalDeliveryID string) error {
+	logger := rlog.With("fn", "TemporalWebhookDeliveryWorkflow.DeliverWebhook").With("externalDeliveryID", externalDeliveryID)
+
+	workflowID := fmt.Sprintf("%s%s", workflows.WebhookDeliveryWorkflowIDPrefix, externalDeliveryID)
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: s.taskQueue,
+	}
+
+	_, err := s.client.ExecuteWorkflow(ctx, workflowOptions, workflows.WebhookDeliveryWorkflow, workflows.WebhookDeliveryWorkflowInput{
+		ExternalDeliveryID: externalDeliveryID,
+	})
+	if err != nil {
+		logger.Error("Failed to start webhook delivery workflow", "error", err)
+		return fmt.Errorf("failed to start webhook delivery workflow: %w", err)
+	}
+
+	logger.Info("Webhook delivery workflow started", "workflowID", workflowID)
+	return nil
+}
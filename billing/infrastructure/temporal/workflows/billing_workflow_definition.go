@@ -2,20 +2,65 @@ package workflows
 
 import (
 	"encoding/json"
+	"errors"
+	"slices"
 	"time"
 
 	"encore.dev/rlog"
+	"github.com/google/uuid"
+	enumspb "go.temporal.io/api/enums/v1"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 
+	"encore.app/billing/domain/entities"
 	"encore.app/billing/infrastructure/temporal/activities"
 )
 
 const (
-	AddLineItemSignal  = "add-line-item"
-	CloseBillingSignal = "close-billing"
+	CloseBillingSignal  = "close-billing"
+	CancelBillingSignal = "cancel-billing"
+
+	// AddLineItemUpdate adds a line item to the billing, a rejected line item is returned to the caller
+	AddLineItemUpdate = "add-line-item"
+
+	// ApplyCouponUpdate redeems a coupon and applies it to the billing, a rejected coupon is returned to the caller
+	ApplyCouponUpdate = "apply-coupon"
+
+	// BillingNotOpenErrorType is the type of the error of updates sent to a billing that is closing, closed or cancelled
+	BillingNotOpenErrorType = "BillingNotOpen"
+
+	// LineItemRejectedErrorType is the type of the error of line items rejected by the billing, its message is the one
+	// of the domain error
+	LineItemRejectedErrorType = "LineItemRejected"
+
+	BillingWorkflowIDPrefix = "billing-workflow-"
 )
 
+// lineItemRejections are the domain errors a line item can be rejected with
+var lineItemRejections = []error{
+	entities.ErrInvalidAdjustment,
+	entities.ErrAdjustmentReasonRequired,
+	entities.ErrReferencedLineItemNotFound,
+	entities.ErrAdjustmentExceedsReferencedItem,
+	entities.ErrNegativeBillingTotal,
+	entities.ErrSpendLimitExceeded,
+}
+
+// LineItemRejection returns the domain error of a line item rejected by the add line item update, or nil if err is not
+// a rejection
+func LineItemRejection(err error) error {
+	var applicationErr *temporal.ApplicationError
+	if !errors.As(err, &applicationErr) || applicationErr.Type() != LineItemRejectedErrorType {
+		return nil
+	}
+	for _, rejection := range lineItemRejections {
+		if applicationErr.Message() == rejection.Error() {
+			return rejection
+		}
+	}
+	return entities.ErrInvalidAdjustment
+}
+
 type BillingWorkflowInput struct {
 	UserID            string     `json:"user_id"`
 	ExternalBillingID string     `json:"billing_id"`
@@ -23,10 +68,47 @@ type BillingWorkflowInput struct {
 	Currency          string     `json:"currency"`
 	CurrencyPrecision int64      `json:"currency_precision"`
 	PlannedClosedAt   *time.Time `json:"planned_closed_at"`
+
+	// PeriodStart and PeriodEnd bound the billing period, Timezone is the IANA timezone the period is computed in
+	PeriodStart *time.Time `json:"period_start,omitempty"`
+	PeriodEnd   *time.Time `json:"period_end,omitempty"`
+	Timezone    string     `json:"timezone,omitempty"`
+
+	// Recurrence is set for billings that roll over into the next period when closed
+	Recurrence        *entities.RecurrenceRule `json:"recurrence,omitempty"`
+	PreviousBillingID *string                  `json:"previous_billing_id,omitempty"`
+
+	// SubscriptionID is set for billings generated by a subscription, each period is charged the subscription's current plan
+	SubscriptionID *string `json:"subscription_id,omitempty"`
+
+	// TaxJurisdiction selects the tax rates applied when the billing closes, TaxInclusive means line amounts already contain tax
+	TaxJurisdiction string `json:"tax_jurisdiction,omitempty"`
+	TaxInclusive    bool   `json:"tax_inclusive,omitempty"`
+
+	// AllowNegativeTotal lets adjustments bring the billing total below zero
+	AllowNegativeTotal bool `json:"allow_negative_total,omitempty"`
+
+	// UserGroup selects the invoice number series the billing is numbered in when it closes
+	UserGroup string `json:"user_group,omitempty"`
+
+	// AutoCharge charges the user through the payment gateway once the summary is written
+	AutoCharge bool `json:"auto_charge,omitempty"`
+
+	// SpendLimit alerts when the total crosses its thresholds, hard limits keep the total from going over it
+	SpendLimit *entities.SpendLimit `json:"spend_limit,omitempty"`
+
+	// InitialLineItems are added right after the billing is created
+	InitialLineItems []LineItemState `json:"initial_line_items,omitempty"`
+}
+
+// CancelBillingSignalInput is the payload of the cancel billing signal
+type CancelBillingSignalInput struct {
+	Reason string `json:"reason"`
 }
 
 type BillingWorkflowState struct {
 	ExternalBillingID string          `json:"external_billing_id"`
+	InvoiceNumber     string          `json:"invoice_number,omitempty"`
 	BillingID         int64           `json:"-"`
 	Description       string          `json:"description"`
 	Currency          string          `json:"currency"`
@@ -36,12 +118,70 @@ type BillingWorkflowState struct {
 	ClosedAt          *time.Time      `json:"-"`
 	LastActivity      time.Time       `json:"-"`
 	TotalAmountMinor  int64           `json:"total_amount_minor"`
+	PeriodStart       *time.Time      `json:"period_start,omitempty"`
+	PeriodEnd         *time.Time      `json:"period_end,omitempty"`
+	Timezone          string          `json:"timezone,omitempty"`
+	SubscriptionID    *string         `json:"subscription_id,omitempty"`
+	PreviousBillingID *string         `json:"previous_billing_id,omitempty"`
+	NextBillingID     *string         `json:"next_billing_id,omitempty"`
+
+	TaxJurisdiction       string                  `json:"tax_jurisdiction,omitempty"`
+	TaxInclusive          bool                    `json:"tax_inclusive,omitempty"`
+	Coupons               []entities.Coupon       `json:"-"`
+	Discounts             []entities.Discount     `json:"discounts,omitempty"`
+	DiscountAmountMinor   int64                   `json:"discount_amount_minor"`
+	SubtotalAmountMinor   int64                   `json:"subtotal_amount_minor"`
+	TaxAmountMinor        int64                   `json:"tax_amount_minor"`
+	GrandTotalAmountMinor int64                   `json:"grand_total_amount_minor"`
+	Taxes                 []entities.TaxBreakdown `json:"taxes,omitempty"`
+
+	PaidFromCreditAmountMinor int64 `json:"paid_from_credit_amount_minor,omitempty"`
 }
 
 type LineItemState struct {
-	Description string    `json:"description"`
-	AmountMinor int64     `json:"amount_minor"`
-	AddedAt     time.Time `json:"added_at"`
+	LineItemID     string    `json:"line_item_id,omitempty"`
+	Kind           string    `json:"kind,omitempty"`
+	Description    string    `json:"description"`
+	AmountMinor    int64     `json:"amount_minor"`
+	TaxCode        string    `json:"tax_code,omitempty"`
+	TaxAmountMinor int64     `json:"tax_amount_minor,omitempty"`
+	AddedAt        time.Time `json:"added_at"`
+
+	DiscountAmountMinor int64 `json:"discount_amount_minor,omitempty"`
+
+	Reason              string `json:"reason,omitempty"`
+	ReferenceLineItemID string `json:"reference_line_item_id,omitempty"`
+}
+
+// NewLineItemState returns the workflow state of a line item added at addedAt
+func NewLineItemState(lineItem entities.LineItem, addedAt time.Time) LineItemState {
+	return LineItemState{
+		LineItemID:          lineItem.LineItemID,
+		Kind:                lineItem.Kind,
+		Description:         lineItem.Description,
+		AmountMinor:         lineItem.AmountMinor,
+		TaxCode:             lineItem.TaxCode,
+		TaxAmountMinor:      lineItem.TaxAmountMinor,
+		AddedAt:             addedAt,
+		DiscountAmountMinor: lineItem.DiscountAmountMinor,
+		Reason:              lineItem.Reason,
+		ReferenceLineItemID: lineItem.ReferenceLineItemID,
+	}
+}
+
+// LineItem returns the domain line item of the state
+func (l LineItemState) LineItem() entities.LineItem {
+	return entities.LineItem{
+		LineItemID:          l.LineItemID,
+		Kind:                l.Kind,
+		Description:         l.Description,
+		AmountMinor:         l.AmountMinor,
+		TaxCode:             l.TaxCode,
+		TaxAmountMinor:      l.TaxAmountMinor,
+		DiscountAmountMinor: l.DiscountAmountMinor,
+		Reason:              l.Reason,
+		ReferenceLineItemID: l.ReferenceLineItemID,
+	}
 }
 
 // BillingWorkflow is the Temporal workflow for managing billing lifecycle
@@ -61,6 +201,13 @@ func BillingWorkflow(ctx workflow.Context, input BillingWorkflowInput) error {
 		LineItems:         []LineItemState{},
 		LastActivity:      workflow.Now(ctx),
 		TotalAmountMinor:  0,
+		PeriodStart:       input.PeriodStart,
+		PeriodEnd:         input.PeriodEnd,
+		Timezone:          input.Timezone,
+		SubscriptionID:    input.SubscriptionID,
+		PreviousBillingID: input.PreviousBillingID,
+		TaxJurisdiction:   input.TaxJurisdiction,
+		TaxInclusive:      input.TaxInclusive,
 	}
 
 	// Activity options
@@ -86,7 +233,27 @@ func BillingWorkflow(ctx workflow.Context, input BillingWorkflowInput) error {
 
 	// start billing activity
 	var billingID int64
-	err = workflow.ExecuteActivity(ctx, activities.StartBillingActivityFunc, input.UserID, input.ExternalBillingID, input.Description, input.Currency, input.CurrencyPrecision, input.PlannedClosedAt).Get(ctx, &billingID)
+	billing := entities.Billing{
+		ExternalBillingID:  input.ExternalBillingID,
+		UserID:             input.UserID,
+		Description:        input.Description,
+		Currency:           input.Currency,
+		CurrencyPrecision:  input.CurrencyPrecision,
+		PlannedClosedAt:    input.PlannedClosedAt,
+		PeriodStart:        input.PeriodStart,
+		PeriodEnd:          input.PeriodEnd,
+		Timezone:           input.Timezone,
+		Recurrence:         input.Recurrence,
+		SubscriptionID:     input.SubscriptionID,
+		PreviousBillingID:  input.PreviousBillingID,
+		TaxJurisdiction:    input.TaxJurisdiction,
+		TaxInclusive:       input.TaxInclusive,
+		AllowNegativeTotal: input.AllowNegativeTotal,
+		UserGroup:          input.UserGroup,
+		AutoCharge:         input.AutoCharge,
+		SpendLimit:         input.SpendLimit,
+	}
+	err = workflow.ExecuteActivity(ctx, activities.StartBillingActivityFunc, billing).Get(ctx, &billingID)
 	if err != nil {
 		logger.Error("Failed to start billing", "error", err)
 		return err
@@ -95,29 +262,324 @@ func BillingWorkflow(ctx workflow.Context, input BillingWorkflowInput) error {
 	// update internal billingID in state
 	state.BillingID = billingID
 
-	// Helper function to close billing and generate summary
-	closeBillingAndGenerateSummary := func() {
+	// the billing is locked once it starts closing or cancelling, updates are rejected from then on and the ones in flight
+	// finish before it goes on
+	locked := false
+	pendingUpdates := 0
+	lock := func() error {
+		locked = true
+		return workflow.Await(ctx, func() bool { return pendingUpdates == 0 })
+	}
+	validateOpen := func() error {
+		if locked || state.Status != "open" {
+			return temporal.NewApplicationError("billing is not open", BillingNotOpenErrorType)
+		}
+		return nil
+	}
+
+	// Helper function to give a line item a stable ID, generated once so that replays and activity retries reuse it
+	assignLineItemID := func(ctx workflow.Context, lineItem *LineItemState) error {
+		if lineItem.Kind == "" {
+			lineItem.Kind = entities.LineItemKindCharge
+		}
+		if lineItem.LineItemID != "" {
+			return nil
+		}
+		return workflow.SideEffect(ctx, func(ctx workflow.Context) interface{} {
+			return uuid.Must(uuid.NewV7()).String()
+		}).Get(&lineItem.LineItemID)
+	}
+
+	// Helper function to alert the spend limit thresholds the total reached since it was previousTotalAmountMinor
+	alertSpendThresholds := func(ctx workflow.Context, previousTotalAmountMinor int64) {
+		if input.SpendLimit == nil {
+			return
+		}
+		for _, threshold := range input.SpendLimit.CrossedThresholds(previousTotalAmountMinor, state.TotalAmountMinor) {
+			err := workflow.ExecuteActivity(ctx, activities.RecordSpendThresholdCrossedActivityFunc, state.BillingID, threshold, state.TotalAmountMinor).Get(ctx, nil)
+			if err != nil {
+				logger.Error("Failed to record spend threshold crossed", "threshold", threshold, "error", err)
+				continue
+			}
+			logger.Info("Spend threshold crossed", "threshold", threshold, "totalAmountMinor", state.TotalAmountMinor)
+		}
+	}
+
+	// Helper function to check a line item against the current line items and the spend limit of the billing
+	validateLineItem := func(lineItem LineItemState) error {
+		lineItems := make([]entities.LineItem, 0, len(state.LineItems))
+		for _, item := range state.LineItems {
+			lineItems = append(lineItems, item.LineItem())
+		}
+
+		// adjustments are checked against the items they credit, any negative line item against the total
+		if lineItem.Kind == entities.LineItemKindAdjustment {
+			err := entities.ValidateAdjustment(lineItems, lineItem.LineItem(), input.AllowNegativeTotal)
+			if err != nil {
+				return err
+			}
+		} else {
+			err := entities.ValidateBillingTotal(lineItems, lineItem.LineItem(), input.AllowNegativeTotal)
+			if err != nil {
+				return err
+			}
+		}
+
+		// a hard spend limit keeps the total from going over it
+		if input.SpendLimit != nil && input.SpendLimit.IsHard() && lineItem.AmountMinor > 0 && input.SpendLimit.Exceeds(state.TotalAmountMinor+lineItem.AmountMinor) {
+			return entities.ErrSpendLimitExceeded
+		}
+		return nil
+	}
+
+	// add initial line items, e.g. the plan charge of a subscription period
+	for _, lineItem := range input.InitialLineItems {
+		err = assignLineItemID(ctx, &lineItem)
+		if err != nil {
+			logger.Error("Failed to generate line item ID", "error", err)
+			return err
+		}
+
+		err = workflow.ExecuteActivity(ctx, activities.AddLineItemActivityFunc, state.BillingID, lineItem.LineItem(), state.ExternalBillingID).Get(ctx, nil)
+		if err != nil {
+			logger.Error("Failed to add initial line item", "error", err)
+			return err
+		}
+
+		state.LineItems = append(state.LineItems, lineItem)
+		state.TotalAmountMinor = state.TotalAmountMinor + lineItem.AmountMinor
+	}
+	alertSpendThresholds(ctx, 0)
+
+	// Helper function to start the billing of the next period for recurring billings, a failure is returned so that the chain
+	// of billings never ends silently
+	startNextBilling := func() error {
+		// the next period starts where the current one was planned to end, even if it was closed early
+		periodStart := workflow.Now(ctx)
+		if input.PlannedClosedAt != nil {
+			periodStart = *input.PlannedClosedAt
+		}
+		// subscriptions are renewed with their current plan, which may have changed during the period
+		recurrence := input.Recurrence
+		description := input.Description
+		var initialLineItems []LineItemState
+		if input.SubscriptionID != nil {
+			var renewal activities.SubscriptionRenewal
+			err := workflow.ExecuteActivity(ctx, activities.PrepareSubscriptionRenewalActivityFunc, *input.SubscriptionID, periodStart).Get(ctx, &renewal)
+			if err != nil {
+				logger.Error("Failed to prepare subscription renewal", "error", err)
+				return err
+			}
+			if !renewal.Renew {
+				logger.Info("Subscription is cancelled, no next billing started", "subscriptionID", *input.SubscriptionID)
+				return nil
+			}
+
+			recurrence = renewal.Recurrence
+			description = renewal.Description
+			initialLineItems = []LineItemState{{
+				Description: renewal.LineItem.Description,
+				AmountMinor: renewal.LineItem.AmountMinor,
+				AddedAt:     periodStart,
+			}}
+		}
+
+		if !recurrence.HasNextPeriod(periodStart) {
+			logger.Info("Recurrence ended, no next billing started", "periodStart", periodStart)
+			return nil
+		}
+
+		nextClosedAt, err := recurrence.NextClose(periodStart)
+		if err != nil {
+			logger.Error("Failed to compute next period", "error", err)
+			return err
+		}
+
+		// generate the next external billing ID once, so that replays start the same workflow
+		var nextBillingID string
+		err = workflow.SideEffect(ctx, func(ctx workflow.Context) interface{} {
+			return uuid.Must(uuid.NewV7()).String()
+		}).Get(&nextBillingID)
+		if err != nil {
+			logger.Error("Failed to generate next billing ID", "error", err)
+			return err
+		}
+
+		nextInput := BillingWorkflowInput{
+			UserID:             input.UserID,
+			ExternalBillingID:  nextBillingID,
+			Description:        description,
+			Currency:           input.Currency,
+			CurrencyPrecision:  input.CurrencyPrecision,
+			PlannedClosedAt:    &nextClosedAt,
+			PeriodStart:        &periodStart,
+			PeriodEnd:          &nextClosedAt,
+			Timezone:           input.Timezone,
+			Recurrence:         recurrence,
+			PreviousBillingID:  &input.ExternalBillingID,
+			SubscriptionID:     input.SubscriptionID,
+			TaxJurisdiction:    input.TaxJurisdiction,
+			TaxInclusive:       input.TaxInclusive,
+			AllowNegativeTotal: input.AllowNegativeTotal,
+			UserGroup:          input.UserGroup,
+			AutoCharge:         input.AutoCharge,
+			SpendLimit:         input.SpendLimit,
+			InitialLineItems:   initialLineItems,
+		}
+
+		// the next billing outlives this workflow, so it is started as an abandoned child
+		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+			WorkflowID:        BillingWorkflowIDPrefix + nextBillingID,
+			ParentClosePolicy: enumspb.PARENT_CLOSE_POLICY_ABANDON,
+		})
+		err = workflow.ExecuteChildWorkflow(childCtx, BillingWorkflow, nextInput).GetChildWorkflowExecution().Get(childCtx, nil)
+		if err != nil {
+			logger.Error("Failed to start next billing", "error", err)
+			return err
+		}
+
+		err = workflow.ExecuteActivity(ctx, activities.LinkNextBillingActivityFunc, state.BillingID, nextBillingID).Get(ctx, nil)
+		if err != nil {
+			logger.Error("Failed to link next billing", "error", err)
+			return err
+		}
+
+		if input.SubscriptionID != nil {
+			err = workflow.ExecuteActivity(ctx, activities.SetSubscriptionCurrentBillingActivityFunc, *input.SubscriptionID, nextBillingID).Get(ctx, nil)
+			if err != nil {
+				logger.Error("Failed to set subscription current billing", "error", err)
+				return err
+			}
+		}
+
+		state.NextBillingID = &nextBillingID
+
+		logger.Info("Next billing started", "nextBillingID", nextBillingID, "nextPlannedClosedAt", nextClosedAt)
+		return nil
+	}
+
+	// Helper function to compute the discounts and then the tax of the billing, billings without a jurisdiction are not taxed
+	calculateTotals := func() error {
+		var taxRates []entities.TaxRate
+		if input.TaxJurisdiction != "" {
+			err := workflow.ExecuteActivity(ctx, activities.GetTaxRatesActivityFunc, input.TaxJurisdiction).Get(ctx, &taxRates)
+			if err != nil {
+				return err
+			}
+		}
+
+		lineItems := make([]entities.LineItem, len(state.LineItems))
+		for i, lineItem := range state.LineItems {
+			lineItems[i] = lineItem.LineItem()
+			lineItems[i].DiscountAmountMinor = 0
+		}
+
+		lineItems, discounts := entities.CalculateDiscounts(lineItems, state.Coupons)
+
+		calculation, err := entities.CalculateTax(lineItems, taxRates, input.TaxInclusive)
+		if err != nil {
+			return err
+		}
+
+		state.Discounts = discounts
+		state.DiscountAmountMinor = 0
+		for _, discount := range discounts {
+			state.DiscountAmountMinor += discount.AmountMinor
+		}
+		for i := range state.LineItems {
+			state.LineItems[i].DiscountAmountMinor = calculation.LineItems[i].DiscountAmountMinor
+			state.LineItems[i].TaxAmountMinor = calculation.LineItems[i].TaxAmountMinor
+		}
+		state.SubtotalAmountMinor = calculation.SubtotalAmountMinor
+		state.TaxAmountMinor = calculation.TaxAmountMinor
+		state.GrandTotalAmountMinor = calculation.GrandTotalAmountMinor
+		state.Taxes = calculation.Taxes
+		return nil
+	}
+
+	// Helper function to close billing and generate summary. A failure is returned so that the workflow fails visibly, the
+	// close is triggered once and a billing left open would never be closed.
+	closeBillingAndGenerateSummary := func() error {
 		logger.Info("Closing billing")
 
+		err := lock()
+		if err != nil {
+			logger.Error("Failed to wait for pending updates", "error", err)
+			return err
+		}
+
+		// bill the usage of the period as line items before the billing is closed, so that they count towards the spend
+		// thresholds. Usage is metered outside the workflow so that events do not grow its history.
+		closedAt := workflow.Now(ctx)
+		var usageLineItems []entities.LineItem
+		err = workflow.ExecuteActivity(ctx, activities.MeterUsageActivityFunc, state.BillingID, closedAt).Get(ctx, &usageLineItems)
+		if err != nil {
+			logger.Error("Failed to meter usage", "error", err)
+			return err
+		}
+		previousTotalAmountMinor := state.TotalAmountMinor
+		for _, usageLineItem := range usageLineItems {
+			if slices.ContainsFunc(state.LineItems, func(item LineItemState) bool { return item.LineItemID == usageLineItem.LineItemID }) {
+				continue
+			}
+
+			// claimed usage is billed even over a hard spend limit, the limit refuses what is added to the billing and not
+			// what was already consumed
+			lineItem := NewLineItemState(usageLineItem, workflow.Now(ctx))
+			err = workflow.ExecuteActivity(ctx, activities.AddLineItemActivityFunc, state.BillingID, lineItem.LineItem(), state.ExternalBillingID).Get(ctx, nil)
+			if err != nil {
+				logger.Error("Failed to add usage line item", "error", err)
+				return err
+			}
+
+			state.LineItems = append(state.LineItems, lineItem)
+			state.TotalAmountMinor = state.TotalAmountMinor + lineItem.AmountMinor
+		}
+		alertSpendThresholds(ctx, previousTotalAmountMinor)
+
+		// compute discounts and tax on the final line items before the billing is closed, so that a failure does not leave
+		// a numbered invoice without its totals
+		err = calculateTotals()
+		if err != nil {
+			logger.Error("Failed to calculate totals", "error", err)
+			return err
+		}
+
+		// roll over into the next period before the billing is closed, so that its summary links both billings and a failure
+		// does not leave a closed billing without its summary
+		if input.Recurrence != nil && state.NextBillingID == nil {
+			err = startNextBilling()
+			if err != nil {
+				logger.Error("Failed to roll over into the next period", "error", err)
+				return err
+			}
+		}
+
 		// Execute activity to close billing
-		err := workflow.ExecuteActivity(ctx, activities.CloseBillingActivityFunc, state.BillingID).Get(ctx, nil)
+		var invoiceNumber string
+		err = workflow.ExecuteActivity(ctx, activities.CloseBillingActivityFunc, state.BillingID, closedAt).Get(ctx, &invoiceNumber)
 		if err != nil {
 			logger.Error("Failed to close billing", "error", err)
-			return
+			return err
 		}
+		state.InvoiceNumber = invoiceNumber
 
 		// Generate billing summary
 		billingSummary, err := json.Marshal(state)
 		if err != nil {
 			logger.Error("Failed to generate billing summary", "error", err)
-			return
+			return err
 		}
 
-		err = workflow.ExecuteActivity(ctx, activities.CreateBillingSummaryActivityFunc, input.ExternalBillingID, billingSummary).Get(ctx, nil)
+		// the wallet of the user pays what it can of the billing in the transaction of its summary, so that it is drawn
+		// only once the summary is written
+		var paidFromCreditAmountMinor int64
+		err = workflow.ExecuteActivity(ctx, activities.CreateBillingSummaryActivityFunc, input.ExternalBillingID, billingSummary).Get(ctx, &paidFromCreditAmountMinor)
 		if err != nil {
 			logger.Error("Failed to generate billing summary", "error", err)
-			return
+			return err
 		}
+		state.PaidFromCreditAmountMinor = paidFromCreditAmountMinor
 
 		now := workflow.Now(ctx)
 		state.ClosedAt = &now
@@ -125,41 +587,185 @@ func BillingWorkflow(ctx workflow.Context, input BillingWorkflowInput) error {
 		state.LastActivity = now
 
 		logger.Info("Billing closed and summary generated")
+
+		// charge the closed billing, an unsuccessful charge is recorded and leaves the billing unpaid
+		if input.AutoCharge && state.GrandTotalAmountMinor-state.PaidFromCreditAmountMinor > 0 {
+			chargeCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+				StartToCloseTimeout: 30 * time.Second,
+				RetryPolicy: &temporal.RetryPolicy{
+					InitialInterval:    10 * time.Second,
+					BackoffCoefficient: 2.0,
+					MaximumInterval:    time.Minute,
+					MaximumAttempts:    activities.ChargeMaxAttempts,
+				},
+			})
+
+			var chargeAttempt entities.ChargeAttempt
+			err = workflow.ExecuteActivity(chargeCtx, activities.ChargeBillingActivityFunc, input.ExternalBillingID).Get(ctx, &chargeAttempt)
+			if err != nil {
+				logger.Error("Failed to charge billing", "error", err)
+				return err
+			}
+
+			logger.Info("Billing charge attempted", "status", chargeAttempt.Status, "gatewayChargeID", chargeAttempt.GatewayChargeID, "declineCode", chargeAttempt.DeclineCode)
+
+			// the subscription of a billing left unpaid is past due until the billing is paid
+			if input.SubscriptionID != nil {
+				err = workflow.ExecuteActivity(ctx, activities.UpdateSubscriptionPaymentStatusActivityFunc, input.ExternalBillingID).Get(ctx, nil)
+				if err != nil {
+					logger.Error("Failed to update subscription payment status", "error", err)
+					return err
+				}
+			}
+
+			// an unsuccessful charge is chased by dunning, which outlives this workflow
+			if chargeAttempt.Status == entities.ChargeAttemptStatusDeclined || chargeAttempt.Status == entities.ChargeAttemptStatusFailed {
+				dunningCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+					WorkflowID:        DunningWorkflowIDPrefix + input.ExternalBillingID,
+					ParentClosePolicy: enumspb.PARENT_CLOSE_POLICY_ABANDON,
+				})
+				err = workflow.ExecuteChildWorkflow(dunningCtx, DunningWorkflow, DunningWorkflowInput{
+					ExternalBillingID: input.ExternalBillingID,
+					AutoCharge:        true,
+				}).GetChildWorkflowExecution().Get(dunningCtx, nil)
+				if err != nil {
+					logger.Error("Failed to start dunning", "error", err)
+					return err
+				}
+
+				logger.Info("Dunning started")
+			}
+		}
+		return nil
 	}
 
-	// Wait for line items to be added or billing to be closed
-	selector := workflow.NewSelector(ctx)
+	// Helper function to tell whether a coupon is applied to the billing
+	couponApplied := func(code string) bool {
+		return slices.ContainsFunc(state.Coupons, func(coupon entities.Coupon) bool { return coupon.Code == code })
+	}
 
-	// Channel for line item additions
-	lineItemChan := workflow.GetSignalChannel(ctx, AddLineItemSignal)
+	// coupons are redeemed by the workflow, so that a billing that closes or is cancelled meanwhile never redeems one it does not apply
+	err = workflow.SetUpdateHandlerWithOptions(ctx, ApplyCouponUpdate, func(ctx workflow.Context, coupon entities.Coupon) error {
+		logger.Info("Received apply coupon update", "code", coupon.Code)
+		ctx = workflow.WithActivityOptions(ctx, activityOptions)
 
-	// Channel for closing billing (manual close)
-	closeChan := workflow.GetSignalChannel(ctx, CloseBillingSignal)
+		// the coupon is already redeemed for this billing, so a repeated update is a no-op
+		if couponApplied(coupon.Code) {
+			logger.Info("Coupon already applied", "code", coupon.Code)
+			return nil
+		}
 
-	// Timer for auto-close at plannedClosedAt (if set)
-	var autoCloseTimer workflow.Future
-	if input.PlannedClosedAt != nil {
-		now := workflow.Now(ctx)
-		duration := input.PlannedClosedAt.Sub(now)
-		autoCloseTimer = workflow.NewTimer(ctx, duration)
+		pendingUpdates++
+		defer func() { pendingUpdates-- }()
+
+		err := workflow.ExecuteActivity(ctx, activities.RedeemCouponActivityFunc, coupon.ID, state.BillingID).Get(ctx, nil)
+		if err != nil {
+			logger.Warn("Failed to redeem coupon", "code", coupon.Code, "error", err)
+			return err
+		}
+
+		if !couponApplied(coupon.Code) {
+			state.Coupons = append(state.Coupons, coupon)
+		}
+		state.LastActivity = workflow.Now(ctx)
+		return nil
+	}, workflow.UpdateHandlerOptions{
+		Validator: func(coupon entities.Coupon) error {
+			return validateOpen()
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to set apply coupon update handler", "error", err)
+		return err
 	}
 
-	selector.AddReceive(lineItemChan, func(c workflow.ReceiveChannel, more bool) {
-		var lineItem LineItemState
-		c.Receive(ctx, &lineItem)
-		logger.Info("Received add line item signal", "description", lineItem.Description, "amountMinor", lineItem.AmountMinor)
+	// line items are checked by the workflow against its current state, one at a time, and a rejected one is returned to
+	// the caller instead of being dropped
+	spendLimitCloseChan := workflow.NewBufferedChannel(ctx, 1)
+	lineItemMutex := workflow.NewMutex(ctx)
+	err = workflow.SetUpdateHandlerWithOptions(ctx, AddLineItemUpdate, func(ctx workflow.Context, lineItem LineItemState) error {
+		logger.Info("Received add line item update", "description", lineItem.Description, "amountMinor", lineItem.AmountMinor, "taxCode", lineItem.TaxCode, "kind", lineItem.Kind)
+		ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+		pendingUpdates++
+		defer func() { pendingUpdates-- }()
+
+		err := lineItemMutex.Lock(ctx)
+		if err != nil {
+			return err
+		}
+		defer lineItemMutex.Unlock()
+
+		// a line item repeated with the same ID is a no-op
+		if lineItem.LineItemID != "" && slices.ContainsFunc(state.LineItems, func(item LineItemState) bool { return item.LineItemID == lineItem.LineItemID }) {
+			logger.Info("Line item already added", "lineItemID", lineItem.LineItemID)
+			return nil
+		}
+
+		err = validateLineItem(lineItem)
+		if err != nil {
+			logger.Warn("Line item rejected", "lineItemID", lineItem.LineItemID, "totalAmountMinor", state.TotalAmountMinor, "error", err)
+			if errors.Is(err, entities.ErrSpendLimitExceeded) && input.SpendLimit.Mode == entities.SpendLimitModeClose {
+				spendLimitCloseChan.SendAsync(struct{}{})
+			}
+			return temporal.NewApplicationError(err.Error(), LineItemRejectedErrorType)
+		}
+
+		err = assignLineItemID(ctx, &lineItem)
+		if err != nil {
+			logger.Error("Failed to generate line item ID", "error", err)
+			return err
+		}
 
 		// Execute activity to add line item
-		err := workflow.ExecuteActivity(ctx, activities.AddLineItemActivityFunc, state.BillingID, lineItem.Description, lineItem.AmountMinor).Get(ctx, nil)
+		err = workflow.ExecuteActivity(ctx, activities.AddLineItemActivityFunc, state.BillingID, lineItem.LineItem(), state.ExternalBillingID).Get(ctx, nil)
 		if err != nil {
 			logger.Error("Failed to add line item", "error", err)
-			return
+			return err
 		}
 
 		// Update state
+		previousTotalAmountMinor := state.TotalAmountMinor
 		state.LineItems = append(state.LineItems, lineItem)
 		state.TotalAmountMinor = state.TotalAmountMinor + lineItem.AmountMinor
 		state.LastActivity = workflow.Now(ctx)
+
+		alertSpendThresholds(ctx, previousTotalAmountMinor)
+		return nil
+	}, workflow.UpdateHandlerOptions{
+		Validator: func(lineItem LineItemState) error {
+			return validateOpen()
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to set add line item update handler", "error", err)
+		return err
+	}
+
+	// Wait for line items to be added or billing to be closed, until the billing is closed or fails to close
+	selector := workflow.NewSelector(ctx)
+	var closeErr error
+
+	// Channel for closing billing (manual close)
+	closeChan := workflow.GetSignalChannel(ctx, CloseBillingSignal)
+
+	// Channel for cancelling billing
+	cancelChan := workflow.GetSignalChannel(ctx, CancelBillingSignal)
+
+	// Timer for auto-close at plannedClosedAt (if set)
+	var autoCloseTimer workflow.Future
+	if input.PlannedClosedAt != nil {
+		now := workflow.Now(ctx)
+		duration := input.PlannedClosedAt.Sub(now)
+		autoCloseTimer = workflow.NewTimer(ctx, duration)
+	}
+
+	// a line item going over a hard spend limit in close mode closes the billing, which the update leaves to the main loop
+	selector.AddReceive(spendLimitCloseChan, func(c workflow.ReceiveChannel, more bool) {
+		c.Receive(ctx, nil)
+		logger.Info("Closing billing at its spend limit")
+
+		closeErr = closeBillingAndGenerateSummary()
 	})
 
 	selector.AddReceive(closeChan, func(c workflow.ReceiveChannel, more bool) {
@@ -167,7 +773,32 @@ func BillingWorkflow(ctx workflow.Context, input BillingWorkflowInput) error {
 		c.Receive(ctx, &closeSignal)
 		logger.Info("Received manual close billing signal")
 
-		closeBillingAndGenerateSummary()
+		closeErr = closeBillingAndGenerateSummary()
+	})
+
+	selector.AddReceive(cancelChan, func(c workflow.ReceiveChannel, more bool) {
+		var cancelSignal CancelBillingSignalInput
+		c.Receive(ctx, &cancelSignal)
+		logger.Info("Received cancel billing signal", "reason", cancelSignal.Reason)
+
+		err := lock()
+		if err != nil {
+			logger.Error("Failed to wait for pending updates", "error", err)
+			return
+		}
+
+		// a cancelled billing is never closed, so it gets no invoice number nor summary
+		err = workflow.ExecuteActivity(ctx, activities.CancelBillingActivityFunc, state.BillingID, state.ExternalBillingID, cancelSignal.Reason).Get(ctx, nil)
+		if err != nil {
+			logger.Error("Failed to cancel billing", "error", err)
+			locked = false
+			return
+		}
+
+		state.Status = "cancelled"
+		state.LastActivity = workflow.Now(ctx)
+
+		logger.Info("Billing cancelled")
 	})
 
 	// Add auto-close timer to selector if it exists
@@ -181,14 +812,18 @@ func BillingWorkflow(ctx workflow.Context, input BillingWorkflowInput) error {
 
 			logger.Info("Auto-close timer fired", "plannedClosedAt", input.PlannedClosedAt)
 
-			closeBillingAndGenerateSummary()
+			closeErr = closeBillingAndGenerateSummary()
 		})
 	}
 
 	// Wait for signals
-	for state.Status != "closed" {
+	for closeErr == nil && state.Status != "closed" && state.Status != "cancelled" {
 		selector.Select(ctx)
 	}
+	if closeErr != nil {
+		logger.Error("BillingWorkflow failed to close billing", "billingID", input.ExternalBillingID, "error", closeErr)
+		return closeErr
+	}
 
 	logger.Info("BillingWorkflow completed", "billingID", input.ExternalBillingID)
 	return nil
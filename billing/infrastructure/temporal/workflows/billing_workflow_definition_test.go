@@ -1,3 +1,409 @@
 package workflows
 
-// TODO: add tests for billing workflow definition
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/infrastructure/temporal/activities"
+)
+
+const (
+	testBillingID         = int64(1)
+	testExternalBillingID = "billing-1"
+)
+
+// newBillingTestEnvironment registers the billing activities and mocks starting the billing and adding line items
+func newBillingTestEnvironment(t *testing.T) *testsuite.TestWorkflowEnvironment {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	t.Cleanup(func() { env.AssertExpectations(t) })
+
+	env.RegisterWorkflow(BillingWorkflow)
+	env.RegisterActivity(activities.StartBillingActivityFunc)
+	env.RegisterActivity(activities.AddLineItemActivityFunc)
+	env.RegisterActivity(activities.RedeemCouponActivityFunc)
+	env.RegisterActivity(activities.CloseBillingActivityFunc)
+	env.RegisterActivity(activities.MeterUsageActivityFunc)
+	env.RegisterActivity(activities.GetTaxRatesActivityFunc)
+	env.RegisterActivity(activities.PrepareSubscriptionRenewalActivityFunc)
+	env.RegisterActivity(activities.CreateBillingSummaryActivityFunc)
+	env.RegisterActivity(activities.ChargeBillingActivityFunc)
+
+	env.OnActivity(activities.StartBillingActivityFunc, mock.Anything, mock.Anything).Return(testBillingID, nil).Once()
+	env.OnActivity(activities.AddLineItemActivityFunc, mock.Anything, testBillingID, mock.Anything, testExternalBillingID).Return(nil)
+
+	return env
+}
+
+// mockCloseBilling mocks closing the billing, closing takes closeDuration and the summary it writes is stored in summary
+func mockCloseBilling(env *testsuite.TestWorkflowEnvironment, closeDuration time.Duration, summary *BillingWorkflowState) {
+	env.OnActivity(activities.CloseBillingActivityFunc, mock.Anything, testBillingID, mock.Anything).After(closeDuration).Return("INV-1", nil).Once()
+	env.OnActivity(activities.MeterUsageActivityFunc, mock.Anything, testBillingID, mock.Anything).Return([]entities.LineItem{}, nil).Once()
+	env.OnActivity(activities.CreateBillingSummaryActivityFunc, mock.Anything, testExternalBillingID, mock.Anything).Return(
+		func(ctx context.Context, externalBillingID string, billingSummary []byte) (int64, error) {
+			return 0, json.Unmarshal(billingSummary, summary)
+		}).Once()
+}
+
+func testBillingInput() BillingWorkflowInput {
+	return BillingWorkflowInput{
+		UserID:            "user-1",
+		ExternalBillingID: testExternalBillingID,
+		Description:       "Pro plan",
+		Currency:          "USD",
+		CurrencyPrecision: 2,
+		InitialLineItems:  []LineItemState{{LineItemID: "line-item-1", Description: "Pro plan", AmountMinor: 10000}},
+	}
+}
+
+// applicationErrorType returns the type of the application error wrapped in err, if any
+func applicationErrorType(err error) string {
+	var applicationErr *temporal.ApplicationError
+	if errors.As(err, &applicationErr) {
+		return applicationErr.Type()
+	}
+	return ""
+}
+
+func TestBillingWorkflow_ApplyCoupon(t *testing.T) {
+	env := newBillingTestEnvironment(t)
+
+	var summary BillingWorkflowState
+	mockCloseBilling(env, time.Minute, &summary)
+
+	coupon := entities.Coupon{ID: 7, Code: "WELCOME10", Type: entities.CouponTypePercentage, PercentOffBPS: 1000}
+	exhaustedCoupon := entities.Coupon{ID: 8, Code: "LAUNCH50", Type: entities.CouponTypePercentage, PercentOffBPS: 5000}
+	lateCoupon := entities.Coupon{ID: 9, Code: "LATE20", Type: entities.CouponTypePercentage, PercentOffBPS: 2000}
+
+	env.OnActivity(activities.RedeemCouponActivityFunc, mock.Anything, coupon.ID, testBillingID).Return(nil).Once()
+	env.OnActivity(activities.RedeemCouponActivityFunc, mock.Anything, exhaustedCoupon.ID, testBillingID).Return(
+		temporal.NewNonRetryableApplicationError("coupon redemption limit reached", activities.CouponRedemptionLimitReachedErrorType, nil)).Once()
+
+	var couponErr, repeatedCouponErr, exhaustedCouponErr, lateCouponErr error
+	couponCompleted, lateCouponRejected := false, false
+	env.RegisterDelayedCallback(func() {
+		env.UpdateWorkflow(ApplyCouponUpdate, "apply-coupon-1", &testsuite.TestUpdateCallback{
+			OnAccept:   func() {},
+			OnReject:   func(err error) { couponErr = err },
+			OnComplete: func(result interface{}, err error) { couponCompleted, couponErr = true, err },
+		}, coupon)
+		env.UpdateWorkflow(ApplyCouponUpdate, "apply-coupon-2", &testsuite.TestUpdateCallback{
+			OnAccept:   func() {},
+			OnReject:   func(err error) { exhaustedCouponErr = err },
+			OnComplete: func(result interface{}, err error) { exhaustedCouponErr = err },
+		}, exhaustedCoupon)
+	}, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		// applying the same coupon again is a no-op, it is not redeemed twice
+		env.UpdateWorkflow(ApplyCouponUpdate, "apply-coupon-3", &testsuite.TestUpdateCallback{
+			OnAccept:   func() {},
+			OnReject:   func(err error) { repeatedCouponErr = err },
+			OnComplete: func(result interface{}, err error) { repeatedCouponErr = err },
+		}, coupon)
+		env.SignalWorkflow(CloseBillingSignal, struct{}{})
+	}, 2*time.Minute)
+	env.RegisterDelayedCallback(func() {
+		// the billing is closing, so the coupon is rejected without being redeemed
+		env.UpdateWorkflow(ApplyCouponUpdate, "apply-coupon-4", &testsuite.TestUpdateCallback{
+			OnAccept:   func() {},
+			OnReject:   func(err error) { lateCouponRejected, lateCouponErr = true, err },
+			OnComplete: func(result interface{}, err error) { lateCouponErr = err },
+		}, lateCoupon)
+	}, 2*time.Minute+30*time.Second)
+
+	env.ExecuteWorkflow(BillingWorkflow, testBillingInput())
+
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("Expected workflow to complete")
+	}
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("BillingWorkflow failed: %v", err)
+	}
+
+	if !couponCompleted || couponErr != nil {
+		t.Errorf("Expected coupon to be applied, got completed %v error %v", couponCompleted, couponErr)
+	}
+	if repeatedCouponErr != nil {
+		t.Errorf("Expected repeated coupon to be a no-op, got %v", repeatedCouponErr)
+	}
+	if errorType := applicationErrorType(exhaustedCouponErr); errorType != activities.CouponRedemptionLimitReachedErrorType {
+		t.Errorf("Expected exhausted coupon error %s, got %v", activities.CouponRedemptionLimitReachedErrorType, exhaustedCouponErr)
+	}
+	if errorType := applicationErrorType(lateCouponErr); !lateCouponRejected || errorType != BillingNotOpenErrorType {
+		t.Errorf("Expected late coupon to be rejected with %s, got %v", BillingNotOpenErrorType, lateCouponErr)
+	}
+
+	if len(summary.Discounts) != 1 || summary.Discounts[0].CouponCode != coupon.Code {
+		t.Fatalf("Expected only the %s discount, got %+v", coupon.Code, summary.Discounts)
+	}
+	if summary.DiscountAmountMinor != 1000 {
+		t.Errorf("Expected discount 1000, got %d", summary.DiscountAmountMinor)
+	}
+	if summary.GrandTotalAmountMinor != 9000 {
+		t.Errorf("Expected grand total 9000, got %d", summary.GrandTotalAmountMinor)
+	}
+}
+
+func TestBillingWorkflow_AddLineItem(t *testing.T) {
+	env := newBillingTestEnvironment(t)
+
+	var summary BillingWorkflowState
+	mockCloseBilling(env, 0, &summary)
+
+	input := testBillingInput()
+	input.SpendLimit = &entities.SpendLimit{AmountMinor: 15000, Mode: entities.SpendLimitModeReject}
+
+	// each adjustment is valid on its own, together they exceed the line item they reference
+	adjustment := LineItemState{LineItemID: "adjustment-1", Kind: entities.LineItemKindAdjustment, Description: "Goodwill", AmountMinor: -8000, Reason: "outage", ReferenceLineItemID: "line-item-1"}
+	secondAdjustment := LineItemState{LineItemID: "adjustment-2", Kind: entities.LineItemKindAdjustment, Description: "Goodwill", AmountMinor: -5000, Reason: "outage", ReferenceLineItemID: "line-item-1"}
+	charge := LineItemState{Description: "Seats", AmountMinor: 4000}
+	overLimitCharge := LineItemState{Description: "Seats", AmountMinor: 12000}
+
+	errs := make(map[string]error)
+	update := func(updateID string, lineItem LineItemState) {
+		env.UpdateWorkflow(AddLineItemUpdate, updateID, &testsuite.TestUpdateCallback{
+			OnAccept:   func() {},
+			OnReject:   func(err error) { errs[updateID] = err },
+			OnComplete: func(result interface{}, err error) { errs[updateID] = err },
+		}, lineItem)
+	}
+	env.RegisterDelayedCallback(func() { update("adjustment-1", adjustment) }, time.Minute)
+	env.RegisterDelayedCallback(func() { update("adjustment-2", secondAdjustment) }, time.Minute+time.Second)
+	env.RegisterDelayedCallback(func() { update("charge", charge) }, time.Minute+2*time.Second)
+	env.RegisterDelayedCallback(func() { update("over-limit-charge", overLimitCharge) }, time.Minute+3*time.Second)
+	// repeating a line item with the same ID does not add it twice
+	env.RegisterDelayedCallback(func() { update("adjustment-1-repeated", adjustment) }, time.Minute+4*time.Second)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(CloseBillingSignal, struct{}{})
+	}, 2*time.Minute)
+
+	env.ExecuteWorkflow(BillingWorkflow, input)
+
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("Expected workflow to complete")
+	}
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("BillingWorkflow failed: %v", err)
+	}
+
+	for _, updateID := range []string{"adjustment-1", "charge", "adjustment-1-repeated"} {
+		if err := errs[updateID]; err != nil {
+			t.Errorf("Expected %s to be added, got %v", updateID, err)
+		}
+	}
+	if rejection := LineItemRejection(errs["adjustment-2"]); rejection != entities.ErrAdjustmentExceedsReferencedItem {
+		t.Errorf("Expected second adjustment to be rejected with %v, got %v", entities.ErrAdjustmentExceedsReferencedItem, errs["adjustment-2"])
+	}
+	if rejection := LineItemRejection(errs["over-limit-charge"]); rejection != entities.ErrSpendLimitExceeded {
+		t.Errorf("Expected over limit charge to be rejected with %v, got %v", entities.ErrSpendLimitExceeded, errs["over-limit-charge"])
+	}
+
+	if len(summary.LineItems) != 3 {
+		t.Fatalf("Expected 3 line items, got %+v", summary.LineItems)
+	}
+	if summary.TotalAmountMinor != 6000 {
+		t.Errorf("Expected total 6000, got %d", summary.TotalAmountMinor)
+	}
+}
+
+func TestBillingWorkflow_SummaryFails(t *testing.T) {
+	env := newBillingTestEnvironment(t)
+
+	// the wallet is drawn in the transaction of the summary, so a summary that fails draws nothing and fails the workflow
+	// instead of leaving it waiting on a closed billing
+	env.OnActivity(activities.CloseBillingActivityFunc, mock.Anything, testBillingID, mock.Anything).Return("INV-1", nil).Once()
+	env.OnActivity(activities.MeterUsageActivityFunc, mock.Anything, testBillingID, mock.Anything).Return([]entities.LineItem{}, nil).Once()
+	env.OnActivity(activities.CreateBillingSummaryActivityFunc, mock.Anything, testExternalBillingID, mock.Anything).Return(int64(0),
+		temporal.NewNonRetryableApplicationError("billing summary mismatch", activities.BillingSummaryMismatchErrorType, nil)).Once()
+	charged := false
+	env.OnActivity(activities.ChargeBillingActivityFunc, mock.Anything, testExternalBillingID).Return(
+		func(ctx context.Context, externalBillingID string) (entities.ChargeAttempt, error) {
+			charged = true
+			return entities.ChargeAttempt{Status: entities.ChargeAttemptStatusSucceeded}, nil
+		}).Maybe()
+
+	input := testBillingInput()
+	input.AutoCharge = true
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(CloseBillingSignal, struct{}{})
+	}, time.Minute)
+
+	env.ExecuteWorkflow(BillingWorkflow, input)
+
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("Expected workflow to complete")
+	}
+	if err := env.GetWorkflowError(); applicationErrorType(err) != activities.BillingSummaryMismatchErrorType {
+		t.Fatalf("Expected workflow to fail with %s, got %v", activities.BillingSummaryMismatchErrorType, err)
+	}
+	if charged {
+		t.Error("Expected a billing without summary not to be charged")
+	}
+}
+
+func TestBillingWorkflow_TaxRatesFail(t *testing.T) {
+	env := newBillingTestEnvironment(t)
+
+	// totals are computed before the billing is closed, so a tax failure leaves no numbered invoice without them
+	env.OnActivity(activities.MeterUsageActivityFunc, mock.Anything, testBillingID, mock.Anything).Return([]entities.LineItem{}, nil).Once()
+	env.OnActivity(activities.GetTaxRatesActivityFunc, mock.Anything, "XX").Return(
+		nil, temporal.NewNonRetryableApplicationError("tax rates unavailable", "TaxRatesUnavailable", nil)).Once()
+	closed := false
+	env.OnActivity(activities.CloseBillingActivityFunc, mock.Anything, testBillingID, mock.Anything).Return(
+		func(ctx context.Context, billingID int64, closedAt time.Time) (string, error) {
+			closed = true
+			return "INV-1", nil
+		}).Maybe()
+
+	input := testBillingInput()
+	input.TaxJurisdiction = "XX"
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(CloseBillingSignal, struct{}{})
+	}, time.Minute)
+
+	env.ExecuteWorkflow(BillingWorkflow, input)
+
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("Expected workflow to complete")
+	}
+	if err := env.GetWorkflowError(); applicationErrorType(err) != "TaxRatesUnavailable" {
+		t.Fatalf("Expected workflow to fail with the tax rates error, got %v", err)
+	}
+	if closed {
+		t.Error("Expected a billing without totals not to be closed")
+	}
+}
+
+func TestBillingWorkflow_RenewalFails(t *testing.T) {
+	env := newBillingTestEnvironment(t)
+
+	// the next billing is started before the billing is closed, so a failed renewal fails the workflow instead of ending
+	// the chain of billings
+	env.OnActivity(activities.MeterUsageActivityFunc, mock.Anything, testBillingID, mock.Anything).Return([]entities.LineItem{}, nil).Once()
+	env.OnActivity(activities.PrepareSubscriptionRenewalActivityFunc, mock.Anything, "subscription-1", mock.Anything).Return(
+		activities.SubscriptionRenewal{}, temporal.NewNonRetryableApplicationError("subscription unavailable", "SubscriptionUnavailable", nil)).Once()
+	closed := false
+	env.OnActivity(activities.CloseBillingActivityFunc, mock.Anything, testBillingID, mock.Anything).Return(
+		func(ctx context.Context, billingID int64, closedAt time.Time) (string, error) {
+			closed = true
+			return "INV-1", nil
+		}).Maybe()
+
+	input := testBillingInput()
+	subscriptionID := "subscription-1"
+	input.SubscriptionID = &subscriptionID
+	input.Recurrence = &entities.RecurrenceRule{Frequency: entities.RecurrenceFrequencyMonthly, Interval: 1, DayOfMonth: 1}
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(CloseBillingSignal, struct{}{})
+	}, time.Minute)
+
+	env.ExecuteWorkflow(BillingWorkflow, input)
+
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("Expected workflow to complete")
+	}
+	if err := env.GetWorkflowError(); applicationErrorType(err) != "SubscriptionUnavailable" {
+		t.Fatalf("Expected workflow to fail with the renewal error, got %v", err)
+	}
+	if closed {
+		t.Error("Expected a billing whose next billing was not started not to be closed")
+	}
+}
+
+func TestBillingWorkflow_MeterUsage(t *testing.T) {
+	env := newBillingTestEnvironment(t)
+
+	// usage is claimed before the billing is closed, at the same time
+	var meteredUntil time.Time
+	usage := []entities.LineItem{
+		{LineItemID: "usage-api-calls", Kind: entities.LineItemKindCharge, Description: "API calls × 1500", AmountMinor: 3000},
+		{LineItemID: "usage-seats", Kind: entities.LineItemKindCharge, Description: "Seats × 5", AmountMinor: 5000},
+	}
+	env.OnActivity(activities.MeterUsageActivityFunc, mock.Anything, testBillingID, mock.Anything).Return(
+		func(ctx context.Context, billingID int64, closedAt time.Time) ([]entities.LineItem, error) {
+			meteredUntil = closedAt
+			return usage, nil
+		}).Once()
+	env.OnActivity(activities.CloseBillingActivityFunc, mock.Anything, testBillingID, mock.Anything).Return(
+		func(ctx context.Context, billingID int64, closedAt time.Time) (string, error) {
+			if meteredUntil.IsZero() || !closedAt.Equal(meteredUntil) {
+				t.Errorf("Expected the billing to close when its usage was claimed until %v, got %v", meteredUntil, closedAt)
+			}
+			return "INV-1", nil
+		}).Once()
+	var summary BillingWorkflowState
+	env.OnActivity(activities.CreateBillingSummaryActivityFunc, mock.Anything, testExternalBillingID, mock.Anything).Return(
+		func(ctx context.Context, externalBillingID string, billingSummary []byte) (int64, error) {
+			return 0, json.Unmarshal(billingSummary, &summary)
+		}).Once()
+
+	input := testBillingInput()
+	input.SpendLimit = &entities.SpendLimit{AmountMinor: 15000, Mode: entities.SpendLimitModeReject}
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(CloseBillingSignal, struct{}{})
+	}, time.Minute)
+
+	env.ExecuteWorkflow(BillingWorkflow, input)
+
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("Expected workflow to complete")
+	}
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("BillingWorkflow failed: %v", err)
+	}
+
+	// the seats take the total over the limit, but usage already consumed is billed anyway
+	if len(summary.LineItems) != 3 || summary.LineItems[1].LineItemID != "usage-api-calls" || summary.LineItems[2].LineItemID != "usage-seats" {
+		t.Fatalf("Expected the plan, the API calls and the seats, got %+v", summary.LineItems)
+	}
+	if summary.TotalAmountMinor != 18000 {
+		t.Errorf("Expected total 18000, got %d", summary.TotalAmountMinor)
+	}
+}
+
+func TestBillingWorkflow_MeterUsageFails(t *testing.T) {
+	env := newBillingTestEnvironment(t)
+
+	// the close is triggered once, so a billing whose usage cannot be claimed fails instead of staying open
+	env.OnActivity(activities.MeterUsageActivityFunc, mock.Anything, testBillingID, mock.Anything).Return(
+		nil, temporal.NewNonRetryableApplicationError("usage unavailable", "UsageUnavailable", nil)).Once()
+	closed := false
+	env.OnActivity(activities.CloseBillingActivityFunc, mock.Anything, testBillingID, mock.Anything).Return(
+		func(ctx context.Context, billingID int64, closedAt time.Time) (string, error) {
+			closed = true
+			return "INV-1", nil
+		}).Maybe()
+
+	// the billing is due to auto-close, there is no signal to retry the close with
+	input := testBillingInput()
+	plannedClosedAt := env.Now().Add(time.Hour)
+	input.PlannedClosedAt = &plannedClosedAt
+
+	env.ExecuteWorkflow(BillingWorkflow, input)
+
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("Expected workflow to complete")
+	}
+	err := env.GetWorkflowError()
+	if err == nil {
+		t.Fatal("Expected BillingWorkflow to fail")
+	}
+	if errType := applicationErrorType(err); errType != "UsageUnavailable" {
+		t.Errorf("Expected the metering error, got %v", err)
+	}
+	if closed {
+		t.Error("Expected a billing whose usage was not claimed not to be closed")
+	}
+}
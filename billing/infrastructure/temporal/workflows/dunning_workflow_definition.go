@@ -0,0 +1,166 @@
+package workflows
+
+import (
+	"time"
+
+	"encore.dev/rlog"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/infrastructure/temporal/activities"
+)
+
+const (
+	DunningWorkflowIDPrefix = "dunning-workflow-"
+
+	// PaymentReceivedSignal stops the dunning of a billing that was paid
+	PaymentReceivedSignal = "payment-received"
+
+	// DunningDay is the length of a day of a dunning schedule
+	DunningDay = 24 * time.Hour
+)
+
+type DunningWorkflowInput struct {
+	ExternalBillingID string `json:"billing_id"`
+
+	// ScheduleDays are the days after the dunning started on which its steps run, defaults to entities.DefaultDunningScheduleDays
+	ScheduleDays []int `json:"schedule_days,omitempty"`
+
+	// AutoCharge charges the billing again before each step
+	AutoCharge bool `json:"auto_charge,omitempty"`
+}
+
+type DunningWorkflowState struct {
+	ExternalBillingID string                 `json:"billing_id"`
+	Status            entities.DunningStatus `json:"status"`
+	Step              int                    `json:"step"`
+	ScheduleDays      []int                  `json:"schedule_days"`
+	StartedAt         time.Time              `json:"started_at"`
+	NextStepAt        *time.Time             `json:"next_step_at,omitempty"`
+}
+
+// DunningWorkflow chases the payment of a closed, unpaid billing. Each day of the schedule retries the charge of auto charged
+// billings and sends a payment reminder, escalating the billing to past due and at the last step to uncollectible.
+// The workflow stops as soon as the billing is paid.
+func DunningWorkflow(ctx workflow.Context, input DunningWorkflowInput) (DunningWorkflowState, error) {
+	fn := "dunningWorkflowDefinition.DunningWorkflow"
+	logger := rlog.With("fn", fn).With("externalBillingID", input.ExternalBillingID).With("scheduleDays", input.ScheduleDays)
+
+	logger.Info("DunningWorkflow starting")
+
+	scheduleDays := input.ScheduleDays
+	if len(scheduleDays) == 0 {
+		scheduleDays = entities.DefaultDunningScheduleDays
+	}
+
+	state := DunningWorkflowState{
+		ExternalBillingID: input.ExternalBillingID,
+		Status:            entities.DunningStatusActive,
+		ScheduleDays:      scheduleDays,
+		StartedAt:         workflow.Now(ctx),
+	}
+
+	activityOptions := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    10,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	// set query handler for current state
+	err := workflow.SetQueryHandler(ctx, "currentState", func() (DunningWorkflowState, error) {
+		return state, nil
+	})
+	if err != nil {
+		logger.Error("Failed to set query handler", "error", err)
+		return state, err
+	}
+
+	var dunning entities.Dunning
+	err = workflow.ExecuteActivity(ctx, activities.StartDunningActivityFunc, input.ExternalBillingID, scheduleDays).Get(ctx, &dunning)
+	if err != nil {
+		logger.Error("Failed to start dunning", "error", err)
+		return state, err
+	}
+
+	paymentChan := workflow.GetSignalChannel(ctx, PaymentReceivedSignal)
+
+	for i, day := range scheduleDays {
+		step := i + 1
+		nextStepAt := state.StartedAt.Add(time.Duration(day) * DunningDay)
+		state.NextStepAt = &nextStepAt
+
+		// wait for the day of the step or a payment, whichever comes first
+		timerCtx, cancelTimer := workflow.WithCancel(ctx)
+		paid := false
+		selector := workflow.NewSelector(ctx)
+		selector.AddFuture(workflow.NewTimer(timerCtx, nextStepAt.Sub(workflow.Now(ctx))), func(f workflow.Future) {})
+		selector.AddReceive(paymentChan, func(c workflow.ReceiveChannel, more bool) {
+			c.Receive(ctx, nil)
+			paid = true
+		})
+		selector.Select(ctx)
+		cancelTimer()
+
+		if paid {
+			logger.Info("Payment received, stopping dunning", "step", state.Step)
+
+			err = workflow.ExecuteActivity(ctx, activities.ResolveDunningActivityFunc, input.ExternalBillingID).Get(ctx, nil)
+			if err != nil {
+				logger.Error("Failed to resolve dunning", "error", err)
+				return state, err
+			}
+
+			state.Status = entities.DunningStatusResolved
+			state.NextStepAt = nil
+			return state, nil
+		}
+
+		// charge again, a successful charge resolves the dunning in the step below
+		if input.AutoCharge {
+			chargeCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+				StartToCloseTimeout: 30 * time.Second,
+				RetryPolicy: &temporal.RetryPolicy{
+					InitialInterval:    10 * time.Second,
+					BackoffCoefficient: 2.0,
+					MaximumInterval:    time.Minute,
+					MaximumAttempts:    activities.ChargeMaxAttempts,
+				},
+			})
+
+			var chargeAttempt entities.ChargeAttempt
+			err = workflow.ExecuteActivity(chargeCtx, activities.RetryBillingChargeActivityFunc, input.ExternalBillingID, step).Get(ctx, &chargeAttempt)
+			if err != nil {
+				logger.Error("Failed to charge billing", "step", step, "error", err)
+			} else {
+				logger.Info("Billing charge attempted", "step", step, "status", chargeAttempt.Status, "declineCode", chargeAttempt.DeclineCode)
+			}
+		}
+
+		var status entities.DunningStatus
+		err = workflow.ExecuteActivity(ctx, activities.DunningStepActivityFunc, input.ExternalBillingID, step, len(scheduleDays)).Get(ctx, &status)
+		if err != nil {
+			logger.Error("Failed to run dunning step", "step", step, "error", err)
+			return state, err
+		}
+
+		state.Step = step
+		state.Status = status
+		state.NextStepAt = nil
+
+		if status == entities.DunningStatusResolved {
+			logger.Info("Billing paid, dunning resolved", "step", step)
+			return state, nil
+		}
+
+		logger.Info("Dunning step completed", "step", step, "status", status)
+	}
+
+	logger.Info("DunningWorkflow completed", "status", state.Status)
+	return state, nil
+}
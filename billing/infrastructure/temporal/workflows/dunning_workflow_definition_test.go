@@ -0,0 +1,153 @@
+package workflows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/testsuite"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/infrastructure/temporal/activities"
+)
+
+const testDunningBillingID = "billing-1"
+
+// newDunningTestEnvironment registers the dunning activities and mocks starting and resolving the dunning, the test
+// environment skips the timers of the schedule
+func newDunningTestEnvironment(t *testing.T) *testsuite.TestWorkflowEnvironment {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	t.Cleanup(func() { env.AssertExpectations(t) })
+
+	env.RegisterWorkflow(DunningWorkflow)
+	env.RegisterActivity(activities.StartDunningActivityFunc)
+	env.RegisterActivity(activities.DunningStepActivityFunc)
+	env.RegisterActivity(activities.RetryBillingChargeActivityFunc)
+	env.RegisterActivity(activities.ResolveDunningActivityFunc)
+
+	env.OnActivity(activities.StartDunningActivityFunc, mock.Anything, testDunningBillingID, mock.Anything).Return(entities.Dunning{Status: entities.DunningStatusActive}, nil).Once()
+
+	return env
+}
+
+// mockUnpaidSteps makes every step find the billing unpaid and records when the steps ran
+func mockUnpaidSteps(env *testsuite.TestWorkflowEnvironment, stepTimes *[]time.Time) {
+	env.OnActivity(activities.DunningStepActivityFunc, mock.Anything, testDunningBillingID, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, externalBillingID string, step int, steps int) (entities.DunningStatus, error) {
+			*stepTimes = append(*stepTimes, env.Now())
+			return entities.DunningStepStatus(step, steps), nil
+		})
+}
+
+func TestDunningWorkflow_Unpaid(t *testing.T) {
+	env := newDunningTestEnvironment(t)
+	start := env.Now()
+
+	var stepTimes []time.Time
+	mockUnpaidSteps(env, &stepTimes)
+
+	env.ExecuteWorkflow(DunningWorkflow, DunningWorkflowInput{ExternalBillingID: testDunningBillingID})
+
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("Expected workflow to complete")
+	}
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("DunningWorkflow failed: %v", err)
+	}
+
+	var state DunningWorkflowState
+	if err := env.GetWorkflowResult(&state); err != nil {
+		t.Fatalf("failed to get workflow result: %v", err)
+	}
+	if state.Status != entities.DunningStatusUncollectible || state.Step != 3 {
+		t.Errorf("DunningWorkflow() = %v at step %v, expected %v at step 3", state.Status, state.Step, entities.DunningStatusUncollectible)
+	}
+
+	// steps run on the days of the default schedule
+	expected := []time.Duration{24 * time.Hour, 72 * time.Hour, 168 * time.Hour}
+	if len(stepTimes) != len(expected) {
+		t.Fatalf("Expected %d steps, got %d", len(expected), len(stepTimes))
+	}
+	for i, offset := range expected {
+		if got := stepTimes[i].Sub(start); got != offset {
+			t.Errorf("Step %d ran after %v, expected %v", i+1, got, offset)
+		}
+	}
+}
+
+func TestDunningWorkflow_CustomSchedule(t *testing.T) {
+	env := newDunningTestEnvironment(t)
+	start := env.Now()
+
+	var stepTimes []time.Time
+	mockUnpaidSteps(env, &stepTimes)
+
+	env.ExecuteWorkflow(DunningWorkflow, DunningWorkflowInput{ExternalBillingID: testDunningBillingID, ScheduleDays: []int{2, 5}})
+
+	var state DunningWorkflowState
+	if err := env.GetWorkflowResult(&state); err != nil {
+		t.Fatalf("DunningWorkflow failed: %v", err)
+	}
+	if state.Status != entities.DunningStatusUncollectible || state.Step != 2 {
+		t.Errorf("DunningWorkflow() = %v at step %v, expected %v at step 2", state.Status, state.Step, entities.DunningStatusUncollectible)
+	}
+	if len(stepTimes) != 2 || stepTimes[0].Sub(start) != 48*time.Hour || stepTimes[1].Sub(start) != 120*time.Hour {
+		t.Errorf("Unexpected step times: %v", stepTimes)
+	}
+}
+
+func TestDunningWorkflow_PaymentSignal(t *testing.T) {
+	env := newDunningTestEnvironment(t)
+
+	var stepTimes []time.Time
+	mockUnpaidSteps(env, &stepTimes)
+	env.OnActivity(activities.ResolveDunningActivityFunc, mock.Anything, testDunningBillingID).Return(nil).Once()
+
+	// the billing is paid between the first and second step
+	env.RegisterDelayedCallback(func() {
+		var state DunningWorkflowState
+		value, err := env.QueryWorkflow("currentState")
+		if err != nil {
+			t.Errorf("failed to query workflow: %v", err)
+		} else if err = value.Get(&state); err != nil || state.Status != entities.DunningStatusPastDue {
+			t.Errorf("currentState = %v, expected %v", state.Status, entities.DunningStatusPastDue)
+		}
+
+		env.SignalWorkflow(PaymentReceivedSignal, struct{}{})
+	}, 48*time.Hour)
+
+	env.ExecuteWorkflow(DunningWorkflow, DunningWorkflowInput{ExternalBillingID: testDunningBillingID})
+
+	var state DunningWorkflowState
+	if err := env.GetWorkflowResult(&state); err != nil {
+		t.Fatalf("DunningWorkflow failed: %v", err)
+	}
+	if state.Status != entities.DunningStatusResolved || state.Step != 1 {
+		t.Errorf("DunningWorkflow() = %v at step %v, expected %v at step 1", state.Status, state.Step, entities.DunningStatusResolved)
+	}
+	if len(stepTimes) != 1 {
+		t.Errorf("Expected 1 step before the payment, got %d", len(stepTimes))
+	}
+}
+
+func TestDunningWorkflow_ChargeRetrySucceeds(t *testing.T) {
+	env := newDunningTestEnvironment(t)
+
+	// the charge is declined at the first step and captured at the second
+	env.OnActivity(activities.RetryBillingChargeActivityFunc, mock.Anything, testDunningBillingID, 1).Return(entities.ChargeAttempt{Status: entities.ChargeAttemptStatusDeclined}, nil).Once()
+	env.OnActivity(activities.RetryBillingChargeActivityFunc, mock.Anything, testDunningBillingID, 2).Return(entities.ChargeAttempt{Status: entities.ChargeAttemptStatusSucceeded}, nil).Once()
+	env.OnActivity(activities.DunningStepActivityFunc, mock.Anything, testDunningBillingID, 1, 3).Return(entities.DunningStatusPastDue, nil).Once()
+	env.OnActivity(activities.DunningStepActivityFunc, mock.Anything, testDunningBillingID, 2, 3).Return(entities.DunningStatusResolved, nil).Once()
+
+	env.ExecuteWorkflow(DunningWorkflow, DunningWorkflowInput{ExternalBillingID: testDunningBillingID, AutoCharge: true})
+
+	var state DunningWorkflowState
+	if err := env.GetWorkflowResult(&state); err != nil {
+		t.Fatalf("DunningWorkflow failed: %v", err)
+	}
+	if state.Status != entities.DunningStatusResolved || state.Step != 2 {
+		t.Errorf("DunningWorkflow() = %v at step %v, expected %v at step 2", state.Status, state.Step, entities.DunningStatusResolved)
+	}
+}
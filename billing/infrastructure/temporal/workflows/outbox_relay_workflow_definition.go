@@ -0,0 +1,57 @@
+package workflows
+
+import (
+	"time"
+
+	"encore.dev/rlog"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"encore.app/billing/infrastructure/temporal/activities"
+)
+
+const (
+	OutboxRelayWorkflowID = "outbox-relay-workflow"
+
+	// OutboxRelayInterval is how long the relay waits when the outbox has no more pending messages
+	OutboxRelayInterval = 2 * time.Second
+
+	// outboxRelayIterations is how many relays run before the workflow continues as new, so that its history stays small
+	outboxRelayIterations = 1000
+)
+
+// OutboxRelayWorkflow publishes the outbox, it runs for as long as the service does
+func OutboxRelayWorkflow(ctx workflow.Context) error {
+	fn := "outboxRelayWorkflowDefinition.OutboxRelayWorkflow"
+	logger := rlog.With("fn", fn)
+
+	// a failed publish is retried a few times, then the next relay starts again from the failed message
+	activityOptions := workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    30 * time.Second,
+			MaximumAttempts:    3,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	for i := 0; i < outboxRelayIterations; i++ {
+		var relayed int
+		err := workflow.ExecuteActivity(ctx, activities.RelayOutboxActivityFunc).Get(ctx, &relayed)
+		if err != nil {
+			logger.Error("Outbox relay failed", "error", err)
+		}
+
+		// a full batch means more messages are pending, relay them right away
+		if err != nil || relayed < activities.OutboxRelayBatchSize {
+			err = workflow.Sleep(ctx, OutboxRelayInterval)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return workflow.NewContinueAsNewError(ctx, OutboxRelayWorkflow)
+}
@@ -0,0 +1,48 @@
+package workflows
+
+import (
+	"time"
+
+	"encore.dev/rlog"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"encore.app/billing/infrastructure/temporal/activities"
+)
+
+const (
+	RefundWorkflowIDPrefix = "refund-workflow-"
+)
+
+type RefundWorkflowInput struct {
+	ExternalRefundID string `json:"refund_id"`
+}
+
+// RefundWorkflow returns a pending refund to the payer, retrying the payment gateway until it answers
+func RefundWorkflow(ctx workflow.Context, input RefundWorkflowInput) error {
+	fn := "refundWorkflowDefinition.RefundWorkflow"
+	logger := rlog.With("fn", fn).With("externalRefundID", input.ExternalRefundID)
+
+	logger.Info("RefundWorkflow starting")
+
+	// each attempt is a retry of the activity, from 10 seconds up to 10 minutes apart
+	activityOptions := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    10 * time.Minute,
+			MaximumAttempts:    activities.RefundMaxAttempts,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	err := workflow.ExecuteActivity(ctx, activities.RefundPaymentActivityFunc, input.ExternalRefundID).Get(ctx, nil)
+	if err != nil {
+		logger.Error("Refund failed", "error", err)
+		return err
+	}
+
+	logger.Info("RefundWorkflow completed")
+	return nil
+}
@@ -0,0 +1,48 @@
+package workflows
+
+import (
+	"time"
+
+	"encore.dev/rlog"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"encore.app/billing/infrastructure/temporal/activities"
+)
+
+const (
+	WebhookDeliveryWorkflowIDPrefix = "webhook-delivery-workflow-"
+)
+
+type WebhookDeliveryWorkflowInput struct {
+	ExternalDeliveryID string `json:"delivery_id"`
+}
+
+// WebhookDeliveryWorkflow sends a delivery to its webhook, retrying with exponential backoff until the receiver accepts it
+func WebhookDeliveryWorkflow(ctx workflow.Context, input WebhookDeliveryWorkflowInput) error {
+	fn := "webhookDeliveryWorkflowDefinition.WebhookDeliveryWorkflow"
+	logger := rlog.With("fn", fn).With("externalDeliveryID", input.ExternalDeliveryID)
+
+	logger.Info("WebhookDeliveryWorkflow starting")
+
+	// each attempt is a retry of the activity, from 30 seconds up to an hour apart
+	activityOptions := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    30 * time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    time.Hour,
+			MaximumAttempts:    activities.WebhookDeliveryMaxAttempts,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+
+	err := workflow.ExecuteActivity(ctx, activities.DeliverWebhookActivityFunc, input.ExternalDeliveryID).Get(ctx, nil)
+	if err != nil {
+		logger.Error("Webhook delivery failed", "error", err)
+		return err
+	}
+
+	logger.Info("WebhookDeliveryWorkflow completed")
+	return nil
+}
@@ -0,0 +1,86 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=POST path=/invoice-number-series
+func (s *Service) SetInvoiceNumberSeries(ctx context.Context, req *SetInvoiceNumberSeriesRequest) error {
+	fn := "billing.Service.SetInvoiceNumberSeries"
+	logger := rlog.With("fn", fn).With("userGroup", req.UserGroup).With("prefix", req.Prefix).With("padding", req.Padding)
+
+	// apply defaults
+	padding := req.Padding
+	if padding == 0 {
+		padding = entities.DefaultInvoiceNumberPadding
+	}
+
+	err := s.setInvoiceNumberSeriesUsecase.Execute(ctx, entities.InvoiceNumberSeries{
+		UserGroup: req.UserGroup,
+		Prefix:    req.Prefix,
+		Padding:   padding,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidInvoiceNumberSeries) {
+			logger.Warn("invoice number series is invalid")
+			return &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "prefix must be uppercase letters and digits separated by dashes, padding between 1 and 12",
+			}
+		}
+		if errors.Is(err, dto.ErrInvoicePrefixTaken) {
+			logger.Warn("invoice number prefix is used by another user group")
+			return &errs.Error{
+				Code:    errs.AlreadyExists,
+				Message: "prefix is used by another user group",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to set invoice number series", "error", err)
+		return &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to set invoice number series",
+		}
+	}
+
+	logger.Info("Invoice number series set successfully")
+
+	return nil
+}
+
+// encore:api private method=GET path=/invoice-number-series
+func (s *Service) ListInvoiceNumberSeries(ctx context.Context) (*ListInvoiceNumberSeriesResponse, error) {
+	fn := "billing.Service.ListInvoiceNumberSeries"
+	logger := rlog.With("fn", fn)
+
+	seriesList, err := s.listInvoiceNumberSeriesUsecase.Execute(ctx)
+	if err != nil {
+		// unknown error
+		logger.Error("failed to list invoice number series", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list invoice number series",
+		}
+	}
+
+	response := make([]InvoiceNumberSeries, len(seriesList))
+	for i, series := range seriesList {
+		response[i] = InvoiceNumberSeries{
+			UserGroup: series.UserGroup,
+			Prefix:    series.Prefix,
+			Padding:   series.Padding,
+		}
+	}
+
+	return &ListInvoiceNumberSeriesResponse{
+		Series: response,
+	}, nil
+}
@@ -0,0 +1,183 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"encore.dev"
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private raw method=GET path=/billing/:billingID/invoice
+func (s *Service) GetInvoice(w http.ResponseWriter, req *http.Request) {
+	fn := "billing.Service.GetInvoice"
+	billingID := encore.CurrentRequest().PathParams.Get("billingID")
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = entities.InvoiceFormatHTML
+	}
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("format", format)
+
+	invoice, document, err := s.renderInvoiceUsecase.Execute(req.Context(), billingID, format)
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidInvoiceFormat) {
+			logger.Warn("invoice format is invalid")
+			errs.HTTPError(w, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "format must be html or pdf",
+			})
+			return
+		}
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			errs.HTTPError(w, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			})
+			return
+		}
+		if errors.Is(err, dto.ErrBillingNotClosed) {
+			logger.Warn("billing is not closed")
+			errs.HTTPError(w, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing is not closed",
+			})
+			return
+		}
+
+		// unknown error
+		logger.Error("failed to render invoice", "error", err)
+		errs.HTTPError(w, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to render invoice",
+		})
+		return
+	}
+
+	if format == entities.InvoiceFormatPDF {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `inline; filename="`+invoice.InvoiceNumber+`.pdf"`)
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	_, err = w.Write(document)
+	if err != nil {
+		logger.Error("failed to write invoice", "error", err)
+	}
+}
+
+// encore:api private raw method=GET path=/billing/:billingID/summary/ubl
+func (s *Service) GetBillingSummaryUBL(w http.ResponseWriter, req *http.Request) {
+	fn := "billing.Service.GetBillingSummaryUBL"
+	billingID := encore.CurrentRequest().PathParams.Get("billingID")
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	invoice, document, err := s.exportUBLInvoiceUsecase.Execute(req.Context(), billingID)
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			errs.HTTPError(w, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			})
+			return
+		}
+		if errors.Is(err, dto.ErrBillingNotClosed) {
+			logger.Warn("billing is not closed")
+			errs.HTTPError(w, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing is not closed",
+			})
+			return
+		}
+		if errors.Is(err, dto.ErrSellerNotFound) {
+			logger.Warn("seller not found")
+			errs.HTTPError(w, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "seller of the tenant is not set",
+			})
+			return
+		}
+		if errors.Is(err, dto.ErrBuyerNotFound) {
+			logger.Warn("buyer not found")
+			errs.HTTPError(w, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "buyer of the user is not set",
+			})
+			return
+		}
+		if errors.Is(err, dto.ErrInvalidEInvoice) {
+			logger.Warn("billing cannot be exported as an e-invoice")
+			errs.HTTPError(w, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing cannot be exported as an e-invoice",
+			})
+			return
+		}
+
+		// unknown error
+		logger.Error("failed to export invoice", "error", err)
+		errs.HTTPError(w, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to export invoice",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", `inline; filename="`+invoice.InvoiceNumber+`.xml"`)
+	_, err = w.Write(document)
+	if err != nil {
+		logger.Error("failed to write invoice", "error", err)
+	}
+}
+
+// encore:api private method=POST path=/invoice-templates
+func (s *Service) SetInvoiceTemplate(ctx context.Context, req *SetInvoiceTemplateRequest) (*InvoiceTemplate, error) {
+	fn := "billing.Service.SetInvoiceTemplate"
+	logger := rlog.With("fn", fn).With("tenant", req.Tenant).With("format", req.Format)
+
+	invoiceTemplate, err := s.setInvoiceTemplateUsecase.Execute(ctx, entities.InvoiceTemplate{
+		Tenant: req.Tenant,
+		Format: req.Format,
+		Source: req.Template,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidInvoiceFormat) {
+			logger.Warn("invoice format is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "format must be html or pdf",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidInvoiceTemplate) {
+			logger.Warn("invoice template is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "template is invalid",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to set invoice template", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to set invoice template",
+		}
+	}
+
+	logger.Info("Invoice template set successfully")
+
+	return &InvoiceTemplate{
+		Tenant:    invoiceTemplate.Tenant,
+		Format:    invoiceTemplate.Format,
+		Template:  invoiceTemplate.Source,
+		CreatedAt: invoiceTemplate.CreatedAt,
+		UpdatedAt: invoiceTemplate.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,125 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=GET path=/ledger/balances
+func (s *Service) ListLedgerBalances(ctx context.Context, req *ListLedgerBalancesRequest) (*ListLedgerBalancesResponse, error) {
+	fn := "billing.Service.ListLedgerBalances"
+	logger := rlog.With("fn", fn).With("currency", req.Currency)
+
+	balances, err := s.getLedgerBalancesUsecase.Execute(ctx, req.Currency)
+	if err != nil {
+		logger.Error("failed to list ledger balances", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list ledger balances",
+		}
+	}
+
+	response := make([]LedgerAccountBalance, len(balances))
+	for i, balance := range balances {
+		response[i] = LedgerAccountBalance{
+			Account:           balance.Code,
+			Name:              balance.Name,
+			Type:              balance.Type,
+			NormalBalance:     balance.NormalBalance,
+			Currency:          balance.Currency,
+			DebitAmountMinor:  balance.DebitAmountMinor,
+			CreditAmountMinor: balance.CreditAmountMinor,
+			BalanceMinor:      balance.BalanceMinor,
+		}
+	}
+
+	return &ListLedgerBalancesResponse{
+		Balances: response,
+	}, nil
+}
+
+// encore:api private method=GET path=/ledger/check
+func (s *Service) CheckLedger(ctx context.Context) (*LedgerCheck, error) {
+	fn := "billing.Service.CheckLedger"
+	logger := rlog.With("fn", fn)
+
+	check, err := s.checkLedgerUsecase.Execute(ctx)
+	if err != nil {
+		logger.Error("failed to check ledger", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to check ledger",
+		}
+	}
+
+	totals := make([]LedgerTotals, len(check.Totals))
+	for i, total := range check.Totals {
+		totals[i] = LedgerTotals{
+			Currency:          total.Currency,
+			DebitAmountMinor:  total.DebitAmountMinor,
+			CreditAmountMinor: total.CreditAmountMinor,
+		}
+	}
+
+	return &LedgerCheck{
+		Balanced:           check.IsBalanced(),
+		Totals:             totals,
+		UnbalancedEntryIDs: check.UnbalancedExternalEntryIDs,
+	}, nil
+}
+
+// encore:api private method=GET path=/billing/:billingID/journal-entries
+func (s *Service) ListJournalEntries(ctx context.Context, billingID string) (*ListJournalEntriesResponse, error) {
+	fn := "billing.Service.ListJournalEntries"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	entries, err := s.listJournalEntriesUsecase.Execute(ctx, billingID)
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to list journal entries", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list journal entries",
+		}
+	}
+
+	response := make([]JournalEntry, len(entries))
+	for i, entry := range entries {
+		postings := make([]LedgerPosting, len(entry.Postings))
+		for j, posting := range entry.Postings {
+			postings[j] = LedgerPosting{
+				Account:     posting.AccountCode,
+				Direction:   posting.Direction,
+				AmountMinor: posting.AmountMinor,
+			}
+		}
+
+		response[i] = JournalEntry{
+			EntryID:     entry.ExternalEntryID,
+			Kind:        entry.Kind,
+			BillingID:   billingID,
+			Currency:    entry.Currency,
+			Description: entry.Description,
+			Postings:    postings,
+			PostedAt:    entry.PostedAt,
+			CreatedAt:   entry.CreatedAt,
+		}
+	}
+
+	return &ListJournalEntriesResponse{
+		Entries: response,
+	}, nil
+}
@@ -0,0 +1,211 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/pubsub"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/infrastructure/events"
+	"encore.app/billing/usecases/dto"
+)
+
+// subscription ingesting the usage events other services publish
+var _ = pubsub.NewSubscription(events.UsageEventsTopic, "usage-events-ingestion", pubsub.SubscriptionConfig[*entities.UsageEvent]{
+	Handler: pubsub.MethodHandler((*Service).IngestPublishedUsageEvent),
+})
+
+// encore:api private method=POST path=/meters
+func (s *Service) CreateMeter(ctx context.Context, req *CreateMeterRequest) (*Meter, error) {
+	fn := "billing.Service.CreateMeter"
+	logger := rlog.With("fn", fn).With("code", req.Code).With("aggregation", req.Aggregation).With("currency", req.Currency).With("unitAmount", req.UnitAmount)
+
+	// validate code
+	if req.Code == "" {
+		logger.Warn("code is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "code is required",
+		}
+	}
+
+	// validate currency
+	if req.Currency == "" {
+		logger.Warn("currency is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "currency is required",
+		}
+	}
+
+	// validate unit amount
+	if req.UnitAmount < 0 {
+		logger.Warn("unit amount must not be negative")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "unit amount must not be negative",
+		}
+	}
+
+	meter, err := s.createMeterUsecase.Execute(ctx, dto.CreateMeterInput{
+		Code:        req.Code,
+		Name:        req.Name,
+		Aggregation: req.Aggregation,
+		Currency:    req.Currency,
+		UnitAmount:  req.UnitAmount,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrCurrencyNotSupported) {
+			logger.Warn("currency not supported")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "currency not supported",
+			}
+		}
+		if errors.Is(err, dto.ErrAmountHasTooManyDecimals) {
+			logger.Warn("unit amount has too many decimals")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "unit amount has too many decimals",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidMeter) {
+			logger.Warn("meter is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "meter is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrMeterCodeTaken) {
+			logger.Warn("meter code already exists")
+			return nil, &errs.Error{
+				Code:    errs.AlreadyExists,
+				Message: "meter code already exists",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to create meter", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to create meter",
+		}
+	}
+
+	response := meterResponse(meter)
+	return &response, nil
+}
+
+// encore:api private method=GET path=/meters
+func (s *Service) ListMeters(ctx context.Context) (*ListMetersResponse, error) {
+	fn := "billing.Service.ListMeters"
+	logger := rlog.With("fn", fn)
+
+	meters, err := s.listMetersUsecase.Execute(ctx)
+	if err != nil {
+		logger.Error("failed to list meters", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list meters",
+		}
+	}
+
+	response := make([]Meter, len(meters))
+	for i := range meters {
+		response[i] = meterResponse(&meters[i])
+	}
+
+	return &ListMetersResponse{
+		Meters: response,
+	}, nil
+}
+
+// encore:api private method=POST path=/usage-events
+func (s *Service) IngestUsageEvents(ctx context.Context, req *IngestUsageEventsRequest) (*IngestUsageEventsResponse, error) {
+	fn := "billing.Service.IngestUsageEvents"
+	logger := rlog.With("fn", fn).With("events", len(req.Events))
+
+	usageEvents := make([]entities.UsageEvent, len(req.Events))
+	for i, event := range req.Events {
+		usageEvents[i] = entities.UsageEvent{
+			UserID:    event.UserID,
+			MeterCode: event.Meter,
+			Quantity:  event.Quantity,
+			Timestamp: event.Timestamp,
+			DedupID:   event.DedupID,
+		}
+	}
+
+	ingested, err := s.ingestUsageEventsUsecase.Execute(ctx, usageEvents)
+	if err != nil {
+		if errors.Is(err, dto.ErrTooManyUsageEvents) {
+			logger.Warn("too many usage events")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "too many usage events",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidUsageEvent) {
+			logger.Warn("usage event is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "usage event is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrMeterNotFound) {
+			logger.Warn("meter not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "meter not found",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to ingest usage events", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to ingest usage events",
+		}
+	}
+
+	return &IngestUsageEventsResponse{
+		Ingested:   ingested,
+		Duplicates: len(usageEvents) - ingested,
+	}, nil
+}
+
+// IngestPublishedUsageEvent ingests a usage event published on the usage events topic. An invalid event or one of an
+// unknown meter would never be ingested and is dropped, a failed ingestion is retried by Pub/Sub.
+func (s *Service) IngestPublishedUsageEvent(ctx context.Context, event *entities.UsageEvent) error {
+	fn := "billing.Service.IngestPublishedUsageEvent"
+	logger := rlog.With("fn", fn).With("userID", event.UserID).With("meter", event.MeterCode).With("dedupID", event.DedupID)
+
+	_, err := s.ingestUsageEventsUsecase.Execute(ctx, []entities.UsageEvent{*event})
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidUsageEvent) || errors.Is(err, dto.ErrMeterNotFound) {
+			logger.Warn("dropping usage event", "error", err)
+			return nil
+		}
+
+		logger.Error("failed to ingest usage event", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func meterResponse(meter *entities.Meter) Meter {
+	return Meter{
+		Code:              meter.Code,
+		Name:              meter.Name,
+		Aggregation:       meter.Aggregation,
+		Currency:          meter.Currency,
+		CurrencyPrecision: meter.CurrencyPrecision,
+		UnitAmountMinor:   meter.UnitAmountMinor,
+		CreatedAt:         meter.CreatedAt,
+		UpdatedAt:         meter.UpdatedAt,
+	}
+}
@@ -0,0 +1,23 @@
+/* Invoice number series, the prefix of each user group, the empty user group is the default */
+CREATE TABLE invoice_number_series (
+    user_group TEXT PRIMARY KEY,
+    prefix TEXT NOT NULL UNIQUE,
+    padding SMALLINT NOT NULL DEFAULT 6,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+INSERT INTO invoice_number_series (user_group, prefix) VALUES ('', 'INV');
+
+/* Invoice number counters, the last number assigned per prefix and year. The counter is incremented in the
+   transaction closing the billing, so a rolled back close releases its number and the sequence has no gaps */
+CREATE TABLE invoice_number_counters (
+    prefix TEXT NOT NULL,
+    year INTEGER NOT NULL,
+    last_number BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    PRIMARY KEY (prefix, year)
+);
+
+ALTER TABLE billings ADD COLUMN user_group TEXT NOT NULL DEFAULT '';
+ALTER TABLE billings ADD COLUMN invoice_number TEXT DEFAULT NULL UNIQUE;
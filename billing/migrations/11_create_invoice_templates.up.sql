@@ -0,0 +1,9 @@
+/* Invoice templates, a tenant's override of the default template of a format, the tenant is the user group of the billing */
+CREATE TABLE invoice_templates (
+    tenant TEXT NOT NULL,
+    format TEXT NOT NULL,
+    source TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    PRIMARY KEY (tenant, format)
+);
@@ -0,0 +1,16 @@
+/* Parties of e-invoices: sellers keyed by tenant (the user group of the billing), buyers keyed by user ID */
+CREATE TABLE parties (
+    role TEXT NOT NULL CHECK (role IN ('seller', 'buyer')),
+    key TEXT NOT NULL,
+    name TEXT NOT NULL,
+    vat_id TEXT NOT NULL DEFAULT '',
+    street TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    postal_code TEXT NOT NULL DEFAULT '',
+    country_code CHAR(2) NOT NULL,
+    endpoint_id TEXT NOT NULL,
+    endpoint_scheme TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    PRIMARY KEY (role, key)
+);
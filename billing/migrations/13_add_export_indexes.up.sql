@@ -0,0 +1,3 @@
+/* Exports select billings by creation date and join their line items */
+CREATE INDEX billing_created_at_idx ON billings (created_at);
+CREATE INDEX line_item_billing_id_idx ON line_items (billing_id);
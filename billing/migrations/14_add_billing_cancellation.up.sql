@@ -0,0 +1,5 @@
+/* Cancelled billings are never closed nor invoiced */
+ALTER TYPE BILLING_STATUS ADD VALUE 'cancelled';
+
+ALTER TABLE billings ADD COLUMN cancelled_at TIMESTAMPTZ DEFAULT NULL;
+ALTER TABLE billings ADD COLUMN cancellation_reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,40 @@
+/* Webhooks, URLs subscribed to billing event types. Requests are signed with the secret */
+CREATE TABLE webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    external_webhook_id UUID NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE INDEX webhook_event_types_idx ON webhooks USING GIN (event_types);
+
+CREATE TYPE WEBHOOK_DELIVERY_STATUS AS ENUM ('pending', 'succeeded', 'failed');
+
+/* Webhook deliveries, one per webhook and event, so an event published again is delivered once */
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    external_delivery_id UUID NOT NULL UNIQUE,
+    webhook_id BIGINT NOT NULL REFERENCES webhooks(id),
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status WEBHOOK_DELIVERY_STATUS NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    UNIQUE (webhook_id, event_id)
+);
+
+/* Webhook delivery attempts, the delivery log */
+CREATE TABLE webhook_delivery_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    delivery_id BIGINT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    status_code INTEGER NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL,
+    attempted_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX webhook_delivery_attempt_delivery_id_idx ON webhook_delivery_attempts (delivery_id);
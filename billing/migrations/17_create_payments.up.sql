@@ -0,0 +1,20 @@
+CREATE TYPE PAYMENT_METHOD AS ENUM ('card', 'bank_transfer', 'direct_debit', 'cash', 'other');
+
+/* Payments table, money received for closed billings in their currency */
+CREATE TABLE payments (
+    id BIGSERIAL PRIMARY KEY,
+    external_payment_id UUID NOT NULL UNIQUE,
+    billing_id BIGINT NOT NULL REFERENCES billings(id),
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    currency CURRENCY_CODE NOT NULL,
+    method PAYMENT_METHOD NOT NULL,
+    external_reference TEXT NOT NULL DEFAULT '',
+    paid_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE INDEX payment_billing_id_idx ON payments (billing_id);
+
+/* a payment recorded again with the same provider reference is recorded once */
+CREATE UNIQUE INDEX payment_external_reference_idx ON payments (method, external_reference) WHERE external_reference <> '';
@@ -0,0 +1,17 @@
+/* billings charged through the payment gateway once closed */
+ALTER TABLE billings ADD COLUMN auto_charge BOOLEAN NOT NULL DEFAULT FALSE;
+
+/* Charge attempts table, outcomes of charging closed billings through the payment gateway */
+CREATE TABLE charge_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    billing_id BIGINT NOT NULL REFERENCES billings(id),
+    gateway_charge_id TEXT NOT NULL DEFAULT '',
+    amount_minor BIGINT NOT NULL,
+    currency CURRENCY_CODE NOT NULL,
+    status TEXT NOT NULL,
+    decline_code TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE INDEX charge_attempt_billing_id_idx ON charge_attempts (billing_id);
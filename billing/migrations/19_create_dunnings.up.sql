@@ -0,0 +1,12 @@
+CREATE TYPE DUNNING_STATUS AS ENUM ('active', 'past_due', 'uncollectible', 'resolved');
+
+/* Dunnings table, payment chasing of closed unpaid billings, one per billing */
+CREATE TABLE dunnings (
+    id BIGSERIAL PRIMARY KEY,
+    billing_id BIGINT NOT NULL UNIQUE REFERENCES billings(id),
+    status DUNNING_STATUS NOT NULL DEFAULT 'active',
+    step INT NOT NULL DEFAULT 0,
+    schedule_days INT[] NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
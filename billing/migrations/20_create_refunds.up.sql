@@ -0,0 +1,21 @@
+CREATE TYPE REFUND_STATUS AS ENUM ('pending', 'succeeded', 'failed');
+
+/* Refunds table, money returned from payments, optionally settling a credit note */
+CREATE TABLE refunds (
+    id BIGSERIAL PRIMARY KEY,
+    external_refund_id UUID NOT NULL UNIQUE,
+    payment_id BIGINT NOT NULL REFERENCES payments(id),
+    credit_note_id BIGINT DEFAULT NULL REFERENCES credit_notes(id),
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    reason TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    status REFUND_STATUS NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+/* a refund requested again with the same key is created once */
+CREATE UNIQUE INDEX refund_idempotency_key_idx ON refunds (payment_id, idempotency_key);
+
+CREATE INDEX refund_credit_note_id_idx ON refunds (credit_note_id) WHERE credit_note_id IS NOT NULL;
@@ -0,0 +1,41 @@
+CREATE TYPE BANK_STATEMENT_FORMAT AS ENUM ('camt053', 'csv');
+CREATE TYPE BANK_TRANSACTION_DIRECTION AS ENUM ('credit', 'debit');
+CREATE TYPE BANK_TRANSACTION_STATUS AS ENUM ('matched', 'needs_review', 'ignored');
+
+/* Bank statements table, imported statement files, a file imported again returns the first import */
+CREATE TABLE bank_statements (
+    id BIGSERIAL PRIMARY KEY,
+    external_statement_id UUID NOT NULL UNIQUE,
+    format BANK_STATEMENT_FORMAT NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    account TEXT NOT NULL DEFAULT '',
+    content_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+/* Bank transactions table, booked transactions of statements with the billing and payment they were matched to */
+CREATE TABLE bank_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    external_transaction_id UUID NOT NULL UNIQUE,
+    statement_id BIGINT NOT NULL REFERENCES bank_statements(id),
+    bank_reference TEXT NOT NULL,
+    booking_date DATE NOT NULL,
+    direction BANK_TRANSACTION_DIRECTION NOT NULL,
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    currency CURRENCY_CODE NOT NULL,
+    currency_precision BIGINT NOT NULL,
+    counterparty_name TEXT NOT NULL DEFAULT '',
+    remittance_info TEXT NOT NULL DEFAULT '',
+    status BANK_TRANSACTION_STATUS NOT NULL,
+    match_rule TEXT NOT NULL DEFAULT '',
+    candidate_billing_ids BIGINT[] NOT NULL DEFAULT '{}',
+    billing_id BIGINT DEFAULT NULL REFERENCES billings(id),
+    payment_id BIGINT DEFAULT NULL REFERENCES payments(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    UNIQUE (statement_id, bank_reference)
+);
+
+/* the review queue */
+CREATE INDEX bank_transaction_needs_review_idx ON bank_transactions (id) WHERE status = 'needs_review';
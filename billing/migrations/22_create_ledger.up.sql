@@ -0,0 +1,149 @@
+CREATE TYPE LEDGER_ACCOUNT_TYPE AS ENUM ('asset', 'liability', 'revenue', 'contra_revenue');
+CREATE TYPE LEDGER_DIRECTION AS ENUM ('debit', 'credit');
+CREATE TYPE JOURNAL_ENTRY_KIND AS ENUM ('billing_closed', 'payment_recorded', 'refund_succeeded', 'credit_note_issued');
+
+/* Ledger accounts table, the chart of accounts, balances are kept per currency */
+CREATE TABLE ledger_accounts (
+    code TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    type LEDGER_ACCOUNT_TYPE NOT NULL,
+    normal_balance LEDGER_DIRECTION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+INSERT INTO ledger_accounts (code, name, type, normal_balance) VALUES
+    ('cash', 'Cash', 'asset', 'debit'),
+    ('accounts_receivable', 'Accounts receivable', 'asset', 'debit'),
+    ('tax_payable', 'Tax payable', 'liability', 'credit'),
+    ('revenue', 'Revenue', 'revenue', 'credit'),
+    ('sales_returns', 'Sales returns and allowances', 'contra_revenue', 'debit');
+
+/* Journal entries table, one per billing operation, identified by its kind and source so an operation is posted once */
+CREATE TABLE journal_entries (
+    id BIGSERIAL PRIMARY KEY,
+    external_entry_id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    kind JOURNAL_ENTRY_KIND NOT NULL,
+    source_id BIGINT NOT NULL,
+    billing_id BIGINT NOT NULL REFERENCES billings(id),
+    currency CURRENCY_CODE NOT NULL,
+    description TEXT NOT NULL,
+    posted_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    UNIQUE (kind, source_id)
+);
+
+CREATE INDEX journal_entry_billing_id_idx ON journal_entries (billing_id);
+
+/* Ledger postings table, the debits and credits of journal entries, in the currency of their entry */
+CREATE TABLE ledger_postings (
+    id BIGSERIAL PRIMARY KEY,
+    journal_entry_id BIGINT NOT NULL REFERENCES journal_entries(id),
+    account_code TEXT NOT NULL REFERENCES ledger_accounts(code),
+    direction LEDGER_DIRECTION NOT NULL,
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE INDEX ledger_posting_journal_entry_id_idx ON ledger_postings (journal_entry_id);
+CREATE INDEX ledger_posting_account_code_idx ON ledger_postings (account_code);
+
+/* the ledger is append-only, mistakes are corrected by posting new entries */
+CREATE FUNCTION ledger_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'the ledger is append-only, % on % is not allowed', TG_OP, TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER journal_entries_append_only BEFORE UPDATE OR DELETE ON journal_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+CREATE TRIGGER journal_entries_no_truncate BEFORE TRUNCATE ON journal_entries
+    FOR EACH STATEMENT EXECUTE FUNCTION ledger_append_only();
+CREATE TRIGGER ledger_postings_append_only BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+CREATE TRIGGER ledger_postings_no_truncate BEFORE TRUNCATE ON ledger_postings
+    FOR EACH STATEMENT EXECUTE FUNCTION ledger_append_only();
+
+/* post what happened before the ledger existed, with the same entries the service posts */
+CREATE TEMPORARY TABLE ledger_backfill (
+    kind JOURNAL_ENTRY_KIND NOT NULL,
+    source_id BIGINT NOT NULL,
+    account_code TEXT NOT NULL,
+    direction LEDGER_DIRECTION NOT NULL,
+    amount_minor BIGINT NOT NULL
+);
+
+/* closed billings: the grand total is owed, net of tax it is revenue */
+WITH closed AS (
+    SELECT b.id, COALESCE((s.summary->>'grand_total_amount_minor')::BIGINT, (s.summary->>'total_amount_minor')::BIGINT, 0) AS grand_total_minor,
+        COALESCE((s.summary->>'tax_amount_minor')::BIGINT, 0) AS tax_minor
+    FROM billings b JOIN billing_summaries s ON s.external_billing_id = b.external_billing_id
+    WHERE b.status = 'closed'
+)
+INSERT INTO ledger_backfill (kind, source_id, account_code, direction, amount_minor)
+SELECT 'billing_closed', c.id, p.account_code, p.direction::LEDGER_DIRECTION, p.amount_minor
+FROM closed c
+CROSS JOIN LATERAL (VALUES
+    ('accounts_receivable', 'debit', c.grand_total_minor),
+    ('revenue', 'credit', c.grand_total_minor - c.tax_minor),
+    ('tax_payable', 'credit', c.tax_minor)
+) AS p(account_code, direction, amount_minor);
+
+/* payments are received against what is owed */
+INSERT INTO ledger_backfill (kind, source_id, account_code, direction, amount_minor)
+SELECT 'payment_recorded', p.id, v.account_code, v.direction::LEDGER_DIRECTION, p.amount_minor
+FROM payments p
+CROSS JOIN (VALUES ('cash', 'debit'), ('accounts_receivable', 'credit')) AS v(account_code, direction);
+
+/* succeeded refunds are owed again until a credit note settles them */
+INSERT INTO ledger_backfill (kind, source_id, account_code, direction, amount_minor)
+SELECT 'refund_succeeded', r.id, v.account_code, v.direction::LEDGER_DIRECTION, r.amount_minor
+FROM refunds r
+CROSS JOIN (VALUES ('accounts_receivable', 'debit'), ('cash', 'credit')) AS v(account_code, direction)
+WHERE r.status = 'succeeded';
+
+/* credit notes reverse their share of the tax of the billing, rounded half away from zero */
+WITH credited AS (
+    SELECT c.id, c.total_amount_minor,
+        CASE WHEN COALESCE((s.summary->>'grand_total_amount_minor')::BIGINT, 0) > 0
+            THEN ROUND(c.total_amount_minor::NUMERIC * COALESCE((s.summary->>'tax_amount_minor')::BIGINT, 0) / (s.summary->>'grand_total_amount_minor')::BIGINT)::BIGINT
+            ELSE 0
+        END AS tax_minor
+    FROM credit_notes c
+    JOIN billings b ON b.id = c.billing_id
+    LEFT JOIN billing_summaries s ON s.external_billing_id = b.external_billing_id
+)
+INSERT INTO ledger_backfill (kind, source_id, account_code, direction, amount_minor)
+SELECT 'credit_note_issued', c.id, p.account_code, p.direction::LEDGER_DIRECTION, p.amount_minor
+FROM credited c
+CROSS JOIN LATERAL (VALUES
+    ('sales_returns', 'debit', c.total_amount_minor - c.tax_minor),
+    ('tax_payable', 'debit', c.tax_minor),
+    ('accounts_receivable', 'credit', c.total_amount_minor)
+) AS p(account_code, direction, amount_minor);
+
+/* a negative amount is posted in the other direction and a zero amount is not posted */
+DELETE FROM ledger_backfill WHERE amount_minor = 0;
+UPDATE ledger_backfill SET amount_minor = -amount_minor,
+    direction = CASE WHEN direction = 'debit' THEN 'credit'::LEDGER_DIRECTION ELSE 'debit'::LEDGER_DIRECTION END
+WHERE amount_minor < 0;
+
+INSERT INTO journal_entries (kind, source_id, billing_id, currency, description, posted_at)
+SELECT 'billing_closed', b.id, b.id, b.currency,
+    COALESCE('invoice ' || b.invoice_number || ' issued', 'billing ' || b.external_billing_id || ' closed'),
+    COALESCE(b.actual_closed_at, b.updated_at)
+FROM billings b WHERE b.id IN (SELECT source_id FROM ledger_backfill WHERE kind = 'billing_closed')
+UNION ALL
+SELECT 'payment_recorded', p.id, p.billing_id, p.currency, 'payment ' || p.external_payment_id || ' by ' || p.method, p.paid_at
+FROM payments p WHERE p.id IN (SELECT source_id FROM ledger_backfill WHERE kind = 'payment_recorded')
+UNION ALL
+SELECT 'refund_succeeded', r.id, p.billing_id, p.currency, 'refund ' || r.external_refund_id || ' of payment ' || p.external_payment_id, r.updated_at
+FROM refunds r JOIN payments p ON p.id = r.payment_id WHERE r.id IN (SELECT source_id FROM ledger_backfill WHERE kind = 'refund_succeeded')
+UNION ALL
+SELECT 'credit_note_issued', c.id, c.billing_id, b.currency, 'credit note ' || c.number || ' issued', c.created_at
+FROM credit_notes c JOIN billings b ON b.id = c.billing_id WHERE c.id IN (SELECT source_id FROM ledger_backfill WHERE kind = 'credit_note_issued');
+
+INSERT INTO ledger_postings (journal_entry_id, account_code, direction, amount_minor)
+SELECT e.id, l.account_code, l.direction, l.amount_minor
+FROM ledger_backfill l JOIN journal_entries e ON e.kind = l.kind AND e.source_id = l.source_id;
+
+DROP TABLE ledger_backfill;
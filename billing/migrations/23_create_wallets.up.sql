@@ -0,0 +1,46 @@
+ALTER TYPE PAYMENT_METHOD ADD VALUE 'wallet';
+ALTER TYPE JOURNAL_ENTRY_KIND ADD VALUE 'wallet_topped_up';
+ALTER TYPE JOURNAL_ENTRY_KIND ADD VALUE 'wallet_adjusted';
+
+CREATE TYPE WALLET_TRANSACTION_KIND AS ENUM ('top_up', 'adjustment', 'billing_draw');
+
+/* wallet entries are not about a billing */
+ALTER TABLE journal_entries ALTER COLUMN billing_id DROP NOT NULL;
+
+/* credit held in wallets is owed to users until it pays their billings */
+INSERT INTO ledger_accounts (code, name, type, normal_balance) VALUES
+    ('customer_credit', 'Customer credit', 'liability', 'credit');
+
+/* Wallets table, credit a user holds in a currency, drawn by their billings when they close */
+CREATE TABLE wallets (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    currency CURRENCY_CODE NOT NULL,
+    currency_precision SMALLINT NOT NULL,
+    balance_minor BIGINT NOT NULL DEFAULT 0 CHECK (balance_minor >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    UNIQUE (user_id, currency)
+);
+
+/* Wallet transactions table, every change of a wallet balance with the balance it left */
+CREATE TABLE wallet_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    external_transaction_id UUID NOT NULL UNIQUE,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    kind WALLET_TRANSACTION_KIND NOT NULL,
+    amount_minor BIGINT NOT NULL CHECK (amount_minor <> 0),
+    balance_after_minor BIGINT NOT NULL CHECK (balance_after_minor >= 0),
+    reason TEXT NOT NULL DEFAULT '',
+    external_reference TEXT NOT NULL DEFAULT '',
+    billing_id BIGINT DEFAULT NULL REFERENCES billings(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE INDEX wallet_transaction_wallet_id_idx ON wallet_transactions (wallet_id);
+
+/* a top-up recorded again with the same reference is recorded once */
+CREATE UNIQUE INDEX wallet_transaction_external_reference_idx ON wallet_transactions (wallet_id, external_reference) WHERE external_reference <> '';
+
+/* a billing draws from a wallet once */
+CREATE UNIQUE INDEX wallet_transaction_billing_draw_idx ON wallet_transactions (billing_id) WHERE kind = 'billing_draw';
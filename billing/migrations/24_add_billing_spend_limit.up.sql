@@ -0,0 +1,2 @@
+/* spend limit of a billing with the percentages of it that are alerted, NULL for billings without a limit */
+ALTER TABLE billings ADD COLUMN spend_limit JSONB DEFAULT NULL;
@@ -0,0 +1,32 @@
+CREATE TYPE METER_AGGREGATION AS ENUM ('sum', 'max', 'last');
+
+/* Meters table, kinds of usage billed per unit when the billings of their currency close */
+CREATE TABLE meters (
+    id BIGSERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    aggregation METER_AGGREGATION NOT NULL,
+    currency CURRENCY_CODE NOT NULL,
+    currency_precision SMALLINT NOT NULL,
+    unit_amount_minor BIGINT NOT NULL CHECK (unit_amount_minor >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+/* Usage events table, raw usage reported by users, claimed by the billing whose period contains it when it closes */
+CREATE TABLE usage_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    meter_id BIGINT NOT NULL REFERENCES meters(id),
+    quantity BIGINT NOT NULL CHECK (quantity >= 0),
+    occurred_at TIMESTAMPTZ NOT NULL,
+    dedup_id TEXT NOT NULL,
+    billing_id BIGINT DEFAULT NULL REFERENCES billings(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    UNIQUE (user_id, dedup_id)
+);
+
+/* unbilled usage is looked up by user and time when a billing closes */
+CREATE INDEX usage_event_unbilled_idx ON usage_events (user_id, occurred_at) WHERE billing_id IS NULL;
+
+CREATE INDEX usage_event_billing_id_idx ON usage_events (billing_id);
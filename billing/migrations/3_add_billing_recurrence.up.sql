@@ -0,0 +1,6 @@
+ALTER TABLE billings
+    ADD COLUMN recurrence JSONB DEFAULT NULL,
+    ADD COLUMN previous_billing_id UUID DEFAULT NULL,
+    ADD COLUMN next_billing_id UUID DEFAULT NULL;
+
+CREATE INDEX billing_previous_billing_id_idx ON billings (previous_billing_id);
@@ -0,0 +1,4 @@
+ALTER TABLE billings
+    ADD COLUMN period_start TIMESTAMPTZ DEFAULT NULL,
+    ADD COLUMN period_end TIMESTAMPTZ DEFAULT NULL,
+    ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,39 @@
+CREATE TYPE PLAN_INTERVAL AS ENUM ('month', 'week', 'day');
+CREATE TYPE SUBSCRIPTION_STATUS AS ENUM ('trial', 'active', 'past_due', 'cancelled');
+
+/* Plans table */
+CREATE TABLE plans (
+    id BIGSERIAL PRIMARY KEY,
+    external_plan_id UUID NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    currency CURRENCY_CODE NOT NULL,
+    currency_precision SMALLINT NOT NULL,
+    amount_minor BIGINT NOT NULL,
+    interval PLAN_INTERVAL NOT NULL,
+    interval_count INTEGER NOT NULL DEFAULT 1,
+    trial_days INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+/* Subscriptions table */
+CREATE TABLE subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    external_subscription_id UUID NOT NULL UNIQUE,
+    user_id TEXT NOT NULL,
+    plan_id BIGINT NOT NULL REFERENCES plans(id),
+    status SUBSCRIPTION_STATUS NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    anchor_at TIMESTAMPTZ NOT NULL,
+    trial_ends_at TIMESTAMPTZ DEFAULT NULL,
+    current_billing_id UUID DEFAULT NULL,
+    cancelled_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE INDEX subscription_user_id_with_status_idx ON subscriptions (user_id, status);
+
+ALTER TABLE billings ADD COLUMN subscription_id UUID DEFAULT NULL;
+
+CREATE INDEX billing_subscription_id_idx ON billings (subscription_id);
@@ -0,0 +1,16 @@
+/* Tax rates table, rate_ppm is the rate in parts per million (20% = 200000) */
+CREATE TABLE tax_rates (
+    id BIGSERIAL PRIMARY KEY,
+    jurisdiction TEXT NOT NULL,
+    tax_code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    rate_ppm INTEGER NOT NULL CHECK (rate_ppm >= 0 AND rate_ppm <= 1000000),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    UNIQUE (jurisdiction, tax_code)
+);
+
+ALTER TABLE billings ADD COLUMN tax_jurisdiction TEXT NOT NULL DEFAULT '';
+ALTER TABLE billings ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE line_items ADD COLUMN tax_code TEXT NOT NULL DEFAULT '';
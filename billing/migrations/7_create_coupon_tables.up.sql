@@ -0,0 +1,26 @@
+CREATE TYPE COUPON_TYPE AS ENUM ('percentage', 'fixed_amount');
+
+/* Coupons table */
+CREATE TABLE coupons (
+    id BIGSERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    type COUPON_TYPE NOT NULL,
+    percent_off_bps INTEGER NOT NULL DEFAULT 0,
+    amount_off_minor BIGINT NOT NULL DEFAULT 0,
+    currency CURRENCY_CODE DEFAULT NULL,
+    currency_precision SMALLINT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ DEFAULT NULL,
+    max_redemptions INTEGER DEFAULT NULL,
+    times_redeemed INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+/* Coupon redemptions table, a coupon is redeemed at most once per billing */
+CREATE TABLE coupon_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    coupon_id BIGINT NOT NULL REFERENCES coupons(id),
+    billing_id BIGINT NOT NULL REFERENCES billings(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    UNIQUE (coupon_id, billing_id)
+);
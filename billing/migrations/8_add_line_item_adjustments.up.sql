@@ -0,0 +1,8 @@
+CREATE TYPE LINE_ITEM_KIND AS ENUM ('charge', 'adjustment');
+
+ALTER TABLE line_items ADD COLUMN external_line_item_id UUID DEFAULT NULL UNIQUE;
+ALTER TABLE line_items ADD COLUMN kind LINE_ITEM_KIND NOT NULL DEFAULT 'charge';
+ALTER TABLE line_items ADD COLUMN reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE line_items ADD COLUMN reference_line_item_id UUID DEFAULT NULL;
+
+ALTER TABLE billings ADD COLUMN allow_negative_total BOOLEAN NOT NULL DEFAULT FALSE;
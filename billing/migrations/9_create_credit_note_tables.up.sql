@@ -0,0 +1,26 @@
+/* Credit note numbers, shared by all billings */
+CREATE SEQUENCE credit_note_number_seq;
+
+/* Credit notes table, each credit note refunds part of a closed billing */
+CREATE TABLE credit_notes (
+    id BIGSERIAL PRIMARY KEY,
+    external_credit_note_id UUID NOT NULL UNIQUE,
+    number TEXT NOT NULL UNIQUE,
+    billing_id BIGINT NOT NULL REFERENCES billings(id),
+    reason TEXT NOT NULL,
+    total_amount_minor BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now()),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE INDEX credit_note_billing_id_idx ON credit_notes (billing_id);
+
+/* Credit note line items table */
+CREATE TABLE credit_note_line_items (
+    id BIGSERIAL PRIMARY KEY,
+    credit_note_id BIGINT NOT NULL REFERENCES credit_notes(id) ON DELETE CASCADE,
+    description TEXT NOT NULL,
+    amount_minor BIGINT NOT NULL,
+    reference_line_item_id UUID DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT timezone('utc', now())
+);
@@ -0,0 +1,57 @@
+package billing
+
+import (
+	"context"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+)
+
+// defaultOutboxReplayLimit is how many messages a replay publishes when no limit is given
+const defaultOutboxReplayLimit = 100
+
+// maxOutboxReplayLimit is the largest replay, longer replays are paged with next_offset
+const maxOutboxReplayLimit = 1000
+
+// encore:api private method=POST path=/outbox/replay
+func (s *Service) ReplayOutbox(ctx context.Context, req *ReplayOutboxRequest) (*ReplayOutboxResponse, error) {
+	fn := "billing.Service.ReplayOutbox"
+	logger := rlog.With("fn", fn).With("fromOffset", req.FromOffset).With("limit", req.Limit)
+
+	// validate offset
+	if req.FromOffset < 0 {
+		logger.Warn("offset is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "from_offset must not be negative",
+		}
+	}
+
+	// validate limit
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultOutboxReplayLimit
+	}
+	if limit < 0 || limit > maxOutboxReplayLimit {
+		logger.Warn("limit is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "limit must be between 1 and 1000",
+		}
+	}
+
+	replayed, nextOffset, err := s.replayOutboxUsecase.Execute(ctx, req.FromOffset, limit)
+	if err != nil {
+		// unknown error
+		logger.Error("failed to replay outbox", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to replay outbox",
+		}
+	}
+
+	return &ReplayOutboxResponse{
+		Replayed:   replayed,
+		NextOffset: nextOffset,
+	}, nil
+}
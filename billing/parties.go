@@ -0,0 +1,81 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=POST path=/sellers
+func (s *Service) SetSeller(ctx context.Context, req *SetSellerRequest) (*Party, error) {
+	fn := "billing.Service.SetSeller"
+	logger := rlog.With("fn", fn).With("tenant", req.Tenant)
+
+	return s.setParty(ctx, logger, entities.PartyRoleSeller, req.Tenant, req.Party)
+}
+
+// encore:api private method=POST path=/buyers
+func (s *Service) SetBuyer(ctx context.Context, req *SetBuyerRequest) (*Party, error) {
+	fn := "billing.Service.SetBuyer"
+	logger := rlog.With("fn", fn).With("userID", req.UserID)
+
+	// validate user ID
+	if req.UserID == "" {
+		logger.Warn("user ID is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "user ID is required",
+		}
+	}
+
+	return s.setParty(ctx, logger, entities.PartyRoleBuyer, req.UserID, req.Party)
+}
+
+func (s *Service) setParty(ctx context.Context, logger rlog.Ctx, role entities.PartyRole, key string, req Party) (*Party, error) {
+	party, err := s.setPartyUsecase.Execute(ctx, entities.Party{
+		Role:           role,
+		Key:            key,
+		Name:           req.Name,
+		VATID:          req.VATID,
+		Street:         req.Street,
+		City:           req.City,
+		PostalCode:     req.PostalCode,
+		CountryCode:    req.CountryCode,
+		EndpointID:     req.EndpointID,
+		EndpointScheme: req.EndpointScheme,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidParty) {
+			logger.Warn("party is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "name, country code, endpoint ID and a 4 digit endpoint scheme are required, and a VAT ID for sellers",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to set party", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to set " + role,
+		}
+	}
+
+	logger.Info("Party set successfully", "role", role)
+
+	return &Party{
+		Name:           party.Name,
+		VATID:          party.VATID,
+		Street:         party.Street,
+		City:           party.City,
+		PostalCode:     party.PostalCode,
+		CountryCode:    party.CountryCode,
+		EndpointID:     party.EndpointID,
+		EndpointScheme: party.EndpointScheme,
+	}, nil
+}
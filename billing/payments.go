@@ -0,0 +1,167 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=POST path=/billing/:billingID/payments
+func (s *Service) RecordPayment(ctx context.Context, billingID string, req *RecordPaymentRequest) (*RecordPaymentResponse, error) {
+	fn := "billing.Service.RecordPayment"
+	logger := rlog.With("fn", fn).With("billingID", billingID).With("amount", req.Amount).With("currency", req.Currency).With("method", req.Method).With("externalReference", req.ExternalReference)
+
+	// validate amount
+	if req.Amount <= 0 {
+		logger.Warn("amount must be greater than 0")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "amount must be greater than 0",
+		}
+	}
+
+	// validate currency
+	if req.Currency == "" {
+		logger.Warn("currency is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "currency is required",
+		}
+	}
+
+	payment, balance, err := s.recordPaymentUsecase.Execute(ctx, billingID, dto.RecordPaymentInput{
+		Amount:            req.Amount,
+		Currency:          req.Currency,
+		Method:            req.Method,
+		ExternalReference: req.ExternalReference,
+		PaidAt:            req.PaidAt,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingNotClosed) {
+			logger.Warn("billing is not closed")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing is not closed",
+			}
+		}
+		if errors.Is(err, dto.ErrPaymentCurrencyMismatch) {
+			logger.Warn("payment currency does not match the billing currency")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "payment currency does not match the billing currency",
+			}
+		}
+		if errors.Is(err, dto.ErrAmountHasTooManyDecimals) {
+			logger.Warn("amount has too many decimals")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "amount has too many decimals",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidPayment) {
+			logger.Warn("payment is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "payment is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrPaymentReferenceTaken) {
+			logger.Warn("payment reference is recorded for another billing")
+			return nil, &errs.Error{
+				Code:    errs.AlreadyExists,
+				Message: "payment reference is recorded for another billing",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to record payment", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to record payment",
+		}
+	}
+
+	logger.Info("Payment recorded successfully", "paymentID", payment.ExternalPaymentID, "status", balance.Status)
+
+	return &RecordPaymentResponse{
+		Payment: paymentResponse(payment),
+		Balance: paymentBalanceResponse(balance),
+	}, nil
+}
+
+// encore:api private method=GET path=/billing/:billingID/payments
+func (s *Service) ListPayments(ctx context.Context, billingID string) (*ListPaymentsResponse, error) {
+	fn := "billing.Service.ListPayments"
+	logger := rlog.With("fn", fn).With("billingID", billingID)
+
+	payments, balance, err := s.listPaymentsUsecase.Execute(ctx, billingID)
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "billing not found",
+			}
+		}
+		if errors.Is(err, dto.ErrBillingNotClosed) {
+			logger.Warn("billing is not closed")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "billing is not closed",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to list payments", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list payments",
+		}
+	}
+
+	response := make([]Payment, len(payments))
+	for i := range payments {
+		response[i] = paymentResponse(&payments[i])
+	}
+
+	return &ListPaymentsResponse{
+		Payments: response,
+		Balance:  paymentBalanceResponse(balance),
+	}, nil
+}
+
+func paymentResponse(payment *entities.Payment) Payment {
+	return Payment{
+		PaymentID:           payment.ExternalPaymentID,
+		BillingID:           payment.ExternalBillingID,
+		AmountMinor:         payment.AmountMinor,
+		RefundedAmountMinor: payment.RefundedAmountMinor,
+		Currency:            payment.Currency,
+		CurrencyPrecision:   payment.CurrencyPrecision,
+		Method:              payment.Method,
+		ExternalReference:   payment.ExternalReference,
+		PaidAt:              payment.PaidAt,
+		CreatedAt:           payment.CreatedAt,
+	}
+}
+
+func paymentBalanceResponse(balance entities.PaymentBalance) PaymentBalance {
+	return PaymentBalance{
+		DueAmountMinor:         balance.DueAmountMinor,
+		PaidAmountMinor:        balance.PaidAmountMinor,
+		OutstandingAmountMinor: balance.OutstandingAmountMinor,
+		Status:                 balance.Status,
+	}
+}
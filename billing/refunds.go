@@ -0,0 +1,174 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=POST path=/payments/:paymentID/refunds
+func (s *Service) RefundPayment(ctx context.Context, paymentID string, req *RefundPaymentRequest) (*Refund, error) {
+	fn := "billing.Service.RefundPayment"
+	logger := rlog.With("fn", fn).With("paymentID", paymentID).With("amount", req.Amount).With("idempotencyKey", req.IdempotencyKey).With("creditNoteID", req.CreditNoteID)
+
+	// validate amount
+	if req.Amount <= 0 {
+		logger.Warn("amount must be greater than 0")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "amount must be greater than 0",
+		}
+	}
+
+	// validate reason
+	if req.Reason == "" {
+		logger.Warn("reason is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "reason is required",
+		}
+	}
+
+	// validate idempotency key
+	if req.IdempotencyKey == "" {
+		logger.Warn("idempotency key is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "idempotency_key is required",
+		}
+	}
+
+	refund, err := s.refundPaymentUsecase.Execute(ctx, paymentID, dto.RefundPaymentInput{
+		Amount:         req.Amount,
+		Reason:         req.Reason,
+		IdempotencyKey: req.IdempotencyKey,
+		CreditNoteID:   req.CreditNoteID,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrPaymentNotFound) {
+			logger.Warn("payment not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "payment not found",
+			}
+		}
+		if errors.Is(err, dto.ErrCreditNoteNotFound) {
+			logger.Warn("credit note not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "credit note not found for the billing of the payment",
+			}
+		}
+		if errors.Is(err, dto.ErrAmountHasTooManyDecimals) {
+			logger.Warn("amount has too many decimals")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "amount has too many decimals",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidRefund) {
+			logger.Warn("refund is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "refund is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrWalletPaymentNotRefundable) {
+			logger.Warn("payment from the wallet cannot be refunded")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "payment from the wallet cannot be refunded, adjust the wallet instead",
+			}
+		}
+		if errors.Is(err, dto.ErrRefundExceedsPayment) {
+			logger.Warn("refund exceeds the refundable amount of the payment")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "refund exceeds the refundable amount of the payment",
+			}
+		}
+		if errors.Is(err, dto.ErrRefundExceedsCreditNote) {
+			logger.Warn("refund exceeds the unsettled amount of the credit note")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "refund exceeds the unsettled amount of the credit note",
+			}
+		}
+		if errors.Is(err, dto.ErrRefundKeyReused) {
+			logger.Warn("idempotency key was used for a different refund")
+			return nil, &errs.Error{
+				Code:    errs.AlreadyExists,
+				Message: "idempotency key was used for a different refund",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to refund payment", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to refund payment",
+		}
+	}
+
+	logger.Info("Refund requested successfully", "refundID", refund.ExternalRefundID, "status", refund.Status)
+
+	response := refundResponse(refund)
+	return &response, nil
+}
+
+// encore:api private method=GET path=/payments/:paymentID/refunds
+func (s *Service) ListRefunds(ctx context.Context, paymentID string) (*ListRefundsResponse, error) {
+	fn := "billing.Service.ListRefunds"
+	logger := rlog.With("fn", fn).With("paymentID", paymentID)
+
+	refunds, err := s.listRefundsUsecase.Execute(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, dto.ErrPaymentNotFound) {
+			logger.Warn("payment not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "payment not found",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to list refunds", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list refunds",
+		}
+	}
+
+	response := make([]Refund, len(refunds))
+	for i := range refunds {
+		response[i] = refundResponse(&refunds[i])
+	}
+
+	return &ListRefundsResponse{Refunds: response}, nil
+}
+
+func refundResponse(refund *entities.Refund) Refund {
+	response := Refund{
+		RefundID:          refund.ExternalRefundID,
+		PaymentID:         refund.ExternalPaymentID,
+		BillingID:         refund.ExternalBillingID,
+		AmountMinor:       refund.AmountMinor,
+		Currency:          refund.Currency,
+		CurrencyPrecision: refund.CurrencyPrecision,
+		Reason:            refund.Reason,
+		IdempotencyKey:    refund.IdempotencyKey,
+		Status:            refund.Status,
+		Error:             refund.Error,
+		CreatedAt:         refund.CreatedAt,
+		UpdatedAt:         refund.UpdatedAt,
+	}
+	if refund.ExternalCreditNoteID != nil {
+		response.CreditNoteID = *refund.ExternalCreditNoteID
+	}
+	return response
+}
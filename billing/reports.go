@@ -0,0 +1,99 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=GET path=/billing/reports/aging
+func (s *Service) GetAgingReport(ctx context.Context, req *GetAgingReportRequest) (*AgingReport, error) {
+	fn := "billing.Service.GetAgingReport"
+	logger := rlog.With("fn", fn).With("asOf", req.AsOf).With("paymentTermsDays", req.PaymentTermsDays).With("reportingCurrency", req.ReportingCurrency)
+
+	input := dto.GetAgingReportInput{
+		PaymentTermsDays:  req.PaymentTermsDays,
+		ReportingCurrency: req.ReportingCurrency,
+	}
+
+	// validate as of
+	if req.AsOf != "" {
+		asOf, err := time.Parse(time.DateOnly, req.AsOf)
+		if err != nil {
+			logger.Warn("as of is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "as_of must be a date (2006-01-02)",
+			}
+		}
+		input.AsOf = &asOf
+	}
+
+	report, err := s.getAgingReportUsecase.Execute(ctx, input)
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidAgingReport) {
+			logger.Warn("aging report is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "as_of cannot be in the future and payment_terms_days must be between 0 and 365",
+			}
+		}
+
+		if errors.Is(err, dto.ErrCurrencyNotSupported) {
+			logger.Warn("currency not supported")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "currency not supported",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to get aging report", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to get aging report",
+		}
+	}
+
+	response := AgingReport{
+		AsOf:                       report.AsOf.Format(time.DateOnly),
+		PaymentTermsDays:           report.PaymentTermsDays,
+		Rows:                       make([]AgingRow, len(report.Rows)),
+		ReportingCurrency:          report.ReportingCurrency,
+		ReportingCurrencyPrecision: report.ReportingCurrencyPrecision,
+		ReportingTotals:            agingAmountsResponse(report.ReportingTotals),
+	}
+	for i, row := range report.Rows {
+		response.Rows[i] = AgingRow{
+			UserID:            row.UserID,
+			Currency:          row.Currency,
+			CurrencyPrecision: row.CurrencyPrecision,
+			BillingCount:      row.BillingCount,
+			Amounts:           *agingAmountsResponse(&row.Amounts),
+			ReportingAmounts:  agingAmountsResponse(row.ReportingAmounts),
+		}
+	}
+
+	return &response, nil
+}
+
+func agingAmountsResponse(amounts *entities.AgingAmounts) *AgingAmounts {
+	if amounts == nil {
+		return nil
+	}
+
+	return &AgingAmounts{
+		CurrentAmountMinor:    amounts.CurrentAmountMinor,
+		Days1To30AmountMinor:  amounts.Days1To30AmountMinor,
+		Days31To60AmountMinor: amounts.Days31To60AmountMinor,
+		Days61To90AmountMinor: amounts.Days61To90AmountMinor,
+		Over90DaysAmountMinor: amounts.Over90DaysAmountMinor,
+		TotalAmountMinor:      amounts.TotalAmountMinor,
+	}
+}
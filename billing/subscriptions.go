@@ -0,0 +1,270 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=POST path=/plans
+func (s *Service) CreatePlan(ctx context.Context, req *CreatePlanRequest) (*CreatePlanResponse, error) {
+	fn := "billing.Service.CreatePlan"
+	logger := rlog.With("fn", fn).With("name", req.Name)
+
+	// validate name
+	if req.Name == "" {
+		logger.Warn("name is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "name is required",
+		}
+	}
+
+	// validate amount
+	if req.Amount < 0 {
+		logger.Warn("amount must not be negative")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "amount must not be negative",
+		}
+	}
+
+	planID, err := s.createPlanUsecase.Execute(ctx, dto.CreatePlanInput{
+		Name:          req.Name,
+		Currency:      req.Currency,
+		Amount:        req.Amount,
+		Interval:      req.Interval,
+		IntervalCount: req.IntervalCount,
+		TrialDays:     req.TrialDays,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrCurrencyNotSupported) {
+			logger.Warn("currency not supported")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "currency not supported",
+			}
+		}
+		if errors.Is(err, dto.ErrAmountHasTooManyDecimals) {
+			logger.Warn("amount has too many decimals")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "amount has many decimals",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidPlan) {
+			logger.Warn("plan is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "plan is invalid",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to create plan", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to create plan",
+		}
+	}
+
+	logger.Info("Plan created successfully", "planID", planID)
+
+	return &CreatePlanResponse{
+		PlanID: planID,
+	}, nil
+}
+
+// encore:api private method=POST path=/subscriptions
+func (s *Service) CreateSubscription(ctx context.Context, req *CreateSubscriptionRequest) (*CreateSubscriptionResponse, error) {
+	fn := "billing.Service.CreateSubscription"
+	logger := rlog.With("fn", fn).With("UserID", req.UserID).With("planID", req.PlanID)
+
+	// validation user id
+	if req.UserID == "" {
+		logger.Warn("user ID is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "user ID is required",
+		}
+	}
+
+	// validation plan id
+	if req.PlanID == "" {
+		logger.Warn("plan ID is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "plan ID is required",
+		}
+	}
+
+	subscriptionID, err := s.createSubscriptionUsecase.Execute(ctx, req.UserID, req.PlanID, req.Timezone)
+	if err != nil {
+		if errors.Is(err, dto.ErrPlanNotFound) {
+			logger.Warn("plan not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "plan not found",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidTimezone) {
+			logger.Warn("timezone is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "timezone is invalid",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to create subscription", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to create subscription",
+		}
+	}
+
+	logger.Info("Subscription created successfully", "subscriptionID", subscriptionID)
+
+	return &CreateSubscriptionResponse{
+		SubscriptionID: subscriptionID,
+	}, nil
+}
+
+// encore:api private method=GET path=/subscriptions/:subscriptionID
+func (s *Service) GetSubscription(ctx context.Context, subscriptionID string) (*GetSubscriptionResponse, error) {
+	fn := "billing.Service.GetSubscription"
+	logger := rlog.With("fn", fn).With("subscriptionID", subscriptionID)
+
+	subscription, err := s.getSubscriptionUsecase.Execute(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, dto.ErrSubscriptionNotFound) {
+			logger.Warn("subscription not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "subscription not found",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to get subscription", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to get subscription",
+		}
+	}
+
+	return &GetSubscriptionResponse{
+		SubscriptionID:   subscription.ExternalSubscriptionID,
+		UserID:           subscription.UserID,
+		PlanID:           subscription.ExternalPlanID,
+		Status:           subscription.Status,
+		Timezone:         subscription.Timezone,
+		TrialEndsAt:      subscription.TrialEndsAt,
+		CurrentBillingID: subscription.CurrentBillingID,
+		CancelledAt:      subscription.CancelledAt,
+	}, nil
+}
+
+// encore:api private method=POST path=/subscriptions/:subscriptionID/change-plan
+func (s *Service) ChangeSubscriptionPlan(ctx context.Context, subscriptionID string, req *ChangeSubscriptionPlanRequest) error {
+	fn := "billing.Service.ChangeSubscriptionPlan"
+	logger := rlog.With("fn", fn).With("subscriptionID", subscriptionID).With("planID", req.PlanID)
+
+	// validation plan id
+	if req.PlanID == "" {
+		logger.Warn("plan ID is invalid")
+		return &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "plan ID is required",
+		}
+	}
+
+	err := s.changeSubscriptionPlanUsecase.Execute(ctx, subscriptionID, req.PlanID)
+	if err != nil {
+		if errors.Is(err, dto.ErrSubscriptionNotFound) {
+			logger.Warn("subscription not found")
+			return &errs.Error{
+				Code:    errs.NotFound,
+				Message: "subscription not found",
+			}
+		}
+		if errors.Is(err, dto.ErrPlanNotFound) {
+			logger.Warn("plan not found")
+			return &errs.Error{
+				Code:    errs.NotFound,
+				Message: "plan not found",
+			}
+		}
+		if errors.Is(err, dto.ErrSubscriptionCancelled) {
+			logger.Warn("subscription is cancelled")
+			return &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "subscription is cancelled",
+			}
+		}
+		if errors.Is(err, dto.ErrPlanCurrencyMismatch) {
+			logger.Warn("plan currency does not match")
+			return &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "plan currency does not match subscription currency",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to change subscription plan", "error", err)
+		return &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to change subscription plan",
+		}
+	}
+
+	logger.Info("Subscription plan changed successfully")
+
+	return nil
+}
+
+// encore:api private method=POST path=/subscriptions/:subscriptionID/cancel
+func (s *Service) CancelSubscription(ctx context.Context, subscriptionID string) error {
+	fn := "billing.Service.CancelSubscription"
+	logger := rlog.With("fn", fn).With("subscriptionID", subscriptionID)
+
+	err := s.cancelSubscriptionUsecase.Execute(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, dto.ErrSubscriptionNotFound) {
+			logger.Warn("subscription not found")
+			return &errs.Error{
+				Code:    errs.NotFound,
+				Message: "subscription not found",
+			}
+		}
+		if errors.Is(err, dto.ErrSubscriptionCancelled) {
+			logger.Warn("subscription is already cancelled")
+			return &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "subscription is already cancelled",
+			}
+		}
+		if errors.Is(err, dto.ErrSubscriptionStatusConflict) {
+			logger.Warn("subscription status changed while cancelling")
+			return &errs.Error{
+				Code:    errs.Aborted,
+				Message: "subscription status changed, retry the cancellation",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to cancel subscription", "error", err)
+		return &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to cancel subscription",
+		}
+	}
+
+	logger.Info("Subscription cancelled successfully")
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=POST path=/tax-rates
+func (s *Service) SetTaxRate(ctx context.Context, req *SetTaxRateRequest) error {
+	fn := "billing.Service.SetTaxRate"
+	logger := rlog.With("fn", fn).With("jurisdiction", req.Jurisdiction).With("taxCode", req.TaxCode).With("rate", req.Rate)
+
+	// validate jurisdiction and tax code
+	if req.Jurisdiction == "" || req.TaxCode == "" {
+		logger.Warn("jurisdiction or tax code is invalid")
+		return &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "jurisdiction and tax code are required",
+		}
+	}
+
+	err := s.setTaxRateUsecase.Execute(ctx, entities.TaxRate{
+		Jurisdiction: req.Jurisdiction,
+		TaxCode:      req.TaxCode,
+		Name:         req.Name,
+		RatePPM:      int64(math.Round(req.Rate * entities.TaxRatePPMScale / 100)),
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidTaxRate) {
+			logger.Warn("tax rate is invalid")
+			return &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "tax rate is invalid",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to set tax rate", "error", err)
+		return &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to set tax rate",
+		}
+	}
+
+	logger.Info("Tax rate set successfully")
+
+	return nil
+}
+
+// encore:api private method=GET path=/tax-rates/:jurisdiction
+func (s *Service) ListTaxRates(ctx context.Context, jurisdiction string) (*ListTaxRatesResponse, error) {
+	fn := "billing.Service.ListTaxRates"
+	logger := rlog.With("fn", fn).With("jurisdiction", jurisdiction)
+
+	taxRates, err := s.listTaxRatesUsecase.Execute(ctx, jurisdiction)
+	if err != nil {
+		// unknown error
+		logger.Error("failed to list tax rates", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list tax rates",
+		}
+	}
+
+	response := make([]TaxRate, len(taxRates))
+	for i, taxRate := range taxRates {
+		response[i] = TaxRate{
+			Jurisdiction: taxRate.Jurisdiction,
+			TaxCode:      taxRate.TaxCode,
+			Name:         taxRate.Name,
+			Rate:         taxRatePercent(taxRate.RatePPM),
+		}
+	}
+
+	return &ListTaxRatesResponse{
+		TaxRates: response,
+	}, nil
+}
+
+// taxRatePercent converts a rate in parts per million to percent
+func taxRatePercent(ratePPM int64) float64 {
+	return float64(ratePPM) * 100 / entities.TaxRatePPMScale
+}
@@ -1,6 +1,9 @@
 package billing
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type CreateBillingRequest struct {
 	UserID      string `json:"user_id"`
@@ -9,6 +12,48 @@ type CreateBillingRequest struct {
 
 	// PlannedClosedAt is designed for periodic billing (e.g. weekly, monthly, etc). If not provided, the billing will not be closed automatically.
 	PlannedClosedAt *time.Time `json:"planned_closed_at,omitempty"`
+
+	// Period is one of calendar_month, calendar_week or day. The billing covers the period containing the creation time
+	// and is closed at its end, so it cannot be combined with PlannedClosedAt.
+	Period string `json:"period,omitempty"`
+
+	// Timezone is the IANA timezone periods are computed in, defaults to UTC
+	Timezone string `json:"timezone,omitempty"`
+
+	// Recurrence makes the billing roll over into a new billing when it closes. If PlannedClosedAt is not provided, the first period ends at the next boundary of the rule.
+	Recurrence *RecurrenceRule `json:"recurrence,omitempty"`
+
+	// TaxJurisdiction selects the tax rates applied to line items with a tax code, e.g. GB or US-NY
+	TaxJurisdiction string `json:"tax_jurisdiction,omitempty"`
+
+	// TaxInclusive means line item amounts already include tax, otherwise tax is added on top
+	TaxInclusive bool `json:"tax_inclusive,omitempty"`
+
+	// AllowNegativeTotal lets adjustments bring the billing total below zero
+	AllowNegativeTotal bool `json:"allow_negative_total,omitempty"`
+
+	// UserGroup selects the invoice number series the billing is numbered in when it closes, defaults to the default series
+	UserGroup string `json:"user_group,omitempty"`
+
+	// AutoCharge charges the user through the payment gateway once the billing is closed
+	AutoCharge bool `json:"auto_charge,omitempty"`
+
+	// SpendLimit alerts when the billing total crosses percentages of a cap, and can stop the total from going over it
+	SpendLimit *SpendLimit `json:"spend_limit,omitempty"`
+}
+
+type SpendLimit struct {
+	Amount     float64 `json:"amount"`
+	Thresholds []int64 `json:"thresholds,omitempty"` // percentages of the amount alerted once reached, defaults to 50, 80 and 100
+	Mode       string  `json:"mode,omitempty"`       // alert (default), reject or close
+}
+
+type RecurrenceRule struct {
+	Frequency  string     `json:"frequency"`              // monthly, weekly or daily
+	Interval   int64      `json:"interval,omitempty"`     // every N months, weeks or days, defaults to 1
+	DayOfMonth int64      `json:"day_of_month,omitempty"` // required for monthly
+	EndsAt     *time.Time `json:"ends_at,omitempty"`
+	Timezone   string     `json:"timezone,omitempty"` // IANA timezone, defaults to UTC
 }
 
 type CreateBillingResponse struct {
@@ -18,18 +63,618 @@ type CreateBillingResponse struct {
 type AddLineItemRequest struct {
 	Description string  `json:"description"`
 	Amount      float64 `json:"amount"`
+	TaxCode     string  `json:"tax_code,omitempty"` // optional, must have a rate in the billing tax jurisdiction
+}
+
+type CancelBillingRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type AddAdjustmentRequest struct {
+	Description         string  `json:"description"`
+	Amount              float64 `json:"amount"` // negative
+	Reason              string  `json:"reason"`
+	ReferenceLineItemID string  `json:"reference_line_item_id,omitempty"` // optional, the line item the adjustment corrects
+	TaxCode             string  `json:"tax_code,omitempty"`               // optional, defaults to the tax code of the referenced line item
+}
+
+type AddAdjustmentResponse struct {
+	LineItemID string `json:"line_item_id"`
 }
 
 type LineItem struct {
-	Description string `json:"description"`
-	AmountMinor int64  `json:"amountMinor"`
+	LineItemID     string `json:"line_item_id,omitempty"`
+	Kind           string `json:"kind,omitempty"` // charge or adjustment
+	Description    string `json:"description"`
+	AmountMinor    int64  `json:"amountMinor"`
+	TaxCode        string `json:"tax_code,omitempty"`
+	TaxAmountMinor int64  `json:"tax_amount_minor,omitempty"`
+
+	DiscountAmountMinor int64 `json:"discount_amount_minor,omitempty"`
+
+	Reason              string `json:"reason,omitempty"`
+	ReferenceLineItemID string `json:"reference_line_item_id,omitempty"`
+
+	// Adjustments are the adjustments referencing this line item, NetAmountMinor is the amount left after them
+	Adjustments    []LineItem `json:"adjustments,omitempty"`
+	NetAmountMinor int64      `json:"net_amount_minor"`
+}
+
+type Discount struct {
+	CouponCode  string  `json:"coupon_code"`
+	Type        string  `json:"type"`
+	PercentOff  float64 `json:"percent_off,omitempty"`
+	AmountMinor int64   `json:"amount_minor"`
+}
+
+type TaxBreakdown struct {
+	Rate               float64 `json:"rate"` // percent
+	TaxableAmountMinor int64   `json:"taxable_amount_minor"`
+	TaxAmountMinor     int64   `json:"tax_amount_minor"`
 }
 
 type GetBillingSummaryResponse struct {
 	ExternalBillingID string     `json:"billing_id"`
+	InvoiceNumber     string     `json:"invoice_number,omitempty"` // assigned when the billing closes
 	Description       string     `json:"description"`
 	Currency          string     `json:"currency"`
 	CurrencyPrecision int64      `json:"currency_precision"`
-	LineItems         []LineItem `json:"line_items"`
+	LineItems         []LineItem `json:"line_items"` // adjustments are grouped under the line items they reference
 	TotalAmountMinor  int64      `json:"total_amount_minor"`
+	PeriodStart       *time.Time `json:"period_start,omitempty"`
+	PeriodEnd         *time.Time `json:"period_end,omitempty"`
+	Timezone          string     `json:"timezone,omitempty"`
+	SubscriptionID    *string    `json:"subscription_id,omitempty"`
+	PreviousBillingID *string    `json:"previous_billing_id,omitempty"`
+	NextBillingID     *string    `json:"next_billing_id,omitempty"`
+
+	// discounts, subtotal, taxes and grand total are computed when the billing closes
+	TaxJurisdiction       string         `json:"tax_jurisdiction,omitempty"`
+	TaxInclusive          bool           `json:"tax_inclusive,omitempty"`
+	Discounts             []Discount     `json:"discounts,omitempty"`
+	DiscountAmountMinor   int64          `json:"discount_amount_minor"`
+	SubtotalAmountMinor   int64          `json:"subtotal_amount_minor"`
+	Taxes                 []TaxBreakdown `json:"taxes,omitempty"`
+	TaxAmountMinor        int64          `json:"tax_amount_minor"`
+	GrandTotalAmountMinor int64          `json:"grand_total_amount_minor"`
+
+	// credit drawn from the wallet of the user when the billing closed, and what was left to pay
+	PaidFromCreditAmountMinor int64 `json:"paid_from_credit_amount_minor"`
+	AmountDueMinor            int64 `json:"amount_due_minor"`
+}
+
+type CreatePlanRequest struct {
+	Name          string  `json:"name"`
+	Currency      string  `json:"currency"`
+	Amount        float64 `json:"amount"`
+	Interval      string  `json:"interval"`                 // month, week or day
+	IntervalCount int64   `json:"interval_count,omitempty"` // defaults to 1
+	TrialDays     int64   `json:"trial_days,omitempty"`
+}
+
+type CreatePlanResponse struct {
+	PlanID string `json:"plan_id"`
+}
+
+type CreateSubscriptionRequest struct {
+	UserID   string `json:"user_id"`
+	PlanID   string `json:"plan_id"`
+	Timezone string `json:"timezone,omitempty"` // IANA timezone periods are computed in, defaults to UTC
+}
+
+type CreateSubscriptionResponse struct {
+	SubscriptionID string `json:"subscription_id"`
+}
+
+type ChangeSubscriptionPlanRequest struct {
+	PlanID string `json:"plan_id"`
+}
+
+type GetSubscriptionResponse struct {
+	SubscriptionID   string     `json:"subscription_id"`
+	UserID           string     `json:"user_id"`
+	PlanID           string     `json:"plan_id"`
+	Status           string     `json:"status"`
+	Timezone         string     `json:"timezone"`
+	TrialEndsAt      *time.Time `json:"trial_ends_at,omitempty"`
+	CurrentBillingID *string    `json:"current_billing_id,omitempty"`
+	CancelledAt      *time.Time `json:"cancelled_at,omitempty"`
+}
+
+type ProrateRequest struct {
+	// ChangeAt is the time the change takes effect, defaults to now
+	ChangeAt *time.Time `json:"change_at,omitempty"`
+
+	// Method is day or second, defaults to day
+	Method string `json:"method,omitempty"`
+
+	OldDescription string  `json:"old_description"`
+	OldAmount      float64 `json:"old_amount"`             // unit price before the change
+	OldQuantity    *int64  `json:"old_quantity,omitempty"` // defaults to 1
+
+	NewDescription string  `json:"new_description"`
+	NewAmount      float64 `json:"new_amount"`             // unit price after the change
+	NewQuantity    *int64  `json:"new_quantity,omitempty"` // defaults to 1
+
+	TaxCode string `json:"tax_code,omitempty"` // optional, set on both line items
+}
+
+type ProrateResponse struct {
+	LineItems []LineItem `json:"line_items"`
+}
+
+type SetTaxRateRequest struct {
+	Jurisdiction string  `json:"jurisdiction"`
+	TaxCode      string  `json:"tax_code"`
+	Name         string  `json:"name"`
+	Rate         float64 `json:"rate"` // percent, e.g. 8.875
+}
+
+type TaxRate struct {
+	Jurisdiction string  `json:"jurisdiction"`
+	TaxCode      string  `json:"tax_code"`
+	Name         string  `json:"name"`
+	Rate         float64 `json:"rate"`
+}
+
+type ListTaxRatesResponse struct {
+	TaxRates []TaxRate `json:"tax_rates"`
+}
+
+type CreateCouponRequest struct {
+	Code           string     `json:"code"`
+	Type           string     `json:"type"`                      // percentage or fixed_amount
+	PercentOff     float64    `json:"percent_off,omitempty"`     // percentage coupons, at most 2 decimals
+	AmountOff      float64    `json:"amount_off,omitempty"`      // fixed amount coupons
+	Currency       string     `json:"currency,omitempty"`        // required for fixed amount coupons, restricts percentage coupons when set
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`      // optional
+	MaxRedemptions *int64     `json:"max_redemptions,omitempty"` // optional, unlimited when not set
+}
+
+type CreateCouponResponse struct {
+	Code string `json:"code"`
+}
+
+type ApplyCouponRequest struct {
+	Code string `json:"code"`
+}
+
+type CreateCreditNoteRequest struct {
+	Reason    string                      `json:"reason"`
+	LineItems []CreditNoteLineItemRequest `json:"line_items"`
+}
+
+type CreditNoteLineItemRequest struct {
+	Description         string  `json:"description"`
+	Amount              float64 `json:"amount"`                           // positive, tax inclusive
+	ReferenceLineItemID string  `json:"reference_line_item_id,omitempty"` // optional, the billed line item being refunded
+}
+
+type CreditNoteLineItem struct {
+	Description         string `json:"description"`
+	AmountMinor         int64  `json:"amount_minor"`
+	ReferenceLineItemID string `json:"reference_line_item_id,omitempty"`
+}
+
+type CreditNote struct {
+	CreditNoteID      string               `json:"credit_note_id"`
+	Number            string               `json:"number"`
+	BillingID         string               `json:"billing_id"`
+	Currency          string               `json:"currency"`
+	CurrencyPrecision int64                `json:"currency_precision"`
+	Reason            string               `json:"reason"`
+	LineItems         []CreditNoteLineItem `json:"line_items"`
+	TotalAmountMinor  int64                `json:"total_amount_minor"`
+	CreatedAt         time.Time            `json:"created_at"`
+}
+
+type ListCreditNotesResponse struct {
+	CreditNotes []CreditNote `json:"credit_notes"`
+
+	// BilledAmountMinor is the grand total of the billing, NetAmountMinor what is left of it after credit notes
+	BilledAmountMinor   int64 `json:"billed_amount_minor"`
+	CreditedAmountMinor int64 `json:"credited_amount_minor"`
+	NetAmountMinor      int64 `json:"net_amount_minor"`
+}
+
+type SetInvoiceNumberSeriesRequest struct {
+	UserGroup string `json:"user_group"`        // empty for the default series
+	Prefix    string `json:"prefix"`            // e.g. INV, uppercase letters and digits separated by dashes
+	Padding   int64  `json:"padding,omitempty"` // digits of the sequence number, defaults to 6
+}
+
+type InvoiceNumberSeries struct {
+	UserGroup string `json:"user_group"`
+	Prefix    string `json:"prefix"`
+	Padding   int64  `json:"padding"`
+}
+
+type ListInvoiceNumberSeriesResponse struct {
+	Series []InvoiceNumberSeries `json:"series"`
+}
+
+type SetInvoiceTemplateRequest struct {
+	Tenant   string `json:"tenant"`   // user group of the billings the template is used for, empty for billings without one
+	Format   string `json:"format"`   // html or pdf
+	Template string `json:"template"` // Go template rendered with the invoice, pdf templates output a line of text per line
+}
+
+type InvoiceTemplate struct {
+	Tenant    string    `json:"tenant"`
+	Format    string    `json:"format"`
+	Template  string    `json:"template"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Party struct {
+	Name           string `json:"name"`
+	VATID          string `json:"vat_id,omitempty"` // required for sellers
+	Street         string `json:"street,omitempty"`
+	City           string `json:"city,omitempty"`
+	PostalCode     string `json:"postal_code,omitempty"`
+	CountryCode    string `json:"country_code"`    // ISO 3166-1 alpha-2, e.g. DE
+	EndpointID     string `json:"endpoint_id"`     // electronic address invoices are delivered to
+	EndpointScheme string `json:"endpoint_scheme"` // Peppol EAS code of the endpoint, e.g. 0088 for GLN
+}
+
+type SetSellerRequest struct {
+	Tenant string `json:"tenant"` // user group of the billings the seller issues
+	Party  Party  `json:"party"`
+}
+
+type SetBuyerRequest struct {
+	UserID string `json:"user_id"`
+	Party  Party  `json:"party"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"` // e.g. billing.closed
+	Secret     string   `json:"secret"`      // signs requests, at least 16 characters
+}
+
+type Webhook struct {
+	WebhookID  string    `json:"webhook_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ListWebhookDeliveriesRequest struct {
+	Limit int `query:"limit"` // defaults to 50
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+type WebhookDelivery struct {
+	DeliveryID string                   `json:"delivery_id"`
+	WebhookID  string                   `json:"webhook_id"`
+	EventID    string                   `json:"event_id"`
+	EventType  string                   `json:"event_type"`
+	Status     string                   `json:"status"` // pending, succeeded or failed
+	Payload    json.RawMessage          `json:"payload"`
+	Attempts   []WebhookDeliveryAttempt `json:"attempts"`
+	CreatedAt  time.Time                `json:"created_at"`
+	UpdatedAt  time.Time                `json:"updated_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	StatusCode  int       `json:"status_code"` // 0 when no response was received
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+type ReplayOutboxRequest struct {
+	FromOffset int64 `json:"from_offset"`
+	Limit      int   `json:"limit"` // defaults to 100
+}
+
+type ReplayOutboxResponse struct {
+	Replayed   int   `json:"replayed"`
+	NextOffset int64 `json:"next_offset"`
+}
+
+type RecordPaymentRequest struct {
+	Amount            float64    `json:"amount"`                       // positive, in the currency of the billing
+	Currency          string     `json:"currency"`                     // must be the currency of the billing
+	Method            string     `json:"method"`                       // card, bank_transfer, direct_debit, cash or other
+	ExternalReference string     `json:"external_reference,omitempty"` // provider reference, a payment recorded again with it is recorded once
+	PaidAt            *time.Time `json:"paid_at,omitempty"`            // defaults to now
+}
+
+type Payment struct {
+	PaymentID           string    `json:"payment_id"`
+	BillingID           string    `json:"billing_id"`
+	AmountMinor         int64     `json:"amount_minor"`
+	RefundedAmountMinor int64     `json:"refunded_amount_minor"` // sum of the succeeded refunds
+	Currency            string    `json:"currency"`
+	CurrencyPrecision   int64     `json:"currency_precision"`
+	Method              string    `json:"method"` // wallet for credit drawn when the billing closed
+	ExternalReference   string    `json:"external_reference,omitempty"`
+	PaidAt              time.Time `json:"paid_at"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// PaymentBalance is what a closed billing owes net of its credit notes, what was paid and the derived status
+type PaymentBalance struct {
+	DueAmountMinor         int64  `json:"due_amount_minor"`
+	PaidAmountMinor        int64  `json:"paid_amount_minor"`
+	OutstandingAmountMinor int64  `json:"outstanding_amount_minor"` // negative when overpaid
+	Status                 string `json:"status"`                   // unpaid, partially_paid, paid or overpaid
+}
+
+type RecordPaymentResponse struct {
+	Payment Payment        `json:"payment"`
+	Balance PaymentBalance `json:"balance"`
+}
+
+type ListPaymentsResponse struct {
+	Payments []Payment      `json:"payments"`
+	Balance  PaymentBalance `json:"balance"`
+}
+
+type StartDunningRequest struct {
+	ScheduleDays []int `json:"schedule_days,omitempty"` // days after the start on which the billing is reminded, defaults to [1, 3, 7]
+}
+
+type Dunning struct {
+	BillingID    string    `json:"billing_id"`
+	Status       string    `json:"status"` // active, past_due, uncollectible or resolved
+	Step         int       `json:"step"`   // last step run, 0 before the first reminder
+	ScheduleDays []int     `json:"schedule_days"`
+	StartedAt    time.Time `json:"started_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type RefundPaymentRequest struct {
+	Amount         float64 `json:"amount"`                   // positive, in the currency of the payment
+	Reason         string  `json:"reason"`                   // why the payment is refunded
+	IdempotencyKey string  `json:"idempotency_key"`          // a refund requested again with it is refunded once
+	CreditNoteID   string  `json:"credit_note_id,omitempty"` // credit note of the billing the refund settles
+}
+
+type Refund struct {
+	RefundID          string    `json:"refund_id"`
+	PaymentID         string    `json:"payment_id"`
+	BillingID         string    `json:"billing_id"`
+	CreditNoteID      string    `json:"credit_note_id,omitempty"`
+	AmountMinor       int64     `json:"amount_minor"`
+	Currency          string    `json:"currency"`
+	CurrencyPrecision int64     `json:"currency_precision"`
+	Reason            string    `json:"reason"`
+	IdempotencyKey    string    `json:"idempotency_key"`
+	Status            string    `json:"status"` // pending, succeeded or failed
+	Error             string    `json:"error,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type ListRefundsResponse struct {
+	Refunds []Refund `json:"refunds"`
+}
+
+type ImportBankStatementRequest struct {
+	Format  string `json:"format"`  // camt053 or csv
+	Content string `json:"content"` // statement file, a file imported again returns the first import
+}
+
+type BankStatement struct {
+	StatementID  string            `json:"statement_id"`
+	Format       string            `json:"format"`
+	Reference    string            `json:"reference,omitempty"`
+	Account      string            `json:"account,omitempty"`
+	Matched      int               `json:"matched"`      // transactions recorded as payments
+	NeedsReview  int               `json:"needs_review"` // incoming transactions waiting for review
+	Ignored      int               `json:"ignored"`      // outgoing or ignored transactions
+	Transactions []BankTransaction `json:"transactions"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+type BankTransaction struct {
+	TransactionID       string    `json:"transaction_id"`
+	StatementID         string    `json:"statement_id"`
+	BankReference       string    `json:"bank_reference"`
+	BookingDate         string    `json:"booking_date"` // YYYY-MM-DD
+	Direction           string    `json:"direction"`    // credit or debit
+	AmountMinor         int64     `json:"amount_minor"`
+	Currency            string    `json:"currency"`
+	CurrencyPrecision   int64     `json:"currency_precision"`
+	CounterpartyName    string    `json:"counterparty_name,omitempty"`
+	RemittanceInfo      string    `json:"remittance_info,omitempty"`
+	Status              string    `json:"status"`               // matched, needs_review or ignored
+	MatchRule           string    `json:"match_rule,omitempty"` // invoice_number, amount_and_reference or manual
+	CandidateBillingIDs []string  `json:"candidate_billing_ids,omitempty"`
+	BillingID           string    `json:"billing_id,omitempty"`
+	PaymentID           string    `json:"payment_id,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+type ListBankTransactionsRequest struct {
+	Status string `query:"status"` // defaults to needs_review
+	Limit  int    `query:"limit"`  // defaults to 50
+}
+
+type ListBankTransactionsResponse struct {
+	Transactions []BankTransaction `json:"transactions"`
+}
+
+type MatchBankTransactionRequest struct {
+	BillingID string `json:"billing_id"` // closed billing the transaction pays
+}
+
+type GetAgingReportRequest struct {
+	AsOf              string `query:"as_of"`              // YYYY-MM-DD in UTC, defaults to today
+	PaymentTermsDays  int    `query:"payment_terms_days"` // days after closing a billing is due, defaults to 0 (due on receipt)
+	ReportingCurrency string `query:"currency"`           // optional, converts the report with the fx rates of the as of day
+}
+
+type AgingAmounts struct {
+	CurrentAmountMinor    int64 `json:"current_amount_minor"` // not yet due
+	Days1To30AmountMinor  int64 `json:"days_1_30_amount_minor"`
+	Days31To60AmountMinor int64 `json:"days_31_60_amount_minor"`
+	Days61To90AmountMinor int64 `json:"days_61_90_amount_minor"`
+	Over90DaysAmountMinor int64 `json:"days_90_plus_amount_minor"`
+	TotalAmountMinor      int64 `json:"total_amount_minor"`
+}
+
+type AgingRow struct {
+	UserID            string        `json:"user_id"`
+	Currency          string        `json:"currency"`
+	CurrencyPrecision int64         `json:"currency_precision"`
+	BillingCount      int           `json:"billing_count"` // closed billings with something outstanding
+	Amounts           AgingAmounts  `json:"amounts"`
+	ReportingAmounts  *AgingAmounts `json:"reporting_amounts,omitempty"` // in the reporting currency
+}
+
+type AgingReport struct {
+	AsOf                       string        `json:"as_of"` // YYYY-MM-DD
+	PaymentTermsDays           int           `json:"payment_terms_days"`
+	Rows                       []AgingRow    `json:"rows"`
+	ReportingCurrency          string        `json:"reporting_currency,omitempty"`
+	ReportingCurrencyPrecision int64         `json:"reporting_currency_precision,omitempty"`
+	ReportingTotals            *AgingAmounts `json:"reporting_totals,omitempty"`
+}
+
+type ListLedgerBalancesRequest struct {
+	Currency string `query:"currency"` // optional, all currencies by default
+}
+
+type LedgerAccountBalance struct {
+	Account           string `json:"account"` // cash, accounts_receivable, tax_payable, revenue, sales_returns or customer_credit
+	Name              string `json:"name"`
+	Type              string `json:"type"`           // asset, liability, revenue or contra_revenue
+	NormalBalance     string `json:"normal_balance"` // debit or credit, the side the balance is positive on
+	Currency          string `json:"currency"`
+	DebitAmountMinor  int64  `json:"debit_amount_minor"`
+	CreditAmountMinor int64  `json:"credit_amount_minor"`
+	BalanceMinor      int64  `json:"balance_minor"`
+}
+
+type ListLedgerBalancesResponse struct {
+	Balances []LedgerAccountBalance `json:"balances"`
+}
+
+type LedgerPosting struct {
+	Account     string `json:"account"`
+	Direction   string `json:"direction"` // debit or credit
+	AmountMinor int64  `json:"amount_minor"`
+}
+
+type JournalEntry struct {
+	EntryID     string          `json:"entry_id"`
+	Kind        string          `json:"kind"` // billing_closed, payment_recorded, refund_succeeded or credit_note_issued
+	BillingID   string          `json:"billing_id"`
+	Currency    string          `json:"currency"`
+	Description string          `json:"description"`
+	Postings    []LedgerPosting `json:"postings"`
+	PostedAt    time.Time       `json:"posted_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type ListJournalEntriesResponse struct {
+	Entries []JournalEntry `json:"entries"`
+}
+
+type LedgerTotals struct {
+	Currency          string `json:"currency"`
+	DebitAmountMinor  int64  `json:"debit_amount_minor"`
+	CreditAmountMinor int64  `json:"credit_amount_minor"`
+}
+
+type LedgerCheck struct {
+	Balanced           bool           `json:"balanced"` // debits equal credits in every currency and every entry
+	Totals             []LedgerTotals `json:"totals"`
+	UnbalancedEntryIDs []string       `json:"unbalanced_entry_ids"`
+}
+
+type Wallet struct {
+	UserID            string    `json:"user_id"`
+	Currency          string    `json:"currency"`
+	CurrencyPrecision int64     `json:"currency_precision"`
+	BalanceMinor      int64     `json:"balance_minor"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type ListWalletsResponse struct {
+	Wallets []Wallet `json:"wallets"`
+}
+
+type TopUpWalletRequest struct {
+	Currency          string  `json:"currency"`
+	Amount            float64 `json:"amount"`                       // positive
+	ExternalReference string  `json:"external_reference,omitempty"` // identifies the top-up at its provider, a top-up sent again with it is recorded once
+}
+
+type AdjustWalletRequest struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"` // positive adds credit, negative removes it
+	Reason   string  `json:"reason"`
+}
+
+type WalletTransaction struct {
+	TransactionID     string    `json:"transaction_id"`
+	UserID            string    `json:"user_id"`
+	Currency          string    `json:"currency"`
+	CurrencyPrecision int64     `json:"currency_precision"`
+	Kind              string    `json:"kind"`         // top_up, adjustment or billing_draw
+	AmountMinor       int64     `json:"amount_minor"` // negative when credit is removed
+	BalanceAfterMinor int64     `json:"balance_after_minor"`
+	Reason            string    `json:"reason,omitempty"`
+	ExternalReference string    `json:"external_reference,omitempty"`
+	BillingID         string    `json:"billing_id,omitempty"` // billing that drew the credit
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type ListWalletTransactionsRequest struct {
+	Currency string `query:"currency"`
+}
+
+type ListWalletTransactionsResponse struct {
+	Transactions []WalletTransaction `json:"transactions"`
+}
+
+type CreateMeterRequest struct {
+	Code        string  `json:"code"`        // usage events report the meter by its code
+	Name        string  `json:"name"`        // describes the line item of the usage
+	Aggregation string  `json:"aggregation"` // sum, max or last
+	Currency    string  `json:"currency"`    // only billings in the currency bill the usage of the meter
+	UnitAmount  float64 `json:"unit_amount"`
+}
+
+type Meter struct {
+	Code              string    `json:"code"`
+	Name              string    `json:"name"`
+	Aggregation       string    `json:"aggregation"`
+	Currency          string    `json:"currency"`
+	CurrencyPrecision int64     `json:"currency_precision"`
+	UnitAmountMinor   int64     `json:"unit_amount_minor"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type ListMetersResponse struct {
+	Meters []Meter `json:"meters"`
+}
+
+type UsageEvent struct {
+	UserID    string    `json:"user_id"`
+	Meter     string    `json:"meter"` // code of the meter
+	Quantity  int64     `json:"quantity"`
+	Timestamp time.Time `json:"timestamp"`
+	DedupID   string    `json:"dedup_id"` // an event sent again with it for the user is ingested once
+}
+
+type IngestUsageEventsRequest struct {
+	Events []UsageEvent `json:"events"`
+}
+
+type IngestUsageEventsResponse struct {
+	Ingested   int `json:"ingested"`
+	Duplicates int `json:"duplicates"` // events already ingested with their dedup ID
 }
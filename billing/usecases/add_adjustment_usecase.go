@@ -0,0 +1,149 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/google/uuid"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+)
+
+type addAdjustmentUseCase struct {
+	dbRepository    repositories.DBRepository
+	taxRepository   repositories.TaxRepository
+	billingWorkflow ports.BillingWorkflow
+}
+
+type AddAdjustmentUsecase interface {
+	Execute(ctx context.Context, externalBillingID string, input dto.AddAdjustmentInput) (string, error)
+}
+
+func NewAddAdjustmentUseCase(dbRepository repositories.DBRepository, taxRepository repositories.TaxRepository, billingWorkflow ports.BillingWorkflow) AddAdjustmentUsecase {
+	return &addAdjustmentUseCase{dbRepository: dbRepository, taxRepository: taxRepository, billingWorkflow: billingWorkflow}
+}
+
+func (uc *addAdjustmentUseCase) Execute(ctx context.Context, externalBillingID string, input dto.AddAdjustmentInput) (string, error) {
+	fn := "usecases.addAdjustmentUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("amount", input.Amount).With("referenceLineItemID", input.ReferenceLineItemID)
+
+	// get billing
+	billing, err := uc.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return "", dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return "", dto.ErrFailedToGetBillingByExternalID
+	}
+
+	// validate billing is open
+	if !billing.CanAddLineItem() {
+		logger.Warn("billing is not open")
+		return "", dto.ErrBillingNotOpen
+	}
+
+	if !billing.CanAddItemWithAmount(input.Amount) {
+		logger.Warn("amount has too many decimals")
+		return "", dto.ErrAmountHasTooManyDecimals
+	}
+
+	// get current line items of the billing
+	summary, err := uc.billingWorkflow.GetBillingSummary(ctx, externalBillingID)
+	if err != nil {
+		logger.Error("failed to get billing summary", "error", err)
+		return "", dto.ErrFailedToGetBillingSummary
+	}
+
+	// generate line item ID
+	randomUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("failed to generate line item ID")
+		return "", dto.ErrFailedToGenerateLineItemID
+	}
+
+	// convert amount to minor units
+	amountMinor := int64(input.Amount * math.Pow10(int(billing.CurrencyPrecision)))
+
+	adjustment := entities.LineItem{
+		LineItemID:          randomUUID.String(),
+		Kind:                entities.LineItemKindAdjustment,
+		Description:         input.Description,
+		AmountMinor:         amountMinor,
+		TaxCode:             input.TaxCode,
+		Reason:              input.Reason,
+		ReferenceLineItemID: input.ReferenceLineItemID,
+	}
+
+	// an adjustment is taxed like the item it corrects unless told otherwise
+	if adjustment.TaxCode == "" && adjustment.ReferenceLineItemID != "" {
+		for _, lineItem := range summary.LineItems {
+			if lineItem.LineItemID == adjustment.ReferenceLineItemID {
+				adjustment.TaxCode = lineItem.TaxCode
+			}
+		}
+	}
+
+	// validate tax code has a rate in the billing jurisdiction
+	err = validateTaxCode(ctx, uc.taxRepository, billing, adjustment.TaxCode)
+	if err != nil {
+		logger.Warn("tax code is invalid", "error", err)
+		return "", err
+	}
+
+	// validate adjustment against the current line items
+	err = entities.ValidateAdjustment(summary.LineItems, adjustment, billing.AllowNegativeTotal)
+	if err != nil {
+		logger.Warn("adjustment is invalid", "error", err)
+		if adjustmentErr := adjustmentError(err); adjustmentErr != nil {
+			return "", adjustmentErr
+		}
+		return "", dto.ErrInvalidAdjustment
+	}
+
+	// add adjustment to billing workflow, which validates it again against the line items it has then
+	err = uc.billingWorkflow.AddLineItem(ctx, externalBillingID, adjustment)
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotOpen) {
+			logger.Warn("billing is not open")
+			return "", dto.ErrBillingNotOpen
+		}
+		if adjustmentErr := adjustmentError(err); adjustmentErr != nil {
+			logger.Warn("adjustment rejected by billing workflow", "error", err)
+			return "", adjustmentErr
+		}
+
+		logger.Error("failed to add adjustment to billing workflow", "error", err)
+		return "", dto.ErrFailedToAddLineItemToBillingWorkflow
+	}
+
+	logger.Info("adjustment added successfully", "lineItemID", adjustment.LineItemID)
+
+	return adjustment.LineItemID, nil
+}
+
+// adjustmentError returns the use case error of an adjustment rejected with err, or nil if err is no rejection
+func adjustmentError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidAdjustment):
+		return dto.ErrInvalidAdjustment
+	case errors.Is(err, entities.ErrAdjustmentReasonRequired):
+		return dto.ErrAdjustmentReasonRequired
+	case errors.Is(err, entities.ErrReferencedLineItemNotFound):
+		return dto.ErrReferencedLineItemNotFound
+	case errors.Is(err, entities.ErrAdjustmentExceedsReferencedItem):
+		return dto.ErrAdjustmentExceedsReferencedItem
+	case errors.Is(err, entities.ErrNegativeBillingTotal):
+		return dto.ErrNegativeBillingTotal
+	}
+	return nil
+}
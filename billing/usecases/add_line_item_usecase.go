@@ -15,20 +15,22 @@ import (
 
 type addLineItemUseCase struct {
 	dbRepository    repositories.DBRepository
+	taxRepository   repositories.TaxRepository
 	billingWorkflow ports.BillingWorkflow
 }
 
 type AddLineItemUsecase interface {
-	Execute(ctx context.Context, externalBillingID string, description string, amount float64) error
+	Execute(ctx context.Context, externalBillingID string, input dto.AddLineItemInput) error
 }
 
-func NewAddLineItemUsecase(dbRepository repositories.DBRepository, billingWorkflow ports.BillingWorkflow) AddLineItemUsecase {
-	return &addLineItemUseCase{dbRepository: dbRepository, billingWorkflow: billingWorkflow}
+func NewAddLineItemUsecase(dbRepository repositories.DBRepository, taxRepository repositories.TaxRepository, billingWorkflow ports.BillingWorkflow) AddLineItemUsecase {
+	return &addLineItemUseCase{dbRepository: dbRepository, taxRepository: taxRepository, billingWorkflow: billingWorkflow}
 }
 
-func (uc *addLineItemUseCase) Execute(ctx context.Context, externalBillingID string, description string, amount float64) error {
+func (uc *addLineItemUseCase) Execute(ctx context.Context, externalBillingID string, input dto.AddLineItemInput) error {
 	fn := "addLineItemUseCase.AddLineItem"
-	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("amount", amount)
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("amount", input.Amount).With("taxCode", input.TaxCode)
+	amount := input.Amount
 
 	// get billing
 	billing, err := uc.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
@@ -54,13 +56,38 @@ func (uc *addLineItemUseCase) Execute(ctx context.Context, externalBillingID str
 		return dto.ErrAmountHasTooManyDecimals
 	}
 
+	// validate tax code has a rate in the billing jurisdiction
+	err = validateTaxCode(ctx, uc.taxRepository, billing, input.TaxCode)
+	if err != nil {
+		logger.Warn("tax code is invalid", "error", err)
+		return err
+	}
+
 	// convert amount to minor units
 	currencyPrecision := billing.CurrencyPrecision
 	amountMinor := int64(amount * math.Pow10(int(currencyPrecision)))
 
 	// add line item to billing workflow
-	err = uc.billingWorkflow.AddLineItem(ctx, externalBillingID, description, amountMinor)
+	err = uc.billingWorkflow.AddLineItem(ctx, externalBillingID, entities.LineItem{
+		Description: input.Description,
+		AmountMinor: amountMinor,
+		TaxCode:     input.TaxCode,
+	})
 	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotOpen) {
+			logger.Warn("billing is not open")
+			return dto.ErrBillingNotOpen
+		}
+		// the workflow checks the line item against the spend limit of its current total, and closes a billing with a
+		// closing spend limit when it refuses the line item
+		if errors.Is(err, entities.ErrSpendLimitExceeded) {
+			logger.Warn("line item exceeds spend limit")
+			if billing.SpendLimit != nil && billing.SpendLimit.Mode == entities.SpendLimitModeClose {
+				return dto.ErrBillingClosedAtSpendLimit
+			}
+			return dto.ErrSpendLimitExceeded
+		}
+
 		logger.Error("failed to add line item to billing workflow", "error", err)
 		return dto.ErrFailedToAddLineItemToBillingWorkflow
 	}
@@ -69,3 +96,23 @@ func (uc *addLineItemUseCase) Execute(ctx context.Context, externalBillingID str
 
 	return nil
 }
+
+// validateTaxCode checks that line items with a tax code are only added to billings with a jurisdiction that has a rate for it
+func validateTaxCode(ctx context.Context, taxRepository repositories.TaxRepository, billing *entities.Billing, taxCode string) error {
+	if taxCode == "" {
+		return nil
+	}
+	if billing.TaxJurisdiction == "" {
+		return dto.ErrBillingHasNoTaxJurisdiction
+	}
+
+	_, err := taxRepository.GetTaxRate(ctx, billing.TaxJurisdiction, taxCode)
+	if err != nil {
+		if errors.Is(err, entities.ErrTaxRateNotFound) {
+			return dto.ErrTaxRateNotFound
+		}
+		return dto.ErrFailedToGetTaxRate
+	}
+
+	return nil
+}
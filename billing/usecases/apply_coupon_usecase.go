@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+)
+
+type applyCouponUseCase struct {
+	dbRepository     repositories.DBRepository
+	couponRepository repositories.CouponRepository
+	billingWorkflow  ports.BillingWorkflow
+}
+
+// ApplyCouponUsecase redeems a coupon for an open billing, applying the same coupon twice is a no-op
+type ApplyCouponUsecase interface {
+	Execute(ctx context.Context, externalBillingID string, code string) error
+}
+
+func NewApplyCouponUseCase(dbRepository repositories.DBRepository, couponRepository repositories.CouponRepository, billingWorkflow ports.BillingWorkflow) ApplyCouponUsecase {
+	return &applyCouponUseCase{dbRepository: dbRepository, couponRepository: couponRepository, billingWorkflow: billingWorkflow}
+}
+
+func (uc *applyCouponUseCase) Execute(ctx context.Context, externalBillingID string, code string) error {
+	fn := "applyCouponUseCase.ApplyCoupon"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("code", code)
+
+	// get billing
+	billing, err := uc.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return dto.ErrFailedToGetBillingByExternalID
+	}
+
+	// validate billing is open
+	if !billing.CanAddLineItem() {
+		logger.Warn("billing is not open")
+		return dto.ErrBillingNotOpen
+	}
+
+	// get coupon
+	coupon, err := uc.couponRepository.GetCouponByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, entities.ErrCouponNotFound) {
+			logger.Warn("coupon not found")
+			return dto.ErrCouponNotFound
+		}
+
+		logger.Error("failed to get coupon", "error", err)
+		return dto.ErrFailedToGetCoupon
+	}
+
+	// validate coupon applies to billing
+	if !coupon.AppliesTo(billing.Currency) {
+		logger.Warn("coupon currency does not match billing currency", "couponCurrency", coupon.Currency, "billingCurrency", billing.Currency)
+		return dto.ErrCouponCurrencyMismatch
+	}
+
+	// redeem and apply coupon in billing workflow, the database enforces the expiry and the limit under concurrent redemptions
+	// and a billing that closes meanwhile never redeems it
+	err = uc.billingWorkflow.ApplyCoupon(ctx, externalBillingID, *coupon)
+	if err != nil {
+		if errors.Is(err, dto.ErrBillingNotOpen) {
+			logger.Warn("billing is not open")
+			return dto.ErrBillingNotOpen
+		}
+		if errors.Is(err, dto.ErrCouponRedemptionLimitReached) {
+			if errors.Is(coupon.CanRedeem(time.Now().UTC()), entities.ErrCouponExpired) {
+				logger.Warn("coupon expired")
+				return dto.ErrCouponExpired
+			}
+
+			logger.Warn("coupon redemption limit reached")
+			return dto.ErrCouponRedemptionLimitReached
+		}
+
+		logger.Error("failed to apply coupon to billing workflow", "error", err)
+		return dto.ErrFailedToApplyCouponToBillingWorkflow
+	}
+
+	logger.Info("coupon applied successfully")
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+	"encore.dev/rlog"
+)
+
+type cancelBillingUseCase struct {
+	dbRepository    repositories.DBRepository
+	billingWorkflow ports.BillingWorkflow
+}
+
+// CancelBillingUsecase cancels an open billing, it is never closed nor invoiced
+type CancelBillingUsecase interface {
+	Execute(ctx context.Context, externalBillingID string, reason string) error
+}
+
+func NewCancelBillingUseCase(dbRepository repositories.DBRepository, billingWorkflow ports.BillingWorkflow) CancelBillingUsecase {
+	return &cancelBillingUseCase{dbRepository: dbRepository, billingWorkflow: billingWorkflow}
+}
+
+func (uc *cancelBillingUseCase) Execute(ctx context.Context, externalBillingID string, reason string) error {
+	fn := "cancelBillingUseCase.CancelBilling"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("reason", reason)
+
+	// get billing
+	billing, err := uc.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return dto.ErrFailedToGetBillingByExternalID
+	}
+	if !billing.CanCancelBilling() {
+		logger.Warn("billing is not open")
+		return dto.ErrBillingNotOpen
+	}
+
+	// cancel billing
+	err = uc.billingWorkflow.CancelBilling(ctx, externalBillingID, reason)
+	if err != nil {
+		logger.Error("failed to cancel billing", "error", err)
+		return dto.ErrFailedToCancelBillingInWorkflow
+	}
+
+	logger.Info("billing cancelled successfully", "billingID", billing.ID)
+
+	return nil
+}
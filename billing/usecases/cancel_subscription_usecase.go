@@ -0,0 +1,63 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type cancelSubscriptionUseCase struct {
+	subscriptionRepository repositories.SubscriptionRepository
+}
+
+// CancelSubscriptionUsecase cancels a subscription at the end of the current period, no further billing is generated
+type CancelSubscriptionUsecase interface {
+	Execute(ctx context.Context, externalSubscriptionID string) error
+}
+
+func NewCancelSubscriptionUseCase(subscriptionRepository repositories.SubscriptionRepository) CancelSubscriptionUsecase {
+	return &cancelSubscriptionUseCase{subscriptionRepository: subscriptionRepository}
+}
+
+func (uc *cancelSubscriptionUseCase) Execute(ctx context.Context, externalSubscriptionID string) error {
+	fn := "cancelSubscriptionUseCase.CancelSubscription"
+	logger := rlog.With("fn", fn).With("externalSubscriptionID", externalSubscriptionID)
+
+	// get subscription
+	subscription, err := uc.subscriptionRepository.GetSubscriptionByExternalID(ctx, externalSubscriptionID)
+	if err != nil {
+		if errors.Is(err, entities.ErrSubscriptionNotFound) {
+			logger.Warn("subscription not found")
+			return dto.ErrSubscriptionNotFound
+		}
+
+		logger.Error("failed to get subscription", "error", err)
+		return err
+	}
+	if !subscription.CanCancel() {
+		logger.Warn("subscription is already cancelled")
+		return dto.ErrSubscriptionCancelled
+	}
+
+	cancelledAt := time.Now().UTC()
+	err = uc.subscriptionRepository.UpdateSubscriptionStatus(ctx, subscription.ID, subscription.Status, entities.SubscriptionStatusCancelled, &cancelledAt)
+	if err != nil {
+		if errors.Is(err, entities.ErrSubscriptionStatusConflict) {
+			logger.Warn("subscription status changed while cancelling")
+			return dto.ErrSubscriptionStatusConflict
+		}
+
+		logger.Error("failed to cancel subscription", "error", err)
+		return dto.ErrFailedToUpdateSubscriptionInDatabase
+	}
+
+	logger.Info("subscription cancelled successfully")
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type changeSubscriptionPlanUseCase struct {
+	subscriptionRepository repositories.SubscriptionRepository
+}
+
+// ChangeSubscriptionPlanUsecase upgrades or downgrades a subscription, the new plan is charged from the next period on
+type ChangeSubscriptionPlanUsecase interface {
+	Execute(ctx context.Context, externalSubscriptionID string, externalPlanID string) error
+}
+
+func NewChangeSubscriptionPlanUseCase(subscriptionRepository repositories.SubscriptionRepository) ChangeSubscriptionPlanUsecase {
+	return &changeSubscriptionPlanUseCase{subscriptionRepository: subscriptionRepository}
+}
+
+func (uc *changeSubscriptionPlanUseCase) Execute(ctx context.Context, externalSubscriptionID string, externalPlanID string) error {
+	fn := "changeSubscriptionPlanUseCase.ChangeSubscriptionPlan"
+	logger := rlog.With("fn", fn).With("externalSubscriptionID", externalSubscriptionID).With("externalPlanID", externalPlanID)
+
+	// get subscription
+	subscription, err := uc.subscriptionRepository.GetSubscriptionByExternalID(ctx, externalSubscriptionID)
+	if err != nil {
+		if errors.Is(err, entities.ErrSubscriptionNotFound) {
+			logger.Warn("subscription not found")
+			return dto.ErrSubscriptionNotFound
+		}
+
+		logger.Error("failed to get subscription", "error", err)
+		return err
+	}
+	if !subscription.CanChangePlan() {
+		logger.Warn("subscription is cancelled")
+		return dto.ErrSubscriptionCancelled
+	}
+
+	// get current and new plan
+	currentPlan, err := uc.subscriptionRepository.GetPlanByID(ctx, subscription.PlanID)
+	if err != nil {
+		logger.Error("failed to get current plan", "error", err)
+		return err
+	}
+	newPlan, err := uc.subscriptionRepository.GetPlanByExternalID(ctx, externalPlanID)
+	if err != nil {
+		if errors.Is(err, entities.ErrPlanNotFound) {
+			logger.Warn("plan not found")
+			return dto.ErrPlanNotFound
+		}
+
+		logger.Error("failed to get new plan", "error", err)
+		return err
+	}
+
+	// billings of a subscription share one currency
+	if newPlan.Currency != currentPlan.Currency {
+		logger.Warn("plan currency does not match", "currentCurrency", currentPlan.Currency, "newCurrency", newPlan.Currency)
+		return dto.ErrPlanCurrencyMismatch
+	}
+
+	err = uc.subscriptionRepository.UpdateSubscriptionPlan(ctx, subscription.ID, newPlan.ID)
+	if err != nil {
+		logger.Error("failed to update subscription plan", "error", err)
+		return dto.ErrFailedToUpdateSubscriptionInDatabase
+	}
+
+	logger.Info("subscription plan changed successfully")
+
+	return nil
+}
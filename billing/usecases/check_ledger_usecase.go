@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type CheckLedgerUseCase interface {
+	// Execute checks that debits equal credits in every currency and in every journal entry
+	Execute(ctx context.Context) (*entities.LedgerCheck, error)
+}
+
+type checkLedgerUseCase struct {
+	ledgerRepository repositories.LedgerRepository
+}
+
+func NewCheckLedgerUseCase(ledgerRepository repositories.LedgerRepository) CheckLedgerUseCase {
+	return &checkLedgerUseCase{ledgerRepository: ledgerRepository}
+}
+
+func (u *checkLedgerUseCase) Execute(ctx context.Context) (*entities.LedgerCheck, error) {
+	fn := "usecases.checkLedgerUseCase.Execute"
+	logger := rlog.With("fn", fn)
+
+	check, err := u.ledgerRepository.CheckLedger(ctx)
+	if err != nil {
+		logger.Error("failed to check ledger", "error", err)
+		return nil, dto.ErrFailedToCheckLedger
+	}
+
+	// entries are checked before they are posted, an unbalanced ledger means it was written to outside of the service
+	if !check.IsBalanced() {
+		logger.Error("ledger is not balanced", "totals", check.Totals, "unbalancedEntryIDs", check.UnbalancedExternalEntryIDs)
+	}
+
+	return check, nil
+}
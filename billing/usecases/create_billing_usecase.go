@@ -2,12 +2,14 @@ package usecases
 
 import (
 	"context"
+	"math"
 	"slices"
 	"time"
 
 	"encore.dev/rlog"
 	"github.com/google/uuid"
 
+	"encore.app/billing/domain/entities"
 	"encore.app/billing/domain/services"
 	"encore.app/billing/usecases/dto"
 	"encore.app/billing/usecases/ports"
@@ -20,7 +22,7 @@ type createBillingUseCase struct {
 }
 
 type CreateBillingUsecase interface {
-	Execute(ctx context.Context, userID string, description string, currency string, plannedClosedAt *time.Time) (string, error)
+	Execute(ctx context.Context, input dto.CreateBillingInput) (string, error)
 }
 
 func NewCreateBillingUseCase(fxService services.FxService, billingWorkflow ports.BillingWorkflow) CreateBillingUsecase {
@@ -30,9 +32,60 @@ func NewCreateBillingUseCase(fxService services.FxService, billingWorkflow ports
 	}
 }
 
-func (uc *createBillingUseCase) Execute(ctx context.Context, userID string, description string, currency string, plannedClosedAt *time.Time) (string, error) {
+func (uc *createBillingUseCase) Execute(ctx context.Context, input dto.CreateBillingInput) (string, error) {
 	fn := "createBillingUseCase.CreateBilling"
-	logger := rlog.With("fn", fn).With("userID", userID).With("description", description).With("currency", currency).With("plannedClosedAt", plannedClosedAt)
+	logger := rlog.With("fn", fn).With("userID", input.UserID).With("description", input.Description).With("currency", input.Currency).With("plannedClosedAt", input.PlannedClosedAt)
+
+	currency := input.Currency
+	plannedClosedAt := input.PlannedClosedAt
+	now := time.Now().UTC()
+
+	// validate timezone, recurring billings default to the timezone of their rule
+	timezone := input.Timezone
+	if timezone == "" && input.Recurrence != nil {
+		timezone = input.Recurrence.Timezone
+	}
+	if _, err := entities.LoadTimezone(timezone); err != nil {
+		logger.Warn("timezone is invalid", "timezone", timezone)
+		return "", dto.ErrInvalidTimezone
+	}
+
+	// compute calendar period bounds, the billing closes at the end of the period
+	var periodStart, periodEnd *time.Time
+	if input.Period != "" {
+		if plannedClosedAt != nil {
+			logger.Warn("period and planned closed at are mutually exclusive")
+			return "", dto.ErrInvalidBillingPeriod
+		}
+
+		start, end, err := entities.PeriodBounds(input.Period, timezone, now)
+		if err != nil {
+			logger.Warn("billing period is invalid", "period", input.Period)
+			return "", dto.ErrInvalidBillingPeriod
+		}
+		periodStart, periodEnd = &start, &end
+		plannedClosedAt = &end
+	}
+
+	// validate recurrence, recurring billings always close at a period boundary
+	if input.Recurrence != nil {
+		if err := input.Recurrence.Validate(); err != nil {
+			logger.Warn("recurrence rule is invalid", "error", err)
+			return "", dto.ErrInvalidRecurrenceRule
+		}
+
+		if plannedClosedAt == nil {
+			firstClosedAt, err := input.Recurrence.FirstClose(now)
+			if err != nil {
+				logger.Warn("failed to compute first period", "error", err)
+				return "", dto.ErrInvalidRecurrenceRule
+			}
+			plannedClosedAt = &firstClosedAt
+		}
+		if periodStart == nil {
+			periodStart, periodEnd = &now, plannedClosedAt
+		}
+	}
 
 	// validate currency
 	supportedCurrencies, err := uc.fxService.GetSupportedCurrencies(ctx, time.Now())
@@ -60,8 +113,51 @@ func (uc *createBillingUseCase) Execute(ctx context.Context, userID string, desc
 		return "", dto.ErrCurrencyMetadataNotFound
 	}
 
+	billing := &entities.Billing{
+		ExternalBillingID:  externalBillingID,
+		UserID:             input.UserID,
+		Description:        input.Description,
+		Currency:           currency,
+		CurrencyPrecision:  currencyMetadata.Precision,
+		PlannedClosedAt:    plannedClosedAt,
+		PeriodStart:        periodStart,
+		PeriodEnd:          periodEnd,
+		Timezone:           timezone,
+		Recurrence:         input.Recurrence,
+		TaxJurisdiction:    input.TaxJurisdiction,
+		TaxInclusive:       input.TaxInclusive,
+		AllowNegativeTotal: input.AllowNegativeTotal,
+		UserGroup:          input.UserGroup,
+		AutoCharge:         input.AutoCharge,
+	}
+
+	// validate spend limit, limits without thresholds alert at the default ones
+	if input.SpendLimit != nil {
+		if !billing.CanLimitSpendWithAmount(input.SpendLimit.Amount) {
+			logger.Warn("spend limit amount has too many decimals")
+			return "", dto.ErrAmountHasTooManyDecimals
+		}
+
+		spendLimit := &entities.SpendLimit{
+			AmountMinor: int64(input.SpendLimit.Amount * math.Pow10(int(billing.CurrencyPrecision))),
+			Thresholds:  input.SpendLimit.Thresholds,
+			Mode:        input.SpendLimit.Mode,
+		}
+		if len(spendLimit.Thresholds) == 0 {
+			spendLimit.Thresholds = entities.DefaultSpendLimitThresholds
+		}
+		if spendLimit.Mode == "" {
+			spendLimit.Mode = entities.SpendLimitModeAlert
+		}
+		if err := spendLimit.Validate(); err != nil {
+			logger.Warn("spend limit is invalid", "error", err)
+			return "", dto.ErrInvalidSpendLimit
+		}
+		billing.SpendLimit = spendLimit
+	}
+
 	// start billing workflow
-	err = uc.billingWorkflow.StartBilling(ctx, userID, externalBillingID, description, currency, currencyMetadata.Precision, plannedClosedAt)
+	err = uc.billingWorkflow.StartBilling(ctx, billing, nil)
 	if err != nil {
 		logger.Error("failed to start billing workflow")
 		return "", dto.ErrFailedToStartBillingWorkflow
@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type createCouponUseCase struct {
+	fxService        services.FxService
+	couponRepository repositories.CouponRepository
+}
+
+type CreateCouponUsecase interface {
+	Execute(ctx context.Context, input dto.CreateCouponInput) (string, error)
+}
+
+func NewCreateCouponUseCase(fxService services.FxService, couponRepository repositories.CouponRepository) CreateCouponUsecase {
+	return &createCouponUseCase{
+		fxService:        fxService,
+		couponRepository: couponRepository,
+	}
+}
+
+func (uc *createCouponUseCase) Execute(ctx context.Context, input dto.CreateCouponInput) (string, error) {
+	fn := "createCouponUseCase.CreateCoupon"
+	logger := rlog.With("fn", fn).With("code", input.Code).With("type", input.Type).With("currency", input.Currency)
+
+	coupon := &entities.Coupon{
+		Code:           input.Code,
+		Type:           input.Type,
+		ExpiresAt:      input.ExpiresAt,
+		MaxRedemptions: input.MaxRedemptions,
+	}
+
+	// percentages are stored in basis points, finer percentages are rejected
+	if input.PercentOff != 0 {
+		percentOffBPS, err := entities.PercentToBasisPoints(input.PercentOff)
+		if err != nil {
+			logger.Warn("percent off has too many decimals")
+			return "", dto.ErrInvalidCoupon
+		}
+		coupon.PercentOffBPS = percentOffBPS
+	}
+
+	// validate currency and convert amount to minor units
+	if input.Currency != "" {
+		supportedCurrencies, err := uc.fxService.GetSupportedCurrencies(ctx, time.Now())
+		if err != nil {
+			logger.Error("failed to get supported currencies")
+			return "", err
+		}
+		if !slices.Contains(supportedCurrencies, input.Currency) {
+			logger.Warn("currency not supported")
+			return "", dto.ErrCurrencyNotSupported
+		}
+
+		currencyMetadata, err := uc.fxService.GetCurrencyMetadata(ctx, input.Currency, time.Now())
+		if err != nil {
+			logger.Error("failed to get currency metadata")
+			return "", dto.ErrCurrencyMetadataNotFound
+		}
+		if !currencyMetadata.CanRepresent(input.AmountOff) {
+			logger.Warn("amount has too many decimals")
+			return "", dto.ErrAmountHasTooManyDecimals
+		}
+
+		coupon.Currency = input.Currency
+		coupon.CurrencyPrecision = currencyMetadata.Precision
+		coupon.AmountOffMinor = currencyMetadata.ToMinorUnits(input.AmountOff)
+	}
+
+	if err := coupon.Validate(); err != nil {
+		logger.Warn("coupon is invalid", "error", err)
+		return "", dto.ErrInvalidCoupon
+	}
+
+	// create coupon
+	_, err := uc.couponRepository.CreateCoupon(ctx, coupon)
+	if err != nil {
+		if errors.Is(err, entities.ErrCouponCodeTaken) {
+			logger.Warn("coupon code already exists")
+			return "", dto.ErrCouponCodeTaken
+		}
+
+		logger.Error("failed to create coupon in database", "error", err)
+		return "", dto.ErrFailedToCreateCouponInDatabase
+	}
+
+	logger.Info("coupon created successfully")
+
+	return coupon.Code, nil
+}
@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/google/uuid"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type CreateCreditNoteUsecase interface {
+	Execute(ctx context.Context, externalBillingID string, input dto.CreateCreditNoteInput) (*entities.CreditNote, error)
+}
+
+type createCreditNoteUseCase struct {
+	dbRepository         repositories.DBRepository
+	creditNoteRepository repositories.CreditNoteRepository
+}
+
+func NewCreateCreditNoteUseCase(dbRepository repositories.DBRepository, creditNoteRepository repositories.CreditNoteRepository) CreateCreditNoteUsecase {
+	return &createCreditNoteUseCase{dbRepository: dbRepository, creditNoteRepository: creditNoteRepository}
+}
+
+func (uc *createCreditNoteUseCase) Execute(ctx context.Context, externalBillingID string, input dto.CreateCreditNoteInput) (*entities.CreditNote, error) {
+	fn := "usecases.createCreditNoteUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("lineItems", len(input.LineItems))
+
+	// get billing
+	billing, err := uc.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return nil, dto.ErrFailedToGetBillingByExternalID
+	}
+
+	// validate billing is closed, open billings are corrected with adjustments
+	if !billing.CanCreditBilling() {
+		logger.Warn("billing is not closed")
+		return nil, dto.ErrBillingNotClosed
+	}
+
+	// get the summary the billing was closed with
+	summary, err := uc.dbRepository.GetBillingSummary(ctx, externalBillingID)
+	if err != nil {
+		logger.Error("failed to get billing summary", "error", err)
+		return nil, dto.ErrFailedToGetBillingSummary
+	}
+
+	creditNote := entities.CreditNote{
+		BillingID:         billing.ID,
+		ExternalBillingID: billing.ExternalBillingID,
+		Currency:          billing.Currency,
+		CurrencyPrecision: billing.CurrencyPrecision,
+		Reason:            input.Reason,
+	}
+	for _, lineItem := range input.LineItems {
+		if !billing.CanCreditItemWithAmount(lineItem.Amount) {
+			logger.Warn("amount has too many decimals")
+			return nil, dto.ErrAmountHasTooManyDecimals
+		}
+
+		// referenced line items must have been billed
+		if lineItem.ReferenceLineItemID != "" && !summaryHasLineItem(summary, lineItem.ReferenceLineItemID) {
+			logger.Warn("referenced line item not found", "referenceLineItemID", lineItem.ReferenceLineItemID)
+			return nil, dto.ErrReferencedLineItemNotFound
+		}
+
+		creditNote.LineItems = append(creditNote.LineItems, entities.CreditNoteLineItem{
+			Description:         lineItem.Description,
+			AmountMinor:         int64(lineItem.Amount * math.Pow10(int(billing.CurrencyPrecision))),
+			ReferenceLineItemID: lineItem.ReferenceLineItemID,
+		})
+	}
+
+	err = creditNote.Validate()
+	if err != nil {
+		logger.Warn("credit note is invalid", "error", err)
+		return nil, dto.ErrInvalidCreditNote
+	}
+
+	// generate external credit note ID
+	randomUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("failed to generate external credit note ID")
+		return nil, dto.ErrFailedToGenerateCreditNoteID
+	}
+	creditNote.ExternalCreditNoteID = randomUUID.String()
+
+	// create credit note, the repository checks it fits in what is left of the billing
+	_, err = uc.creditNoteRepository.CreateCreditNote(ctx, &creditNote, summary.BilledAmountMinor())
+	if err != nil {
+		if errors.Is(err, entities.ErrCreditNoteExceedsBalance) {
+			logger.Warn("credit note exceeds the remaining balance")
+			return nil, dto.ErrCreditNoteExceedsBalance
+		}
+
+		logger.Error("failed to create credit note in database", "error", err)
+		return nil, dto.ErrFailedToCreateCreditNoteInDatabase
+	}
+
+	logger.Info("credit note created successfully", "externalCreditNoteID", creditNote.ExternalCreditNoteID, "number", creditNote.Number)
+
+	return &creditNote, nil
+}
+
+func summaryHasLineItem(summary *entities.BillingSummary, lineItemID string) bool {
+	for _, lineItem := range summary.LineItems {
+		if lineItem.LineItemID == lineItemID {
+			return true
+		}
+	}
+	return false
+}
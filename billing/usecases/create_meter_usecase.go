@@ -0,0 +1,90 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type CreateMeterUseCase interface {
+	// Execute creates a meter priced in its currency and returns it
+	Execute(ctx context.Context, input dto.CreateMeterInput) (*entities.Meter, error)
+}
+
+type createMeterUseCase struct {
+	fxService services.FxService
+
+	meterRepository repositories.MeterRepository
+}
+
+func NewCreateMeterUseCase(fxService services.FxService, meterRepository repositories.MeterRepository) CreateMeterUseCase {
+	return &createMeterUseCase{
+		fxService:       fxService,
+		meterRepository: meterRepository,
+	}
+}
+
+func (uc *createMeterUseCase) Execute(ctx context.Context, input dto.CreateMeterInput) (*entities.Meter, error) {
+	fn := "usecases.createMeterUseCase.Execute"
+	logger := rlog.With("fn", fn).With("code", input.Code).With("aggregation", input.Aggregation).With("currency", input.Currency).With("unitAmount", input.UnitAmount)
+
+	// validate currency
+	supportedCurrencies, err := uc.fxService.GetSupportedCurrencies(ctx, time.Now())
+	if err != nil {
+		logger.Error("failed to get supported currencies", "error", err)
+		return nil, err
+	}
+	if !slices.Contains(supportedCurrencies, input.Currency) {
+		logger.Warn("currency not supported")
+		return nil, dto.ErrCurrencyNotSupported
+	}
+
+	// get currency precision
+	currencyMetadata, err := uc.fxService.GetCurrencyMetadata(ctx, input.Currency, time.Now())
+	if err != nil {
+		logger.Error("failed to get currency metadata", "error", err)
+		return nil, dto.ErrCurrencyMetadataNotFound
+	}
+	if !currencyMetadata.CanRepresent(input.UnitAmount) {
+		logger.Warn("unit amount has too many decimals")
+		return nil, dto.ErrAmountHasTooManyDecimals
+	}
+
+	meter := &entities.Meter{
+		Code:              input.Code,
+		Name:              input.Name,
+		Aggregation:       input.Aggregation,
+		Currency:          input.Currency,
+		CurrencyPrecision: currencyMetadata.Precision,
+		UnitAmountMinor:   currencyMetadata.ToMinorUnits(input.UnitAmount),
+	}
+	if err := meter.Validate(); err != nil {
+		logger.Warn("meter is invalid", "error", err)
+		return nil, dto.ErrInvalidMeter
+	}
+
+	// create meter
+	meterID, err := uc.meterRepository.CreateMeter(ctx, meter)
+	if err != nil {
+		if errors.Is(err, entities.ErrMeterCodeTaken) {
+			logger.Warn("meter code already exists")
+			return nil, dto.ErrMeterCodeTaken
+		}
+
+		logger.Error("failed to create meter in database", "error", err)
+		return nil, dto.ErrFailedToCreateMeter
+	}
+	meter.ID = meterID
+
+	logger.Info("meter created successfully", "meterID", meterID)
+
+	return meter, nil
+}
@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type createPlanUseCase struct {
+	fxService              services.FxService
+	subscriptionRepository repositories.SubscriptionRepository
+}
+
+type CreatePlanUsecase interface {
+	Execute(ctx context.Context, input dto.CreatePlanInput) (string, error)
+}
+
+func NewCreatePlanUseCase(fxService services.FxService, subscriptionRepository repositories.SubscriptionRepository) CreatePlanUsecase {
+	return &createPlanUseCase{
+		fxService:              fxService,
+		subscriptionRepository: subscriptionRepository,
+	}
+}
+
+func (uc *createPlanUseCase) Execute(ctx context.Context, input dto.CreatePlanInput) (string, error) {
+	fn := "createPlanUseCase.CreatePlan"
+	logger := rlog.With("fn", fn).With("name", input.Name).With("currency", input.Currency).With("amount", input.Amount).With("interval", input.Interval)
+
+	// validate currency
+	supportedCurrencies, err := uc.fxService.GetSupportedCurrencies(ctx, time.Now())
+	if err != nil {
+		logger.Error("failed to get supported currencies")
+		return "", err
+	}
+	if !slices.Contains(supportedCurrencies, input.Currency) {
+		logger.Warn("currency not supported")
+		return "", dto.ErrCurrencyNotSupported
+	}
+
+	// get currency precision
+	currencyMetadata, err := uc.fxService.GetCurrencyMetadata(ctx, input.Currency, time.Now())
+	if err != nil {
+		logger.Error("failed to get currency metadata")
+		return "", dto.ErrCurrencyMetadataNotFound
+	}
+	if !currencyMetadata.CanRepresent(input.Amount) {
+		logger.Warn("amount has too many decimals")
+		return "", dto.ErrAmountHasTooManyDecimals
+	}
+
+	// generate external plan ID
+	randomUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("failed to generate external plan ID")
+		return "", dto.ErrFailedToGenerateBillingID
+	}
+
+	intervalCount := input.IntervalCount
+	if intervalCount == 0 {
+		intervalCount = 1
+	}
+
+	plan := &entities.Plan{
+		ExternalPlanID:    randomUUID.String(),
+		Name:              input.Name,
+		Currency:          input.Currency,
+		CurrencyPrecision: currencyMetadata.Precision,
+		AmountMinor:       currencyMetadata.ToMinorUnits(input.Amount),
+		Interval:          input.Interval,
+		IntervalCount:     intervalCount,
+		TrialDays:         input.TrialDays,
+	}
+	if err := plan.Validate(); err != nil {
+		logger.Warn("plan is invalid", "error", err)
+		return "", dto.ErrInvalidPlan
+	}
+
+	// create plan
+	_, err = uc.subscriptionRepository.CreatePlan(ctx, plan)
+	if err != nil {
+		logger.Error("failed to create plan in database", "error", err)
+		return "", dto.ErrFailedToCreatePlanInDatabase
+	}
+
+	logger.Info("plan created successfully", "planID", plan.ExternalPlanID)
+
+	return plan.ExternalPlanID, nil
+}
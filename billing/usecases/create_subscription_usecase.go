@@ -0,0 +1,145 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+)
+
+type createSubscriptionUseCase struct {
+	subscriptionRepository repositories.SubscriptionRepository
+	billingWorkflow        ports.BillingWorkflow
+}
+
+type CreateSubscriptionUsecase interface {
+	Execute(ctx context.Context, userID string, externalPlanID string, timezone string) (string, error)
+}
+
+func NewCreateSubscriptionUseCase(subscriptionRepository repositories.SubscriptionRepository, billingWorkflow ports.BillingWorkflow) CreateSubscriptionUsecase {
+	return &createSubscriptionUseCase{
+		subscriptionRepository: subscriptionRepository,
+		billingWorkflow:        billingWorkflow,
+	}
+}
+
+func (uc *createSubscriptionUseCase) Execute(ctx context.Context, userID string, externalPlanID string, timezone string) (string, error) {
+	fn := "createSubscriptionUseCase.CreateSubscription"
+	logger := rlog.With("fn", fn).With("userID", userID).With("externalPlanID", externalPlanID).With("timezone", timezone)
+
+	// get plan
+	plan, err := uc.subscriptionRepository.GetPlanByExternalID(ctx, externalPlanID)
+	if err != nil {
+		if errors.Is(err, entities.ErrPlanNotFound) {
+			logger.Warn("plan not found")
+			return "", dto.ErrPlanNotFound
+		}
+
+		logger.Error("failed to get plan", "error", err)
+		return "", err
+	}
+
+	// validate timezone
+	loc, err := entities.LoadTimezone(timezone)
+	if err != nil {
+		logger.Warn("timezone is invalid")
+		return "", dto.ErrInvalidTimezone
+	}
+
+	// generate external IDs
+	subscriptionUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("failed to generate external subscription ID")
+		return "", dto.ErrFailedToGenerateSubscriptionID
+	}
+	billingUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("failed to generate external billing ID")
+		return "", dto.ErrFailedToGenerateBillingID
+	}
+
+	// the first period is the trial if the plan has one, regular periods are anchored on the end of the trial
+	now := time.Now().UTC()
+	subscription := &entities.Subscription{
+		ExternalSubscriptionID: subscriptionUUID.String(),
+		UserID:                 userID,
+		PlanID:                 plan.ID,
+		ExternalPlanID:         plan.ExternalPlanID,
+		Status:                 entities.SubscriptionStatusActive,
+		Timezone:               timezone,
+		AnchorAt:               now,
+	}
+	lineItem := plan.Charge()
+	if plan.TrialDays > 0 {
+		trialEndsAt := now.In(loc).AddDate(0, 0, int(plan.TrialDays)).UTC()
+		subscription.Status = entities.SubscriptionStatusTrial
+		subscription.TrialEndsAt = &trialEndsAt
+		subscription.AnchorAt = trialEndsAt
+		lineItem = plan.TrialCharge()
+	}
+
+	recurrence, err := plan.RecurrenceRule(subscription.AnchorAt, timezone)
+	if err != nil {
+		logger.Error("failed to get plan recurrence rule", "error", err)
+		return "", dto.ErrInvalidPlan
+	}
+
+	plannedClosedAt := subscription.AnchorAt
+	if subscription.TrialEndsAt == nil {
+		plannedClosedAt, err = recurrence.FirstClose(now)
+		if err != nil {
+			logger.Error("failed to compute first period", "error", err)
+			return "", dto.ErrInvalidRecurrenceRule
+		}
+	}
+
+	// create subscription
+	subscription.ID, err = uc.subscriptionRepository.CreateSubscription(ctx, subscription)
+	if err != nil {
+		logger.Error("failed to create subscription in database", "error", err)
+		return "", dto.ErrFailedToCreateSubscriptionInDatabase
+	}
+
+	// start the billing of the first period, the workflow rolls over into the following periods
+	err = uc.billingWorkflow.StartBilling(ctx, &entities.Billing{
+		ExternalBillingID: billingUUID.String(),
+		UserID:            userID,
+		Description:       plan.Name,
+		Currency:          plan.Currency,
+		CurrencyPrecision: plan.CurrencyPrecision,
+		PlannedClosedAt:   &plannedClosedAt,
+		PeriodStart:       &now,
+		PeriodEnd:         &plannedClosedAt,
+		Timezone:          timezone,
+		Recurrence:        recurrence,
+		SubscriptionID:    &subscription.ExternalSubscriptionID,
+	}, []entities.LineItem{lineItem})
+	if err != nil {
+		logger.Error("failed to start billing workflow", "error", err)
+
+		// a subscription without billing would never be charged, it is cancelled
+		cancelledAt := time.Now().UTC()
+		cancelErr := uc.subscriptionRepository.UpdateSubscriptionStatus(ctx, subscription.ID, subscription.Status, entities.SubscriptionStatusCancelled, &cancelledAt)
+		if cancelErr != nil {
+			logger.Error("failed to cancel subscription without billing", "error", cancelErr)
+		}
+		return "", dto.ErrFailedToStartBillingWorkflow
+	}
+
+	err = uc.subscriptionRepository.SetSubscriptionCurrentBilling(ctx, subscription.ID, billingUUID.String())
+	if err != nil {
+		logger.Error("failed to set subscription current billing", "error", err)
+		return "", dto.ErrFailedToUpdateSubscriptionInDatabase
+	}
+
+	logger.Info("subscription created successfully", "subscriptionID", subscription.ExternalSubscriptionID)
+
+	return subscription.ExternalSubscriptionID, nil
+}
@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type createWebhookUseCase struct {
+	webhookRepository repositories.WebhookRepository
+}
+
+// CreateWebhookUsecase subscribes a URL to billing event types
+type CreateWebhookUsecase interface {
+	Execute(ctx context.Context, input dto.CreateWebhookInput) (*entities.Webhook, error)
+}
+
+func NewCreateWebhookUseCase(webhookRepository repositories.WebhookRepository) CreateWebhookUsecase {
+	return &createWebhookUseCase{webhookRepository: webhookRepository}
+}
+
+func (uc *createWebhookUseCase) Execute(ctx context.Context, input dto.CreateWebhookInput) (*entities.Webhook, error) {
+	fn := "createWebhookUseCase.CreateWebhook"
+	logger := rlog.With("fn", fn).With("url", input.URL).With("eventTypes", input.EventTypes)
+
+	// generate webhook ID
+	randomUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("failed to generate webhook ID")
+		return nil, dto.ErrFailedToGenerateWebhookID
+	}
+
+	webhook := &entities.Webhook{
+		ExternalWebhookID: randomUUID.String(),
+		URL:               input.URL,
+		EventTypes:        input.EventTypes,
+		Secret:            input.Secret,
+	}
+	if err := webhook.Validate(); err != nil {
+		logger.Warn("webhook is invalid", "error", err)
+		return nil, dto.ErrInvalidWebhook
+	}
+
+	// create webhook
+	_, err = uc.webhookRepository.CreateWebhook(ctx, webhook)
+	if err != nil {
+		logger.Error("failed to create webhook in database", "error", err)
+		return nil, dto.ErrFailedToCreateWebhookInDatabase
+	}
+
+	logger.Info("webhook created successfully", "externalWebhookID", webhook.ExternalWebhookID)
+
+	return webhook, nil
+}
@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type CreditWalletUseCase interface {
+	// Execute tops up or adjusts the wallet of a user in a currency and returns the transaction with the balance it left
+	Execute(ctx context.Context, userID string, input dto.CreditWalletInput) (*entities.WalletTransaction, error)
+}
+
+type creditWalletUseCase struct {
+	fxService services.FxService
+
+	walletRepository repositories.WalletRepository
+}
+
+func NewCreditWalletUseCase(fxService services.FxService, walletRepository repositories.WalletRepository) CreditWalletUseCase {
+	return &creditWalletUseCase{
+		fxService:        fxService,
+		walletRepository: walletRepository,
+	}
+}
+
+func (uc *creditWalletUseCase) Execute(ctx context.Context, userID string, input dto.CreditWalletInput) (*entities.WalletTransaction, error) {
+	fn := "usecases.creditWalletUseCase.Execute"
+	logger := rlog.With("fn", fn).With("userID", userID).With("kind", input.Kind).With("currency", input.Currency).With("amount", input.Amount).With("externalReference", input.ExternalReference)
+
+	// validate currency
+	supportedCurrencies, err := uc.fxService.GetSupportedCurrencies(ctx, time.Now())
+	if err != nil {
+		logger.Error("failed to get supported currencies", "error", err)
+		return nil, err
+	}
+	if !slices.Contains(supportedCurrencies, input.Currency) {
+		logger.Warn("currency not supported")
+		return nil, dto.ErrCurrencyNotSupported
+	}
+
+	// get currency precision
+	currencyMetadata, err := uc.fxService.GetCurrencyMetadata(ctx, input.Currency, time.Now())
+	if err != nil {
+		logger.Error("failed to get currency metadata", "error", err)
+		return nil, dto.ErrCurrencyMetadataNotFound
+	}
+
+	transaction := entities.WalletTransaction{
+		UserID:            userID,
+		Currency:          input.Currency,
+		CurrencyPrecision: currencyMetadata.Precision,
+		Kind:              input.Kind,
+		AmountMinor:       int64(math.Round(input.Amount * math.Pow10(int(currencyMetadata.Precision)))),
+		Reason:            input.Reason,
+		ExternalReference: input.ExternalReference,
+	}
+	if !transaction.CanCreditWithAmount(input.Amount) {
+		logger.Warn("amount has too many decimals")
+		return nil, dto.ErrAmountHasTooManyDecimals
+	}
+	err = transaction.Validate()
+	if err != nil {
+		logger.Warn("wallet transaction is invalid", "error", err)
+		return nil, dto.ErrInvalidWalletTransaction
+	}
+
+	// generate external transaction ID
+	randomUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("failed to generate external wallet transaction ID")
+		return nil, dto.ErrFailedToGenerateWalletTransactionID
+	}
+	transaction.ExternalTransactionID = randomUUID.String()
+
+	// credit wallet, a reference recorded before returns the recorded transaction
+	err = uc.walletRepository.CreditWallet(ctx, &transaction)
+	if err != nil {
+		if errors.Is(err, entities.ErrInsufficientWalletBalance) {
+			logger.Warn("wallet balance is insufficient")
+			return nil, dto.ErrInsufficientWalletBalance
+		}
+
+		logger.Error("failed to credit wallet", "error", err)
+		return nil, dto.ErrFailedToCreditWallet
+	}
+
+	logger.Info("wallet credited successfully", "externalTransactionID", transaction.ExternalTransactionID, "balanceAfterMinor", transaction.BalanceAfterMinor)
+
+	return &transaction, nil
+}
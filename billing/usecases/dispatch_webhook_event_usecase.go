@@ -0,0 +1,78 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+)
+
+type dispatchWebhookEventUseCase struct {
+	webhookRepository       repositories.WebhookRepository
+	webhookDeliveryWorkflow ports.WebhookDeliveryWorkflow
+}
+
+// DispatchWebhookEventUsecase delivers a billing event to the webhooks subscribed to its type. Dispatching the same
+// event again delivers it once, so events received more than once are safe to dispatch.
+type DispatchWebhookEventUsecase interface {
+	Execute(ctx context.Context, eventID string, eventType entities.BillingEventType, payload []byte) error
+}
+
+func NewDispatchWebhookEventUseCase(webhookRepository repositories.WebhookRepository, webhookDeliveryWorkflow ports.WebhookDeliveryWorkflow) DispatchWebhookEventUsecase {
+	return &dispatchWebhookEventUseCase{webhookRepository: webhookRepository, webhookDeliveryWorkflow: webhookDeliveryWorkflow}
+}
+
+func (uc *dispatchWebhookEventUseCase) Execute(ctx context.Context, eventID string, eventType entities.BillingEventType, payload []byte) error {
+	fn := "dispatchWebhookEventUseCase.DispatchWebhookEvent"
+	logger := rlog.With("fn", fn).With("eventID", eventID).With("eventType", eventType)
+
+	// get webhooks subscribed to the event
+	webhooks, err := uc.webhookRepository.ListWebhooksByEventType(ctx, eventType)
+	if err != nil {
+		logger.Error("failed to list webhooks", "error", err)
+		return dto.ErrFailedToGetWebhook
+	}
+
+	for _, webhook := range webhooks {
+		// generate delivery ID, an event dispatched again keeps the ID of its first delivery
+		randomUUID, err := uuid.NewV7()
+		if err != nil {
+			logger.Error("failed to generate webhook delivery ID")
+			return dto.ErrFailedToCreateWebhookDelivery
+		}
+
+		delivery, err := uc.webhookRepository.CreateWebhookDelivery(ctx, &entities.WebhookDelivery{
+			ExternalDeliveryID: randomUUID.String(),
+			WebhookID:          webhook.ID,
+			ExternalWebhookID:  webhook.ExternalWebhookID,
+			EventID:            eventID,
+			EventType:          eventType,
+			Payload:            payload,
+		})
+		if err != nil {
+			logger.Error("failed to create webhook delivery", "externalWebhookID", webhook.ExternalWebhookID, "error", err)
+			return dto.ErrFailedToCreateWebhookDelivery
+		}
+		if delivery.Status != entities.WebhookDeliveryStatusPending {
+			logger.Info("webhook delivery already completed", "externalDeliveryID", delivery.ExternalDeliveryID, "status", delivery.Status)
+			continue
+		}
+
+		// deliver in the background
+		err = uc.webhookDeliveryWorkflow.DeliverWebhook(ctx, delivery.ExternalDeliveryID)
+		if err != nil {
+			logger.Error("failed to start webhook delivery", "externalDeliveryID", delivery.ExternalDeliveryID, "error", err)
+			return dto.ErrFailedToStartWebhookDeliveryInWorkflow
+		}
+	}
+
+	logger.Info("webhook event dispatched successfully", "webhooks", len(webhooks))
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package dto
+
+type AddAdjustmentInput struct {
+	Description string
+
+	// Amount is negative, it credits the billing
+	Amount float64
+	Reason string
+
+	// ReferenceLineItemID is the line item the adjustment corrects, empty for adjustments of the whole billing
+	ReferenceLineItemID string
+
+	// TaxCode defaults to the tax code of the referenced line item
+	TaxCode string
+}
@@ -0,0 +1,9 @@
+package dto
+
+type AddLineItemInput struct {
+	Description string
+	Amount      float64
+
+	// TaxCode selects the tax rate of the line item in the billing jurisdiction, empty means untaxed
+	TaxCode string
+}
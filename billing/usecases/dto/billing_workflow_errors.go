@@ -8,4 +8,6 @@ var (
 	ErrFailedToCloseBillingWorkflow         = errors.New("failed to close billing workflow")
 	ErrFailedToAddLineItemToBillingWorkflow = errors.New("failed to add line item to billing workflow")
 	ErrFailedToCloseBillingInWorkflow       = errors.New("failed to close billing in workflow")
+	ErrFailedToCancelBillingInWorkflow      = errors.New("failed to cancel billing in workflow")
+	ErrFailedToApplyCouponToBillingWorkflow = errors.New("failed to apply coupon to billing workflow")
 )
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+type CreateBillingInput struct {
+	UserID          string
+	Description     string
+	Currency        string
+	PlannedClosedAt *time.Time
+	Period          entities.BillingPeriod
+	Timezone        string
+	Recurrence      *entities.RecurrenceRule
+	TaxJurisdiction string
+	TaxInclusive    bool
+
+	// AllowNegativeTotal lets adjustments bring the billing total below zero
+	AllowNegativeTotal bool
+
+	// UserGroup selects the invoice number series of the billing
+	UserGroup string
+
+	// AutoCharge charges the user once the billing is closed
+	AutoCharge bool
+
+	// SpendLimit caps the billing total, nil for billings without a limit
+	SpendLimit *SpendLimitInput
+}
+
+type SpendLimitInput struct {
+	Amount float64
+
+	// Thresholds are the percentages of the amount alerted, defaults to 50, 80 and 100
+	Thresholds []int64
+
+	// Mode is alert, reject or close, defaults to alert
+	Mode entities.SpendLimitMode
+}
@@ -0,0 +1,17 @@
+package dto
+
+import (
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+type CreateCouponInput struct {
+	Code           string
+	Type           entities.CouponType
+	PercentOff     float64
+	AmountOff      float64
+	Currency       string
+	ExpiresAt      *time.Time
+	MaxRedemptions *int64
+}
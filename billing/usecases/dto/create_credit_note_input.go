@@ -0,0 +1,16 @@
+package dto
+
+type CreateCreditNoteInput struct {
+	Reason    string
+	LineItems []CreditNoteLineItemInput
+}
+
+type CreditNoteLineItemInput struct {
+	Description string
+
+	// Amount is positive and tax inclusive
+	Amount float64
+
+	// ReferenceLineItemID is the billed line item being refunded, optional
+	ReferenceLineItemID string
+}
@@ -0,0 +1,14 @@
+package dto
+
+type CreateMeterInput struct {
+	Code string
+	Name string
+
+	// Aggregation is how the usage of a billing period is aggregated, sum, max or last
+	Aggregation string
+
+	Currency string
+
+	// UnitAmount is the price of one unit of usage in the currency of the meter
+	UnitAmount float64
+}
@@ -0,0 +1,14 @@
+package dto
+
+import (
+	"encore.app/billing/domain/entities"
+)
+
+type CreatePlanInput struct {
+	Name          string
+	Currency      string
+	Amount        float64
+	Interval      entities.PlanInterval
+	IntervalCount int64
+	TrialDays     int64
+}
@@ -0,0 +1,11 @@
+package dto
+
+import (
+	"encore.app/billing/domain/entities"
+)
+
+type CreateWebhookInput struct {
+	URL        string
+	EventTypes []entities.BillingEventType
+	Secret     string
+}
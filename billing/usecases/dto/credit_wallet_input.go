@@ -0,0 +1,16 @@
+package dto
+
+type CreditWalletInput struct {
+	// Kind is a top-up or an adjustment
+	Kind     string
+	Currency string
+
+	// Amount is in the currency of the wallet, positive for top-ups and signed for adjustments
+	Amount float64
+
+	// Reason is required for adjustments
+	Reason string
+
+	// ExternalReference identifies a top-up at its provider, a top-up recorded again with it is recorded once
+	ExternalReference string
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+type GetAgingReportInput struct {
+	// AsOf is the day the report is as of, in UTC, it defaults to today and counts everything recorded until the end of the day
+	AsOf *time.Time
+
+	// PaymentTermsDays is how many days after closing a billing is due, billings are due on receipt by default
+	PaymentTermsDays int
+
+	// ReportingCurrency converts the report with the fx rates of the as of day, empty leaves it in billing currencies
+	ReportingCurrency string
+}
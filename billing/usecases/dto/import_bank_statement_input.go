@@ -0,0 +1,9 @@
+package dto
+
+type ImportBankStatementInput struct {
+	// Format is camt053 or csv
+	Format string
+
+	// Content is the statement file, a file imported again returns the first import
+	Content []byte
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"encore.app/billing/domain/entities"
+)
+
+type ProrateInput struct {
+	ChangeAt time.Time
+	Method   entities.ProrationMethod
+
+	OldDescription string
+	OldUnitAmount  float64
+	OldQuantity    int64
+
+	NewDescription string
+	NewUnitAmount  float64
+	NewQuantity    int64
+
+	// TaxCode is set on both line items
+	TaxCode string
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type RecordPaymentInput struct {
+	// Amount is positive, in the currency of the billing
+	Amount   float64
+	Currency string
+	Method   string
+
+	// ExternalReference identifies the payment at its provider, a payment recorded again with it is recorded once
+	ExternalReference string
+
+	// PaidAt defaults to now
+	PaidAt *time.Time
+}
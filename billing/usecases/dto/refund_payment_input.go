@@ -0,0 +1,13 @@
+package dto
+
+type RefundPaymentInput struct {
+	// Amount is positive, in the currency of the payment
+	Amount float64
+	Reason string
+
+	// IdempotencyKey identifies the refund request, a refund requested again with it is refunded once
+	IdempotencyKey string
+
+	// CreditNoteID is the credit note of the billing the refund settles, if any
+	CreditNoteID string
+}
@@ -0,0 +1,6 @@
+package dto
+
+type StartDunningInput struct {
+	// ScheduleDays are the days after the start on which the billing is reminded, defaults to day 1, 3 and 7
+	ScheduleDays []int
+}
@@ -7,6 +7,9 @@ var (
 	ErrCurrencyMetadataNotFound        = errors.New("currency metadata not found in FX service")
 	ErrFailedToCreateBillingInDatabase = errors.New("failed to create billing in database")
 	ErrFailedToGenerateBillingID       = errors.New("failed to generate billing ID")
+	ErrInvalidRecurrenceRule           = errors.New("invalid recurrence rule")
+	ErrInvalidBillingPeriod            = errors.New("invalid billing period")
+	ErrInvalidTimezone                 = errors.New("invalid timezone")
 
 	ErrBillingNotFound                = errors.New("billing not found")
 	ErrAmountHasTooManyDecimals       = errors.New("amount has too many decimals")
@@ -15,4 +18,158 @@ var (
 	ErrFailedToAddLineItemToDatabase  = errors.New("failed to add line item to database")
 
 	ErrFailedToCloseBillingInDatabase = errors.New("failed to close billing in database")
+
+	ErrInvalidPlan                          = errors.New("invalid plan")
+	ErrPlanNotFound                         = errors.New("plan not found")
+	ErrPlanCurrencyMismatch                 = errors.New("plan currency does not match subscription currency")
+	ErrFailedToCreatePlanInDatabase         = errors.New("failed to create plan in database")
+	ErrSubscriptionNotFound                 = errors.New("subscription not found")
+	ErrSubscriptionCancelled                = errors.New("subscription is cancelled")
+	ErrSubscriptionStatusConflict           = errors.New("subscription status changed concurrently")
+	ErrFailedToGenerateSubscriptionID       = errors.New("failed to generate subscription ID")
+	ErrFailedToCreateSubscriptionInDatabase = errors.New("failed to create subscription in database")
+	ErrFailedToUpdateSubscriptionInDatabase = errors.New("failed to update subscription in database")
+
+	ErrBillingHasNoPeriod  = errors.New("billing has no period end")
+	ErrInvalidProration    = errors.New("invalid proration")
+	ErrChangeOutsidePeriod = errors.New("change is outside of the billing period")
+
+	ErrInvalidTaxRate                 = errors.New("invalid tax rate")
+	ErrTaxRateNotFound                = errors.New("tax rate not found")
+	ErrBillingHasNoTaxJurisdiction    = errors.New("billing has no tax jurisdiction")
+	ErrFailedToGetTaxRate             = errors.New("failed to get tax rate")
+	ErrFailedToSetTaxRateInDatabase   = errors.New("failed to set tax rate in database")
+	ErrFailedToListTaxRatesInDatabase = errors.New("failed to list tax rates in database")
+
+	ErrInvalidCoupon                  = errors.New("invalid coupon")
+	ErrCouponNotFound                 = errors.New("coupon not found")
+	ErrCouponCodeTaken                = errors.New("coupon code already exists")
+	ErrCouponExpired                  = errors.New("coupon expired")
+	ErrCouponRedemptionLimitReached   = errors.New("coupon redemption limit reached")
+	ErrCouponCurrencyMismatch         = errors.New("coupon currency does not match billing currency")
+	ErrFailedToGetCoupon              = errors.New("failed to get coupon")
+	ErrFailedToCreateCouponInDatabase = errors.New("failed to create coupon in database")
+	ErrFailedToRedeemCouponInDatabase = errors.New("failed to redeem coupon in database")
+
+	ErrInvalidAdjustment               = errors.New("invalid adjustment")
+	ErrAdjustmentReasonRequired        = errors.New("adjustment reason is required")
+	ErrReferencedLineItemNotFound      = errors.New("referenced line item not found")
+	ErrAdjustmentExceedsReferencedItem = errors.New("adjustment exceeds referenced line item")
+	ErrNegativeBillingTotal            = errors.New("billing total cannot go below zero")
+	ErrFailedToGenerateLineItemID      = errors.New("failed to generate line item ID")
+	ErrFailedToGetBillingSummary       = errors.New("failed to get billing summary")
+
+	ErrBillingNotClosed                   = errors.New("billing is not closed")
+	ErrInvalidCreditNote                  = errors.New("invalid credit note")
+	ErrCreditNoteNotFound                 = errors.New("credit note not found")
+	ErrCreditNoteExceedsBalance           = errors.New("credit note exceeds the remaining balance of the billing")
+	ErrFailedToGenerateCreditNoteID       = errors.New("failed to generate credit note ID")
+	ErrFailedToCreateCreditNoteInDatabase = errors.New("failed to create credit note in database")
+	ErrFailedToGetCreditNote              = errors.New("failed to get credit note")
+	ErrFailedToListCreditNotesInDatabase  = errors.New("failed to list credit notes in database")
+	ErrFailedToRenderCreditNote           = errors.New("failed to render credit note")
+
+	ErrInvalidInvoiceNumberSeries                = errors.New("invalid invoice number series")
+	ErrInvoicePrefixTaken                        = errors.New("invoice number prefix is used by another user group")
+	ErrFailedToSetInvoiceNumberSeriesInDatabase  = errors.New("failed to set invoice number series in database")
+	ErrFailedToListInvoiceNumberSeriesInDatabase = errors.New("failed to list invoice number series in database")
+
+	ErrInvalidInvoiceFormat                 = errors.New("invalid invoice format")
+	ErrInvalidInvoiceTemplate               = errors.New("invalid invoice template")
+	ErrFailedToGetInvoiceTemplate           = errors.New("failed to get invoice template")
+	ErrFailedToSetInvoiceTemplateInDatabase = errors.New("failed to set invoice template in database")
+	ErrFailedToRenderInvoice                = errors.New("failed to render invoice")
+
+	ErrInvalidParty               = errors.New("invalid party")
+	ErrSellerNotFound             = errors.New("seller not found")
+	ErrBuyerNotFound              = errors.New("buyer not found")
+	ErrInvalidEInvoice            = errors.New("invalid e-invoice")
+	ErrFailedToGetParty           = errors.New("failed to get party")
+	ErrFailedToSetPartyInDatabase = errors.New("failed to set party in database")
+	ErrFailedToExportInvoice      = errors.New("failed to export invoice")
+
+	ErrInvalidExportFormat     = errors.New("invalid export format")
+	ErrInvalidExportFilter     = errors.New("invalid export filter")
+	ErrFailedToExportLineItems = errors.New("failed to export line items")
+
+	ErrInvalidWebhook                         = errors.New("invalid webhook")
+	ErrWebhookNotFound                        = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound                = errors.New("webhook delivery not found")
+	ErrWebhookDeliveryInProgress              = errors.New("webhook delivery is in progress")
+	ErrFailedToGenerateWebhookID              = errors.New("failed to generate webhook ID")
+	ErrFailedToCreateWebhookInDatabase        = errors.New("failed to create webhook in database")
+	ErrFailedToGetWebhook                     = errors.New("failed to get webhook")
+	ErrFailedToGetWebhookDelivery             = errors.New("failed to get webhook delivery")
+	ErrFailedToListWebhookDeliveries          = errors.New("failed to list webhook deliveries")
+	ErrFailedToCreateWebhookDelivery          = errors.New("failed to create webhook delivery")
+	ErrFailedToStartWebhookDeliveryInWorkflow = errors.New("failed to start webhook delivery in workflow")
+
+	ErrFailedToReplayOutbox = errors.New("failed to replay outbox")
+
+	ErrInvalidPayment                  = errors.New("invalid payment")
+	ErrPaymentCurrencyMismatch         = errors.New("payment currency does not match the billing currency")
+	ErrPaymentReferenceTaken           = errors.New("payment reference is recorded for another billing")
+	ErrFailedToGeneratePaymentID       = errors.New("failed to generate payment ID")
+	ErrFailedToCreatePaymentInDatabase = errors.New("failed to create payment in database")
+	ErrFailedToListPaymentsInDatabase  = errors.New("failed to list payments in database")
+
+	ErrInvalidDunningSchedule = errors.New("invalid dunning schedule")
+	ErrBillingAlreadyPaid     = errors.New("billing is already paid")
+	ErrDunningNotFound        = errors.New("dunning not found")
+	ErrDunningAlreadyStarted  = errors.New("dunning is already started")
+	ErrFailedToGetDunning     = errors.New("failed to get dunning")
+	ErrFailedToStartDunning   = errors.New("failed to start dunning")
+
+	ErrPaymentNotFound                = errors.New("payment not found")
+	ErrInvalidRefund                  = errors.New("invalid refund")
+	ErrRefundExceedsPayment           = errors.New("refund exceeds the refundable amount of the payment")
+	ErrRefundExceedsCreditNote        = errors.New("refund exceeds the unsettled amount of the credit note")
+	ErrRefundKeyReused                = errors.New("idempotency key was used for a different refund")
+	ErrFailedToGetPayment             = errors.New("failed to get payment")
+	ErrFailedToGenerateRefundID       = errors.New("failed to generate refund ID")
+	ErrFailedToCreateRefundInDatabase = errors.New("failed to create refund in database")
+	ErrFailedToListRefundsInDatabase  = errors.New("failed to list refunds in database")
+	ErrFailedToStartRefundInWorkflow  = errors.New("failed to start refund in workflow")
+
+	ErrInvalidBankStatement                  = errors.New("invalid bank statement")
+	ErrBankStatementNotFound                 = errors.New("bank statement not found")
+	ErrBankTransactionNotFound               = errors.New("bank transaction not found")
+	ErrBankTransactionReviewed               = errors.New("bank transaction is already reviewed")
+	ErrFailedToGetBankStatement              = errors.New("failed to get bank statement")
+	ErrFailedToGetBankTransaction            = errors.New("failed to get bank transaction")
+	ErrFailedToListOpenReceivables           = errors.New("failed to list open receivables")
+	ErrFailedToRecordBankTransactionPayment  = errors.New("failed to record bank transaction payment")
+	ErrFailedToGenerateBankStatementID       = errors.New("failed to generate bank statement ID")
+	ErrFailedToCreateBankStatementInDatabase = errors.New("failed to create bank statement in database")
+	ErrFailedToListBankTransactions          = errors.New("failed to list bank transactions")
+	ErrFailedToReviewBankTransaction         = errors.New("failed to review bank transaction")
+
+	ErrInvalidAgingReport = errors.New("invalid aging report")
+	ErrFailedToGetFxRates = errors.New("failed to get fx rates")
+	ErrFxRateNotFound     = errors.New("fx rate not found")
+
+	ErrFailedToListLedgerBalances = errors.New("failed to list ledger balances")
+	ErrFailedToListJournalEntries = errors.New("failed to list journal entries")
+	ErrFailedToCheckLedger        = errors.New("failed to check ledger")
+
+	ErrInvalidWalletTransaction            = errors.New("invalid wallet transaction")
+	ErrInsufficientWalletBalance           = errors.New("wallet balance is insufficient")
+	ErrWalletPaymentNotRefundable          = errors.New("payment from the wallet cannot be refunded")
+	ErrFailedToGenerateWalletTransactionID = errors.New("failed to generate wallet transaction ID")
+	ErrFailedToCreditWallet                = errors.New("failed to credit wallet")
+	ErrFailedToListWallets                 = errors.New("failed to list wallets")
+	ErrFailedToListWalletTransactions      = errors.New("failed to list wallet transactions")
+
+	ErrInvalidSpendLimit         = errors.New("invalid spend limit")
+	ErrSpendLimitExceeded        = errors.New("spend limit exceeded")
+	ErrBillingClosedAtSpendLimit = errors.New("spend limit exceeded, billing closed")
+
+	ErrInvalidMeter              = errors.New("invalid meter")
+	ErrMeterNotFound             = errors.New("meter not found")
+	ErrMeterCodeTaken            = errors.New("meter code already exists")
+	ErrInvalidUsageEvent         = errors.New("invalid usage event")
+	ErrTooManyUsageEvents        = errors.New("too many usage events")
+	ErrFailedToCreateMeter       = errors.New("failed to create meter")
+	ErrFailedToListMeters        = errors.New("failed to list meters")
+	ErrFailedToIngestUsageEvents = errors.New("failed to ingest usage events")
 )
@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"context"
+	"io"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type ExportLineItemsUseCase interface {
+	// Validate checks the format and filter, so that callers can reject an export before writing anything
+	Validate(format entities.ExportFormat, filter entities.ExportFilter) error
+
+	// Execute streams the line items of the billings matching filter to w, a row at a time
+	Execute(ctx context.Context, format entities.ExportFormat, filter entities.ExportFilter, w io.Writer) error
+}
+
+type exportLineItemsUseCase struct {
+	exportRepository repositories.ExportRepository
+	documentRenderer services.DocumentRenderer
+}
+
+func NewExportLineItemsUseCase(exportRepository repositories.ExportRepository, documentRenderer services.DocumentRenderer) ExportLineItemsUseCase {
+	return &exportLineItemsUseCase{
+		exportRepository: exportRepository,
+		documentRenderer: documentRenderer,
+	}
+}
+
+func (u *exportLineItemsUseCase) Validate(format entities.ExportFormat, filter entities.ExportFilter) error {
+	if !entities.IsValidExportFormat(format) {
+		return dto.ErrInvalidExportFormat
+	}
+	if err := filter.Validate(); err != nil {
+		return dto.ErrInvalidExportFilter
+	}
+	return nil
+}
+
+func (u *exportLineItemsUseCase) Execute(ctx context.Context, format entities.ExportFormat, filter entities.ExportFilter, w io.Writer) error {
+	fn := "usecases.exportLineItemsUseCase.Execute"
+	logger := rlog.With("fn", fn).With("format", format).With("from", filter.From).With("to", filter.To)
+
+	// validate export
+	if err := u.Validate(format, filter); err != nil {
+		logger.Warn("export is invalid", "error", err)
+		return err
+	}
+
+	writer, err := u.documentRenderer.NewExportWriter(w, format)
+	if err != nil {
+		logger.Error("failed to create export writer", "error", err)
+		return dto.ErrFailedToExportLineItems
+	}
+
+	// stream rows from the database into the writer
+	err = u.exportRepository.StreamExportRows(ctx, filter, writer.Write)
+	if err != nil {
+		logger.Error("failed to stream line items", "error", err)
+		return dto.ErrFailedToExportLineItems
+	}
+
+	if err = writer.Flush(); err != nil {
+		logger.Error("failed to flush export", "error", err)
+		return dto.ErrFailedToExportLineItems
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type ExportUBLInvoiceUseCase interface {
+	// Execute exports a closed billing as a UBL e-invoice from the seller of its tenant to the buyer of its user
+	Execute(ctx context.Context, externalBillingID string) (*entities.Invoice, []byte, error)
+}
+
+type exportUBLInvoiceUseCase struct {
+	dbRepository     repositories.DBRepository
+	partyRepository  repositories.PartyRepository
+	documentRenderer services.DocumentRenderer
+}
+
+func NewExportUBLInvoiceUseCase(dbRepository repositories.DBRepository, partyRepository repositories.PartyRepository, documentRenderer services.DocumentRenderer) ExportUBLInvoiceUseCase {
+	return &exportUBLInvoiceUseCase{
+		dbRepository:     dbRepository,
+		partyRepository:  partyRepository,
+		documentRenderer: documentRenderer,
+	}
+}
+
+func (u *exportUBLInvoiceUseCase) Execute(ctx context.Context, externalBillingID string) (*entities.Invoice, []byte, error) {
+	fn := "usecases.exportUBLInvoiceUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID)
+
+	// get billing
+	billing, err := u.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, nil, dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return nil, nil, dto.ErrFailedToGetBillingByExternalID
+	}
+
+	// open billings have no invoice yet
+	if !billing.CanInvoiceBilling() {
+		logger.Warn("billing is not closed")
+		return nil, nil, dto.ErrBillingNotClosed
+	}
+
+	summary, err := u.dbRepository.GetBillingSummary(ctx, externalBillingID)
+	if err != nil {
+		logger.Error("failed to get billing summary", "error", err)
+		return nil, nil, dto.ErrFailedToGetBillingSummary
+	}
+
+	// get seller of the tenant
+	seller, err := u.partyRepository.GetParty(ctx, entities.PartyRoleSeller, billing.UserGroup)
+	if err != nil {
+		if errors.Is(err, entities.ErrPartyNotFound) {
+			logger.Warn("seller not found", "tenant", billing.UserGroup)
+			return nil, nil, dto.ErrSellerNotFound
+		}
+
+		logger.Error("failed to get seller", "error", err)
+		return nil, nil, dto.ErrFailedToGetParty
+	}
+
+	// get buyer of the user
+	buyer, err := u.partyRepository.GetParty(ctx, entities.PartyRoleBuyer, billing.UserID)
+	if err != nil {
+		if errors.Is(err, entities.ErrPartyNotFound) {
+			logger.Warn("buyer not found", "userID", billing.UserID)
+			return nil, nil, dto.ErrBuyerNotFound
+		}
+
+		logger.Error("failed to get buyer", "error", err)
+		return nil, nil, dto.ErrFailedToGetParty
+	}
+
+	// export invoice, amounts are written as numbers so the billing precision is all the currency needed
+	invoice := entities.NewInvoice(billing, summary, entities.CurrencyMetadata{Code: billing.Currency, Precision: billing.CurrencyPrecision})
+	invoice.Seller = seller
+	invoice.Buyer = buyer
+	document, err := u.documentRenderer.RenderUBL(invoice)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidEInvoice) {
+			logger.Warn("invoice cannot be exported", "error", err)
+			return nil, nil, dto.ErrInvalidEInvoice
+		}
+
+		logger.Error("failed to export invoice", "error", err)
+		return nil, nil, dto.ErrFailedToExportInvoice
+	}
+
+	return &invoice, document, nil
+}
@@ -0,0 +1,111 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type GetAgingReportUsecase interface {
+	// Execute buckets what users owe on closed billings by days past due, per user and currency
+	Execute(ctx context.Context, input dto.GetAgingReportInput) (*entities.AgingReport, error)
+}
+
+type getAgingReportUseCase struct {
+	fxService services.FxService
+
+	receivableRepository repositories.ReceivableRepository
+}
+
+func NewGetAgingReportUseCase(fxService services.FxService, receivableRepository repositories.ReceivableRepository) GetAgingReportUsecase {
+	return &getAgingReportUseCase{
+		fxService:            fxService,
+		receivableRepository: receivableRepository,
+	}
+}
+
+func (uc *getAgingReportUseCase) Execute(ctx context.Context, input dto.GetAgingReportInput) (*entities.AgingReport, error) {
+	fn := "usecases.getAgingReportUseCase.Execute"
+	logger := rlog.With("fn", fn).With("asOf", input.AsOf).With("paymentTermsDays", input.PaymentTermsDays).With("reportingCurrency", input.ReportingCurrency)
+
+	// the report is as of a whole day in UTC, which cannot be in the future
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	asOf := today
+	if input.AsOf != nil {
+		asOf = input.AsOf.UTC().Truncate(24 * time.Hour)
+	}
+	if asOf.After(today) {
+		logger.Warn("as of is in the future")
+		return nil, dto.ErrInvalidAgingReport
+	}
+
+	if input.PaymentTermsDays < 0 || input.PaymentTermsDays > entities.MaxPaymentTermsDays {
+		logger.Warn("payment terms are invalid")
+		return nil, dto.ErrInvalidAgingReport
+	}
+
+	// everything recorded until the end of the day counts
+	receivables, err := uc.receivableRepository.ListOpenReceivablesAsOf(ctx, asOf.AddDate(0, 0, 1))
+	if err != nil {
+		logger.Error("failed to list open receivables", "error", err)
+		return nil, dto.ErrFailedToListOpenReceivables
+	}
+
+	report := entities.NewAgingReport(receivables, asOf, input.PaymentTermsDays)
+
+	if input.ReportingCurrency == "" {
+		return report, nil
+	}
+
+	if err = uc.convert(ctx, report, input.ReportingCurrency); err != nil {
+		logger.Error("failed to convert aging report", "error", err)
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// convert converts the report to the reporting currency with the rates of the as of day
+func (uc *getAgingReportUseCase) convert(ctx context.Context, report *entities.AgingReport, currency string) error {
+	logger := rlog.With("fn", "usecases.getAgingReportUseCase.convert").With("currency", currency)
+
+	supportedCurrencies, err := uc.fxService.GetSupportedCurrencies(ctx, report.AsOf)
+	if err != nil {
+		logger.Error("failed to get supported currencies", "error", err)
+		return err
+	}
+	if !slices.Contains(supportedCurrencies, currency) {
+		logger.Warn("currency not supported")
+		return dto.ErrCurrencyNotSupported
+	}
+
+	currencyMetadata, err := uc.fxService.GetCurrencyMetadata(ctx, currency, report.AsOf)
+	if err != nil {
+		logger.Error("failed to get currency metadata", "error", err)
+		return dto.ErrCurrencyMetadataNotFound
+	}
+
+	rates, err := uc.fxService.GetRates(ctx, report.AsOf)
+	if err != nil {
+		logger.Error("failed to get fx rates", "error", err)
+		return dto.ErrFailedToGetFxRates
+	}
+
+	if err = report.ConvertTo(currency, currencyMetadata.Precision, *rates); err != nil {
+		if errors.Is(err, entities.ErrFxRateNotFound) {
+			logger.Error("fx rate not found", "error", err)
+			return dto.ErrFxRateNotFound
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type GetBankStatementUseCase interface {
+	// Execute gets an imported statement with its transactions
+	Execute(ctx context.Context, externalStatementID string) (*entities.BankStatement, error)
+}
+
+type getBankStatementUseCase struct {
+	bankStatementRepository repositories.BankStatementRepository
+}
+
+func NewGetBankStatementUseCase(bankStatementRepository repositories.BankStatementRepository) GetBankStatementUseCase {
+	return &getBankStatementUseCase{bankStatementRepository: bankStatementRepository}
+}
+
+func (u *getBankStatementUseCase) Execute(ctx context.Context, externalStatementID string) (*entities.BankStatement, error) {
+	fn := "usecases.getBankStatementUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalStatementID", externalStatementID)
+
+	statement, err := u.bankStatementRepository.GetBankStatementByExternalID(ctx, externalStatementID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBankStatementNotFound) {
+			logger.Warn("bank statement not found")
+			return nil, dto.ErrBankStatementNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get bank statement", "error", err)
+		return nil, dto.ErrFailedToGetBankStatement
+	}
+
+	return statement, nil
+}
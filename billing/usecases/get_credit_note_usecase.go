@@ -0,0 +1,70 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type GetCreditNoteUseCase interface {
+	Execute(ctx context.Context, externalCreditNoteID string) (*entities.CreditNote, error)
+
+	// ExecutePDF renders the credit note as a PDF document
+	ExecutePDF(ctx context.Context, externalCreditNoteID string) (*entities.CreditNote, []byte, error)
+}
+
+type getCreditNoteUseCase struct {
+	creditNoteRepository repositories.CreditNoteRepository
+	documentRenderer     services.DocumentRenderer
+}
+
+func NewGetCreditNoteUseCase(creditNoteRepository repositories.CreditNoteRepository, documentRenderer services.DocumentRenderer) GetCreditNoteUseCase {
+	return &getCreditNoteUseCase{
+		creditNoteRepository: creditNoteRepository,
+		documentRenderer:     documentRenderer,
+	}
+}
+
+func (u *getCreditNoteUseCase) Execute(ctx context.Context, externalCreditNoteID string) (*entities.CreditNote, error) {
+	fn := "usecases.getCreditNoteUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalCreditNoteID", externalCreditNoteID)
+
+	// get credit note
+	creditNote, err := u.creditNoteRepository.GetCreditNoteByExternalID(ctx, externalCreditNoteID)
+	if err != nil {
+		if errors.Is(err, entities.ErrCreditNoteNotFound) {
+			logger.Warn("credit note not found")
+			return nil, dto.ErrCreditNoteNotFound
+		}
+
+		logger.Error("failed to get credit note", "error", err)
+		return nil, dto.ErrFailedToGetCreditNote
+	}
+
+	return creditNote, nil
+}
+
+func (u *getCreditNoteUseCase) ExecutePDF(ctx context.Context, externalCreditNoteID string) (*entities.CreditNote, []byte, error) {
+	fn := "usecases.getCreditNoteUseCase.ExecutePDF"
+	logger := rlog.With("fn", fn).With("externalCreditNoteID", externalCreditNoteID)
+
+	creditNote, err := u.Execute(ctx, externalCreditNoteID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// render credit note
+	pdf, err := u.documentRenderer.RenderCreditNotePDF(creditNote)
+	if err != nil {
+		logger.Error("failed to render credit note", "error", err)
+		return nil, nil, dto.ErrFailedToRenderCreditNote
+	}
+
+	return creditNote, pdf, nil
+}
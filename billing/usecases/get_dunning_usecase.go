@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type GetDunningUseCase interface {
+	// Execute gets the dunning of a billing with its status and current step
+	Execute(ctx context.Context, externalBillingID string) (*entities.Dunning, error)
+}
+
+type getDunningUseCase struct {
+	dbRepository      repositories.DBRepository
+	dunningRepository repositories.DunningRepository
+}
+
+func NewGetDunningUseCase(dbRepository repositories.DBRepository, dunningRepository repositories.DunningRepository) GetDunningUseCase {
+	return &getDunningUseCase{
+		dbRepository:      dbRepository,
+		dunningRepository: dunningRepository,
+	}
+}
+
+func (u *getDunningUseCase) Execute(ctx context.Context, externalBillingID string) (*entities.Dunning, error) {
+	fn := "usecases.getDunningUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID)
+
+	// get billing
+	billing, err := u.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return nil, dto.ErrFailedToGetBillingByExternalID
+	}
+
+	dunning, err := u.dunningRepository.GetDunningByBillingID(ctx, billing.ID)
+	if err != nil {
+		if errors.Is(err, entities.ErrDunningNotFound) {
+			logger.Warn("dunning not found")
+			return nil, dto.ErrDunningNotFound
+		}
+
+		logger.Error("failed to get dunning", "error", err)
+		return nil, dto.ErrFailedToGetDunning
+	}
+
+	return dunning, nil
+}
@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type GetLedgerBalancesUseCase interface {
+	// Execute lists the balance of every ledger account per currency, an empty currency lists all currencies
+	Execute(ctx context.Context, currency string) ([]entities.LedgerAccountBalance, error)
+}
+
+type getLedgerBalancesUseCase struct {
+	ledgerRepository repositories.LedgerRepository
+}
+
+func NewGetLedgerBalancesUseCase(ledgerRepository repositories.LedgerRepository) GetLedgerBalancesUseCase {
+	return &getLedgerBalancesUseCase{ledgerRepository: ledgerRepository}
+}
+
+func (u *getLedgerBalancesUseCase) Execute(ctx context.Context, currency string) ([]entities.LedgerAccountBalance, error) {
+	fn := "usecases.getLedgerBalancesUseCase.Execute"
+	logger := rlog.With("fn", fn).With("currency", currency)
+
+	balances, err := u.ledgerRepository.ListAccountBalances(ctx, currency)
+	if err != nil {
+		logger.Error("failed to list ledger balances", "error", err)
+		return nil, dto.ErrFailedToListLedgerBalances
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,44 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type GetSubscriptionUseCase interface {
+	Execute(ctx context.Context, externalSubscriptionID string) (*entities.Subscription, error)
+}
+
+type getSubscriptionUseCase struct {
+	subscriptionRepository repositories.SubscriptionRepository
+}
+
+func NewGetSubscriptionUseCase(subscriptionRepository repositories.SubscriptionRepository) GetSubscriptionUseCase {
+	return &getSubscriptionUseCase{
+		subscriptionRepository: subscriptionRepository,
+	}
+}
+
+func (u *getSubscriptionUseCase) Execute(ctx context.Context, externalSubscriptionID string) (*entities.Subscription, error) {
+	fn := "usecases.getSubscriptionUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalSubscriptionID", externalSubscriptionID)
+
+	subscription, err := u.subscriptionRepository.GetSubscriptionByExternalID(ctx, externalSubscriptionID)
+	if err != nil {
+		if errors.Is(err, entities.ErrSubscriptionNotFound) {
+			logger.Warn("subscription not found")
+			return nil, dto.ErrSubscriptionNotFound
+		}
+
+		logger.Error("failed to get subscription", "error", err)
+		return nil, err
+	}
+
+	return subscription, nil
+}
@@ -0,0 +1,276 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type ImportBankStatementUsecase interface {
+	// Execute imports a statement file, records its confident matches as payments and queues the other incoming transactions for review
+	Execute(ctx context.Context, input dto.ImportBankStatementInput) (*entities.BankStatement, error)
+}
+
+type importBankStatementUseCase struct {
+	fxService               services.FxService
+	bankStatementParser     services.BankStatementParser
+	bankStatementRepository repositories.BankStatementRepository
+	receivableRepository    repositories.ReceivableRepository
+	recordPaymentUsecase    RecordPaymentUsecase
+}
+
+func NewImportBankStatementUseCase(fxService services.FxService, bankStatementParser services.BankStatementParser, bankStatementRepository repositories.BankStatementRepository, receivableRepository repositories.ReceivableRepository, recordPaymentUsecase RecordPaymentUsecase) ImportBankStatementUsecase {
+	return &importBankStatementUseCase{
+		fxService:               fxService,
+		bankStatementParser:     bankStatementParser,
+		bankStatementRepository: bankStatementRepository,
+		receivableRepository:    receivableRepository,
+		recordPaymentUsecase:    recordPaymentUsecase,
+	}
+}
+
+func (uc *importBankStatementUseCase) Execute(ctx context.Context, input dto.ImportBankStatementInput) (*entities.BankStatement, error) {
+	fn := "usecases.importBankStatementUseCase.Execute"
+	logger := rlog.With("fn", fn).With("format", input.Format).With("size", len(input.Content))
+
+	// a file imported again returns the first import, so that its transactions are not matched twice. The payments an
+	// interrupted import did not record are recorded first.
+	hash := sha256.Sum256(input.Content)
+	contentHash := hex.EncodeToString(hash[:])
+	imported, err := uc.bankStatementRepository.GetBankStatementByContentHash(ctx, contentHash)
+	if err == nil {
+		logger.Info("bank statement already imported", "externalStatementID", imported.ExternalStatementID)
+		return uc.settleMatches(ctx, imported)
+	}
+	if !errors.Is(err, entities.ErrBankStatementNotFound) {
+		logger.Error("failed to get bank statement by content hash", "error", err)
+		return nil, dto.ErrFailedToGetBankStatement
+	}
+
+	parsed, err := uc.bankStatementParser.Parse(bytes.NewReader(input.Content), input.Format)
+	if err != nil {
+		logger.Warn("bank statement is invalid", "error", err)
+		return nil, dto.ErrInvalidBankStatement
+	}
+
+	// generate external statement ID
+	randomUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("failed to generate external statement ID")
+		return nil, dto.ErrFailedToGenerateBankStatementID
+	}
+	statement := entities.BankStatement{
+		ExternalStatementID: randomUUID.String(),
+		Format:              input.Format,
+		Reference:           parsed.Reference,
+		Account:             parsed.Account,
+		ContentHash:         contentHash,
+	}
+
+	receivables, err := uc.receivableRepository.ListOpenReceivables(ctx, "")
+	if err != nil {
+		logger.Error("failed to list open receivables", "error", err)
+		return nil, dto.ErrFailedToListOpenReceivables
+	}
+
+	precisions, err := uc.currencyPrecisions(ctx, parsed.Entries)
+	if err != nil {
+		return nil, err
+	}
+
+	// match every entry before anything is written, so that an invalid entry rejects the statement without any payment
+	for _, entry := range parsed.Entries {
+		amountMinor, err := entry.AmountMinor(precisions[entry.Currency])
+		if err != nil {
+			logger.Warn("bank statement amount is invalid", "bankReference", entry.BankReference, "amount", entry.Amount)
+			return nil, dto.ErrInvalidBankStatement
+		}
+
+		randomUUID, err := uuid.NewV7()
+		if err != nil {
+			logger.Error("failed to generate external transaction ID")
+			return nil, dto.ErrFailedToGenerateBankStatementID
+		}
+		transaction := entities.BankTransaction{
+			ExternalTransactionID: randomUUID.String(),
+			BankReference:         entry.BankReference,
+			BookingDate:           entry.BookingDate,
+			Direction:             entry.Direction,
+			AmountMinor:           amountMinor,
+			Currency:              entry.Currency,
+			CurrencyPrecision:     precisions[entry.Currency],
+			CounterpartyName:      entry.CounterpartyName,
+			RemittanceInfo:        entry.RemittanceInfo,
+		}
+
+		match := entities.MatchBankTransaction(transaction, receivables)
+		transaction.Status = match.Status
+		transaction.MatchRule = match.Rule
+		for _, candidate := range match.Candidates {
+			transaction.CandidateBillingIDs = append(transaction.CandidateBillingIDs, candidate.BillingID)
+		}
+
+		if match.Status == entities.BankTransactionStatusMatched {
+			// the payment is recorded once the statement is stored
+			transaction.BillingID = &match.Receivable.BillingID
+
+			// the receivable owes less for the next transactions of the statement
+			receivables = payReceivable(receivables, match.Receivable.BillingID, transaction)
+		}
+
+		statement.Transactions = append(statement.Transactions, transaction)
+	}
+
+	// store the statement before its payments, so that an import interrupted in between is resumed when imported again
+	created, err := uc.bankStatementRepository.CreateBankStatement(ctx, &statement)
+	if err != nil {
+		logger.Error("failed to create bank statement in database", "error", err)
+		return nil, dto.ErrFailedToCreateBankStatementInDatabase
+	}
+
+	created, err = uc.settleMatches(ctx, created)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("bank statement imported successfully", "externalStatementID", created.ExternalStatementID, "transactions", len(created.Transactions))
+
+	return created, nil
+}
+
+// settleMatches records the payments of the matched transactions of a stored statement that have none yet and returns the
+// statement with them
+func (uc *importBankStatementUseCase) settleMatches(ctx context.Context, statement *entities.BankStatement) (*entities.BankStatement, error) {
+	logger := rlog.With("fn", "usecases.importBankStatementUseCase.settleMatches").With("externalStatementID", statement.ExternalStatementID)
+
+	settled := 0
+	for i := range statement.Transactions {
+		transaction := &statement.Transactions[i]
+		if transaction.Status != entities.BankTransactionStatusMatched || transaction.PaymentID != nil || transaction.ExternalBillingID == nil {
+			continue
+		}
+
+		err := uc.recordMatch(ctx, transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		err = uc.bankStatementRepository.SettleBankTransaction(ctx, transaction)
+		if err != nil {
+			logger.Error("failed to settle bank transaction", "bankReference", transaction.BankReference, "error", err)
+			return nil, dto.ErrFailedToRecordBankTransactionPayment
+		}
+		settled++
+	}
+	if settled == 0 {
+		return statement, nil
+	}
+
+	logger.Info("matched bank transactions settled", "settled", settled)
+
+	statement, err := uc.bankStatementRepository.GetBankStatementByExternalID(ctx, statement.ExternalStatementID)
+	if err != nil {
+		logger.Error("failed to get bank statement", "error", err)
+		return nil, dto.ErrFailedToGetBankStatement
+	}
+	return statement, nil
+}
+
+// recordMatch records a confidently matched transaction as a bank transfer paying its billing. A payment that cannot be
+// recorded, e.g. because its reference already paid another billing, leaves the transaction for review.
+func (uc *importBankStatementUseCase) recordMatch(ctx context.Context, transaction *entities.BankTransaction) error {
+	logger := rlog.With("fn", "usecases.importBankStatementUseCase.recordMatch").With("bankReference", transaction.BankReference).With("externalBillingID", *transaction.ExternalBillingID)
+
+	// the payment reference is the bank reference, so that a transaction recorded again is recorded once
+	payment, _, err := uc.recordPaymentUsecase.Execute(ctx, *transaction.ExternalBillingID, dto.RecordPaymentInput{
+		Amount:            float64(transaction.AmountMinor) / math.Pow10(int(transaction.CurrencyPrecision)),
+		Currency:          transaction.Currency,
+		Method:            entities.PaymentMethodBankTransfer,
+		ExternalReference: transaction.BankReference,
+		PaidAt:            &transaction.BookingDate,
+	})
+	if err != nil {
+		if isPaymentRejected(err) {
+			logger.Warn("matched payment is rejected, transaction is left for review", "error", err)
+			transaction.Status = entities.BankTransactionStatusNeedsReview
+			transaction.MatchRule = ""
+			transaction.CandidateBillingIDs = []int64{*transaction.BillingID}
+			transaction.BillingID = nil
+			return nil
+		}
+
+		logger.Error("failed to record matched payment", "error", err)
+		return dto.ErrFailedToRecordBankTransactionPayment
+	}
+
+	transaction.BillingID = &payment.BillingID
+	transaction.PaymentID = &payment.ID
+	return nil
+}
+
+// currencyPrecisions gets the precision of the currencies of the entries, a statement in a currency that cannot be billed is rejected
+func (uc *importBankStatementUseCase) currencyPrecisions(ctx context.Context, entries []entities.BankStatementEntry) (map[string]int64, error) {
+	logger := rlog.With("fn", "usecases.importBankStatementUseCase.currencyPrecisions")
+
+	supportedCurrencies, err := uc.fxService.GetSupportedCurrencies(ctx, time.Now())
+	if err != nil {
+		logger.Error("failed to get supported currencies", "error", err)
+		return nil, err
+	}
+
+	precisions := map[string]int64{}
+	for _, entry := range entries {
+		if _, ok := precisions[entry.Currency]; ok {
+			continue
+		}
+		if !slices.Contains(supportedCurrencies, entry.Currency) {
+			logger.Warn("currency not supported", "currency", entry.Currency)
+			return nil, dto.ErrCurrencyNotSupported
+		}
+
+		currencyMetadata, err := uc.fxService.GetCurrencyMetadata(ctx, entry.Currency, time.Now())
+		if err != nil {
+			logger.Error("failed to get currency metadata", "currency", entry.Currency)
+			return nil, dto.ErrCurrencyMetadataNotFound
+		}
+		precisions[entry.Currency] = currencyMetadata.Precision
+	}
+
+	return precisions, nil
+}
+
+// payReceivable lowers what a receivable owes by a transaction, a receivable that is paid is no longer open
+func payReceivable(receivables []entities.Receivable, billingID int64, transaction entities.BankTransaction) []entities.Receivable {
+	open := make([]entities.Receivable, 0, len(receivables))
+	for _, receivable := range receivables {
+		if receivable.BillingID == billingID {
+			receivable.PaidAmountMinor += transaction.AmountMinor
+			receivable.OutstandingAmountMinor -= transaction.AmountMinor
+			if receivable.OutstandingAmountMinor <= 0 {
+				continue
+			}
+		}
+		open = append(open, receivable)
+	}
+	return open
+}
+
+// isPaymentRejected reports whether recording a payment failed on the payment itself rather than on the service
+func isPaymentRejected(err error) bool {
+	return errors.Is(err, dto.ErrBillingNotFound) || errors.Is(err, dto.ErrBillingNotClosed) || errors.Is(err, dto.ErrPaymentCurrencyMismatch) ||
+		errors.Is(err, dto.ErrAmountHasTooManyDecimals) || errors.Is(err, dto.ErrInvalidPayment) || errors.Is(err, dto.ErrPaymentReferenceTaken)
+}
@@ -0,0 +1,76 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type IngestUsageEventsUseCase interface {
+	// Execute stores a batch of usage events and returns how many were new, the usage is billed when the billings of
+	// their users close. A batch with an invalid event or an unknown meter is rejected as a whole.
+	Execute(ctx context.Context, events []entities.UsageEvent) (int, error)
+}
+
+type ingestUsageEventsUseCase struct {
+	meterRepository repositories.MeterRepository
+}
+
+func NewIngestUsageEventsUseCase(meterRepository repositories.MeterRepository) IngestUsageEventsUseCase {
+	return &ingestUsageEventsUseCase{meterRepository: meterRepository}
+}
+
+func (uc *ingestUsageEventsUseCase) Execute(ctx context.Context, events []entities.UsageEvent) (int, error) {
+	fn := "usecases.ingestUsageEventsUseCase.Execute"
+	logger := rlog.With("fn", fn).With("events", len(events))
+
+	// validate batch
+	if len(events) == 0 {
+		logger.Warn("no usage events")
+		return 0, dto.ErrInvalidUsageEvent
+	}
+	if len(events) > entities.UsageEventsMaxBatchSize {
+		logger.Warn("too many usage events")
+		return 0, dto.ErrTooManyUsageEvents
+	}
+	for i := range events {
+		if err := events[i].Validate(); err != nil {
+			logger.Warn("usage event is invalid", "index", i, "error", err)
+			return 0, dto.ErrInvalidUsageEvent
+		}
+	}
+
+	// resolve meters
+	meters, err := uc.meterRepository.ListMeters(ctx)
+	if err != nil {
+		logger.Error("failed to list meters", "error", err)
+		return 0, dto.ErrFailedToListMeters
+	}
+	meterIDs := make(map[string]int64, len(meters))
+	for _, meter := range meters {
+		meterIDs[meter.Code] = meter.ID
+	}
+	for i := range events {
+		meterID, ok := meterIDs[events[i].MeterCode]
+		if !ok {
+			logger.Warn("meter not found", "meter", events[i].MeterCode)
+			return 0, dto.ErrMeterNotFound
+		}
+		events[i].MeterID = meterID
+	}
+
+	// ingest usage events, events already ingested are skipped
+	ingested, err := uc.meterRepository.IngestUsageEvents(ctx, events)
+	if err != nil {
+		logger.Error("failed to ingest usage events", "error", err)
+		return 0, dto.ErrFailedToIngestUsageEvents
+	}
+
+	logger.Info("usage events ingested successfully", "ingested", ingested, "duplicates", len(events)-ingested)
+
+	return ingested, nil
+}
@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ListBankTransactionsUseCase interface {
+	// Execute lists the imported transactions in a status, oldest first, e.g. the needs_review queue
+	Execute(ctx context.Context, status entities.BankTransactionStatus, limit int) ([]entities.BankTransaction, error)
+}
+
+type listBankTransactionsUseCase struct {
+	bankStatementRepository repositories.BankStatementRepository
+}
+
+func NewListBankTransactionsUseCase(bankStatementRepository repositories.BankStatementRepository) ListBankTransactionsUseCase {
+	return &listBankTransactionsUseCase{bankStatementRepository: bankStatementRepository}
+}
+
+func (u *listBankTransactionsUseCase) Execute(ctx context.Context, status entities.BankTransactionStatus, limit int) ([]entities.BankTransaction, error) {
+	fn := "usecases.listBankTransactionsUseCase.Execute"
+	logger := rlog.With("fn", fn).With("status", status).With("limit", limit)
+
+	transactions, err := u.bankStatementRepository.ListBankTransactionsByStatus(ctx, status, limit)
+	if err != nil {
+		logger.Error("failed to list bank transactions", "error", err)
+		return nil, dto.ErrFailedToListBankTransactions
+	}
+
+	return transactions, nil
+}
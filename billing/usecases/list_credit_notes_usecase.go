@@ -0,0 +1,73 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ListCreditNotesUseCase interface {
+	// Execute lists the credit notes of a closed billing with its balance net of them
+	Execute(ctx context.Context, externalBillingID string) ([]entities.CreditNote, entities.CreditedBalance, error)
+}
+
+type listCreditNotesUseCase struct {
+	dbRepository         repositories.DBRepository
+	creditNoteRepository repositories.CreditNoteRepository
+}
+
+func NewListCreditNotesUseCase(dbRepository repositories.DBRepository, creditNoteRepository repositories.CreditNoteRepository) ListCreditNotesUseCase {
+	return &listCreditNotesUseCase{
+		dbRepository:         dbRepository,
+		creditNoteRepository: creditNoteRepository,
+	}
+}
+
+func (u *listCreditNotesUseCase) Execute(ctx context.Context, externalBillingID string) ([]entities.CreditNote, entities.CreditedBalance, error) {
+	fn := "usecases.listCreditNotesUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID)
+
+	// get billing
+	billing, err := u.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, entities.CreditedBalance{}, dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return nil, entities.CreditedBalance{}, dto.ErrFailedToGetBillingByExternalID
+	}
+
+	// open billings have no billed amount yet
+	if !billing.CanCreditBilling() {
+		logger.Warn("billing is not closed")
+		return nil, entities.CreditedBalance{}, dto.ErrBillingNotClosed
+	}
+
+	summary, err := u.dbRepository.GetBillingSummary(ctx, externalBillingID)
+	if err != nil {
+		logger.Error("failed to get billing summary", "error", err)
+		return nil, entities.CreditedBalance{}, dto.ErrFailedToGetBillingSummary
+	}
+
+	// list credit notes
+	creditNotes, err := u.creditNoteRepository.ListCreditNotesByBillingID(ctx, billing.ID)
+	if err != nil {
+		logger.Error("failed to list credit notes", "error", err)
+		return nil, entities.CreditedBalance{}, dto.ErrFailedToListCreditNotesInDatabase
+	}
+
+	var creditedAmountMinor int64
+	for _, creditNote := range creditNotes {
+		creditedAmountMinor += creditNote.TotalAmountMinor
+	}
+
+	return creditNotes, entities.NewCreditedBalance(summary.BilledAmountMinor(), creditedAmountMinor), nil
+}
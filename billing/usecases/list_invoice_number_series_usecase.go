@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ListInvoiceNumberSeriesUseCase interface {
+	Execute(ctx context.Context) ([]entities.InvoiceNumberSeries, error)
+}
+
+type listInvoiceNumberSeriesUseCase struct {
+	invoiceNumberRepository repositories.InvoiceNumberRepository
+}
+
+func NewListInvoiceNumberSeriesUseCase(invoiceNumberRepository repositories.InvoiceNumberRepository) ListInvoiceNumberSeriesUseCase {
+	return &listInvoiceNumberSeriesUseCase{
+		invoiceNumberRepository: invoiceNumberRepository,
+	}
+}
+
+func (u *listInvoiceNumberSeriesUseCase) Execute(ctx context.Context) ([]entities.InvoiceNumberSeries, error) {
+	fn := "usecases.listInvoiceNumberSeriesUseCase.Execute"
+	logger := rlog.With("fn", fn)
+
+	// list invoice number series
+	seriesList, err := u.invoiceNumberRepository.ListInvoiceNumberSeries(ctx)
+	if err != nil {
+		logger.Error("failed to list invoice number series", "error", err)
+		return nil, dto.ErrFailedToListInvoiceNumberSeriesInDatabase
+	}
+
+	return seriesList, nil
+}
@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ListJournalEntriesUseCase interface {
+	// Execute lists the journal entries posted for a billing, oldest posted first
+	Execute(ctx context.Context, externalBillingID string) ([]entities.JournalEntry, error)
+}
+
+type listJournalEntriesUseCase struct {
+	dbRepository     repositories.DBRepository
+	ledgerRepository repositories.LedgerRepository
+}
+
+func NewListJournalEntriesUseCase(dbRepository repositories.DBRepository, ledgerRepository repositories.LedgerRepository) ListJournalEntriesUseCase {
+	return &listJournalEntriesUseCase{
+		dbRepository:     dbRepository,
+		ledgerRepository: ledgerRepository,
+	}
+}
+
+func (u *listJournalEntriesUseCase) Execute(ctx context.Context, externalBillingID string) ([]entities.JournalEntry, error) {
+	fn := "usecases.listJournalEntriesUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID)
+
+	// get billing
+	billing, err := u.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return nil, dto.ErrFailedToGetBillingByExternalID
+	}
+
+	entries, err := u.ledgerRepository.ListJournalEntriesByBillingID(ctx, billing.ID)
+	if err != nil {
+		logger.Error("failed to list journal entries", "error", err)
+		return nil, dto.ErrFailedToListJournalEntries
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ListMetersUseCase interface {
+	// Execute lists the meters usage can be reported for
+	Execute(ctx context.Context) ([]entities.Meter, error)
+}
+
+type listMetersUseCase struct {
+	meterRepository repositories.MeterRepository
+}
+
+func NewListMetersUseCase(meterRepository repositories.MeterRepository) ListMetersUseCase {
+	return &listMetersUseCase{meterRepository: meterRepository}
+}
+
+func (u *listMetersUseCase) Execute(ctx context.Context) ([]entities.Meter, error) {
+	fn := "usecases.listMetersUseCase.Execute"
+	logger := rlog.With("fn", fn)
+
+	meters, err := u.meterRepository.ListMeters(ctx)
+	if err != nil {
+		logger.Error("failed to list meters", "error", err)
+		return nil, dto.ErrFailedToListMeters
+	}
+
+	return meters, nil
+}
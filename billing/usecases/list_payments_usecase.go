@@ -0,0 +1,91 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ListPaymentsUseCase interface {
+	// Execute lists the payments of a closed billing with its payment balance
+	Execute(ctx context.Context, externalBillingID string) ([]entities.Payment, entities.PaymentBalance, error)
+}
+
+type listPaymentsUseCase struct {
+	dbRepository         repositories.DBRepository
+	creditNoteRepository repositories.CreditNoteRepository
+	paymentRepository    repositories.PaymentRepository
+}
+
+func NewListPaymentsUseCase(dbRepository repositories.DBRepository, creditNoteRepository repositories.CreditNoteRepository, paymentRepository repositories.PaymentRepository) ListPaymentsUseCase {
+	return &listPaymentsUseCase{
+		dbRepository:         dbRepository,
+		creditNoteRepository: creditNoteRepository,
+		paymentRepository:    paymentRepository,
+	}
+}
+
+func (u *listPaymentsUseCase) Execute(ctx context.Context, externalBillingID string) ([]entities.Payment, entities.PaymentBalance, error) {
+	fn := "usecases.listPaymentsUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID)
+
+	// get billing
+	billing, err := u.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, entities.PaymentBalance{}, dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return nil, entities.PaymentBalance{}, dto.ErrFailedToGetBillingByExternalID
+	}
+
+	// open billings are not owed yet
+	if !billing.CanPayBilling() {
+		logger.Warn("billing is not closed")
+		return nil, entities.PaymentBalance{}, dto.ErrBillingNotClosed
+	}
+
+	return getPayments(ctx, u.dbRepository, u.creditNoteRepository, u.paymentRepository, billing)
+}
+
+// getPayments lists the payments of a closed billing with its balance, what is due is the grand total net of credit notes
+func getPayments(ctx context.Context, dbRepository repositories.DBRepository, creditNoteRepository repositories.CreditNoteRepository, paymentRepository repositories.PaymentRepository, billing *entities.Billing) ([]entities.Payment, entities.PaymentBalance, error) {
+	logger := rlog.With("fn", "usecases.getPayments").With("externalBillingID", billing.ExternalBillingID)
+
+	summary, err := dbRepository.GetBillingSummary(ctx, billing.ExternalBillingID)
+	if err != nil {
+		logger.Error("failed to get billing summary", "error", err)
+		return nil, entities.PaymentBalance{}, dto.ErrFailedToGetBillingSummary
+	}
+
+	creditNotes, err := creditNoteRepository.ListCreditNotesByBillingID(ctx, billing.ID)
+	if err != nil {
+		logger.Error("failed to list credit notes", "error", err)
+		return nil, entities.PaymentBalance{}, dto.ErrFailedToListCreditNotesInDatabase
+	}
+	var creditedAmountMinor int64
+	for _, creditNote := range creditNotes {
+		creditedAmountMinor += creditNote.TotalAmountMinor
+	}
+
+	payments, err := paymentRepository.ListPaymentsByBillingID(ctx, billing.ID)
+	if err != nil {
+		logger.Error("failed to list payments", "error", err)
+		return nil, entities.PaymentBalance{}, dto.ErrFailedToListPaymentsInDatabase
+	}
+	var paidAmountMinor int64
+	for _, payment := range payments {
+		paidAmountMinor += payment.NetAmountMinor()
+	}
+
+	dueAmountMinor := entities.NewCreditedBalance(summary.BilledAmountMinor(), creditedAmountMinor).NetAmountMinor
+	return payments, entities.NewPaymentBalance(dueAmountMinor, paidAmountMinor), nil
+}
@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ListRefundsUseCase interface {
+	// Execute lists the refunds of a payment, oldest first
+	Execute(ctx context.Context, externalPaymentID string) ([]entities.Refund, error)
+}
+
+type listRefundsUseCase struct {
+	paymentRepository repositories.PaymentRepository
+	refundRepository  repositories.RefundRepository
+}
+
+func NewListRefundsUseCase(paymentRepository repositories.PaymentRepository, refundRepository repositories.RefundRepository) ListRefundsUseCase {
+	return &listRefundsUseCase{
+		paymentRepository: paymentRepository,
+		refundRepository:  refundRepository,
+	}
+}
+
+func (u *listRefundsUseCase) Execute(ctx context.Context, externalPaymentID string) ([]entities.Refund, error) {
+	fn := "usecases.listRefundsUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalPaymentID", externalPaymentID)
+
+	// get payment
+	payment, err := u.paymentRepository.GetPaymentByExternalID(ctx, externalPaymentID)
+	if err != nil {
+		if errors.Is(err, entities.ErrPaymentNotFound) {
+			logger.Warn("payment not found")
+			return nil, dto.ErrPaymentNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get payment by external ID", "error", err)
+		return nil, dto.ErrFailedToGetPayment
+	}
+
+	refunds, err := u.refundRepository.ListRefundsByPaymentID(ctx, payment.ID)
+	if err != nil {
+		logger.Error("failed to list refunds in database", "error", err)
+		return nil, dto.ErrFailedToListRefundsInDatabase
+	}
+
+	return refunds, nil
+}
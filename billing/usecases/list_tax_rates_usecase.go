@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ListTaxRatesUseCase interface {
+	Execute(ctx context.Context, jurisdiction string) ([]entities.TaxRate, error)
+}
+
+type listTaxRatesUseCase struct {
+	taxRepository repositories.TaxRepository
+}
+
+func NewListTaxRatesUseCase(taxRepository repositories.TaxRepository) ListTaxRatesUseCase {
+	return &listTaxRatesUseCase{
+		taxRepository: taxRepository,
+	}
+}
+
+func (u *listTaxRatesUseCase) Execute(ctx context.Context, jurisdiction string) ([]entities.TaxRate, error) {
+	fn := "usecases.listTaxRatesUseCase.Execute"
+	logger := rlog.With("fn", fn).With("jurisdiction", jurisdiction)
+
+	// list tax rates
+	taxRates, err := u.taxRepository.ListTaxRates(ctx, jurisdiction)
+	if err != nil {
+		logger.Error("failed to list tax rates", "error", err)
+		return nil, dto.ErrFailedToListTaxRatesInDatabase
+	}
+
+	return taxRates, nil
+}
@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ListWalletTransactionsUseCase interface {
+	// Execute lists the top-ups, adjustments and billing draws of the wallet of a user in a currency, oldest first
+	Execute(ctx context.Context, userID string, currency string) ([]entities.WalletTransaction, error)
+}
+
+type listWalletTransactionsUseCase struct {
+	walletRepository repositories.WalletRepository
+}
+
+func NewListWalletTransactionsUseCase(walletRepository repositories.WalletRepository) ListWalletTransactionsUseCase {
+	return &listWalletTransactionsUseCase{walletRepository: walletRepository}
+}
+
+func (u *listWalletTransactionsUseCase) Execute(ctx context.Context, userID string, currency string) ([]entities.WalletTransaction, error) {
+	fn := "usecases.listWalletTransactionsUseCase.Execute"
+	logger := rlog.With("fn", fn).With("userID", userID).With("currency", currency)
+
+	transactions, err := u.walletRepository.ListWalletTransactions(ctx, userID, currency)
+	if err != nil {
+		logger.Error("failed to list wallet transactions", "error", err)
+		return nil, dto.ErrFailedToListWalletTransactions
+	}
+
+	return transactions, nil
+}
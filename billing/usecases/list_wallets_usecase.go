@@ -0,0 +1,37 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ListWalletsUseCase interface {
+	// Execute lists the wallets of a user with their balances, a user who never held credit has none
+	Execute(ctx context.Context, userID string) ([]entities.Wallet, error)
+}
+
+type listWalletsUseCase struct {
+	walletRepository repositories.WalletRepository
+}
+
+func NewListWalletsUseCase(walletRepository repositories.WalletRepository) ListWalletsUseCase {
+	return &listWalletsUseCase{walletRepository: walletRepository}
+}
+
+func (u *listWalletsUseCase) Execute(ctx context.Context, userID string) ([]entities.Wallet, error) {
+	fn := "usecases.listWalletsUseCase.Execute"
+	logger := rlog.With("fn", fn).With("userID", userID)
+
+	wallets, err := u.walletRepository.ListWalletsByUserID(ctx, userID)
+	if err != nil {
+		logger.Error("failed to list wallets", "error", err)
+		return nil, dto.ErrFailedToListWallets
+	}
+
+	return wallets, nil
+}
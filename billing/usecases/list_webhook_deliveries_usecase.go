@@ -0,0 +1,52 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type listWebhookDeliveriesUseCase struct {
+	webhookRepository repositories.WebhookRepository
+}
+
+// ListWebhookDeliveriesUseCase returns the delivery log of a webhook, the latest deliveries first with all their attempts
+type ListWebhookDeliveriesUseCase interface {
+	Execute(ctx context.Context, externalWebhookID string, limit int) ([]entities.WebhookDelivery, error)
+}
+
+func NewListWebhookDeliveriesUseCase(webhookRepository repositories.WebhookRepository) ListWebhookDeliveriesUseCase {
+	return &listWebhookDeliveriesUseCase{webhookRepository: webhookRepository}
+}
+
+func (uc *listWebhookDeliveriesUseCase) Execute(ctx context.Context, externalWebhookID string, limit int) ([]entities.WebhookDelivery, error) {
+	fn := "listWebhookDeliveriesUseCase.ListWebhookDeliveries"
+	logger := rlog.With("fn", fn).With("externalWebhookID", externalWebhookID).With("limit", limit)
+
+	// get webhook
+	webhook, err := uc.webhookRepository.GetWebhookByExternalID(ctx, externalWebhookID)
+	if err != nil {
+		if errors.Is(err, entities.ErrWebhookNotFound) {
+			logger.Warn("webhook not found")
+			return nil, dto.ErrWebhookNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get webhook", "error", err)
+		return nil, dto.ErrFailedToGetWebhook
+	}
+
+	// get deliveries
+	deliveries, err := uc.webhookRepository.ListWebhookDeliveries(ctx, webhook.ID, limit)
+	if err != nil {
+		logger.Error("failed to list webhook deliveries", "error", err)
+		return nil, dto.ErrFailedToListWebhookDeliveries
+	}
+
+	return deliveries, nil
+}
@@ -2,22 +2,27 @@ package ports
 
 import (
 	"context"
-	"time"
 
 	"encore.app/billing/domain/entities"
 )
 
 type BillingWorkflow interface {
-	// StartBilling starts a billing
-	StartBilling(ctx context.Context, userID string, billingID string, description string, currency string, currencyPrecision int64, plannedClosedAt *time.Time) error
+	// StartBilling starts a billing, initialLineItems are added before any other line item
+	StartBilling(ctx context.Context, billing *entities.Billing, initialLineItems []entities.LineItem) error
 
-	// AddLineItem adds a line item to a billing
-	// billingID is the internal auto-incremented billing ID
-	AddLineItem(ctx context.Context, externalBillingID string, description string, amountMinor int64) error
+	// AddLineItem adds a line item to an open billing, a line item the billing rejects returns its domain error, e.g.
+	// entities.ErrSpendLimitExceeded
+	AddLineItem(ctx context.Context, externalBillingID string, lineItem entities.LineItem) error
+
+	// ApplyCoupon redeems a coupon for an open billing and applies it, the discount is computed when the billing closes
+	ApplyCoupon(ctx context.Context, externalBillingID string, coupon entities.Coupon) error
 
 	// CloseBilling closes a billing
 	CloseBilling(ctx context.Context, externalBillingID string) error
 
+	// CancelBilling cancels an open billing, it is neither closed nor invoiced
+	CancelBilling(ctx context.Context, externalBillingID string, reason string) error
+
 	// GetBillingSummary gets a billing summary
 	GetBillingSummary(ctx context.Context, externalBillingID string) (*entities.BillingSummary, error)
 }
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+)
+
+type DunningWorkflow interface {
+	// StartDunning starts chasing the payment of a closed billing on the days of scheduleDays,
+	// autoCharge charges the billing again before each reminder
+	StartDunning(ctx context.Context, externalBillingID string, scheduleDays []int, autoCharge bool) error
+
+	// NotifyPayment stops the dunning of a billing that was paid, a billing without a running dunning is left as it is
+	NotifyPayment(ctx context.Context, externalBillingID string) error
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+
+	"encore.app/billing/domain/entities"
+)
+
+type PaymentGateway interface {
+	// CreateCharge authorizes a charge, a declined charge is returned with its decline code and no error.
+	// A request sent again with the same idempotency key returns the charge of the first request.
+	CreateCharge(ctx context.Context, request entities.ChargeRequest) (*entities.Charge, error)
+
+	// CaptureCharge collects the money of an authorized charge, capturing a captured charge returns it as it is
+	CaptureCharge(ctx context.Context, chargeID string) (*entities.Charge, error)
+
+	// RefundCharge refunds part or all of a captured charge, a refund sent again with the same idempotency key is refunded once
+	RefundCharge(ctx context.Context, chargeID string, amountMinor int64, idempotencyKey string) (*entities.Charge, error)
+
+	// GetCharge fetches the current status of a charge
+	GetCharge(ctx context.Context, chargeID string) (*entities.Charge, error)
+
+	// GetChargeByIdempotencyKey fetches the charge a request with the idempotency key made, ErrChargeNotFound if it made none
+	GetChargeByIdempotencyKey(ctx context.Context, idempotencyKey string) (*entities.Charge, error)
+}
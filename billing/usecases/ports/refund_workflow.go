@@ -0,0 +1,10 @@
+package ports
+
+import (
+	"context"
+)
+
+type RefundWorkflow interface {
+	// StartRefund starts returning a pending refund to the payer, a refund that is already started is not started again
+	StartRefund(ctx context.Context, externalRefundID string) error
+}
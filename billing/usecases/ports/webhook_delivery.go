@@ -0,0 +1,10 @@
+package ports
+
+import (
+	"context"
+)
+
+type WebhookDeliveryWorkflow interface {
+	// DeliverWebhook starts sending a delivery to its webhook, failed requests are retried with exponential backoff
+	DeliverWebhook(ctx context.Context, externalDeliveryID string) error
+}
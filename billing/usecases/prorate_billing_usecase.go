@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+)
+
+type prorateBillingUseCase struct {
+	dbRepository    repositories.DBRepository
+	taxRepository   repositories.TaxRepository
+	billingWorkflow ports.BillingWorkflow
+}
+
+// ProrateBillingUsecase adds the credit and charge line items of a mid-period change to an open billing
+type ProrateBillingUsecase interface {
+	Execute(ctx context.Context, externalBillingID string, input dto.ProrateInput) ([]entities.LineItem, error)
+}
+
+func NewProrateBillingUseCase(dbRepository repositories.DBRepository, taxRepository repositories.TaxRepository, billingWorkflow ports.BillingWorkflow) ProrateBillingUsecase {
+	return &prorateBillingUseCase{dbRepository: dbRepository, taxRepository: taxRepository, billingWorkflow: billingWorkflow}
+}
+
+func (uc *prorateBillingUseCase) Execute(ctx context.Context, externalBillingID string, input dto.ProrateInput) ([]entities.LineItem, error) {
+	fn := "prorateBillingUseCase.ProrateBilling"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("changeAt", input.ChangeAt).With("method", input.Method)
+
+	// get billing
+	billing, err := uc.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return nil, dto.ErrFailedToGetBillingByExternalID
+	}
+
+	// validate billing is open
+	if !billing.CanAddLineItem() {
+		logger.Warn("billing is not open")
+		return nil, dto.ErrBillingNotOpen
+	}
+
+	// validate tax code has a rate in the billing jurisdiction
+	err = validateTaxCode(ctx, uc.taxRepository, billing, input.TaxCode)
+	if err != nil {
+		logger.Warn("tax code is invalid", "error", err)
+		return nil, err
+	}
+
+	// billings without an explicit period run from their creation to their planned close
+	periodStart := billing.CreatedAt
+	if billing.PeriodStart != nil {
+		periodStart = *billing.PeriodStart
+	}
+	periodEnd := billing.PlannedClosedAt
+	if billing.PeriodEnd != nil {
+		periodEnd = billing.PeriodEnd
+	}
+	if periodEnd == nil {
+		logger.Warn("billing has no period end")
+		return nil, dto.ErrBillingHasNoPeriod
+	}
+
+	// convert unit amounts to minor units
+	currency := entities.CurrencyMetadata{Code: billing.Currency, Precision: billing.CurrencyPrecision}
+	if !currency.CanRepresent(input.OldUnitAmount) || !currency.CanRepresent(input.NewUnitAmount) {
+		logger.Warn("amount has too many decimals")
+		return nil, dto.ErrAmountHasTooManyDecimals
+	}
+
+	lineItems, err := entities.Prorate(entities.ProrationChange{
+		PeriodStart:        periodStart,
+		PeriodEnd:          *periodEnd,
+		ChangeAt:           input.ChangeAt,
+		Timezone:           billing.Timezone,
+		Method:             input.Method,
+		OldDescription:     input.OldDescription,
+		OldUnitAmountMinor: currency.ToMinorUnits(input.OldUnitAmount),
+		OldQuantity:        input.OldQuantity,
+		NewDescription:     input.NewDescription,
+		NewUnitAmountMinor: currency.ToMinorUnits(input.NewUnitAmount),
+		NewQuantity:        input.NewQuantity,
+	})
+	if err != nil {
+		if errors.Is(err, entities.ErrChangeOutsidePeriod) {
+			logger.Warn("change is outside of the billing period")
+			return nil, dto.ErrChangeOutsidePeriod
+		}
+
+		logger.Warn("proration is invalid", "error", err)
+		return nil, dto.ErrInvalidProration
+	}
+
+	// add proration line items to billing workflow. Their IDs are derived from the billing and the time of the change, so that
+	// a retried proration skips the line items the workflow already added instead of crediting twice
+	for i := range lineItems {
+		side := "charge"
+		if lineItems[i].AmountMinor < 0 {
+			side = "credit"
+		}
+		lineItems[i].LineItemID = uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmt.Sprintf("proration:%s:%s:%s", externalBillingID, input.ChangeAt.UTC().Format(time.RFC3339Nano), side))).String()
+		lineItems[i].TaxCode = input.TaxCode
+		err = uc.billingWorkflow.AddLineItem(ctx, externalBillingID, lineItems[i])
+		if err != nil {
+			if errors.Is(err, dto.ErrBillingNotOpen) {
+				logger.Warn("billing is not open")
+				return nil, dto.ErrBillingNotOpen
+			}
+			if errors.Is(err, entities.ErrSpendLimitExceeded) {
+				logger.Warn("proration line item exceeds spend limit")
+				return nil, dto.ErrSpendLimitExceeded
+			}
+			if errors.Is(err, entities.ErrNegativeBillingTotal) {
+				logger.Warn("proration credit takes the billing total below zero")
+				return nil, dto.ErrNegativeBillingTotal
+			}
+
+			logger.Error("failed to add proration line item to billing workflow", "error", err)
+			return nil, dto.ErrFailedToAddLineItemToBillingWorkflow
+		}
+	}
+
+	logger.Info("proration line items added successfully", "count", len(lineItems))
+
+	return lineItems, nil
+}
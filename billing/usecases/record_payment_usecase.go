@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+)
+
+type RecordPaymentUsecase interface {
+	// Execute records a payment against a closed billing and returns it with the balance of the billing
+	Execute(ctx context.Context, externalBillingID string, input dto.RecordPaymentInput) (*entities.Payment, entities.PaymentBalance, error)
+}
+
+type recordPaymentUseCase struct {
+	dbRepository         repositories.DBRepository
+	creditNoteRepository repositories.CreditNoteRepository
+	paymentRepository    repositories.PaymentRepository
+	dunningWorkflow      ports.DunningWorkflow
+}
+
+func NewRecordPaymentUseCase(dbRepository repositories.DBRepository, creditNoteRepository repositories.CreditNoteRepository, paymentRepository repositories.PaymentRepository, dunningWorkflow ports.DunningWorkflow) RecordPaymentUsecase {
+	return &recordPaymentUseCase{
+		dbRepository:         dbRepository,
+		creditNoteRepository: creditNoteRepository,
+		paymentRepository:    paymentRepository,
+		dunningWorkflow:      dunningWorkflow,
+	}
+}
+
+func (uc *recordPaymentUseCase) Execute(ctx context.Context, externalBillingID string, input dto.RecordPaymentInput) (*entities.Payment, entities.PaymentBalance, error) {
+	fn := "usecases.recordPaymentUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("amount", input.Amount).With("currency", input.Currency).With("method", input.Method).With("externalReference", input.ExternalReference)
+
+	// get billing
+	billing, err := uc.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, entities.PaymentBalance{}, dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return nil, entities.PaymentBalance{}, dto.ErrFailedToGetBillingByExternalID
+	}
+
+	// validate billing is closed, open and cancelled billings are not owed
+	if !billing.CanPayBilling() {
+		logger.Warn("billing is not closed")
+		return nil, entities.PaymentBalance{}, dto.ErrBillingNotClosed
+	}
+
+	// validate payment is in the currency of the billing, payments are never converted
+	if !strings.EqualFold(input.Currency, billing.Currency) {
+		logger.Warn("payment currency does not match the billing currency", "billingCurrency", billing.Currency)
+		return nil, entities.PaymentBalance{}, dto.ErrPaymentCurrencyMismatch
+	}
+
+	if !billing.CanPayWithAmount(input.Amount) {
+		logger.Warn("amount has too many decimals")
+		return nil, entities.PaymentBalance{}, dto.ErrAmountHasTooManyDecimals
+	}
+
+	paidAt := time.Now().UTC()
+	if input.PaidAt != nil {
+		paidAt = input.PaidAt.UTC()
+	}
+
+	payment := entities.Payment{
+		BillingID:         billing.ID,
+		ExternalBillingID: billing.ExternalBillingID,
+		AmountMinor:       int64(math.Round(input.Amount * math.Pow10(int(billing.CurrencyPrecision)))),
+		Currency:          billing.Currency,
+		CurrencyPrecision: billing.CurrencyPrecision,
+		Method:            input.Method,
+		ExternalReference: input.ExternalReference,
+		PaidAt:            paidAt,
+	}
+	err = payment.Validate()
+	if err != nil {
+		logger.Warn("payment is invalid", "error", err)
+		return nil, entities.PaymentBalance{}, dto.ErrInvalidPayment
+	}
+
+	// generate external payment ID
+	randomUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("failed to generate external payment ID")
+		return nil, entities.PaymentBalance{}, dto.ErrFailedToGeneratePaymentID
+	}
+	payment.ExternalPaymentID = randomUUID.String()
+
+	// create payment, a reference recorded before returns the recorded payment
+	_, err = uc.paymentRepository.CreatePayment(ctx, &payment)
+	if err != nil {
+		if errors.Is(err, entities.ErrPaymentReferenceTaken) {
+			logger.Warn("payment reference is recorded for another billing")
+			return nil, entities.PaymentBalance{}, dto.ErrPaymentReferenceTaken
+		}
+
+		logger.Error("failed to create payment in database", "error", err)
+		return nil, entities.PaymentBalance{}, dto.ErrFailedToCreatePaymentInDatabase
+	}
+
+	_, balance, err := getPayments(ctx, uc.dbRepository, uc.creditNoteRepository, uc.paymentRepository, billing)
+	if err != nil {
+		return nil, entities.PaymentBalance{}, err
+	}
+
+	// stop chasing a paid billing, a dunning that misses the signal resolves at its next step
+	if balance.OutstandingAmountMinor <= 0 {
+		err = uc.dunningWorkflow.NotifyPayment(ctx, billing.ExternalBillingID)
+		if err != nil {
+			logger.Warn("failed to notify dunning of payment", "error", err)
+		}
+	}
+
+	logger.Info("payment recorded successfully", "externalPaymentID", payment.ExternalPaymentID, "status", balance.Status)
+
+	return &payment, balance, nil
+}
@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+)
+
+type redeliverWebhookUseCase struct {
+	webhookRepository       repositories.WebhookRepository
+	webhookDeliveryWorkflow ports.WebhookDeliveryWorkflow
+}
+
+// RedeliverWebhookUsecase sends a completed delivery again, with a fresh set of attempts
+type RedeliverWebhookUsecase interface {
+	Execute(ctx context.Context, externalDeliveryID string) (*entities.WebhookDelivery, error)
+}
+
+func NewRedeliverWebhookUseCase(webhookRepository repositories.WebhookRepository, webhookDeliveryWorkflow ports.WebhookDeliveryWorkflow) RedeliverWebhookUsecase {
+	return &redeliverWebhookUseCase{webhookRepository: webhookRepository, webhookDeliveryWorkflow: webhookDeliveryWorkflow}
+}
+
+func (uc *redeliverWebhookUseCase) Execute(ctx context.Context, externalDeliveryID string) (*entities.WebhookDelivery, error) {
+	fn := "redeliverWebhookUseCase.RedeliverWebhook"
+	logger := rlog.With("fn", fn).With("externalDeliveryID", externalDeliveryID)
+
+	// get delivery
+	delivery, err := uc.webhookRepository.GetWebhookDeliveryByExternalID(ctx, externalDeliveryID)
+	if err != nil {
+		if errors.Is(err, entities.ErrWebhookDeliveryNotFound) {
+			logger.Warn("webhook delivery not found")
+			return nil, dto.ErrWebhookDeliveryNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get webhook delivery", "error", err)
+		return nil, dto.ErrFailedToGetWebhookDelivery
+	}
+
+	// a pending delivery is still being retried
+	if delivery.Status == entities.WebhookDeliveryStatusPending {
+		logger.Warn("webhook delivery is in progress")
+		return nil, dto.ErrWebhookDeliveryInProgress
+	}
+
+	// mark delivery as pending, so that a delivery that succeeded before is sent again
+	previousStatus := delivery.Status
+	err = uc.webhookRepository.SetWebhookDeliveryStatus(ctx, delivery.ID, entities.WebhookDeliveryStatusPending)
+	if err != nil {
+		logger.Error("failed to set webhook delivery status", "error", err)
+		return nil, dto.ErrFailedToCreateWebhookDelivery
+	}
+	delivery.Status = entities.WebhookDeliveryStatusPending
+
+	// deliver in the background, the previous status is restored if the delivery cannot start so that it can be redelivered later
+	err = uc.webhookDeliveryWorkflow.DeliverWebhook(ctx, delivery.ExternalDeliveryID)
+	if err != nil {
+		logger.Error("failed to start webhook delivery", "error", err)
+		if restoreErr := uc.webhookRepository.SetWebhookDeliveryStatus(ctx, delivery.ID, previousStatus); restoreErr != nil {
+			logger.Error("failed to restore webhook delivery status", "error", restoreErr)
+		}
+		return nil, dto.ErrFailedToStartWebhookDeliveryInWorkflow
+	}
+
+	logger.Info("webhook redelivery started successfully")
+
+	return delivery, nil
+}
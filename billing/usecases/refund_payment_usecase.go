@@ -0,0 +1,148 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/google/uuid"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+)
+
+type RefundPaymentUsecase interface {
+	// Execute requests a refund of a payment and returns it, the refund is sent to the payment gateway in a workflow
+	Execute(ctx context.Context, externalPaymentID string, input dto.RefundPaymentInput) (*entities.Refund, error)
+}
+
+type refundPaymentUseCase struct {
+	paymentRepository    repositories.PaymentRepository
+	creditNoteRepository repositories.CreditNoteRepository
+	refundRepository     repositories.RefundRepository
+	refundWorkflow       ports.RefundWorkflow
+}
+
+func NewRefundPaymentUseCase(paymentRepository repositories.PaymentRepository, creditNoteRepository repositories.CreditNoteRepository, refundRepository repositories.RefundRepository, refundWorkflow ports.RefundWorkflow) RefundPaymentUsecase {
+	return &refundPaymentUseCase{
+		paymentRepository:    paymentRepository,
+		creditNoteRepository: creditNoteRepository,
+		refundRepository:     refundRepository,
+		refundWorkflow:       refundWorkflow,
+	}
+}
+
+func (uc *refundPaymentUseCase) Execute(ctx context.Context, externalPaymentID string, input dto.RefundPaymentInput) (*entities.Refund, error) {
+	fn := "usecases.refundPaymentUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalPaymentID", externalPaymentID).With("amount", input.Amount).With("idempotencyKey", input.IdempotencyKey).With("creditNoteID", input.CreditNoteID)
+
+	// get payment
+	payment, err := uc.paymentRepository.GetPaymentByExternalID(ctx, externalPaymentID)
+	if err != nil {
+		if errors.Is(err, entities.ErrPaymentNotFound) {
+			logger.Warn("payment not found")
+			return nil, dto.ErrPaymentNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get payment by external ID", "error", err)
+		return nil, dto.ErrFailedToGetPayment
+	}
+
+	// credit drawn from a wallet is given back by adjusting the wallet
+	if !payment.IsRefundable() {
+		logger.Warn("payment from the wallet cannot be refunded")
+		return nil, dto.ErrWalletPaymentNotRefundable
+	}
+
+	if !payment.CanRefundWithAmount(input.Amount) {
+		logger.Warn("amount has too many decimals")
+		return nil, dto.ErrAmountHasTooManyDecimals
+	}
+
+	refund := entities.Refund{
+		PaymentID:         payment.ID,
+		ExternalPaymentID: payment.ExternalPaymentID,
+		BillingID:         payment.BillingID,
+		ExternalBillingID: payment.ExternalBillingID,
+		AmountMinor:       int64(math.Round(input.Amount * math.Pow10(int(payment.CurrencyPrecision)))),
+		Currency:          payment.Currency,
+		CurrencyPrecision: payment.CurrencyPrecision,
+		Reason:            input.Reason,
+		IdempotencyKey:    input.IdempotencyKey,
+		Status:            entities.RefundStatusPending,
+	}
+
+	// link credit note, only a credit note of the billing of the payment can be settled
+	if input.CreditNoteID != "" {
+		creditNote, err := uc.creditNoteRepository.GetCreditNoteByExternalID(ctx, input.CreditNoteID)
+		if err != nil {
+			if errors.Is(err, entities.ErrCreditNoteNotFound) {
+				logger.Warn("credit note not found")
+				return nil, dto.ErrCreditNoteNotFound
+			}
+
+			// unknown error
+			logger.Error("failed to get credit note", "error", err)
+			return nil, dto.ErrFailedToGetCreditNote
+		}
+		if creditNote.BillingID != payment.BillingID {
+			logger.Warn("credit note is not for the billing of the payment", "creditNoteBillingID", creditNote.ExternalBillingID)
+			return nil, dto.ErrCreditNoteNotFound
+		}
+
+		refund.CreditNoteID = &creditNote.ID
+		refund.ExternalCreditNoteID = &creditNote.ExternalCreditNoteID
+	}
+
+	err = refund.Validate()
+	if err != nil {
+		logger.Warn("refund is invalid", "error", err)
+		return nil, dto.ErrInvalidRefund
+	}
+
+	// generate external refund ID
+	randomUUID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("failed to generate external refund ID")
+		return nil, dto.ErrFailedToGenerateRefundID
+	}
+	refund.ExternalRefundID = randomUUID.String()
+
+	// create refund, a refund requested again returns the first refund
+	created, err := uc.refundRepository.CreateRefund(ctx, &refund)
+	if err != nil {
+		if errors.Is(err, entities.ErrRefundExceedsPayment) {
+			logger.Warn("refund exceeds the refundable amount of the payment")
+			return nil, dto.ErrRefundExceedsPayment
+		}
+		if errors.Is(err, entities.ErrRefundExceedsCreditNote) {
+			logger.Warn("refund exceeds the unsettled amount of the credit note")
+			return nil, dto.ErrRefundExceedsCreditNote
+		}
+		if errors.Is(err, entities.ErrRefundKeyReused) {
+			logger.Warn("idempotency key was used for a different refund")
+			return nil, dto.ErrRefundKeyReused
+		}
+
+		logger.Error("failed to create refund in database", "error", err)
+		return nil, dto.ErrFailedToCreateRefundInDatabase
+	}
+
+	// start refund, a pending refund requested again is started again in case its first start failed
+	if !created.IsCompleted() {
+		err = uc.refundWorkflow.StartRefund(ctx, created.ExternalRefundID)
+		if err != nil {
+			logger.Error("failed to start refund in workflow", "error", err)
+			return nil, dto.ErrFailedToStartRefundInWorkflow
+		}
+	}
+
+	logger.Info("refund requested successfully", "externalRefundID", created.ExternalRefundID, "status", created.Status)
+
+	return created, nil
+}
@@ -0,0 +1,99 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type RenderInvoiceUseCase interface {
+	// Execute renders a closed billing as an invoice document of the format, with the template of its tenant if it has one
+	Execute(ctx context.Context, externalBillingID string, format entities.InvoiceFormat) (*entities.Invoice, []byte, error)
+}
+
+type renderInvoiceUseCase struct {
+	dbRepository              repositories.DBRepository
+	invoiceTemplateRepository repositories.InvoiceTemplateRepository
+	fxService                 services.FxService
+	documentRenderer          services.DocumentRenderer
+}
+
+func NewRenderInvoiceUseCase(dbRepository repositories.DBRepository, invoiceTemplateRepository repositories.InvoiceTemplateRepository, fxService services.FxService, documentRenderer services.DocumentRenderer) RenderInvoiceUseCase {
+	return &renderInvoiceUseCase{
+		dbRepository:              dbRepository,
+		invoiceTemplateRepository: invoiceTemplateRepository,
+		fxService:                 fxService,
+		documentRenderer:          documentRenderer,
+	}
+}
+
+func (u *renderInvoiceUseCase) Execute(ctx context.Context, externalBillingID string, format entities.InvoiceFormat) (*entities.Invoice, []byte, error) {
+	fn := "usecases.renderInvoiceUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("format", format)
+
+	// validate format
+	if !entities.IsValidInvoiceFormat(format) {
+		logger.Warn("invoice format is invalid")
+		return nil, nil, dto.ErrInvalidInvoiceFormat
+	}
+
+	// get billing
+	billing, err := u.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, nil, dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return nil, nil, dto.ErrFailedToGetBillingByExternalID
+	}
+
+	// open billings have no invoice yet
+	if !billing.CanInvoiceBilling() {
+		logger.Warn("billing is not closed")
+		return nil, nil, dto.ErrBillingNotClosed
+	}
+
+	summary, err := u.dbRepository.GetBillingSummary(ctx, externalBillingID)
+	if err != nil {
+		logger.Error("failed to get billing summary", "error", err)
+		return nil, nil, dto.ErrFailedToGetBillingSummary
+	}
+
+	// get currency symbol and precision
+	currencyMetadata, err := u.fxService.GetCurrencyMetadata(ctx, billing.Currency, time.Now())
+	if err != nil {
+		logger.Error("failed to get currency metadata", "error", err)
+		return nil, nil, dto.ErrCurrencyMetadataNotFound
+	}
+
+	// get template of the tenant, tenants without one use the default template
+	source := ""
+	invoiceTemplate, err := u.invoiceTemplateRepository.GetInvoiceTemplate(ctx, billing.UserGroup, format)
+	if err != nil && !errors.Is(err, entities.ErrInvoiceTemplateNotFound) {
+		logger.Error("failed to get invoice template", "error", err)
+		return nil, nil, dto.ErrFailedToGetInvoiceTemplate
+	}
+	if invoiceTemplate != nil {
+		source = invoiceTemplate.Source
+	}
+
+	// render invoice
+	invoice := entities.NewInvoice(billing, summary, *currencyMetadata)
+	document, err := u.documentRenderer.RenderInvoice(invoice, format, source)
+	if err != nil {
+		logger.Error("failed to render invoice", "error", err)
+		return nil, nil, dto.ErrFailedToRenderInvoice
+	}
+
+	return &invoice, document, nil
+}
@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type replayOutboxUseCase struct {
+	outboxRepository repositories.OutboxRepository
+	eventPublisher   services.EventPublisher
+}
+
+// ReplayOutboxUseCase publishes the sent outbox messages again from an offset, for consumers that lost or need to rebuild their state
+type ReplayOutboxUseCase interface {
+	Execute(ctx context.Context, fromOffset int64, limit int) (int, int64, error)
+}
+
+func NewReplayOutboxUseCase(outboxRepository repositories.OutboxRepository, eventPublisher services.EventPublisher) ReplayOutboxUseCase {
+	return &replayOutboxUseCase{
+		outboxRepository: outboxRepository,
+		eventPublisher:   eventPublisher,
+	}
+}
+
+func (uc *replayOutboxUseCase) Execute(ctx context.Context, fromOffset int64, limit int) (int, int64, error) {
+	fn := "replayOutboxUseCase.Execute"
+	logger := rlog.With("fn", fn).With("fromOffset", fromOffset).With("limit", limit)
+
+	// replay messages, a failed publish stops the replay at the failed message
+	replayed, nextOffset, err := uc.outboxRepository.ReplayOutbox(ctx, fromOffset, limit, func(message *entities.OutboxMessage) error {
+		return uc.eventPublisher.Publish(ctx, message)
+	})
+	if err != nil {
+		logger.Error("failed to replay outbox", "replayed", replayed, "nextOffset", nextOffset, "error", err)
+		return replayed, nextOffset, dto.ErrFailedToReplayOutbox
+	}
+
+	logger.Info("outbox replayed", "replayed", replayed, "nextOffset", nextOffset)
+	return replayed, nextOffset, nil
+}
@@ -0,0 +1,122 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type ReviewBankTransactionUsecase interface {
+	// Match records a transaction waiting for review as a bank transfer paying a closed billing
+	Match(ctx context.Context, externalTransactionID string, externalBillingID string) (*entities.BankTransaction, error)
+
+	// Ignore takes a transaction waiting for review out of the queue without recording it, e.g. a transfer that pays nothing billed
+	Ignore(ctx context.Context, externalTransactionID string) (*entities.BankTransaction, error)
+}
+
+type reviewBankTransactionUseCase struct {
+	bankStatementRepository repositories.BankStatementRepository
+	recordPaymentUsecase    RecordPaymentUsecase
+}
+
+func NewReviewBankTransactionUseCase(bankStatementRepository repositories.BankStatementRepository, recordPaymentUsecase RecordPaymentUsecase) ReviewBankTransactionUsecase {
+	return &reviewBankTransactionUseCase{
+		bankStatementRepository: bankStatementRepository,
+		recordPaymentUsecase:    recordPaymentUsecase,
+	}
+}
+
+func (uc *reviewBankTransactionUseCase) Match(ctx context.Context, externalTransactionID string, externalBillingID string) (*entities.BankTransaction, error) {
+	fn := "usecases.reviewBankTransactionUseCase.Match"
+	logger := rlog.With("fn", fn).With("externalTransactionID", externalTransactionID).With("externalBillingID", externalBillingID)
+
+	transaction, err := uc.getReviewableTransaction(ctx, externalTransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// record payment, a reviewer matching the transaction to another billing at the same time is rejected by its reference
+	payment, _, err := uc.recordPaymentUsecase.Execute(ctx, externalBillingID, dto.RecordPaymentInput{
+		Amount:            float64(transaction.AmountMinor) / math.Pow10(int(transaction.CurrencyPrecision)),
+		Currency:          transaction.Currency,
+		Method:            entities.PaymentMethodBankTransfer,
+		ExternalReference: transaction.BankReference,
+		PaidAt:            &transaction.BookingDate,
+	})
+	if err != nil {
+		logger.Warn("failed to record payment of bank transaction", "error", err)
+		return nil, err
+	}
+
+	transaction.Status = entities.BankTransactionStatusMatched
+	transaction.MatchRule = entities.BankTransactionMatchRuleManual
+	transaction.BillingID = &payment.BillingID
+	transaction.PaymentID = &payment.ID
+	return uc.review(ctx, transaction)
+}
+
+func (uc *reviewBankTransactionUseCase) Ignore(ctx context.Context, externalTransactionID string) (*entities.BankTransaction, error) {
+	transaction, err := uc.getReviewableTransaction(ctx, externalTransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.Status = entities.BankTransactionStatusIgnored
+	return uc.review(ctx, transaction)
+}
+
+// getReviewableTransaction gets a transaction that waits for review
+func (uc *reviewBankTransactionUseCase) getReviewableTransaction(ctx context.Context, externalTransactionID string) (*entities.BankTransaction, error) {
+	logger := rlog.With("fn", "usecases.reviewBankTransactionUseCase.getReviewableTransaction").With("externalTransactionID", externalTransactionID)
+
+	transaction, err := uc.bankStatementRepository.GetBankTransactionByExternalID(ctx, externalTransactionID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBankTransactionNotFound) {
+			logger.Warn("bank transaction not found")
+			return nil, dto.ErrBankTransactionNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get bank transaction", "error", err)
+		return nil, dto.ErrFailedToGetBankTransaction
+	}
+
+	if !transaction.CanReview() {
+		logger.Warn("bank transaction is already reviewed", "status", transaction.Status)
+		return nil, dto.ErrBankTransactionReviewed
+	}
+
+	return transaction, nil
+}
+
+// review stores the outcome of a review and returns the reviewed transaction
+func (uc *reviewBankTransactionUseCase) review(ctx context.Context, transaction *entities.BankTransaction) (*entities.BankTransaction, error) {
+	logger := rlog.With("fn", "usecases.reviewBankTransactionUseCase.review").With("externalTransactionID", transaction.ExternalTransactionID).With("status", transaction.Status)
+
+	err := uc.bankStatementRepository.ReviewBankTransaction(ctx, transaction)
+	if err != nil {
+		if errors.Is(err, entities.ErrBankTransactionReviewed) {
+			logger.Warn("bank transaction is already reviewed")
+			return nil, dto.ErrBankTransactionReviewed
+		}
+
+		logger.Error("failed to review bank transaction", "error", err)
+		return nil, dto.ErrFailedToReviewBankTransaction
+	}
+
+	reviewed, err := uc.bankStatementRepository.GetBankTransactionByExternalID(ctx, transaction.ExternalTransactionID)
+	if err != nil {
+		logger.Error("failed to get reviewed bank transaction", "error", err)
+		return nil, dto.ErrFailedToGetBankTransaction
+	}
+
+	logger.Info("bank transaction reviewed successfully")
+
+	return reviewed, nil
+}
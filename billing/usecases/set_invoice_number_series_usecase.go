@@ -0,0 +1,52 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type setInvoiceNumberSeriesUseCase struct {
+	invoiceNumberRepository repositories.InvoiceNumberRepository
+}
+
+// SetInvoiceNumberSeriesUsecase creates or replaces the invoice number series of a user group
+type SetInvoiceNumberSeriesUsecase interface {
+	Execute(ctx context.Context, series entities.InvoiceNumberSeries) error
+}
+
+func NewSetInvoiceNumberSeriesUseCase(invoiceNumberRepository repositories.InvoiceNumberRepository) SetInvoiceNumberSeriesUsecase {
+	return &setInvoiceNumberSeriesUseCase{invoiceNumberRepository: invoiceNumberRepository}
+}
+
+func (uc *setInvoiceNumberSeriesUseCase) Execute(ctx context.Context, series entities.InvoiceNumberSeries) error {
+	fn := "setInvoiceNumberSeriesUseCase.SetInvoiceNumberSeries"
+	logger := rlog.With("fn", fn).With("userGroup", series.UserGroup).With("prefix", series.Prefix).With("padding", series.Padding)
+
+	// validate series
+	if err := series.Validate(); err != nil {
+		logger.Warn("invoice number series is invalid", "error", err)
+		return dto.ErrInvalidInvoiceNumberSeries
+	}
+
+	// upsert series
+	err := uc.invoiceNumberRepository.UpsertInvoiceNumberSeries(ctx, &series)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvoicePrefixTaken) {
+			logger.Warn("invoice number prefix is used by another user group")
+			return dto.ErrInvoicePrefixTaken
+		}
+
+		logger.Error("failed to set invoice number series in database", "error", err)
+		return dto.ErrFailedToSetInvoiceNumberSeriesInDatabase
+	}
+
+	logger.Info("invoice number series set successfully")
+
+	return nil
+}
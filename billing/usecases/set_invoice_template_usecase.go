@@ -0,0 +1,54 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/domain/services"
+	"encore.app/billing/usecases/dto"
+)
+
+type setInvoiceTemplateUseCase struct {
+	invoiceTemplateRepository repositories.InvoiceTemplateRepository
+	documentRenderer          services.DocumentRenderer
+}
+
+// SetInvoiceTemplateUsecase creates or replaces the invoice template of a tenant and format
+type SetInvoiceTemplateUsecase interface {
+	Execute(ctx context.Context, invoiceTemplate entities.InvoiceTemplate) (*entities.InvoiceTemplate, error)
+}
+
+func NewSetInvoiceTemplateUseCase(invoiceTemplateRepository repositories.InvoiceTemplateRepository, documentRenderer services.DocumentRenderer) SetInvoiceTemplateUsecase {
+	return &setInvoiceTemplateUseCase{invoiceTemplateRepository: invoiceTemplateRepository, documentRenderer: documentRenderer}
+}
+
+func (uc *setInvoiceTemplateUseCase) Execute(ctx context.Context, invoiceTemplate entities.InvoiceTemplate) (*entities.InvoiceTemplate, error) {
+	fn := "usecases.setInvoiceTemplateUseCase.Execute"
+	logger := rlog.With("fn", fn).With("tenant", invoiceTemplate.Tenant).With("format", invoiceTemplate.Format)
+
+	// validate format
+	if !entities.IsValidInvoiceFormat(invoiceTemplate.Format) {
+		logger.Warn("invoice format is invalid")
+		return nil, dto.ErrInvalidInvoiceFormat
+	}
+
+	// validate template parses, so that a broken template never reaches invoice rendering
+	if err := uc.documentRenderer.ValidateInvoiceTemplate(invoiceTemplate.Format, invoiceTemplate.Source); err != nil {
+		logger.Warn("invoice template is invalid", "error", err)
+		return nil, dto.ErrInvalidInvoiceTemplate
+	}
+
+	// upsert template
+	err := uc.invoiceTemplateRepository.UpsertInvoiceTemplate(ctx, &invoiceTemplate)
+	if err != nil {
+		logger.Error("failed to set invoice template in database", "error", err)
+		return nil, dto.ErrFailedToSetInvoiceTemplateInDatabase
+	}
+
+	logger.Info("invoice template set successfully")
+
+	return &invoiceTemplate, nil
+}
@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type setPartyUseCase struct {
+	partyRepository repositories.PartyRepository
+}
+
+// SetPartyUsecase creates or replaces the seller of a tenant or the buyer of a user
+type SetPartyUsecase interface {
+	Execute(ctx context.Context, party entities.Party) (*entities.Party, error)
+}
+
+func NewSetPartyUseCase(partyRepository repositories.PartyRepository) SetPartyUsecase {
+	return &setPartyUseCase{partyRepository: partyRepository}
+}
+
+func (uc *setPartyUseCase) Execute(ctx context.Context, party entities.Party) (*entities.Party, error) {
+	fn := "usecases.setPartyUseCase.Execute"
+	logger := rlog.With("fn", fn).With("role", party.Role).With("key", party.Key)
+
+	// validate party
+	if err := party.Validate(); err != nil {
+		logger.Warn("party is invalid", "error", err)
+		return nil, dto.ErrInvalidParty
+	}
+
+	// upsert party
+	err := uc.partyRepository.UpsertParty(ctx, &party)
+	if err != nil {
+		logger.Error("failed to set party in database", "error", err)
+		return nil, dto.ErrFailedToSetPartyInDatabase
+	}
+
+	logger.Info("party set successfully")
+
+	return &party, nil
+}
@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+)
+
+type setTaxRateUseCase struct {
+	taxRepository repositories.TaxRepository
+}
+
+// SetTaxRateUsecase creates or replaces the rate of a tax code in a jurisdiction
+type SetTaxRateUsecase interface {
+	Execute(ctx context.Context, taxRate entities.TaxRate) error
+}
+
+func NewSetTaxRateUseCase(taxRepository repositories.TaxRepository) SetTaxRateUsecase {
+	return &setTaxRateUseCase{taxRepository: taxRepository}
+}
+
+func (uc *setTaxRateUseCase) Execute(ctx context.Context, taxRate entities.TaxRate) error {
+	fn := "setTaxRateUseCase.SetTaxRate"
+	logger := rlog.With("fn", fn).With("jurisdiction", taxRate.Jurisdiction).With("taxCode", taxRate.TaxCode).With("ratePPM", taxRate.RatePPM)
+
+	// validate tax rate
+	if err := taxRate.Validate(); err != nil {
+		logger.Warn("tax rate is invalid", "error", err)
+		return dto.ErrInvalidTaxRate
+	}
+
+	// upsert tax rate
+	_, err := uc.taxRepository.UpsertTaxRate(ctx, &taxRate)
+	if err != nil {
+		logger.Error("failed to set tax rate in database", "error", err)
+		return dto.ErrFailedToSetTaxRateInDatabase
+	}
+
+	logger.Info("tax rate set successfully")
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/domain/repositories"
+	"encore.app/billing/usecases/dto"
+	"encore.app/billing/usecases/ports"
+)
+
+type StartDunningUsecase interface {
+	// Execute starts chasing the payment of a closed, unpaid billing and returns its dunning
+	Execute(ctx context.Context, externalBillingID string, input dto.StartDunningInput) (*entities.Dunning, error)
+}
+
+type startDunningUseCase struct {
+	dbRepository         repositories.DBRepository
+	creditNoteRepository repositories.CreditNoteRepository
+	paymentRepository    repositories.PaymentRepository
+	dunningRepository    repositories.DunningRepository
+	dunningWorkflow      ports.DunningWorkflow
+}
+
+func NewStartDunningUseCase(dbRepository repositories.DBRepository, creditNoteRepository repositories.CreditNoteRepository, paymentRepository repositories.PaymentRepository, dunningRepository repositories.DunningRepository, dunningWorkflow ports.DunningWorkflow) StartDunningUsecase {
+	return &startDunningUseCase{
+		dbRepository:         dbRepository,
+		creditNoteRepository: creditNoteRepository,
+		paymentRepository:    paymentRepository,
+		dunningRepository:    dunningRepository,
+		dunningWorkflow:      dunningWorkflow,
+	}
+}
+
+func (uc *startDunningUseCase) Execute(ctx context.Context, externalBillingID string, input dto.StartDunningInput) (*entities.Dunning, error) {
+	fn := "usecases.startDunningUseCase.Execute"
+	logger := rlog.With("fn", fn).With("externalBillingID", externalBillingID).With("scheduleDays", input.ScheduleDays)
+
+	// validate schedule
+	scheduleDays := input.ScheduleDays
+	if len(scheduleDays) == 0 {
+		scheduleDays = entities.DefaultDunningScheduleDays
+	}
+	if err := entities.ValidateDunningSchedule(scheduleDays); err != nil {
+		logger.Warn("dunning schedule is invalid")
+		return nil, dto.ErrInvalidDunningSchedule
+	}
+
+	// get billing
+	billing, err := uc.dbRepository.GetBillingByExternalID(ctx, externalBillingID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBillingNotFound) {
+			logger.Warn("billing not found")
+			return nil, dto.ErrBillingNotFound
+		}
+
+		// unknown error
+		logger.Error("failed to get billing by external ID", "error", err)
+		return nil, dto.ErrFailedToGetBillingByExternalID
+	}
+
+	// only closed billings are owed
+	if !billing.CanPayBilling() {
+		logger.Warn("billing is not closed")
+		return nil, dto.ErrBillingNotClosed
+	}
+
+	// validate billing is unpaid
+	_, balance, err := getPayments(ctx, uc.dbRepository, uc.creditNoteRepository, uc.paymentRepository, billing)
+	if err != nil {
+		return nil, err
+	}
+	if balance.OutstandingAmountMinor <= 0 {
+		logger.Warn("billing is already paid", "status", balance.Status)
+		return nil, dto.ErrBillingAlreadyPaid
+	}
+
+	// a billing is chased once
+	_, err = uc.dunningRepository.GetDunningByBillingID(ctx, billing.ID)
+	if err == nil {
+		logger.Warn("dunning is already started")
+		return nil, dto.ErrDunningAlreadyStarted
+	}
+	if !errors.Is(err, entities.ErrDunningNotFound) {
+		logger.Error("failed to get dunning", "error", err)
+		return nil, dto.ErrFailedToGetDunning
+	}
+
+	// create dunning, the workflow starts from it
+	dunning, err := uc.dunningRepository.CreateDunning(ctx, &entities.Dunning{
+		BillingID:    billing.ID,
+		ScheduleDays: scheduleDays,
+	})
+	if err != nil {
+		logger.Error("failed to create dunning", "error", err)
+		return nil, dto.ErrFailedToStartDunning
+	}
+
+	err = uc.dunningWorkflow.StartDunning(ctx, billing.ExternalBillingID, scheduleDays, billing.AutoCharge)
+	if err != nil {
+		if errors.Is(err, dto.ErrDunningAlreadyStarted) {
+			return nil, err
+		}
+
+		logger.Error("failed to start dunning workflow", "error", err)
+		return nil, dto.ErrFailedToStartDunning
+	}
+
+	logger.Info("dunning started successfully", "outstandingAmountMinor", balance.OutstandingAmountMinor)
+
+	return dunning, nil
+}
@@ -0,0 +1,206 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/usecases/dto"
+)
+
+// encore:api private method=GET path=/wallets/:userID
+func (s *Service) ListWallets(ctx context.Context, userID string) (*ListWalletsResponse, error) {
+	fn := "billing.Service.ListWallets"
+	logger := rlog.With("fn", fn).With("userID", userID)
+
+	wallets, err := s.listWalletsUsecase.Execute(ctx, userID)
+	if err != nil {
+		logger.Error("failed to list wallets", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list wallets",
+		}
+	}
+
+	response := make([]Wallet, len(wallets))
+	for i, wallet := range wallets {
+		response[i] = Wallet{
+			UserID:            wallet.UserID,
+			Currency:          wallet.Currency,
+			CurrencyPrecision: wallet.CurrencyPrecision,
+			BalanceMinor:      wallet.BalanceMinor,
+			CreatedAt:         wallet.CreatedAt,
+			UpdatedAt:         wallet.UpdatedAt,
+		}
+	}
+
+	return &ListWalletsResponse{
+		Wallets: response,
+	}, nil
+}
+
+// encore:api private method=POST path=/wallets/:userID/top-up
+func (s *Service) TopUpWallet(ctx context.Context, userID string, req *TopUpWalletRequest) (*WalletTransaction, error) {
+	fn := "billing.Service.TopUpWallet"
+	logger := rlog.With("fn", fn).With("userID", userID).With("currency", req.Currency).With("amount", req.Amount).With("externalReference", req.ExternalReference)
+
+	// validate amount
+	if req.Amount <= 0 {
+		logger.Warn("amount must be greater than 0")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "amount must be greater than 0",
+		}
+	}
+
+	return s.creditWallet(ctx, userID, dto.CreditWalletInput{
+		Kind:              entities.WalletTransactionKindTopUp,
+		Currency:          req.Currency,
+		Amount:            req.Amount,
+		ExternalReference: req.ExternalReference,
+	})
+}
+
+// encore:api private method=POST path=/wallets/:userID/adjustments
+func (s *Service) AdjustWallet(ctx context.Context, userID string, req *AdjustWalletRequest) (*WalletTransaction, error) {
+	fn := "billing.Service.AdjustWallet"
+	logger := rlog.With("fn", fn).With("userID", userID).With("currency", req.Currency).With("amount", req.Amount).With("reason", req.Reason)
+
+	// validate amount
+	if req.Amount == 0 {
+		logger.Warn("amount must not be 0")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "amount must not be 0",
+		}
+	}
+
+	// validate reason
+	if req.Reason == "" {
+		logger.Warn("reason is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "reason is required",
+		}
+	}
+
+	return s.creditWallet(ctx, userID, dto.CreditWalletInput{
+		Kind:     entities.WalletTransactionKindAdjustment,
+		Currency: req.Currency,
+		Amount:   req.Amount,
+		Reason:   req.Reason,
+	})
+}
+
+// creditWallet tops up or adjusts a wallet and maps the errors of both endpoints
+func (s *Service) creditWallet(ctx context.Context, userID string, input dto.CreditWalletInput) (*WalletTransaction, error) {
+	logger := rlog.With("fn", "billing.Service.creditWallet").With("userID", userID).With("kind", input.Kind)
+
+	// validate currency
+	if input.Currency == "" {
+		logger.Warn("currency is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "currency is required",
+		}
+	}
+
+	transaction, err := s.creditWalletUsecase.Execute(ctx, userID, input)
+	if err != nil {
+		if errors.Is(err, dto.ErrCurrencyNotSupported) {
+			logger.Warn("currency not supported")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "currency not supported",
+			}
+		}
+		if errors.Is(err, dto.ErrAmountHasTooManyDecimals) {
+			logger.Warn("amount has too many decimals")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "amount has too many decimals",
+			}
+		}
+		if errors.Is(err, dto.ErrInvalidWalletTransaction) {
+			logger.Warn("wallet transaction is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "wallet transaction is invalid",
+			}
+		}
+		if errors.Is(err, dto.ErrInsufficientWalletBalance) {
+			logger.Warn("wallet balance is insufficient")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "wallet balance is insufficient",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to credit wallet", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to credit wallet",
+		}
+	}
+
+	logger.Info("Wallet credited successfully", "transactionID", transaction.ExternalTransactionID, "balanceAfterMinor", transaction.BalanceAfterMinor)
+
+	response := walletTransactionResponse(transaction)
+	return &response, nil
+}
+
+// encore:api private method=GET path=/wallets/:userID/transactions
+func (s *Service) ListWalletTransactions(ctx context.Context, userID string, req *ListWalletTransactionsRequest) (*ListWalletTransactionsResponse, error) {
+	fn := "billing.Service.ListWalletTransactions"
+	logger := rlog.With("fn", fn).With("userID", userID).With("currency", req.Currency)
+
+	// validate currency
+	if req.Currency == "" {
+		logger.Warn("currency is required")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "currency is required",
+		}
+	}
+
+	transactions, err := s.listWalletTransactionsUsecase.Execute(ctx, userID, req.Currency)
+	if err != nil {
+		logger.Error("failed to list wallet transactions", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list wallet transactions",
+		}
+	}
+
+	response := make([]WalletTransaction, len(transactions))
+	for i := range transactions {
+		response[i] = walletTransactionResponse(&transactions[i])
+	}
+
+	return &ListWalletTransactionsResponse{
+		Transactions: response,
+	}, nil
+}
+
+func walletTransactionResponse(transaction *entities.WalletTransaction) WalletTransaction {
+	response := WalletTransaction{
+		TransactionID:     transaction.ExternalTransactionID,
+		UserID:            transaction.UserID,
+		Currency:          transaction.Currency,
+		CurrencyPrecision: transaction.CurrencyPrecision,
+		Kind:              transaction.Kind,
+		AmountMinor:       transaction.AmountMinor,
+		BalanceAfterMinor: transaction.BalanceAfterMinor,
+		Reason:            transaction.Reason,
+		ExternalReference: transaction.ExternalReference,
+		CreatedAt:         transaction.CreatedAt,
+	}
+	if transaction.ExternalBillingID != nil {
+		response.BillingID = *transaction.ExternalBillingID
+	}
+	return response
+}
@@ -0,0 +1,260 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"encore.dev/beta/errs"
+	"encore.dev/pubsub"
+	"encore.dev/rlog"
+
+	"encore.app/billing/domain/entities"
+	"encore.app/billing/infrastructure/events"
+	"encore.app/billing/usecases/dto"
+)
+
+// defaultWebhookDeliveriesLimit is how many deliveries the delivery log returns when no limit is given
+const defaultWebhookDeliveriesLimit = 50
+
+// maxWebhookDeliveriesLimit is the largest page of the delivery log
+const maxWebhookDeliveriesLimit = 500
+
+// subscriptions forwarding billing events to webhooks
+var (
+	_ = pubsub.NewSubscription(events.BillingCreatedTopic, "billing-created-webhooks", pubsub.SubscriptionConfig[*entities.BillingCreatedEvent]{
+		Handler: pubsub.MethodHandler((*Service).DispatchBillingCreatedWebhooks),
+	})
+
+	_ = pubsub.NewSubscription(events.BillingLineItemAddedTopic, "billing-line-item-added-webhooks", pubsub.SubscriptionConfig[*entities.BillingLineItemAddedEvent]{
+		Handler: pubsub.MethodHandler((*Service).DispatchBillingLineItemAddedWebhooks),
+	})
+
+	_ = pubsub.NewSubscription(events.BillingClosedTopic, "billing-closed-webhooks", pubsub.SubscriptionConfig[*entities.BillingClosedEvent]{
+		Handler: pubsub.MethodHandler((*Service).DispatchBillingClosedWebhooks),
+	})
+
+	_ = pubsub.NewSubscription(events.BillingCancelledTopic, "billing-cancelled-webhooks", pubsub.SubscriptionConfig[*entities.BillingCancelledEvent]{
+		Handler: pubsub.MethodHandler((*Service).DispatchBillingCancelledWebhooks),
+	})
+
+	_ = pubsub.NewSubscription(events.BillingPaymentReminderTopic, "billing-payment-reminder-webhooks", pubsub.SubscriptionConfig[*entities.BillingPaymentReminderEvent]{
+		Handler: pubsub.MethodHandler((*Service).DispatchBillingPaymentReminderWebhooks),
+	})
+
+	_ = pubsub.NewSubscription(events.BillingSpendThresholdCrossedTopic, "billing-spend-threshold-crossed-webhooks", pubsub.SubscriptionConfig[*entities.BillingSpendThresholdCrossedEvent]{
+		Handler: pubsub.MethodHandler((*Service).DispatchBillingSpendThresholdCrossedWebhooks),
+	})
+
+	_ = pubsub.NewSubscription(events.BillingUsageUnbilledTopic, "billing-usage-unbilled-webhooks", pubsub.SubscriptionConfig[*entities.BillingUsageUnbilledEvent]{
+		Handler: pubsub.MethodHandler((*Service).DispatchBillingUsageUnbilledWebhooks),
+	})
+)
+
+// encore:api private method=POST path=/webhooks
+func (s *Service) CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*Webhook, error) {
+	fn := "billing.Service.CreateWebhook"
+	logger := rlog.With("fn", fn).With("url", req.URL).With("eventTypes", req.EventTypes)
+
+	// validate url
+	if req.URL == "" {
+		logger.Warn("url is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "url is required",
+		}
+	}
+
+	webhook, err := s.createWebhookUsecase.Execute(ctx, dto.CreateWebhookInput{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+	})
+	if err != nil {
+		if errors.Is(err, dto.ErrInvalidWebhook) {
+			logger.Warn("webhook is invalid")
+			return nil, &errs.Error{
+				Code:    errs.InvalidArgument,
+				Message: "webhook is invalid",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to create webhook", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to create webhook",
+		}
+	}
+
+	logger.Info("Webhook created successfully", "webhookID", webhook.ExternalWebhookID)
+
+	return &Webhook{
+		WebhookID:  webhook.ExternalWebhookID,
+		URL:        webhook.URL,
+		EventTypes: webhook.EventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}, nil
+}
+
+// encore:api private method=GET path=/webhooks/:webhookID/deliveries
+func (s *Service) ListWebhookDeliveries(ctx context.Context, webhookID string, req *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	fn := "billing.Service.ListWebhookDeliveries"
+	logger := rlog.With("fn", fn).With("webhookID", webhookID).With("limit", req.Limit)
+
+	// validate limit
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultWebhookDeliveriesLimit
+	}
+	if limit < 0 || limit > maxWebhookDeliveriesLimit {
+		logger.Warn("limit is invalid")
+		return nil, &errs.Error{
+			Code:    errs.InvalidArgument,
+			Message: "limit must be between 1 and 500",
+		}
+	}
+
+	deliveries, err := s.listWebhookDeliveriesUsecase.Execute(ctx, webhookID, limit)
+	if err != nil {
+		if errors.Is(err, dto.ErrWebhookNotFound) {
+			logger.Warn("webhook not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "webhook not found",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to list webhook deliveries", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to list webhook deliveries",
+		}
+	}
+
+	response := make([]WebhookDelivery, len(deliveries))
+	for i := range deliveries {
+		response[i] = webhookDeliveryResponse(&deliveries[i])
+	}
+
+	return &ListWebhookDeliveriesResponse{
+		Deliveries: response,
+	}, nil
+}
+
+// encore:api private method=POST path=/webhook-deliveries/:deliveryID/redeliver
+func (s *Service) RedeliverWebhook(ctx context.Context, deliveryID string) (*WebhookDelivery, error) {
+	fn := "billing.Service.RedeliverWebhook"
+	logger := rlog.With("fn", fn).With("deliveryID", deliveryID)
+
+	delivery, err := s.redeliverWebhookUsecase.Execute(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, dto.ErrWebhookDeliveryNotFound) {
+			logger.Warn("webhook delivery not found")
+			return nil, &errs.Error{
+				Code:    errs.NotFound,
+				Message: "webhook delivery not found",
+			}
+		}
+		if errors.Is(err, dto.ErrWebhookDeliveryInProgress) {
+			logger.Warn("webhook delivery is in progress")
+			return nil, &errs.Error{
+				Code:    errs.FailedPrecondition,
+				Message: "webhook delivery is in progress",
+			}
+		}
+
+		// unknown error
+		logger.Error("failed to redeliver webhook", "error", err)
+		return nil, &errs.Error{
+			Code:    errs.Internal,
+			Message: "failed to redeliver webhook",
+		}
+	}
+
+	logger.Info("Webhook redelivery started", "deliveryID", deliveryID)
+
+	response := webhookDeliveryResponse(delivery)
+	return &response, nil
+}
+
+// DispatchBillingCreatedWebhooks delivers billing.created events to webhooks
+func (s *Service) DispatchBillingCreatedWebhooks(ctx context.Context, event *entities.BillingCreatedEvent) error {
+	return s.dispatchWebhooks(ctx, event.EventID, event.Type, event)
+}
+
+// DispatchBillingLineItemAddedWebhooks delivers billing.line_item_added events to webhooks
+func (s *Service) DispatchBillingLineItemAddedWebhooks(ctx context.Context, event *entities.BillingLineItemAddedEvent) error {
+	return s.dispatchWebhooks(ctx, event.EventID, event.Type, event)
+}
+
+// DispatchBillingClosedWebhooks delivers billing.closed events to webhooks
+func (s *Service) DispatchBillingClosedWebhooks(ctx context.Context, event *entities.BillingClosedEvent) error {
+	return s.dispatchWebhooks(ctx, event.EventID, event.Type, event)
+}
+
+// DispatchBillingCancelledWebhooks delivers billing.cancelled events to webhooks
+func (s *Service) DispatchBillingCancelledWebhooks(ctx context.Context, event *entities.BillingCancelledEvent) error {
+	return s.dispatchWebhooks(ctx, event.EventID, event.Type, event)
+}
+
+// DispatchBillingPaymentReminderWebhooks delivers billing.payment_reminder events to webhooks
+func (s *Service) DispatchBillingPaymentReminderWebhooks(ctx context.Context, event *entities.BillingPaymentReminderEvent) error {
+	return s.dispatchWebhooks(ctx, event.EventID, event.Type, event)
+}
+
+// DispatchBillingSpendThresholdCrossedWebhooks delivers billing.spend_threshold_crossed events to webhooks
+func (s *Service) DispatchBillingSpendThresholdCrossedWebhooks(ctx context.Context, event *entities.BillingSpendThresholdCrossedEvent) error {
+	return s.dispatchWebhooks(ctx, event.EventID, event.Type, event)
+}
+
+// DispatchBillingUsageUnbilledWebhooks delivers billing.usage_unbilled events to webhooks
+func (s *Service) DispatchBillingUsageUnbilledWebhooks(ctx context.Context, event *entities.BillingUsageUnbilledEvent) error {
+	return s.dispatchWebhooks(ctx, event.EventID, event.Type, event)
+}
+
+// dispatchWebhooks sends an event to the webhooks subscribed to its type, a failed dispatch is retried by Pub/Sub
+func (s *Service) dispatchWebhooks(ctx context.Context, eventID string, eventType entities.BillingEventType, event any) error {
+	fn := "billing.Service.dispatchWebhooks"
+	logger := rlog.With("fn", fn).With("eventID", eventID).With("eventType", eventType)
+
+	// webhooks receive the event as it was published
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("failed to encode event", "error", err)
+		return err
+	}
+
+	err = s.dispatchWebhookEventUsecase.Execute(ctx, eventID, eventType, payload)
+	if err != nil {
+		logger.Error("failed to dispatch webhooks", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func webhookDeliveryResponse(delivery *entities.WebhookDelivery) WebhookDelivery {
+	attempts := make([]WebhookDeliveryAttempt, len(delivery.Attempts))
+	for i, attempt := range delivery.Attempts {
+		attempts[i] = WebhookDeliveryAttempt{
+			StatusCode:  attempt.StatusCode,
+			Error:       attempt.Error,
+			DurationMS:  attempt.DurationMS,
+			AttemptedAt: attempt.AttemptedAt,
+		}
+	}
+
+	return WebhookDelivery{
+		DeliveryID: delivery.ExternalDeliveryID,
+		WebhookID:  delivery.ExternalWebhookID,
+		EventID:    delivery.EventID,
+		EventType:  delivery.EventType,
+		Status:     delivery.Status,
+		Payload:    json.RawMessage(delivery.Payload),
+		Attempts:   attempts,
+		CreatedAt:  delivery.CreatedAt,
+		UpdatedAt:  delivery.UpdatedAt,
+	}
+}